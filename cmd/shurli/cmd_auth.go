@@ -2,11 +2,15 @@ package main
 
 import (
 	"bufio"
+	"cmp"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +18,7 @@ import (
 	"github.com/shurlinet/shurli/internal/auth"
 	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/internal/output"
 	"github.com/shurlinet/shurli/internal/termcolor"
 	"github.com/shurlinet/shurli/internal/validate"
 	"github.com/shurlinet/shurli/pkg/sdk"
@@ -32,6 +37,12 @@ func runAuth(args []string) {
 		runAuthList(args[1:])
 	case "remove":
 		runAuthRemove(args[1:])
+	case "prune":
+		runAuthPrune(args[1:])
+	case "export":
+		runAuthExport(args[1:])
+	case "import":
+		runAuthImport(args[1:])
 	case "validate":
 		runAuthValidate(args[1:])
 	case "grant":
@@ -61,9 +72,12 @@ func printAuthUsage() {
 	fmt.Println("Usage: shurli auth <command> [options]")
 	fmt.Println()
 	fmt.Println("Peer authorization (authorized_keys):")
-	fmt.Println("  add      <peer-id> [--comment \"label\"] [--role admin|member]   Authorize a peer")
+	fmt.Println("  add      <peer-id> [--comment \"label\"] [--role admin|member] [--ttl 24h]   Authorize a peer")
 	fmt.Println("  list                                                          List authorized peers")
 	fmt.Println("  remove   <peer-id>                                            Revoke a peer's access")
+	fmt.Println("  prune                                                         Remove expired peers")
+	fmt.Println("  export   [--json]                                             Export authorized peers")
+	fmt.Println("  import   <file.json>                                          Merge peers from an export")
 	fmt.Println("  validate [file]                                               Validate authorized_keys format")
 	fmt.Println("  set-attr <peer-id> <key> <value>                              Set peer attribute")
 	fmt.Println()
@@ -118,17 +132,29 @@ func doAuthAdd(args []string, stdout io.Writer) error {
 	fileFlag := fs.String("file", "", "path to authorized_keys file (overrides config)")
 	commentFlag := fs.String("comment", "", "optional comment for this peer")
 	roleFlag := fs.String("role", "member", "peer role: admin or member")
+	ttlFlag := fs.Duration("ttl", 0, "authorize only for this long (e.g. 24h); the daemon removes the peer afterwards")
+	expiresFlag := fs.Duration("expires", 0, "same as --ttl")
 	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
 		return err
 	}
 
 	if fs.NArg() != 1 {
-		return fmt.Errorf("usage: shurli auth add <peer-id> [--comment \"label\"] [--role admin|member]")
+		return fmt.Errorf("usage: shurli auth add <peer-id> [--comment \"label\"] [--role admin|member] [--ttl 24h]")
 	}
 
 	if *roleFlag != auth.RoleAdmin && *roleFlag != auth.RoleMember {
 		return fmt.Errorf("invalid role %q: must be \"admin\" or \"member\"", *roleFlag)
 	}
+	ttlName := "--ttl"
+	if *expiresFlag != 0 {
+		if *ttlFlag != 0 {
+			return fmt.Errorf("--ttl and --expires are the same option; use one")
+		}
+		ttlName, *ttlFlag = "--expires", *expiresFlag
+	}
+	if *ttlFlag < 0 {
+		return fmt.Errorf("invalid %s %s: must be positive", ttlName, *ttlFlag)
+	}
 
 	peerIDStr := fs.Arg(0)
 	authKeysPath, err := resolveAuthKeysPathErr(*fileFlag, *configFlag)
@@ -144,11 +170,22 @@ func doAuthAdd(args []string, stdout io.Writer) error {
 		return fmt.Errorf("failed to set role: %w", err)
 	}
 
+	var expiresAt time.Time
+	if *ttlFlag > 0 {
+		expiresAt = time.Now().Add(*ttlFlag).UTC().Truncate(time.Second)
+		if err := auth.SetPeerAttr(authKeysPath, peerIDStr, "expires", expiresAt.Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("failed to set expiry: %w", err)
+		}
+	}
+
 	termcolor.Green("Authorized peer: %s", peerIDStr[:min(16, len(peerIDStr))]+"...")
 	if *commentFlag != "" {
 		fmt.Fprintf(stdout, "  Comment: %s\n", *commentFlag)
 	}
 	fmt.Fprintf(stdout, "  Role: %s\n", *roleFlag)
+	if !expiresAt.IsZero() {
+		fmt.Fprintf(stdout, "  Expires: %s (in %s)\n", expiresAt.Format(time.RFC3339), *ttlFlag)
+	}
 	fmt.Fprintf(stdout, "  File: %s\n", authKeysPath)
 
 	// Also add name mapping to config if a comment was provided.
@@ -183,6 +220,17 @@ func tryDaemonConfigReload() {
 	c.ConfigReload() //nolint:errcheck // best-effort, ignored by design
 }
 
+// authListEntry is the json/yaml shape of one authorized_keys entry.
+type authListEntry struct {
+	PeerID    string `json:"peer_id"`
+	Role      string `json:"role"`
+	Comment   string `json:"comment,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Verified  string `json:"verified,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Schedule  string `json:"schedule,omitempty"`
+}
+
 func runAuthList(args []string) {
 	if err := doAuthList(args, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -195,10 +243,35 @@ func doAuthList(args []string, stdout io.Writer) error {
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
 	fileFlag := fs.String("file", "", "path to authorized_keys file (overrides config)")
-	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+	groupFlag := fs.String("group", "", `only peers in this group ("" = peers with no group)`)
+	ungroupedFlag := fs.Bool("ungrouped", false, "only peers with no group")
+	commentFlag := fs.String("comment-contains", "", "only peers whose comment contains this text (case-insensitive)")
+	outFlags := output.Register(fs)
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true, "ungrouped": true})); err != nil {
+		return err
+	}
+	format, err := outFlags.Format()
+	if err != nil {
 		return err
 	}
 
+	// --group "" is a valid filter (no group), so look at whether the flag
+	// was given rather than at its value.
+	filter := auth.PeerFilter{CommentContains: *commentFlag}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "group" {
+			filter.Group = groupFlag
+		}
+	})
+	if *ungroupedFlag {
+		if filter.Group != nil && *filter.Group != "" {
+			return fmt.Errorf("--ungrouped and --group %q are mutually exclusive", *filter.Group)
+		}
+		none := ""
+		filter.Group = &none
+	}
+	filtered := filter != (auth.PeerFilter{})
+
 	authKeysPath, err := resolveAuthKeysPathErr(*fileFlag, *configFlag)
 	if err != nil {
 		return err
@@ -208,13 +281,48 @@ func doAuthList(args []string, stdout io.Writer) error {
 	if err != nil {
 		return fmt.Errorf("failed to list peers: %w", err)
 	}
+	total := len(entries)
+	entries = auth.FilterPeers(entries, filter)
+
+	if format != output.Table {
+		list := make([]authListEntry, 0, len(entries))
+		for _, e := range entries {
+			role := e.Role
+			if role == "" {
+				role = auth.RoleMember
+			}
+			item := authListEntry{
+				PeerID:   e.PeerID.String(),
+				Role:     role,
+				Comment:  e.Comment,
+				Group:    e.Group,
+				Verified: e.Verified,
+			}
+			if !e.ExpiresAt.IsZero() {
+				item.ExpiresAt = e.ExpiresAt.UTC().Format(time.RFC3339)
+			}
+			if e.Schedule != nil {
+				item.Schedule = e.Schedule.String()
+			}
+			list = append(list, item)
+		}
+		return output.Write(stdout, format, list)
+	}
 
 	if len(entries) == 0 {
-		fmt.Fprintln(stdout, "No authorized peers.")
+		if filtered && total > 0 {
+			fmt.Fprintf(stdout, "No authorized peers match (%d in total).\n", total)
+		} else {
+			fmt.Fprintln(stdout, "No authorized peers.")
+		}
 		return nil
 	}
 
-	fmt.Fprintf(stdout, "Authorized peers (%d):\n\n", len(entries))
+	if filtered {
+		fmt.Fprintf(stdout, "Authorized peers (%d of %d):\n\n", len(entries), total)
+	} else {
+		fmt.Fprintf(stdout, "Authorized peers (%d):\n\n", len(entries))
+	}
 	for i, entry := range entries {
 		short := entry.PeerID.String()[:16] + "..."
 		full := entry.PeerID.String()
@@ -248,12 +356,40 @@ func doAuthList(args []string, stdout io.Writer) error {
 		if !entry.ExpiresAt.IsZero() {
 			attrs += " [expires=" + entry.ExpiresAt.Format("2006-01-02") + "]"
 		}
+		if entry.Schedule != nil {
+			attrs += " [schedule=" + entry.Schedule.String() + "]"
+		}
 		termcolor.Faint("     %s\n", attrs)
 	}
-	fmt.Fprintf(stdout, "\nFile: %s\n", authKeysPath)
+	fmt.Fprintf(stdout, "\nBy group: %s\n", formatGroupCounts(auth.GroupCounts(entries)))
+	fmt.Fprintf(stdout, "File: %s\n", authKeysPath)
 	return nil
 }
 
+// formatGroupCounts renders per-group peer counts for the auth list
+// footer, largest group first, with ungrouped peers last.
+func formatGroupCounts(counts map[string]int) string {
+	groups := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(groups, func(a, b string) int {
+		if (a == "") != (b == "") {
+			if a == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+	parts := make([]string, 0, len(groups))
+	for _, g := range groups {
+		name := g
+		if g == "" {
+			name = "(no group)"
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", name, counts[g]))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func runAuthRemove(args []string) {
 	if err := doAuthRemove(args, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -294,6 +430,175 @@ func doAuthRemove(args []string, stdout io.Writer) error {
 	return nil
 }
 
+func runAuthPrune(args []string) {
+	if err := doAuthPrune(args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// doAuthPrune rewrites authorized_keys without the peers whose --ttl has
+// passed. The daemon does the same every minute; prune is for files no
+// daemon is watching, or to clean up right away.
+func doAuthPrune(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("auth prune", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFlag := fs.String("config", "", "path to config file")
+	fileFlag := fs.String("file", "", "path to authorized_keys file (overrides config)")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: shurli auth prune [--file <path>]")
+	}
+
+	authKeysPath, err := resolveAuthKeysPathErr(*fileFlag, *configFlag)
+	if err != nil {
+		return err
+	}
+
+	removed, err := auth.RemoveExpiredPeers(authKeysPath, time.Now())
+	for _, p := range removed {
+		fmt.Fprintf(stdout, "Removed expired peer: %s\n", p.String()[:16]+"...")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune: %w", err)
+	}
+	if len(removed) == 0 {
+		fmt.Fprintln(stdout, "No expired peers.")
+		return nil
+	}
+	fmt.Fprintf(stdout, "  File: %s\n", authKeysPath)
+
+	tryDaemonConfigReload()
+	return nil
+}
+
+func runAuthExport(args []string) {
+	if err := doAuthExport(args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// doAuthExport prints every authorized peer with its comment and attributes.
+// Table format prints authorized_keys lines; --json emits the array that
+// `auth import` reads on another node.
+func doAuthExport(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("auth export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFlag := fs.String("config", "", "path to config file")
+	fileFlag := fs.String("file", "", "path to authorized_keys file (overrides config)")
+	outFlags := output.Register(fs)
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true})); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: shurli auth export [--json] [--file <path>]")
+	}
+	format, err := outFlags.Format()
+	if err != nil {
+		return err
+	}
+
+	authKeysPath, err := resolveAuthKeysPathErr(*fileFlag, *configFlag)
+	if err != nil {
+		return err
+	}
+
+	peers, err := auth.ExportPeers(authKeysPath)
+	if err != nil {
+		return fmt.Errorf("failed to export peers: %w", err)
+	}
+
+	if format != output.Table {
+		if peers == nil {
+			peers = []auth.ExportedPeer{}
+		}
+		return output.Write(stdout, format, peers)
+	}
+	for _, p := range peers {
+		fmt.Fprintln(stdout, auth.FormatExportedPeer(p))
+	}
+	return nil
+}
+
+func runAuthImport(args []string) {
+	if err := doAuthImport(args, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// doAuthImport merges peers from a JSON export ("-" reads stdin) into the
+// local authorized_keys. Malformed entries and comment conflicts are
+// reported; the rest are still imported.
+func doAuthImport(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("auth import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFlag := fs.String("config", "", "path to config file")
+	fileFlag := fs.String("file", "", "path to authorized_keys file (overrides config)")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: shurli auth import <file.json> [--file <path>]")
+	}
+
+	var data []byte
+	var err error
+	if src := fs.Arg(0); src == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read import: %w", err)
+	}
+	var peers []auth.ExportedPeer
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return fmt.Errorf("failed to parse import: expected a JSON array from 'shurli auth export --json': %w", err)
+	}
+
+	authKeysPath, err := resolveAuthKeysPathErr(*fileFlag, *configFlag)
+	if err != nil {
+		return err
+	}
+
+	result, err := auth.ImportPeers(authKeysPath, peers)
+	if err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+
+	short := func(id string) string {
+		if len(id) > 16 {
+			return id[:16] + "..."
+		}
+		return id
+	}
+	for _, id := range result.Added {
+		fmt.Fprintf(stdout, "Added:    %s\n", short(id))
+	}
+	for _, id := range result.Merged {
+		fmt.Fprintf(stdout, "Merged:   %s (new attributes)\n", short(id))
+	}
+	for _, c := range result.Conflicts {
+		fmt.Fprintf(stdout, "Conflict: %s comment %q kept (import has %q)\n",
+			short(c.PeerID), validate.SanitizeForDisplay(c.ExistingComment), validate.SanitizeForDisplay(c.ImportedComment))
+	}
+	for _, inv := range result.Invalid {
+		fmt.Fprintf(stdout, "Invalid:  entry %d (%q): %s\n", inv.Index, validate.SanitizeForDisplay(inv.PeerID), inv.Error)
+	}
+	fmt.Fprintf(stdout, "%d added, %d merged, %d unchanged, %d conflicts, %d invalid\n",
+		len(result.Added), len(result.Merged), len(result.Skipped), len(result.Conflicts), len(result.Invalid))
+
+	if result.Changed() {
+		fmt.Fprintf(stdout, "  File: %s\n", authKeysPath)
+		tryDaemonConfigReload()
+	}
+	return nil
+}
+
 func runAuthValidate(args []string) {
 	if err := doAuthValidate(args, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -408,9 +713,17 @@ func doAuthSetAttr(args []string, stdout io.Writer) error {
 		"group":            true,
 		"verified":         true,
 		"bandwidth_budget": true,
+		"schedule":         true,
 	}
 	if !allowed[key] {
-		return fmt.Errorf("attribute %q not allowed (allowed: role, group, verified, bandwidth_budget)", key)
+		return fmt.Errorf("attribute %q not allowed (allowed: role, group, verified, bandwidth_budget, schedule)", key)
+	}
+
+	// Validate schedule windows parse correctly ("" clears the schedule).
+	if key == "schedule" && value != "" {
+		if _, err := auth.ParseSchedule(value); err != nil {
+			return fmt.Errorf("invalid schedule value %q: %w (e.g. mon-fri@09:00-17:00)", value, err)
+		}
 	}
 
 	// Validate bandwidth_budget values parse correctly.
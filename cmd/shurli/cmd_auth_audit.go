@@ -77,7 +77,7 @@ func doAuthAudit(args []string, stdout io.Writer) error {
 		return errOut(fmt.Errorf("failed to derive audit key"))
 	}
 
-	auditPath := filepath.Join(configDir, "grant_audit.log")
+	auditPath := filepath.Join(configDir, config.ProfileFileName("grant_audit.log"))
 	al, err := grants.NewAuditLog(auditPath, auditKey)
 	if err != nil {
 		return errOut(fmt.Errorf("cannot open audit log: %w", err))
@@ -2,13 +2,21 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/auth"
+	"github.com/shurlinet/shurli/internal/clock"
 )
 
 // generateTestPeerID creates a fresh valid Ed25519 peer ID for testing.
@@ -132,6 +140,331 @@ func TestDoAuthAdd_FileContainsPeer(t *testing.T) {
 	}
 }
 
+func TestDoAuthAdd_TTL(t *testing.T) {
+	dir := t.TempDir()
+	peerID := generateTestPeerID(t)
+	akPath := filepath.Join(dir, "authorized_keys")
+
+	var stdout bytes.Buffer
+	before := time.Now()
+	if err := doAuthAdd([]string{peerID, "--file", akPath, "--ttl", "24h"}, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Expires:") {
+		t.Errorf("output should show expiry, got:\n%s", stdout.String())
+	}
+
+	entries, err := auth.ListPeers(akPath)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListPeers = %v, %v", entries, err)
+	}
+	exp := entries[0].ExpiresAt
+	if exp.Before(before.Add(24*time.Hour-time.Second)) || exp.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want ~24h from now", exp)
+	}
+}
+
+func TestDoAuthAdd_NegativeTTLRejected(t *testing.T) {
+	dir := t.TempDir()
+	akPath := filepath.Join(dir, "authorized_keys")
+
+	var stdout bytes.Buffer
+	err := doAuthAdd([]string{generateTestPeerID(t), "--file", akPath, "--ttl", "-1h"}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "--ttl") {
+		t.Fatalf("expected --ttl error, got %v", err)
+	}
+}
+
+func TestDoAuthAdd_Expires(t *testing.T) {
+	dir := t.TempDir()
+	peerID := generateTestPeerID(t)
+	akPath := filepath.Join(dir, "authorized_keys")
+
+	var stdout bytes.Buffer
+	if err := doAuthAdd([]string{peerID, "--file", akPath, "--expires", "2h"}, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := auth.ListPeers(akPath)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListPeers = %v, %v", entries, err)
+	}
+	if exp := entries[0].ExpiresAt; exp.IsZero() || exp.After(time.Now().Add(2*time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want ~2h from now", exp)
+	}
+
+	err = doAuthAdd([]string{generateTestPeerID(t), "--file", akPath, "--ttl", "1h", "--expires", "2h"}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "--expires") {
+		t.Errorf("--ttl with --expires: err = %v, want conflict error", err)
+	}
+	err = doAuthAdd([]string{generateTestPeerID(t), "--file", akPath, "--expires", "-1h"}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "--expires") {
+		t.Errorf("negative --expires: err = %v, want --expires error", err)
+	}
+}
+
+func TestDoAuthPrune(t *testing.T) {
+	dir := t.TempDir()
+	guest := generateTestPeerID(t)
+	home := generateTestPeerID(t)
+	akPath := writeAuthKeysFile(t, dir, guest+"  # guest\n"+home+"  # home\n")
+	if err := auth.SetPeerAttr(akPath, guest, "expires", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := doAuthPrune([]string{"--file", akPath}, &stdout); err != nil {
+		t.Fatalf("doAuthPrune: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Removed expired peer: "+guest[:16]) {
+		t.Errorf("output should name the pruned peer, got:\n%s", stdout.String())
+	}
+	data, err := os.ReadFile(akPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), guest) || !strings.Contains(string(data), home+"  # home") {
+		t.Errorf("authorized_keys after prune:\n%s", data)
+	}
+
+	stdout.Reset()
+	if err := doAuthPrune([]string{"--file", akPath}, &stdout); err != nil {
+		t.Fatalf("second prune: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No expired peers.") {
+		t.Errorf("second prune output = %q", stdout.String())
+	}
+
+	if err := doAuthPrune([]string{"--file", akPath, "extra"}, &stdout); err == nil {
+		t.Error("expected usage error for extra argument")
+	}
+}
+
+func TestDoAuthExportImport(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	home := generateTestPeerID(t)
+	laptop := generateTestPeerID(t)
+	srcPath := writeAuthKeysFile(t, srcDir, home+"  role=admin  # home\n"+laptop+"  # laptop\n")
+	dstPath := writeAuthKeysFile(t, dstDir, laptop+"  # work-laptop\n")
+
+	var exported bytes.Buffer
+	if err := doAuthExport([]string{"--file", srcPath, "--json"}, &exported); err != nil {
+		t.Fatalf("doAuthExport: %v", err)
+	}
+	if !strings.Contains(exported.String(), `"peer_id": "`+home+`"`) {
+		t.Errorf("export missing home peer:\n%s", exported.String())
+	}
+
+	var stdout bytes.Buffer
+	if err := doAuthImport([]string{"--file", dstPath, "-"}, &exported, &stdout); err != nil {
+		t.Fatalf("doAuthImport: %v", err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "Added:    "+home[:16]) || !strings.Contains(out, "Conflict: "+laptop[:16]) {
+		t.Errorf("import output:\n%s", out)
+	}
+	if !strings.Contains(out, "1 added, 0 merged, 1 unchanged, 1 conflicts, 0 invalid") {
+		t.Errorf("import summary:\n%s", out)
+	}
+	data, _ := os.ReadFile(dstPath)
+	if !strings.Contains(string(data), laptop+"  # work-laptop") || !strings.Contains(string(data), home+"  role=admin  # home") {
+		t.Errorf("authorized_keys after import:\n%s", data)
+	}
+
+	var table bytes.Buffer
+	if err := doAuthExport([]string{"--file", dstPath}, &table); err != nil {
+		t.Fatalf("doAuthExport table: %v", err)
+	}
+	if strings.Count(table.String(), "\n") != 2 {
+		t.Errorf("table export:\n%s", table.String())
+	}
+
+	if err := doAuthImport([]string{"--file", dstPath, "-"}, strings.NewReader("{not json"), &stdout); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+func TestSweepExpiredPeers(t *testing.T) {
+	dir := t.TempDir()
+	guest := generateTestPeerID(t)
+	home := generateTestPeerID(t)
+	akPath := writeAuthKeysFile(t, dir, guest+"\n"+home+"\n")
+
+	if err := auth.SetPeerAttr(akPath, guest, "expires", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)); err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err := auth.LoadAuthorizedKeys(akPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gater := auth.NewAuthorizedPeerGater(peers)
+
+	var disconnected []peer.ID
+	removed, err := sweepExpiredPeers(akPath, gater, time.Now(), func(p peer.ID) {
+		disconnected = append(disconnected, p)
+	})
+	if err != nil {
+		t.Fatalf("sweepExpiredPeers: %v", err)
+	}
+
+	guestID, _ := peer.Decode(guest)
+	homeID, _ := peer.Decode(home)
+	if len(removed) != 1 || removed[0] != guestID {
+		t.Fatalf("removed = %v, want [%s]", removed, guest)
+	}
+	if len(disconnected) != 1 || disconnected[0] != guestID {
+		t.Errorf("disconnected = %v, want [%s]", disconnected, guest)
+	}
+	if gater.IsAuthorized(guestID) {
+		t.Error("expired peer should no longer be authorized in the gater")
+	}
+	if !gater.IsAuthorized(homeID) {
+		t.Error("peer without a TTL must stay authorized")
+	}
+
+	// A second sweep, even far in the future, never removes the peer without a TTL.
+	removed, err = sweepExpiredPeers(akPath, gater, time.Now().Add(10*365*24*time.Hour), nil)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("second sweep removed %v (err %v), want nothing", removed, err)
+	}
+	if !gater.IsAuthorized(homeID) {
+		t.Error("peer without a TTL was deauthorized")
+	}
+}
+
+// TestRunAuthExpirySweep fast-forwards a fake clock past a peer's TTL and
+// checks the sweep loop deauthorizes it on the next tick.
+func TestRunAuthExpirySweep(t *testing.T) {
+	dir := t.TempDir()
+	guest := generateTestPeerID(t)
+	akPath := writeAuthKeysFile(t, dir, guest+"\n")
+
+	// Start at the wall clock: the authorized_keys loader judges expiry by it.
+	fake := clock.NewFake(time.Now())
+	if err := auth.SetPeerAttr(akPath, guest, "expires", fake.Now().Add(time.Hour).Format(time.RFC3339)); err != nil {
+		t.Fatal(err)
+	}
+	peers, err := auth.LoadAuthorizedKeys(akPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gater := auth.NewAuthorizedPeerGater(peers)
+	guestID, _ := peer.Decode(guest)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	removedCh := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		runAuthExpirySweep(ctx, fake, akPath, gater, nil, func() { removedCh <- struct{}{} })
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Wait for the loop's ticker before moving the clock.
+	for i := 0; fake.Waiters() == 0; i++ {
+		if i > 1000 {
+			t.Fatal("sweep loop never started its ticker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Sweeps before the TTL leave the peer alone.
+	fake.Advance(59 * time.Minute)
+	select {
+	case <-removedCh:
+		t.Fatal("peer removed before its TTL")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !gater.IsAuthorized(guestID) {
+		t.Fatal("peer deauthorized before its TTL")
+	}
+
+	fake.Advance(2 * authExpirySweepInterval)
+	select {
+	case <-removedCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("peer not removed after its TTL")
+	}
+	if gater.IsAuthorized(guestID) {
+		t.Error("expired peer still authorized in the gater")
+	}
+}
+
+// TestRunScheduleSweep connects a scheduled peer inside its window, moves a
+// fake clock past the window's end and checks the sweep drops the live
+// connection and refreshes the watchlist.
+func TestRunScheduleSweep(t *testing.T) {
+	newHost := func() host.Host {
+		h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"), libp2p.DisableRelay())
+		if err != nil {
+			t.Fatalf("libp2p.New: %v", err)
+		}
+		t.Cleanup(func() { h.Close() })
+		return h
+	}
+	local, remote := newHost(), newHost()
+	if err := local.Connect(context.Background(), peer.AddrInfo{ID: remote.ID(), Addrs: remote.Addrs()}); err != nil {
+		t.Fatal(err)
+	}
+
+	gater := auth.NewAuthorizedPeerGater(map[peer.ID]bool{remote.ID(): true})
+	sched, err := auth.ParseSchedule("mon-fri@09:00-17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gater.SetPeerSchedule(remote.ID(), sched)
+
+	fake := clock.NewFake(time.Date(2026, 3, 4, 16, 58, 0, 0, time.Local)) // Wednesday, window open
+	disconnect := func(p peer.ID) { local.Network().ClosePeer(p) }
+	changed := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runScheduleSweep(ctx, fake, gater, disconnect, func() { changed <- struct{}{} })
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	for i := 0; fake.Waiters() == 0; i++ {
+		if i > 1000 {
+			t.Fatal("sweep loop never started its ticker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Still inside the window: the connection stays.
+	fake.Advance(scheduleSweepInterval)
+	select {
+	case <-changed:
+		t.Fatal("watchlist refreshed while the peer is inside its window")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if len(local.Network().ConnsToPeer(remote.ID())) == 0 {
+		t.Fatal("peer disconnected inside its window")
+	}
+
+	// 17:00: the window has ended.
+	fake.Advance(scheduleSweepInterval)
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchlist not refreshed when the window ended")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(local.Network().ConnsToPeer(remote.ID())) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("peer still connected after its window ended")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestDoAuthAdd_DuplicateRejected(t *testing.T) {
 	dir := t.TempDir()
 	peerID := generateTestPeerID(t)
@@ -410,6 +743,113 @@ func TestDoAuthValidate(t *testing.T) {
 
 // ----- Auth commands via --config (exercises resolveAuthKeysPathErr) -----
 
+func TestDoAuthList_Formats(t *testing.T) {
+	dir := t.TempDir()
+	pid := generateTestPeerID(t)
+	akPath := writeAuthKeysFile(t, dir, pid+" role=admin # home\n")
+
+	var jsonOut bytes.Buffer
+	if err := doAuthList([]string{"--file", akPath, "--format", "json"}, &jsonOut); err != nil {
+		t.Fatalf("--format json: %v", err)
+	}
+	var list []authListEntry
+	if err := json.Unmarshal(jsonOut.Bytes(), &list); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, jsonOut.String())
+	}
+	if len(list) != 1 || list[0].PeerID != pid || list[0].Role != "admin" || list[0].Comment != "home" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+
+	var yamlOut bytes.Buffer
+	if err := doAuthList([]string{"--file", akPath, "--format", "yaml"}, &yamlOut); err != nil {
+		t.Fatalf("--format yaml: %v", err)
+	}
+	if !strings.Contains(yamlOut.String(), "peer_id: "+pid) {
+		t.Errorf("yaml output missing peer_id:\n%s", yamlOut.String())
+	}
+
+	// Empty file renders an empty list, not "null".
+	emptyPath := writeAuthKeysFile(t, t.TempDir(), "")
+	var emptyOut bytes.Buffer
+	if err := doAuthList([]string{"--json", "--file", emptyPath}, &emptyOut); err != nil {
+		t.Fatalf("--json empty: %v", err)
+	}
+	if strings.TrimSpace(emptyOut.String()) != "[]" {
+		t.Errorf("empty list = %q, want []", emptyOut.String())
+	}
+}
+
+func TestDoAuthList_Filters(t *testing.T) {
+	dir := t.TempDir()
+	ids := []string{generateTestPeerID(t), generateTestPeerID(t), generateTestPeerID(t), generateTestPeerID(t)}
+	akPath := writeAuthKeysFile(t, dir,
+		ids[0]+" group=family # Mum's Laptop\n"+
+			ids[1]+" group=family # phone\n"+
+			ids[2]+" group=work # work laptop\n"+
+			ids[3]+" # nas\n")
+
+	list := func(args ...string) []authListEntry {
+		t.Helper()
+		var out bytes.Buffer
+		if err := doAuthList(append([]string{"--file", akPath, "--json"}, args...), &out); err != nil {
+			t.Fatalf("auth list %v: %v", args, err)
+		}
+		var entries []authListEntry
+		if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+			t.Fatalf("unmarshal: %v\n%s", err, out.String())
+		}
+		return entries
+	}
+
+	if got := list("--group", "family"); len(got) != 2 || got[0].Group != "family" || got[1].Group != "family" {
+		t.Errorf("--group family = %+v", got)
+	}
+	if got := list("--group", ""); len(got) != 1 || got[0].PeerID != ids[3] {
+		t.Errorf(`--group "" = %+v, want only the ungrouped peer`, got)
+	}
+	if got := list("--ungrouped"); len(got) != 1 || got[0].PeerID != ids[3] {
+		t.Errorf("--ungrouped = %+v, want only the ungrouped peer", got)
+	}
+	if got := list("--comment-contains", "LAPTOP"); len(got) != 2 {
+		t.Errorf("--comment-contains LAPTOP = %+v, want 2 (case-insensitive)", got)
+	}
+	if got := list("--group", "family", "--comment-contains", "laptop"); len(got) != 1 || got[0].PeerID != ids[0] {
+		t.Errorf("--group family --comment-contains laptop = %+v", got)
+	}
+
+	var errOut bytes.Buffer
+	if err := doAuthList([]string{"--file", akPath, "--ungrouped", "--group", "family"}, &errOut); err == nil {
+		t.Error("--ungrouped with --group family: expected error")
+	}
+
+	// Table output: filtered count and the per-group footer.
+	var table bytes.Buffer
+	if err := doAuthList([]string{"--file", akPath, "--comment-contains", "laptop"}, &table); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	for _, want := range []string{"Authorized peers (2 of 4)", "By group: family 1, work 1"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("table output missing %q:\n%s", want, table.String())
+		}
+	}
+
+	table.Reset()
+	if err := doAuthList([]string{"--file", akPath}, &table); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	if !strings.Contains(table.String(), "By group: family 2, work 1, (no group) 1") {
+		t.Errorf("footer wrong:\n%s", table.String())
+	}
+
+	table.Reset()
+	if err := doAuthList([]string{"--file", akPath, "--group", "nobody"}, &table); err != nil {
+		t.Fatalf("table: %v", err)
+	}
+	if !strings.Contains(table.String(), "No authorized peers match (4 in total)") {
+		t.Errorf("no-match output:\n%s", table.String())
+	}
+}
+
 func TestDoAuthList_ViaConfig(t *testing.T) {
 	// Tests the resolveAuthKeysPathErr path through config resolution
 	// instead of the --file shortcut.
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// bwtestClientTimeout bounds the daemon request. The daemon caps one
+// direction of a test at 10 minutes, plus time to reach the peer.
+const bwtestClientTimeout = 15 * time.Minute
+
+func runBwtest(args []string) {
+	runWithJSON(doBwtest(args, os.Stdout))
+}
+
+// doBwtest measures throughput to a peer through the running daemon and
+// reports whether the path was direct or relayed.
+func doBwtest(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("bwtest", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	bytesFlag := fs.String("bytes", "100MB", "bytes to transfer per direction")
+	dirFlag := fs.String("direction", sdk.BandwidthBoth, "up, down or both")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true})); err != nil {
+		return err
+	}
+
+	errOut := func(err error) error {
+		if *jsonFlag {
+			return jsonErr(stdout, err)
+		}
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errOut(fmt.Errorf("usage: shurli bwtest <target> [--bytes 100MB] [--direction up|down|both] [--json]"))
+	}
+	target := fs.Arg(0)
+
+	if err := sdk.ValidateBandwidthDirection(*dirFlag); err != nil {
+		return errOut(err)
+	}
+	size, err := sdk.ParseByteSize(*bytesFlag)
+	if err != nil || size <= 0 {
+		return errOut(fmt.Errorf("invalid --bytes %q: want a size like 10MB or 1GB", *bytesFlag))
+	}
+	if size > sdk.MaxBandwidthTestBytes {
+		return errOut(fmt.Errorf("--bytes %s exceeds the %s limit", *bytesFlag, sdk.FormatBytes(sdk.MaxBandwidthTestBytes)))
+	}
+
+	client, err := daemon.NewClient(daemonSocketPath(), daemonCookiePath())
+	if err != nil {
+		return errOut(err)
+	}
+	client.SetTimeout(bwtestClientTimeout)
+
+	if *jsonFlag {
+		resp, err := client.BandwidthTest(target, *dirFlag, size)
+		if err != nil {
+			return errOut(err)
+		}
+		return writeJSON(stdout, resp)
+	}
+
+	text, err := client.BandwidthTestText(target, *dirFlag, size)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, text)
+	return nil
+}
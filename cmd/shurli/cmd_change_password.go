@@ -28,7 +28,7 @@ func runChangePassword(args []string) {
 		configDir = filepath.Dir(cfgFile)
 	}
 
-	keyPath := filepath.Join(configDir, "identity.key")
+	keyPath := filepath.Join(configDir, config.ProfileKeyFileName())
 
 	// Read current password.
 	currentPassword, err := readPassword("Current password: ", os.Stdout)
@@ -228,18 +228,18 @@ _shurli_completions() {
     local cur prev words cword
     _init_completion || return
 
-    local commands="init daemon proxy ping traceroute resolve whoami auth relay config invite join verify service plugin notify reconnect status recover change-password lock unlock session doctor completion man version help PLUGIN_COMMANDS_PLACEHOLDER"
+    local commands="init daemon proxy ping traceroute bwtest resolve whoami auth relay config invite join verify service plugin notify reconnect remote msg status history recover change-password lock unlock session doctor completion man version help PLUGIN_COMMANDS_PLACEHOLDER"
 
     local proxy_cmds="add list ls remove rm enable disable"
-    local daemon_cmds="start status stop ping services peers paths connect disconnect"
-    local auth_cmds="add list remove validate set-attr grant grants revoke extend delegate pouch audit"
-    local config_cmds="validate show set reload rollback apply confirm"
-    local relay_cmds="add list remove seeds show setup serve authorize deauthorize set-attr grant grants revoke extend list-peers verify info invite vault seal unseal seal-status config version zkp-setup zkp-test motd goodbye recover"
+    local daemon_cmds="start status health stop ping services peers paths inbound holepunch stats connect disconnect messages"
+    local auth_cmds="add list remove prune validate export import set-attr grant grants revoke extend delegate pouch audit"
+    local config_cmds="validate show set reload rollback diff apply confirm edit schema"
+    local relay_cmds="add list remove seeds show setup serve authorize deauthorize set-attr grant grants stats revoke extend list-peers verify selftest info invite vault seal unseal seal-status config version zkp-setup zkp-test motd goodbye recover"
     local relay_invite_cmds="create list revoke"
     local relay_vault_cmds="init seal unseal status change-password"
     local relay_motd_cmds="set clear status"
     local relay_goodbye_cmds="set retract shutdown status"
-    local relay_config_cmds="show validate rollback"
+    local relay_config_cmds="show validate rollback schema"
     local service_cmds="add list remove enable disable"
     local plugin_cmds="list enable disable info disable-all"
     local notify_cmds="test list"
@@ -248,14 +248,30 @@ _shurli_completions() {
     case "${words[1]}" in
         daemon)
             case "${words[2]}" in
-                status|services|peers|paths)
+                status|health|services|paths|inbound|holepunch)
                     COMPREPLY=($(compgen -W "--json" -- "$cur"))
                     return ;;
+                peers)
+                    if [[ "$prev" == "--sort" ]]; then
+                        COMPREPLY=($(compgen -W "latency lastseen peerid" -- "$cur"))
+                        return
+                    fi
+                    COMPREPLY=($(compgen -W "--all --peer --watched --direct --relayed --sort --format --json" -- "$cur"))
+                    return ;;
                 ping)
                     COMPREPLY=($(compgen -W "-c --interval --json" -- "$cur"))
                     return ;;
+                stop)
+                    COMPREPLY=($(compgen -W "--drain --timeout" -- "$cur"))
+                    return ;;
                 connect)
-                    COMPREPLY=($(compgen -W "--peer --service --listen" -- "$cur"))
+                    COMPREPLY=($(compgen -W "--peer --service --listen --proto --stdio" -- "$cur"))
+                    return ;;
+                messages)
+                    COMPREPLY=($(compgen -W "--clear --json" -- "$cur"))
+                    return ;;
+                stats)
+                    COMPREPLY=($(compgen -W "reset --json" -- "$cur"))
                     return ;;
                 start)
                     COMPREPLY=($(compgen -W "--config" -- "$cur"))
@@ -268,11 +284,17 @@ _shurli_completions() {
         auth)
             case "${words[2]}" in
                 add)
-                    COMPREPLY=($(compgen -W "--config --file --comment --role" -- "$cur"))
+                    COMPREPLY=($(compgen -W "--config --file --comment --role --ttl --expires" -- "$cur"))
                     return ;;
-                list|remove|validate)
+                list)
+                    COMPREPLY=($(compgen -W "--config --file --group --ungrouped --comment-contains --format --json" -- "$cur"))
+                    return ;;
+                remove|prune|validate|import)
                     COMPREPLY=($(compgen -W "--config --file" -- "$cur"))
                     return ;;
+                export)
+                    COMPREPLY=($(compgen -W "--config --file --json" -- "$cur"))
+                    return ;;
                 grant)
                     COMPREPLY=($(compgen -W "--duration --services --permanent --delegate" -- "$cur"))
                     return ;;
@@ -292,12 +314,15 @@ _shurli_completions() {
         config)
             case "${words[2]}" in
                 apply)
-                    COMPREPLY=($(compgen -W "--config --confirm-timeout" -- "$cur"))
+                    COMPREPLY=($(compgen -W "--config --confirm-timeout --dry-run" -- "$cur"))
+                    return ;;
+                diff)
+                    COMPREPLY=($(compgen -W "--config" -- "$cur"))
                     return ;;
                 set)
                     COMPREPLY=($(compgen -W "--config --duration" -- "$cur"))
                     return ;;
-                validate|show|rollback|confirm)
+                validate|show|rollback|confirm|edit)
                     COMPREPLY=($(compgen -W "--config" -- "$cur"))
                     return ;;
                 *)
@@ -308,17 +333,23 @@ _shurli_completions() {
         relay)
             case "${words[2]}" in
                 add)
-                    COMPREPLY=($(compgen -W "--config --peer-id" -- "$cur"))
+                    COMPREPLY=($(compgen -W "--config --peer-id --verify" -- "$cur"))
                     return ;;
-                list|info|seal|seal-status|version)
+                list|seal|seal-status|version)
                     COMPREPLY=($(compgen -W "--config" -- "$cur"))
                     return ;;
+                info)
+                    COMPREPLY=($(compgen -W "--config --json" -- "$cur"))
+                    return ;;
                 authorize|deauthorize|list-peers|grants)
                     COMPREPLY=($(compgen -W "--config --remote" -- "$cur"))
                     return ;;
                 grant)
                     COMPREPLY=($(compgen -W "--duration --services --permanent --remote" -- "$cur"))
                     return ;;
+                stats)
+                    COMPREPLY=($(compgen -W "--config --json --days --remote" -- "$cur"))
+                    return ;;
                 revoke)
                     COMPREPLY=($(compgen -W "--remote" -- "$cur"))
                     return ;;
@@ -342,6 +373,12 @@ _shurli_completions() {
                         create)
                             COMPREPLY=($(compgen -W "--ttl --expires --remote" -- "$cur"))
                             return ;;
+                        list)
+                            COMPREPLY=($(compgen -W "--all --remote" -- "$cur"))
+                            return ;;
+                        revoke)
+                            COMPREPLY=($(compgen -W "--remote" -- "$cur"))
+                            return ;;
                         *)
                             COMPREPLY=($(compgen -W "$relay_invite_cmds" -- "$cur"))
                             return ;;
@@ -369,6 +406,9 @@ _shurli_completions() {
                 zkp-test)
                     COMPREPLY=($(compgen -W "--auth-keys --keys-dir --relay --role" -- "$cur"))
                     return ;;
+                selftest)
+                    COMPREPLY=($(compgen -W "--size --timeout" -- "$cur"))
+                    return ;;
                 motd)
                     case "${words[3]}" in
                         set|clear|status)
@@ -397,7 +437,7 @@ _shurli_completions() {
         service)
             case "${words[2]}" in
                 add)
-                    COMPREPLY=($(compgen -W "--config --protocol" -- "$cur"))
+                    COMPREPLY=($(compgen -W "--config --protocol --require-verified" -- "$cur"))
                     return ;;
                 list|remove|enable|disable)
                     COMPREPLY=($(compgen -W "--config" -- "$cur"))
@@ -420,9 +460,12 @@ _shurli_completions() {
         notify)
             COMPREPLY=($(compgen -W "$notify_cmds" -- "$cur"))
             return ;;
-        reconnect)
+        reconnect|msg)
             COMPREPLY=($(compgen -W "--json" -- "$cur"))
             return ;;
+        remote)
+            COMPREPLY=($(compgen -W "status peers services paths inbound bandwidth relay-health auth reload ping api --peer --json" -- "$cur"))
+            return ;;
         recover)
             COMPREPLY=($(compgen -W "--relay --dir" -- "$cur"))
             return ;;
@@ -433,35 +476,52 @@ _shurli_completions() {
             COMPREPLY=($(compgen -W "refresh destroy" -- "$cur"))
             return ;;
         ping)
-            COMPREPLY=($(compgen -W "--config -c -n --interval --json --standalone" -- "$cur"))
+            COMPREPLY=($(compgen -W "--config -c -n --interval --json -a --audible --standalone --targets" -- "$cur"))
             return ;;
         traceroute)
             COMPREPLY=($(compgen -W "--config --json --standalone" -- "$cur"))
             return ;;
+        bwtest)
+            COMPREPLY=($(compgen -W "--bytes --direction --json" -- "$cur"))
+            return ;;
         resolve)
-            COMPREPLY=($(compgen -W "--config --json" -- "$cur"))
+            COMPREPLY=($(compgen -W "--config --json --no-cache --refresh" -- "$cur"))
             return ;;
         # PLUGIN_CASES_PLACEHOLDER
         proxy)
-            COMPREPLY=($(compgen -W "add list ls remove rm enable disable --config --standalone" -- "$cur"))
+            COMPREPLY=($(compgen -W "add list ls remove rm enable disable --config --standalone --no-cache --refresh" -- "$cur"))
             return ;;
         proxy\ list|proxy\ ls)
             COMPREPLY=($(compgen -W "--json" -- "$cur"))
             return ;;
-        whoami|verify|status)
+        whoami)
+            COMPREPLY=($(compgen -W "--config --addresses --fingerprint --json --watch-reachability --timeout" -- "$cur"))
+            return ;;
+        verify|status)
             COMPREPLY=($(compgen -W "--config" -- "$cur"))
             return ;;
         invite)
-            COMPREPLY=($(compgen -W "--config --as --ttl --non-interactive" -- "$cur"))
+            COMPREPLY=($(compgen -W "--config --as --ttl --non-interactive --qr" -- "$cur"))
             return ;;
         join)
             COMPREPLY=($(compgen -W "--config --as --non-interactive" -- "$cur"))
             return ;;
         init)
-            COMPREPLY=($(compgen -W "--dir --network" -- "$cur"))
+            if [[ "$prev" == "--template" ]]; then
+                COMPREPLY=($(compgen -W "home-node relay-client minimal server" -- "$cur"))
+                return
+            fi
+            COMPREPLY=($(compgen -W "--dir --network --template --from --as --force --user --skip-seed-confirm" -- "$cur"))
             return ;;
         doctor)
-            COMPREPLY=($(compgen -W "--fix" -- "$cur"))
+            COMPREPLY=($(compgen -W "--fix --json --config" -- "$cur"))
+            return ;;
+        history)
+            if [[ ${cword} -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "export peer" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "--format --config --json" -- "$cur"))
+            fi
             return ;;
         completion)
             if [[ ${cword} -eq 2 ]]; then
@@ -499,6 +559,7 @@ _shurli() {
         'proxy:Proxy management (add/list/remove/enable/disable)'
         'ping:P2P ping'
         'traceroute:P2P traceroute'
+        'bwtest:Measure throughput to a peer'
         'resolve:Resolve name to peer ID'
         # PLUGIN_COMMANDS_PLACEHOLDER
         'whoami:Show your peer ID'
@@ -512,7 +573,10 @@ _shurli() {
         'plugin:Manage plugins'
         'notify:Notification management'
         'reconnect:Clear backoffs and force redial'
+        'remote:Run daemon commands on another node'
+        'msg:Send a short text message to a peer'
         'status:Show local config and services'
+        'history:Export peer interaction history'
         'recover:Recover identity from seed phrase'
         'change-password:Change identity password'
         'lock:Lock daemon'
@@ -529,13 +593,18 @@ _shurli() {
     daemon_cmds=(
         'start:Start daemon in foreground'
         'status:Query running daemon'
+        'health:Liveness check (exits 1 if unhealthy)'
         'stop:Graceful shutdown'
         'ping:Ping a peer via daemon'
         'services:List services via daemon'
         'peers:List connected peers'
         'paths:Show connection paths'
+        'inbound:Show peers using local services'
+        'holepunch:Show hole punch outcomes per peer'
+        'stats:Reset session counters'
         'connect:Create a TCP proxy'
         'disconnect:Tear down a proxy'
+        'messages:Show received peer messages'
     )
 
     local -a auth_cmds
@@ -543,7 +612,10 @@ _shurli() {
         'add:Authorize a peer'
         'list:List authorized peers'
         'remove:Revoke a peer'
+        'prune:Remove expired peers'
         'validate:Validate authorized_keys format'
+        'export:Export authorized peers'
+        'import:Import peers from an export'
         'set-attr:Set peer attribute'
         'grant:Grant relay data access'
         'grants:List active grants'
@@ -561,8 +633,11 @@ _shurli() {
         'set:Set a config value'
         'reload:Reload config into running daemon'
         'rollback:Restore last-known-good config'
+        'diff:Show what applying a config would change'
         'apply:Apply config with auto-revert'
         'confirm:Confirm applied config'
+        'edit:Edit in $EDITOR, save only if valid'
+        'schema:Print a JSON Schema for editor validation'
     )
 
     local -a relay_cmds
@@ -579,6 +654,7 @@ _shurli() {
         'set-attr:Set peer attribute'
         'list-peers:List authorized peers'
         'verify:Verify a peer identity (SAS)'
+        'selftest:Test a circuit through the relay'
         'info:Show peer ID and multiaddrs'
         'invite:Manage invites'
         'vault:Manage relay vault'
@@ -594,6 +670,7 @@ _shurli() {
         'recover:Recover relay identity from seed'
         'grant:Grant time-limited data relay access'
         'grants:List active data relay grants'
+        'stats:Show data relayed per peer and group'
         'revoke:Revoke data relay access'
         'extend:Extend data relay grant'
     )
@@ -601,8 +678,8 @@ _shurli() {
     local -a relay_invite_cmds
     relay_invite_cmds=(
         'create:Generate an invite code'
-        'list:List active invites'
-        'revoke:Revoke an invite'
+        'list:List outstanding invites'
+        'revoke:Revoke an invite code or group'
     )
 
     local -a relay_vault_cmds
@@ -634,6 +711,7 @@ _shurli() {
         'show:Show resolved relay config'
         'validate:Validate relay config'
         'rollback:Restore last-known-good config'
+        'schema:Print a JSON Schema for editor validation'
     )
 
     local -a service_cmds
@@ -675,12 +753,20 @@ _shurli() {
                 _describe -t daemon_cmds 'daemon subcommand' daemon_cmds
             else
                 case "${words[3]}" in
-                    status|services|peers|paths)
+                    status|health|services|paths|inbound|holepunch)
                         _arguments '--json[Output as JSON]' ;;
+                    peers)
+                        _arguments '--all[Include DHT neighbors]' '--peer[Only this peer]:peer' '--watched[Only watched peers]' '--direct[Only direct connections]' '--relayed[Only relayed connections]' '--sort[Sort order]:order:(latency lastseen peerid)' '--format[Output format]:format:(table json yaml)' '--json[Output as JSON]' ;;
                     ping)
                         _arguments '-c[Number of pings]:count' '--interval[Ping interval (ms)]:ms' '--json[Output as JSON]' ;;
+                    stop)
+                        _arguments '--drain[Let open proxy connections finish first]' '--timeout[Longest drain wait]:duration' ;;
                     connect)
-                        _arguments '--peer[Peer name or ID]:peer' '--service[Service name]:service' '--listen[Local listen address]:addr' ;;
+                        _arguments '--peer[Peer name or ID]:peer' '--service[Service name]:service' '--listen[Local listen address]:addr' '--proto[Listener protocol]:proto:(tcp udp)' '--stdio[Bridge to stdin/stdout]' ;;
+                    messages)
+                        _arguments '--clear[Empty the inbox]' '--json[Output as JSON]' ;;
+                    stats)
+                        _arguments '1:action:(reset)' '--json[Output as JSON]' ;;
                     start)
                         _arguments '--config[Config file]:file:_files' ;;
                 esac
@@ -697,8 +783,10 @@ _shurli() {
                         _arguments '--duration[Extension duration]:duration' ;;
                     delegate)
                         _arguments '--to[Target peer]:peer' '--duration[Shorter duration]:duration' '--services[Fewer services]:services' '--delegate[Further delegation hops]:hops' ;;
+                    list)
+                        _arguments '--config[Config file]:file:_files' '--file[authorized_keys path]:file:_files' '--group[Only this group]:group' '--ungrouped[Only peers with no group]' '--comment-contains[Comment substring (case-insensitive)]:text' '--format[Output format]:format:(table json yaml)' '--json[Output as JSON]' ;;
                     add)
-                        _arguments '--config[Config file]:file:_files' '--file[authorized_keys path]:file:_files' '--comment[Peer comment]:comment' '--role[Peer role (admin/member)]:role:(admin member)' ;;
+                        _arguments '--config[Config file]:file:_files' '--file[authorized_keys path]:file:_files' '--comment[Peer comment]:comment' '--role[Peer role (admin/member)]:role:(admin member)' '--ttl[Authorize for this long]:duration' '--expires[Same as --ttl]:duration' ;;
                     *)
                         _arguments '--config[Config file]:file:_files' '--file[authorized_keys path]:file:_files' ;;
                 esac
@@ -712,7 +800,9 @@ _shurli() {
                     set)
                         _arguments '--config[Config file]:file:_files' '--duration[Timed receive mode duration]:duration' ;;
                     apply)
-                        _arguments '--config[Config file]:file:_files' '--confirm-timeout[Auto-revert timeout]:duration' ;;
+                        _arguments '--config[Config file]:file:_files' '--confirm-timeout[Auto-revert timeout]:duration' '--dry-run[Preview only, apply nothing]' ;;
+                    diff)
+                        _arguments '--config[Config file]:file:_files' ;;
                     *)
                         _arguments '--config[Config file]:file:_files' ;;
                 esac
@@ -724,17 +814,21 @@ _shurli() {
             else
                 case "${words[3]}" in
                     add)
-                        _arguments '--config[Config file]:file:_files' '--peer-id[Relay peer ID]:id' ;;
+                        _arguments '--config[Config file]:file:_files' '--peer-id[Relay peer ID]:id' '--verify[Dial relay to confirm its peer ID]' ;;
                     remove)
                         _arguments '--config[Config file]:file:_files' '--force[Force removal]' '-f[Force removal]' ;;
                     serve)
                         _arguments '--config[Config file]:file:_files' ;;
+                    info)
+                        _arguments '--config[Config file]:file:_files' '--json[Output as JSON]' ;;
                     setup)
                         _arguments '--dir[Relay directory]:dir:_directories' '--fresh[Non-interactive fresh setup]' '--non-interactive[Fail if prompts needed]' ;;
                     authorize|deauthorize|list-peers|grants)
                         _arguments '--config[Config file]:file:_files' '--remote[Relay multiaddr]:addr' ;;
                     grant)
                         _arguments '--duration[Grant duration]:duration' '--services[Service names]:services' '--permanent[No expiry]' '--remote[Relay multiaddr]:addr' ;;
+                    stats)
+                        _arguments '--config[Config file]:file:_files' '--json[Output as JSON]' '--days[Include daily rollups]' '--remote[Relay multiaddr]:addr' ;;
                     revoke)
                         _arguments '--remote[Relay multiaddr]:addr' ;;
                     extend)
@@ -748,6 +842,10 @@ _shurli() {
                             case "${words[4]}" in
                                 create)
                                     _arguments '--ttl[Code validity]:duration' '--expires[Auth expiry]:duration' '--remote[Relay multiaddr]:addr' ;;
+                                list)
+                                    _arguments '--all[Include used, revoked and expired invites]' '--remote[Relay multiaddr]:addr' ;;
+                                revoke)
+                                    _arguments '--remote[Relay multiaddr]:addr' '1:code or group ID:' ;;
                             esac
                         fi
                         ;;
@@ -772,6 +870,8 @@ _shurli() {
                         _arguments '--keys-dir[Output directory]:dir:_directories' '--force[Overwrite existing keys]' ;;
                     zkp-test)
                         _arguments '--auth-keys[authorized_keys path]:file:_files' '--keys-dir[ZKP keys directory]:dir:_directories' '--relay[Relay multiaddr]:addr' '--role[Role to prove]:role:(0 1 2)' ;;
+                    selftest)
+                        _arguments '--size[Test data size]:size' '--timeout[Overall time limit]:duration' ;;
                     motd)
                         if (( CURRENT == 4 )); then
                             _describe -t relay_motd_cmds 'motd subcommand' relay_motd_cmds
@@ -793,7 +893,7 @@ _shurli() {
             if (( CURRENT == 3 )); then
                 _describe -t service_cmds 'service subcommand' service_cmds
             else
-                _arguments '--config[Config file]:file:_files' '--protocol[Custom protocol ID]:protocol'
+                _arguments '--config[Config file]:file:_files' '--protocol[Custom protocol ID]:protocol' '--require-verified[Only allow verified peers]'
             fi
             ;;
         plugin)
@@ -812,11 +912,13 @@ _shurli() {
             fi
             ;;
         ping)
-            _arguments '--config[Config file]:file:_files' '-c[Number of pings]:count' '-n[Number of pings]:count' '--interval[Ping interval]:interval' '--json[Output as JSON]' '--standalone[Direct P2P mode]' ;;
+            _arguments '--config[Config file]:file:_files' '-c[Number of pings]:count' '-n[Number of pings]:count' '--interval[Ping interval]:interval' '--json[Output as JSON]' '-a[Ring bell on each reply]' '--audible[Ring bell on each reply]' '--standalone[Direct P2P mode]' '--targets[Comma-separated targets]:targets' ;;
         traceroute)
             _arguments '--config[Config file]:file:_files' '--json[Output as JSON]' '--standalone[Direct P2P mode]' ;;
+        bwtest)
+            _arguments '--bytes[Bytes per direction]:size' '--direction[Direction]:direction:(up down both)' '--json[Output as JSON]' ;;
         resolve)
-            _arguments '--config[Config file]:file:_files' '--json[Output as JSON]' ;;
+            _arguments '--config[Config file]:file:_files' '--json[Output as JSON]' '--no-cache[Hide cached addresses]' '--refresh[Drop cached addresses]' ;;
         # PLUGIN_CASES_PLACEHOLDER
         proxy)
             local -a proxy_cmds
@@ -828,15 +930,20 @@ _shurli() {
                 'disable:Disable a proxy'
             )
             _describe 'proxy command' proxy_cmds
-            _arguments '--config[Config file]:file:_files' '--standalone[Direct P2P mode]' ;;
-        whoami|verify|status)
+            _arguments '--config[Config file]:file:_files' '--standalone[Direct P2P mode]' '--no-cache[Skip the peer address cache]' '--refresh[Ignore cached peer addresses]' ;;
+        whoami)
+            _arguments '--config[Config file]:file:_files' '--addresses[Show advertised and peer-observed addresses]' '--fingerprint[Show a short identity fingerprint]' '--json[Output as JSON]' '--watch-reachability[Report reachability changes until stable]' '--timeout[Watch timeout]:duration:' ;;
+        verify|status)
             _arguments '--config[Config file]:file:_files' ;;
         invite)
-            _arguments '--config[Config file]:file:_files' '--as[Your node name]:name' '--ttl[Invite TTL]:duration' '--non-interactive[Machine-friendly output]' ;;
+            _arguments '--config[Config file]:file:_files' '--as[Your node name]:name' '--ttl[Invite TTL]:duration' '--non-interactive[Machine-friendly output]' '--qr[QR code for every invite code]' ;;
         join)
             _arguments '--config[Config file]:file:_files' '--as[Your node name]:name' '--non-interactive[Machine-friendly output]' ;;
-        reconnect)
+        reconnect|msg)
             _arguments '--json[Output as JSON]' ;;
+        remote)
+            _arguments '--peer[Remote node name or peer ID]:peer' '--json[Output as JSON]' \
+                '1:command:(status peers services paths inbound bandwidth relay-health auth reload ping api)' ;;
         recover)
             _arguments '--relay[Also recover relay vault]' '--dir[Config directory]:dir:_directories' ;;
         change-password)
@@ -849,9 +956,18 @@ _shurli() {
             fi
             ;;
         init)
-            _arguments '--dir[Config directory]:dir:_directories' '--network[DHT namespace]:namespace' ;;
+            _arguments '--dir[Config directory]:dir:_directories' '--network[DHT namespace]:namespace' '--template[Config preset]:template:(home-node relay-client minimal server)' '--from[Bootstrap bundle or URL]:bundle' '--as[Node name for the bundled invite]:name' '--force[Overwrite existing config]' '--user[Install in ~/.shurli]' '--skip-seed-confirm[Skip seed backup quiz]' ;;
         doctor)
-            _arguments '--fix[Auto-fix issues]'
+            _arguments '--fix[Auto-fix issues]' '--json[Output as JSON]' '--config[Config file]:file:_files'
+            ;;
+        history)
+            if (( CURRENT == 3 )); then
+                local -a history_cmds
+                history_cmds=('export:Export peer interaction history' 'peer:Recent connections to a peer')
+                _describe -t history_cmds 'history subcommand' history_cmds
+            else
+                _arguments '--format[Output format]:format:(csv json)' '--config[Config file]:file:_files' '--json[Output as JSON]'
+            fi
             ;;
         completion)
             if (( CURRENT == 3 )); then
@@ -902,6 +1018,7 @@ complete -c shurli -n __shurli_no_subcommand -a daemon      -d 'Daemon managemen
 complete -c shurli -n __shurli_no_subcommand -a proxy       -d 'Proxy management'
 complete -c shurli -n __shurli_no_subcommand -a ping        -d 'P2P ping'
 complete -c shurli -n __shurli_no_subcommand -a traceroute  -d 'P2P traceroute'
+complete -c shurli -n __shurli_no_subcommand -a bwtest      -d 'Measure throughput to a peer'
 complete -c shurli -n __shurli_no_subcommand -a resolve     -d 'Resolve name to peer ID'
 # PLUGIN_COMMANDS_PLACEHOLDER
 complete -c shurli -n __shurli_no_subcommand -a whoami      -d 'Show your peer ID'
@@ -915,6 +1032,8 @@ complete -c shurli -n __shurli_no_subcommand -a service     -d 'Manage local ser
 complete -c shurli -n __shurli_no_subcommand -a plugin      -d 'Manage plugins'
 complete -c shurli -n __shurli_no_subcommand -a notify      -d 'Notification management'
 complete -c shurli -n __shurli_no_subcommand -a reconnect   -d 'Clear backoffs and force redial'
+complete -c shurli -n __shurli_no_subcommand -a remote      -d 'Run daemon commands on another node'
+complete -c shurli -n __shurli_no_subcommand -a msg         -d 'Send a short text message to a peer'
 complete -c shurli -n __shurli_no_subcommand -a status      -d 'Show local config and services'
 complete -c shurli -n __shurli_no_subcommand -a recover         -d 'Recover identity from seed phrase'
 complete -c shurli -n __shurli_no_subcommand -a change-password -d 'Change identity password'
@@ -922,56 +1041,100 @@ complete -c shurli -n __shurli_no_subcommand -a lock            -d 'Lock daemon'
 complete -c shurli -n __shurli_no_subcommand -a unlock          -d 'Unlock daemon'
 complete -c shurli -n __shurli_no_subcommand -a session         -d 'Session token management'
 complete -c shurli -n __shurli_no_subcommand -a doctor      -d 'Check installation health'
+complete -c shurli -n __shurli_no_subcommand -a history     -d 'Export peer interaction history'
 complete -c shurli -n __shurli_no_subcommand -a completion  -d 'Generate shell completion script'
 complete -c shurli -n __shurli_no_subcommand -a man         -d 'Show manual page'
 complete -c shurli -n __shurli_no_subcommand -a version     -d 'Show version information'
 complete -c shurli -n __shurli_no_subcommand -a help        -d 'Show usage information'
+complete -c shurli -n __shurli_no_subcommand -l profile     -d 'Use a named profile (<name>.yaml)'
 
 # --- init ---
 complete -c shurli -n '__shurli_using_command init' -l dir     -d 'Config directory'
 complete -c shurli -n '__shurli_using_command init' -l network -d 'DHT namespace'
+complete -c shurli -n '__shurli_using_command init' -l template -d 'Config preset' -xa 'home-node relay-client minimal server'
+complete -c shurli -n '__shurli_using_command init' -l from    -d 'Bootstrap bundle or URL' -x
+complete -c shurli -n '__shurli_using_command init' -l as      -d 'Node name for the bundled invite' -x
+complete -c shurli -n '__shurli_using_command init' -l force   -d 'Overwrite existing config'
+complete -c shurli -n '__shurli_using_command init' -l user    -d 'Install in ~/.shurli'
+complete -c shurli -n '__shurli_using_command init' -l skip-seed-confirm -d 'Skip seed backup quiz'
 
 # --- daemon subcommands ---
 complete -c shurli -n '__shurli_using_command daemon' -a start      -d 'Start daemon'
 complete -c shurli -n '__shurli_using_command daemon' -a status     -d 'Query running daemon'
+complete -c shurli -n '__shurli_using_command daemon' -a health     -d 'Liveness check'
 complete -c shurli -n '__shurli_using_command daemon' -a stop       -d 'Graceful shutdown'
 complete -c shurli -n '__shurli_using_command daemon' -a ping       -d 'Ping a peer via daemon'
 complete -c shurli -n '__shurli_using_command daemon' -a services   -d 'List services via daemon'
 complete -c shurli -n '__shurli_using_command daemon' -a peers      -d 'List connected peers'
 complete -c shurli -n '__shurli_using_command daemon' -a paths      -d 'Show connection paths'
+complete -c shurli -n '__shurli_using_command daemon' -a inbound    -d 'Show peers using local services'
+complete -c shurli -n '__shurli_using_command daemon' -a holepunch  -d 'Show hole punch outcomes per peer'
+complete -c shurli -n '__shurli_using_command daemon' -a stats      -d 'Reset session counters'
 complete -c shurli -n '__shurli_using_command daemon' -a connect    -d 'Create a TCP proxy'
 complete -c shurli -n '__shurli_using_command daemon' -a disconnect -d 'Tear down a proxy'
+complete -c shurli -n '__shurli_using_command daemon' -a messages   -d 'Show received peer messages'
 
 complete -c shurli -n '__shurli_using_subcommand daemon status'   -l json -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand daemon health'   -l json -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_subcommand daemon services' -l json -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l json -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l all  -d 'Show all peers'
+complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l peer -d 'Only show this peer'
+complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l watched -d 'Only watched peers'
+complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l direct  -d 'Only direct connections'
+complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l relayed -d 'Only relayed connections'
+complete -c shurli -n '__shurli_using_subcommand daemon peers'    -l sort    -d 'Sort order' -xa 'latency lastseen peerid'
 complete -c shurli -n '__shurli_using_subcommand daemon paths'    -l json -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand daemon inbound'  -l json -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand daemon holepunch' -l json -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_subcommand daemon ping'     -s c    -d 'Number of pings'
 complete -c shurli -n '__shurli_using_subcommand daemon ping'     -l interval -d 'Ping interval (ms)'
 complete -c shurli -n '__shurli_using_subcommand daemon ping'     -l json -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand daemon stop'     -l drain   -d 'Let open proxy connections finish first'
+complete -c shurli -n '__shurli_using_subcommand daemon stop'     -l timeout -d 'Longest drain wait'
 complete -c shurli -n '__shurli_using_subcommand daemon connect'  -l peer    -d 'Peer name or ID'
 complete -c shurli -n '__shurli_using_subcommand daemon connect'  -l service -d 'Service name'
 complete -c shurli -n '__shurli_using_subcommand daemon connect'  -l listen  -d 'Local listen address'
+complete -c shurli -n '__shurli_using_subcommand daemon connect'  -l proto   -d 'Listener protocol' -xa 'tcp udp'
+complete -c shurli -n '__shurli_using_subcommand daemon connect'  -l stdio   -d 'Bridge to stdin/stdout'
 complete -c shurli -n '__shurli_using_subcommand daemon start'    -l config  -d 'Config file'
+complete -c shurli -n '__shurli_using_subcommand daemon messages' -l clear -d 'Empty the inbox'
+complete -c shurli -n '__shurli_using_subcommand daemon messages' -l json  -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand daemon stats'    -a reset -d 'Zero session counters'
+complete -c shurli -n '__shurli_using_subcommand daemon stats'    -l json -d 'Output as JSON'
 
 # --- auth subcommands ---
 complete -c shurli -n '__shurli_using_command auth' -a add      -d 'Authorize a peer'
 complete -c shurli -n '__shurli_using_command auth' -a list     -d 'List authorized peers'
 complete -c shurli -n '__shurli_using_command auth' -a remove   -d 'Revoke a peer'
+complete -c shurli -n '__shurli_using_command auth' -a prune    -d 'Remove expired peers'
 complete -c shurli -n '__shurli_using_command auth' -a validate -d 'Validate authorized_keys'
+complete -c shurli -n '__shurli_using_command auth' -a export   -d 'Export authorized peers'
+complete -c shurli -n '__shurli_using_command auth' -a import   -d 'Import peers from an export'
 complete -c shurli -n '__shurli_using_command auth' -a set-attr -d 'Set peer attribute'
 
 complete -c shurli -n '__shurli_using_subcommand auth add'      -l config  -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand auth add'      -l file    -d 'authorized_keys path'
 complete -c shurli -n '__shurli_using_subcommand auth add'      -l comment -d 'Peer comment'
 complete -c shurli -n '__shurli_using_subcommand auth add'      -l role    -d 'Peer role'
+complete -c shurli -n '__shurli_using_subcommand auth add'      -l ttl     -d 'Authorize for this long'
+complete -c shurli -n '__shurli_using_subcommand auth add'      -l expires -d 'Same as --ttl'
 complete -c shurli -n '__shurli_using_subcommand auth list'     -l config  -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand auth list'     -l file    -d 'authorized_keys path'
+complete -c shurli -n '__shurli_using_subcommand auth list'     -l group   -d 'Only this group'
+complete -c shurli -n '__shurli_using_subcommand auth list'     -l ungrouped -d 'Only peers with no group'
+complete -c shurli -n '__shurli_using_subcommand auth list'     -l comment-contains -d 'Comment substring (case-insensitive)'
 complete -c shurli -n '__shurli_using_subcommand auth remove'   -l config  -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand auth remove'   -l file    -d 'authorized_keys path'
+complete -c shurli -n '__shurli_using_subcommand auth prune'    -l config  -d 'Config file'
+complete -c shurli -n '__shurli_using_subcommand auth prune'    -l file    -d 'authorized_keys path'
 complete -c shurli -n '__shurli_using_subcommand auth validate' -l config  -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand auth validate' -l file    -d 'authorized_keys path'
+complete -c shurli -n '__shurli_using_subcommand auth export'   -l json    -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand auth export'   -l config  -d 'Config file'
+complete -c shurli -n '__shurli_using_subcommand auth export'   -l file    -d 'authorized_keys path'
+complete -c shurli -n '__shurli_using_subcommand auth import'   -l config  -d 'Config file'
+complete -c shurli -n '__shurli_using_subcommand auth import'   -l file    -d 'authorized_keys path'
 complete -c shurli -n '__shurli_using_command auth' -a grant    -d 'Grant relay data access'
 complete -c shurli -n '__shurli_using_command auth' -a grants   -d 'List active grants'
 complete -c shurli -n '__shurli_using_command auth' -a revoke   -d 'Revoke relay data access'
@@ -998,8 +1161,11 @@ complete -c shurli -n '__shurli_using_command config' -a show     -d 'Show resol
 complete -c shurli -n '__shurli_using_command config' -a set      -d 'Set a config value'
 complete -c shurli -n '__shurli_using_command config' -a reload   -d 'Reload config into running daemon'
 complete -c shurli -n '__shurli_using_command config' -a rollback -d 'Restore last-known-good config'
+complete -c shurli -n '__shurli_using_command config' -a diff     -d 'Show what applying a config would change'
 complete -c shurli -n '__shurli_using_command config' -a apply    -d 'Apply config with auto-revert'
 complete -c shurli -n '__shurli_using_command config' -a confirm  -d 'Confirm applied config'
+complete -c shurli -n '__shurli_using_command config' -a edit     -d 'Edit in $EDITOR, save only if valid'
+complete -c shurli -n '__shurli_using_command config' -a schema   -d 'Print a JSON Schema for editor validation'
 
 complete -c shurli -n '__shurli_using_subcommand config validate' -l config -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand config show'     -l config -d 'Config file'
@@ -1008,7 +1174,10 @@ complete -c shurli -n '__shurli_using_subcommand config set'      -l duration -d
 complete -c shurli -n '__shurli_using_subcommand config rollback' -l config -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand config apply'    -l config -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand config apply'    -l confirm-timeout -d 'Auto-revert timeout'
+complete -c shurli -n '__shurli_using_subcommand config apply'    -l dry-run -d 'Preview only, apply nothing'
+complete -c shurli -n '__shurli_using_subcommand config diff'     -l config -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand config confirm'  -l config -d 'Config file'
+complete -c shurli -n '__shurli_using_subcommand config edit'     -l config -d 'Config file'
 
 # --- relay subcommands ---
 complete -c shurli -n '__shurli_using_command relay' -a add         -d 'Add a relay server'
@@ -1023,6 +1192,7 @@ complete -c shurli -n '__shurli_using_command relay' -a deauthorize -d 'Remove p
 complete -c shurli -n '__shurli_using_command relay' -a set-attr    -d 'Set peer attribute'
 complete -c shurli -n '__shurli_using_command relay' -a list-peers  -d 'List authorized peers'
 complete -c shurli -n '__shurli_using_command relay' -a verify      -d 'Verify a peer identity (SAS)'
+complete -c shurli -n '__shurli_using_command relay' -a selftest    -d 'Test a circuit through the relay'
 complete -c shurli -n '__shurli_using_command relay' -a info        -d 'Show peer ID and multiaddrs'
 complete -c shurli -n '__shurli_using_command relay' -a invite      -d 'Manage invites'
 complete -c shurli -n '__shurli_using_command relay' -a vault       -d 'Manage relay vault'
@@ -1038,12 +1208,15 @@ complete -c shurli -n '__shurli_using_command relay' -a goodbye     -d 'Manage g
 complete -c shurli -n '__shurli_using_command relay' -a recover     -d 'Recover relay identity from seed'
 complete -c shurli -n '__shurli_using_command relay' -a grant       -d 'Grant time-limited data relay access'
 complete -c shurli -n '__shurli_using_command relay' -a grants      -d 'List active data relay grants'
+complete -c shurli -n '__shurli_using_command relay' -a stats       -d 'Show data relayed per peer and group'
 complete -c shurli -n '__shurli_using_command relay' -a revoke      -d 'Revoke data relay access'
 complete -c shurli -n '__shurli_using_command relay' -a extend      -d 'Extend data relay grant'
 
 complete -c shurli -n '__shurli_using_subcommand relay add'    -l config  -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand relay add'    -l peer-id -d 'Relay peer ID'
+complete -c shurli -n '__shurli_using_subcommand relay add'    -l verify  -d 'Dial relay to confirm its peer ID'
 complete -c shurli -n '__shurli_using_subcommand relay remove' -l config  -d 'Config file'
+complete -c shurli -n '__shurli_using_subcommand relay info'   -l json    -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_subcommand relay remove' -l force   -d 'Force removal'
 complete -c shurli -n '__shurli_using_subcommand relay remove' -s f       -d 'Force removal'
 complete -c shurli -n '__shurli_using_subcommand relay authorize'   -l config -d 'Config file'
@@ -1057,6 +1230,9 @@ complete -c shurli -n '__shurli_using_subcommand relay grant'       -l services
 complete -c shurli -n '__shurli_using_subcommand relay grant'       -l permanent -d 'Permanent grant'
 complete -c shurli -n '__shurli_using_subcommand relay grant'       -l remote    -d 'Relay multiaddr'
 complete -c shurli -n '__shurli_using_subcommand relay grants'      -l remote    -d 'Relay multiaddr'
+complete -c shurli -n '__shurli_using_subcommand relay stats'       -l json      -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_subcommand relay stats'       -l days      -d 'Include daily rollups'
+complete -c shurli -n '__shurli_using_subcommand relay stats'       -l remote    -d 'Relay multiaddr'
 complete -c shurli -n '__shurli_using_subcommand relay revoke'      -l remote    -d 'Relay multiaddr'
 complete -c shurli -n '__shurli_using_subcommand relay extend'      -l duration  -d 'New duration'
 complete -c shurli -n '__shurli_using_subcommand relay extend'      -l remote    -d 'Relay multiaddr'
@@ -1070,8 +1246,9 @@ complete -c shurli -n '__shurli_using_subcommand relay unseal' -l totp    -d 'Pr
 
 # relay invite sub-subcommands
 complete -c shurli -n '__shurli_using_subcommand relay invite' -a create -d 'Generate an invite code'
-complete -c shurli -n '__shurli_using_subcommand relay invite' -a list   -d 'List active invites'
-complete -c shurli -n '__shurli_using_subcommand relay invite' -a revoke -d 'Revoke an invite'
+complete -c shurli -n '__shurli_using_subcommand relay invite' -a list   -d 'List outstanding invites'
+complete -c shurli -n '__shurli_using_subcommand relay invite' -a revoke -d 'Revoke an invite code or group'
+complete -c shurli -n '__shurli_using_subcommand relay invite; and contains -- list (commandline -opc)' -l all -d 'Include used, revoked and expired invites'
 
 # relay vault sub-subcommands
 complete -c shurli -n '__shurli_using_subcommand relay vault' -a init   -d 'Initialize vault'
@@ -1095,6 +1272,7 @@ complete -c shurli -n '__shurli_using_subcommand relay goodbye' -a status   -d '
 complete -c shurli -n '__shurli_using_subcommand relay config' -a show     -d 'Show resolved relay config'
 complete -c shurli -n '__shurli_using_subcommand relay config' -a validate -d 'Validate relay config'
 complete -c shurli -n '__shurli_using_subcommand relay config' -a rollback -d 'Restore last-known-good config'
+complete -c shurli -n '__shurli_using_subcommand relay config' -a schema   -d 'Print a JSON Schema for editor validation'
 
 # relay zkp flags
 complete -c shurli -n '__shurli_using_subcommand relay zkp-setup' -l seed      -d 'BIP39 seed phrase'
@@ -1106,6 +1284,8 @@ complete -c shurli -n '__shurli_using_subcommand relay zkp-test'  -l keys-dir  -
 complete -c shurli -n '__shurli_using_subcommand relay zkp-test'  -l relay     -d 'Relay multiaddr'
 complete -c shurli -n '__shurli_using_subcommand relay zkp-test'  -l role      -d 'Role to prove (0/1/2)'
 complete -c shurli -n '__shurli_using_subcommand relay zkp-test'  -l seed      -d 'BIP39 seed phrase'
+complete -c shurli -n '__shurli_using_subcommand relay selftest'  -l size      -d 'Test data size'
+complete -c shurli -n '__shurli_using_subcommand relay selftest'  -l timeout   -d 'Overall time limit'
 
 # --- service subcommands ---
 complete -c shurli -n '__shurli_using_command service' -a add     -d 'Expose a local service'
@@ -1116,6 +1296,7 @@ complete -c shurli -n '__shurli_using_command service' -a disable -d 'Disable a
 
 complete -c shurli -n '__shurli_using_subcommand service add'     -l config   -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand service add'     -l protocol -d 'Custom protocol ID'
+complete -c shurli -n '__shurli_using_subcommand service add'     -l require-verified -d 'Only allow verified peers'
 complete -c shurli -n '__shurli_using_subcommand service list'    -l config   -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand service remove'  -l config   -d 'Config file'
 complete -c shurli -n '__shurli_using_subcommand service enable'  -l config   -d 'Config file'
@@ -1138,18 +1319,33 @@ complete -c shurli -n '__shurli_using_command notify' -a list -d 'Show configure
 # --- reconnect ---
 complete -c shurli -n '__shurli_using_command reconnect'   -l json       -d 'Output as JSON'
 
+# --- remote ---
+complete -c shurli -n '__shurli_using_command remote'      -l peer       -d 'Remote node name or peer ID'
+complete -c shurli -n '__shurli_using_command remote'      -l json       -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_command remote'      -a 'status peers services paths inbound bandwidth relay-health auth reload ping api'
+
+# --- msg ---
+complete -c shurli -n '__shurli_using_command msg'         -l json       -d 'Output as JSON'
+
 # --- standalone commands with flags ---
 complete -c shurli -n '__shurli_using_command ping'       -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command ping'       -s c          -d 'Number of pings'
 complete -c shurli -n '__shurli_using_command ping'       -s n          -d 'Number of pings'
 complete -c shurli -n '__shurli_using_command ping'       -l interval   -d 'Ping interval'
 complete -c shurli -n '__shurli_using_command ping'       -l json       -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_command ping'       -s a -l audible -d 'Ring bell on each reply'
 complete -c shurli -n '__shurli_using_command ping'       -l standalone -d 'Direct P2P mode'
+complete -c shurli -n '__shurli_using_command ping'       -l targets    -d 'Comma-separated targets'
 complete -c shurli -n '__shurli_using_command traceroute' -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command traceroute' -l json       -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_command traceroute' -l standalone -d 'Direct P2P mode'
+complete -c shurli -n '__shurli_using_command bwtest'     -l bytes      -d 'Bytes per direction'
+complete -c shurli -n '__shurli_using_command bwtest'     -l direction  -d 'Direction' -xa 'up down both'
+complete -c shurli -n '__shurli_using_command bwtest'     -l json       -d 'Output as JSON'
 complete -c shurli -n '__shurli_using_command resolve'    -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command resolve'    -l json       -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_command resolve'    -l no-cache   -d 'Hide cached addresses'
+complete -c shurli -n '__shurli_using_command resolve'    -l refresh    -d 'Drop cached addresses'
 complete -c shurli -n '__shurli_using_command proxy'      -a add        -d 'Create persistent proxy'
 complete -c shurli -n '__shurli_using_command proxy'      -a list       -d 'List all proxies'
 complete -c shurli -n '__shurli_using_command proxy'      -a remove     -d 'Remove a proxy'
@@ -1157,13 +1353,21 @@ complete -c shurli -n '__shurli_using_command proxy'      -a enable     -d 'Enab
 complete -c shurli -n '__shurli_using_command proxy'      -a disable    -d 'Disable a proxy'
 complete -c shurli -n '__shurli_using_command proxy'      -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command proxy'      -l standalone -d 'Direct P2P mode'
+complete -c shurli -n '__shurli_using_command proxy'      -l no-cache   -d 'Skip the peer address cache'
+complete -c shurli -n '__shurli_using_command proxy'      -l refresh    -d 'Ignore cached peer addresses'
 complete -c shurli -n '__shurli_using_command whoami'     -l config     -d 'Config file'
+complete -c shurli -n '__shurli_using_command whoami'     -l addresses  -d 'Show advertised and peer-observed addresses'
+complete -c shurli -n '__shurli_using_command whoami'     -l fingerprint -d 'Show a short identity fingerprint'
+complete -c shurli -n '__shurli_using_command whoami'     -l json       -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_command whoami'     -l watch-reachability -d 'Report reachability changes until stable'
+complete -c shurli -n '__shurli_using_command whoami'     -l timeout    -d 'Watch timeout'
 complete -c shurli -n '__shurli_using_command verify'     -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command status'     -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command invite'     -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command invite'     -l name       -d 'Peer name'
 complete -c shurli -n '__shurli_using_command invite'     -l ttl        -d 'Invite TTL'
 complete -c shurli -n '__shurli_using_command invite'     -l non-interactive -d 'Machine-friendly output'
+complete -c shurli -n '__shurli_using_command invite'     -l qr         -d 'QR code for every invite code'
 complete -c shurli -n '__shurli_using_command join'       -l config     -d 'Config file'
 complete -c shurli -n '__shurli_using_command join'       -l name       -d 'Peer name'
 complete -c shurli -n '__shurli_using_command join'       -l non-interactive -d 'Machine-friendly output'
@@ -1179,7 +1383,16 @@ complete -c shurli -n '__shurli_using_command session' -a refresh -d 'Rotate ses
 complete -c shurli -n '__shurli_using_command session' -a destroy -d 'Delete session token'
 
 # --- doctor ---
-complete -c shurli -n '__shurli_using_command doctor' -l fix -d 'Auto-fix issues'
+complete -c shurli -n '__shurli_using_command doctor' -l fix    -d 'Auto-fix issues'
+complete -c shurli -n '__shurli_using_command doctor' -l json   -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_command doctor' -l config -d 'Config file'
+
+# --- history ---
+complete -c shurli -n '__shurli_using_command history' -a export -d 'Export peer interaction history'
+complete -c shurli -n '__shurli_using_command history' -a peer   -d 'Recent connections to a peer'
+complete -c shurli -n '__shurli_using_command history' -l json   -d 'Output as JSON'
+complete -c shurli -n '__shurli_using_command history' -l format -d 'Output format (csv|json)'
+complete -c shurli -n '__shurli_using_command history' -l config -d 'Config file'
 
 # --- completion ---
 complete -c shurli -n '__shurli_using_command completion' -a 'bash zsh fish' -d 'Shell type'
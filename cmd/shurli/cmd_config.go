@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -35,8 +37,14 @@ func runConfig(args []string) {
 		runConfigRollback(args[1:])
 	case "apply":
 		runConfigApply(args[1:])
+	case "diff":
+		runConfigDiff(args[1:])
 	case "confirm":
 		runConfigConfirm(args[1:])
+	case "edit":
+		runConfigEdit(args[1:])
+	case "schema":
+		runConfigSchema(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n\n", args[0])
 		printConfigUsage()
@@ -75,6 +83,9 @@ func doConfigValidate(args []string, stdout io.Writer) error {
 		fmt.Fprintf(stdout, "FAIL: %s\n", err)
 		return fmt.Errorf("validation failed")
 	}
+	for _, w := range config.NodeConfigWarnings(cfg) {
+		fmt.Fprintf(stdout, "WARN: %s\n", w)
+	}
 
 	fmt.Fprintf(stdout, "OK: %s is valid\n", cfgFile)
 	return nil
@@ -211,28 +222,48 @@ var validConfigKeys = []string{
 	"network.force_cgnat",
 	"network.resource_limits_enabled",
 	"network.memory_limit",
+	"network.advertise_exclude",
+	"network.idle_connection_timeout",
+	"network.transports",
+	"network.ip_mode",
 	"relay.addresses",
 	"relay.reservation_interval",
+	"relay.enabled",
+	"relay.startup_wait",
+	"relay.preferred_region",
+	"relay.prefer_quic",
 	"discovery.rendezvous",
+	"discovery.channels",
 	"discovery.network",
 	"discovery.bootstrap_peers",
 	"discovery.dns_seed_domain",
 	"discovery.mdns_enabled",
 	"discovery.net_intel_enabled",
 	"discovery.announce_interval",
+	"discovery.require_bootstrap",
+	"discovery.reconnect_log_burst",
+	"discovery.peer_cache_ttl",
 	"security.authorized_keys_file",
 	"security.enable_connection_gating",
 	"security.invite_policy",
 	"security.zkp.enabled",
 	"security.zkp.srs_cache_dir",
 	"security.zkp.max_tree_depth",
+	"security.user_agent_policy.allow",
+	"security.user_agent_policy.deny",
+	"security.admin_peers",
+	"security.introduction_challenge",
 	"protocols.ping_pong.enabled",
 	"protocols.ping_pong.id",
 	"cli.allow_standalone",
 	"cli.color",
+	"control.max_proxies",
 	"telemetry.metrics.enabled",
 	"telemetry.metrics.listen_address",
 	"telemetry.audit.enabled",
+	"telemetry.on_isolated.after",
+	"telemetry.on_isolated.url",
+	"telemetry.on_isolated.command",
 	"peer_relay.enabled",
 	"peer_relay.resources.max_reservations",
 	"peer_relay.resources.max_circuits",
@@ -366,7 +397,7 @@ func doConfigSet(args []string, stdout io.Writer) error {
 	if strings.HasPrefix(key, "transfer.") {
 		pluginKey = strings.TrimPrefix(key, "transfer.")
 		configDir := filepath.Dir(cfgFile)
-		pluginCfg := filepath.Join(configDir, "plugins", "shurli.io", "official", "filetransfer", "config.yaml")
+		pluginCfg := filepath.Join(configDir, config.ProfileFileName("plugins"), "shurli.io", "official", "filetransfer", "config.yaml")
 		if _, statErr := os.Stat(pluginCfg); statErr == nil {
 			cfgFile = pluginCfg
 		}
@@ -547,13 +578,14 @@ func doConfigApply(args []string, stdout, stderr io.Writer) error {
 	fs.SetOutput(stderr)
 	configFlag := fs.String("config", "", "path to current config file")
 	timeout := fs.Duration("confirm-timeout", 5*time.Minute, "auto-revert timeout (e.g., 5m, 10m)")
+	dryRun := fs.Bool("dry-run", false, "validate and show the changes without applying them")
 	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
 		return err
 	}
 
 	remaining := fs.Args()
 	if len(remaining) < 1 {
-		return fmt.Errorf("usage: shurli config apply <new-config> [--config path] [--confirm-timeout 5m]")
+		return fmt.Errorf("usage: shurli config apply <new-config> [--config path] [--confirm-timeout 5m] [--dry-run]")
 	}
 	newConfigPath := remaining[0]
 
@@ -562,14 +594,13 @@ func doConfigApply(args []string, stdout, stderr io.Writer) error {
 		return fmt.Errorf("config error: %w", err)
 	}
 
-	// Validate the new config before applying
-	newCfg, err := config.LoadNodeConfig(newConfigPath)
-	if err != nil {
-		return fmt.Errorf("new config is invalid: %w", err)
+	// Show what is about to change before the auto-revert timer starts.
+	if err := previewConfigChange(cfgFile, newConfigPath, stdout); err != nil {
+		return err
 	}
-	config.ResolveConfigPaths(newCfg, filepath.Dir(newConfigPath))
-	if err := config.ValidateNodeConfig(newCfg); err != nil {
-		return fmt.Errorf("new config has validation errors: %w", err)
+	if *dryRun {
+		fmt.Fprintln(stdout, "Dry run: nothing applied.")
+		return nil
 	}
 
 	if err := config.ApplyCommitConfirmed(cfgFile, newConfigPath, *timeout); err != nil {
@@ -584,6 +615,81 @@ func doConfigApply(args []string, stdout, stderr io.Writer) error {
 	return nil
 }
 
+// previewConfigChange loads and validates the candidate config at newPath,
+// then prints how it differs from the config at cfgFile, followed by any
+// lockout warnings. It is the shared front half of config apply and diff.
+func previewConfigChange(cfgFile, newPath string, stdout io.Writer) error {
+	newCfg, err := config.LoadNodeConfig(newPath)
+	if err != nil {
+		return fmt.Errorf("new config is invalid: %w", err)
+	}
+
+	// Diff before path resolution so relative paths compare as written.
+	changes, warnings, diffErr := diffAgainstCurrent(cfgFile, newCfg)
+
+	config.ResolveConfigPaths(newCfg, filepath.Dir(newPath))
+	if err := config.ValidateNodeConfig(newCfg); err != nil {
+		return fmt.Errorf("new config has validation errors: %w", err)
+	}
+
+	switch {
+	case diffErr != nil:
+		fmt.Fprintf(stdout, "Cannot diff against current config: %v\n", diffErr)
+	case len(changes) == 0:
+		fmt.Fprintln(stdout, "No changes from current config.")
+	default:
+		fmt.Fprintf(stdout, "Changes (%d):\n", len(changes))
+		config.FormatChanges(stdout, changes)
+	}
+	fmt.Fprintln(stdout)
+	if len(warnings) > 0 {
+		config.FormatLockoutWarnings(stdout, warnings)
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
+
+func runConfigDiff(args []string) {
+	if err := doConfigDiff(args, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// doConfigDiff validates a candidate config and shows what applying it
+// would change. Nothing is written.
+func doConfigDiff(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("config diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFlag := fs.String("config", "", "path to current config file")
+	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: shurli config diff <new-config> [--config path]")
+	}
+
+	cfgFile, err := config.FindConfigFile(*configFlag)
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+	return previewConfigChange(cfgFile, fs.Arg(0), stdout)
+}
+
+// diffAgainstCurrent loads the config at cfgFile and returns its semantic
+// differences from newCfg, plus the lockout warnings they raise.
+func diffAgainstCurrent(cfgFile string, newCfg *config.NodeConfig) ([]config.Change, []string, error) {
+	oldCfg, err := config.LoadNodeConfig(cfgFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes, err := config.DiffNodeConfigs(oldCfg, newCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return changes, config.LockoutWarnings(oldCfg, newCfg), nil
+}
+
 func runConfigConfirm(args []string) {
 	if err := doConfigConfirm(args, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -612,6 +718,140 @@ func doConfigConfirm(args []string, stdout io.Writer) error {
 	return nil
 }
 
+func runConfigEdit(args []string) {
+	if err := doConfigEdit(args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// doConfigEdit opens a copy of the config in $VISUAL or $EDITOR and only
+// replaces the original once the edited copy loads and validates. A failed
+// edit leaves the original untouched and keeps the edited copy so the
+// changes aren't lost.
+func doConfigEdit(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("config edit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFlag := fs.String("config", "", "path to config file")
+	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
+		return err
+	}
+
+	cfgFile, err := config.FindConfigFile(*configFlag)
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+	original, err := os.ReadFile(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "shurli-config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(tmpPath)
+		}
+	}()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if err := runEditor(tmpPath); err != nil {
+		return fmt.Errorf("editor failed, %s left unchanged: %w", cfgFile, err)
+	}
+
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to read edited config: %w", err)
+	}
+	if bytes.Equal(edited, original) {
+		fmt.Fprintln(stdout, "No changes.")
+		return nil
+	}
+
+	// Editors that save by writing a new file and renaming it over the old
+	// one drop our 0600 mode; the loader would reject the copy for that.
+	os.Chmod(tmpPath, 0600)
+
+	// Validate exactly as the daemon would load it: relative key paths
+	// resolve against the real config directory, not the temp directory.
+	// Diff before path resolution so relative paths compare as written.
+	var changes []config.Change
+	var warnings []string
+	newCfg, err := config.LoadNodeConfig(tmpPath)
+	if err == nil {
+		changes, warnings, _ = diffAgainstCurrent(cfgFile, newCfg)
+		config.ResolveConfigPaths(newCfg, filepath.Dir(cfgFile))
+		err = config.ValidateNodeConfig(newCfg)
+	}
+	if err != nil {
+		keep = true
+		fmt.Fprintf(stdout, "FAIL: %s\n", err)
+		fmt.Fprintf(stdout, "Your edits were saved to %s\n", tmpPath)
+		return fmt.Errorf("edited config is invalid, %s left unchanged", cfgFile)
+	}
+
+	if len(changes) > 0 {
+		fmt.Fprintf(stdout, "Changes (%d):\n", len(changes))
+		config.FormatChanges(stdout, changes)
+	}
+	config.FormatLockoutWarnings(stdout, warnings)
+	if err := auth.WriteFilePreserveOwnership(cfgFile, edited, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	fmt.Fprintf(stdout, "Saved %s\n", cfgFile)
+	fmt.Fprintln(stdout, "Apply without restart: shurli config reload")
+	return nil
+}
+
+// runEditor opens path in $VISUAL, then $EDITOR, falling back to vi. The
+// variable may carry arguments (e.g. "code --wait").
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func runConfigSchema(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: shurli config schema > shurli.schema.json")
+		osExit(1)
+	}
+	if err := writeConfigSchema(config.NodeConfigSchema, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// writeConfigSchema prints a generated JSON Schema for editor validation.
+func writeConfigSchema(gen func() ([]byte, error), stdout io.Writer) error {
+	data, err := gen()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	_, err = fmt.Fprintln(stdout, string(data))
+	return err
+}
+
 func printConfigUsage() {
 	fmt.Println("Usage: shurli config <command> [options]")
 	fmt.Println()
@@ -621,8 +861,11 @@ func printConfigUsage() {
 	fmt.Println("  set      <key> <value> [--config path] [--duration 10m]    Set a config value (dotted key path)")
 	fmt.Println("  reload   [--json] [--status]                               Reload config into running daemon")
 	fmt.Println("  rollback [--config path]                                   Restore last-known-good config")
-	fmt.Println("  apply    <new-config> [--config path] [--confirm-timeout]  Apply config with auto-revert safety")
+	fmt.Println("  diff     <new-config> [--config path]                      Show what applying a config would change")
+	fmt.Println("  apply    <new-config> [--config path] [--confirm-timeout]  Apply config with auto-revert safety (--dry-run: preview only)")
 	fmt.Println("  confirm  [--config path]                                   Confirm applied config (cancel revert)")
+	fmt.Println("  edit     [--config path]                                   Edit in $EDITOR, save only if valid")
+	fmt.Println("  schema                                                     Print a JSON Schema for editor validation")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  shurli config set transfer.receive_mode ask")
@@ -630,4 +873,5 @@ func printConfigUsage() {
 	fmt.Println("  shurli config reload                          # apply without restart")
 	fmt.Println("  shurli config set network.force_private_reachability true")
 	fmt.Println("  shurli config set network.memory_limit 4G     # systemd MemoryMax")
+	fmt.Println("  shurli config schema > ~/.shurli/shurli.schema.json")
 }
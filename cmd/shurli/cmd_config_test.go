@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -332,6 +333,23 @@ func TestDoConfigApply(t *testing.T) {
 			},
 			wantOutput: "Applied",
 		},
+		{
+			name: "prints diff before applying",
+			setup: func(t *testing.T, dir string) []string {
+				cfgPath := writeValidConfig(t, dir)
+
+				newDir := filepath.Join(dir, "new")
+				os.MkdirAll(newDir, 0755)
+				newYAML := strings.Replace(validConfigYAML(), "test-network", "updated-network", 1)
+				newCfgPath := filepath.Join(newDir, "new-config.yaml")
+				os.WriteFile(newCfgPath, []byte(newYAML), 0600)
+				writeTestIdentityKey(t, newDir)
+				os.WriteFile(filepath.Join(newDir, "authorized_keys"), []byte(""), 0600)
+
+				return []string{"--config", cfgPath, newCfgPath}
+			},
+			wantOutput: "~ discovery.rendezvous: test-network -> updated-network",
+		},
 		{
 			name: "invalid new config returns error",
 			setup: func(t *testing.T, dir string) []string {
@@ -409,6 +427,49 @@ names: {}
 	}
 }
 
+func TestDoConfigDiff(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := writeValidConfig(t, dir)
+	original, _ := os.ReadFile(cfgPath)
+
+	newDir := filepath.Join(dir, "new")
+	os.MkdirAll(newDir, 0755)
+	newYAML := strings.Replace(validConfigYAML(), "enable_connection_gating: true", "enable_connection_gating: false", 1)
+	newCfgPath := filepath.Join(newDir, "new-config.yaml")
+	os.WriteFile(newCfgPath, []byte(newYAML), 0600)
+	writeTestIdentityKey(t, newDir)
+
+	var stdout, stderr bytes.Buffer
+	if err := doConfigDiff([]string{"--config", cfgPath, newCfgPath}, &stdout, &stderr); err != nil {
+		t.Fatalf("doConfigDiff: %v", err)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"~ security.enable_connection_gating: true -> false",
+		"WARNING: connection gating would be DISABLED",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// apply --dry-run shows the same preview and leaves the config alone.
+	stdout.Reset()
+	if err := doConfigApply([]string{"--config", cfgPath, "--dry-run", newCfgPath}, &stdout, &stderr); err != nil {
+		t.Fatalf("doConfigApply --dry-run: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "WARNING: connection gating") || !strings.Contains(out, "Dry run: nothing applied.") {
+		t.Errorf("dry-run output:\n%s", out)
+	}
+	if after, _ := os.ReadFile(cfgPath); !bytes.Equal(after, original) {
+		t.Error("--dry-run modified the config file")
+	}
+
+	if err := doConfigDiff([]string{"--config", cfgPath}, &stdout, &stderr); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Errorf("missing argument: err = %v, want usage error", err)
+	}
+}
+
 // ----- doConfigShow commit-confirmed pending path -----
 
 func TestDoConfigShow_WithPendingCommitConfirmed(t *testing.T) {
@@ -728,3 +789,92 @@ func TestLevenshtein(t *testing.T) {
 		}
 	}
 }
+
+// ----- doConfigEdit tests -----
+
+// fakeEditor writes a shell script that acts as $EDITOR: it runs body with
+// the file to edit in $1.
+func fakeEditor(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDoConfigEdit(t *testing.T) {
+	tests := []struct {
+		name       string
+		editor     string
+		wantErr    string
+		wantOutput string
+		wantSaved  bool // original replaced with the edit
+	}{
+		{
+			name:       "valid edit is saved",
+			editor:     `sed 's/test-network/edited-network/' "$1" > "$1.new" && mv "$1.new" "$1"`,
+			wantOutput: "~ discovery.rendezvous: test-network -> edited-network",
+			wantSaved:  true,
+		},
+		{
+			name:       "invalid edit is rejected",
+			editor:     `sed '/12D3KooW/d' "$1" > "$1.new" && mv "$1.new" "$1"`,
+			wantErr:    "edited config is invalid",
+			wantOutput: "Your edits were saved to",
+		},
+		{
+			name:    "broken YAML is rejected",
+			editor:  `echo '{{{{' >> "$1"`,
+			wantErr: "edited config is invalid",
+		},
+		{
+			name:       "unchanged file is a no-op",
+			editor:     `true`,
+			wantOutput: "No changes.",
+		},
+		{
+			name:    "editor failure aborts",
+			editor:  `sed 's/test-network/edited-network/' "$1" > "$1.new" && mv "$1.new" "$1"; exit 1`,
+			wantErr: "editor failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgPath := writeValidConfig(t, t.TempDir())
+			t.Setenv("VISUAL", "")
+			t.Setenv("EDITOR", fakeEditor(t, tt.editor))
+
+			var stdout bytes.Buffer
+			err := doConfigEdit([]string{"--config", cfgPath}, &stdout)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("output %q should contain %q", stdout.String(), tt.wantOutput)
+			}
+
+			data, _ := os.ReadFile(cfgPath)
+			saved := strings.Contains(string(data), "edited-network")
+			if saved != tt.wantSaved {
+				t.Errorf("original replaced = %v, want %v\n%s", saved, tt.wantSaved, data)
+			}
+			if !tt.wantSaved && string(data) != validConfigYAML() {
+				t.Errorf("original config was modified:\n%s", data)
+			}
+
+			// The kept copy is only for rejected edits; clean it up.
+			if _, kept, ok := strings.Cut(stdout.String(), "Your edits were saved to "); ok {
+				os.Remove(strings.TrimSpace(kept))
+			}
+		})
+	}
+}
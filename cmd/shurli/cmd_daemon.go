@@ -27,6 +27,8 @@ import (
 	"github.com/shurlinet/shurli/internal/grants"
 	"github.com/shurlinet/shurli/internal/macaroon"
 	"github.com/shurlinet/shurli/internal/notify"
+	"github.com/shurlinet/shurli/internal/output"
+	"github.com/shurlinet/shurli/internal/reputation"
 	"github.com/shurlinet/shurli/internal/watchdog"
 	"github.com/shurlinet/shurli/pkg/sdk"
 	"github.com/shurlinet/shurli/pkg/plugin"
@@ -46,6 +48,7 @@ func (rt *serveRuntime) PathTracker() *sdk.PathTracker             { return rt.p
 func (rt *serveRuntime) PathProtector() *sdk.PathProtector         { return rt.pathProtector }
 func (rt *serveRuntime) BandwidthTracker() *sdk.BandwidthTracker   { return rt.bwTracker }
 func (rt *serveRuntime) RelayHealth() *sdk.RelayHealth           { return rt.relayHealth }
+func (rt *serveRuntime) RelaySelector() *sdk.RelaySelector       { return rt.relaySelector }
 func (rt *serveRuntime) STUNResult() *sdk.STUNResult {
 	if rt.stunProber == nil {
 		return nil
@@ -59,7 +62,7 @@ func (rt *serveRuntime) IsRelaying() bool {
 	return rt.peerRelay.Enabled()
 }
 
-func (rt *serveRuntime) RelayAddresses() []string        { return rt.config.Relay.Addresses }
+func (rt *serveRuntime) RelayAddresses() []string        { return rt.config.Relay.ActiveAddresses() }
 func (rt *serveRuntime) RelayNameFromConfig(peerID string) string { return rt.config.Relay.RelayName(peerID) }
 func (rt *serveRuntime) DiscoveryNetwork() string         { return rt.config.Discovery.Network }
 func (rt *serveRuntime) GrantStore() *grants.Store              { return rt.grantStore }
@@ -69,6 +72,11 @@ func (rt *serveRuntime) GrantsAutoRefresh() bool                { return rt.conf
 func (rt *serveRuntime) GrantsMaxRefreshDuration() string       { return rt.config.Grants.MaxRefreshDuration }
 func (rt *serveRuntime) NotifyRouter() *notify.Router            { return rt.notifyRouter }
 func (rt *serveRuntime) PeerManager() *sdk.PeerManager        { return rt.peerManager }
+func (rt *serveRuntime) Messenger() *sdk.Messenger            { return rt.messenger }
+func (rt *serveRuntime) Advertiser() *sdk.RendezvousAdvertiser { return rt.advertiser }
+func (rt *serveRuntime) MDNSDiscovery() *sdk.MDNSDiscovery     { return rt.mdnsDiscovery }
+func (rt *serveRuntime) HealthChecks() []watchdog.CheckStatus  { return rt.watchdogState.Snapshot() }
+func (rt *serveRuntime) PeerHistory() *reputation.PeerHistory  { return rt.peerHistory }
 func (rt *serveRuntime) GrantCacheSnapshot() []*grants.GrantReceipt {
 	if rt.grantCache == nil {
 		return nil
@@ -84,7 +92,7 @@ func (rt *serveRuntime) RelayMOTDs() []daemon.MOTDInfo {
 	h := rt.network.Host()
 	var result []daemon.MOTDInfo
 
-	for _, addrStr := range rt.config.Relay.Addresses {
+	for _, addrStr := range rt.config.Relay.ActiveAddresses() {
 		maddr, err := ma.NewMultiaddr(addrStr)
 		if err != nil {
 			continue
@@ -151,17 +159,20 @@ func (rt *serveRuntime) GaterForHotReload() daemon.GaterReloader {
 		gater:        rt.gater,
 		authKeysPath: rt.authKeys,
 		peerManager:  rt.peerManager,
+		disconnect:   rt.disconnectDeauthorized,
 	}
 }
 
 // gaterReloader implements daemon.GaterReloader by re-reading the
 // authorized_keys file and updating the live connection gater.
 // Also syncs PeerManager's watchlist so newly authorized peers
-// are immediately reconnected.
+// are immediately reconnected, and disconnects peers that lost their
+// authorization (removed, or past their expires attribute).
 type gaterReloader struct {
 	gater        *auth.AuthorizedPeerGater
 	authKeysPath string
 	peerManager  *sdk.PeerManager // nil-safe
+	disconnect   func(peer.ID)    // nil-safe
 }
 
 func (g *gaterReloader) ReloadFromFile() error {
@@ -169,9 +180,18 @@ func (g *gaterReloader) ReloadFromFile() error {
 	if err != nil {
 		return fmt.Errorf("failed to reload authorized_keys: %w", err)
 	}
+	before := g.gater.GetAuthorizedPeerIDs()
 	g.gater.UpdateAuthorizedPeers(peers)
+	applyPeerRestrictions(g.gater, g.authKeysPath)
 	if g.peerManager != nil {
-		g.peerManager.SetWatchlist(g.gater.GetAuthorizedPeerIDs())
+		g.peerManager.SetWatchlist(g.gater.ActivePeerIDs())
+	}
+	if g.disconnect != nil {
+		for _, p := range before {
+			if !g.gater.IsAuthorized(p) {
+				g.disconnect(p)
+			}
+		}
 	}
 	return nil
 }
@@ -194,6 +214,11 @@ func (cr *configReloader) ReloadConfig() (*daemon.ConfigReloadResult, error) {
 	if err := config.ValidateNodeConfig(newCfg); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
+	for _, w := range config.NodeConfigWarnings(newCfg) {
+		slog.Warn("config: " + w)
+	}
+
+	oldPingPong := cr.rt.config.Protocols.PingPong
 
 	// Transfer config reload is now handled by the filetransfer plugin's
 	// OnConfigReload callback, triggered via registry.NotifyConfigReload()
@@ -218,9 +243,25 @@ func (cr *configReloader) ReloadConfig() (*daemon.ConfigReloadResult, error) {
 		}
 	}
 
+	// Services: expose, unexpose or update the ACL of whatever the
+	// services section changed, without touching unchanged services.
+	if cr.rt.network != nil {
+		for _, name := range cr.rt.reconcileServices(cr.rt.config.Services, newCfg.Services) {
+			result.Changed = append(result.Changed, "services."+name)
+		}
+	}
+
 	// Update the stored config pointer for future comparisons.
 	cr.rt.config = newCfg
 
+	// Ping-pong: attach or detach the handler to match the new config.
+	// SetPingPongEnabled reads the protocol ID from cr.rt.config, so this
+	// must follow the pointer update.
+	if pp := newCfg.Protocols.PingPong; pp != oldPingPong && cr.rt.network != nil {
+		cr.rt.SetPingPongEnabled(pp.Enabled)
+		result.Changed = append(result.Changed, "protocols.ping_pong")
+	}
+
 	if len(result.Changed) == 0 {
 		result.Changed = []string{} // empty slice, not nil (cleaner JSON)
 	}
@@ -230,11 +271,11 @@ func (cr *configReloader) ReloadConfig() (*daemon.ConfigReloadResult, error) {
 // --- Daemon paths ---
 
 func daemonSocketPath() string {
-	return filepath.Join(daemonConfigDir(), "shurli.sock")
+	return daemon.SocketPath(daemonConfigDir(), config.Profile())
 }
 
 func daemonCookiePath() string {
-	return filepath.Join(daemonConfigDir(), ".daemon-cookie")
+	return daemon.CookiePath(daemonConfigDir(), config.Profile())
 }
 
 // daemonConfigDir returns the directory where the daemon stores its socket and cookie.
@@ -256,7 +297,7 @@ func daemonConfigDir() string {
 
 func runDaemon(args []string) {
 	// If no subcommand or "start", run the daemon foreground.
-	// Flags (--pprof, --config) are passed through to runDaemonStart.
+	// Flags (--pprof, --config, --no-relay-reservation-wait, --no-stun, --insecure-config-url, --fix-perms) are passed through to runDaemonStart.
 	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
 		runDaemonStart(args)
 		return
@@ -267,8 +308,10 @@ func runDaemon(args []string) {
 		runDaemonStart(args[1:])
 	case "status":
 		runDaemonStatus(args[1:])
+	case "health":
+		runDaemonHealth(args[1:])
 	case "stop":
-		runDaemonStop()
+		runDaemonStop(args[1:])
 	case "ping":
 		runDaemonPing(args[1:])
 	case "services":
@@ -277,10 +320,28 @@ func runDaemon(args []string) {
 		runDaemonPeers(args[1:])
 	case "paths":
 		runDaemonPaths(args[1:])
+	case "inbound":
+		runDaemonInbound(args[1:])
+	case "holepunch":
+		runDaemonHolePunch(args[1:])
+	case "stats":
+		runDaemonStats(args[1:])
 	case "connect":
 		runDaemonConnect(args[1:])
 	case "disconnect":
 		runDaemonDisconnect(args[1:])
+	case "events":
+		runDaemonEvents(args[1:])
+	case "messages":
+		runDaemonMessages(args[1:])
+	case "install":
+		runDaemonInstall(args[1:])
+	case "uninstall":
+		runDaemonUninstall()
+	case "service-start":
+		runDaemonServiceStart()
+	case "service-stop":
+		runDaemonServiceStop()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown daemon subcommand: %s\n\n", args[0])
 		printDaemonUsage()
@@ -294,21 +355,44 @@ func printDaemonUsage() {
 	fmt.Println("  (no subcommand)  Start daemon in foreground")
 	fmt.Println("  start            Start daemon in foreground")
 	fmt.Println("  status [--json]  Show daemon status")
-	fmt.Println("  stop             Graceful shutdown")
+	fmt.Println("  health [--json]  Liveness check; exits 1 if unhealthy")
+	fmt.Println("  stop [--drain [--timeout 30s]]  Graceful shutdown (--drain lets open proxy connections finish)")
 	fmt.Println("  ping <peer> [-c N] [--interval 1s] [--json]")
 	fmt.Println("  services [--json]")
-	fmt.Println("  peers [--all] [--json]")
+	fmt.Println("  peers [--all] [--peer <name|id>] [--watched] [--direct|--relayed] [--sort latency|lastseen|peerid] [--json]")
 	fmt.Println("  paths [--json]")
+	fmt.Println("  inbound [--json]")
+	fmt.Println("  holepunch [--json]")
+	fmt.Println("  stats reset [--json]")
 	fmt.Println("  connect --peer <name> --service <svc> --listen <addr>")
 	fmt.Println("  disconnect <id>")
+	fmt.Println("  events [--since 5m|<RFC3339>] [--peer <name|id>] [--json]")
+	fmt.Println("  messages [--clear] [--json]")
+	fmt.Println()
+	fmt.Println("Start flags:")
+	fmt.Println("  --no-relay-reservation-wait  Don't wait for relay reservations at startup")
+	fmt.Println("  --no-stun                    Skip STUN probing (NAT type detection)")
+	fmt.Println("  --config <path|https-url>    Config file, or a URL to fetch it from")
+	fmt.Println("  --insecure-config-url        Allow --config to fetch over plain http")
+	fmt.Println("  --fix-perms                  Repair insecure key/authorized_keys permissions")
+	fmt.Println()
+	fmt.Println("OS service (launchd on macOS, Service Control Manager on Windows):")
+	fmt.Println("  install [--config <path>] [--no-start]")
+	fmt.Println("  uninstall")
+	fmt.Println("  service-start")
+	fmt.Println("  service-stop")
 }
 
 // --- Start daemon (foreground) ---
 
 func runDaemonStart(args []string) {
 	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
-	configFlag := fs.String("config", "", "path to config file")
+	configFlag := fs.String("config", "", "path to config file, or https URL to fetch it from")
+	insecureConfigURL := fs.Bool("insecure-config-url", false, "allow --config to fetch over plain http")
+	fixPerms := fs.Bool("fix-perms", false, "repair insecure permissions on the identity key and authorized_keys")
 	pprofAddr := fs.String("pprof", "", "enable pprof HTTP server (e.g. localhost:6060)")
+	noRelayWait := fs.Bool("no-relay-reservation-wait", false, "don't wait for relay reservations at startup (same as relay.startup_wait: 0s)")
+	noSTUN := fs.Bool("no-stun", false, "skip STUN probing and NAT type detection (same as network.disable_stun: true)")
 	fs.Parse(reorderFlags(fs, args))
 
 	fmt.Printf("shurli daemon %s (%s)\n", version, commit)
@@ -325,23 +409,34 @@ func runDaemonStart(args []string) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	rt, err := newServeRuntime(ctx, cancel, *configFlag, version)
+	config.SetInsecureConfigURL(*insecureConfigURL)
+	rt, err := newServeRuntime(ctx, cancel, *configFlag, version, *fixPerms)
 	if err != nil {
 		cancel()
 		fatal("Failed to start: %v", err)
 	}
+	if *noRelayWait {
+		var noWait time.Duration
+		rt.config.Relay.StartupWait = &noWait
+	}
+	if *noSTUN {
+		rt.config.Network.DisableSTUN = true
+	}
 
 	// Register protocol handlers BEFORE Bootstrap so they're ready when
 	// the relay fires reconnect-notifier on our connection. Without this,
 	// the relay tries to deliver peer introductions before the handler exists.
 	rt.SetupPingPong()
 	rt.SetupPeerNotify()
+	rt.SetupAuthChallenge()
 	rt.setupGrantReceiptHandler() // not gated by authKeys - any node can use relays
 	rt.SetupMOTDClient()
+	rt.SetupMessaging()
+	rt.SetupBandwidthTest()
 
 	// Check plugin directory permissions (for future WASM plugins).
 	// Layer 2 will make this a hard error; for now it's a warning.
-	pluginDir := filepath.Join(filepath.Dir(rt.configFile), "plugins")
+	pluginDir := filepath.Join(filepath.Dir(rt.configFile), config.ProfileFileName("plugins"))
 	if info, err := os.Stat(pluginDir); err == nil {
 		if info.Mode().Perm() != 0700 {
 			slog.Warn("plugin.dir-perms",
@@ -360,6 +455,7 @@ func runDaemonStart(args []string) {
 		Network:         rt.network,
 		ServiceRegistry: rt.network.ServiceRegistry(),
 		ConfigDir:       filepath.Dir(rt.configFile),
+		PluginsDir:      config.ProfileFileName("plugins"),
 		NameResolver:    rt.network.ResolveName,
 		PeerConnector:   rt.ConnectToPeer,
 		ScoreResolver:   scoreResolver,
@@ -405,7 +501,7 @@ func runDaemonStart(args []string) {
 	if pluginProvider.KeyDeriver != nil {
 		grantRootKey := pluginProvider.KeyDeriver("shurli/grants/root/v1")
 		grantHMACKey := pluginProvider.KeyDeriver("shurli/grants/hmac/v1")
-		grantsPath := filepath.Join(filepath.Dir(rt.configFile), "grants.json")
+		grantsPath := filepath.Join(filepath.Dir(rt.configFile), config.ProfileFileName("grants.json"))
 
 		gs, err := grants.Load(grantsPath, grantRootKey, grantHMACKey)
 		if err != nil {
@@ -439,7 +535,7 @@ func runDaemonStart(args []string) {
 
 		// Phase D1: integrity-chained audit log.
 		auditKey := pluginProvider.KeyDeriver("shurli/grants/audit/v1")
-		auditPath := filepath.Join(filepath.Dir(rt.configFile), "grant_audit.log")
+		auditPath := filepath.Join(filepath.Dir(rt.configFile), config.ProfileFileName("grant_audit.log"))
 		auditLog, err := grants.NewAuditLog(auditPath, auditKey)
 		if err != nil {
 			slog.Warn("grants: failed to init audit log, continuing without", "error", err)
@@ -469,7 +565,7 @@ func runDaemonStart(args []string) {
 		// Phase B: GrantPouch (received tokens) + delivery protocol + offline queue.
 		configDir := filepath.Dir(rt.configFile)
 		pouchHMACKey := pluginProvider.KeyDeriver("shurli/grants/pouch/v1")
-		pouchPath := filepath.Join(configDir, "grant_pouch.json")
+		pouchPath := filepath.Join(configDir, config.ProfileFileName("grant_pouch.json"))
 
 		pouch, err := grants.LoadPouch(pouchPath, pouchHMACKey)
 		if err != nil {
@@ -481,7 +577,7 @@ func runDaemonStart(args []string) {
 		rt.grantPouch = pouch
 
 		queueHMACKey := pluginProvider.KeyDeriver("shurli/grants/queue/v1")
-		queuePath := filepath.Join(configDir, "grant_delivery_queue.json")
+		queuePath := filepath.Join(configDir, config.ProfileFileName("grant_delivery_queue.json"))
 		queueTTL := grants.DefaultDeliveryQueueTTL
 		if rt.config.Grants.DeliveryQueueTTL != "" {
 			if parsed, err := grants.ParseDurationExtended(rt.config.Grants.DeliveryQueueTTL); err == nil && parsed > 0 {
@@ -517,7 +613,7 @@ func runDaemonStart(args []string) {
 		// Grant receipt cache: client-side cache of relay grant receipts.
 		// HKDF domain "grant-cache/v1" for file integrity (H10: separate from relay receipt key).
 		cacheHMACKey := pluginProvider.KeyDeriver("grant-cache/v1")
-		cachePath := filepath.Join(configDir, "grant_cache.json")
+		cachePath := filepath.Join(configDir, config.ProfileFileName("grant_cache.json"))
 		gc, gcErr := grants.LoadGrantCache(cachePath, cacheHMACKey)
 		if gcErr != nil {
 			slog.Error("grants: failed to load receipt cache, starting empty", "error", gcErr)
@@ -716,6 +812,20 @@ func runDaemonStart(args []string) {
 	notifyRouter.Start()
 	rt.notifyRouter = notifyRouter
 
+	// Stream auth decisions for authorized peers to /v1/events subscribers.
+	// Decisions for unknown peers stay in metrics and the audit log set up
+	// in serve setup: internet scanners would otherwise flood the shared
+	// event history.
+	if rt.gater != nil {
+		router := notifyRouter
+		rt.gater.SetKnownPeerDecisionCallback(func(pid peer.ID, result string) {
+			router.Record(notify.NewEvent(notify.EventAuthDecision, notify.SeverityInfo, pid.String(), "", "inbound connection "+result).
+				WithMetadata("peer", pid.String()[:16]+"...").
+				WithMetadata("direction", "inbound").
+				WithMetadata("result", result))
+		})
+	}
+
 	// Phase D3: wire rate limiter notification callback now that router exists.
 	if rt.opsRateLimiter != nil {
 		router := notifyRouter
@@ -735,6 +845,26 @@ func runDaemonStart(args []string) {
 		rt.network.ServiceRegistry().SetRelayGrantChecker(rt.grantCache)
 	}
 
+	// Verified-only services (require_verified) consult authorized_keys on
+	// each stream, so 'shurli verify' takes effect without a restart.
+	if rt.authKeys != "" {
+		authKeys := rt.authKeys
+		rt.network.ServiceRegistry().SetVerifiedChecker(func(p peer.ID) bool {
+			return auth.IsPeerVerified(authKeys, p)
+		})
+	}
+
+	// Streams refused by per-service peer limits go to the audit log.
+	if rt.audit != nil {
+		audit := rt.audit
+		rt.network.ServiceRegistry().SetServiceRejectHook(func(service string, p peer.ID, reason string) {
+			audit.ServiceLimitExceeded(p.String(), service, reason)
+		})
+	}
+
+	// Config hooks: peer connect/disconnect and service access commands.
+	rt.StartHooks()
+
 	// All Set* callbacks configured. Seal the registry to enforce the
 	// set-once-at-startup contract. Any future Set* call will panic.
 	rt.network.ServiceRegistry().Seal()
@@ -773,6 +903,7 @@ func runDaemonStart(args []string) {
 	srv := daemon.NewServer(rt, socketPath, cookiePath, version)
 	srv.SetInstrumentation(rt.metrics, rt.audit)
 	srv.SetRegistry(pluginRegistry)
+	srv.SetMaxProxies(rt.config.Control.MaxProxiesOrDefault())
 
 	// Persistent proxy store (Item #24).
 	configDir := filepath.Dir(rt.configFile)
@@ -794,6 +925,21 @@ func runDaemonStart(args []string) {
 	// F1: Subscribe to libp2p peer connectivity events for proxy state management.
 	rt.startProxyEventLoop(srv)
 
+	// Remote administration over P2P, only when admin peers are configured.
+	// IDs were validated when the config was loaded.
+	if len(rt.config.Security.AdminPeers) > 0 {
+		var admins []peer.ID
+		for _, id := range rt.config.Security.AdminPeers {
+			if pid, err := peer.Decode(id); err == nil {
+				admins = append(admins, pid)
+			}
+		}
+		adminHandler := daemon.NewRemoteAdminHandler(srv, admins)
+		rt.Network().Host().SetStreamHandler(daemon.AdminProtocol, adminHandler.HandleStream)
+		slog.Info("remote admin protocol registered", "protocol", daemon.AdminProtocol, "admin_peers", len(admins))
+		fmt.Printf("Remote admin: enabled for %d admin peer(s)\n", len(admins))
+	}
+
 
 	// Start metrics endpoint (no-op if telemetry disabled)
 	rt.StartMetricsServer()
@@ -814,23 +960,53 @@ func runDaemonStart(args []string) {
 
 	rt.StartStatusPrinter()
 	rt.StartDHTHealthCheck()
+	rt.StartAuthExpirySweep()
+	rt.StartScheduleSweep()
+	rt.StartIntroductionChallenges()
+	rt.StartIsolationAlert()
 
 	// SIGUSR1 triggers a read-only diagnostic snapshot (see cmd_daemon_diag.go).
 	stopDiag := installDiagSignalHandler(rt)
 	defer stopDiag()
 
-	// Wait for signal or API-initiated shutdown
+	// Wait for signal, API-initiated shutdown, or a Windows service stop
+	// request (nil channel elsewhere).
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	svcStop, svcStopped := daemonServiceControl()
+	defer svcStopped()
 
 	select {
 	case sig := <-sigCh:
 		fmt.Printf("\nReceived %s, shutting down...\n", sig)
 	case <-srv.ShutdownCh():
 		fmt.Println("\nShutdown requested via API")
+	case <-svcStop:
+		fmt.Println("\nShutdown requested by service manager")
 	case <-ctx.Done():
 	}
 
+	// Draining stop (daemon stop --drain): refuse new proxy connections and
+	// relay reservations, let open ones finish. A signal cuts it short.
+	if timeout := srv.DrainTimeout(); timeout > 0 {
+		fmt.Printf("Draining open connections (up to %s, Ctrl+C to stop now)...\n", timeout)
+		if rt.peerRelay != nil {
+			rt.peerRelay.SetDraining(true)
+		}
+		drainCtx, drainCancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-sigCh:
+				drainCancel()
+			case <-svcStop:
+				drainCancel()
+			case <-drainCtx.Done():
+			}
+		}()
+		srv.Drain(drainCtx, timeout)
+		drainCancel()
+	}
+
 	// P9 fix: stop plugins BEFORE HTTP server so in-flight handlers complete
 	// before plugin resources are torn down. P10 fix: global shutdown watchdog.
 	shutdownDone := make(chan struct{})
@@ -915,13 +1091,74 @@ func runDaemonStatus(args []string) {
 	}
 }
 
-func runDaemonStop() {
+func runDaemonHealth(args []string) {
+	fs := flag.NewFlagSet("daemon health", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fs.Parse(reorderFlags(fs, args))
+
+	c := daemonClient()
+	resp, err := c.Health()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(resp)
+	} else {
+		state := "ok"
+		switch {
+		case resp.Draining:
+			state = "draining"
+		case !resp.OK:
+			state = "unhealthy"
+		}
+		fmt.Printf("%s: uptime %ds, %d peers, relay connected: %v\n",
+			state, resp.UptimeSeconds, resp.PeerCount, resp.RelayConnected)
+		for _, c := range resp.Checks {
+			if c.Healthy {
+				continue
+			}
+			fmt.Printf("  %s failing x%d: %s", c.Name, c.ConsecutiveFailures, c.LastError)
+			if c.RecoveryAttempts > 0 {
+				fmt.Printf(" (%d recovery attempts)", c.RecoveryAttempts)
+			}
+			fmt.Println()
+		}
+	}
+	if !resp.OK {
+		osExit(1)
+	}
+}
+
+func runDaemonStop(args []string) {
+	fs := flag.NewFlagSet("daemon stop", flag.ExitOnError)
+	drainFlag := fs.Bool("drain", false, "refuse new proxy connections and let open ones finish before stopping")
+	timeoutFlag := fs.Duration("timeout", daemon.DefaultDrainTimeout, "longest to wait for open connections with --drain")
+	fs.Parse(reorderFlags(fs, args))
+
+	if !*drainFlag {
+		c := daemonClient()
+		if err := c.Shutdown(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		fmt.Println("Shutdown requested.")
+		return
+	}
+
+	if *timeoutFlag <= 0 || *timeoutFlag > daemon.MaxDrainTimeout {
+		fmt.Fprintf(os.Stderr, "Error: --timeout must be between 0 and %s\n", daemon.MaxDrainTimeout)
+		osExit(1)
+	}
 	c := daemonClient()
-	if err := c.Shutdown(); err != nil {
+	if err := c.ShutdownDrain(*timeoutFlag); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		osExit(1)
 	}
-	fmt.Println("Shutdown requested.")
+	fmt.Printf("Drain requested: the daemon stops once open connections finish (at most %s).\n", *timeoutFlag)
 }
 
 func runDaemonPing(args []string) {
@@ -989,23 +1226,50 @@ func runDaemonServices(args []string) {
 
 func runDaemonPeers(args []string) {
 	fs := flag.NewFlagSet("daemon peers", flag.ExitOnError)
-	jsonFlag := fs.Bool("json", false, "output as JSON")
+	outFlags := output.Register(fs)
 	allFlag := fs.Bool("all", false, "show all connected peers (including DHT/IPFS neighbors)")
+	peerFlag := fs.String("peer", "", "only show this peer (name or ID)")
+	watchedFlag := fs.Bool("watched", false, "only show watched (authorized) peers, including disconnected ones")
+	directFlag := fs.Bool("direct", false, "only show peers connected directly")
+	relayedFlag := fs.Bool("relayed", false, "only show peers connected through a relay")
+	sortFlag := fs.String("sort", "", "sort by latency, lastseen, or peerid (default peerid)")
 	fs.Parse(reorderFlags(fs, args))
+	format, err := outFlags.Format()
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	opts := daemon.PeerListOptions{
+		All:     *allFlag,
+		Peer:    *peerFlag,
+		Watched: *watchedFlag,
+		Sort:    *sortFlag,
+	}
+	switch {
+	case *directFlag && *relayedFlag:
+		fatal("--direct and --relayed are mutually exclusive")
+	case *directFlag:
+		opts.Path = daemon.PeerPathDirect
+	case *relayedFlag:
+		opts.Path = daemon.PeerPathRelayed
+	}
+	switch opts.Sort {
+	case "", daemon.PeerSortLatency, daemon.PeerSortLastSeen, daemon.PeerSortPeerID:
+	default:
+		fatal("invalid --sort value %q (use latency, lastseen, or peerid)", opts.Sort)
+	}
 
 	c := daemonClient()
 
-	if *jsonFlag {
-		resp, err := c.Peers(*allFlag)
+	if format != output.Table {
+		resp, err := c.Peers(opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			osExit(1)
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		enc.Encode(resp)
+		output.Write(os.Stdout, format, resp)
 	} else {
-		text, err := c.PeersText(*allFlag)
+		text, err := c.PeersText(opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			osExit(1)
@@ -1040,25 +1304,119 @@ func runDaemonPaths(args []string) {
 	}
 }
 
+// runDaemonInbound lists remote peers currently using this node's services.
+func runDaemonInbound(args []string) {
+	fs := flag.NewFlagSet("daemon inbound", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fs.Parse(reorderFlags(fs, args))
+
+	c := daemonClient()
+
+	if *jsonFlag {
+		resp, err := c.Inbound()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(resp)
+	} else {
+		text, err := c.InboundText()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		if text == "" {
+			fmt.Println("No active inbound service streams.")
+			return
+		}
+		fmt.Print(text)
+	}
+}
+
+func runDaemonHolePunch(args []string) {
+	fs := flag.NewFlagSet("daemon holepunch", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fs.Parse(reorderFlags(fs, args))
+
+	c := daemonClient()
+
+	if *jsonFlag {
+		resp, err := c.HolePunch()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(resp)
+	} else {
+		text, err := c.HolePunchText()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		fmt.Print(text)
+	}
+}
+
 func runDaemonConnect(args []string) {
 	fs := flag.NewFlagSet("daemon connect", flag.ExitOnError)
 	peerFlag := fs.String("peer", "", "peer name or ID")
 	serviceFlag := fs.String("service", "", "service name")
 	listenFlag := fs.String("listen", "", "local listen address (e.g. 127.0.0.1:2222)")
+	protoFlag := fs.String("proto", "tcp", "local listener protocol: tcp or udp")
+	stdioFlag := fs.Bool("stdio", false, "bridge the service to stdin/stdout instead of listening")
 	fs.Parse(reorderFlags(fs, args))
 
-	if *peerFlag == "" || *serviceFlag == "" || *listenFlag == "" {
-		fmt.Fprintln(os.Stderr, "Usage: shurli daemon connect --peer <name> --service <svc> --listen <addr>")
+	const usage = "Usage: shurli daemon connect --peer <name> --service <svc> (--listen <addr> [--proto tcp|udp] | --stdio)"
+	if *peerFlag == "" || *serviceFlag == "" || (*listenFlag == "") != *stdioFlag {
+		fmt.Fprintln(os.Stderr, usage)
+		osExit(1)
+	}
+	if *protoFlag != "tcp" && *protoFlag != "udp" {
+		fmt.Fprintf(os.Stderr, "Error: --proto must be tcp or udp, got %q\n", *protoFlag)
 		osExit(1)
 	}
 
 	c := daemonClient()
-	resp, err := c.Connect(*peerFlag, *serviceFlag, *listenFlag)
+	if *stdioFlag {
+		conn, _, err := c.ConnectStdio(context.Background(), *peerFlag, *serviceFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		if err := bridgeStdio(conn, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		return
+	}
+
+	resp, err := c.ConnectProto(*peerFlag, *serviceFlag, *listenFlag, *protoFlag)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		osExit(1)
 	}
-	fmt.Printf("Proxy created: %s -> %s:%s (listen: %s)\n", resp.ID, *peerFlag, *serviceFlag, resp.ListenAddress)
+	listen := resp.ListenAddress
+	if resp.Proto == "udp" {
+		listen += "/udp"
+	}
+	fmt.Printf("Proxy created: %s -> %s:%s (listen: %s)\n", resp.ID, *peerFlag, *serviceFlag, listen)
+}
+
+// bridgeStdio pipes stdin to a stdio proxy and the proxy to stdout. End of
+// stdin half-closes the stream so the service can still reply; the bridge
+// returns once the service stops sending, whether or not stdin is done.
+func bridgeStdio(conn sdk.HalfCloseConn, stdin io.Reader, stdout io.Writer) error {
+	defer conn.Close()
+	go func() {
+		io.Copy(conn, stdin)
+		conn.CloseWrite()
+	}()
+	_, err := io.Copy(stdout, conn)
+	return err
 }
 
 func runDaemonDisconnect(args []string) {
@@ -1122,3 +1480,67 @@ func notifyEventMessage(eventType string, meta map[string]string) string {
 		return eventType
 	}
 }
+
+func runDaemonEvents(args []string) {
+	args = reorderArgs(args, map[string]bool{"json": true})
+
+	fs := flag.NewFlagSet("daemon events", flag.ExitOnError)
+	sinceFlag := fs.String("since", "", "replay buffered events from this long ago (e.g. 5m) or since an RFC3339 timestamp")
+	jsonFlag := fs.Bool("json", false, "output one JSON event per line")
+	peerFlag := fs.String("peer", "", "only show events about this peer (name or ID)")
+	fs.Parse(reorderFlags(fs, args))
+
+	since, err := parseEventsSince(*sinceFlag, time.Now())
+	if err != nil {
+		fatal("%v", err)
+	}
+
+	c := daemonClient()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	err = c.Events(ctx, since, *peerFlag, func(e notify.Event) error {
+		if *jsonFlag {
+			return enc.Encode(e)
+		}
+		_, err := fmt.Fprintln(os.Stdout, formatEventLine(e))
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// parseEventsSince accepts a duration ("5m", "2h") counted back from now,
+// or an absolute RFC3339 timestamp. An empty value means live events only.
+func parseEventsSince(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("--since duration must be positive, got %s", v)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration (5m) or RFC3339 timestamp", v)
+	}
+	return t, nil
+}
+
+// formatEventLine renders an event as a single human-readable line.
+func formatEventLine(e notify.Event) string {
+	who := e.PeerName
+	if who == "" && e.PeerID != "" {
+		who = truncateID(e.PeerID)
+	}
+	if who == "" {
+		who = "-"
+	}
+	return fmt.Sprintf("%s  %-4s  %-20s  %-16s  %s",
+		e.Timestamp.Local().Format("2006-01-02 15:04:05"), e.Severity, e.Type, who, e.Message)
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/libp2p/go-libp2p/core/protocol"
 	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
 	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
 // diagSnapshot writes a read-only snapshot of daemon state to w. It exists
@@ -339,7 +341,7 @@ func writePeerstore(w io.Writer, nw libnet.Network, ps peerstoreLike, self peer.
 		liveKeys := make(map[string]struct{}, len(conns))
 		for _, c := range conns {
 			rm := c.RemoteMultiaddr()
-			if strings.Contains(rm.String(), "p2p-circuit") {
+			if sdk.ClassifyAddr(rm) == sdk.AddrCircuit {
 				circuits++
 			}
 			transport, _ := peer.SplitAddr(rm)
@@ -392,52 +394,35 @@ func classifyLive(a ma.Multiaddr, liveKeys map[string]struct{}) string {
 }
 
 func addrClass(a ma.Multiaddr) string {
-	s := a.String()
-	switch {
-	case strings.Contains(s, "p2p-circuit"):
+	switch sdk.ClassifyAddr(a) {
+	case sdk.AddrCircuit:
 		return "circuit"
-	case strings.HasPrefix(s, "/ip4/127.") || strings.HasPrefix(s, "/ip6/::1"):
+	case sdk.AddrLoopback:
 		return "loopback"
-	case strings.HasPrefix(s, "/ip4/10.") ||
-		strings.HasPrefix(s, "/ip4/192.168.") ||
-		isPrivateIPv4Block172(s):
+	case sdk.AddrPrivate:
 		return "lan4"
-	case strings.HasPrefix(s, "/ip6/fe80"):
-		return "ll6"
-	case strings.HasPrefix(s, "/ip6/fc") || strings.HasPrefix(s, "/ip6/fd"):
+	case sdk.AddrCGNAT:
+		return "cgnat4"
+	case sdk.AddrLinkLocal:
+		if isIP6Addr(a) {
+			return "ll6"
+		}
+		return "ll4"
+	case sdk.AddrULA:
 		return "ula6"
-	case strings.HasPrefix(s, "/ip4/"):
+	case sdk.AddrGlobal:
+		if isIP6Addr(a) {
+			return "pub6"
+		}
 		return "pub4"
-	case strings.HasPrefix(s, "/ip6/"):
-		return "pub6"
 	}
 	return "other"
 }
 
-// isPrivateIPv4Block172 recognises 172.16.0.0/12 without enumerating every
-// second octet by prefix — parses the second octet and range-checks.
-func isPrivateIPv4Block172(s string) bool {
-	const p = "/ip4/172."
-	if !strings.HasPrefix(s, p) {
-		return false
-	}
-	rest := s[len(p):]
-	dot := strings.IndexByte(rest, '.')
-	if dot <= 0 {
-		return false
-	}
-	oct := rest[:dot]
-	var n int
-	for _, c := range []byte(oct) {
-		if c < '0' || c > '9' {
-			return false
-		}
-		n = n*10 + int(c-'0')
-		if n > 255 {
-			return false
-		}
-	}
-	return n >= 16 && n <= 31
+// isIP6Addr reports whether a starts with an /ip6 component.
+func isIP6Addr(a ma.Multiaddr) bool {
+	first, _ := ma.SplitFirst(a)
+	return first != nil && first.Protocol().Code == ma.P_IP6
 }
 
 func shortPeerID(p peer.ID) string {
@@ -277,6 +277,9 @@ func TestAddrClass(t *testing.T) {
 		{"/ip6/fe80::1/tcp/4001", "ll6"},
 		{"/ip6/fd00::1/tcp/4001", "ula6"},
 		{"/ip6/2001:db8::1/tcp/4001", "pub6"},
+		{"/ip4/100.64.1.1/tcp/4001", "cgnat4"},
+		{"/ip4/169.254.1.1/tcp/4001", "ll4"},
+		{"/ip4/203.0.113.7/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit", "circuit"},
 	}
 	for _, tc := range tests {
 		got := addrClass(mustMA(tc.addr))
@@ -285,18 +288,3 @@ func TestAddrClass(t *testing.T) {
 		}
 	}
 }
-
-func TestIsPrivateIPv4Block172(t *testing.T) {
-	yes := []string{"/ip4/172.16.0.0", "/ip4/172.31.255.255", "/ip4/172.20.1.1"}
-	no := []string{"/ip4/172.15.0.0", "/ip4/172.32.0.0", "/ip4/172.a.0.0", "/ip4/172..0.0", "/ip4/17.16.0.0"}
-	for _, s := range yes {
-		if !isPrivateIPv4Block172(s) {
-			t.Errorf("isPrivateIPv4Block172(%q) = false, want true", s)
-		}
-	}
-	for _, s := range no {
-		if isPrivateIPv4Block172(s) {
-			t.Errorf("isPrivateIPv4Block172(%q) = true, want false", s)
-		}
-	}
-}
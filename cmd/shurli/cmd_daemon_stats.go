@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+)
+
+func runDaemonStats(args []string) {
+	runWithJSON(doDaemonStats(args, os.Stdout))
+}
+
+// doDaemonStats handles "daemon stats reset": zero the running daemon's
+// session counters so a benchmark can measure from a clean start.
+func doDaemonStats(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("daemon stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return err
+	}
+
+	errOut := func(err error) error {
+		if *jsonFlag {
+			return jsonErr(stdout, err)
+		}
+		return err
+	}
+
+	if fs.NArg() != 1 || fs.Arg(0) != "reset" {
+		return errOut(fmt.Errorf("usage: shurli daemon stats reset [--json]"))
+	}
+
+	client, err := daemon.NewClient(daemonSocketPath(), daemonCookiePath())
+	if err != nil {
+		return errOut(err)
+	}
+	resp, err := client.ResetStats()
+	if err != nil {
+		return errOut(err)
+	}
+
+	if *jsonFlag {
+		return writeJSON(stdout, resp)
+	}
+	if len(resp.Reset) == 0 {
+		fmt.Fprintln(stdout, "No session counters to reset.")
+		return nil
+	}
+	fmt.Fprintf(stdout, "Reset session counters: %s\n", strings.Join(resp.Reset, ", "))
+	fmt.Fprintln(stdout, "Peer history and Prometheus counters are unchanged.")
+	return nil
+}
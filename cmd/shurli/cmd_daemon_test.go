@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/notify"
+)
+
+func TestParseEventsSince(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseEventsSince("", now)
+	if err != nil || !got.IsZero() {
+		t.Errorf("empty: got %v, %v; want zero time", got, err)
+	}
+
+	got, err = parseEventsSince("5m", now)
+	if err != nil || !got.Equal(now.Add(-5*time.Minute)) {
+		t.Errorf("5m: got %v, %v", got, err)
+	}
+
+	got, err = parseEventsSince("2026-03-01T11:00:00Z", now)
+	if err != nil || !got.Equal(now.Add(-time.Hour)) {
+		t.Errorf("timestamp: got %v, %v", got, err)
+	}
+
+	for _, bad := range []string{"-5m", "0s", "yesterday"} {
+		if _, err := parseEventsSince(bad, now); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestFormatEventLine(t *testing.T) {
+	e := notify.NewEvent(notify.EventGrantRevoked, notify.SeverityWarn, "12D3KooWAbCdEfGhIjKlMnOpQrStUv", "", "relay data access revoked")
+	line := formatEventLine(e)
+	for _, want := range []string{"warn", "grant_revoked", "12D3KooWAbCdEfGh...", "relay data access revoked"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("line %q missing %q", line, want)
+		}
+	}
+}
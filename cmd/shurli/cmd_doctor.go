@@ -1,106 +1,209 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/shurlinet/shurli/internal/auth"
 	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/internal/identity"
 	tc "github.com/shurlinet/shurli/internal/termcolor"
 )
 
 func runDoctor(args []string) {
-	if err := doDoctor(os.Stdout); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if err := doDoctor(args, os.Stdout); err != nil {
+		if !isJSONError(err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
 		osExit(1)
 	}
 }
 
+// Doctor check outcomes.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
 // checkResult represents a single doctor check.
 type checkResult struct {
-	name    string
-	ok      bool
-	message string
-	fixable bool
+	Name    string `json:"name"`
+	Status  string `json:"status"` // pass, warn or fail
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // how to fix a warn or fail
+	Fixable bool   `json:"fixable,omitempty"`
+	Path    string `json:"path,omitempty"` // file --fix acts on, for fixable checks
 }
 
-func doDoctor(stdout io.Writer) error {
-	tc.Wfaint(stdout, "shurli doctor\n")
-	tc.Wfaint(stdout, "Checking your shurli installation...\n")
-	fmt.Fprintln(stdout)
-
-	var checks []checkResult
+// doctorReport is the --json output of shurli doctor.
+type doctorReport struct {
+	Checks   []checkResult `json:"checks"`
+	Passed   int           `json:"passed"`
+	Warnings int           `json:"warnings"`
+	Failed   int           `json:"failed"`
+}
 
-	// 1. Binary version.
-	checks = append(checks, checkResult{
-		name:    "Binary",
-		ok:      true,
-		message: fmt.Sprintf("shurli %s (%s) built %s", version, commit, buildDate),
-	})
+func doDoctor(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFlag := fs.String("config", "", "path to config file")
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fixFlag := fs.Bool("fix", false, "fix what can be fixed automatically")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true, "fix": true})); err != nil {
+		return err
+	}
 
-	// 2. Config file.
-	checks = append(checks, checkConfig())
+	checks := runDoctorChecks(*configFlag)
 
-	// 3. Identity key.
-	checks = append(checks, checkIdentity())
+	report := doctorReport{Checks: checks}
+	fixable := 0
+	for _, c := range checks {
+		switch c.Status {
+		case checkPass:
+			report.Passed++
+		case checkWarn:
+			report.Warnings++
+		case checkFail:
+			report.Failed++
+		}
+		if c.Status != checkPass && c.Fixable {
+			fixable++
+		}
+	}
 
-	// 4. Shell completion.
-	checks = append(checks, checkShellCompletion())
+	if *jsonFlag {
+		if err := writeJSON(stdout, report); err != nil {
+			return err
+		}
+		if report.Failed > 0 {
+			return &jsonError{err: fmt.Errorf("%d check(s) failed", report.Failed), stdout: stdout}
+		}
+		return nil
+	}
 
-	// 5. Man page.
-	checks = append(checks, checkManPage())
+	tc.Wfaint(stdout, "shurli doctor\n")
+	tc.Wfaint(stdout, "Checking your shurli installation...\n")
+	fmt.Fprintln(stdout)
 
-	// Print results.
-	fixable := 0
-	problems := 0
 	for _, c := range checks {
-		if c.ok {
-			fmt.Fprint(stdout, "  ")
-			tc.Wgreen(stdout, "[OK]")
-			fmt.Fprintf(stdout, " %-20s %s\n", c.name, c.message)
-		} else {
-			fmt.Fprint(stdout, "  ")
-			tc.Wred(stdout, "[!!]")
-			fmt.Fprintf(stdout, " %-20s %s\n", c.name, c.message)
-			problems++
-			if c.fixable {
-				fixable++
-			}
+		fmt.Fprint(stdout, "  ")
+		switch c.Status {
+		case checkPass:
+			tc.Wgreen(stdout, "[OK]  ")
+		case checkWarn:
+			tc.Wyellow(stdout, "[WARN]")
+		default:
+			tc.Wred(stdout, "[FAIL]")
+		}
+		fmt.Fprintf(stdout, " %-20s %s\n", c.Name, c.Message)
+		if c.Status != checkPass && c.Hint != "" {
+			fmt.Fprintf(stdout, "         %-20s -> %s\n", "", c.Hint)
 		}
 	}
 
 	fmt.Fprintln(stdout)
+	fmt.Fprintf(stdout, "%d passed, %d warning(s), %d failed.\n", report.Passed, report.Warnings, report.Failed)
 
-	if problems == 0 {
+	if report.Warnings+report.Failed == 0 {
 		tc.Wgreen(stdout, "Everything looks good.\n")
 		return nil
 	}
-
-	if fixable > 0 {
-		tc.Wyellow(stdout, "%d issue(s) found, %d auto-fixable.\n", problems, fixable)
+	if fixable > 0 && !*fixFlag {
+		tc.Wyellow(stdout, "%d issue(s) auto-fixable.\n", fixable)
 		fmt.Fprintln(stdout, "Run: shurli doctor --fix")
-		fmt.Fprintln(stdout)
-	} else {
-		tc.Wyellow(stdout, "%d issue(s) found.\n", problems)
-		fmt.Fprintln(stdout)
 	}
+	fmt.Fprintln(stdout)
 
-	// If --fix was passed, fix what we can.
-	for _, arg := range os.Args[2:] {
-		if arg == "--fix" {
-			return doctorFix(stdout)
+	if *fixFlag {
+		if err := doctorFix(stdout, checks); err != nil {
+			return err
 		}
 	}
-
+	if report.Failed > 0 {
+		return fmt.Errorf("%d check(s) failed", report.Failed)
+	}
 	return nil
 }
 
-func doctorFix(stdout io.Writer) error {
+// runDoctorChecks runs every check in display order. Checks that need the
+// config are skipped when it cannot be found or loaded.
+func runDoctorChecks(configPath string) []checkResult {
+	checks := []checkResult{{
+		Name:    "Binary",
+		Status:  checkPass,
+		Message: fmt.Sprintf("shurli %s (%s) built %s", version, commit, buildDate),
+	}}
+
+	cfgFile, cfg, configCheck := checkConfig(configPath)
+	checks = append(checks, configCheck)
+	if cfg != nil {
+		checks = append(checks, doctorConfigChecks(cfgFile, cfg)...)
+	}
+
+	checks = append(checks, checkShellCompletion(), checkManPage())
+	return checks
+}
+
+// doctorConfigChecks inspects a loaded config beyond what
+// ValidateNodeConfig enforces: files on disk, relay addresses, and
+// listen ports.
+func doctorConfigChecks(cfgFile string, cfg *config.NodeConfig) []checkResult {
+	var checks []checkResult
+	if err := config.ValidateNodeConfig(cfg); err != nil {
+		checks = append(checks, checkResult{
+			Name:    "Config values",
+			Status:  checkFail,
+			Message: err.Error(),
+			Hint:    fmt.Sprintf("Edit %s, then run: shurli config validate", cfgFile),
+		})
+	} else {
+		checks = append(checks, checkResult{Name: "Config values", Status: checkPass, Message: "valid"})
+	}
+
+	config.ResolveConfigPaths(cfg, filepath.Dir(cfgFile))
+	checks = append(checks,
+		checkIdentity(cfgFile, cfg),
+		checkAuthorizedKeys(cfg),
+		checkRelayAddresses(cfg),
+		checkRendezvous(cfg),
+		checkListenPorts(cfg),
+	)
+	return checks
+}
+
+// doctorFix applies the automatic fixes for the failing checks.
+func doctorFix(stdout io.Writer, checks []checkResult) error {
 	fmt.Fprintln(stdout, "Fixing...")
 	fmt.Fprintln(stdout)
+	for _, c := range checks {
+		if c.Status == checkPass || !c.Fixable {
+			continue
+		}
+		switch c.Name {
+		case "Identity":
+			if changed, err := identity.FixKeyFilePermissions(c.Path); err != nil {
+				tc.Wred(stdout, "Identity: %v\n", err)
+			} else if changed {
+				tc.Wgreen(stdout, "Set %s to 0600\n", c.Path)
+			}
+		case "Authorized keys":
+			if changed, err := auth.FixAuthorizedKeysPermissions(c.Path); err != nil {
+				tc.Wred(stdout, "Authorized keys: %v\n", err)
+			} else if changed {
+				tc.Wgreen(stdout, "Removed group/other write access from %s\n", c.Path)
+			}
+		}
+	}
 	setupShellEnvironment(stdout)
 	return nil
 }
@@ -182,72 +285,224 @@ func tryInstallFile(dest string, content string) bool {
 
 // --- Doctor checks ---
 
-func checkConfig() checkResult {
-	cfgFile, err := config.FindConfigFile("")
+// checkConfig finds and parses the config. It returns the loaded config,
+// or nil when the remaining config checks cannot run.
+func checkConfig(configPath string) (string, *config.NodeConfig, checkResult) {
+	cfgFile, err := config.FindConfigFile(configPath)
 	if err != nil {
+		return "", nil, checkResult{
+			Name:    "Config",
+			Status:  checkFail,
+			Message: "Not found",
+			Hint:    "Run: shurli init",
+		}
+	}
+	cfg, err := config.LoadNodeConfig(cfgFile)
+	if err != nil {
+		return cfgFile, nil, checkResult{
+			Name:    "Config",
+			Status:  checkFail,
+			Message: fmt.Sprintf("Invalid: %v", err),
+			Hint:    fmt.Sprintf("Fix the YAML in %s", cfgFile),
+		}
+	}
+	return cfgFile, cfg, checkResult{Name: "Config", Status: checkPass, Message: cfgFile}
+}
+
+// checkIdentity confirms the key file exists, is readable, is an
+// encrypted identity, and is not readable by group or others. On a
+// permission warning Path holds the key path so --fix can chmod it.
+func checkIdentity(cfgFile string, cfg *config.NodeConfig) checkResult {
+	keyFile := cfg.Identity.KeyFile
+	if keyFile == "" {
+		keyFile = filepath.Join(filepath.Dir(cfgFile), config.ProfileKeyFileName())
+	}
+	data, err := os.ReadFile(keyFile)
+	if os.IsNotExist(err) {
 		return checkResult{
-			name:    "Config",
-			ok:      false,
-			message: "Not found. Run: shurli init",
+			Name:    "Identity",
+			Status:  checkFail,
+			Message: fmt.Sprintf("Key file missing: %s", keyFile),
+			Hint:    "Run: shurli init, or restore the key from backup (shurli recover)",
 		}
 	}
-	if _, err := config.LoadNodeConfig(cfgFile); err != nil {
+	if err != nil {
 		return checkResult{
-			name:    "Config",
-			ok:      false,
-			message: fmt.Sprintf("Invalid: %v", err),
+			Name:    "Identity",
+			Status:  checkFail,
+			Message: fmt.Sprintf("Key file unreadable: %v", err),
+			Hint:    fmt.Sprintf("Make %s readable by the user running shurli", keyFile),
 		}
 	}
-	return checkResult{
-		name:    "Config",
-		ok:      true,
-		message: cfgFile,
+	if !identity.IsEncrypted(data) {
+		return checkResult{
+			Name:    "Identity",
+			Status:  checkFail,
+			Message: fmt.Sprintf("Key file is not an encrypted shurli identity: %s", keyFile),
+			Hint:    "Run: shurli init, or restore the key from backup (shurli recover)",
+		}
 	}
+	if err := identity.CheckKeyFilePermissions(keyFile); err != nil {
+		return checkResult{
+			Name:    "Identity",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Key file is readable by other users: %s", keyFile),
+			Hint:    fmt.Sprintf("chmod 600 %s, or run: shurli doctor --fix", keyFile),
+			Fixable: true,
+			Path:    keyFile,
+		}
+	}
+	return checkResult{Name: "Identity", Status: checkPass, Message: keyFile}
 }
 
-func checkIdentity() checkResult {
-	cfgFile, err := config.FindConfigFile("")
-	if err != nil {
+// checkAuthorizedKeys warns when gating is off, and fails when gating is
+// on but no peer is authorized (every inbound connection is refused). On
+// a permission warning Path holds the file path so --fix can chmod it.
+func checkAuthorizedKeys(cfg *config.NodeConfig) checkResult {
+	if !cfg.Security.EnableConnectionGating {
 		return checkResult{
-			name:    "Identity",
-			ok:      false,
-			message: "No config found",
+			Name:    "Authorized keys",
+			Status:  checkWarn,
+			Message: "Connection gating disabled: any peer can connect",
+			Hint:    "Set security.enable_connection_gating: true",
 		}
 	}
-	cfg, err := config.LoadNodeConfig(cfgFile)
+	path := cfg.Security.AuthorizedKeysFile
+	peers, err := auth.LoadAuthorizedKeys(path)
 	if err != nil {
 		return checkResult{
-			name:    "Identity",
-			ok:      false,
-			message: "Cannot load config",
+			Name:    "Authorized keys",
+			Status:  checkFail,
+			Message: err.Error(),
+			Hint:    "Run: shurli auth validate",
 		}
 	}
-	config.ResolveConfigPaths(cfg, filepath.Dir(cfgFile))
-	keyFile := cfg.Identity.KeyFile
-	if keyFile == "" {
-		keyFile = filepath.Join(filepath.Dir(cfgFile), "identity.key")
+	if len(peers) == 0 {
+		return checkResult{
+			Name:    "Authorized keys",
+			Status:  checkFail,
+			Message: fmt.Sprintf("No peers authorized in %s; gating refuses every inbound connection", path),
+			Hint:    "Run: shurli auth add <peer-id>, or pair with: shurli invite",
+		}
 	}
-	if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+	if err := auth.CheckAuthorizedKeysPermissions(path); err != nil {
 		return checkResult{
-			name:    "Identity",
-			ok:      false,
-			message: fmt.Sprintf("Key file missing: %s", keyFile),
+			Name:    "Authorized keys",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Writable by other users, who could authorize themselves: %s", path),
+			Hint:    fmt.Sprintf("chmod 600 %s, or run: shurli doctor --fix", path),
+			Fixable: true,
+			Path:    path,
 		}
 	}
-	return checkResult{
-		name:    "Identity",
-		ok:      true,
-		message: keyFile,
+	return checkResult{Name: "Authorized keys", Status: checkPass, Message: fmt.Sprintf("%d peer(s) in %s", len(peers), path)}
+}
+
+// checkRelayAddresses parses every relay address into an AddrInfo, the
+// way the daemon does at startup.
+func checkRelayAddresses(cfg *config.NodeConfig) checkResult {
+	addrs := cfg.Relay.ActiveAddresses()
+	if !cfg.Relay.IsEnabled() {
+		return checkResult{Name: "Relays", Status: checkPass, Message: "disabled"}
+	}
+	if len(addrs) == 0 {
+		return checkResult{
+			Name:    "Relays",
+			Status:  checkFail,
+			Message: "No relay addresses configured",
+			Hint:    "Run: shurli relay add <multiaddr>, or set relay.enabled: false",
+		}
+	}
+	for _, addr := range addrs {
+		if _, err := peer.AddrInfoFromString(addr); err != nil {
+			hint := "Relay addresses must be full multiaddrs like /ip4/203.0.113.50/tcp/7777/p2p/<relay-peer-id>"
+			if !strings.Contains(addr, "/p2p/") {
+				hint = "Append /p2p/<relay-peer-id> (shown by: shurli relay info on the relay)"
+			}
+			return checkResult{
+				Name:    "Relays",
+				Status:  checkFail,
+				Message: fmt.Sprintf("Invalid relay address %q: %v", addr, err),
+				Hint:    hint,
+			}
+		}
+	}
+	return checkResult{Name: "Relays", Status: checkPass, Message: fmt.Sprintf("%d address(es) parse", len(addrs))}
+}
+
+func checkRendezvous(cfg *config.NodeConfig) checkResult {
+	if strings.TrimSpace(cfg.Discovery.Rendezvous) == "" {
+		return checkResult{
+			Name:    "Rendezvous",
+			Status:  checkFail,
+			Message: "discovery.rendezvous is empty",
+			Hint:    "Set discovery.rendezvous to the string shared by your nodes",
+		}
 	}
+	return checkResult{Name: "Rendezvous", Status: checkPass, Message: strings.Join(cfg.Discovery.RendezvousList(), ", ")}
+}
+
+// checkListenPorts tries to bind each fixed listen port. A running daemon
+// holds them itself, so the check is skipped then.
+func checkListenPorts(cfg *config.NodeConfig) checkResult {
+	if _, err := os.Stat(daemonSocketPath()); err == nil {
+		if c := tryDaemonClient(); c != nil {
+			if _, err := c.Status(); err == nil {
+				return checkResult{Name: "Listen ports", Status: checkPass, Message: "in use by the running daemon"}
+			}
+		}
+	}
+	for _, addr := range cfg.Network.ListenAddresses {
+		if err := listenAddrAvailable(addr); err != nil {
+			return checkResult{
+				Name:    "Listen ports",
+				Status:  checkFail,
+				Message: fmt.Sprintf("%s: %v", addr, err),
+				Hint:    "Stop the process using the port, or change network.listen_addresses (port 0 picks a free one)",
+			}
+		}
+	}
+	return checkResult{Name: "Listen ports", Status: checkPass, Message: "available"}
+}
+
+// listenAddrAvailable binds and releases the TCP or UDP port of a listen
+// multiaddr. Port 0 and non-IP addresses always succeed.
+func listenAddrAvailable(addr string) error {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return err
+	}
+	network, hostPort, err := manet.DialArgs(maddr)
+	if err != nil {
+		return nil // not an IP transport (e.g. /webrtc-direct over a shared port)
+	}
+	if _, port, err := net.SplitHostPort(hostPort); err != nil || port == "0" {
+		return nil
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		l, err := net.Listen(network, hostPort)
+		if err != nil {
+			return fmt.Errorf("port already in use (%w)", err)
+		}
+		return l.Close()
+	case "udp", "udp4", "udp6":
+		c, err := net.ListenPacket(network, hostPort)
+		if err != nil {
+			return fmt.Errorf("port already in use (%w)", err)
+		}
+		return c.Close()
+	}
+	return nil
 }
 
 func checkShellCompletion() checkResult {
 	shell := detectShell()
 	if shell == "" {
 		return checkResult{
-			name:    "Completion",
-			ok:      true,
-			message: "Shell not detected (SHELL env var empty)",
+			Name:    "Completion",
+			Status:  checkPass,
+			Message: "Shell not detected (SHELL env var empty)",
 		}
 	}
 
@@ -256,17 +511,18 @@ func checkShellCompletion() checkResult {
 	info, err := os.Stat(dest)
 	if os.IsNotExist(err) {
 		return checkResult{
-			name:    "Completion",
-			ok:      false,
-			message: fmt.Sprintf("Not installed for %s (%s)", shell, dest),
-			fixable: true,
+			Name:    "Completion",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Not installed for %s (%s)", shell, dest),
+			Hint:    "Run: shurli doctor --fix",
+			Fixable: true,
 		}
 	}
 	if err != nil {
 		return checkResult{
-			name:    "Completion",
-			ok:      false,
-			message: fmt.Sprintf("Cannot check %s: %v", dest, err),
+			Name:    "Completion",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Cannot check %s: %v", dest, err),
 		}
 	}
 
@@ -275,17 +531,18 @@ func checkShellCompletion() checkResult {
 	currentContent := completionContent(shell)
 	if info.Size() != int64(len(currentContent)) {
 		return checkResult{
-			name:    "Completion",
-			ok:      false,
-			message: fmt.Sprintf("Outdated for %s (installed size %d, current %d)", shell, info.Size(), len(currentContent)),
-			fixable: true,
+			Name:    "Completion",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Outdated for %s (installed size %d, current %d)", shell, info.Size(), len(currentContent)),
+			Hint:    "Run: shurli doctor --fix",
+			Fixable: true,
 		}
 	}
 
 	return checkResult{
-		name:    "Completion",
-		ok:      true,
-		message: fmt.Sprintf("%s (%s)", shell, dest),
+		Name:    "Completion",
+		Status:  checkPass,
+		Message: fmt.Sprintf("%s (%s)", shell, dest),
 	}
 }
 
@@ -299,17 +556,18 @@ func checkManPage() checkResult {
 			if out, err := cmd.Output(); err == nil {
 				path := strings.TrimSpace(string(out))
 				return checkResult{
-					name:    "Man page",
-					ok:      true,
-					message: path,
+					Name:    "Man page",
+					Status:  checkPass,
+					Message: path,
 				}
 			}
 		}
 		return checkResult{
-			name:    "Man page",
-			ok:      false,
-			message: fmt.Sprintf("Not installed (%s)", dest),
-			fixable: true,
+			Name:    "Man page",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Not installed (%s)", dest),
+			Hint:    "Run: shurli doctor --fix",
+			Fixable: true,
 		}
 	}
 
@@ -317,24 +575,25 @@ func checkManPage() checkResult {
 	info, err := os.Stat(dest)
 	if err != nil {
 		return checkResult{
-			name:    "Man page",
-			ok:      false,
-			message: fmt.Sprintf("Cannot check: %v", err),
+			Name:    "Man page",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Cannot check: %v", err),
 		}
 	}
 	currentContent := manPage()
 	if info.Size() != int64(len(currentContent)) {
 		return checkResult{
-			name:    "Man page",
-			ok:      false,
-			message: fmt.Sprintf("Outdated (installed size %d, current %d)", info.Size(), len(currentContent)),
-			fixable: true,
+			Name:    "Man page",
+			Status:  checkWarn,
+			Message: fmt.Sprintf("Outdated (installed size %d, current %d)", info.Size(), len(currentContent)),
+			Hint:    "Run: shurli doctor --fix",
+			Fixable: true,
 		}
 	}
 
 	return checkResult{
-		name:    "Man page",
-		ok:      true,
-		message: dest,
+		Name:    "Man page",
+		Status:  checkPass,
+		Message: dest,
 	}
 }
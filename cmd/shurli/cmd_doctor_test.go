@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shurlinet/shurli/internal/auth"
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+const doctorTestPeer = "12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"
+
+func loadDoctorTestConfig(t *testing.T, cfgPath string) *config.NodeConfig {
+	t.Helper()
+	cfg, err := config.LoadNodeConfig(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+func findCheck(t *testing.T, checks []checkResult, name string) checkResult {
+	t.Helper()
+	for _, c := range checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check in %+v", name, checks)
+	return checkResult{}
+}
+
+func TestDoctorConfigChecks(t *testing.T) {
+	cfgPath := writeTestConfigDir(t)
+
+	checks := doctorConfigChecks(cfgPath, loadDoctorTestConfig(t, cfgPath))
+	for _, name := range []string{"Config values", "Identity", "Relays", "Rendezvous", "Listen ports"} {
+		if c := findCheck(t, checks, name); c.Status != checkPass {
+			t.Errorf("%s: status %s (%s), want pass", name, c.Status, c.Message)
+		}
+	}
+	// The fixture's authorized_keys is empty while gating is enabled.
+	if c := findCheck(t, checks, "Authorized keys"); c.Status != checkFail || c.Hint == "" {
+		t.Errorf("empty authorized_keys: %+v, want fail with hint", c)
+	}
+
+	if err := auth.AddPeer(filepath.Join(filepath.Dir(cfgPath), "authorized_keys"), doctorTestPeer, "relay"); err != nil {
+		t.Fatal(err)
+	}
+	checks = doctorConfigChecks(cfgPath, loadDoctorTestConfig(t, cfgPath))
+	if c := findCheck(t, checks, "Authorized keys"); c.Status != checkPass {
+		t.Errorf("authorized_keys with a peer: %+v, want pass", c)
+	}
+}
+
+func TestCheckRelayAddresses(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       string
+		wantStatus string
+		wantHint   string
+	}{
+		{"full", "/ip4/1.2.3.4/tcp/7777/p2p/" + doctorTestPeer, checkPass, ""},
+		{"missing p2p", "/ip4/1.2.3.4/tcp/7777", checkFail, "/p2p/"},
+		{"garbage", "relay.example.com:7777", checkFail, "/p2p/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.NodeConfig{}
+			cfg.Relay.Addresses = []string{tt.addr}
+			c := checkRelayAddresses(cfg)
+			if c.Status != tt.wantStatus {
+				t.Errorf("status = %s (%s), want %s", c.Status, c.Message, tt.wantStatus)
+			}
+			if !strings.Contains(c.Hint, tt.wantHint) {
+				t.Errorf("hint = %q, want it to mention %q", c.Hint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestCheckRendezvous(t *testing.T) {
+	cfg := &config.NodeConfig{}
+	if c := checkRendezvous(cfg); c.Status != checkFail {
+		t.Errorf("empty rendezvous: status %s, want fail", c.Status)
+	}
+	cfg.Discovery.Rendezvous = "home"
+	if c := checkRendezvous(cfg); c.Status != checkPass {
+		t.Errorf("rendezvous set: status %s, want pass", c.Status)
+	}
+}
+
+func TestCheckIdentity_Permissions(t *testing.T) {
+	cfgPath := writeTestConfigDir(t)
+	cfg := loadDoctorTestConfig(t, cfgPath)
+	config.ResolveConfigPaths(cfg, filepath.Dir(cfgPath))
+
+	if err := os.Chmod(cfg.Identity.KeyFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := checkIdentity(cfgPath, cfg)
+	if c.Status != checkWarn || !c.Fixable || c.Path != cfg.Identity.KeyFile {
+		t.Fatalf("0644 key: %+v, want fixable warn on %s", c, cfg.Identity.KeyFile)
+	}
+
+	if err := os.WriteFile(cfg.Identity.KeyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if c := checkIdentity(cfgPath, cfg); c.Status != checkFail {
+		t.Errorf("unencrypted key: status %s, want fail", c.Status)
+	}
+
+	os.Remove(cfg.Identity.KeyFile)
+	if c := checkIdentity(cfgPath, cfg); c.Status != checkFail {
+		t.Errorf("missing key: status %s, want fail", c.Status)
+	}
+}
+
+func TestListenAddrAvailable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+
+	busy := fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", port)
+	if err := listenAddrAvailable(busy); err == nil {
+		t.Errorf("port %d in use: expected error", port)
+	}
+	if err := listenAddrAvailable("/ip4/127.0.0.1/tcp/0"); err != nil {
+		t.Errorf("port 0: unexpected error %v", err)
+	}
+	if err := listenAddrAvailable("not-a-multiaddr"); err == nil {
+		t.Error("invalid multiaddr: expected error")
+	}
+}
+
+func TestDoDoctor_JSON(t *testing.T) {
+	cfgPath := writeTestConfigDir(t)
+
+	var stdout bytes.Buffer
+	err := doDoctor([]string{"--json", "--config", cfgPath}, &stdout)
+	// Empty authorized_keys with gating enabled fails one check.
+	if err == nil || !isJSONError(err) {
+		t.Fatalf("expected JSON error for failing check, got %v", err)
+	}
+
+	var envelope struct {
+		Status string       `json:"status"`
+		Data   doctorReport `json:"data"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &envelope); err != nil {
+		t.Fatalf("parse output: %v\n%s", err, stdout.String())
+	}
+	if envelope.Data.Failed != 1 {
+		t.Errorf("failed = %d, want 1: %+v", envelope.Data.Failed, envelope.Data.Checks)
+	}
+	if c := findCheck(t, envelope.Data.Checks, "Authorized keys"); c.Status != checkFail {
+		t.Errorf("Authorized keys status = %s, want fail", c.Status)
+	}
+}
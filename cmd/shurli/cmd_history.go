@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/internal/reputation"
+)
+
+func runHistory(args []string) {
+	if len(args) == 0 {
+		printHistoryUsage()
+		osExit(1)
+	}
+
+	switch args[0] {
+	case "export":
+		if err := doHistoryExport(args[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+	case "peer":
+		if err := doHistoryPeer(args[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown history command: %s\n\n", args[0])
+		printHistoryUsage()
+		osExit(1)
+	}
+}
+
+func printHistoryUsage() {
+	fmt.Println("Usage: shurli history <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  export [--format csv|json] [--config path]   Export peer interaction history")
+	fmt.Println("  peer <peer> [--json]                         Recent connections to a peer (path, latency)")
+}
+
+// doHistoryPeer shows one peer's recent connections from the running
+// daemon: the past day's DIRECT/RELAYED mix, how often the path flipped,
+// and the latest events.
+func doHistoryPeer(args []string, stdout io.Writer) error {
+	fset := flag.NewFlagSet("history peer", flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	jsonFlag := fset.Bool("json", false, "output as JSON")
+	if err := fset.Parse(reorderArgs(args, map[string]bool{"json": true})); err != nil || fset.NArg() != 1 {
+		return fmt.Errorf("usage: shurli history peer <peer> [--json]")
+	}
+	target := fset.Arg(0)
+
+	client, err := daemon.NewClient(daemonSocketPath(), daemonCookiePath())
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag {
+		resp, err := client.PeerHistory(target)
+		if err != nil {
+			return err
+		}
+		return writeJSON(stdout, resp)
+	}
+	text, err := client.PeerHistoryText(target)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, text)
+	return nil
+}
+
+// doHistoryExport writes the peer history recorded by the daemon next to
+// the config file. It only reads peer_history.json, so it works whether or
+// not the daemon is running.
+func doHistoryExport(args []string, stdout io.Writer) error {
+	fset := flag.NewFlagSet("history export", flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	configFlag := fset.String("config", "", "path to config file")
+	formatFlag := fset.String("format", "csv", "output format: csv or json")
+	if err := fset.Parse(reorderArgs(args, nil)); err != nil {
+		return fmt.Errorf("usage: shurli history export [--format csv|json] [--config path]")
+	}
+	if *formatFlag != "csv" && *formatFlag != "json" {
+		return fmt.Errorf("unknown format %q (use csv or json)", *formatFlag)
+	}
+
+	cfgFile, err := config.FindConfigFile(*configFlag)
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+	historyPath := filepath.Join(filepath.Dir(cfgFile), config.ProfileFileName("peer_history.json"))
+
+	records, err := reputation.ReadRecords(historyPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no peer history at %s (the daemon records it while running)", historyPath)
+		}
+		return err
+	}
+
+	if *formatFlag == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	return reputation.WriteCSV(stdout, records)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shurlinet/shurli/internal/reputation"
+)
+
+func TestDoHistoryExport(t *testing.T) {
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "shurli.yaml")
+	if err := os.WriteFile(cfgFile, []byte("version: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err := doHistoryExport([]string{"--config", cfgFile}, &buf)
+	if err == nil || !strings.Contains(err.Error(), "no peer history") {
+		t.Fatalf("expected missing-history error, got %v", err)
+	}
+
+	h := reputation.NewPeerHistory(filepath.Join(dir, "peer_history.json"))
+	h.RecordConnection("peer-A", "direct", 12)
+	h.RecordIntroduction("peer-A", "relay-001", "invite")
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := doHistoryExport([]string{"--config", cfgFile}, &buf); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		out := buf.String()
+		if !strings.HasPrefix(out, "peer_id,first_seen,") {
+			t.Errorf("missing CSV header:\n%s", out)
+		}
+		if !strings.Contains(out, "peer-A,") || !strings.Contains(out, "relay-001,invite") {
+			t.Errorf("missing peer row:\n%s", out)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := doHistoryExport([]string{"--format", "json", "--config", cfgFile}, &buf); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		var records []reputation.PeerRecord
+		if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if len(records) != 1 || records[0].PeerID != "peer-A" || records[0].PathTypes["direct"] != 1 {
+			t.Errorf("records = %+v", records)
+		}
+	})
+
+	t.Run("bad format", func(t *testing.T) {
+		if err := doHistoryExport([]string{"--format", "xml", "--config", cfgFile}, &bytes.Buffer{}); err == nil {
+			t.Error("expected error for unknown format")
+		}
+	})
+}
+
+func TestDoHistoryPeerUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"a", "b"}} {
+		err := doHistoryPeer(args, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), "usage:") {
+			t.Errorf("args %v: err = %v, want usage error", args, err)
+		}
+	}
+}
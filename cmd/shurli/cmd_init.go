@@ -15,6 +15,7 @@ import (
 
 	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/identity"
+	"github.com/shurlinet/shurli/internal/invite"
 	"github.com/shurlinet/shurli/internal/qr"
 	tc "github.com/shurlinet/shurli/internal/termcolor"
 	"github.com/shurlinet/shurli/internal/validate"
@@ -67,6 +68,130 @@ func promptRelayAddress(reader *bufio.Reader, stdout io.Writer) (string, error)
 	return addr, nil
 }
 
+// promptNetworkSetup asks whether to use the user's own relay or the
+// public seed nodes and returns the relay addresses to write to config.
+// usedSeeds is true when only public seeds were chosen.
+func promptNetworkSetup(reader *bufio.Reader, stdout io.Writer) (relayAddrs []string, usedSeeds bool, err error) {
+	fmt.Fprintln(stdout, "Network setup:")
+	fmt.Fprintln(stdout, "  1. Use my own relay server (recommended)")
+	fmt.Fprintln(stdout, "     Full capability: data relay, file transfer, service proxy.")
+	fmt.Fprintln(stdout, "     Your relay, your rules. Setup: https://shurli.io/docs/relay-setup/")
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "  2. Use public seed nodes (limited - discovery only)")
+	fmt.Fprintln(stdout, "     Seed nodes handle peer DISCOVERY only. They do NOT relay data.")
+	fmt.Fprintln(stdout, "     You cannot: relay files, proxy services, or pass any data through seeds.")
+	fmt.Fprintln(stdout, "     You can: discover peers and make direct connections (hole-punching).")
+	fmt.Fprintln(stdout)
+	fmt.Fprint(stdout, "Choice [1]: ")
+
+	choice, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read input: %w", err)
+	}
+	choice = strings.TrimSpace(choice)
+	if choice == "" {
+		choice = "1"
+	}
+
+	switch choice {
+	case "1":
+		relayAddr, err := promptRelayAddress(reader, stdout)
+		if err != nil {
+			return nil, false, err
+		}
+		relayAddrs = []string{relayAddr}
+
+		fmt.Fprintln(stdout)
+		fmt.Fprint(stdout, "Also add public seed nodes for broader peer discovery? [y/N]: ")
+		seedChoice, _ := reader.ReadString('\n')
+		seedChoice = strings.TrimSpace(strings.ToLower(seedChoice))
+		if seedChoice == "y" || seedChoice == "yes" {
+			relayAddrs = append(relayAddrs, HardcodedSeeds...)
+			fmt.Fprintf(stdout, "Added %d public seed nodes.\n", len(HardcodedSeeds))
+		}
+	case "2":
+		usedSeeds = true
+		relayAddrs = HardcodedSeeds
+		fmt.Fprintln(stdout)
+		fmt.Fprintf(stdout, "Using %d public seed nodes for DISCOVERY ONLY.\n", len(SeedPeerIDs()))
+		fmt.Fprintln(stdout, "  - No file transfer through seeds")
+		fmt.Fprintln(stdout, "  - No service proxy through seeds")
+		fmt.Fprintln(stdout, "  - No data circuits of any kind")
+		fmt.Fprintln(stdout, "  Direct connections still work when both peers are online.")
+		fmt.Fprintln(stdout, "  Deploy your own relay for full capability: https://shurli.io/docs/relay-setup/")
+	default:
+		return nil, false, fmt.Errorf("invalid choice: %s (enter 1 or 2)", choice)
+	}
+	fmt.Fprintln(stdout)
+	return relayAddrs, usedSeeds, nil
+}
+
+// unlockExistingIdentity asks for the password of the identity at keyFile
+// and decrypts it, so --force can rewrite the config around it.
+func unlockExistingIdentity(keyFile string, stdout io.Writer) (crypto.PrivKey, string, error) {
+	const maxAttempts = 3
+	for attempt := 1; ; attempt++ {
+		pw, err := readPassword("Password: ", stdout)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read password: %w", err)
+		}
+		privKey, err := identity.LoadIdentity(keyFile, pw)
+		if err == nil {
+			return privKey, pw, nil
+		}
+		fmt.Fprintf(stdout, "  %v\n", err)
+		if attempt == maxAttempts {
+			return nil, "", fmt.Errorf("cannot unlock existing identity %s after %d attempts\nMove it aside first if you really want a new identity", keyFile, maxAttempts)
+		}
+	}
+}
+
+// promptIdentityChoice asks whether to create a new identity or recover
+// one from a seed phrase. It reports true for recovery.
+func promptIdentityChoice(reader *bufio.Reader, stdout io.Writer) (bool, error) {
+	fmt.Fprintln(stdout, "Identity:")
+	fmt.Fprintln(stdout, "  1. Create a new identity (default)")
+	fmt.Fprintln(stdout, "  2. Recover from an existing seed phrase")
+	fmt.Fprintln(stdout)
+	fmt.Fprint(stdout, "Choice [1]: ")
+
+	idChoice, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+	idChoice = strings.TrimSpace(idChoice)
+	if idChoice == "" {
+		idChoice = "1"
+	}
+
+	var recoverMode bool
+	switch idChoice {
+	case "1":
+		// New identity - handled by the caller
+	case "2":
+		recoverMode = true
+	default:
+		return false, fmt.Errorf("invalid choice: %s (enter 1 or 2)", idChoice)
+	}
+	fmt.Fprintln(stdout)
+	return recoverMode, nil
+}
+
+// initPairJoin runs the join flow for an invite code carried in an init
+// bundle. Replaced in tests.
+var initPairJoin = func(data *invite.InviteData, name, configFile string) {
+	runPairJoin(data, name, configFile, "", false, false, fmt.Printf, fmt.Println)
+}
+
+// networkLabel names a DHT namespace for messages, with the empty
+// namespace shown as the global network.
+func networkLabel(network string) string {
+	if network == "" {
+		return "the global network"
+	}
+	return fmt.Sprintf("%q", network)
+}
+
 func runInit(args []string) {
 	if err := doInit(args, os.Stdin, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -81,10 +206,18 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 	userFlag := fs.Bool("user", false, "install config in ~/.shurli/ instead of /etc/shurli/")
 	networkFlag := fs.String("network", "", "DHT network namespace for private networks (e.g., \"my-crew\")")
 	skipSeedConfirm := fs.Bool("skip-seed-confirm", false, "skip seed backup confirmation quiz (automation only)")
+	templateFlag := fs.String("template", templateHomeNode, "config preset: "+strings.Join(nodeConfigTemplates, ", "))
+	fromFlag := fs.String("from", "", "bootstrap bundle or URL carrying relays, network and an optional invite code")
+	forceFlag := fs.Bool("force", false, "overwrite an existing config (the existing identity is kept)")
+	asFlag := fs.String("as", "", "your node's name on the network, used when the bundle carries an invite code")
 	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
 		return err
 	}
 
+	if !isNodeConfigTemplate(*templateFlag) {
+		return fmt.Errorf("invalid --template value %q (choose from: %s)", *templateFlag, strings.Join(nodeConfigTemplates, ", "))
+	}
+
 	// Validate network namespace if provided
 	if *networkFlag != "" {
 		if err := validate.NetworkName(*networkFlag); err != nil {
@@ -92,6 +225,25 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 		}
 	}
 
+	// A bootstrap bundle supplies the relays and namespace, replacing the
+	// network prompt. --network, when given, is what the bundle must target.
+	var bundle *invite.Bundle
+	network := *networkFlag
+	if *fromFlag != "" {
+		if !templateUsesRelay(*templateFlag) {
+			return fmt.Errorf("--from needs a template that uses a relay (not %q)", *templateFlag)
+		}
+		b, err := invite.DecodeBundle(*fromFlag)
+		if err != nil {
+			return err
+		}
+		if *networkFlag != "" && b.Network != *networkFlag {
+			return fmt.Errorf("bundle targets network %s, expected %s", networkLabel(b.Network), networkLabel(*networkFlag))
+		}
+		bundle = b
+		network = b.Network
+	}
+
 	tc.Wgreen(stdout, "Welcome to Shurli!\n")
 	fmt.Fprintln(stdout)
 
@@ -112,9 +264,29 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 	}
 
 	// Check if config already exists (including legacy path)
-	configFile := filepath.Join(configDir, "config.yaml")
+	configFile := filepath.Join(configDir, config.ProfileConfigName())
+	keyFile := filepath.Join(configDir, config.ProfileKeyFileName())
+	var keepIdentity bool
 	if _, err := os.Stat(configFile); err == nil {
-		return fmt.Errorf("config already exists: %s\nDelete it first if you want to reinitialize", configFile)
+		if !*forceFlag {
+			return fmt.Errorf("config already exists: %s\nDelete it first or pass --force if you want to reinitialize", configFile)
+		}
+		// Re-provisioning from a bundle must not silently move this node
+		// to another network. Pass --network to confirm a deliberate move.
+		if bundle != nil && *networkFlag == "" {
+			if old, err := config.LoadNodeConfig(configFile); err == nil && old.Discovery.Network != bundle.Network {
+				return fmt.Errorf("bundle targets network %s but %s is on %s\nPass --network %s to move this node", networkLabel(bundle.Network), configFile, networkLabel(old.Discovery.Network), bundle.Network)
+			}
+		}
+		// --force replaces the config only. The peer ID is what every
+		// other node authorized, so an existing identity is reused.
+		if _, err := os.Stat(keyFile); err == nil {
+			keepIdentity = true
+			tc.Wyellow(stdout, "Overwriting existing config in %s (--force); keeping the existing identity.\n", configDir)
+		} else {
+			tc.Wyellow(stdout, "Overwriting existing config in %s (--force).\n", configDir)
+		}
+		fmt.Fprintln(stdout)
 	}
 
 	// Create config directory
@@ -142,38 +314,31 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 	}
 	fmt.Fprintln(stdout)
 
-	// Identity setup: new or recover
+	// Identity setup: keep, new or recover. A bundle creates a new one
+	// unless an existing identity is kept.
 	reader := bufio.NewReader(stdin)
-	fmt.Fprintln(stdout, "Identity:")
-	fmt.Fprintln(stdout, "  1. Create a new identity (default)")
-	fmt.Fprintln(stdout, "  2. Recover from an existing seed phrase")
-	fmt.Fprintln(stdout)
-	fmt.Fprint(stdout, "Choice [1]: ")
-
-	idChoice, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
-	}
-	idChoice = strings.TrimSpace(idChoice)
-	if idChoice == "" {
-		idChoice = "1"
-	}
-
 	var recoverMode bool
-	switch idChoice {
-	case "1":
-		// New identity - handled below
-	case "2":
-		recoverMode = true
-	default:
-		return fmt.Errorf("invalid choice: %s (enter 1 or 2)", idChoice)
+	var err error
+	if bundle == nil && !keepIdentity {
+		if recoverMode, err = promptIdentityChoice(reader, stdout); err != nil {
+			return err
+		}
 	}
-	fmt.Fprintln(stdout)
 
-	// Identity: generate new or recover from seed phrase.
+	// Identity: keep the existing one, generate new or recover from seed phrase.
 	// This runs BEFORE network setup so the user confirms their identity first.
 	var privKey crypto.PrivKey
-	if recoverMode {
+	var password string
+	if keepIdentity {
+		fmt.Fprintf(stdout, "Unlock the existing identity in %s.\n", keyFile)
+		privKey, password, err = unlockExistingIdentity(keyFile, stdout)
+		if err != nil {
+			return err
+		}
+		peerID, _ := peer.IDFromPrivateKey(privKey)
+		fmt.Fprintf(stdout, "Keeping Peer ID: %s\n", peerID)
+		fmt.Fprintln(stdout)
+	} else if recoverMode {
 		fmt.Fprintln(stdout, "Enter your seed phrase to recover your identity.")
 		fmt.Fprintln(stdout)
 		mnemonic, err := readSeedPhrase(stdout)
@@ -231,87 +396,55 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 		}
 	}
 
-	// Network setup: own relay (recommended) or public seed nodes
-	fmt.Fprintln(stdout, "Network setup:")
-	fmt.Fprintln(stdout, "  1. Use my own relay server (recommended)")
-	fmt.Fprintln(stdout, "     Full capability: data relay, file transfer, service proxy.")
-	fmt.Fprintln(stdout, "     Your relay, your rules. Setup: https://shurli.io/docs/relay-setup/")
-	fmt.Fprintln(stdout)
-	fmt.Fprintln(stdout, "  2. Use public seed nodes (limited - discovery only)")
-	fmt.Fprintln(stdout, "     Seed nodes handle peer DISCOVERY only. They do NOT relay data.")
-	fmt.Fprintln(stdout, "     You cannot: relay files, proxy services, or pass any data through seeds.")
-	fmt.Fprintln(stdout, "     You can: discover peers and make direct connections (hole-punching).")
-	fmt.Fprintln(stdout)
-	fmt.Fprint(stdout, "Choice [1]: ")
-
-	choice, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
-	}
-	choice = strings.TrimSpace(choice)
-	if choice == "" {
-		choice = "1"
-	}
-
+	// Network setup: own relay (recommended) or public seed nodes,
+	// unless the template runs without one.
 	var relayAddrs []string
 	var usedSeeds bool
-	switch choice {
-	case "1":
-		relayAddr, err := promptRelayAddress(reader, stdout)
-		if err != nil {
-			return err
+	if bundle != nil {
+		relayAddrs = bundle.Relays
+		fmt.Fprintln(stdout, "Network setup: from bootstrap bundle")
+		fmt.Fprintf(stdout, "  Network: %s\n", networkLabel(network))
+		for _, r := range relayAddrs {
+			fmt.Fprintf(stdout, "  Relay:   %s\n", r)
 		}
-		relayAddrs = []string{relayAddr}
-
 		fmt.Fprintln(stdout)
-		fmt.Fprint(stdout, "Also add public seed nodes for broader peer discovery? [y/N]: ")
-		seedChoice, _ := reader.ReadString('\n')
-		seedChoice = strings.TrimSpace(strings.ToLower(seedChoice))
-		if seedChoice == "y" || seedChoice == "yes" {
-			relayAddrs = append(relayAddrs, HardcodedSeeds...)
-			fmt.Fprintf(stdout, "Added %d public seed nodes.\n", len(HardcodedSeeds))
+	} else if templateUsesRelay(*templateFlag) {
+		relayAddrs, usedSeeds, err = promptNetworkSetup(reader, stdout)
+		if err != nil {
+			return err
 		}
-	case "2":
-		usedSeeds = true
-		relayAddrs = HardcodedSeeds
+	} else {
+		fmt.Fprintln(stdout, "Network setup: direct connections only (no relay).")
+		fmt.Fprintln(stdout, "  Peers must reach this node on the LAN or at a public address.")
 		fmt.Fprintln(stdout)
-		fmt.Fprintf(stdout, "Using %d public seed nodes for DISCOVERY ONLY.\n", len(SeedPeerIDs()))
-		fmt.Fprintln(stdout, "  - No file transfer through seeds")
-		fmt.Fprintln(stdout, "  - No service proxy through seeds")
-		fmt.Fprintln(stdout, "  - No data circuits of any kind")
-		fmt.Fprintln(stdout, "  Direct connections still work when both peers are online.")
-		fmt.Fprintln(stdout, "  Deploy your own relay for full capability: https://shurli.io/docs/relay-setup/")
-	default:
-		return fmt.Errorf("invalid choice: %s (enter 1 or 2)", choice)
 	}
-	fmt.Fprintln(stdout)
 
-	// Set identity password (interactive).
-	fmt.Fprintln(stdout, "Set a password to protect your identity:")
-	fmt.Fprintf(stdout, "  Requirements: %d+ characters, at least 3 of: uppercase, lowercase, digit, symbol\n", validate.MinPasswordLen)
-	fmt.Fprintln(stdout)
+	if !keepIdentity {
+		// Set identity password (interactive).
+		fmt.Fprintln(stdout, "Set a password to protect your identity:")
+		fmt.Fprintf(stdout, "  Requirements: %d+ characters, at least 3 of: uppercase, lowercase, digit, symbol\n", validate.MinPasswordLen)
+		fmt.Fprintln(stdout)
 
-	var password string
-	const maxPasswordAttempts = 3
-	for attempt := 1; attempt <= maxPasswordAttempts; attempt++ {
-		pw, pwErr := readPasswordConfirm("Password: ", "Confirm: ", stdout)
-		if pwErr == nil {
-			password = pw
-			break
-		}
-		fmt.Fprintf(stdout, "  %v\n", pwErr)
-		if attempt < maxPasswordAttempts {
-			fmt.Fprintf(stdout, "  Try again (%d of %d)\n\n", attempt+1, maxPasswordAttempts)
-		} else {
-			return fmt.Errorf("password setup failed after %d attempts", maxPasswordAttempts)
+		const maxPasswordAttempts = 3
+		for attempt := 1; attempt <= maxPasswordAttempts; attempt++ {
+			pw, pwErr := readPasswordConfirm("Password: ", "Confirm: ", stdout)
+			if pwErr == nil {
+				password = pw
+				break
+			}
+			fmt.Fprintf(stdout, "  %v\n", pwErr)
+			if attempt < maxPasswordAttempts {
+				fmt.Fprintf(stdout, "  Try again (%d of %d)\n\n", attempt+1, maxPasswordAttempts)
+			} else {
+				return fmt.Errorf("password setup failed after %d attempts", maxPasswordAttempts)
+			}
 		}
-	}
-	fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout)
 
-	// Save encrypted identity.key.
-	keyFile := filepath.Join(configDir, "identity.key")
-	if err := identity.SaveIdentity(keyFile, privKey, password); err != nil {
-		return fmt.Errorf("failed to save identity: %w", err)
+		// Save encrypted identity.key.
+		if err := identity.SaveIdentity(keyFile, privKey, password); err != nil {
+			return fmt.Errorf("failed to save identity: %w", err)
+		}
 	}
 
 	// Create session token for auto-start.
@@ -329,7 +462,7 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 	fmt.Fprintln(stdout)
 
 	// Create authorized_keys file
-	authKeysFile := filepath.Join(configDir, "authorized_keys")
+	authKeysFile := filepath.Join(configDir, config.ProfileAuthorizedKeysName())
 	if _, err := os.Stat(authKeysFile); os.IsNotExist(err) {
 		authContent := "# authorized_keys - Add peer IDs here (one per line)\n# Format: <peer_id> # optional comment\n"
 		if err := os.WriteFile(authKeysFile, []byte(authContent), 0600); err != nil {
@@ -338,7 +471,10 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 	}
 
 	// Write config file
-	configContent := nodeConfigTemplate(relayAddrs, "shurli init", *networkFlag)
+	configContent, err := nodeConfigTemplateFor(*templateFlag, relayAddrs, "shurli init", network)
+	if err != nil {
+		return err
+	}
 
 	if err := os.WriteFile(configFile, []byte(configContent), 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
@@ -361,6 +497,17 @@ func doInit(args []string, stdin io.Reader, stdout io.Writer) error {
 	// Install shell completions and man page.
 	setupShellEnvironment(stdout)
 
+	// Pair right away when the bundle carries an invite code, before a
+	// service install could start a daemon on the same identity.
+	if bundle != nil && bundle.Invite != "" {
+		data, err := invite.Decode(bundle.Invite)
+		if err != nil {
+			return fmt.Errorf("bundle invite code: %w", err)
+		}
+		fmt.Fprintln(stdout)
+		initPairJoin(data, *asFlag, configFile)
+	}
+
 	// Offer systemd service installation (Linux only, skipped on macOS/Windows).
 	serviceInstalled := promptInstallService(reader, stdout, 0)
 
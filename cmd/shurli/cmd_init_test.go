@@ -2,10 +2,13 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/shurlinet/shurli/internal/invite"
 )
 
 func TestDoInit_FullMultiaddr(t *testing.T) {
@@ -125,6 +128,31 @@ func TestDoInit_InvalidChoice(t *testing.T) {
 	}
 }
 
+func TestDoInit_InvalidTemplate(t *testing.T) {
+	err := doInit([]string{"--dir", t.TempDir(), "--template", "desktop"}, strings.NewReader(""), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "invalid --template") {
+		t.Errorf("err = %v, want invalid --template", err)
+	}
+}
+
+func TestDoInit_MinimalTemplateSkipsRelayPrompt(t *testing.T) {
+	dir := t.TempDir()
+
+	// Identity: new (1). The minimal template has no relay, so the next
+	// prompt is the password, which needs a TTY and fails here.
+	stdin := strings.NewReader("1\n")
+	var stdout bytes.Buffer
+
+	_ = doInit([]string{"--dir", dir, "--skip-seed-confirm", "--template", "minimal"}, stdin, &stdout)
+	out := stdout.String()
+	if strings.Contains(out, "Use my own relay server") {
+		t.Error("minimal template should not ask for a relay")
+	}
+	if !strings.Contains(out, "direct connections only") {
+		t.Errorf("output should explain direct-only setup:\n%s", out)
+	}
+}
+
 func TestDoInit_PublicNetworkDefault(t *testing.T) {
 	// Choosing option 2 selects the public seed network.
 	// Option 1 (default/enter) is now own relay server.
@@ -133,3 +161,72 @@ func TestDoInit_PublicNetworkDefault(t *testing.T) {
 	// on the relay choice itself.
 	t.Skip("doInit requires interactive terminal for seed confirmation and password entry")
 }
+
+func testInitBundle(t *testing.T, network string) string {
+	t.Helper()
+	s, err := invite.EncodeBundle(invite.Bundle{
+		Relays:  []string{"/ip4/203.0.113.10/tcp/7777/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"},
+		Network: network,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDoInit_FromRejectsOtherNetwork(t *testing.T) {
+	var stdout bytes.Buffer
+	err := doInit([]string{"--dir", t.TempDir(), "--network", "home", "--from", testInitBundle(t, "work")}, strings.NewReader(""), &stdout)
+	if err == nil || !strings.Contains(err.Error(), `bundle targets network "work", expected "home"`) {
+		t.Fatalf("err = %v, want namespace mismatch", err)
+	}
+}
+
+func TestDoInit_FromInvalidBundle(t *testing.T) {
+	var stdout bytes.Buffer
+	err := doInit([]string{"--dir", t.TempDir(), "--from", "https://example.com/join"}, strings.NewReader(""), &stdout)
+	if !errors.Is(err, invite.ErrInvalidBundle) {
+		t.Fatalf("err = %v, want ErrInvalidBundle", err)
+	}
+}
+
+func TestDoInit_FromNeedsForceToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("existing"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	err := doInit([]string{"--dir", dir, "--from", testInitBundle(t, "home")}, strings.NewReader(""), &stdout)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("err = %v, want config exists hint about --force", err)
+	}
+	if data, _ := os.ReadFile(cfgPath); string(data) != "existing" {
+		t.Error("existing config was modified")
+	}
+}
+
+func TestDoInit_FromForceKeepsNetwork(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	existing, err := nodeConfigTemplateFor(templateHomeNode, []string{"/ip4/203.0.113.10/tcp/7777/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"}, "test", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "identity.key"), []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	err = doInit([]string{"--dir", dir, "--force", "--from", testInitBundle(t, "work")}, strings.NewReader(""), &stdout)
+	if err == nil || !strings.Contains(err.Error(), `bundle targets network "work"`) {
+		t.Fatalf("err = %v, want refusal to move network", err)
+	}
+	if data, _ := os.ReadFile(cfgPath); string(data) != existing {
+		t.Error("existing config was modified")
+	}
+}
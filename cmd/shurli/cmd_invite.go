@@ -3,13 +3,16 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/shurlinet/shurli/internal/auth"
+	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/internal/invite"
 	"github.com/shurlinet/shurli/internal/qr"
 	"github.com/shurlinet/shurli/internal/termcolor"
 )
@@ -22,24 +25,48 @@ func runInvite(args []string) {
 	countFlag := fs.Int("count", 1, "number of invite codes to generate")
 	remoteFlag := fs.String("remote", "", "relay address (multiaddr, name, or peer ID)")
 	nonInteractive := fs.Bool("non-interactive", false, "machine-friendly output (no QR, bare code to stdout)")
+	qrFlag := fs.Bool("qr", false, "QR code for every code; with --non-interactive, QR codes go to stderr")
 	fs.Parse(reorderFlags(fs, args))
 
+	display := inviteDisplay{nonInteractive: *nonInteractive, qrAll: *qrFlag}
+
+	// Offer a one-step bootstrap bundle when the code is for our own relays.
+	if *remoteFlag == "" {
+		if cfgFile, err := config.FindConfigFile(*configFlag); err == nil {
+			if cfg, err := config.LoadNodeConfig(cfgFile); err == nil {
+				display.bundleRelays = cfg.Relay.Addresses
+				display.bundleNetwork = cfg.Discovery.Network
+			}
+		}
+	}
+
 	// If a daemon is running, delegate to it
 	if client := tryDaemonClient(); client != nil {
-		runInviteViaDaemon(client, *nameFlag, *ttlFlag, *countFlag, *remoteFlag, *nonInteractive)
+		runInviteViaDaemon(client, *nameFlag, *ttlFlag, *countFlag, *remoteFlag, display)
 		return
 	}
 
 	// Standalone mode: connect to relay admin and create invite group directly
-	runInviteStandalone(*configFlag, *nameFlag, *ttlFlag, *countFlag, *remoteFlag, *nonInteractive)
+	runInviteStandalone(*configFlag, *nameFlag, *ttlFlag, *countFlag, *remoteFlag, display)
+}
+
+// inviteDisplay controls how invite codes are printed.
+type inviteDisplay struct {
+	nonInteractive bool // bare codes on stdout, everything else on stderr
+	qrAll          bool // QR for every code (by default only the first, and none when non-interactive)
+
+	// bundleRelays and bundleNetwork, when set, are offered to the joiner
+	// as a "shurli init --from" bundle alongside the plain code.
+	bundleRelays  []string
+	bundleNetwork string
 }
 
 // runInviteStandalone creates an invite by calling the relay admin's CreateGroup.
 // This is async: the invite is stored on the relay. No need to stay online.
-func runInviteStandalone(configFlag, name string, ttl time.Duration, count int, remoteAddr string, nonInteractive bool) {
+func runInviteStandalone(configFlag, name string, ttl time.Duration, count int, remoteAddr string, display inviteDisplay) {
 	out := fmt.Printf
 	outln := fmt.Println
-	if nonInteractive {
+	if display.nonInteractive {
 		out = func(format string, a ...any) (int, error) { return fmt.Fprintf(os.Stderr, format, a...) }
 		outln = func(a ...any) (int, error) { return fmt.Fprintln(os.Stderr, a...) }
 	}
@@ -73,12 +100,12 @@ func runInviteStandalone(configFlag, name string, ttl time.Duration, count int,
 
 	// Record group membership locally so the peer-notify handler accepts
 	// introductions for this group when the daemon starts.
-	authKeysPath := filepath.Join(filepath.Dir(cfgFile), "authorized_keys")
+	authKeysPath := filepath.Join(filepath.Dir(cfgFile), config.ProfileAuthorizedKeysName())
 	if err := auth.SetPeerAttr(authKeysPath, conn.relayPeerID.String(), "group", resp.GroupID); err != nil {
 		slog.Warn("invite: failed to record group on relay entry", "err", err)
 	}
 
-	printInviteCodes(resp.Codes, ttl, nonInteractive)
+	printInviteCodes(os.Stdout, os.Stderr, resp.Codes, ttl, display)
 
 	outln()
 	out("Invite is stored on the relay (group: %s, expires: %s).\n", resp.GroupID, resp.ExpiresAt)
@@ -89,10 +116,10 @@ func runInviteStandalone(configFlag, name string, ttl time.Duration, count int,
 }
 
 // runInviteViaDaemon delegates the invite flow to a running daemon.
-func runInviteViaDaemon(client *daemon.Client, name string, ttl time.Duration, count int, relayAddr string, nonInteractive bool) {
+func runInviteViaDaemon(client *daemon.Client, name string, ttl time.Duration, count int, relayAddr string, display inviteDisplay) {
 	out := fmt.Printf
 	outln := fmt.Println
-	if nonInteractive {
+	if display.nonInteractive {
 		out = func(format string, a ...any) (int, error) { return fmt.Fprintf(os.Stderr, format, a...) }
 		outln = func(a ...any) (int, error) { return fmt.Fprintln(os.Stderr, a...) }
 	}
@@ -106,7 +133,7 @@ func runInviteViaDaemon(client *daemon.Client, name string, ttl time.Duration, c
 		fatal("Daemon returned no invite codes")
 	}
 
-	printInviteCodes(resp.Codes, ttl, nonInteractive)
+	printInviteCodes(os.Stdout, os.Stderr, resp.Codes, ttl, display)
 
 	outln()
 	out("Invite is stored on the relay (group: %s, expires: %s).\n", resp.GroupID, resp.ExpiresAt)
@@ -115,48 +142,71 @@ func runInviteViaDaemon(client *daemon.Client, name string, ttl time.Duration, c
 	out("To revoke:  shurli relay invite revoke %s --remote <relay-addr>\n", resp.GroupID)
 }
 
-// printInviteCodes displays one or more invite codes with optional QR.
-func printInviteCodes(codes []string, ttl time.Duration, nonInteractive bool) {
-	if nonInteractive {
+// printInviteCodes displays one or more invite codes with QR codes
+// rendered by the built-in encoder. The plain code is always printed for
+// copy/paste. In non-interactive mode only the codes go to stdout, and QR
+// codes (with display.qrAll) go to stderr.
+func printInviteCodes(stdout, stderr io.Writer, codes []string, ttl time.Duration, display inviteDisplay) {
+	if display.nonInteractive {
 		for _, code := range codes {
-			fmt.Println(code)
+			fmt.Fprintln(stdout, code)
+			if display.qrAll {
+				printInviteQR(stderr, code)
+			}
 		}
 		return
 	}
 
-	fmt.Println()
-	termcolor.Green("=== Invite Code%s (expires in %s) ===", plural(len(codes)), ttl)
-	fmt.Println()
+	fmt.Fprintln(stdout)
+	termcolor.Wgreen(stdout, "=== Invite Code%s (expires in %s) ===", plural(len(codes)), ttl)
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout)
 
 	for i, code := range codes {
 		if len(codes) > 1 {
-			fmt.Printf("Code %d:\n", i+1)
+			fmt.Fprintf(stdout, "Code %d:\n", i+1)
 		}
-		termcolor.Wgreen(os.Stdout, "%s", code)
-		fmt.Println()
-		fmt.Println()
-
-		// Show QR code for the first code only
-		if i == 0 {
-			q, err := qr.New(code, qr.Medium)
-			if err == nil {
-				fmt.Println("Scan this QR code to join:")
-				fmt.Println()
-				fmt.Print(q.ToSmallString(false))
-			}
+		termcolor.Wgreen(stdout, "%s", code)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout)
+
+		// Show a QR code for the first code, or every code with --qr.
+		if i == 0 || display.qrAll {
+			fmt.Fprintln(stdout, "Scan this QR code to join:")
+			fmt.Fprintln(stdout)
+			printInviteQR(stdout, code)
+		}
+	}
+
+	termcolor.Wfaint(stdout, "--- Send this to the joining peer ---")
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "Install shurli: curl -sSL get.shurli.io | sh")
+	fmt.Fprintln(stdout, "Then run:")
+	fmt.Fprintln(stdout, "  shurli init")
+	fmt.Fprintf(stdout, "  shurli join %s --as <your-device-name>\n", codes[0])
+	fmt.Fprintln(stdout)
+	if len(display.bundleRelays) > 0 {
+		b, err := invite.EncodeBundle(invite.Bundle{Relays: display.bundleRelays, Network: display.bundleNetwork, Invite: codes[0]})
+		if err == nil {
+			fmt.Fprintln(stdout, "Or, on a fresh install, in one step:")
+			fmt.Fprintf(stdout, "  shurli init --from %s --as <your-device-name>\n", b)
+			fmt.Fprintln(stdout)
 		}
 	}
+	termcolor.Wfaint(stdout, "---")
+	fmt.Fprintln(stdout)
+}
 
-	termcolor.Faint("--- Send this to the joining peer ---")
-	fmt.Println()
-	fmt.Println()
-	fmt.Println("Install shurli: curl -sSL get.shurli.io | sh")
-	fmt.Println("Then run:")
-	fmt.Println("  shurli init")
-	fmt.Printf("  shurli join %s --as <your-device-name>\n", codes[0])
-	fmt.Println()
-	termcolor.Faint("---")
-	fmt.Println()
+// printInviteQR writes code as a terminal QR code. A code too long to
+// encode is skipped; the plain code is printed regardless.
+func printInviteQR(w io.Writer, code string) {
+	q, err := qr.New(code, qr.Medium)
+	if err != nil {
+		return
+	}
+	fmt.Fprint(w, q.ToSmallString(false))
+	fmt.Fprintln(w)
 }
 
 // plural returns "s" for count > 1.
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/invite"
+)
+
+func TestPrintInviteCodes(t *testing.T) {
+	codes := []string{"CODE-ONE", "CODE-TWO"}
+	scanLine := "Scan this QR code to join:"
+
+	t.Run("interactive shows QR for first code", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		printInviteCodes(&stdout, &stderr, codes, time.Hour, inviteDisplay{})
+		out := stdout.String()
+		if n := strings.Count(out, scanLine); n != 1 {
+			t.Errorf("QR codes shown = %d, want 1", n)
+		}
+		for _, c := range codes {
+			if !strings.Contains(out, c) {
+				t.Errorf("output missing plain code %s", c)
+			}
+		}
+	})
+
+	t.Run("interactive offers a bootstrap bundle", func(t *testing.T) {
+		token, _ := invite.GenerateToken()
+		code, err := invite.Encode(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		relays := []string{"/ip4/203.0.113.10/tcp/7777/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"}
+		var stdout, stderr bytes.Buffer
+		printInviteCodes(&stdout, &stderr, []string{code}, time.Hour, inviteDisplay{bundleRelays: relays, bundleNetwork: "home"})
+		out := stdout.String()
+		i := strings.Index(out, "shurli init --from ")
+		if i < 0 {
+			t.Fatalf("output missing init --from line:\n%s", out)
+		}
+		b, err := invite.DecodeBundle(strings.Fields(out[i:])[3])
+		if err != nil {
+			t.Fatalf("DecodeBundle: %v", err)
+		}
+		if b.Invite != code || b.Network != "home" || len(b.Relays) != 1 {
+			t.Errorf("bundle = %+v", b)
+		}
+	})
+
+	t.Run("interactive --qr shows QR for every code", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		printInviteCodes(&stdout, &stderr, codes, time.Hour, inviteDisplay{qrAll: true})
+		if n := strings.Count(stdout.String(), scanLine); n != 2 {
+			t.Errorf("QR codes shown = %d, want 2", n)
+		}
+	})
+
+	t.Run("non-interactive --qr keeps stdout bare", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		printInviteCodes(&stdout, &stderr, codes, time.Hour, inviteDisplay{nonInteractive: true, qrAll: true})
+		if got := stdout.String(); got != "CODE-ONE\nCODE-TWO\n" {
+			t.Errorf("stdout = %q, want bare codes", got)
+		}
+		if stderr.Len() == 0 {
+			t.Error("QR codes not written to stderr")
+		}
+	})
+
+	t.Run("non-interactive has no QR by default", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		printInviteCodes(&stdout, &stderr, codes, time.Hour, inviteDisplay{nonInteractive: true})
+		if stderr.Len() != 0 {
+			t.Errorf("unexpected stderr output: %q", stderr.String())
+		}
+	})
+}
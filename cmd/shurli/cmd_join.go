@@ -195,6 +195,9 @@ func runPairJoin(data *invite.InviteData, nameFlag, configFlag, relayAddr string
 	if _, err := s.Read(statusBuf[:]); err != nil {
 		fatal("Failed to read relay response: %v", err)
 	}
+	if statusBuf[0] == relay.StatusRevoked {
+		fatal("Invite code rejected by relay: code revoked. Ask the relay operator for a new one.")
+	}
 	if statusBuf[0] != relay.StatusOK {
 		fatal("Invite code rejected by relay")
 	}
@@ -317,7 +320,7 @@ func runPairJoin(data *invite.InviteData, nameFlag, configFlag, relayAddr string
 	outln("Starting daemon...")
 	var daemonPID int
 	if started := kickServiceDaemon(); !started {
-		daemonCmd := exec.Command(os.Args[0], "daemon")
+		daemonCmd := exec.Command(os.Args[0], append(profileArgs(), "daemon")...)
 		daemonCmd.Stdout = nil
 		daemonCmd.Stderr = nil
 		daemonCmd.SysProcAttr = detachedProcAttr()
@@ -569,7 +572,7 @@ func bootstrapForJoin(relayInput string, userMode bool, stdin io.Reader, stdout
 	if dirErr != nil {
 		return "", fmt.Errorf("cannot determine config directory: %w", dirErr)
 	}
-	configFile := filepath.Join(configDir, "config.yaml")
+	configFile := filepath.Join(configDir, config.ProfileConfigName())
 	if _, err := os.Stat(configFile); err == nil {
 		return "", fmt.Errorf("config already exists: %s", configFile)
 	}
@@ -699,7 +702,7 @@ func bootstrapForJoin(relayInput string, userMode bool, stdin io.Reader, stdout
 		}
 	}
 	fmt.Fprintln(stdout)
-	keyFile := filepath.Join(configDir, "identity.key")
+	keyFile := filepath.Join(configDir, config.ProfileKeyFileName())
 	if err := identity.SaveIdentity(keyFile, privKey, password); err != nil {
 		return "", fmt.Errorf("failed to save identity: %w", err)
 	}
@@ -717,7 +720,7 @@ func bootstrapForJoin(relayInput string, userMode bool, stdin io.Reader, stdout
 	fmt.Fprintln(stdout)
 
 	// Create authorized_keys.
-	authKeysFile := filepath.Join(configDir, "authorized_keys")
+	authKeysFile := filepath.Join(configDir, config.ProfileAuthorizedKeysName())
 	if _, err := os.Stat(authKeysFile); os.IsNotExist(err) {
 		authContent := "# authorized_keys - Add peer IDs here (one per line)\n# Format: <peer_id> # optional comment\n"
 		if err := os.WriteFile(authKeysFile, []byte(authContent), 0600); err != nil {
@@ -2,8 +2,11 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/shurlinet/shurli/internal/config"
 )
 
 // ----- nodeConfigTemplate tests -----
@@ -78,6 +81,77 @@ func TestNodeConfigTemplateDifferentInputs(t *testing.T) {
 	})
 }
 
+func TestNodeConfigTemplatePresets(t *testing.T) {
+	relayAddr := "/ip4/1.2.3.4/tcp/7777/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"
+
+	for _, name := range nodeConfigTemplates {
+		t.Run(name, func(t *testing.T) {
+			out, err := nodeConfigTemplateFor(name, []string{relayAddr}, "shurli init", "my-crew")
+			if err != nil {
+				t.Fatalf("nodeConfigTemplateFor: %v", err)
+			}
+
+			// Every preset must load and validate as written.
+			path := filepath.Join(t.TempDir(), "shurli.yaml")
+			if err := os.WriteFile(path, []byte(out), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.LoadNodeConfig(path)
+			if err != nil {
+				t.Fatalf("LoadNodeConfig: %v\n%s", err, out)
+			}
+			if err := config.ValidateNodeConfig(cfg); err != nil {
+				t.Fatalf("ValidateNodeConfig: %v\n%s", err, out)
+			}
+
+			if !cfg.Security.EnableConnectionGating {
+				t.Error("connection gating should be on in every preset")
+			}
+			if cfg.Discovery.Network != "my-crew" {
+				t.Errorf("discovery.network = %q, want my-crew", cfg.Discovery.Network)
+			}
+			if len(cfg.Services) != 0 {
+				t.Errorf("presets should not expose services, got %v", cfg.Services)
+			}
+
+			switch name {
+			case templateMinimal:
+				if cfg.Relay.IsEnabled() || len(cfg.Relay.Addresses) != 0 {
+					t.Errorf("minimal should run without a relay, got enabled=%v addresses=%v", cfg.Relay.IsEnabled(), cfg.Relay.Addresses)
+				}
+			default:
+				if !cfg.Relay.IsEnabled() || len(cfg.Relay.Addresses) != 1 || cfg.Relay.Addresses[0] != relayAddr {
+					t.Errorf("relay = %+v, want the given address", cfg.Relay)
+				}
+			}
+
+			if got := cfg.Network.ForcePrivateReachability; got != (name == templateRelayClient) {
+				t.Errorf("force_private_reachability = %v", got)
+			}
+			wantTCP := "/ip4/0.0.0.0/tcp/0"
+			if name == templateServer {
+				wantTCP = "/ip4/0.0.0.0/tcp/9100"
+			}
+			if cfg.Network.ListenAddresses[0] != wantTCP {
+				t.Errorf("listen_addresses[0] = %q, want %q", cfg.Network.ListenAddresses[0], wantTCP)
+			}
+		})
+	}
+}
+
+func TestNodeConfigTemplateForUnknown(t *testing.T) {
+	if _, err := nodeConfigTemplateFor("desktop", nil, "shurli init", ""); err == nil {
+		t.Fatal("expected error for unknown template")
+	}
+	home, err := nodeConfigTemplateFor(templateHomeNode, []string{"/ip4/1.2.3.4/tcp/7777/p2p/12D3KooWTest"}, "shurli init", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if home != nodeConfigTemplate([]string{"/ip4/1.2.3.4/tcp/7777/p2p/12D3KooWTest"}, "shurli init", "") {
+		t.Error("home-node preset should match the default template")
+	}
+}
+
 // ----- updateConfigNames tests -----
 
 func TestUpdateConfigNames(t *testing.T) {
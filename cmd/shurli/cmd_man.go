@@ -134,6 +134,7 @@ shurli \- sovereign peer-to-peer networking tool
 
 .SH SYNOPSIS
 .B shurli
+[\fB--profile\fR \fIname\fR]
 .I command
 .RI [ options ]
 .br
@@ -283,15 +284,41 @@ you out.
 The daemon runs the P2P host, listens for incoming connections, and exposes
 a local control API over a Unix socket.
 .TP
-.B daemon
+.B daemon \fR[\fB--no-relay-reservation-wait\fR] [\fB--no-stun\fR] [\fB--config\fR \fIpath|url\fR] [\fB--insecure-config-url\fR] [\fB--fix-perms\fR]
 Start the daemon in the foreground.
+\fB--no-relay-reservation-wait\fR skips the startup wait for relay
+reservations and makes them in the background (same as
+\fBrelay.startup_wait: 0s\fR).
+\fB--no-stun\fR skips STUN probing, so the NAT type is reported as unknown
+(same as \fBnetwork.disable_stun: true\fR). \fBnetwork.stun_servers\fR
+replaces the built-in public STUN servers.
+\fB--config\fR accepts an https URL: the config is downloaded to
+\fI~/.shurli/remote-config.yaml\fR and must parse before it is used. Relative
+key and authorized_keys paths still refer to local files. A failed fetch
+stops startup; there is no fallback to a local config. Plain http requires
+\fB--insecure-config-url\fR.
+At startup the daemon warns if the identity key is readable by other users
+or authorized_keys is writable by them; \fB--fix-perms\fR resets them to
+0600 and 0644 (or tighter) instead.
 .TP
 .B daemon status \fR[\fB--json\fR]
 Query the running daemon for its peer ID, uptime, connected peers, relay
-grant cache, and active proxies.
-.TP
-.B daemon stop
-Send a graceful shutdown signal. Active proxy tunnels are drained before exit.
+grant cache, and active proxies. The \fBtraversal\fR line combines the STUN
+NAT type with real hole punch outcomes to explain whether connections can go
+direct or will stay on the relay (e.g. symmetric NAT or CGNAT).
+.TP
+.B daemon health \fR[\fB--json\fR]
+Lightweight liveness check for monitoring: uptime, peer count, and whether a
+relay reservation is held. Exits 1 when unhealthy (relays are configured but
+none holds a reservation).
+.TP
+.B daemon stop \fR[\fB--drain\fR [\fB--timeout\fR \fIduration\fR]]
+Send a graceful shutdown signal. By default the daemon stops at once and
+closes active proxy tunnels. With \fB--drain\fR it first refuses new proxy
+connections, service streams and relay reservations, and waits for the open
+ones to finish, for at most \fB--timeout\fR (default 30s, maximum 1h).
+\fBdaemon health\fR reports "draining" meanwhile. A signal to the daemon
+ends the drain early.
 .TP
 .B daemon ping \fItarget\fR [\fB-c\fR \fIN\fR] [\fB--interval\fR \fIms\fR] [\fB--json\fR]
 Ping a peer through the daemon. The target can be a peer ID or a friendly
@@ -300,35 +327,106 @@ name from your config. Default: 4 pings at 1-second intervals.
 .B daemon services \fR[\fB--json\fR]
 List services registered with the daemon (both local and remote).
 .TP
-.B daemon peers \fR[\fB--all\fR] [\fB--json\fR]
+.B daemon peers \fR[\fB--all\fR] [\fB--peer\fR \fIname|id\fR] [\fB--watched\fR] [\fB--direct\fR|\fB--relayed\fR] [\fB--sort\fR \fIkey\fR] [\fB--format\fR \fItable|json|yaml\fR]
 List connected peers. By default, shows only authorized peers. Use
-\fB--all\fR to include DHT routing table neighbors.
+\fB--all\fR to include DHT routing table neighbors. \fB--peer\fR shows only
+the given peer (an empty list if it is not connected).
+\fB--watched\fR shows only watched peers (the ones the daemon keeps
+reconnecting), including disconnected ones with their last-seen time and
+reconnect backoff. \fB--direct\fR and \fB--relayed\fR filter
+by connection path. \fB--sort\fR orders by \fBlatency\fR, \fBlastseen\fR or
+\fBpeerid\fR (default). All filters apply to \fB--json\fR output as well.
 .TP
 .B daemon paths \fR[\fB--json\fR]
 Show the current connection path for each peer: LAN, direct, or relayed.
 Includes latency and the relay address if applicable.
 .TP
-.B daemon connect \fB--peer\fR \fIname\fR \fB--service\fR \fIname\fR \fB--listen\fR \fIaddr\fR
+.B daemon inbound \fR[\fB--json\fR]
+Show the inbound service streams open right now: which remote peer is using
+which local service, over which path, for how long, and the bytes moved each
+way. Each peer is marked verified, authorized or unauthorized. The serving
+side counterpart of \fBproxy list\fR.
+.TP
+.B daemon holepunch \fR[\fB--json\fR]
+Show DCUtR hole punch attempts since the daemon started, in total and per
+peer: how many succeeded, whether the peer was ever upgraded from a relayed
+to a direct connection, and the last error for peers that never were. A peer
+that fails every time (typically both sides behind symmetric NAT) will stay
+relayed.
+.TP
+.B daemon stats reset \fR[\fB--json\fR]
+Zero the daemon's session counters (bandwidth totals and rates, last RTT per
+peer) without restarting, to measure a benchmark from a clean start. Persistent
+peer history and Prometheus counters are not reset.
+.TP
+.B daemon connect \fB--peer\fR \fIname\fR \fB--service\fR \fIname\fR \fB--listen\fR \fIaddr\fR \fR[\fB--proto\fR \fItcp\fR|\fIudp\fR]
 Open a persistent TCP proxy through the daemon. Survives brief disconnections.
+Fails up front, listing the peer's available services, if the peer does not
+offer the service to this node. \fB--proto udp\fR listens on a UDP port instead,
+for services the remote exposes with \fBnetwork: udp\fR.
+.TP
+.B daemon connect \fB--peer\fR \fIname\fR \fB--service\fR \fIname\fR \fB--stdio\fR
+Bridge the service to stdin/stdout instead of listening on a port, e.g. as an
+SSH \fBProxyCommand\fR. Exits when the service closes the stream.
 .TP
 .B daemon disconnect \fIid\fR
 Tear down a proxy tunnel by its ID (shown in \fBdaemon status\fR output).
+.TP
+.B daemon events \fR[\fB--since\fR \fIduration|timestamp\fR] [\fB--peer\fR \fIname|id\fR] [\fB--json\fR]
+Follow notification events until interrupted. With \fB--since\fR, replay
+buffered events from that long ago (e.g. \fB5m\fR) or since an RFC3339
+timestamp first. The daemon keeps the last 512 events, up to 24 hours old.
+\fB--peer\fR shows only events about the given peer.
+.TP
+.B daemon messages \fR[\fB--clear\fR] [\fB--json\fR]
+Show text messages received from peers with \fBshurli msg\fR, oldest first.
+The daemon keeps the last 100 in memory. \fB--clear\fR empties the inbox.
+.TP
+.B daemon install \fR[\fB--config\fR \fIpath\fR] [\fB--no-start\fR]
+Register the daemon with the OS service manager and start it: a launchd
+agent on macOS, a Windows service on Windows (run as Administrator).
+On Linux this is a no-op; the daemon runs under systemd.
+.TP
+.B daemon uninstall
+Stop and remove the launchd agent or Windows service.
+.TP
+.B daemon service-start\fR, \fBdaemon service-stop
+Start, or gracefully stop, the installed service.
 
 .SH NETWORK TOOLS
 These commands create a temporary P2P host, perform their operation, and exit.
 They do not require a running daemon. Useful for quick diagnostics.
 .TP
-.B ping \fItarget\fR [\fB-c\fR \fIN\fR] [\fB--interval\fR \fI1s\fR] [\fB--json\fR]
+.B ping \fItarget\fR [\fB-c\fR \fIN\fR] [\fB--interval\fR \fI1s\fR] [\fB--json\fR] [\fB-a\fR]
 P2P ping. Measures round-trip time over the encrypted tunnel. With \fB-c 0\fR,
-pings continuously until interrupted.
+pings continuously until interrupted. \fItarget\fR is a name, a peer ID, or a
+full multiaddr ending in /p2p/\fIpeer-id\fR, which is dialed directly at that
+address without a DHT lookup. \fB-a\fR (\fB--audible\fR) rings the terminal
+bell on each reply. Exits 0 if every ping was answered, 1 if some were lost,
+and 2 if none were answered or the target could not be resolved or reached.
+.TP
+.B ping --targets \fIa,b,...\fR [\fB-c\fR \fIN\fR] [\fB--json\fR]
+Ping several peers concurrently through the daemon (default \fB-c 3\fR) and
+print one row per target: average RTT, loss and path. A target that fails to
+resolve or connect is reported in its row and does not abort the others. Exits
+non-zero if any target was unreachable.
 .TP
 .B traceroute \fItarget\fR [\fB--json\fR]
 Trace the P2P path to a peer. Shows whether the connection is direct or relayed,
-and the relay hops involved.
+and the relay hops involved. \fItarget\fR may be a full multiaddr, as for \fBping\fR.
+.TP
+.B bwtest \fItarget\fR [\fB--bytes\fR \fI100MB\fR] [\fB--direction\fR \fIup|down|both\fR] [\fB--json\fR]
+Measure throughput to a peer over /shurli/bwtest/1.0.0 and report Mbps per
+direction, plus whether the path was DIRECT or RELAYED. \fBboth\fR (the default)
+runs upload and download at the same time. The peer must run a daemon and
+authorize you; it refuses tests over 1GB per direction. Relays may cut a test
+short when their data limits are reached.
 .TP
-.B resolve \fIname\fR [\fB--json\fR]
+.B resolve \fIname\fR [\fB--json\fR] [\fB--no-cache\fR|\fB--refresh\fR]
 Look up a friendly name in your config and resolve it to a peer ID. Also queries
-the DHT if the name is not found locally.
+the DHT if the name is not found locally. Addresses cached in peer_cache.json by
+an earlier DHT lookup are listed too (\fB--no-cache\fR hides them);
+\fB--refresh\fR drops them so the next connect looks the peer up again.
 .TP
 .B proxy add \fIname\fR \fIpeer\fR \fIservice\fR \fIport\fR
 Create a persistent proxy that survives daemon restarts. The proxy binds
@@ -360,6 +458,24 @@ instead.
 Clear all dial backoffs for a peer and trigger immediate reconnection. Resets both
 the swarm-level and PeerManager-level backoff state. Useful for AI agents and operators
 to recover a peer from exponential backoff without waiting for it to expire naturally.
+.TP
+.B msg \fItarget\fR \fI"text"\fR [\fB--json\fR]
+Send a short text message (up to 1024 bytes) to a peer through the daemon,
+e.g. "I'm online, try connecting now". Works over relayed connections. Only
+peers in the recipient's authorized_keys can deliver messages; they appear in
+the recipient's \fBdaemon messages\fR.
+.TP
+.B remote \-\-peer \fInode\fR \fIcommand\fR [\fB--json\fR]
+Run a command on another node's daemon over the P2P network, without SSH.
+Commands: \fBstatus\fR, \fBpeers\fR, \fBservices\fR, \fBpaths\fR,
+\fBinbound\fR, \fBbandwidth\fR, \fBrelay-health\fR, \fBauth\fR,
+\fBreload\fR, \fBping\fR \fItarget\fR (ping from the remote node) and
+\fBapi\fR \fIMETHOD\fR \fIPATH\fR [\fIJSON\fR] for any other daemon
+API endpoint. The request goes through the local daemon over
+/shurli/admin/1.0.0; the remote node must list this node's peer ID in its
+\fBsecurity.admin_peers\fR. Every remote request is logged and audited on
+the remote node. Shutdown, lock/unlock, invites, proxies and event streams
+stay local-only.
 PLUGIN_MAN_PLACEHOLDER
 .SH IDENTITY & ACCESS
 Access control in shurli is based on
@@ -375,28 +491,70 @@ Peers have roles:
 (can connect, use services). The first peer paired is automatically promoted
 to admin.
 .TP
-.B whoami
+.B whoami \fR[\fB--addresses\fR] [\fB--fingerprint\fR] [\fB--json\fR] [\fB--watch-reachability\fR [\fB--timeout\fR \fIduration\fR]]
 Print your peer ID. This is the value other peers add to their authorized_keys.
-.TP
-.B auth add \fIpeer-id\fR [\fB--comment\fR \fI"..."\fR] [\fB--role\fR \fIadmin|member\fR]
+When a relay is known, also print a connect hint: a relay circuit multiaddr
+ending in your peer ID that \fBping\fR and \fBtraceroute\fR accept as a target.
+It uses the running daemon's live relay addresses, or the configured relays.
+With \fB--addresses\fR, also print every address the running daemon
+advertises, labelled \fIdirect public\fR, \fIdirect local\fR or
+\fIRELAY\fR, and the addresses connected peers observed us at (learned via
+identify, with the reporting peers). Observed addresses show what your NAT
+actually maps you to. \fB--json\fR prints all of this, including the
+fingerprint, as JSON.
+With \fB--fingerprint\fR, also print a short fingerprint of the identity as six
+words and as grouped hex, for reading aloud ("read me your fingerprint").
+\fBverify\fR shows the same fingerprint for both peers.
+With \fB--watch-reachability\fR, poll the running daemon and print each
+change between \fIunreachable\fR, \fIreachable via relay\fR and
+\fIreachable directly\fR with a timestamp. Reachable directly means the
+daemon's reachability grade is A or B. Stops once reachable directly, once
+reachable via relay for 30 seconds, or after \fB--timeout\fR (default 2m);
+exits non-zero if the node never became reachable.
+.TP
+.B auth add \fIpeer-id\fR [\fB--comment\fR \fI"..."\fR] [\fB--role\fR \fIadmin|member\fR] [\fB--ttl\fR \fIduration\fR]
 Add a peer to your authorized_keys. The comment is for your reference only.
-Default role: member.
+Default role: member. With \fB--ttl\fR (e.g. 24h; \fB--expires\fR is the same
+option), the peer gets an expires attribute. Once it passes the peer is no
+longer loaded or authorized, and the daemon removes and disconnects it.
 .TP
-.B auth list
-List all authorized peers with their roles, comments, and verification status.
+.B auth list \fR[\fB--group\fR \fIid\fR | \fB--ungrouped\fR] [\fB--comment-contains\fR \fItext\fR] [\fB--format\fR \fItable|json|yaml\fR]
+List all authorized peers with their roles, comments, and verification status,
+followed by a count of peers per group. \fB--group\fR keeps one pairing group;
+\fB--ungrouped\fR (or \fB--group ""\fR) keeps peers with no group.
+\fB--comment-contains\fR matches comments case-insensitively. Filters combine.
 .TP
 .B auth remove \fIpeer-id\fR
 Revoke a peer. Takes effect immediately; existing connections from that peer
 are terminated.
 .TP
+.B auth prune
+Rewrite authorized_keys without the peers whose expires attribute has passed.
+.TP
+.B auth export \fR[\fB--json\fR]
+Print every authorized peer with its comment and attributes. Without
+\fB--json\fR the output is authorized_keys lines; with it, a JSON array that
+\fBauth import\fR reads on another node.
+.TP
+.B auth import \fIfile\fR
+Merge peers from an \fBauth export --json\fR file (\fB-\fR reads stdin). New
+peers are added; for existing peers only missing attributes are added, and a
+different comment is reported as a conflict and left unchanged. Invalid
+entries are reported without aborting the import.
+.TP
 .B auth validate \fR[\fIfile\fR]
 Check the authorized_keys file for syntax errors, duplicate entries, and
 invalid peer IDs.
 .TP
 .B auth set-attr \fIpeer-id\fR \fIkey\fR \fIvalue\fR
 Set a peer attribute in authorized_keys. Allowed keys: role, group,
-verified, bandwidth_budget. Bandwidth budget values: unlimited, or a size
-like 500MB, 1GB, 10GB.
+verified, bandwidth_budget, schedule. Bandwidth budget values: unlimited, or a size
+like 500MB, 1GB, 10GB. Schedule values restrict the peer to
+time-of-day windows in local time, e.g. mon-fri@09:00-17:00 or
+mon-fri@08:00-12:00,sat@10:00-14:00; a window ending before it starts runs
+overnight. Outside its windows the peer is refused, not redialed, and
+disconnected within a minute of the window closing. An empty value removes
+the schedule.
 .TP
 .B auth grant \fIpeer\fR [\fB--duration\fR \fI1h\fR] [\fB--services\fR \fIfile-transfer,...\fR] [\fB--permanent\fR] [\fB--delegate\fR \fIN\fR]
 Grant relay data access to a peer using macaroon capability tokens.
@@ -433,12 +591,25 @@ Default: last 20 entries (configurable with \fB--tail\fR).
 
 .SH CONFIGURATION
 .TP
-.B init \fR[\fB--dir\fR \fIpath\fR] [\fB--network\fR \fInamespace\fR]
+.B init \fR[\fB--dir\fR \fIpath\fR] [\fB--network\fR \fInamespace\fR] [\fB--template\fR \fIname\fR] [\fB--from\fR \fIbundle\fR] [\fB--as\fR \fIname\fR] [\fB--force\fR]
 Interactive first-time setup. Creates the config directory, generates an
 Ed25519 identity key, and writes config.yaml. Prompts for relay choice:
 own relay server (recommended, full capability) or public seed nodes
 (discovery only, no data relay). Installs shell completions and the man page.
 The \fB--network\fR flag creates a private DHT namespace.
+\fB--template\fR picks a config preset: \fBhome-node\fR (default, NAT'd
+machine exposing services), \fBrelay-client\fR (laptop or phone reaching
+peers through a relay), \fBminimal\fR (direct connections only, no relay
+prompt) or \fBserver\fR (public IP, fixed port 9100).
+\fB--from\fR takes a bootstrap bundle (a \fBshurli-bundle:\fR string, or a
+URL carrying one in its \fBbundle\fR parameter or fragment) with the relay
+addresses, DHT namespace and optionally an invite code. The relay and
+identity prompts are skipped, and when the bundle has an invite code the
+device pairs right away as \fB--as\fR \fIname\fR. A bundle for a different
+namespace than \fB--network\fR (or than the config being replaced) is
+rejected. \fBshurli invite\fR prints a ready-made bundle. An existing config
+is never overwritten without \fB--force\fR, which keeps the existing identity
+(its password is asked for) and replaces only the config.
 .TP
 .B config validate \fR[\fB--config\fR \fIpath\fR]
 Parse and validate the config file. Reports errors without starting anything.
@@ -456,32 +627,58 @@ both the mode and duration in a single command. Apply without restart:
 .TP
 .B config reload \fR[\fB--json\fR] [\fB--status\fR]
 Reload the running daemon's config from disk without restarting. Reports
-which fields changed. Use \fB--status\fR to check the last reload result
+which fields changed. Services are exposed, unexposed or have their ACL
+updated to match the services section; unchanged services are untouched. Use \fB--status\fR to check the last reload result
 without triggering a new reload.
 .TP
 .B config rollback \fR[\fB--config\fR \fIpath\fR]
 Replace the current config with the last-known-good backup (created
 automatically before each \fBconfig apply\fR).
 .TP
-.B config apply \fInew-config\fR [\fB--confirm-timeout\fR \fIduration\fR]
+.B config diff \fInew-config\fR [\fB--config\fR \fIpath\fR]
+Validate \fInew-config\fR and print what applying it would change, without
+writing anything. Prints a WARNING when the change would disable connection
+gating or remove the last relay, the two changes most likely to lock you out
+of a remote node.
+.TP
+.B config apply \fInew-config\fR [\fB--confirm-timeout\fR \fIduration\fR] [\fB--dry-run\fR]
 Swap in a new config with a dead-man's switch: if \fBconfig confirm\fR is not
 run within the timeout (default: 5 minutes), the previous config is restored
-automatically. Designed for safe remote config changes.
+automatically. Designed for safe remote config changes. Before the timer
+starts, prints what changes (\fB+\fR added, \fB-\fR removed, \fB~\fR changed),
+e.g. relay addresses, services and security flags, with the same warnings as
+\fBconfig diff\fR. \fB--dry-run\fR stops after the preview.
 .TP
 .B config confirm \fR[\fB--config\fR \fIpath\fR]
 Accept the currently applied config, cancelling the auto-revert timer.
+.TP
+.B config edit \fR[\fB--config\fR \fIpath\fR]
+Open a copy of the config in \fB$VISUAL\fR or \fB$EDITOR\fR (default
+\fBvi\fR). On save the copy is validated; only a valid config replaces the
+original, and the changes are printed. An invalid edit leaves the original
+untouched and keeps the edited copy in a temp file. Exiting the editor with
+an error aborts, and an unchanged file is a no-op.
+.TP
+.B config schema
+Print a JSON Schema for the node config, generated from the config structs:
+required fields, duration and data-size formats, and allowed values. Save it
+and point yaml-language-server at it for editor completion and validation.
 
 .SH RELAY CLIENT COMMANDS
 These commands manage relay server addresses in your local
 .I shurli.yaml.
 A node typically has one relay, but you can configure multiple for redundancy.
 .TP
-.B relay add \fIaddress\fR [\fB--peer-id\fR \fIID\fR]
+.B relay add \fIaddress\fR [\fB--peer-id\fR \fIID\fR] [\fB--verify\fR]
 Add a relay. Accepts a full multiaddr
 (\fB/ip4/203.0.113.50/tcp/7777/p2p/12D3KooW...\fR) or shorthand
 (\fB203.0.113.50:7777\fR with \fB--peer-id\fR).
+If both the address and \fB--peer-id\fR carry a peer ID they must match;
+a multiaddr without \fB/p2p/\fR gets the \fB--peer-id\fR appended.
+\fB--verify\fR dials the relay first and refuses to add it unless the live
+peer ID matches.
 .TP
-.B relay list
+.B relay list \fR[\fB--format\fR \fItable|json|yaml\fR]
 Show all configured relay addresses.
 .TP
 .B relay remove \fImultiaddr\fR [\fB--force\fR]
@@ -530,6 +727,12 @@ If omitted, the relay's configured session_data_limit is used as default.
 .B relay grants [\fB--remote\fR \fIaddr\fR]
 List all active data relay grants with remaining time.
 .TP
+.B relay stats [\fB--json\fR] [\fB--days\fR] [\fB--remote\fR \fIaddr\fR]
+Show bytes relayed per peer (today and since the relay started) and per
+authorized_keys group (today). Every circuit is counted, including admin
+peers. The daily rollup (UTC) is kept for 30 days in relay-accounting.json
+next to the relay config; --days prints it.
+.TP
 .B relay revoke \fIpeer-id\fR [\fB--remote\fR \fIaddr\fR]
 Revoke a peer's data relay grant and terminate all active circuits.
 .TP
@@ -546,19 +749,34 @@ Verify a peer's identity using a Short Authentication String (SAS). Displays
 emoji and numeric codes that must match on both sides. Marks the peer as
 verified in the relay's authorized_keys on confirmation.
 .TP
-.B relay info
-Display the relay's peer ID, all multiaddrs it is listening on, and a
-QR code for easy mobile pairing.
+.B relay selftest \fR[\fB--size\fR \fI4MB\fR] [\fB--timeout\fR \fI60s\fR]
+Check the running relay end to end. Two throwaway peers are temporarily
+authorized (and granted data access on a signaling-only relay); one reserves
+a slot, the other opens a circuit to it, and test data is sent through.
+Reports latency and throughput, or which stage failed and the limits to
+check. The temporary peers and grant are removed afterwards.
+.TP
+.B relay info \fR[\fB--json\fR]
+Display the relay's peer ID, configured region, all multiaddrs it is
+listening on, and a QR code for easy mobile pairing. \fB--json\fR prints
+the peer ID, name, region, gating state and multiaddrs without the QR code.
 .TP
 .B relay invite create \fR[\fB--ttl\fR \fI1h\fR] [\fB--expires\fR \fIduration\fR] [\fB--remote\fR \fIaddr\fR]
 Generate a single-use invite code. Share the code with the joining peer
 who uses \fBshurli join <code>\fR. Codes expire after the TTL.
 .TP
-.B relay invite list \fR[\fB--remote\fR \fIaddr\fR]
-List active invites with usage status.
+.B relay invite list \fR[\fB--all\fR] [\fB--remote\fR \fIaddr\fR]
+List invites that can still be redeemed, with group ID, outstanding code
+count and time left, soonest expiry first. \fB--all\fR also shows used,
+revoked and expired invites, and void ones: codes not yet used when the
+relay restarted, which can no longer be redeemed.
 .TP
-.B relay invite revoke \fR\fIid\fR [\fB--remote\fR \fIaddr\fR]
-Revoke an unused invite.
+.B relay invite revoke \fR\fIcode\fR|\fIgroup-id\fR [\fB--remote\fR \fIaddr\fR]
+Revoke an invite before it is used. A code revokes just that code; a group
+ID revokes every unused code in the group. Peers that already joined are
+not affected. Revocations are saved in \fIrelay-pairing.json\fR next to the
+relay config and survive restarts; a joiner presenting a revoked code is
+told "code revoked".
 .TP
 .B relay show
 Show the resolved relay config (alias for relay config show).
@@ -572,6 +790,9 @@ Validate the relay config without starting.
 .B relay config rollback
 Restore the last-known-good relay config.
 .TP
+.B relay config schema
+Print a JSON Schema for \fIrelay-server.yaml\fR (see \fBconfig schema\fR).
+.TP
 .B relay recover
 Recover relay identity from a BIP39 seed phrase.
 .TP
@@ -704,9 +925,16 @@ peers. Each service has a name (e.g., "ssh", "grafana") and a local address
 .B shurli proxy
 to reach these services through the encrypted tunnel.
 .TP
-.B service add \fIname\fR \fIaddress\fR [\fB--protocol\fR \fIid\fR]
+.B service add \fIname\fR \fIaddress\fR [\fB--protocol\fR \fIid\fR] [\fB--require-verified\fR]
 Register a new service. The address must be reachable on the local machine.
 The optional \fB--protocol\fR overrides the default libp2p protocol ID.
+\fB--require-verified\fR restricts the service to peers verified with
+\fBshurli verify\fR; authorized but unverified peers are refused.
+An address with a port range (\fIhost\fR:\fIfirst\fR-\fIlast\fR, up to 256
+ports) exposes one service per port, named \fIname\fR-\fIport\fR. Peers
+address a single port as \fIname\fR:\fIport\fR (e.g.
+\fBshurli proxy home myapp:8005 8005\fR). Ranges may not overlap other
+services on the same host and cannot take \fB--protocol\fR.
 .TP
 .B service remove \fIname\fR
 Remove a service. Active connections to it are dropped.
@@ -717,7 +945,7 @@ Re-enable a previously disabled service.
 .B service disable \fIname\fR
 Stop accepting connections for this service without removing its config.
 .TP
-.B service list
+.B service list \fR[\fB--format\fR \fItable|json|yaml\fR]
 List all services with their name, address, enabled status, and protocol ID.
 
 .SH PAIRING
@@ -729,9 +957,11 @@ secret is the invite code itself, so no pre-existing secure channel is needed.
 After pairing, both peers are added to each other's authorized_keys
 automatically.
 .TP
-.B invite \fR[\fB--as\fR \fI"home"\fR] [\fB--ttl\fR \fIduration\fR] [\fB--non-interactive\fR]
+.B invite \fR[\fB--as\fR \fI"home"\fR] [\fB--ttl\fR \fIduration\fR] [\fB--non-interactive\fR] [\fB--qr\fR]
 Generate a one-time invite code and wait for a peer to join. The
-\fB--as\fR flag sets your node's name on the network.
+\fB--as\fR flag sets your node's name on the network. The first code is
+also shown as a QR code; \fB--qr\fR shows one for every code, and with
+\fB--non-interactive\fR writes them to stderr.
 Default TTL: 10 minutes.
 .TP
 .B join \fIcode\fR [\fB--as\fR \fI"laptop"\fR] [\fB--non-interactive\fR]
@@ -804,22 +1034,44 @@ Show a summary of local configuration and daemon state: config file path,
 identity, relay addresses, relay grant cache (budget, remaining time, session
 usage per relay), authorized peers, and registered services.
 .TP
-.B doctor \fR[\fB--fix\fR]
-Health check for your shurli installation. Verifies:
+.B history export \fR[\fB--format\fR \fIcsv\fR|\fIjson\fR] [\fB--config\fR \fIpath\fR]
+Export the peer interaction history the daemon records in
+\fIpeer_history.json\fR: one row per peer with first/last seen, connection
+count, average latency, connection counts per path type, and how the peer was
+introduced. Reads the file directly; no running daemon is needed.
+.TP
+.B history peer \fIpeer\fR [\fB--json\fR]
+Show a peer's recent connections from the running daemon: how often it
+connected DIRECT versus RELAYED over the past day, how many times the path
+changed, latency, and the most recent connections. The daemon keeps the last
+100 connections per peer.
+.TP
+.B doctor \fR[\fB--fix\fR] [\fB--json\fR] [\fB--config\fR \fIpath\fR]
+Health check for your shurli installation and config. Each check passes,
+warns or fails, with a hint on how to fix it. Verifies:
 .RS
 .IP \(bu 2
-Config file exists and is valid
+Config file exists, parses and passes validation
 .IP \(bu 2
-Identity key exists
+Identity key exists, is readable and encrypted, and is mode 0600
+.IP \(bu 2
+authorized_keys lists at least one peer when connection gating is enabled,
+and is not writable by others
+.IP \(bu 2
+Every relay address parses, including its /p2p/ peer ID
+.IP \(bu 2
+discovery.rendezvous is set
+.IP \(bu 2
+Fixed listen ports are free (skipped while the daemon is running)
 .IP \(bu 2
 Shell completions are installed and up to date
 .IP \(bu 2
 Man page is installed
 .RE
 .IP
-Use \fB--fix\fR to automatically repair any issues found. After upgrading
-shurli, run \fBdoctor --fix\fR to update completions and the man page for
-new commands.
+Exits non-zero if any check fails. Use \fB--fix\fR to repair file
+permissions, completions and the man page. After upgrading shurli, run
+\fBdoctor --fix\fR to update completions and the man page for new commands.
 .TP
 .B completion \fIbash\fR|\fIzsh\fR|\fIfish\fR
 Print a shell completion script to stdout. Completions are installed
@@ -880,7 +1132,16 @@ comment, expires, verified, group. Syntax is validated by \fBauth validate\fR.
 .TP
 .I ./shurli.yaml
 Alternate config location. Shurli searches the current directory first,
-then /etc/shurli/config.yaml, then ~/.shurli/config.yaml. Override with \fB--config\fR.
+then /etc/shurli/config.yaml, then ~/.shurli/config.yaml. Override with \fB--config\fR
+or \fBSHURLI_CONFIG\fR.
+.TP
+.I ~/.shurli/<name>.yaml
+Config for the profile selected with the global \fB--profile\fR \fIname\fR
+option (given before the command). The profile uses \fI<name>.key\fR and
+\fI<name>-authorized_keys\fR, and its daemon listens on \fI<name>.sock\fR,
+so several profiles can share one config directory and run daemons side by
+side. Names are 1-63 lowercase letters, digits or hyphens; \fBconfig\fR,
+\fBshurli\fR, \fBrelay-server\fR and \fBidentity\fR are reserved.
 .TP
 .I relay-server.yaml
 Relay server configuration. Contains listen addresses, authorized_keys path,
@@ -895,6 +1156,11 @@ Installed man page (created by \fBshurli init\fR or \fBshurli man --install\fR).
 Used by \fBshurli init\fR and \fBshurli doctor\fR to detect your shell and
 install the correct completion script.
 .TP
+.B SHURLI_CONFIG
+Path to the config file. Takes precedence over the standard search paths
+but not over \fB--config\fR. The file must exist. \fBPEERUP_CONFIG\fR is
+honored as a legacy name when \fBSHURLI_CONFIG\fR is unset.
+.TP
 .B SHURLI_INVITE_CODE
 Invite code for the \fBjoin\fR command. Alternative to the positional argument.
 Useful for scripted or non-interactive pairing.
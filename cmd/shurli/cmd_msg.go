@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/internal/termcolor"
+)
+
+func runMsg(args []string) {
+	runWithJSON(doMsg(args, os.Stdout))
+}
+
+// doMsg sends a short text message to a peer through the running daemon.
+func doMsg(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("msg", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return err
+	}
+
+	errOut := func(err error) error {
+		if *jsonFlag {
+			return jsonErr(stdout, err)
+		}
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		return errOut(fmt.Errorf("usage: shurli msg <target> \"text\" [--json]"))
+	}
+	target := fs.Arg(0)
+	text := strings.Join(fs.Args()[1:], " ")
+
+	client, err := daemon.NewClient(daemonSocketPath(), daemonCookiePath())
+	if err != nil {
+		return errOut(err)
+	}
+
+	resp, err := client.SendMessage(target, text)
+	if err != nil {
+		return errOut(err)
+	}
+
+	if *jsonFlag {
+		return writeJSON(stdout, resp)
+	}
+	termcolor.Green("Message delivered to %s", resp.Peer)
+	return nil
+}
+
+func runDaemonMessages(args []string) {
+	runWithJSON(doDaemonMessages(args, os.Stdout))
+}
+
+// doDaemonMessages prints (or clears) the daemon's inbox of received
+// messages.
+func doDaemonMessages(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("daemon messages", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	clearFlag := fs.Bool("clear", false, "empty the inbox")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return err
+	}
+
+	errOut := func(err error) error {
+		if *jsonFlag {
+			return jsonErr(stdout, err)
+		}
+		return err
+	}
+
+	client, err := daemon.NewClient(daemonSocketPath(), daemonCookiePath())
+	if err != nil {
+		return errOut(err)
+	}
+
+	if *clearFlag {
+		n, err := client.ClearMessages()
+		if err != nil {
+			return errOut(err)
+		}
+		if *jsonFlag {
+			return writeJSON(stdout, map[string]int{"cleared": n})
+		}
+		fmt.Fprintf(stdout, "Cleared %d message(s)\n", n)
+		return nil
+	}
+
+	msgs, err := client.Messages()
+	if err != nil {
+		return errOut(err)
+	}
+	if *jsonFlag {
+		return writeJSON(stdout, msgs)
+	}
+	if len(msgs) == 0 {
+		fmt.Fprintln(stdout, "No messages.")
+		return nil
+	}
+	for _, m := range msgs {
+		from := m.FromName
+		if from == "" {
+			from = truncateID(m.From)
+		}
+		fmt.Fprintf(stdout, "[%s] %s: %s\n", m.Received.Local().Format("2006-01-02 15:04:05"), from, m.Text)
+	}
+	return nil
+}
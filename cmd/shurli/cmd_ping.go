@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,8 +16,36 @@ import (
 	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
+// Exit codes of a single-target ping, so monitoring scripts can tell a
+// flaky peer from a dead one: shurli ping home -c 3 || alert
+const (
+	pingExitOK          = 0 // every ping answered
+	pingExitLoss        = 1 // some pings lost (usage and setup errors also exit 1)
+	pingExitUnreachable = 2 // no reply at all, or the target could not be resolved or reached
+)
+
+// pingExitCode maps a finished ping's statistics to its exit code.
+func pingExitCode(stats sdk.PingStats) int {
+	switch {
+	case stats.Received == 0:
+		return pingExitUnreachable
+	case stats.Received < stats.Sent:
+		return pingExitLoss
+	default:
+		return pingExitOK
+	}
+}
+
+// exitPing exits with code unless it is pingExitOK, in which case the
+// command returns normally.
+func exitPing(code int) {
+	if code != pingExitOK {
+		osExit(code)
+	}
+}
+
 func runPing(args []string) {
-	args = reorderArgs(args, map[string]bool{"json": true})
+	args = reorderArgs(args, map[string]bool{"json": true, "a": true, "audible": true})
 
 	fs := flag.NewFlagSet("ping", flag.ExitOnError)
 	configFlag := fs.String("config", "", "path to config file")
@@ -25,32 +54,66 @@ func runPing(args []string) {
 	intervalStr := fs.String("interval", "1s", "interval between pings")
 	jsonFlag := fs.Bool("json", false, "output as JSON (one line per ping)")
 	standaloneFlag := fs.Bool("standalone", false, "use direct P2P without daemon (debug)")
+	targetsFlag := fs.String("targets", "", "comma-separated targets to ping concurrently")
+	audible := fs.Bool("audible", false, "ring the terminal bell on each reply")
+	fs.BoolVar(audible, "a", false, "alias for --audible")
 	fs.Parse(reorderFlags(fs, args))
 
 	remaining := fs.Args()
-	if len(remaining) < 1 {
-		fmt.Println("Usage: shurli ping [--config <path>] [-c N] [--interval 1s] [--json] [--standalone] <target>")
+	if len(remaining) < 1 && *targetsFlag == "" {
+		fmt.Println("Usage: shurli ping [--config <path>] [-c N] [--interval 1s] [--json] [-a] [--standalone] <target>")
+		fmt.Println("       shurli ping --targets <a,b,...> [-c N] [--interval 1s] [--json]")
 		fmt.Println()
 		fmt.Println("Options:")
 		fmt.Println("  -c, -n N       Number of pings (0 = continuous, default)")
 		fmt.Println("  --interval 1s  Time between pings (default: 1s)")
 		fmt.Println("  --json         Output each ping as a JSON line")
+		fmt.Println("  -a, --audible  Ring the terminal bell on each reply")
 		fmt.Println("  --standalone   Use direct P2P without daemon (debug)")
+		fmt.Println("  --targets a,b  Ping several targets concurrently and print a summary")
+		fmt.Println("                 table (default -c 3; needs the daemon)")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  shurli ping home-server")
 		fmt.Println("  shurli ping home-server -c 5")
 		fmt.Println("  shurli ping 12D3KooWPrmh... -c 3 --json")
+		fmt.Println("  shurli ping /ip4/203.0.113.7/udp/9100/quic-v1/p2p/12D3KooWPrmh... -c 3")
+		fmt.Println("  shurli ping --targets home,laptop,vps -c 3")
+		fmt.Println()
+		fmt.Println("A full multiaddr target is dialed directly, without a DHT lookup.")
+		fmt.Println()
+		fmt.Println("Exit status (single target): 0 all pings answered, 1 some lost,")
+		fmt.Println("2 no reply or target unresolvable/unreachable. With --targets: 0 if")
+		fmt.Println("every target answered, 1 otherwise.")
 		osExit(1)
 	}
 
-	target := remaining[0]
-
 	interval, err := time.ParseDuration(*intervalStr)
 	if err != nil {
 		fatal("Invalid interval %q: %v", *intervalStr, err)
 	}
 
+	if *targetsFlag != "" {
+		targets := splitTargets(*targetsFlag)
+		if len(remaining) > 0 || len(targets) == 0 {
+			fatal("--targets takes a comma-separated list and no positional target")
+		}
+		if *standaloneFlag {
+			fatal("--targets requires the daemon and cannot be combined with --standalone")
+		}
+		client := tryDaemonClient()
+		if client == nil {
+			fatal("Daemon not running. Start it with: shurli daemon")
+		}
+		if err := doBulkPing(client.Ping, targets, *count, int(interval.Milliseconds()), *jsonFlag, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
+		return
+	}
+
+	target := remaining[0]
+
 	// Standalone allowed via CLI flag or config setting.
 	allowStandalone := *standaloneFlag || configAllowsStandalone(*configFlag)
 
@@ -59,9 +122,9 @@ func runPing(args []string) {
 		if client := tryDaemonClient(); client != nil {
 			if *count == 0 {
 				// Continuous: loop single pings client-side, Ctrl+C stops.
-				runPingViaDaemonContinuous(client, target, int(interval.Milliseconds()), *jsonFlag)
+				runPingViaDaemonContinuous(client, target, int(interval.Milliseconds()), *jsonFlag, *audible)
 			} else {
-				runPingViaDaemon(client, target, *count, int(interval.Milliseconds()), *jsonFlag)
+				runPingViaDaemon(client, target, *count, int(interval.Milliseconds()), *jsonFlag, *audible)
 			}
 			return
 		}
@@ -109,7 +172,8 @@ func runPing(args []string) {
 
 	targetPeerID, err := standalone.ResolveAndConnect(ctx, target)
 	if err != nil {
-		fatal("%v", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		osExit(pingExitUnreachable)
 	}
 
 	if !*jsonFlag {
@@ -137,6 +201,9 @@ func runPing(args []string) {
 				tc.Wgreen(os.Stdout, "rtt=%.1fms", result.RttMs)
 				tc.Wfaint(os.Stdout, " path=[%s]", result.Path)
 				fmt.Println()
+				if *audible {
+					fmt.Print("\a")
+				}
 			}
 		}
 	}
@@ -155,39 +222,52 @@ func runPing(args []string) {
 		} else {
 			tc.Wgreen(os.Stdout, "%.0f%% loss", stats.LossPct)
 		}
-		fmt.Printf(", rtt min/avg/max = %.1f/%.1f/%.1f ms\n",
-			stats.MinMs, stats.AvgMs, stats.MaxMs)
+		fmt.Printf(", rtt min/avg/max/stddev = %.1f/%.1f/%.1f/%.1f ms, jitter = %.1f ms, p50/p95/p99 = %.1f/%.1f/%.1f ms\n",
+			stats.MinMs, stats.AvgMs, stats.MaxMs, stats.StddevMs, stats.JitterMs, stats.P50Ms, stats.P95Ms, stats.P99Ms)
+	}
+	if code := pingExitCode(stats); code != pingExitOK {
+		standalone.Network.Close() // osExit skips deferred calls
+		osExit(code)
 	}
 }
 
-// runPingViaDaemon pings a peer through the running daemon.
-func runPingViaDaemon(client *daemon.Client, target string, count, intervalMs int, jsonOutput bool) {
+// runPingViaDaemon pings a peer through the running daemon and exits with
+// the ping exit code.
+func runPingViaDaemon(client *daemon.Client, target string, count, intervalMs int, jsonOutput, audible bool) {
 	// Show verification badge (OMEMO-style).
 	if !jsonOutput {
 		showVerificationBadge(client, target)
 	}
+	exitPing(doPingViaDaemon(client.Ping, target, count, intervalMs, jsonOutput, audible, os.Stdout, os.Stderr))
+}
 
+// doPingViaDaemon runs a counted ping through ping, prints the replies and
+// statistics, and returns the ping exit code. A target the daemon cannot
+// resolve or connect to is reported on stderr as unreachable.
+func doPingViaDaemon(ping pingFunc, target string, count, intervalMs int, jsonOutput, audible bool, stdout, stderr io.Writer) int {
+	resp, err := ping(target, count, intervalMs)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return pingExitUnreachable
+	}
 	if jsonOutput {
-		resp, err := client.Ping(target, count, intervalMs)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			osExit(1)
-		}
-		enc := json.NewEncoder(os.Stdout)
+		enc := json.NewEncoder(stdout)
 		enc.SetIndent("", "  ")
 		enc.Encode(resp)
-	} else {
-		text, err := client.PingText(target, count, intervalMs)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			osExit(1)
-		}
-		fmt.Print(text)
+		return pingExitCode(resp.Stats)
 	}
+
+	peerID := ""
+	if len(resp.Results) > 0 {
+		peerID = resp.Results[0].PeerID
+	}
+	sdk.WritePingReport(stdout, target, peerID, resp.Results, resp.Stats, audible)
+	return pingExitCode(resp.Stats)
 }
 
-// runPingViaDaemonContinuous sends one ping at a time via the daemon until Ctrl+C.
-func runPingViaDaemonContinuous(client *daemon.Client, target string, intervalMs int, jsonOutput bool) {
+// runPingViaDaemonContinuous sends one ping at a time via the daemon until
+// Ctrl+C, then exits with the ping exit code for everything sent.
+func runPingViaDaemonContinuous(client *daemon.Client, target string, intervalMs int, jsonOutput, audible bool) {
 	if !jsonOutput {
 		showVerificationBadge(client, target)
 	}
@@ -213,7 +293,7 @@ func runPingViaDaemonContinuous(client *daemon.Client, target string, intervalMs
 		resp, err := client.Ping(target, 1, intervalMs)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			osExit(1)
+			osExit(pingExitUnreachable)
 		}
 
 		for _, r := range resp.Results {
@@ -233,6 +313,9 @@ func runPingViaDaemonContinuous(client *daemon.Client, target string, intervalMs
 					tc.Wgreen(os.Stdout, "rtt=%.1fms", r.RttMs)
 					tc.Wfaint(os.Stdout, " path=[%s]", r.Path)
 					fmt.Println()
+					if audible {
+						fmt.Print("\a")
+					}
 				}
 			}
 		}
@@ -258,9 +341,10 @@ done:
 		} else {
 			tc.Wgreen(os.Stdout, "%.0f%% loss", stats.LossPct)
 		}
-		fmt.Printf(", rtt min/avg/max = %.1f/%.1f/%.1f ms\n",
-			stats.MinMs, stats.AvgMs, stats.MaxMs)
+		fmt.Printf(", rtt min/avg/max/stddev = %.1f/%.1f/%.1f/%.1f ms, jitter = %.1f ms, p50/p95/p99 = %.1f/%.1f/%.1f ms\n",
+			stats.MinMs, stats.AvgMs, stats.MaxMs, stats.StddevMs, stats.JitterMs, stats.P50Ms, stats.P95Ms, stats.P99Ms)
 	}
+	exitPing(pingExitCode(stats))
 }
 
 // showVerificationBadge queries the daemon for a peer's verification status
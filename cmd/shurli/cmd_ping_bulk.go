@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// bulkPingConcurrency caps how many targets a bulk ping runs at once.
+const bulkPingConcurrency = 8
+
+// bulkPingDefaultCount is the per-target count when --targets is given
+// without -c (continuous ping makes no sense for a summary table).
+const bulkPingDefaultCount = 3
+
+// bulkPingResult is one target's row in a bulk ping report.
+type bulkPingResult struct {
+	Target string         `json:"target"`
+	PeerID string         `json:"peer_id,omitempty"`
+	Path   string         `json:"path,omitempty"` // path of the last reply
+	Stats  *sdk.PingStats `json:"stats,omitempty"`
+	Error  string         `json:"error,omitempty"` // resolve/connect failure
+}
+
+// ok reports whether the target answered at least one ping.
+func (r bulkPingResult) ok() bool {
+	return r.Error == "" && r.Stats != nil && r.Stats.Received > 0
+}
+
+// pingFunc pings one target; daemon.Client.Ping in production.
+type pingFunc func(target string, count, intervalMs int) (*daemon.PingResponse, error)
+
+// splitTargets parses a comma-separated --targets value, dropping blanks
+// and duplicates while keeping order.
+func splitTargets(s string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// bulkPing pings every target with at most concurrency in flight and
+// returns one result per target, in input order. A target that fails to
+// resolve or connect is reported in its row; it does not stop the others.
+func bulkPing(ping pingFunc, targets []string, count, intervalMs, concurrency int) []bulkPingResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]bulkPingResult, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = pingOneTarget(ping, target, count, intervalMs)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func pingOneTarget(ping pingFunc, target string, count, intervalMs int) bulkPingResult {
+	res := bulkPingResult{Target: target}
+	resp, err := ping(target, count, intervalMs)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	stats := resp.Stats
+	res.Stats = &stats
+	for _, r := range resp.Results {
+		if res.PeerID == "" {
+			res.PeerID = r.PeerID
+		}
+		if r.Error == "" {
+			res.Path = r.Path
+		}
+	}
+	return res
+}
+
+// writeBulkPingTable prints the per-target summary table.
+func writeBulkPingTable(w io.Writer, results []bulkPingResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tAVG RTT\tLOSS\tPATH")
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(tw, "%s\t-\t-\terror: %s\n", r.Target, sdk.HumanizeError(r.Error))
+		case r.Stats.Received == 0:
+			fmt.Fprintf(tw, "%s\t-\t%.0f%%\t-\n", r.Target, r.Stats.LossPct)
+		default:
+			fmt.Fprintf(tw, "%s\t%.1f ms\t%.0f%%\t%s\n", r.Target, r.Stats.AvgMs, r.Stats.LossPct, r.Path)
+		}
+	}
+	tw.Flush()
+}
+
+// doBulkPing pings several targets through the daemon and prints one
+// summary row (or JSON object) per target. Returns an error if any target
+// could not be reached, so scripts can use the exit code as a health check.
+func doBulkPing(ping pingFunc, targets []string, count, intervalMs int, jsonOutput bool, stdout io.Writer) error {
+	if count <= 0 {
+		count = bulkPingDefaultCount
+	}
+	results := bulkPing(ping, targets, count, intervalMs, bulkPingConcurrency)
+
+	if jsonOutput {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			Targets []bulkPingResult `json:"targets"`
+		}{results}); err != nil {
+			return err
+		}
+	} else {
+		writeBulkPingTable(stdout, results)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.ok() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d targets unreachable", failed, len(results))
+	}
+	return nil
+}
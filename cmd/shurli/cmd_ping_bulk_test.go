@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// fakeBulkPing answers "home" and "vps", times out on "laptop" and fails
+// to resolve anything else.
+func fakeBulkPing(target string, count, intervalMs int) (*daemon.PingResponse, error) {
+	var results []sdk.PingResult
+	for i := 0; i < count; i++ {
+		switch target {
+		case "home":
+			results = append(results, sdk.PingResult{Seq: i, PeerID: "12D3KooWHome", RttMs: 10, Path: "DIRECT"})
+		case "vps":
+			results = append(results, sdk.PingResult{Seq: i, PeerID: "12D3KooWVps", RttMs: 40, Path: "RELAYED"})
+		case "laptop":
+			results = append(results, sdk.PingResult{Seq: i, PeerID: "12D3KooWLaptop", Error: "timeout"})
+		default:
+			return nil, fmt.Errorf("cannot resolve %q", target)
+		}
+	}
+	return &daemon.PingResponse{Results: results, Stats: sdk.ComputePingStats(results)}, nil
+}
+
+func TestSplitTargets(t *testing.T) {
+	got := splitTargets(" home, laptop,,vps,home ")
+	want := []string{"home", "laptop", "vps"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitTargets = %v, want %v", got, want)
+	}
+}
+
+func TestBulkPing(t *testing.T) {
+	results := bulkPing(fakeBulkPing, []string{"home", "nowhere", "laptop", "vps"}, 3, 10, 2)
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+
+	// Results keep input order regardless of completion order.
+	for i, want := range []string{"home", "nowhere", "laptop", "vps"} {
+		if results[i].Target != want {
+			t.Errorf("results[%d].Target = %q, want %q", i, results[i].Target, want)
+		}
+	}
+
+	home := results[0]
+	if !home.ok() || home.Path != "DIRECT" || home.PeerID != "12D3KooWHome" || home.Stats.AvgMs != 10 {
+		t.Errorf("home = %+v", home)
+	}
+	if nowhere := results[1]; nowhere.ok() || !strings.Contains(nowhere.Error, "cannot resolve") {
+		t.Errorf("unresolvable target should carry its error, got %+v", nowhere)
+	}
+	if laptop := results[2]; laptop.ok() || laptop.Stats == nil || laptop.Stats.LossPct != 100 {
+		t.Errorf("laptop should report 100%% loss, got %+v", laptop)
+	}
+	if vps := results[3]; !vps.ok() || vps.Path != "RELAYED" {
+		t.Errorf("vps = %+v", vps)
+	}
+}
+
+func TestBulkPingBoundedConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	ping := func(target string, count, intervalMs int) (*daemon.PingResponse, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return fakeBulkPing("home", count, intervalMs)
+	}
+
+	targets := make([]string, 10)
+	for i := range targets {
+		targets[i] = fmt.Sprintf("peer-%d", i)
+	}
+	bulkPing(ping, targets, 1, 10, 3)
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", got)
+	}
+}
+
+func TestDoBulkPing(t *testing.T) {
+	var buf bytes.Buffer
+	err := doBulkPing(fakeBulkPing, []string{"home", "vps"}, 0, 10, false, &buf)
+	if err != nil {
+		t.Fatalf("doBulkPing: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"TARGET", "AVG RTT", "home", "10.0 ms", "DIRECT", "vps", "RELAYED"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+
+	// One bad target is reported in the table and the exit status,
+	// without aborting the others.
+	buf.Reset()
+	err = doBulkPing(fakeBulkPing, []string{"home", "nowhere", "laptop"}, 1, 10, false, &buf)
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 targets unreachable") {
+		t.Errorf("err = %v, want 2 of 3 unreachable", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "home") || !strings.Contains(out, "error: ") || !strings.Contains(out, "100%") {
+		t.Errorf("table should show every target:\n%s", out)
+	}
+}
+
+func TestDoBulkPingJSON(t *testing.T) {
+	var buf bytes.Buffer
+	_ = doBulkPing(fakeBulkPing, []string{"home", "nowhere"}, 2, 10, true, &buf)
+
+	var report struct {
+		Targets []bulkPingResult `json:"targets"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(report.Targets) != 2 {
+		t.Fatalf("got %d targets, want 2", len(report.Targets))
+	}
+	if home := report.Targets[0]; home.Stats == nil || home.Stats.Sent != 2 || home.Path != "DIRECT" {
+		t.Errorf("home = %+v", home)
+	}
+	if nowhere := report.Targets[1]; nowhere.Error == "" || nowhere.Stats != nil {
+		t.Errorf("nowhere = %+v", nowhere)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// fakeLossyPing answers every other ping from "flaky" and otherwise
+// behaves like fakeBulkPing.
+func fakeLossyPing(target string, count, intervalMs int) (*daemon.PingResponse, error) {
+	if target != "flaky" {
+		return fakeBulkPing(target, count, intervalMs)
+	}
+	var results []sdk.PingResult
+	for i := 0; i < count; i++ {
+		r := sdk.PingResult{Seq: i, PeerID: "12D3KooWFlakyPeer", RttMs: 25, Path: "DIRECT"}
+		if i%2 == 1 {
+			r = sdk.PingResult{Seq: i, PeerID: "12D3KooWFlakyPeer", Error: "timeout"}
+		}
+		results = append(results, r)
+	}
+	return &daemon.PingResponse{Results: results, Stats: sdk.ComputePingStats(results)}, nil
+}
+
+func TestPingExitCodes(t *testing.T) {
+	for _, tc := range []struct {
+		target   string
+		wantCode int
+		wantExit bool
+	}{
+		{"home", pingExitOK, false},            // all answered
+		{"flaky", pingExitLoss, true},          // partial loss
+		{"laptop", pingExitUnreachable, true},  // every ping timed out
+		{"nowhere", pingExitUnreachable, true}, // cannot resolve
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code, exited := captureExit(func() {
+				exitPing(doPingViaDaemon(fakeLossyPing, tc.target, 4, 10, false, false, &stdout, &stderr))
+			})
+			if exited != tc.wantExit || code != tc.wantCode {
+				t.Errorf("exit = (%d, %v), want (%d, %v)\nstdout:\n%s\nstderr:\n%s",
+					code, exited, tc.wantCode, tc.wantExit, stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestDoPingViaDaemonText(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := doPingViaDaemon(fakeLossyPing, "flaky", 2, 10, false, true, &stdout, &stderr)
+	if code != pingExitLoss {
+		t.Errorf("code = %d, want %d", code, pingExitLoss)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"PING flaky (12D3KooWFlakyPee...):",
+		"seq=0 rtt=25.0ms path=[DIRECT]",
+		"seq=1 error=timeout",
+		"--- flaky ping statistics ---",
+		"2 sent, 1 received, 50% loss",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "\a"); n != 1 {
+		t.Errorf("--audible rang %d bells, want 1 (one per reply)", n)
+	}
+
+	// The error of an unresolvable target goes to stderr.
+	stdout.Reset()
+	if code := doPingViaDaemon(fakeLossyPing, "nowhere", 2, 10, false, false, &stdout, &stderr); code != pingExitUnreachable {
+		t.Errorf("unresolvable code = %d, want %d", code, pingExitUnreachable)
+	}
+	if !strings.Contains(stderr.String(), "cannot resolve") || stdout.Len() != 0 {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestDoPingViaDaemonJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := doPingViaDaemon(fakeLossyPing, "laptop", 3, 10, true, true, &stdout, &stderr)
+	if code != pingExitUnreachable {
+		t.Errorf("code = %d, want %d", code, pingExitUnreachable)
+	}
+	var resp daemon.PingResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if resp.Stats.Sent != 3 || resp.Stats.Received != 0 {
+		t.Errorf("stats = %+v", resp.Stats)
+	}
+}
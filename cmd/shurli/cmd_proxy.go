@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -16,6 +17,7 @@ import (
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	"github.com/libp2p/go-libp2p/core/protocol"
 
+	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/daemon"
 	tc "github.com/shurlinet/shurli/internal/termcolor"
 	"github.com/shurlinet/shurli/pkg/sdk"
@@ -232,6 +234,8 @@ func runProxyEphemeral(args []string) {
 	fs := flag.NewFlagSet("proxy", flag.ExitOnError)
 	configFlag := fs.String("config", "", "path to config file")
 	standaloneFlag := fs.Bool("standalone", false, "use direct P2P without daemon (debug)")
+	noCacheFlag := fs.Bool("no-cache", false, "standalone: don't use or update the peer address cache")
+	refreshFlag := fs.Bool("refresh", false, "standalone: ignore cached peer addresses and record fresh ones")
 	fs.Parse(reorderFlags(fs, args))
 
 	remaining := fs.Args()
@@ -246,7 +250,7 @@ func runProxyEphemeral(args []string) {
 		fmt.Println("  disable <name>                        Disable without removing")
 		fmt.Println()
 		fmt.Println("Ephemeral (foreground, stops on Ctrl+C):")
-		fmt.Println("  shurli proxy <target> <service> <local-port>")
+		fmt.Println("  shurli proxy [--no-cache|--refresh] <target> <service> <local-port>")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  shurli proxy add home-ssh home-node ssh 2222")
@@ -272,7 +276,7 @@ func runProxyEphemeral(args []string) {
 	}
 
 	// Standalone P2P host (no daemon running, or --standalone forced)
-	runProxyStandalone(target, serviceName, localPort, *configFlag, allowStandalone)
+	runProxyStandalone(target, serviceName, localPort, *configFlag, allowStandalone, *noCacheFlag, *refreshFlag)
 }
 
 // runProxyViaDaemon creates a TCP proxy through the running daemon.
@@ -323,8 +327,10 @@ func runProxyViaDaemon(client *daemon.Client, target, service, port string) {
 }
 
 // runProxyStandalone creates a TCP proxy with its own P2P host.
-// Used when no daemon is running (debug/development mode).
-func runProxyStandalone(target, serviceName, localPort, configPath string, allowStandalone bool) {
+// Used when no daemon is running (debug/development mode). Addresses found
+// through the DHT are cached in peer_cache.json unless noCache is set;
+// refresh drops the target's cached addresses before dialing.
+func runProxyStandalone(target, serviceName, localPort, configPath string, allowStandalone, noCache, refresh bool) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -395,7 +401,17 @@ func runProxyStandalone(target, serviceName, localPort, configPath string, allow
 
 	// Connect to target using parallel path racing (DHT + relay simultaneously)
 	fmt.Println("Connecting to target peer...")
-	pd := sdk.NewPathDialer(h, kdht, &sdk.StaticRelaySource{Addrs: cfg.Relay.Addresses}, nil)
+	pd := sdk.NewPathDialer(h, kdht, &sdk.StaticRelaySource{Addrs: cfg.Relay.ActiveAddresses()}, nil)
+	pd.SetPreferredRegion(cfg.Relay.PreferredRegion)
+	pd.SetIPMode(cfg.Network.EffectiveIPMode())
+	pd.SetRendezvous(cfg.Discovery.RendezvousList())
+	if !noCache {
+		cache := sdk.NewPeerCache(filepath.Join(standalone.ConfigDir, config.ProfileFileName("peer_cache.json")), cfg.Discovery.PeerCacheTTL)
+		if refresh {
+			cache.Forget(homePeerID)
+		}
+		pd.SetPeerCache(cache)
+	}
 	connectCtx, connectCancel := context.WithTimeout(ctx, 45*time.Second)
 	result, err := pd.DialPeer(connectCtx, homePeerID)
 	connectCancel()
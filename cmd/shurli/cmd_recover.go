@@ -99,7 +99,7 @@ func runRecover(args []string) {
 	}
 
 	// Save encrypted identity.key.
-	keyPath := filepath.Join(configDir, "identity.key")
+	keyPath := filepath.Join(configDir, config.ProfileKeyFileName())
 	if err := identity.SaveIdentity(keyPath, privKey, password); err != nil {
 		fatal("Failed to save identity key: %v", err)
 	}
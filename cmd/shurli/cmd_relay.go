@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/sec"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/shurlinet/shurli/internal/auth"
 	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/internal/identity"
+	"github.com/shurlinet/shurli/internal/output"
 	"github.com/shurlinet/shurli/internal/termcolor"
+	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
 func runRelay(args []string) {
@@ -109,6 +118,8 @@ func runRelay(args []string) {
 		runRelayGrant(args[1:], serverConfigFile)
 	case "grants":
 		runRelayGrants(args[1:], serverConfigFile)
+	case "stats":
+		runRelayStats(args[1:], serverConfigFile)
 	case "revoke":
 		runRelayRevoke(args[1:], serverConfigFile)
 	case "extend":
@@ -116,7 +127,7 @@ func runRelay(args []string) {
 	case "list-peers":
 		runRelayListPeers(args[1:], serverConfigFile)
 	case "info":
-		runRelayInfo(serverConfigFile)
+		runRelayInfo(args[1:], serverConfigFile)
 	case "invite":
 		runRelayInvite(args[1:], serverConfigFile)
 	case "vault":
@@ -147,6 +158,8 @@ func runRelay(args []string) {
 		runRelayGoodbye(args[1:], serverConfigFile)
 	case "verify":
 		runRelayVerify(args[1:], serverConfigFile)
+	case "selftest":
+		runRelaySelftest(args[1:], serverConfigFile)
 	case "recover":
 		runRelayRecover(args[1:], serverConfigFile)
 
@@ -199,13 +212,14 @@ func doRelayAdd(args []string, stdout io.Writer) error {
 	fs := flag.NewFlagSet("relay add", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
-	peerIDFlag := fs.String("peer-id", "", "relay server's peer ID (when using IP:PORT format)")
-	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+	peerIDFlag := fs.String("peer-id", "", "relay server's peer ID (checked against, or appended to, the address)")
+	verifyFlag := fs.Bool("verify", false, "dial each new relay to confirm its live peer ID")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"verify": true})); err != nil {
 		return err
 	}
 
 	if fs.NArg() < 1 {
-		return fmt.Errorf("usage: shurli relay add <address> [--peer-id <PEER_ID>]")
+		return fmt.Errorf("usage: shurli relay add <address> [--peer-id <PEER_ID>] [--verify]")
 	}
 
 	cfgFile, cfg, err := resolveConfigFileErr(*configFlag)
@@ -213,28 +227,46 @@ func doRelayAdd(args []string, stdout io.Writer) error {
 		return err
 	}
 
+	var wantID peer.ID
+	if *peerIDFlag != "" {
+		if wantID, err = peer.Decode(*peerIDFlag); err != nil {
+			return fmt.Errorf("invalid peer ID: %v", err)
+		}
+	}
+
 	// Resolve addresses  - handle both full multiaddr and IP:PORT + --peer-id
 	var resolvedAddrs []string
 	for _, arg := range fs.Args() {
 		if isFullMultiaddr(arg) {
-			// Validate multiaddr format
-			if _, err := ma.NewMultiaddr(arg); err != nil {
+			// Validate multiaddr format. DNS forms (/dns4, /dns6, /dnsaddr)
+			// are stored as-is and resolved by libp2p at dial time.
+			maddr, err := ma.NewMultiaddr(arg)
+			if err != nil {
 				return fmt.Errorf("invalid multiaddr: %s\n  Error: %v", arg, err)
 			}
+			_, addrID := peer.SplitAddr(maddr)
+			switch {
+			case addrID == "" && wantID == "":
+				return fmt.Errorf("relay address must end with /p2p/<peer-id> (or pass --peer-id): %s", arg)
+			case addrID == "":
+				// Only --peer-id given: append it to the address.
+				arg = strings.TrimSuffix(arg, "/") + "/p2p/" + wantID.String()
+			case wantID != "" && addrID != wantID:
+				// Both given and they disagree: most likely a transposed or
+				// copy-pasted ID. Refuse rather than guess which is right.
+				return fmt.Errorf("peer ID mismatch: address has %s but --peer-id is %s", addrID, wantID)
+			}
 			resolvedAddrs = append(resolvedAddrs, arg)
 		} else {
 			// Short format  - needs --peer-id
-			if *peerIDFlag == "" {
+			if wantID == "" {
 				return fmt.Errorf("short address format requires --peer-id flag.\n  Example: shurli relay add %s --peer-id 12D3KooW...", arg)
 			}
 			ip, port, err := parseRelayHostPort(arg)
 			if err != nil {
 				return fmt.Errorf("invalid address: %s\n  Error: %v", arg, err)
 			}
-			if err := validatePeerID(*peerIDFlag); err != nil {
-				return fmt.Errorf("invalid peer ID: %v", err)
-			}
-			resolvedAddrs = append(resolvedAddrs, buildRelayMultiaddr(ip, port, *peerIDFlag))
+			resolvedAddrs = append(resolvedAddrs, buildRelayMultiaddr(ip, port, wantID.String()))
 		}
 	}
 
@@ -259,6 +291,17 @@ func doRelayAdd(args []string, stdout io.Writer) error {
 		return nil
 	}
 
+	if *verifyFlag {
+		if err := verifyRelayPeerIDs(cfgFile, cfg, toAdd, stdout); err != nil {
+			return err
+		}
+	}
+
+	// Warn (but still add) when the node can't speak the relay's transport.
+	for _, w := range config.RelayTransportWarnings(toAdd, cfg.Network.Transports()) {
+		termcolor.Wyellow(stdout, "Warning: %s\n", w)
+	}
+
 	// Read config file and insert new addresses
 	data, err := os.ReadFile(cfgFile)
 	if err != nil {
@@ -344,6 +387,55 @@ func doRelayAdd(args []string, stdout io.Writer) error {
 	return nil
 }
 
+// relayVerifyTimeout bounds each --verify dial.
+const relayVerifyTimeout = 15 * time.Second
+
+// verifyRelayPeerIDs dials each relay address with this node's identity and
+// confirms the remote proves the peer ID embedded in the address. libp2p's
+// security handshake fails on a mismatch, so a successful connect is proof.
+// The node identity (not a throwaway key) is used so relays with connection
+// gating accept the dial.
+func verifyRelayPeerIDs(cfgFile string, cfg *config.NodeConfig, addrs []string, stdout io.Writer) error {
+	pw, _ := resolvePassword(filepath.Dir(cfgFile))
+	priv, err := sdk.LoadOrCreateIdentity(cfg.Identity.KeyFile, pw)
+	if err != nil {
+		return fmt.Errorf("failed to load identity: %w", err)
+	}
+	if ns := cfg.Discovery.Network; ns != "" {
+		if priv, err = identity.DeriveNamespaceKey(priv, ns); err != nil {
+			return fmt.Errorf("failed to derive namespace identity: %w", err)
+		}
+	}
+
+	h, err := libp2p.New(libp2p.Identity(priv), libp2p.NoListenAddrs)
+	if err != nil {
+		return fmt.Errorf("creating host: %w", err)
+	}
+	defer h.Close()
+
+	for _, addr := range addrs {
+		ai, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			return fmt.Errorf("invalid relay address %s: %w", addr, err)
+		}
+		fmt.Fprintf(stdout, "Verifying %s ... ", truncateAddr(addr))
+		ctx, cancel := context.WithTimeout(context.Background(), relayVerifyTimeout)
+		err = h.Connect(ctx, *ai)
+		cancel()
+		if err != nil {
+			fmt.Fprintln(stdout, "failed")
+			var mismatch sec.ErrPeerIDMismatch
+			if errors.As(err, &mismatch) {
+				return fmt.Errorf("relay at %s is %s, not %s", truncateAddr(addr), mismatch.Actual, mismatch.Expected)
+			}
+			return fmt.Errorf("could not verify relay %s: %w", truncateAddr(addr), err)
+		}
+		termcolor.Wgreen(stdout, "OK\n")
+		h.Network().ClosePeer(ai.ID)
+	}
+	return nil
+}
+
 func runRelayList(args []string) {
 	if err := doRelayList(args, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -351,11 +443,22 @@ func runRelayList(args []string) {
 	}
 }
 
+// relayListEntry is the json/yaml shape of one configured relay address.
+type relayListEntry struct {
+	Address string `json:"address"`
+	PeerID  string `json:"peer_id,omitempty"`
+}
+
 func doRelayList(args []string, stdout io.Writer) error {
 	fs := flag.NewFlagSet("relay list", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
-	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+	outFlags := output.Register(fs)
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true})); err != nil {
+		return err
+	}
+	format, err := outFlags.Format()
+	if err != nil {
 		return err
 	}
 
@@ -364,6 +467,20 @@ func doRelayList(args []string, stdout io.Writer) error {
 		return err
 	}
 
+	if format != output.Table {
+		list := make([]relayListEntry, 0, len(cfg.Relay.Addresses))
+		for _, addr := range cfg.Relay.Addresses {
+			item := relayListEntry{Address: addr}
+			if maddr, err := ma.NewMultiaddr(addr); err == nil {
+				if ai, err := peer.AddrInfoFromP2pAddr(maddr); err == nil {
+					item.PeerID = ai.ID.String()
+				}
+			}
+			list = append(list, item)
+		}
+		return output.Write(stdout, format, list)
+	}
+
 	if len(cfg.Relay.Addresses) == 0 {
 		fmt.Fprintln(stdout, "No relay addresses configured.\n  Add one with: shurli relay add <address>")
 		return nil
@@ -132,21 +132,7 @@ func doRelayGrants(args []string, configFile string, stdout io.Writer) error {
 		return nil
 	}
 
-	// Build peer ID -> name map from authorized_keys (local relay only).
-	// When using --remote, we can't access the remote relay's authorized_keys.
-	peerNames := make(map[string]string)
-	if *remoteFlag == "" && configFile != "" {
-		if relayCfg, loadErr := config.LoadRelayServerConfig(configFile); loadErr == nil {
-			config.ResolveRelayConfigPaths(relayCfg, filepath.Dir(configFile))
-			if peers, listErr := auth.ListPeers(relayCfg.Security.AuthorizedKeysFile); listErr == nil {
-				for _, p := range peers {
-					if p.Comment != "" {
-						peerNames[p.PeerID.String()] = p.Comment
-					}
-				}
-			}
-		}
-	}
+	peerNames := relayPeerNames(*remoteFlag, configFile)
 
 	fmt.Fprintf(stdout, "Relay data grants (%d):\n\n", len(grantList))
 	for _, g := range grantList {
@@ -155,6 +141,27 @@ func doRelayGrants(args []string, configFile string, stdout io.Writer) error {
 	return nil
 }
 
+// relayPeerNames builds a peer ID -> name map from authorized_keys (local
+// relay only). When using --remote, we can't access the remote relay's
+// authorized_keys, so the map is empty.
+func relayPeerNames(remoteAddr, configFile string) map[string]string {
+	peerNames := make(map[string]string)
+	if remoteAddr != "" || configFile == "" {
+		return peerNames
+	}
+	if relayCfg, loadErr := config.LoadRelayServerConfig(configFile); loadErr == nil {
+		config.ResolveRelayConfigPaths(relayCfg, filepath.Dir(configFile))
+		if peers, listErr := auth.ListPeers(relayCfg.Security.AuthorizedKeysFile); listErr == nil {
+			for _, p := range peers {
+				if p.Comment != "" {
+					peerNames[p.PeerID.String()] = p.Comment
+				}
+			}
+		}
+	}
+	return peerNames
+}
+
 func printRelayGrantInfo(stdout io.Writer, g relay.RelayGrantInfo, peerNames map[string]string) {
 	pid := g.PeerID
 	if len(pid) > 16 {
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/shurlinet/shurli/internal/invite"
+	"github.com/shurlinet/shurli/internal/relay"
+	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
 func runRelayInvite(args []string, configFile string) {
@@ -95,6 +102,7 @@ func doRelayInviteList(args []string, configFile string, stdout io.Writer) error
 	fs := flag.NewFlagSet("relay invite list", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	remoteFlag := fs.String("remote", "", "relay multiaddr for remote P2P admin")
+	allFlag := fs.Bool("all", false, "also show used, revoked, void and expired invites")
 	fs.Parse(reorderFlags(fs, args))
 
 	client, cleanup, err := relayAdminClientOrRemote(*remoteFlag, configFile)
@@ -108,23 +116,82 @@ func doRelayInviteList(args []string, configFile string, stdout io.Writer) error
 		return fmt.Errorf("list invites failed: %w", err)
 	}
 
-	if len(groups) == 0 {
-		fmt.Fprintln(stdout, "No active invites.")
-		return nil
-	}
+	writeRelayInviteList(stdout, groups, *allFlag, time.Now())
+	return nil
+}
 
-	fmt.Fprintf(stdout, "Active invites (%d):\n\n", len(groups))
+// writeRelayInviteList prints invites that can still be redeemed, soonest
+// expiry first. With all set, used, revoked, void and expired invites are
+// listed too, each with its status.
+func writeRelayInviteList(w io.Writer, groups []relay.GroupInfo, all bool, now time.Time) {
+	var shown []relay.GroupInfo
 	for _, g := range groups {
-		remaining := time.Until(g.ExpiresAt).Truncate(time.Second)
-		status := "active"
-		if remaining <= 0 {
-			status = "expired"
-			remaining = 0
+		if all || relayInviteStatus(g, now) == "outstanding" {
+			shown = append(shown, g)
 		}
-		fmt.Fprintf(stdout, "  %s  %d/%d used  %s (%s remaining)\n",
-			g.ID, g.Used, g.Total, status, remaining)
 	}
-	return nil
+	hidden := len(groups) - len(shown)
+	slices.SortFunc(shown, func(a, b relay.GroupInfo) int {
+		return a.ExpiresAt.Compare(b.ExpiresAt)
+	})
+
+	if len(shown) == 0 {
+		fmt.Fprintln(w, "No outstanding invites.")
+		if hidden > 0 {
+			fmt.Fprintf(w, "%d used, revoked, void or expired (use --all to show).\n", hidden)
+		}
+		return
+	}
+
+	if all {
+		fmt.Fprintf(w, "Invites (%d):\n\n", len(shown))
+	} else {
+		fmt.Fprintf(w, "Outstanding invites (%d):\n\n", len(shown))
+	}
+	for _, g := range shown {
+		status := relayInviteStatus(g, now)
+		remaining := g.ExpiresAt.Sub(now).Truncate(time.Second)
+		line := fmt.Sprintf("  %s  %d/%d outstanding", g.ID, g.Outstanding(), g.Total)
+		if g.Used > 0 {
+			line += fmt.Sprintf(", %d used", g.Used)
+		}
+		if g.Revoked > 0 {
+			line += fmt.Sprintf(", %d revoked", g.Revoked)
+		}
+		if g.Void > 0 {
+			line += fmt.Sprintf(", %d void (relay restarted)", g.Void)
+		}
+		if status == "expired" {
+			line += "  expired"
+		} else {
+			line += fmt.Sprintf("  expires in %s", remaining)
+			if status != "outstanding" {
+				line += "  " + status
+			}
+		}
+		fmt.Fprintln(w, line)
+	}
+	if hidden > 0 {
+		fmt.Fprintf(w, "\n%d used, revoked, void or expired not shown (use --all).\n", hidden)
+	}
+}
+
+// relayInviteStatus classifies a pairing group for listing: "outstanding"
+// while at least one code can be redeemed, otherwise "expired", "void"
+// (unused codes lost in a relay restart), "revoked" or "used".
+func relayInviteStatus(g relay.GroupInfo, now time.Time) string {
+	switch {
+	case !now.Before(g.ExpiresAt):
+		return "expired"
+	case g.Outstanding() > 0:
+		return "outstanding"
+	case g.Void > 0:
+		return "void"
+	case g.Revoked > 0:
+		return "revoked"
+	default:
+		return "used"
+	}
 }
 
 func runRelayInviteRevoke(args []string, configFile string) {
@@ -141,10 +208,22 @@ func doRelayInviteRevoke(args []string, configFile string, stdout io.Writer) err
 	fs.Parse(reorderFlags(fs, args))
 
 	if fs.NArg() < 1 {
-		return fmt.Errorf("usage: shurli relay invite revoke <group-id> [--remote <addr>]")
+		return fmt.Errorf("usage: shurli relay invite revoke <code|group-id> [--remote <addr>]")
 	}
 	id := fs.Arg(0)
 
+	// A code is sent as the SHA-256 of its token, the same lookup key the
+	// joiner uses, so revoking never puts the code itself on the wire.
+	var codeHash string
+	if !isRelayGroupID(id) {
+		data, err := invite.Decode(id)
+		if err != nil {
+			return fmt.Errorf("%q is neither a group ID nor an invite code: %w", id, err)
+		}
+		sum := sha256.Sum256(data.Token)
+		codeHash = hex.EncodeToString(sum[:])
+	}
+
 	client, cleanup, err := relayAdminClientOrRemote(*remoteFlag, configFile)
 	if err != nil {
 		if *remoteFlag == "" {
@@ -154,6 +233,15 @@ func doRelayInviteRevoke(args []string, configFile string, stdout io.Writer) err
 	}
 	defer cleanup()
 
+	if codeHash != "" {
+		groupID, err := client.RevokeCode(codeHash)
+		if err != nil {
+			return fmt.Errorf("revoke failed: %w", err)
+		}
+		fmt.Fprintf(stdout, "Invite code revoked (group %s).\n", groupID)
+		return nil
+	}
+
 	if err := client.RevokeGroup(id); err != nil {
 		return fmt.Errorf("revoke failed: %w", err)
 	}
@@ -162,6 +250,21 @@ func doRelayInviteRevoke(args []string, configFile string, stdout io.Writer) err
 	return nil
 }
 
+// isRelayGroupID reports whether s has the shape of a pairing group ID
+// (16 lowercase hex characters). Invite codes are upper-case base36 with
+// dashes, so the two never collide in the form the CLI prints them.
+func isRelayGroupID(s string) bool {
+	if len(s) != 16 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 func truncateID(s string) string {
 	if len(s) > 16 {
 		return s[:16] + "..."
@@ -174,8 +277,8 @@ func printRelayInviteUsage() {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  create  [--ttl 1h] [--expires 24h]   Generate a single-use invite code")
-	fmt.Println("  list                                  List active invites")
-	fmt.Println("  revoke  <group-id>                    Revoke an invite")
+	fmt.Println("  list    [--all]                       List outstanding invites")
+	fmt.Println("  revoke  <code|group-id>               Revoke an invite before it is used")
 	fmt.Println()
 	fmt.Println("All commands accept: --remote <multiaddr|name|peer-id>")
 	fmt.Println()
@@ -199,8 +302,7 @@ func detectRelayEndpoint(configFile string) (ip, port, peerIDStr string) {
 	// Find public IPv4 multiaddr and extract IP:port.
 	for _, maddr := range info.Multiaddrs {
 		if strings.Contains(maddr, "/ip4/") && strings.Contains(maddr, "/tcp/") && !strings.Contains(maddr, "/ws") {
-			// Skip private/loopback.
-			if strings.Contains(maddr, "/ip4/127.") || strings.Contains(maddr, "/ip4/10.") || strings.Contains(maddr, "/ip4/192.168.") || strings.Contains(maddr, "/ip4/172.") {
+			if sdk.ClassifyAddrString(maddr) != sdk.AddrGlobal {
 				continue
 			}
 			parts := strings.Split(maddr, "/")
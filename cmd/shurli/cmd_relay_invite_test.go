@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/relay"
+)
+
+func TestWriteRelayInviteList(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	groups := []relay.GroupInfo{
+		{ID: "aaaaaaaaaaaaaaaa", Total: 1, ExpiresAt: now.Add(50 * time.Minute)},
+		{ID: "bbbbbbbbbbbbbbbb", Total: 1, Revoked: 1, ExpiresAt: now.Add(time.Hour)},
+		{ID: "cccccccccccccccc", Total: 1, ExpiresAt: now.Add(-time.Minute)},
+		{ID: "dddddddddddddddd", Total: 3, Used: 1, Revoked: 1, ExpiresAt: now.Add(10 * time.Minute)},
+	}
+
+	var buf bytes.Buffer
+	writeRelayInviteList(&buf, groups, false, now)
+	out := buf.String()
+
+	if !strings.Contains(out, "Outstanding invites (2):") {
+		t.Errorf("missing header:\n%s", out)
+	}
+	// Soonest expiry first.
+	if strings.Index(out, "dddddddddddddddd") > strings.Index(out, "aaaaaaaaaaaaaaaa") {
+		t.Errorf("not sorted by expiry:\n%s", out)
+	}
+	if !strings.Contains(out, "dddddddddddddddd  1/3 outstanding, 1 used, 1 revoked  expires in 10m0s") {
+		t.Errorf("missing partial group line:\n%s", out)
+	}
+	if strings.Contains(out, "bbbbbbbbbbbbbbbb") || strings.Contains(out, "cccccccccccccccc") {
+		t.Errorf("revoked or expired group shown without --all:\n%s", out)
+	}
+	if !strings.Contains(out, "2 used, revoked, void or expired not shown (use --all).") {
+		t.Errorf("missing hidden footer:\n%s", out)
+	}
+
+	buf.Reset()
+	writeRelayInviteList(&buf, groups, true, now)
+	out = buf.String()
+	if !strings.Contains(out, "bbbbbbbbbbbbbbbb  0/1 outstanding, 1 revoked  expires in 1h0m0s  revoked") {
+		t.Errorf("--all missing revoked group:\n%s", out)
+	}
+	if !strings.Contains(out, "cccccccccccccccc  1/1 outstanding  expired") {
+		t.Errorf("--all missing expired group:\n%s", out)
+	}
+
+	buf.Reset()
+	writeRelayInviteList(&buf, groups[1:3], false, now)
+	if got := buf.String(); !strings.HasPrefix(got, "No outstanding invites.") {
+		t.Errorf("empty list output = %q", got)
+	}
+}
+
+// TestWriteRelayInviteListAfterRestart checks that codes still unused when
+// the relay restarts are listed as void, not outstanding: their raw tokens
+// are not persisted, so they can no longer be redeemed.
+func TestWriteRelayInviteListAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay-pairing.json")
+	ts, err := relay.NewPersistentTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, groupID, err := ts.CreateGroup(2, time.Hour, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ts.ValidateAndUse(tokens[0], "", "laptop"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := relay.NewPersistentTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	var buf bytes.Buffer
+	writeRelayInviteList(&buf, restarted.List(), false, now)
+	if got, want := buf.String(), "No outstanding invites.\n1 used, revoked, void or expired (use --all to show).\n"; got != want {
+		t.Errorf("list after restart = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	writeRelayInviteList(&buf, restarted.List(), true, now)
+	if want := groupID + "  0/2 outstanding, 1 used, 1 void (relay restarted)  expires in "; !strings.Contains(buf.String(), want) {
+		t.Errorf("--all after restart missing %q:\n%s", want, buf.String())
+	}
+	if !strings.Contains(buf.String(), "  void\n") {
+		t.Errorf("--all after restart: group not marked void:\n%s", buf.String())
+	}
+}
+
+func TestIsRelayGroupID(t *testing.T) {
+	for s, want := range map[string]bool{
+		"0123456789abcdef":    true,
+		"0123456789ABCDEF":    false,
+		"0123456789abcde":     false,
+		"ABCD-EFGH-JKLM-NPQR": false,
+		"ghijklmnopqrstuv":    false,
+	} {
+		if got := isRelayGroupID(s); got != want {
+			t.Errorf("isRelayGroupID(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	circuitv2client "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/client"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// relaySelftestProtocol is spoken only between the two throwaway selftest
+// hosts, through the relay under test. Each request is an 8-byte big-endian
+// length followed by that many bytes; the receiver answers with one byte
+// once it has read them all. A zero-length request is a latency probe.
+const relaySelftestProtocol = protocol.ID("/shurli/relay-selftest/1.0.0")
+
+const (
+	relaySelftestProbes = 5
+	relaySelftestGrant  = 5 * time.Minute
+	relaySelftestChunk  = 64 << 10 // test data is streamed in chunks of this size
+)
+
+// relaySelftestResult holds what the selftest measured through the relay.
+type relaySelftestResult struct {
+	Latency    time.Duration // median round trip of the latency probes
+	Bytes      int64
+	Elapsed    time.Duration
+	Throughput float64 // bytes per second
+}
+
+func runRelaySelftest(args []string, configFile string) {
+	if err := doRelaySelftest(args, configFile, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+// doRelaySelftest checks the running relay end to end: two throwaway hosts
+// are temporarily authorized, one reserves a slot, the other dials it over
+// /p2p-circuit, and test data is pushed through the circuit. The temporary
+// authorizations and grant are removed again whatever the outcome, including
+// when the run is interrupted with Ctrl+C or SIGTERM.
+func doRelaySelftest(args []string, configFile string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("relay selftest", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	sizeFlag := fs.String("size", "4MB", "amount of test data to send through the relay")
+	timeoutFlag := fs.Duration("timeout", 60*time.Second, "overall time limit for the selftest")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return fmt.Errorf("usage: shurli relay selftest [--size 4MB] [--timeout 60s]")
+	}
+	size, err := config.ParseDataSize(*sizeFlag)
+	if err != nil || size <= 0 {
+		return fmt.Errorf("invalid --size %q (e.g. 512KB, 4MB)", *sizeFlag)
+	}
+
+	cfg, err := config.LoadRelayServerConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	client, err := relayAdminClient(configFile)
+	if err != nil {
+		return fmt.Errorf("relay is not running (admin socket unavailable): %w", err)
+	}
+	info, err := client.GetInfo()
+	if err != nil {
+		return fmt.Errorf("relay is not running (admin socket unavailable): %w", err)
+	}
+	relayInfo, err := relayInfoToAddrInfo(info.PeerID, info.Multiaddrs)
+	if err != nil {
+		return err
+	}
+
+	// Interrupting cancels the run instead of killing the process, so the
+	// deferred cleanup below still removes the temporary authorizations.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Identities are generated up front so both peers can be authorized
+	// before they ever connect to the relay.
+	var keys [2]crypto.PrivKey
+	for i := range keys {
+		if keys[i], _, err = crypto.GenerateEd25519Key(rand.Reader); err != nil {
+			return fmt.Errorf("failed to generate selftest identity: %w", err)
+		}
+	}
+	ids := [2]peer.ID{}
+	for i, k := range keys {
+		ids[i], _ = peer.IDFromPrivateKey(k)
+	}
+
+	if cfg.Security.EnableConnectionGating {
+		for _, id := range ids {
+			if err := client.AuthorizePeer(id.String(), "relay selftest (temporary)"); err != nil {
+				return fmt.Errorf("failed to authorize selftest peer: %w", err)
+			}
+			defer func(id peer.ID) {
+				if err := client.DeauthorizePeer(id.String()); err != nil {
+					fmt.Fprintf(stdout, "Warning: failed to remove selftest peer %s: %v\n", id, err)
+				}
+			}(id)
+		}
+	}
+	if !cfg.Security.EnableDataRelay {
+		// Signaling-only relay: a grant on the listening side is what
+		// normal clients need for data circuits, so the test uses one too.
+		if _, err := client.RelayGrant(ids[1].String(), int(relaySelftestGrant/time.Second), nil, false, ""); err != nil {
+			return fmt.Errorf("failed to grant selftest data access: %w", err)
+		}
+		defer func() {
+			if err := client.RelayRevoke(ids[1].String()); err != nil {
+				fmt.Fprintf(stdout, "Warning: failed to revoke selftest grant: %v\n", err)
+			}
+		}()
+	}
+
+	fmt.Fprintf(stdout, "Relay selftest: %s\n", relayInfo.ID)
+	ctx, cancel := context.WithTimeout(sigCtx, *timeoutFlag)
+	defer cancel()
+
+	res, err := relaySelftest(ctx, *relayInfo, keys, size, stdout)
+	if err != nil {
+		fmt.Fprintln(stdout)
+		if sigCtx.Err() != nil {
+			fmt.Fprintln(stdout, "Selftest interrupted.")
+			return errors.New("interrupted")
+		}
+		fmt.Fprintln(stdout, "Selftest FAILED.")
+		return err
+	}
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "Selftest passed.")
+	fmt.Fprintf(stdout, "  Latency:    %s (median of %d round trips)\n", res.Latency.Round(time.Microsecond), relaySelftestProbes)
+	fmt.Fprintf(stdout, "  Throughput: %s/s (%s in %s)\n",
+		sdk.FormatBytes(int64(res.Throughput)), sdk.FormatBytes(res.Bytes), res.Elapsed.Round(time.Millisecond))
+	return nil
+}
+
+// relayInfoToAddrInfo turns the admin API's /p2p-suffixed multiaddrs into
+// a dialable AddrInfo for the relay.
+func relayInfoToAddrInfo(peerID string, addrs []string) (*peer.AddrInfo, error) {
+	id, err := peer.Decode(peerID)
+	if err != nil {
+		return nil, fmt.Errorf("relay reported invalid peer ID %q: %w", peerID, err)
+	}
+	ai := &peer.AddrInfo{ID: id}
+	for _, s := range addrs {
+		addr, err := ma.NewMultiaddr(s)
+		if err != nil {
+			continue
+		}
+		transport, _ := peer.SplitAddr(addr)
+		if transport != nil {
+			ai.Addrs = append(ai.Addrs, transport)
+		}
+	}
+	if len(ai.Addrs) == 0 {
+		return nil, fmt.Errorf("relay reported no listen addresses")
+	}
+	return ai, nil
+}
+
+// relaySelftest runs the circuit test against relayInfo using two
+// throwaway hosts built from keys. keys[1] reserves a slot and serves the
+// test protocol; keys[0] dials it through the relay. Both hosts are closed
+// before returning.
+func relaySelftest(ctx context.Context, relayInfo peer.AddrInfo, keys [2]crypto.PrivKey, size int64, stdout io.Writer) (*relaySelftestResult, error) {
+	dialer, err := newSelftestHost(keys[0])
+	if err != nil {
+		return nil, err
+	}
+	defer dialer.Close()
+	listener, err := newSelftestHost(keys[1])
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	listener.SetStreamHandler(relaySelftestProtocol, handleRelaySelftest)
+
+	fmt.Fprintln(stdout, "  Connecting throwaway peers to relay...")
+	for _, h := range []host.Host{listener, dialer} {
+		if err := h.Connect(ctx, relayInfo); err != nil {
+			return nil, fmt.Errorf("cannot reach relay: %w\n  Check the relay's listen addresses and connection gating", err)
+		}
+	}
+
+	fmt.Fprintln(stdout, "  Reserving a relay slot...")
+	if _, err := circuitv2client.Reserve(ctx, listener, relayInfo); err != nil {
+		return nil, fmt.Errorf("relay refused the reservation: %w\n  Check resources.max_reservations and the reservation limits", err)
+	}
+
+	circuitAddr, err := ma.NewMultiaddr("/p2p/" + relayInfo.ID.String() + "/p2p-circuit")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(stdout, "  Opening a circuit through the relay...")
+	dialer.Peerstore().AddAddr(listener.ID(), circuitAddr, time.Minute)
+	s, err := dialer.NewStream(network.WithAllowLimitedConn(ctx, "relay-selftest"), listener.ID(), relaySelftestProtocol)
+	if err != nil {
+		return nil, fmt.Errorf("relay refused the circuit: %w\n  Check security.enable_data_relay and resources.max_circuits", err)
+	}
+	defer s.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		s.SetDeadline(deadline)
+	}
+	stopReset := context.AfterFunc(ctx, func() { s.Reset() })
+	defer stopReset()
+
+	fmt.Fprintln(stdout, "  Measuring latency...")
+	rtts := make([]time.Duration, 0, relaySelftestProbes)
+	for range relaySelftestProbes {
+		start := time.Now()
+		if err := relaySelftestRequest(s, 0, nil); err != nil {
+			s.Reset()
+			return nil, fmt.Errorf("latency probe failed through relay: %w", err)
+		}
+		rtts = append(rtts, time.Since(start))
+	}
+
+	fmt.Fprintf(stdout, "  Sending %s through the circuit...\n", sdk.FormatBytes(size))
+	chunk := make([]byte, min(size, relaySelftestChunk))
+	rand.Read(chunk)
+	start := time.Now()
+	if err := relaySelftestRequest(s, size, chunk); err != nil {
+		s.Reset()
+		return nil, fmt.Errorf("relay closed the circuit during the transfer: %w\n"+
+			"  The relay's session limits are likely too low for %s (resources.session_data_limit, resources.session_duration)",
+			err, sdk.FormatBytes(size))
+	}
+	elapsed := time.Since(start)
+
+	return &relaySelftestResult{
+		Latency:    medianDuration(rtts),
+		Bytes:      size,
+		Elapsed:    elapsed,
+		Throughput: float64(size) / elapsed.Seconds(),
+	}, nil
+}
+
+// newSelftestHost builds a minimal host with no listen addresses: it can
+// only be reached through the relay circuit it reserves.
+func newSelftestHost(priv crypto.PrivKey) (host.Host, error) {
+	h, err := libp2p.New(
+		libp2p.Identity(priv),
+		libp2p.Transport(tcp.NewTCPTransport),
+		libp2p.NoListenAddrs,
+		libp2p.EnableRelay(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating selftest host: %w", err)
+	}
+	return h, nil
+}
+
+// relaySelftestRequest sends one framed request of n bytes, repeating
+// chunk as often as needed, and waits for the ack. Only one chunk is ever
+// held in memory, whatever --size asks for.
+func relaySelftestRequest(s network.Stream, n int64, chunk []byte) error {
+	var hdr [8]byte
+	binary.BigEndian.PutUint64(hdr[:], uint64(n))
+	if _, err := s.Write(hdr[:]); err != nil {
+		return err
+	}
+	for n > 0 {
+		b := chunk[:min(n, int64(len(chunk)))]
+		if _, err := s.Write(b); err != nil {
+			return err
+		}
+		n -= int64(len(b))
+	}
+	var ack [1]byte
+	if _, err := io.ReadFull(s, ack[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleRelaySelftest reads framed requests until the dialer closes the
+// stream, acknowledging each once fully received.
+func handleRelaySelftest(s network.Stream) {
+	defer s.Close()
+	var hdr [8]byte
+	for {
+		if _, err := io.ReadFull(s, hdr[:]); err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.Reset()
+			}
+			return
+		}
+		n := int64(binary.BigEndian.Uint64(hdr[:]))
+		if _, err := io.CopyN(io.Discard, s, n); err != nil {
+			s.Reset()
+			return
+		}
+		if _, err := s.Write([]byte{1}); err != nil {
+			s.Reset()
+			return
+		}
+	}
+}
+
+// medianDuration returns the median of ds. ds is sorted in place.
+func medianDuration(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	slices.Sort(ds)
+	return ds[len(ds)/2]
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	relayv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/relay"
+)
+
+// startTestRelay runs an in-process circuit relay on loopback.
+func startTestRelay(t *testing.T, limit *relayv2.RelayLimit) peer.AddrInfo {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	if _, err := relayv2.New(h, relayv2.WithLimit(limit)); err != nil {
+		t.Fatal(err)
+	}
+	return peer.AddrInfo{ID: h.ID(), Addrs: h.Addrs()}
+}
+
+func selftestKeys(t *testing.T) [2]crypto.PrivKey {
+	t.Helper()
+	var keys [2]crypto.PrivKey
+	for i := range keys {
+		k, _, err := crypto.GenerateEd25519Key(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = k
+	}
+	return keys
+}
+
+func TestRelaySelftest(t *testing.T) {
+	relayInfo := startTestRelay(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	res, err := relaySelftest(ctx, relayInfo, selftestKeys(t), 256<<10, io.Discard)
+	if err != nil {
+		t.Fatalf("relaySelftest: %v", err)
+	}
+	if res.Bytes != 256<<10 {
+		t.Errorf("Bytes = %d, want %d", res.Bytes, 256<<10)
+	}
+	if res.Latency <= 0 || res.Throughput <= 0 {
+		t.Errorf("expected positive measurements, got latency %v throughput %v", res.Latency, res.Throughput)
+	}
+}
+
+func TestRelaySelftest_LimitTooLow(t *testing.T) {
+	relayInfo := startTestRelay(t, &relayv2.RelayLimit{Duration: time.Minute, Data: 16 << 10})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := relaySelftest(ctx, relayInfo, selftestKeys(t), 1<<20, io.Discard)
+	if err == nil {
+		t.Fatal("expected failure when the transfer exceeds the relay data limit")
+	}
+	if !strings.Contains(err.Error(), "session_data_limit") {
+		t.Errorf("error should point at the relay limits, got: %v", err)
+	}
+}
+
+func TestRelayInfoToAddrInfo(t *testing.T) {
+	const id = "12D3KooWLqK4mSLSoaPxnMTaJvYZpqwAjrk2rn4RD5VMZzKK2rNG"
+	ai, err := relayInfoToAddrInfo(id, []string{
+		"/ip4/127.0.0.1/tcp/7777/p2p/" + id,
+		"not-a-multiaddr",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ai.Addrs) != 1 || ai.Addrs[0].String() != "/ip4/127.0.0.1/tcp/7777" {
+		t.Errorf("Addrs = %v", ai.Addrs)
+	}
+
+	if _, err := relayInfoToAddrInfo(id, nil); err == nil {
+		t.Error("expected error for relay without addresses")
+	}
+	if _, err := relayInfoToAddrInfo("bogus", nil); err == nil {
+		t.Error("expected error for invalid peer ID")
+	}
+}
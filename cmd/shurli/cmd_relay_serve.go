@@ -53,12 +53,18 @@ import (
 const relayConfigFile = "relay-server.yaml"
 
 // relayUserAgent builds the UserAgent string for the relay server.
-// If a name is configured, it is appended in parentheses.
-func relayUserAgent(name string) string {
+// If a name is configured, it is appended in parentheses. A configured
+// region follows as a "region/<name>" token (see sdk.RelayAgentRegion),
+// which nodes read via identify to prefer nearby relays.
+func relayUserAgent(name, region string) string {
+	ua := fmt.Sprintf("relay-server/%s", version)
 	if name != "" {
-		return fmt.Sprintf("relay-server/%s (%s)", version, name)
+		ua += fmt.Sprintf(" (%s)", name)
 	}
-	return fmt.Sprintf("relay-server/%s", version)
+	if region != "" {
+		ua += " " + sdk.RelayRegionToken + region
+	}
+	return ua
 }
 
 // runRelayServe starts the circuit relay server. This is the equivalent of the
@@ -109,6 +115,12 @@ func runRelayServe(args []string) {
 	fmt.Printf("Authentication: %v\n", cfg.Security.EnableConnectionGating)
 	fmt.Println()
 
+	// Invite expiry reads the wall clock.
+	if now := time.Now(); !config.ClockPlausible(now) {
+		slog.Warn("system clock looks wrong; invite expiry may misbehave until it is synced",
+			"now", now.UTC().Format(time.RFC3339))
+	}
+
 	var priv crypto.PrivKey
 	if _, statErr := os.Stat(cfg.Identity.KeyFile); statErr == nil {
 		// Existing key file: load it with session token or interactive prompt.
@@ -292,7 +304,7 @@ func runRelayServe(args []string) {
 		libp2p.Transport(tcp.NewTCPTransport),
 		libp2p.Transport(ws.New),
 		libp2p.EnableAutoNATv2(),
-		libp2p.UserAgent(relayUserAgent(cfg.Name)),
+		libp2p.UserAgent(relayUserAgent(cfg.Name, cfg.Region)),
 	}
 
 	// Resource manager (always enabled on relay - public-facing service)
@@ -381,12 +393,13 @@ func runRelayServe(args []string) {
 
 	relayResources, relayLimit := buildRelayResources(&cfg.Resources)
 	circuitACL := relay.NewCircuitACL(cfg.Security.AuthorizedKeysFile, cfg.Security.EnableDataRelay, cfg.Security.EnableConnectionGating, relayGrantStore)
+	reserveThrottle := relay.NewReservationThrottle(cfg.Security.MaxReservationsPerMinute)
+	circuitACL.SetReservationThrottle(reserveThrottle)
 
 	// Per-peer relay data budgets (BUG-GRANT-1).
 	// When grants are enabled, create BudgetTracker + LimitingHost to enforce
 	// per-peer data limits on relay circuits. The LimitingHost wraps streams
 	// at the host.Host layer — zero fork, zero libp2p changes.
-	var relayHost libp2phost.Host = h
 	var relayBudgetTracker *relay.BudgetTracker
 
 	if relayGrantStore != nil {
@@ -443,23 +456,57 @@ func runRelayServe(args []string) {
 		relayLimit.Data = safetyNetBytes
 		relayLimit.Duration = safetyNetDuration
 
-		// Create LimitingHost — relay sees this as host.Host.
-		relayHost = relay.NewLimitingHost(h, budgetTracker, circuitACL)
 		slog.Info("relay budget: per-peer enforcement enabled",
 			"default_limit", cfg.Resources.SessionDataLimit)
 	}
 
-	_, err = relayv2.New(relayHost,
+	// Per-peer and per-group relay accounting. Always on: the LimitingHost
+	// counts HOP/STOP streams even when no budgets are enforced (tracker nil).
+	// Without grants that is all it does, and counting is a per-stream atomic
+	// add, so the relay data path takes no extra locks.
+	// The daily rollup lives next to the config so restarts keep today's totals.
+	accountingPath := filepath.Join(filepath.Dir(configFile), "relay-accounting.json")
+	relayAccounting, acctErr := relay.NewRelayAccounting(accountingPath, circuitACL.PeerGroup)
+	if acctErr != nil {
+		slog.Error("relay accounting: failed to load, starting empty", "error", acctErr)
+	}
+	go relayAccounting.Run(ctx, relay.DefaultAccountingFlushInterval)
+	defer func() {
+		if err := relayAccounting.Flush(); err != nil {
+			slog.Warn("relay accounting: flush on shutdown failed", "error", err)
+		}
+	}()
+
+	// Create LimitingHost — relay sees this as host.Host.
+	limitingHost := relay.NewLimitingHost(h, relayBudgetTracker, circuitACL)
+	limitingHost.SetAccounting(relayAccounting)
+	var relayHost libp2phost.Host = limitingHost
+
+	// Initialize relay observability (opt-in). Created before the relay
+	// service so its reservation/circuit tracer can be attached.
+	var relayMetrics *sdk.Metrics
+	if cfg.Telemetry.Metrics.Enabled {
+		relayMetrics = sdk.NewMetrics(version, runtime.Version())
+		relayAccounting.Metrics = relayMetrics
+		slog.Info("telemetry: metrics enabled", "addr", cfg.Telemetry.Metrics.ListenAddress)
+	}
+
+	relayOpts := []relayv2.Option{
 		relayv2.WithResources(relayResources),
 		relayv2.WithLimit(relayLimit),
 		relayv2.WithACL(circuitACL),
-	)
+	}
+	// The tracer's counts feed the periodic status log, and the
+	// shurli_relay_* metrics when metrics are enabled (relayMetrics non-nil).
+	serviceStats := sdk.NewRelayMetricsTracer(relayMetrics)
+	relayOpts = append(relayOpts, relayv2.WithMetricsTracer(serviceStats))
+	_, err = relayv2.New(relayHost, relayOpts...)
 	if err != nil {
 		fatal("Failed to start relay service: %v", err)
 	}
-	fmt.Printf("Relay limits: max_reservations=%d, max_circuits=%d, session=%s, data=%s/direction\n",
+	fmt.Printf("Relay limits: max_reservations=%d, max_circuits=%d, session=%s, data=%s/direction, reservations/peer/min=%d\n",
 		cfg.Resources.MaxReservations, cfg.Resources.MaxCircuits,
-		cfg.Resources.SessionDuration, cfg.Resources.SessionDataLimit)
+		cfg.Resources.SessionDuration, cfg.Resources.SessionDataLimit, reserveThrottle.Limit())
 	if cfg.Security.EnableDataRelay {
 		fmt.Println("Data relay: ENABLED (all authorized peers can relay data)")
 	} else {
@@ -492,8 +539,15 @@ func runRelayServe(args []string) {
 		fmt.Printf("Private DHT active (protocol: %s/kad/1.0.0)\n", dhtPrefix)
 	}
 
-	// Initialize token store and pairing protocol handler.
-	tokenStore := relay.NewTokenStore()
+	// Initialize token store and pairing protocol handler. Pairing state is
+	// kept next to the config so revoked and redeemed codes stay that way
+	// across restarts.
+	tokenStore, tsErr := relay.NewPersistentTokenStore(relay.PairingStateFile(filepath.Dir(configFile)))
+	if tsErr != nil {
+		// Starting empty would silently drop every revocation.
+		fatal("Pairing state: %v\nFix or move the file aside to start with no pairing history", tsErr)
+	}
+	tokenStore.SetClockSkewTolerance(cfg.Security.InviteClockSkewDuration())
 	depositStore := deposit.NewDepositStore()
 	// Same nil interface trap guard for pairing handler.
 	var pairingGater relay.GaterInterface
@@ -554,6 +608,7 @@ func runRelayServe(args []string) {
 	if relayBudgetTracker != nil {
 		adminSrv.SetBudgetTracker(relayBudgetTracker)
 	}
+	adminSrv.SetAccounting(relayAccounting)
 
 	// Load vault if configured. When sealed, the relay starts in watch-only mode:
 	// existing peers can use the relay, but no new peers can be authorized.
@@ -590,6 +645,11 @@ func runRelayServe(args []string) {
 		defer adminSrv.Stop()
 	}
 
+	// Pick up hand edits to authorized_keys without a restart.
+	if gater != nil && cfg.Security.IsWatchAuthorizedKeysEnabled() {
+		go adminSrv.WatchAuthorizedKeys(ctx, relay.DefaultAuthKeysWatchInterval)
+	}
+
 	// Register remote admin protocol for general admin operations over P2P.
 	// Available even when sealed - admin peers can unseal, check status, etc.
 	remoteAdminHandler := relay.NewRemoteAdminHandler(adminSrv, cfg.Security.AuthorizedKeysFile)
@@ -631,6 +691,14 @@ func runRelayServe(args []string) {
 	go motdHandler.RunMOTDNotifier(ctx)
 	slog.Info("motd handler initialized", "protocol", relay.MOTDProtocol)
 
+	// Soft admission control on identify agent versions (advisory: agent
+	// strings are self-reported).
+	if cfg.Security.UserAgentPolicy.Enabled() {
+		sdk.EnforceUserAgentPolicy(ctx, h, cfg.Security.UserAgentPolicy, nil)
+		slog.Info("user-agent policy enabled",
+			"allow", len(cfg.Security.UserAgentPolicy.Allow), "deny", len(cfg.Security.UserAgentPolicy.Deny))
+	}
+
 	// Register ZKP anonymous auth protocol (Phase 7).
 	var zkpAuthHandler *relay.ZKPAuthHandler
 	if cfg.Security.ZKP.Enabled {
@@ -763,6 +831,12 @@ func runRelayServe(args []string) {
 				for _, p := range peers {
 					fmt.Printf("  %s\n", p.String()[:16])
 				}
+				st := serviceStats.Snapshot()
+				slog.Info("relay status",
+					"connected_peers", len(peers),
+					"reservations", st.Reservations,
+					"circuits", st.Circuits,
+					"bytes_relayed", st.BytesRelayed)
 			}
 		}
 	}()
@@ -790,12 +864,6 @@ func runRelayServe(args []string) {
 		},
 	})
 
-	// Initialize relay observability (opt-in)
-	var relayMetrics *sdk.Metrics
-	if cfg.Telemetry.Metrics.Enabled {
-		relayMetrics = sdk.NewMetrics(version, runtime.Version())
-		slog.Info("telemetry: metrics enabled", "addr", cfg.Telemetry.Metrics.ListenAddress)
-	}
 	// Wire metrics to Phase 6 components (nil-safe: if metrics disabled, handlers work without them).
 	if relayMetrics != nil {
 		adminSrv.Metrics = relayMetrics
@@ -830,6 +898,7 @@ func runRelayServe(args []string) {
 		gater.SetDecisionCallback(func(peerID, result string) {
 			if relayMetrics != nil {
 				relayMetrics.AuthDecisionsTotal.WithLabelValues(result).Inc()
+				relayMetrics.RelayConnectionsGatedTotal.WithLabelValues(result).Inc()
 			}
 			if relayAudit != nil {
 				relayAudit.AuthDecision(peerID, "inbound", result)
@@ -837,50 +906,12 @@ func runRelayServe(args []string) {
 		})
 	}
 
-	// Start /healthz HTTP endpoint if enabled.
-	// Security: only exposes operational status (no peer IDs, versions, or protocol lists).
-	// Default listen address is 127.0.0.1:9090 (localhost-only), but if configured to
-	// bind externally, we validate that the source IP is loopback to prevent information leakage.
-	var healthServer *http.Server
-	if cfg.Health.Enabled || relayMetrics != nil {
-		startTime := time.Now()
-		mux := http.NewServeMux()
-
-		if cfg.Health.Enabled {
-			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-				// Reject non-loopback sources when bound to a non-loopback address
-				host, _, _ := net.SplitHostPort(r.RemoteAddr)
-				if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() {
-					http.Error(w, "forbidden", http.StatusForbidden)
-					return
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(map[string]any{
-					"status":          "ok",
-					"uptime_seconds":  int(time.Since(startTime).Seconds()),
-					"connected_peers": len(h.Network().Peers()),
-				})
-			})
-		}
-
-		if relayMetrics != nil {
-			mux.Handle("/metrics", relayMetrics.Handler())
-		}
-
-		// Use metrics listen address when health is not enabled
-		listenAddr := cfg.Health.ListenAddress
-		if !cfg.Health.Enabled && relayMetrics != nil {
-			listenAddr = cfg.Telemetry.Metrics.ListenAddress
-		}
-
-		healthServer = &http.Server{
-			Addr:         listenAddr,
-			Handler:      mux,
-			ReadTimeout:  5 * time.Second,
-			WriteTimeout: 5 * time.Second,
-		}
+	// Start the /healthz and /metrics HTTP endpoint. Nothing listens when
+	// both are disabled (the default).
+	healthServer := newRelayHTTPServer(cfg, relayMetrics, h)
+	if healthServer != nil {
 		go func() {
-			slog.Info("HTTP endpoint started", "addr", listenAddr)
+			slog.Info("HTTP endpoint started", "addr", healthServer.Addr)
 			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 				slog.Error("HTTP endpoint error", "err", err)
 			}
@@ -911,6 +942,56 @@ func runRelayServe(args []string) {
 	cancel() // Stop background goroutines
 }
 
+// newRelayHTTPServer builds the relay's HTTP endpoint: /healthz when
+// health.enabled, /metrics when m is non-nil. It returns nil when neither is
+// enabled, so no port is opened. The server shares health.listen_address
+// when health is enabled; otherwise it uses telemetry.metrics.listen_address.
+//
+// Security: /healthz only exposes operational status (no peer IDs, versions,
+// or protocol lists). The default listen address is 127.0.0.1:9090
+// (localhost-only), but if configured to bind externally, non-loopback
+// sources are refused to prevent information leakage.
+func newRelayHTTPServer(cfg *config.RelayServerConfig, m *sdk.Metrics, h libp2phost.Host) *http.Server {
+	if !cfg.Health.Enabled && m == nil {
+		return nil
+	}
+	startTime := time.Now()
+	mux := http.NewServeMux()
+
+	if cfg.Health.Enabled {
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			// Reject non-loopback sources when bound to a non-loopback address
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"status":          "ok",
+				"uptime_seconds":  int(time.Since(startTime).Seconds()),
+				"connected_peers": len(h.Network().Peers()),
+			})
+		})
+	}
+
+	if m != nil {
+		mux.Handle("/metrics", m.Handler())
+	}
+
+	listenAddr := cfg.Health.ListenAddress
+	if !cfg.Health.Enabled {
+		listenAddr = cfg.Telemetry.Metrics.ListenAddress
+	}
+
+	return &http.Server{
+		Addr:         listenAddr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+	}
+}
+
 // buildRelayResources converts config resource settings into relayv2 types.
 func buildRelayResources(rc *config.RelayResourcesConfig) (relayv2.Resources, *relayv2.RelayLimit) {
 	// Parse durations (already validated by ValidateRelayServerConfig)
@@ -1325,15 +1406,34 @@ func runRelayListPeers(args []string, configFile string) {
 	}
 }
 
-func runRelayInfo(configFile string) {
+// relayInfo is the `relay info --json` output.
+type relayInfo struct {
+	PeerID           string   `json:"peer_id"`
+	Name             string   `json:"name,omitempty"`
+	Region           string   `json:"region,omitempty"`
+	ConnectionGating bool     `json:"connection_gating"`
+	AuthorizedPeers  int      `json:"authorized_peers"`
+	Multiaddrs       []string `json:"multiaddrs"`
+}
+
+func runRelayInfo(args []string, configFile string) {
+	fs := flag.NewFlagSet("relay info", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	fs.Parse(reorderFlags(fs, args))
+
 	cfg, err := config.LoadRelayServerConfig(configFile)
 	if err != nil {
 		fatal("Failed to load config: %v", err)
 	}
 
 	// Read encrypted SHRL identity key (read-only, don't auto-create).
+	// With --json the password prompt goes to stderr to keep stdout parseable.
 	relayConfigDir := filepath.Dir(configFile)
-	pw, pwErr := resolvePasswordInteractive(relayConfigDir, os.Stdout)
+	promptOut := io.Writer(os.Stdout)
+	if *jsonFlag {
+		promptOut = os.Stderr
+	}
+	pw, pwErr := resolvePasswordInteractive(relayConfigDir, promptOut)
 	if pwErr != nil {
 		fatal("Identity error: %v", pwErr)
 	}
@@ -1346,7 +1446,32 @@ func runRelayInfo(configFile string) {
 		fatal("Failed to derive peer ID: %v", err)
 	}
 
+	if *jsonFlag {
+		info := relayInfo{
+			PeerID:           peerID.String(),
+			Name:             cfg.Name,
+			Region:           cfg.Region,
+			ConnectionGating: cfg.Security.EnableConnectionGating,
+			Multiaddrs:       buildPublicMultiaddrs(cfg.Network.ListenAddresses, detectPublicIPs(), peerID),
+		}
+		if cfg.Security.AuthorizedKeysFile != "" {
+			if peers, err := auth.ListPeers(cfg.Security.AuthorizedKeysFile); err == nil {
+				info.AuthorizedPeers = len(peers)
+			}
+		}
+		if info.Multiaddrs == nil {
+			info.Multiaddrs = []string{}
+		}
+		if err := writeJSON(os.Stdout, info); err != nil {
+			fatal("%v", err)
+		}
+		return
+	}
+
 	fmt.Printf("Peer ID: %s\n", peerID)
+	if cfg.Region != "" {
+		fmt.Printf("Region: %s\n", cfg.Region)
+	}
 
 	// Connection gating status
 	if cfg.Security.EnableConnectionGating {
@@ -1426,6 +1551,7 @@ func runRelayServerConfig(args []string, configFile string) {
 		fmt.Println("  show        Show resolved relay config")
 		fmt.Println("  validate    Validate relay-server.yaml without starting")
 		fmt.Println("  rollback    Restore last-known-good config")
+		fmt.Println("  schema      Print a JSON Schema for editor validation")
 		osExit(1)
 	}
 	switch args[0] {
@@ -1435,6 +1561,11 @@ func runRelayServerConfig(args []string, configFile string) {
 		runRelayServerConfigValidate(configFile)
 	case "rollback":
 		runRelayServerConfigRollback(configFile)
+	case "schema":
+		if err := writeConfigSchema(config.RelayServerConfigSchema, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			osExit(1)
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n", args[0])
 		osExit(1)
@@ -1461,6 +1592,12 @@ func doRelayServerConfigShow(configFile string, stdout io.Writer) error {
 	} else {
 		tc.Wfaint(stdout, "(not set)\n")
 	}
+	tc.Wblue(stdout, "Region:     ")
+	if cfg.Region != "" {
+		fmt.Fprintln(stdout, cfg.Region)
+	} else {
+		tc.Wfaint(stdout, "(not set)\n")
+	}
 	tc.Wblue(stdout, "Key file:   ")
 	fmt.Fprintln(stdout, cfg.Identity.KeyFile)
 	tc.Wblue(stdout, "Config:     ")
@@ -1684,23 +1821,10 @@ func detectPublicIPs() []string {
 		if !ok {
 			continue
 		}
-		ip := ipNet.IP
-		if !ip.IsGlobalUnicast() {
-			continue
-		}
-		// Skip private IPv4 (10/8, 172.16/12, 192.168/16)
-		if ip4 := ip.To4(); ip4 != nil {
-			if ip4[0] == 10 ||
-				(ip4[0] == 172 && ip4[1] >= 16 && ip4[1] <= 31) ||
-				(ip4[0] == 192 && ip4[1] == 168) {
-				continue
-			}
-		}
-		// Skip ULA IPv6 (fc00::/7)
-		if ip.To4() == nil && len(ip) == net.IPv6len && (ip[0]&0xfe) == 0xfc {
+		if sdk.ClassifyIP(ipNet.IP) != sdk.AddrGlobal {
 			continue
 		}
-		ips = append(ips, ip.String())
+		ips = append(ips, ipNet.IP.String())
 	}
 	return ips
 }
@@ -1859,6 +1983,7 @@ func printRelayServeUsage() {
 	fmt.Println("  grants                              List active data relay grants")
 	fmt.Println("  revoke <peer-id>                    Revoke data relay access")
 	fmt.Println("  extend <peer-id> --duration 2h      Extend data relay grant")
+	fmt.Println("  stats [--json] [--days]             Show data relayed per peer and group")
 	fmt.Println("  list-peers                          List authorized peers")
 	fmt.Println("  seal                                Seal vault (watch-only mode)")
 	fmt.Println("  unseal                              Unseal vault")
@@ -1874,6 +1999,7 @@ func printRelayServeUsage() {
 	fmt.Println("  serve                               Start the relay server")
 	fmt.Println("  info                                Show peer ID, multiaddrs, QR code")
 	fmt.Println("  verify <peer-id>                    Verify a peer's identity (SAS)")
+	fmt.Println("  selftest [--size 4MB]               Test a circuit through this relay")
 	fmt.Println("  show                                Show resolved relay config")
 	fmt.Println("  config <subcommand>                 Config management (show/validate/rollback)")
 	fmt.Println("  vault init [--totp] [--auto-seal N] Initialize passphrase-sealed vault")
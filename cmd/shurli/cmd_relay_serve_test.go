@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/identity"
+	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
 // ----- buildRelayResources tests -----
@@ -465,3 +468,60 @@ func TestDoRelayServerConfigRollback(t *testing.T) {
 		}
 	})
 }
+
+// ----- newRelayHTTPServer tests -----
+
+func TestNewRelayHTTPServer(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		if srv := newRelayHTTPServer(&config.RelayServerConfig{}, nil, nil); srv != nil {
+			t.Errorf("server = %+v with health and metrics disabled, want nil (no port opened)", srv)
+		}
+	})
+
+	t.Run("metrics only", func(t *testing.T) {
+		cfg := &config.RelayServerConfig{}
+		cfg.Telemetry.Metrics.Enabled = true
+		cfg.Telemetry.Metrics.ListenAddress = "127.0.0.1:9091"
+		m := sdk.NewMetrics("test", "go1.26.0")
+		m.RelayConnectionsGatedTotal.WithLabelValues("deny").Inc()
+
+		srv := newRelayHTTPServer(cfg, m, nil)
+		if srv == nil {
+			t.Fatal("server is nil with metrics enabled")
+		}
+		if srv.Addr != "127.0.0.1:9091" {
+			t.Errorf("Addr = %q, want the metrics listen address", srv.Addr)
+		}
+
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		for _, want := range []string{
+			"shurli_relay_reservations_active",
+			"shurli_relay_circuits_active",
+			`shurli_relay_connections_gated_total{result="deny"} 1`,
+			"shurli_relay_data_relayed_bytes_total",
+		} {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Errorf("/metrics missing %q", want)
+			}
+		}
+
+		rec = httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("/healthz status = %d with health disabled, want 404", rec.Code)
+		}
+	})
+
+	t.Run("health address wins", func(t *testing.T) {
+		cfg := &config.RelayServerConfig{}
+		cfg.Health.Enabled = true
+		cfg.Health.ListenAddress = "127.0.0.1:9090"
+		cfg.Telemetry.Metrics.Enabled = true
+		cfg.Telemetry.Metrics.ListenAddress = "127.0.0.1:9091"
+		srv := newRelayHTTPServer(cfg, sdk.NewMetrics("test", "go1.26.0"), nil)
+		if srv == nil || srv.Addr != "127.0.0.1:9090" {
+			t.Fatalf("server = %+v, want one listening on the health address", srv)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/relay"
+	"github.com/shurlinet/shurli/internal/termcolor"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+func runRelayStats(args []string, configFile string) {
+	if err := doRelayStats(args, configFile, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+}
+
+func doRelayStats(args []string, configFile string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("relay stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	daysFlag := fs.Bool("days", false, "include the retained daily rollups")
+	remoteFlag := fs.String("remote", "", "relay multiaddr for remote P2P admin")
+	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+		return err
+	}
+
+	client, cleanup, err := relayAdminClientOrRemote(*remoteFlag, configFile)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	st, err := client.RelayStats(*daysFlag)
+	if err != nil {
+		return err
+	}
+	if *jsonFlag {
+		return writeJSON(stdout, st)
+	}
+	printRelayStats(stdout, st, relayPeerNames(*remoteFlag, configFile))
+	return nil
+}
+
+func printRelayStats(stdout io.Writer, st *relay.RelayStats, peerNames map[string]string) {
+	fmt.Fprintf(stdout, "Relayed data (today %s UTC, running since %s):\n\n",
+		st.Date, st.Since.Local().Format(time.DateTime))
+
+	if len(st.Peers) == 0 {
+		fmt.Fprintln(stdout, "  No data relayed yet.")
+	} else {
+		fmt.Fprintln(stdout, "Peers:")
+		for _, p := range st.Peers {
+			pid := p.PeerID
+			if len(pid) > 16 {
+				pid = pid[:16] + "..."
+			}
+			fmt.Fprintf(stdout, "  %s  today %s  since start %s", pid, formatRelayUsage(p.Today), formatRelayUsage(p.Total))
+			if p.Group != "" {
+				fmt.Fprintf(stdout, "  group:%s", p.Group)
+			}
+			if name := peerNames[p.PeerID]; name != "" {
+				termcolor.Wfaint(stdout, "  # %s", name)
+			}
+			fmt.Fprintln(stdout)
+		}
+	}
+
+	if len(st.Groups) > 0 {
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Groups (today):")
+		for _, g := range st.Groups {
+			fmt.Fprintf(stdout, "  %s  %s\n", g.Group, formatRelayUsage(g.Today))
+		}
+	}
+
+	if len(st.Days) > 0 {
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Daily rollup (UTC):")
+		for _, d := range st.Days {
+			var sum relay.RelayUsage
+			for _, u := range d.Peers {
+				sum.BytesIn += u.BytesIn
+				sum.BytesOut += u.BytesOut
+			}
+			fmt.Fprintf(stdout, "  %s  %s  (%d peers)\n", d.Date, formatRelayUsage(sum), len(d.Peers))
+		}
+	}
+}
+
+// formatRelayUsage renders a usage pair as "<in> in / <out> out".
+func formatRelayUsage(u relay.RelayUsage) string {
+	return fmt.Sprintf("%s in / %s out", sdk.FormatBytes(int64(u.BytesIn)), sdk.FormatBytes(int64(u.BytesOut)))
+}
@@ -2,15 +2,19 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/identity"
+	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
 const testPassword = "test-pw-12345678"
@@ -70,6 +74,22 @@ names: {}
 
 // ----- doRelayList tests -----
 
+func TestDoRelayList_JSON(t *testing.T) {
+	cfgPath := writeTestConfigDir(t)
+
+	var stdout bytes.Buffer
+	if err := doRelayList([]string{"--json", "--config", cfgPath}, &stdout); err != nil {
+		t.Fatalf("doRelayList --json: %v", err)
+	}
+	var list []relayListEntry
+	if err := json.Unmarshal(stdout.Bytes(), &list); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, stdout.String())
+	}
+	if len(list) != 1 || list[0].PeerID != "12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+}
+
 func TestDoRelayList(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -323,6 +343,137 @@ func TestDoRelayAddEmptyList(t *testing.T) {
 	}
 }
 
+func TestDoRelayAddUnsupportedTransportWarns(t *testing.T) {
+	// Simulate a node with WebSocket disabled.
+	orig := config.NodeTransports
+	config.NodeTransports = []string{"quic-v1", "tcp"}
+	t.Cleanup(func() { config.NodeTransports = orig })
+
+	cfgPath := writeTestConfigDirEmptyRelay(t)
+	addr := "/ip4/5.6.7.8/tcp/443/ws/p2p/12D3KooWSC3sb3uKwHJ8g6GkjqVK5pN2JFSasBknoT3ciVdFWf3q"
+
+	var stdout bytes.Buffer
+	if err := doRelayAdd([]string{"--config", cfgPath, addr}, &stdout); err != nil {
+		t.Fatalf("doRelayAdd: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Warning:") || !strings.Contains(stdout.String(), `"ws"`) {
+		t.Errorf("expected transport warning for /ws relay, got:\n%s", stdout.String())
+	}
+
+	// The address is still added: the warning is advisory.
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if !strings.Contains(string(data), addr) {
+		t.Errorf("config should contain %s", addr)
+	}
+
+	// A TCP relay on the same node draws no warning.
+	stdout.Reset()
+	tcpAddr := "/ip4/5.6.7.9/tcp/7777/p2p/12D3KooWSC3sb3uKwHJ8g6GkjqVK5pN2JFSasBknoT3ciVdFWf3q"
+	if err := doRelayAdd([]string{"--config", cfgPath, tcpAddr}, &stdout); err != nil {
+		t.Fatalf("doRelayAdd tcp: %v", err)
+	}
+	if strings.Contains(stdout.String(), "Warning:") {
+		t.Errorf("unexpected warning for tcp relay:\n%s", stdout.String())
+	}
+}
+
+func TestDoRelayAddPeerIDCheck(t *testing.T) {
+	t.Run("mismatch is rejected", func(t *testing.T) {
+		cfgPath := writeTestConfigDirEmptyRelay(t)
+		addr := "/ip4/5.6.7.8/tcp/7777/p2p/" + generateTestPeerID(t)
+		other := generateTestPeerID(t)
+
+		var stdout bytes.Buffer
+		err := doRelayAdd([]string{"--config", cfgPath, addr, "--peer-id", other}, &stdout)
+		if err == nil || !strings.Contains(err.Error(), "peer ID mismatch") {
+			t.Fatalf("expected peer ID mismatch error, got %v", err)
+		}
+		data, _ := os.ReadFile(cfgPath)
+		if strings.Contains(string(data), "5.6.7.8") {
+			t.Error("mismatched relay must not be written to config")
+		}
+	})
+
+	t.Run("matching ID is accepted", func(t *testing.T) {
+		cfgPath := writeTestConfigDirEmptyRelay(t)
+		pid := generateTestPeerID(t)
+		addr := "/ip4/5.6.7.8/tcp/7777/p2p/" + pid
+
+		var stdout bytes.Buffer
+		if err := doRelayAdd([]string{"--config", cfgPath, addr, "--peer-id", pid}, &stdout); err != nil {
+			t.Fatalf("doRelayAdd: %v", err)
+		}
+	})
+
+	t.Run("peer ID appended to bare multiaddr", func(t *testing.T) {
+		cfgPath := writeTestConfigDirEmptyRelay(t)
+		pid := generateTestPeerID(t)
+
+		var stdout bytes.Buffer
+		if err := doRelayAdd([]string{"--config", cfgPath, "/ip4/5.6.7.8/udp/7777/quic-v1", "--peer-id", pid}, &stdout); err != nil {
+			t.Fatalf("doRelayAdd: %v", err)
+		}
+		data, _ := os.ReadFile(cfgPath)
+		if want := "/ip4/5.6.7.8/udp/7777/quic-v1/p2p/" + pid; !strings.Contains(string(data), want) {
+			t.Errorf("config should contain %s, got:\n%s", want, data)
+		}
+	})
+
+	t.Run("bare multiaddr without peer ID is rejected", func(t *testing.T) {
+		cfgPath := writeTestConfigDirEmptyRelay(t)
+
+		var stdout bytes.Buffer
+		err := doRelayAdd([]string{"--config", cfgPath, "/ip4/5.6.7.8/tcp/7777"}, &stdout)
+		if err == nil || !strings.Contains(err.Error(), "/p2p/<peer-id>") {
+			t.Fatalf("expected missing peer ID error, got %v", err)
+		}
+	})
+}
+
+func TestDoRelayAddVerify(t *testing.T) {
+	relayHost, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("create relay host: %v", err)
+	}
+	t.Cleanup(func() { relayHost.Close() })
+	base := relayHost.Addrs()[0].String()
+
+	t.Run("live peer ID matches", func(t *testing.T) {
+		cfgPath := writeTestConfigDirEmptyRelay(t)
+		addr := base + "/p2p/" + relayHost.ID().String()
+
+		var stdout bytes.Buffer
+		if err := doRelayAdd([]string{"--config", cfgPath, "--verify", addr}, &stdout); err != nil {
+			t.Fatalf("doRelayAdd --verify: %v\n%s", err, stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "OK") {
+			t.Errorf("expected verification OK, got:\n%s", stdout.String())
+		}
+	})
+
+	t.Run("live peer ID differs", func(t *testing.T) {
+		cfgPath := writeTestConfigDirEmptyRelay(t)
+		wrong := generateTestPeerID(t)
+		addr := base + "/p2p/" + wrong
+
+		var stdout bytes.Buffer
+		err := doRelayAdd([]string{"--config", cfgPath, "--verify", addr}, &stdout)
+		if err == nil {
+			t.Fatal("expected verification failure for wrong peer ID")
+		}
+		if !strings.Contains(err.Error(), relayHost.ID().String()) {
+			t.Errorf("error should name the live peer ID %s: %v", relayHost.ID(), err)
+		}
+		data, _ := os.ReadFile(cfgPath)
+		if strings.Contains(string(data), wrong) {
+			t.Error("unverified relay must not be written to config")
+		}
+	})
+}
+
 func TestAddRelayToConfigFile(t *testing.T) {
 	t.Run("empty list", func(t *testing.T) {
 		cfgPath := writeTestConfigDirEmptyRelay(t)
@@ -487,6 +638,22 @@ func TestDoWhoami(t *testing.T) {
 			},
 			wantOutput: "12D3KooW",
 		},
+		{
+			name: "fingerprint flag adds fingerprint",
+			args: func(t *testing.T) []string {
+				cfgPath := writeTestConfigDir(t)
+				return []string{"--config", cfgPath, "--fingerprint"}
+			},
+			wantOutput: "Fingerprint: ",
+		},
+		{
+			name: "json flag outputs envelope",
+			args: func(t *testing.T) []string {
+				cfgPath := writeTestConfigDir(t)
+				return []string{"--config", cfgPath, "--json"}
+			},
+			wantOutput: `"peer_id": "12D3KooW`,
+		},
 		{
 			name: "missing config returns error",
 			args: func(t *testing.T) []string {
@@ -864,6 +1031,47 @@ func TestDoRelayAddSuccess(t *testing.T) {
 		}
 	})
 
+	t.Run("add dns4 multiaddr round-trips through config", func(t *testing.T) {
+		cfgPath := writeTestConfigDir(t)
+		pid := generateTestPeerID(t)
+		newAddr := "/dns4/relay.example.com/tcp/7777/p2p/" + pid
+
+		var stdout bytes.Buffer
+		if err := doRelayAdd([]string{"--config", cfgPath, newAddr}, &stdout); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		cfg, err := config.LoadNodeConfig(cfgPath)
+		if err != nil {
+			t.Fatalf("reload config: %v", err)
+		}
+		if err := config.ValidateNodeConfig(cfg); err != nil {
+			t.Fatalf("validate config: %v", err)
+		}
+		found := false
+		for _, a := range cfg.Relay.Addresses {
+			if a == newAddr {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("relay.addresses = %v, want to contain %s", cfg.Relay.Addresses, newAddr)
+		}
+		if _, err := sdk.ParseRelayAddrs(cfg.Relay.Addresses); err != nil {
+			t.Errorf("ParseRelayAddrs after round-trip: %v", err)
+		}
+	})
+
+	t.Run("reject multiaddr without peer ID", func(t *testing.T) {
+		cfgPath := writeTestConfigDir(t)
+
+		var stdout bytes.Buffer
+		err := doRelayAdd([]string{"--config", cfgPath, "/dnsaddr/relay.example.com"}, &stdout)
+		if err == nil || !strings.Contains(err.Error(), "/p2p/<peer-id>") {
+			t.Fatalf("expected missing peer ID error, got %v", err)
+		}
+	})
+
 	t.Run("add IP:PORT with peer-id", func(t *testing.T) {
 		cfgPath := writeTestConfigDir(t)
 		pid := generateTestPeerID(t)
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+)
+
+// remoteCommands maps "shurli remote" commands to the remote daemon's API.
+var remoteCommands = map[string]struct{ method, path string }{
+	"status":       {"GET", "/v1/status"},
+	"peers":        {"GET", "/v1/peers"},
+	"services":     {"GET", "/v1/services"},
+	"paths":        {"GET", "/v1/paths"},
+	"inbound":      {"GET", "/v1/inbound"},
+	"bandwidth":    {"GET", "/v1/bandwidth"},
+	"relay-health": {"GET", "/v1/relay-health"},
+	"auth":         {"GET", "/v1/auth"},
+	"reload":       {"POST", "/v1/config/reload"},
+}
+
+const remoteUsage = "usage: shurli remote --peer <node> [--json] <status|peers|services|paths|inbound|bandwidth|relay-health|auth|reload|ping <target>|api <METHOD> <PATH> [JSON]>"
+
+func runRemote(args []string) {
+	runWithJSON(doRemote(args, os.Stdout))
+}
+
+// doRemote runs one command on another node's daemon. The request goes
+// through the local daemon, which forwards it over /shurli/admin/1.0.0;
+// the remote node must list this node in its security.admin_peers.
+func doRemote(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("remote", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	peerFlag := fs.String("peer", "", "remote node name or peer ID")
+	jsonFlag := fs.Bool("json", false, "output as JSON")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true})); err != nil {
+		return err
+	}
+
+	errOut := func(err error) error {
+		if *jsonFlag {
+			return jsonErr(stdout, err)
+		}
+		return err
+	}
+
+	if *peerFlag == "" || fs.NArg() == 0 {
+		return errOut(fmt.Errorf("%s", remoteUsage))
+	}
+	req, err := remoteRequest(fs.Args())
+	if err != nil {
+		return errOut(err)
+	}
+	req.Peer = *peerFlag
+
+	client, err := daemon.NewClient(daemonSocketPath(), daemonCookiePath())
+	if err != nil {
+		return errOut(err)
+	}
+
+	if *jsonFlag {
+		data, err := client.Remote(req)
+		if err != nil {
+			return errOut(err)
+		}
+		return writeJSON(stdout, data)
+	}
+	text, err := client.RemoteText(req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, text)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
+
+// remoteRequest turns the positional arguments of "shurli remote" into
+// the API call to make on the remote daemon.
+func remoteRequest(args []string) (daemon.RemoteAdminRequest, error) {
+	cmd, rest := args[0], args[1:]
+	if c, ok := remoteCommands[cmd]; ok {
+		if len(rest) != 0 {
+			return daemon.RemoteAdminRequest{}, fmt.Errorf("remote %s takes no arguments", cmd)
+		}
+		return daemon.RemoteAdminRequest{Method: c.method, Path: c.path}, nil
+	}
+
+	switch cmd {
+	case "ping":
+		// Ping from the remote node, e.g. to check its view of a third peer.
+		if len(rest) != 1 {
+			return daemon.RemoteAdminRequest{}, fmt.Errorf("usage: shurli remote --peer <node> ping <target>")
+		}
+		body, _ := json.Marshal(daemon.PingRequest{Peer: rest[0], Count: 4})
+		return daemon.RemoteAdminRequest{Method: "POST", Path: "/v1/ping", Body: body}, nil
+	case "api":
+		// Any other endpoint, as documented in DAEMON-API.md.
+		if len(rest) < 2 || len(rest) > 3 {
+			return daemon.RemoteAdminRequest{}, fmt.Errorf("usage: shurli remote --peer <node> api <METHOD> <PATH> [JSON]")
+		}
+		req := daemon.RemoteAdminRequest{Method: strings.ToUpper(rest[0]), Path: rest[1]}
+		if !strings.HasPrefix(req.Path, "/v1/") {
+			return daemon.RemoteAdminRequest{}, fmt.Errorf("path must start with /v1/, got %q", req.Path)
+		}
+		if len(rest) == 3 {
+			if !json.Valid([]byte(rest[2])) {
+				return daemon.RemoteAdminRequest{}, fmt.Errorf("request body is not valid JSON")
+			}
+			req.Body = json.RawMessage(rest[2])
+		}
+		return req, nil
+	}
+	return daemon.RemoteAdminRequest{}, fmt.Errorf("unknown remote command %q\n%s", cmd, remoteUsage)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemoteRequest(t *testing.T) {
+	for _, tc := range []struct {
+		args       []string
+		wantMethod string
+		wantPath   string
+		wantBody   string
+		wantErr    string
+	}{
+		{args: []string{"status"}, wantMethod: "GET", wantPath: "/v1/status"},
+		{args: []string{"peers"}, wantMethod: "GET", wantPath: "/v1/peers"},
+		{args: []string{"reload"}, wantMethod: "POST", wantPath: "/v1/config/reload"},
+		{args: []string{"ping", "laptop"}, wantMethod: "POST", wantPath: "/v1/ping", wantBody: `{"peer":"laptop","count":4}`},
+		{args: []string{"api", "get", "/v1/grants"}, wantMethod: "GET", wantPath: "/v1/grants"},
+		{args: []string{"api", "POST", "/v1/messages", `{"peer":"home","text":"hi"}`},
+			wantMethod: "POST", wantPath: "/v1/messages", wantBody: `{"peer":"home","text":"hi"}`},
+		{args: []string{"status", "extra"}, wantErr: "takes no arguments"},
+		{args: []string{"ping"}, wantErr: "usage"},
+		{args: []string{"api", "GET", "status"}, wantErr: "must start with /v1/"},
+		{args: []string{"api", "POST", "/v1/messages", "{not json"}, wantErr: "not valid JSON"},
+		{args: []string{"reboot"}, wantErr: "unknown remote command"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			req, err := remoteRequest(tc.args)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.Method != tc.wantMethod || req.Path != tc.wantPath || string(req.Body) != tc.wantBody {
+				t.Errorf("got %s %s %s, want %s %s %s", req.Method, req.Path, req.Body, tc.wantMethod, tc.wantPath, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestDoRemoteUsage(t *testing.T) {
+	var stdout strings.Builder
+	if err := doRemote([]string{"status"}, &stdout); err == nil || !strings.Contains(err.Error(), "--peer") {
+		t.Errorf("missing --peer: err = %v", err)
+	}
+	stdout.Reset()
+	if err := doRemote([]string{"--json", "--peer", "home"}, &stdout); err == nil || !strings.Contains(stdout.String(), `"status": "error"`) {
+		t.Errorf("missing command with --json: err = %v, stdout = %q", err, stdout.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 
@@ -22,19 +23,21 @@ func runResolve(args []string) {
 }
 
 func doResolve(args []string, stdout io.Writer) error {
-	args = reorderArgs(args, map[string]bool{"json": true})
+	args = reorderArgs(args, map[string]bool{"json": true, "no-cache": true, "refresh": true})
 
 	fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
 	jsonFlag := fs.Bool("json", false, "output as JSON")
+	noCacheFlag := fs.Bool("no-cache", false, "don't show cached addresses")
+	refreshFlag := fs.Bool("refresh", false, "drop cached addresses so the next connect does a fresh DHT lookup")
 	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
 		return err
 	}
 
 	remaining := fs.Args()
 	if len(remaining) < 1 {
-		return fmt.Errorf("usage: shurli resolve [--config <path>] [--json] <name>")
+		return fmt.Errorf("usage: shurli resolve [--config <path>] [--json] [--no-cache|--refresh] <name>")
 	}
 
 	name := remaining[0]
@@ -70,15 +73,37 @@ func doResolve(args []string, stdout io.Writer) error {
 		source = "peer_id"
 	}
 
+	// Addresses an earlier DHT lookup found for this peer, if still fresh.
+	var cachedAddrs []string
+	var cachedAt time.Time
+	if !*noCacheFlag {
+		cachePath := filepath.Join(filepath.Dir(cfgFile), config.ProfileFileName("peer_cache.json"))
+		cache := sdk.NewPeerCache(cachePath, cfg.Discovery.PeerCacheTTL)
+		if *refreshFlag {
+			cache.Forget(peerID)
+		} else if addrs, updated, ok := cache.Lookup(peerID); ok {
+			for _, a := range addrs {
+				cachedAddrs = append(cachedAddrs, a.String())
+			}
+			cachedAt = updated
+		}
+	}
+
 	if *jsonFlag {
 		resp := struct {
-			Name   string `json:"name"`
-			PeerID string `json:"peer_id"`
-			Source string `json:"source"`
+			Name        string     `json:"name"`
+			PeerID      string     `json:"peer_id"`
+			Source      string     `json:"source"`
+			CachedAddrs []string   `json:"cached_addrs,omitempty"`
+			CachedAt    *time.Time `json:"cached_at,omitempty"`
 		}{
-			Name:   name,
-			PeerID: peerID.String(),
-			Source:  source,
+			Name:        name,
+			PeerID:      peerID.String(),
+			Source:      source,
+			CachedAddrs: cachedAddrs,
+		}
+		if len(cachedAddrs) > 0 {
+			resp.CachedAt = &cachedAt
 		}
 		enc := json.NewEncoder(stdout)
 		enc.SetIndent("", "  ")
@@ -87,5 +112,11 @@ func doResolve(args []string, stdout io.Writer) error {
 	}
 
 	fmt.Fprintf(stdout, "%s → %s\n", name, peerID.String())
+	if len(cachedAddrs) > 0 {
+		fmt.Fprintf(stdout, "Cached addresses (%s ago):\n", time.Since(cachedAt).Round(time.Second))
+		for _, a := range cachedAddrs {
+			fmt.Fprintf(stdout, "  %s\n", a)
+		}
+	}
 	return nil
 }
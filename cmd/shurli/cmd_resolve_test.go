@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,8 +11,10 @@ import (
 
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/shurlinet/shurli/internal/identity"
+	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
 // writeTestConfigWithNames creates a config with a name mapping and returns the config path.
@@ -166,6 +169,35 @@ func TestDoResolve(t *testing.T) {
 		}
 	})
 
+	t.Run("cached addresses", func(t *testing.T) {
+		cfgPath := writeTestConfigWithNames(t, map[string]string{"home": peerIDStr})
+		cachePath := filepath.Join(filepath.Dir(cfgPath), "peer_cache.json")
+		sdk.NewPeerCache(cachePath, 0).Store(pid, []ma.Multiaddr{ma.StringCast("/ip4/203.0.113.5/tcp/4001")})
+
+		var stdout bytes.Buffer
+		if err := doResolve([]string{"--config", cfgPath, "home"}, &stdout); err != nil {
+			t.Fatalf("doResolve: %v", err)
+		}
+		if !strings.Contains(stdout.String(), "/ip4/203.0.113.5/tcp/4001") {
+			t.Errorf("output should list the cached address, got: %s", stdout.String())
+		}
+
+		stdout.Reset()
+		if err := doResolve([]string{"--config", cfgPath, "--no-cache", "home"}, &stdout); err != nil {
+			t.Fatalf("doResolve --no-cache: %v", err)
+		}
+		if strings.Contains(stdout.String(), "Cached") {
+			t.Errorf("--no-cache output should not list cached addresses, got: %s", stdout.String())
+		}
+
+		if err := doResolve([]string{"--config", cfgPath, "--refresh", "home"}, io.Discard); err != nil {
+			t.Fatalf("doResolve --refresh: %v", err)
+		}
+		if _, _, ok := sdk.NewPeerCache(cachePath, 0).Lookup(pid); ok {
+			t.Error("--refresh should drop the cached entry")
+		}
+	})
+
 	t.Run("name not found", func(t *testing.T) {
 		cfgPath := writeTestConfigWithNames(t, nil)
 
@@ -4,11 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"net"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/shurlinet/shurli/internal/auth"
+	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/internal/output"
 	"github.com/shurlinet/shurli/internal/termcolor"
 	"github.com/shurlinet/shurli/internal/validate"
 )
@@ -52,6 +54,8 @@ func printServiceUsage() {
 	fmt.Println("  shurli service add ssh localhost:22")
 	fmt.Println("  shurli service add ollama localhost:11434")
 	fmt.Println("  shurli service add web localhost:8080 --protocol my-web")
+	fmt.Println("  shurli service add ssh localhost:22 --require-verified")
+	fmt.Println("  shurli service add myapp localhost:8000-8010   (one service per port)")
 	fmt.Println("  shurli service list")
 	fmt.Println("  shurli service list --peer home-node")
 	fmt.Println("  shurli service disable web")
@@ -73,12 +77,13 @@ func doServiceAdd(args []string, stdout io.Writer) error {
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
 	protocolFlag := fs.String("protocol", "", "custom protocol ID (optional)")
-	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+	requireVerifiedFlag := fs.Bool("require-verified", false, "only allow peers verified with 'shurli verify'")
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"require-verified": true})); err != nil {
 		return err
 	}
 
 	if fs.NArg() < 2 {
-		return fmt.Errorf("usage: shurli service add <name> <local-address> [--protocol <id>]")
+		return fmt.Errorf("usage: shurli service add <name> <local-address> [--protocol <id>] [--require-verified]")
 	}
 
 	name := fs.Arg(0)
@@ -89,9 +94,16 @@ func doServiceAdd(args []string, stdout io.Writer) error {
 		return fmt.Errorf("invalid service name: %w", err)
 	}
 
-	// Validate address has host:port
-	if _, _, err := net.SplitHostPort(address); err != nil {
-		return fmt.Errorf("invalid address %q: must be host:port (e.g., localhost:22)\n  Error: %v", address, err)
+	// Validate address has host:port or host:first-last
+	_, _, _, isRange, err := config.ParseServicePortRange(address)
+	if err != nil {
+		if isRange {
+			return fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		return fmt.Errorf("invalid address %q: must be host:port (e.g., localhost:22) or host:first-last\n  Error: %v", address, err)
+	}
+	if isRange && *protocolFlag != "" {
+		return fmt.Errorf("--protocol cannot be used with a port range")
 	}
 
 	cfgFile, cfg, err := resolveConfigFileErr(*configFlag)
@@ -107,6 +119,15 @@ func doServiceAdd(args []string, stdout io.Writer) error {
 		}
 	}
 
+	// Range members must not collide or overlap with existing services
+	withNew := config.ServicesConfig{name: {Enabled: true, LocalAddress: address, Protocol: *protocolFlag}}
+	for n, svc := range cfg.Services {
+		withNew[n] = svc
+	}
+	if err := config.ValidateServiceRanges(withNew); err != nil {
+		return err
+	}
+
 	// Build the service YAML block
 	var block string
 	if *protocolFlag != "" {
@@ -114,6 +135,9 @@ func doServiceAdd(args []string, stdout io.Writer) error {
 	} else {
 		block = fmt.Sprintf("  %s:\n    enabled: true\n    local_address: \"%s\"", name, address)
 	}
+	if *requireVerifiedFlag {
+		block += "\n    require_verified: true"
+	}
 
 	// Read config file and insert service
 	data, err := os.ReadFile(cfgFile)
@@ -194,10 +218,23 @@ func doServiceAdd(args []string, stdout io.Writer) error {
 	termcolor.Green("Added service: %s -> %s", name, address)
 	fmt.Fprintf(stdout, "Config: %s\n", cfgFile)
 	fmt.Fprintln(stdout)
-	tryDaemonServiceReload(stdout, name, address, true)
+	tryDaemonServiceReload(stdout)
 	return nil
 }
 
+// serviceListEntry is the json/yaml shape of one configured service.
+type serviceListEntry struct {
+	Name             string   `json:"name"`
+	LocalAddress     string   `json:"local_address"`
+	Protocol         string   `json:"protocol,omitempty"`
+	Enabled          bool     `json:"enabled"`
+	AllowedPeers     []string `json:"allowed_peers,omitempty"`
+	MaxBandwidthMbps float64  `json:"max_bandwidth_mbps,omitempty"`
+	MaxConcurrent    int      `json:"max_concurrent,omitempty"`
+	RatePerMinute    int      `json:"rate_per_minute,omitempty"`
+	RequireVerified  bool     `json:"require_verified,omitempty"`
+}
+
 func runServiceList(args []string) {
 	if err := doServiceList(args, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -210,13 +247,18 @@ func doServiceList(args []string, stdout io.Writer) error {
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
 	peerFlag := fs.String("peer", "", "query a remote peer's services")
-	if err := fs.Parse(reorderArgs(args, nil)); err != nil {
+	outFlags := output.Register(fs)
+	if err := fs.Parse(reorderArgs(args, map[string]bool{"json": true})); err != nil {
+		return err
+	}
+	format, err := outFlags.Format()
+	if err != nil {
 		return err
 	}
 
 	// Remote peer query mode.
 	if *peerFlag != "" {
-		return doRemoteServiceList(*peerFlag, stdout)
+		return doRemoteServiceList(*peerFlag, format, stdout)
 	}
 
 	cfgFile, cfg, err := resolveConfigFileErr(*configFlag)
@@ -224,14 +266,40 @@ func doServiceList(args []string, stdout io.Writer) error {
 		return err
 	}
 
-	if cfg.Services == nil || len(cfg.Services) == 0 {
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if format != output.Table {
+		list := make([]serviceListEntry, 0, len(names))
+		for _, name := range names {
+			svc := cfg.Services[name]
+			list = append(list, serviceListEntry{
+				Name:             name,
+				LocalAddress:     svc.LocalAddress,
+				Protocol:         svc.Protocol,
+				Enabled:          svc.Enabled,
+				AllowedPeers:     svc.AllowedPeers,
+				MaxBandwidthMbps: svc.MaxBandwidthMbps,
+				MaxConcurrent:    svc.MaxConcurrent,
+				RatePerMinute:    svc.RatePerMinute,
+				RequireVerified:  svc.RequireVerified,
+			})
+		}
+		return output.Write(stdout, format, list)
+	}
+
+	if len(names) == 0 {
 		fmt.Fprintln(stdout, "No services configured.")
 		fmt.Fprintf(stdout, "Config: %s\n", cfgFile)
 		return nil
 	}
 
-	fmt.Fprintf(stdout, "Services (%d):\n\n", len(cfg.Services))
-	for name, svc := range cfg.Services {
+	fmt.Fprintf(stdout, "Services (%d):\n\n", len(names))
+	for _, name := range names {
+		svc := cfg.Services[name]
 		state := "enabled"
 		if !svc.Enabled {
 			state = "disabled"
@@ -240,18 +308,32 @@ func doServiceList(args []string, stdout io.Writer) error {
 		if svc.Protocol != "" {
 			proto = fmt.Sprintf("  protocol: %s", svc.Protocol)
 		}
-		fmt.Fprintf(stdout, "  %-12s -> %-20s (%s)%s\n", name, svc.LocalAddress, state, proto)
+		if svc.RequireVerified {
+			proto += "  verified peers only"
+		}
+		if svc.MaxConcurrent > 0 || svc.RatePerMinute > 0 {
+			proto += "  per peer: " + formatServicePeerLimits(svc.MaxConcurrent, svc.RatePerMinute)
+		}
+		fmt.Fprintf(stdout, "  %-12s -> %-20s (%s)%s\n", name, svc.Target(), state, proto)
 	}
 	fmt.Fprintf(stdout, "\nConfig: %s\n", cfgFile)
 	return nil
 }
 
-func doRemoteServiceList(peer string, stdout io.Writer) error {
+func doRemoteServiceList(peer string, format output.Format, stdout io.Writer) error {
 	client := tryDaemonClient()
 	if client == nil {
 		return fmt.Errorf("daemon not running. Start with: shurli daemon")
 	}
 
+	if format != output.Table {
+		resp, err := client.RemoteServices(peer)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		return output.Write(stdout, format, resp)
+	}
+
 	text, err := client.RemoteServicesText(peer)
 	if err != nil {
 		return fmt.Errorf("query failed: %w", err)
@@ -380,7 +462,7 @@ func doServiceSetEnabled(args []string, enabled bool, stdout io.Writer) error {
 	}
 	fmt.Fprintf(stdout, "Config: %s\n", cfgFile)
 	fmt.Fprintln(stdout)
-	tryDaemonServiceReload(stdout, name, svc.LocalAddress, enabled)
+	tryDaemonServiceReload(stdout)
 	return nil
 }
 
@@ -493,28 +575,23 @@ func doServiceRemove(args []string, stdout io.Writer) error {
 	termcolor.Green("Removed service: %s", name)
 	fmt.Fprintf(stdout, "Config: %s\n", cfgFile)
 	fmt.Fprintln(stdout)
-	tryDaemonServiceReload(stdout, name, "", false)
+	tryDaemonServiceReload(stdout)
 	return nil
 }
 
-// tryDaemonServiceReload attempts to apply a service change to a running daemon.
-// If expose is true and localAddress is non-empty, exposes the service.
-// If expose is false, unexposes it. Falls back to a message if daemon is not running.
-func tryDaemonServiceReload(stdout io.Writer, name, localAddress string, expose bool) {
+// tryDaemonServiceReload asks a running daemon to reload its config, which
+// exposes, unexposes or updates services to match the services section.
+// Falls back to a message if the daemon is not running.
+func tryDaemonServiceReload(stdout io.Writer) {
 	client := tryDaemonClient()
 	if client == nil {
 		fmt.Fprintln(stdout, "Daemon not running. Changes saved to config.")
 		return
 	}
-	if expose && localAddress != "" {
-		if err := client.Expose(name, localAddress); err != nil {
-			fmt.Fprintf(stdout, "Warning: config saved but live apply failed: %v\n", err)
-			fmt.Fprintln(stdout, "Restart 'shurli daemon' to apply.")
-			return
-		}
-	} else {
-		// Unexpose - ignore errors (service may not be running)
-		_ = client.Unexpose(name)
+	if _, err := client.ConfigReload(); err != nil {
+		fmt.Fprintf(stdout, "Warning: config saved but live apply failed: %v\n", err)
+		fmt.Fprintln(stdout, "Fix the config and run 'shurli config reload', or restart 'shurli daemon'.")
+		return
 	}
 	fmt.Fprintln(stdout, "Applied immediately (live reload).")
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -126,6 +127,23 @@ func TestDoServiceAdd(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "add service restricted to verified peers",
+			args: func(cfgPath string) []string {
+				return []string{"--config", cfgPath, "ssh", "--require-verified", "localhost:22"}
+			},
+			wantOutput: []string{"Config:"},
+			checkFile: func(t *testing.T, cfgPath string) {
+				cfg, err := config.LoadNodeConfig(cfgPath)
+				if err != nil {
+					t.Fatalf("load config: %v", err)
+				}
+				svc := cfg.Services["ssh"]
+				if !svc.RequireVerified || svc.LocalAddress != "localhost:22" {
+					t.Errorf("ssh = %+v, want require_verified on localhost:22", svc)
+				}
+			},
+		},
 		{
 			name: "add duplicate service",
 			servicesYAML: `services:
@@ -185,6 +203,54 @@ func TestDoServiceAdd(t *testing.T) {
 			wantErr:    true,
 			wantErrStr: "invalid address",
 		},
+		{
+			name: "add port range service",
+			args: func(cfgPath string) []string {
+				return []string{"--config", cfgPath, "myapp", "localhost:8000-8010"}
+			},
+			wantOutput: []string{"Config:"},
+			checkFile: func(t *testing.T, cfgPath string) {
+				cfg, err := config.LoadNodeConfig(cfgPath)
+				if err != nil {
+					t.Fatalf("load config: %v", err)
+				}
+				members, err := cfg.Services["myapp"].Members("myapp")
+				if err != nil {
+					t.Fatalf("Members: %v", err)
+				}
+				if len(members) != 11 || members[5].Name != "myapp-8005" || members[5].LocalAddress != "localhost:8005" {
+					t.Errorf("unexpected members: %+v", members)
+				}
+			},
+		},
+		{
+			name: "port range with protocol rejected",
+			args: func(cfgPath string) []string {
+				return []string{"--config", cfgPath, "myapp", "localhost:8000-8010", "--protocol", "my-app"}
+			},
+			wantErr:    true,
+			wantErrStr: "--protocol cannot be used with a port range",
+		},
+		{
+			name: "reversed port range rejected",
+			args: func(cfgPath string) []string {
+				return []string{"--config", cfgPath, "myapp", "localhost:8010-8000"}
+			},
+			wantErr:    true,
+			wantErrStr: "invalid port range",
+		},
+		{
+			name: "port range overlapping existing service rejected",
+			servicesYAML: `services:
+  web:
+    enabled: true
+    local_address: "localhost:8005"`,
+			args: func(cfgPath string) []string {
+				return []string{"--config", cfgPath, "myapp", "localhost:8000-8010"}
+			},
+			wantErr:    true,
+			wantErrStr: "overlap",
+		},
 		{
 			name: "add to existing services",
 			servicesYAML: `services:
@@ -386,6 +452,50 @@ func TestDoServiceList(t *testing.T) {
 	}
 }
 
+func TestDoServiceList_Formats(t *testing.T) {
+	cfgPath := writeServiceTestConfig(t, `services:
+  web:
+    enabled: true
+    local_address: "localhost:8080"
+    max_bandwidth_mbps: 20
+  ssh:
+    enabled: false
+    local_address: "localhost:22"`)
+
+	var jsonOut bytes.Buffer
+	if err := doServiceList([]string{"--config", cfgPath, "--json"}, &jsonOut); err != nil {
+		t.Fatalf("--json: %v", err)
+	}
+	var list []serviceListEntry
+	if err := json.Unmarshal(jsonOut.Bytes(), &list); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, jsonOut.String())
+	}
+	// Sorted by name for stable output.
+	if len(list) != 2 || list[0].Name != "ssh" || list[1].Name != "web" || list[1].MaxBandwidthMbps != 20 {
+		t.Errorf("unexpected list: %+v", list)
+	}
+
+	var formatOut bytes.Buffer
+	if err := doServiceList([]string{"--config", cfgPath, "--format", "json"}, &formatOut); err != nil {
+		t.Fatalf("--format json: %v", err)
+	}
+	if formatOut.String() != jsonOut.String() {
+		t.Error("--json and --format json should produce identical output")
+	}
+
+	var yamlOut bytes.Buffer
+	if err := doServiceList([]string{"--config", cfgPath, "--format", "yaml"}, &yamlOut); err != nil {
+		t.Fatalf("--format yaml: %v", err)
+	}
+	if !strings.Contains(yamlOut.String(), "local_address: localhost:22") {
+		t.Errorf("yaml output missing fields:\n%s", yamlOut.String())
+	}
+
+	if err := doServiceList([]string{"--config", cfgPath, "--format", "xml"}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
 // ----- doServiceSetEnabled tests -----
 
 func TestDoServiceSetEnabled(t *testing.T) {
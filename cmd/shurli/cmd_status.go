@@ -93,6 +93,14 @@ func doStatus(args []string, stdout io.Writer) error {
 	} else {
 		tc.Wred(stdout, "not running\n")
 	}
+	// The running daemon's mode wins: the config may have been edited
+	// since it started.
+	ipMode := cfg.Network.EffectiveIPMode()
+	if daemonStatus != nil && daemonStatus.IPMode != "" {
+		ipMode = daemonStatus.IPMode
+	}
+	tc.Wblue(stdout, "IP mode:  ")
+	fmt.Fprintf(stdout, "%s\n", sdk.IPModeLabel(ipMode))
 	fmt.Fprintln(stdout)
 
 	// Reachability details (when daemon is running and grade available)
@@ -102,6 +110,9 @@ func doStatus(args []string, stdout io.Writer) error {
 		writeReachabilityGrade(stdout, r)
 		tc.Wfaint(stdout, " - %s", r.Description)
 		fmt.Fprintln(stdout)
+		if t := daemonStatus.Traversal; t != nil {
+			tc.Wfaint(stdout, "  %s\n", t.Summary)
+		}
 		// Practical guidance for restricted NAT grades.
 		switch r.Grade {
 		case "C", "D":
@@ -154,6 +165,12 @@ func doStatus(args []string, stdout io.Writer) error {
 				fmt.Fprintf(stdout, "%-12s  ", validate.SanitizeForDisplay(r.RelayName))
 			}
 			fmt.Fprint(stdout, truncateAddr(r.Address))
+			if r.Role != "" {
+				fmt.Fprintf(stdout, "  %s", r.Role)
+			}
+			if r.LatencyMs > 0 {
+				fmt.Fprintf(stdout, "  %.1fms", r.LatencyMs)
+			}
 			if r.AgentVersion != "" {
 				tc.Wfaint(stdout, "  %s", validate.SanitizeForDisplay(r.AgentVersion))
 			}
@@ -241,6 +258,24 @@ func doStatus(args []string, stdout io.Writer) error {
 		}
 	}
 
+	// Authorized peers seen on the LAN via mDNS (direct LAN connections).
+	if daemonStatus != nil && len(daemonStatus.MDNSPeers) > 0 {
+		names := make([]string, 0, len(daemonStatus.MDNSPeers))
+		for _, p := range daemonStatus.MDNSPeers {
+			name := validate.SanitizeForDisplay(p.Name)
+			if name == "" {
+				name = p.PeerID
+				if len(name) > 16 {
+					name = name[:16] + "..."
+				}
+			}
+			names = append(names, name)
+		}
+		fmt.Fprintln(stdout)
+		tc.Wblue(stdout, "LAN (mDNS): ")
+		fmt.Fprintf(stdout, "%d peer(s): %s\n", len(names), strings.Join(names, ", "))
+	}
+
 	// Notifications section: configured sinks + expiring grants.
 	if daemonStatus != nil && (daemonStatus.Notifications != nil || len(daemonStatus.ExpiringGrants) > 0) {
 		fmt.Fprintln(stdout)
@@ -318,7 +353,7 @@ func doStatus(args []string, stdout io.Writer) error {
 		fmt.Fprintln(stdout, "Services:")
 		for name, svc := range cfg.Services {
 			fmt.Fprint(stdout, "  ")
-			fmt.Fprintf(stdout, "%-12s -> %-20s ", name, svc.LocalAddress)
+			fmt.Fprintf(stdout, "%-12s -> %-20s ", name, svc.Target())
 			if svc.Enabled {
 				tc.Wgreen(stdout, "(enabled)")
 			} else {
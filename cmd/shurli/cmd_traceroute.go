@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/shurlinet/shurli/internal/daemon"
 	tc "github.com/shurlinet/shurli/internal/termcolor"
@@ -14,28 +18,47 @@ import (
 )
 
 func runTraceroute(args []string) {
-	args = reorderArgs(args, map[string]bool{"json": true})
+	args = reorderArgs(args, map[string]bool{"json": true, "watch": true, "json-lines": true, "standalone": true})
 
 	fs := flag.NewFlagSet("traceroute", flag.ExitOnError)
 	configFlag := fs.String("config", "", "path to config file")
 	jsonFlag := fs.Bool("json", false, "output as JSON")
 	standaloneFlag := fs.Bool("standalone", false, "use direct P2P without daemon (debug)")
+	watchFlag := fs.Bool("watch", false, "re-trace on an interval and report path changes")
+	intervalStr := fs.String("interval", "10s", "time between traces in --watch mode")
+	jsonLinesFlag := fs.Bool("json-lines", false, "in --watch mode, output one JSON object per line")
 	fs.Parse(reorderFlags(fs, args))
 
 	remaining := fs.Args()
 	if len(remaining) < 1 {
 		fmt.Println("Usage: shurli traceroute [--config <path>] [--json] [--standalone] <target>")
+		fmt.Println("       shurli traceroute --watch [--interval 10s] [--json-lines] <target>")
+		fmt.Println()
+		fmt.Println("<target> is a name, a peer ID, or a full multiaddr ending in /p2p/<peer-id>.")
 		osExit(1)
 	}
 
 	target := remaining[0]
 
+	var interval time.Duration
+	if *watchFlag {
+		d, err := time.ParseDuration(*intervalStr)
+		if err != nil || d <= 0 {
+			fatal("Invalid --interval %q: must be a positive duration (e.g. 10s)", *intervalStr)
+		}
+		interval = d
+	}
+
 	// Standalone allowed via CLI flag or config setting.
 	allowStandalone := *standaloneFlag || configAllowsStandalone(*configFlag)
 
 	// Always try daemon first (uses existing connections, supports direct paths).
 	if !allowStandalone {
 		if client := tryDaemonClient(); client != nil {
+			if *watchFlag {
+				runTracerouteWatchViaDaemon(client, target, interval, *jsonLinesFlag)
+				return
+			}
 			runTracerouteViaDaemon(client, target, *jsonFlag)
 			return
 		}
@@ -68,7 +91,7 @@ func runTraceroute(args []string) {
 	}
 	defer standalone.Network.Close()
 
-	if !*jsonFlag {
+	if !*jsonFlag && !*jsonLinesFlag {
 		tc.Wfaint(os.Stdout, "traceroute to %s\n", target)
 		fmt.Println("Connecting...")
 	}
@@ -78,6 +101,22 @@ func runTraceroute(args []string) {
 		fatal("%v", err)
 	}
 
+	if *watchFlag {
+		// No daemon PathTracker to lean on: re-probe the live connection each
+		// interval. A failed trace triggers a reconnect attempt so the next
+		// sample reflects whatever path libp2p lands on.
+		sample := func() tracePathSample {
+			result, err := sdk.TracePeer(ctx, standalone.Network.Host(), targetPeerID)
+			if err != nil {
+				standalone.ResolveAndConnect(ctx, target)
+				return tracePathSample{Path: tracePathUnreachable, Error: err.Error()}
+			}
+			return tracePathSample{Path: result.Path, Address: traceResultAddress(result)}
+		}
+		watchTracePath(os.Stdout, target, interval, *jsonLinesFlag, sample, watchStopSignal())
+		return
+	}
+
 	// Run traceroute
 	result, err := sdk.TracePeer(ctx, standalone.Network.Host(), targetPeerID)
 	if err != nil {
@@ -98,21 +137,22 @@ func runTraceroute(args []string) {
 		if len(peerShort) > 16 {
 			peerShort = peerShort[:16] + "..."
 		}
+		if peerShort == "" {
+			peerShort = "?"
+		}
+		hop.Name = validate.SanitizeForDisplay(hop.Name)
+		fmt.Printf(" %d  %s%s  %s  ", hop.Hop, peerShort, hop.Label(), hop.Address)
 		if hop.Error != "" {
-			fmt.Printf(" %d  %s  %s  ", hop.Hop, peerShort, hop.Address)
 			tc.Wred(os.Stdout, "*")
-			fmt.Println()
 		} else {
-			name := ""
-			if hop.Name != "" {
-				name = " (" + validate.SanitizeForDisplay(hop.Name) + ")"
-			}
-			fmt.Printf(" %d  %s%s  %s  ", hop.Hop, peerShort, name, hop.Address)
 			tc.Wgreen(os.Stdout, "%.1fms", hop.RttMs)
-			fmt.Println()
+			if note := hop.SegmentNote(); note != "" {
+				tc.Wfaint(os.Stdout, "  %s", note)
+			}
 		}
+		fmt.Println()
 	}
-	tc.Wfaint(os.Stdout, "--- path: [%s] ---\n", result.Path)
+	tc.Wfaint(os.Stdout, "--- path: [%s] ---\n", validate.SanitizeForDisplay(result.PathSummary()))
 }
 
 // runTracerouteViaDaemon traces a peer through the running daemon.
@@ -140,3 +180,175 @@ func runTracerouteViaDaemon(client *daemon.Client, target string, jsonOutput boo
 		fmt.Print(text)
 	}
 }
+
+// tracePathUnreachable is the path label recorded when a watch sample fails.
+const tracePathUnreachable = "UNREACHABLE"
+
+// tracePathSample is one observation of the path to a peer in --watch mode.
+type tracePathSample struct {
+	Path    string // DIRECT, RELAYED, or UNREACHABLE
+	Address string
+	Error   string
+}
+
+// tracePathChange is the --json-lines record emitted when the path changes.
+type tracePathChange struct {
+	Time     string `json:"time"`
+	Target   string `json:"target"`
+	Path     string `json:"path"`
+	PrevPath string `json:"prev_path,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Flaps    int    `json:"flaps"`
+}
+
+// tracePathSummary is the --json-lines record emitted when watching stops.
+type tracePathSummary struct {
+	Target          string  `json:"target"`
+	Samples         int     `json:"samples"`
+	Flaps           int     `json:"flaps"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	FinalPath       string  `json:"final_path,omitempty"`
+}
+
+// traceResultAddress returns the address of the final hop (the target itself).
+func traceResultAddress(r *sdk.TraceResult) string {
+	if len(r.Hops) == 0 {
+		return ""
+	}
+	return r.Hops[len(r.Hops)-1].Address
+}
+
+// watchStopSignal returns a channel closed on Ctrl+C or SIGTERM.
+func watchStopSignal() <-chan struct{} {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	stop := make(chan struct{})
+	go func() {
+		<-sigCh
+		close(stop)
+	}()
+	return stop
+}
+
+// watchTracePath samples the path to target every interval until stop is
+// closed. The first sample and every path change are printed with a
+// timestamp; a flap is any change after the first observation. Returns the
+// number of flaps seen.
+func watchTracePath(w io.Writer, target string, interval time.Duration, jsonLines bool, sample func() tracePathSample, stop <-chan struct{}) int {
+	start := time.Now()
+	if !jsonLines {
+		tc.Wfaint(w, "watching path to %s every %s (Ctrl+C to stop)\n", target, interval)
+	}
+
+	var (
+		last    string
+		samples int
+		flaps   int
+	)
+
+	for {
+		s := sample()
+		samples++
+
+		if s.Path != last {
+			if last != "" {
+				flaps++
+			}
+			now := time.Now()
+			if jsonLines {
+				line, _ := json.Marshal(tracePathChange{
+					Time:     now.UTC().Format(time.RFC3339),
+					Target:   target,
+					Path:     s.Path,
+					PrevPath: last,
+					Address:  s.Address,
+					Error:    s.Error,
+					Flaps:    flaps,
+				})
+				fmt.Fprintln(w, string(line))
+			} else {
+				fmt.Fprintf(w, "%s  ", now.Format("15:04:05"))
+				if last != "" {
+					fmt.Fprintf(w, "%s -> ", last)
+				}
+				switch s.Path {
+				case string(sdk.PathDirect):
+					tc.Wgreen(w, "%s", s.Path)
+				case tracePathUnreachable:
+					tc.Wred(w, "%s", s.Path)
+				default:
+					tc.Wyellow(w, "%s", s.Path)
+				}
+				if s.Address != "" {
+					fmt.Fprintf(w, "  %s", s.Address)
+				}
+				if s.Error != "" {
+					tc.Wfaint(w, "  (%s)", s.Error)
+				}
+				fmt.Fprintln(w)
+			}
+			last = s.Path
+		}
+
+		select {
+		case <-stop:
+			elapsed := time.Since(start)
+			if jsonLines {
+				line, _ := json.Marshal(tracePathSummary{
+					Target:          target,
+					Samples:         samples,
+					Flaps:           flaps,
+					DurationSeconds: elapsed.Seconds(),
+					FinalPath:       last,
+				})
+				fmt.Fprintln(w, string(line))
+			} else {
+				tc.Wfaint(w, "\n--- %s path watch ---\n", target)
+				fmt.Fprintf(w, "%d samples over %s, ", samples, elapsed.Round(time.Second))
+				if flaps > 0 {
+					tc.Wred(w, "%d flaps", flaps)
+				} else {
+					tc.Wgreen(w, "0 flaps")
+				}
+				fmt.Fprintf(w, ", final path [%s]\n", last)
+			}
+			return flaps
+		case <-time.After(interval):
+		}
+	}
+}
+
+// runTracerouteWatchViaDaemon watches the path to a peer using the daemon's
+// PathTracker. The target is resolved with a single traceroute; afterwards
+// each sample is a cheap read of /v1/paths, falling back to a full trace
+// when the tracker has no entry for the peer.
+func runTracerouteWatchViaDaemon(client *daemon.Client, target string, interval time.Duration, jsonLines bool) {
+	if !jsonLines {
+		showVerificationBadge(client, target)
+	}
+
+	initial, err := client.Traceroute(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+		return
+	}
+	targetID := initial.TargetID
+
+	sample := func() tracePathSample {
+		if paths, err := client.Paths(); err == nil {
+			for _, p := range paths {
+				if p.PeerID == targetID {
+					return tracePathSample{Path: p.PathType, Address: p.Address}
+				}
+			}
+		}
+		result, err := client.Traceroute(target)
+		if err != nil {
+			return tracePathSample{Path: tracePathUnreachable, Error: err.Error()}
+		}
+		return tracePathSample{Path: result.Path, Address: traceResultAddress(result)}
+	}
+	watchTracePath(os.Stdout, target, interval, jsonLines, sample, watchStopSignal())
+}
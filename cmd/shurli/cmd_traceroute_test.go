@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchTracePath_CountsFlaps(t *testing.T) {
+	seq := []string{"DIRECT", "DIRECT", "RELAYED", "RELAYED", "DIRECT", tracePathUnreachable}
+	stop := make(chan struct{})
+	i := 0
+	sample := func() tracePathSample {
+		s := tracePathSample{Path: seq[i], Address: "/ip4/203.0.113.1/tcp/4001"}
+		i++
+		if i == len(seq) {
+			close(stop)
+		}
+		return s
+	}
+
+	var buf bytes.Buffer
+	flaps := watchTracePath(&buf, "home", time.Millisecond, true, sample, stop)
+	if flaps != 3 {
+		t.Fatalf("flaps = %d, want 3", flaps)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	// initial + 3 changes + summary
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5:\n%s", len(lines), buf.String())
+	}
+
+	var change tracePathChange
+	if err := json.Unmarshal([]byte(lines[1]), &change); err != nil {
+		t.Fatalf("unmarshal change: %v", err)
+	}
+	if change.PrevPath != "DIRECT" || change.Path != "RELAYED" || change.Flaps != 1 {
+		t.Errorf("unexpected change record: %+v", change)
+	}
+
+	var summary tracePathSummary
+	if err := json.Unmarshal([]byte(lines[4]), &summary); err != nil {
+		t.Fatalf("unmarshal summary: %v", err)
+	}
+	if summary.Samples != len(seq) || summary.Flaps != 3 || summary.FinalPath != tracePathUnreachable {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestWatchTracePath_StablePathText(t *testing.T) {
+	stop := make(chan struct{})
+	n := 0
+	sample := func() tracePathSample {
+		n++
+		if n == 3 {
+			close(stop)
+		}
+		return tracePathSample{Path: "DIRECT"}
+	}
+
+	var buf bytes.Buffer
+	if flaps := watchTracePath(&buf, "home", time.Millisecond, false, sample, stop); flaps != 0 {
+		t.Fatalf("flaps = %d, want 0", flaps)
+	}
+	out := buf.String()
+	if strings.Count(out, "DIRECT") != 2 { // initial line + summary
+		t.Errorf("expected one path line and summary, got:\n%s", out)
+	}
+	if !strings.Contains(out, "3 samples") {
+		t.Errorf("summary missing sample count:\n%s", out)
+	}
+}
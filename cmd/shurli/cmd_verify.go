@@ -105,6 +105,14 @@ func runVerify(args []string) {
 	fmt.Println()
 	fmt.Println()
 
+	// Per-identity fingerprints: each side can also read theirs from
+	// "shurli whoami --fingerprint" and the other compares it here.
+	termcolor.Wblue(os.Stdout, "Their fingerprint:  ")
+	fmt.Println(identity.PeerFingerprint(targetPeerID))
+	termcolor.Wblue(os.Stdout, "Your fingerprint:   ")
+	fmt.Println(identity.PeerFingerprint(ourPeerID))
+	fmt.Println()
+
 	if displayName != "" {
 		fmt.Printf("Compare this with %s over a secure channel\n", displayName)
 	} else {
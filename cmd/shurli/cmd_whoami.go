@@ -6,10 +6,14 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/internal/daemon"
 	"github.com/shurlinet/shurli/internal/identity"
 	"github.com/shurlinet/shurli/pkg/sdk"
 )
@@ -21,13 +25,46 @@ func runWhoami(args []string) {
 	}
 }
 
+// whoamiResult is the --json form of whoami.
+type whoamiResult struct {
+	PeerID        string             `json:"peer_id"`             // the ID peers see
+	MasterID      string             `json:"master_id,omitempty"` // set when a network namespace is configured
+	Network       string             `json:"network,omitempty"`
+	Fingerprint   *whoamiFingerprint `json:"fingerprint,omitempty"`
+	ConnectHint   string             `json:"connect_hint,omitempty"`
+	DaemonRunning bool               `json:"daemon_running"`
+	Addresses     []whoamiAddr       `json:"addresses,omitempty"` // advertised by the daemon
+	Observed      []sdk.ObservedAddr `json:"observed_addresses,omitempty"`
+}
+
+type whoamiFingerprint struct {
+	Words []string `json:"words"`
+	Hex   string   `json:"hex"`
+}
+
+// whoamiAddr is one advertised address with the label the status printer
+// uses: RELAY, public or local.
+type whoamiAddr struct {
+	Addr   string `json:"addr"`
+	Label  string `json:"label"`
+	Direct bool   `json:"direct"`
+}
+
 func doWhoami(args []string, stdout io.Writer) error {
 	fs := flag.NewFlagSet("whoami", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	configFlag := fs.String("config", "", "path to config file")
+	addrsFlag := fs.Bool("addresses", false, "also show advertised and peer-observed addresses (needs a running daemon)")
+	fingerprintFlag := fs.Bool("fingerprint", false, "also show a short fingerprint of the identity for reading aloud")
+	jsonFlag := fs.Bool("json", false, "output as JSON (includes addresses when the daemon is running)")
+	watchFlag := fs.Bool("watch-reachability", false, "poll the daemon and report reachability changes until stable (needs a running daemon)")
+	timeoutFlag := fs.Duration("timeout", 2*time.Minute, "give up watching reachability after this long")
 	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
 		return err
 	}
+	if *jsonFlag && *watchFlag {
+		return fmt.Errorf("--json and --watch-reachability cannot be combined")
+	}
 
 	cfgFile, err := config.FindConfigFile(*configFlag)
 	if err != nil {
@@ -52,8 +89,9 @@ func doWhoami(args []string, stdout io.Writer) error {
 
 	// If a namespace is configured, show the namespace-specific peer ID
 	// that the node actually uses on the network.
-	ns := cfg.Discovery.Network
-	if ns != "" {
+	res := whoamiResult{PeerID: masterID.String()}
+	networkID := masterID
+	if ns := cfg.Discovery.Network; ns != "" {
 		nsKey, err := identity.DeriveNamespaceKey(priv, ns)
 		if err != nil {
 			return fmt.Errorf("failed to derive namespace identity: %w", err)
@@ -62,11 +100,207 @@ func doWhoami(args []string, stdout io.Writer) error {
 		if err != nil {
 			return fmt.Errorf("failed to derive namespace peer ID: %w", err)
 		}
-		fmt.Fprintf(stdout, "%s  (network: %s)\n", nsID.String(), ns)
-		fmt.Fprintf(stdout, "Master ID: %s\n", masterID.String())
+		res.PeerID, res.MasterID, res.Network = nsID.String(), masterID.String(), ns
+		networkID = nsID
+	}
+
+	// The fingerprint is of the ID peers see, so it matches what they
+	// compute from their authorized_keys entry for us.
+	if *fingerprintFlag || *jsonFlag {
+		fp := identity.PeerFingerprint(networkID)
+		res.Fingerprint = &whoamiFingerprint{Words: fp.Words, Hex: fp.Hex}
+	}
+
+	// Live addresses come from the daemon. Without one, the connect hint
+	// falls back to the configured relays.
+	var status *daemon.StatusResponse
+	if *addrsFlag || *jsonFlag {
+		if c := tryDaemonClient(); c != nil {
+			if status, err = c.Status(); err != nil {
+				return fmt.Errorf("daemon status: %w", err)
+			}
+		}
+	}
+	circuits := make([]string, 0, len(cfg.Relay.ActiveAddresses()))
+	for _, r := range cfg.Relay.ActiveAddresses() {
+		circuits = append(circuits, r+"/p2p-circuit")
+	}
+	if status != nil {
+		res.DaemonRunning = true
+		res.Addresses = whoamiAddresses(status)
+		res.Observed = status.ObservedAddrs
+		if len(status.RelayAddrs) > 0 {
+			circuits = status.RelayAddrs
+		}
+	}
+	res.ConnectHint = connectHint(res.PeerID, circuits)
+
+	if *jsonFlag {
+		return writeJSON(stdout, res)
+	}
+
+	if res.Network != "" {
+		fmt.Fprintf(stdout, "%s  (network: %s)\n", res.PeerID, res.Network)
+		fmt.Fprintf(stdout, "Master ID: %s\n", res.MasterID)
 	} else {
-		fmt.Fprintln(stdout, masterID.String())
+		fmt.Fprintln(stdout, res.PeerID)
+	}
+	if *fingerprintFlag {
+		fmt.Fprintf(stdout, "Fingerprint: %s\n", strings.Join(res.Fingerprint.Words, " "))
+		fmt.Fprintf(stdout, "             %s\n", res.Fingerprint.Hex)
+	}
+	if res.ConnectHint != "" {
+		fmt.Fprintf(stdout, "Connect hint: %s\n", res.ConnectHint)
+	}
+
+	if *addrsFlag {
+		if status == nil {
+			fmt.Fprintln(stdout, "\nAddresses: daemon not running (start it with: shurli daemon)")
+			return nil
+		}
+		printWhoamiAddresses(stdout, status)
+	}
+
+	if *watchFlag {
+		c := tryDaemonClient()
+		if c == nil {
+			return fmt.Errorf("--watch-reachability needs a running daemon (start it with: shurli daemon)")
+		}
+		fmt.Fprintln(stdout)
+		return watchReachability(stdout, c.Status, reachabilityPollInterval, reachabilityStableFor, *timeoutFlag)
 	}
 
 	return nil
 }
+
+// whoamiAddresses labels every address the daemon advertises, direct ones
+// first.
+func whoamiAddresses(status *daemon.StatusResponse) []whoamiAddr {
+	out := make([]whoamiAddr, 0, len(status.ListenAddrs)+len(status.RelayAddrs))
+	for _, a := range append(slices.Clone(status.ListenAddrs), status.RelayAddrs...) {
+		label := sdk.AddrLabel(a)
+		out = append(out, whoamiAddr{Addr: a, Label: label, Direct: label != sdk.AddrLabelRelay})
+	}
+	return out
+}
+
+// connectHint bundles the peer ID with a relay circuit address into one
+// multiaddr that ping and traceroute accept as a target.
+// Circuits through a relay at a public address are preferred. Empty when
+// there is no relay.
+func connectHint(peerID string, circuits []string) string {
+	if len(circuits) == 0 {
+		return ""
+	}
+	best := circuits[0]
+	for _, c := range circuits {
+		if sdk.ClassifyAddrString(strings.TrimSuffix(c, "/p2p-circuit")) == sdk.AddrGlobal {
+			best = c
+			break
+		}
+	}
+	return best + "/p2p/" + peerID
+}
+
+// Reachability states reported by whoami --watch-reachability, worst to best.
+const (
+	reachUnreachable = "unreachable"
+	reachViaRelay    = "reachable via relay"
+	reachDirect      = "reachable directly"
+)
+
+const (
+	reachabilityPollInterval = 2 * time.Second
+	// reachabilityStableFor is how long a relay-only state must hold
+	// before the watch stops. Direct reachability ends it at once.
+	reachabilityStableFor = 30 * time.Second
+)
+
+// classifyReachability reduces a daemon status to one of the reach*
+// states, with a detail that justifies it. The daemon's reachability
+// grade decides whether peers can dial us directly (A or B: a public
+// address or a hole-punchable NAT); otherwise a relay circuit address
+// means they can reach us through a relay reservation.
+func classifyReachability(s *daemon.StatusResponse) (state, detail string) {
+	if g := s.Reachability; g != nil && (g.Grade == sdk.GradeA || g.Grade == sdk.GradeB) {
+		return reachDirect, "grade " + g.Grade + ": " + g.Description
+	}
+	if len(s.RelayAddrs) > 0 {
+		return reachViaRelay, s.RelayAddrs[0]
+	}
+	return reachUnreachable, ""
+}
+
+// watchReachability polls status every interval and prints each change of
+// reachability state with a timestamp. It returns once the node is
+// reachable directly, once it has been reachable via relay for stableFor,
+// or at timeout - an error if the node never became reachable.
+func watchReachability(w io.Writer, status func() (*daemon.StatusResponse, error), interval, stableFor, timeout time.Duration) error {
+	fmt.Fprintf(w, "Watching reachability (timeout %s)...\n", timeout)
+	deadline := time.Now().Add(timeout)
+	last := ""
+	var since time.Time
+	for {
+		s, err := status()
+		if err != nil {
+			return fmt.Errorf("daemon status: %w", err)
+		}
+		now := time.Now()
+		state, detail := classifyReachability(s)
+		if state != last {
+			line := fmt.Sprintf("[%s] %s", now.Format("15:04:05"), state)
+			if detail != "" {
+				line += "  (" + detail + ")"
+			}
+			fmt.Fprintln(w, line)
+			last, since = state, now
+		}
+
+		switch {
+		case state == reachDirect:
+			return nil
+		case state == reachViaRelay && now.Sub(since) >= stableFor:
+			fmt.Fprintf(w, "Stable: %s for %s\n", state, stableFor)
+			return nil
+		case !now.Before(deadline):
+			if state == reachUnreachable {
+				return fmt.Errorf("still unreachable after %s", timeout)
+			}
+			fmt.Fprintf(w, "Timed out after %s; still %s\n", timeout, state)
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// printWhoamiAddresses prints the addresses the daemon advertises, each
+// labelled direct or RELAY and public or local, and the addresses peers
+// observed us at. The observed ones are what NATs actually map us to, so
+// they are the ones to compare when debugging traversal.
+func printWhoamiAddresses(w io.Writer, status *daemon.StatusResponse) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Advertised addresses:")
+	addrs := whoamiAddresses(status)
+	if len(addrs) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, a := range addrs {
+		label := a.Label
+		if a.Direct {
+			label = "direct " + label
+		}
+		fmt.Fprintf(w, "  [%s] %s\n", label, a.Addr)
+	}
+
+	fmt.Fprintln(w, "Observed by peers:")
+	if len(status.ObservedAddrs) == 0 {
+		fmt.Fprintln(w, "  (none yet - no peer has reported our address)")
+	}
+	for _, oa := range status.ObservedAddrs {
+		reporters := make([]string, 0, len(oa.ReportedBy))
+		for _, r := range oa.ReportedBy {
+			reporters = append(reporters, truncateID(r))
+		}
+		fmt.Fprintf(w, "  %s  (reported by %s)\n", oa.Addr, strings.Join(reporters, ", "))
+	}
+}
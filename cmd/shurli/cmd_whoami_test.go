@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/daemon"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+func TestPrintWhoamiAddresses(t *testing.T) {
+	t.Run("observed addresses labelled with reporters", func(t *testing.T) {
+		var buf bytes.Buffer
+		printWhoamiAddresses(&buf, &daemon.StatusResponse{
+			ListenAddrs: []string{"/ip4/192.168.1.10/tcp/9100", "/ip4/203.0.113.7/tcp/9100"},
+			RelayAddrs:  []string{"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit"},
+			ObservedAddrs: []sdk.ObservedAddr{{
+				Addr:       "/ip4/203.0.113.7/tcp/41234",
+				ReportedBy: []string{"12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"},
+			}},
+		})
+		out := buf.String()
+		for _, want := range []string{
+			"Advertised addresses:\n" +
+				"  [direct local] /ip4/192.168.1.10/tcp/9100\n" +
+				"  [direct public] /ip4/203.0.113.7/tcp/9100\n" +
+				"  [RELAY] /ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit\n",
+			"Observed by peers:\n  /ip4/203.0.113.7/tcp/41234  (reported by 12D3KooWDpJ7As7B...)\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q\ngot:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("no observations yet", func(t *testing.T) {
+		var buf bytes.Buffer
+		printWhoamiAddresses(&buf, &daemon.StatusResponse{})
+		if !strings.Contains(buf.String(), "none yet") {
+			t.Errorf("expected empty-state hint, got:\n%s", buf.String())
+		}
+	})
+}
+
+func TestConnectHint(t *testing.T) {
+	const id = "12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"
+	if got := connectHint(id, nil); got != "" {
+		t.Errorf("no relays: got %q, want empty", got)
+	}
+	got := connectHint(id, []string{
+		"/ip4/10.0.0.2/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit",
+		"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit",
+	})
+	want := "/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit/p2p/" + id
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestClassifyReachability(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    daemon.StatusResponse
+		want string
+	}{
+		{"nothing", daemon.StatusResponse{ListenAddrs: []string{"/ip4/192.168.1.10/tcp/9100"}}, reachUnreachable},
+		{"relay circuit", daemon.StatusResponse{RelayAddrs: []string{"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWK/p2p-circuit"}}, reachViaRelay},
+		{"grade B", daemon.StatusResponse{
+			ListenAddrs:  []string{"/ip4/192.168.1.10/tcp/9100"},
+			RelayAddrs:   []string{"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWK/p2p-circuit"},
+			Reachability: &sdk.ReachabilityGrade{Grade: sdk.GradeB, Description: "Hole-punchable NAT (full-cone)"},
+		}, reachDirect},
+		// A public listen address or interface alone is not enough: the
+		// grade accounts for firewalls and NAT seen by STUN.
+		{"symmetric NAT with global address", daemon.StatusResponse{
+			ListenAddrs:   []string{"/ip4/203.0.113.7/tcp/9100"},
+			HasGlobalIPv4: true,
+			RelayAddrs:    []string{"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWK/p2p-circuit"},
+			Reachability:  &sdk.ReachabilityGrade{Grade: sdk.GradeD, Description: "Symmetric NAT (CGNAT likely)"},
+		}, reachViaRelay},
+	} {
+		if got, _ := classifyReachability(&tc.s); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// statusSequence returns a status func that yields each response in turn,
+// repeating the last one.
+func statusSequence(seq ...*daemon.StatusResponse) func() (*daemon.StatusResponse, error) {
+	i := 0
+	return func() (*daemon.StatusResponse, error) {
+		s := seq[min(i, len(seq)-1)]
+		i++
+		return s, nil
+	}
+}
+
+func TestWatchReachability(t *testing.T) {
+	unreachable := &daemon.StatusResponse{}
+	relayed := &daemon.StatusResponse{RelayAddrs: []string{"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWK/p2p-circuit"}}
+	direct := &daemon.StatusResponse{Reachability: &sdk.ReachabilityGrade{Grade: sdk.GradeA, Description: "Public IPv6 detected"}}
+
+	t.Run("reports each transition and stops when direct", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := watchReachability(&buf, statusSequence(unreachable, unreachable, relayed, direct), time.Millisecond, time.Hour, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		iu := strings.Index(out, "] "+reachUnreachable)
+		ir := strings.Index(out, "] "+reachViaRelay)
+		id := strings.Index(out, "] "+reachDirect+"  (grade A: Public IPv6 detected)")
+		if iu < 0 || ir < iu || id < ir {
+			t.Errorf("transitions missing or out of order:\n%s", out)
+		}
+		if n := strings.Count(out, reachUnreachable); n != 1 {
+			t.Errorf("unchanged state printed %d times:\n%s", n, out)
+		}
+	})
+
+	t.Run("relay stable", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := watchReachability(&buf, statusSequence(relayed), time.Millisecond, 5*time.Millisecond, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "Stable: "+reachViaRelay) {
+			t.Errorf("expected stable message, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("timeout while unreachable is an error", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := watchReachability(&buf, statusSequence(unreachable), time.Millisecond, time.Hour, 10*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "still unreachable") {
+			t.Fatalf("err = %v, want still unreachable", err)
+		}
+	})
+}
@@ -6,63 +6,135 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+// Config presets for "shurli init --template". Every preset is a complete,
+// valid node config; they differ in listen ports, relay use and which
+// optional sections are spelled out.
+const (
+	templateHomeNode    = "home-node"    // always-on machine behind NAT exposing services (default)
+	templateRelayClient = "relay-client" // laptop or phone reaching peers through a relay
+	templateMinimal     = "minimal"      // direct connections only, no relay
+	templateServer      = "server"       // public-IP machine on fixed ports
 )
 
+// nodeConfigTemplates lists the presets in the order help text shows them.
+var nodeConfigTemplates = []string{templateHomeNode, templateRelayClient, templateMinimal, templateServer}
+
+// serverListenPort is the fixed port the server preset listens on, so
+// firewall rules can allow it.
+const serverListenPort = 9100
+
+// isNodeConfigTemplate reports whether name is a known preset.
+func isNodeConfigTemplate(name string) bool {
+	for _, t := range nodeConfigTemplates {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// templateUsesRelay reports whether a preset needs relay addresses.
+func templateUsesRelay(name string) bool {
+	return name != templateMinimal
+}
+
 // nodeConfigTemplate returns the default config YAML for a new Shurli node.
 // This is the single source of truth used by both "shurli init" and "shurli join".
 // relayAddrs are the full multiaddrs of relay servers (one or more).
 // generator identifies what created the config (e.g., "shurli init", "shurli join").
 // network is the optional DHT namespace (empty = global network).
+// Key and authorized_keys file names follow the active --profile.
 func nodeConfigTemplate(relayAddrs []string, generator, network string) string {
+	out, _ := nodeConfigTemplateFor(templateHomeNode, relayAddrs, generator, network)
+	return out
+}
+
+// nodeConfigTemplateFor renders the named preset. relayAddrs is ignored by
+// presets that run without a relay (see templateUsesRelay).
+func nodeConfigTemplateFor(name string, relayAddrs []string, generator, network string) (string, error) {
+	if !isNodeConfigTemplate(name) {
+		return "", fmt.Errorf("unknown config template %q (choose from: %s)", name, strings.Join(nodeConfigTemplates, ", "))
+	}
+
 	networkLine := ""
 	if network != "" {
 		networkLine = fmt.Sprintf("  network: %q\n", network)
 	}
 
-	// Build YAML list of relay addresses
-	var addrLines string
-	for _, addr := range relayAddrs {
-		addrLines += fmt.Sprintf("    - %q\n", addr)
+	header := fmt.Sprintf("# Shurli configuration\n# Generated by: %s\n", generator)
+	if name != templateHomeNode {
+		header += fmt.Sprintf("# Template: %s\n", name)
 	}
 
-	return fmt.Sprintf(`# Shurli configuration
-# Generated by: %s
-
-version: 1
-
-identity:
-  key_file: "identity.key"
-
-network:
-  listen_addresses:
-    - "/ip4/0.0.0.0/tcp/0"
-    - "/ip4/0.0.0.0/udp/0/quic-v1"
-    - "/ip6/::/tcp/0"
-    - "/ip6/::/udp/0/quic-v1"
-  # Set to true on nodes behind CGNAT (required for shurli daemon)
+	port := 0
+	if name == templateServer {
+		port = serverListenPort
+	}
+	listen := ""
+	if name == templateServer {
+		listen += fmt.Sprintf("  # Fixed ports: allow TCP and UDP %d through the firewall\n", port)
+	}
+	listen += fmt.Sprintf(`  listen_addresses:
+    - "/ip4/0.0.0.0/tcp/%[1]d"
+    - "/ip4/0.0.0.0/udp/%[1]d/quic-v1"
+    - "/ip6/::/tcp/%[1]d"
+    - "/ip6/::/udp/%[1]d/quic-v1"
+`, port)
+
+	var reachability string
+	switch name {
+	case templateRelayClient:
+		reachability = `  # Roaming devices are usually behind NAT: hold relay reservations
+  # from the start instead of waiting for AutoNAT to decide
+  force_private_reachability: true
+`
+	case templateMinimal:
+		// Without a relay there is nothing to force.
+	default:
+		reachability = `  # Set to true on nodes behind CGNAT (required for shurli daemon)
   force_private_reachability: false
+`
+	}
+	tor := ""
+	if name != templateMinimal {
+		tor = `  # Dial /onion3 relay and peer addresses through Tor (opt-in; much higher latency)
+  # tor:
+  #   socks_proxy: "127.0.0.1:9050"
+`
+	}
 
-# Relay server addresses.
+	var relay string
+	if templateUsesRelay(name) {
+		// Build YAML list of relay addresses
+		var addrLines string
+		for _, addr := range relayAddrs {
+			addrLines += fmt.Sprintf("    - %q\n", addr)
+		}
+		relay = fmt.Sprintf(`# Relay server addresses.
 # Own relay (option 1 in init) = full capability: data relay, file transfer, proxy.
 # Public seeds (option 2 in init) = discovery only, no data relay.
 # Deploy your own: https://shurli.io/docs/relay-setup/
 relay:
   addresses:
 %s  reservation_interval: "2m"
+`, addrLines)
+	} else {
+		relay = `# Direct connections only: no relay servers, reservations or circuits.
+# Peers must reach this node on the LAN or at a public address.
+relay:
+  enabled: false
+`
+	}
 
-discovery:
-  rendezvous: "shurli-default-network"
-%s  bootstrap_peers: []
-
-security:
-  authorized_keys_file: "authorized_keys"
-  enable_connection_gating: true
-
-protocols:
-  ping_pong:
-    enabled: true
-    id: "/pingpong/1.0.0"
-
+	var extras string
+	switch name {
+	case templateHomeNode, templateServer:
+		extras = `
 # Uncomment and configure services to expose (for shurli daemon):
 # services:
 #   ssh:
@@ -95,7 +167,45 @@ names: {}
 #     listen_address: "127.0.0.1:9091"
 #   audit:
 #     enabled: true
-`, generator, addrLines, networkLine)
+`
+	case templateRelayClient:
+		extras = `
+# This node reaches services on other peers and exposes none itself.
+# To expose one later: shurli service add <name> <local-address>
+
+# Map friendly names to peer IDs:
+names: {}
+#  home: "PEER_ID_HERE"
+`
+	case templateMinimal:
+		extras = `
+# Map friendly names to peer IDs:
+names: {}
+`
+	}
+
+	return fmt.Sprintf(`%s
+version: 1
+
+identity:
+  key_file: %q
+
+network:
+%s%s%s
+%s
+discovery:
+  rendezvous: "shurli-default-network"
+%s  bootstrap_peers: []
+
+security:
+  authorized_keys_file: %q
+  enable_connection_gating: true
+
+protocols:
+  ping_pong:
+    enabled: true
+    id: "/pingpong/1.0.0"
+%s`, header, config.ProfileKeyFileName(), listen, reachability, tor, relay, networkLine, config.ProfileAuthorizedKeysName(), extras), nil
 }
 
 // defaultReceiveDir returns a platform-appropriate default receive directory.
@@ -116,7 +226,7 @@ func defaultReceiveDir() string {
 // bandwidth_budget out of the box. Without this, every new node needs manual
 // plugin config creation before file transfer works.
 func createPluginDefaults(configDir string) {
-	pluginDir := filepath.Join(configDir, "plugins", "shurli.io", "official", "filetransfer")
+	pluginDir := filepath.Join(configDir, config.ProfileFileName("plugins"), "shurli.io", "official", "filetransfer")
 	if err := os.MkdirAll(pluginDir, 0700); err != nil {
 		slog.Debug("init: could not create plugin config dir", "error", err)
 		return
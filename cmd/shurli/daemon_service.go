@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Daemon OS-service integration: `shurli daemon install|uninstall|
+// service-start|service-stop`. The platform-specific halves live in
+// daemon_service_darwin.go (launchd agent), daemon_service_windows.go
+// (Service Control Manager) and daemon_service_other.go (no-op: Linux uses
+// systemd, installed by `shurli init` or `make install-service`).
+
+const (
+	// windowsServiceName is the SCM service name for the daemon.
+	windowsServiceName = "shurli-daemon"
+
+	// launchdLogPath matches deploy/com.shurli.daemon.plist so existing
+	// log instructions (kickLaunchd) stay valid.
+	launchdLogPath = "/tmp/shurli-daemon.log"
+)
+
+// errServiceUnsupported is returned by the platform layer when the OS has no
+// service integration in this command (Linux uses systemd instead).
+var errServiceUnsupported = errors.New("daemon service integration is not used on this platform")
+
+// daemonServiceOptions describes how the service manager launches the daemon.
+type daemonServiceOptions struct {
+	BinaryPath string // absolute path to the shurli binary
+	ConfigPath string // optional --config passed to `shurli daemon`
+}
+
+// daemonArgs returns the arguments the service manager passes to the binary.
+func (o daemonServiceOptions) daemonArgs() []string {
+	args := []string{"daemon"}
+	if o.ConfigPath != "" {
+		args = append(args, "--config", o.ConfigPath)
+	}
+	return args
+}
+
+func runDaemonInstall(args []string) {
+	if err := doDaemonInstall(args, os.Stdout); err != nil {
+		fatal("%v", err)
+	}
+}
+
+func doDaemonInstall(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("daemon install", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFlag := fs.String("config", "", "config file passed to the daemon")
+	noStart := fs.Bool("no-start", false, "register the service without starting it")
+	if err := fs.Parse(reorderFlags(fs, args)); err != nil {
+		return fmt.Errorf("usage: shurli daemon install [--config <path>] [--no-start]")
+	}
+
+	bin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate shurli binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(bin); err == nil {
+		bin = resolved
+	}
+	opts := daemonServiceOptions{BinaryPath: bin}
+	if *configFlag != "" {
+		abs, err := filepath.Abs(*configFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --config: %w", err)
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return fmt.Errorf("config file: %w", err)
+		}
+		opts.ConfigPath = abs
+	}
+
+	if err := installDaemonService(opts); err != nil {
+		if errors.Is(err, errServiceUnsupported) {
+			printServiceUnsupported(stdout)
+			return nil
+		}
+		return err
+	}
+	fmt.Fprintf(stdout, "Daemon service installed (%s).\n", daemonServiceDescription())
+
+	if *noStart {
+		fmt.Fprintln(stdout, "Start it with: shurli daemon service-start")
+		return nil
+	}
+	if err := startDaemonService(); err != nil {
+		return fmt.Errorf("service installed but failed to start: %w", err)
+	}
+	fmt.Fprintln(stdout, "Daemon service started.")
+	return nil
+}
+
+func runDaemonUninstall() {
+	if err := doDaemonServiceAction("uninstall", uninstallDaemonService, "Daemon service removed.", os.Stdout); err != nil {
+		fatal("%v", err)
+	}
+}
+
+func runDaemonServiceStart() {
+	if err := doDaemonServiceAction("service-start", startDaemonService, "Daemon service started.", os.Stdout); err != nil {
+		fatal("%v", err)
+	}
+}
+
+func runDaemonServiceStop() {
+	if err := doDaemonServiceAction("service-stop", stopDaemonService, "Daemon service stopped.", os.Stdout); err != nil {
+		fatal("%v", err)
+	}
+}
+
+// doDaemonServiceAction runs a platform service operation, treating an
+// unsupported platform as a graceful no-op.
+func doDaemonServiceAction(name string, action func() error, done string, stdout io.Writer) error {
+	if err := action(); err != nil {
+		if errors.Is(err, errServiceUnsupported) {
+			printServiceUnsupported(stdout)
+			return nil
+		}
+		return fmt.Errorf("daemon %s: %w", name, err)
+	}
+	fmt.Fprintln(stdout, done)
+	return nil
+}
+
+func printServiceUnsupported(stdout io.Writer) {
+	fmt.Fprintln(stdout, "Nothing to do: on Linux the daemon runs under systemd.")
+	fmt.Fprintln(stdout, "  Install: shurli init (offers to install the unit) or sudo make install-service")
+	fmt.Fprintln(stdout, "  Control: sudo systemctl start|stop|status shurli-daemon")
+}
+
+// generateLaunchdPlist returns a launchd agent definition for the daemon.
+// KeepAlive restarts the daemon only after an unclean exit, so a graceful
+// `shurli daemon stop` or service-stop leaves it stopped.
+func generateLaunchdPlist(label string, opts daemonServiceOptions, logPath string) string {
+	esc := func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	var args bytes.Buffer
+	for _, a := range append([]string{opts.BinaryPath}, opts.daemonArgs()...) {
+		fmt.Fprintf(&args, "        <string>%s</string>\n", esc(a))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>Label</key>
+    <string>%s</string>
+
+    <key>ProgramArguments</key>
+    <array>
+%s    </array>
+
+    <key>KeepAlive</key>
+    <dict>
+        <key>SuccessfulExit</key>
+        <false/>
+    </dict>
+
+    <key>RunAtLoad</key>
+    <true/>
+
+    <key>StandardOutPath</key>
+    <string>%s</string>
+
+    <key>StandardErrorPath</key>
+    <string>%s</string>
+
+    <key>EnvironmentVariables</key>
+    <dict>
+        <key>PATH</key>
+        <string>/usr/local/bin:/usr/bin:/usr/sbin:/bin</string>
+    </dict>
+</dict>
+</plist>
+`, esc(label), args.String(), esc(logPath), esc(logPath))
+}
@@ -0,0 +1,101 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// launchdPlistPath returns the per-user LaunchAgents path for the daemon.
+func launchdPlistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+func launchdDomain() string {
+	return "gui/" + strconv.Itoa(os.Getuid())
+}
+
+func launchctl(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("launchctl %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("launchctl %s: %w", args[0], err)
+	}
+	return nil
+}
+
+func installDaemonService(opts daemonServiceOptions) error {
+	path, err := launchdPlistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(generateLaunchdPlist(launchdLabel, opts, launchdLogPath)), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	// Unload any previous definition so service-start picks up the new
+	// one. bootout fails harmlessly when the agent isn't loaded. launchd
+	// loads the agent by itself at the next login (RunAtLoad).
+	_ = launchctl("bootout", launchdDomain()+"/"+launchdLabel)
+	return nil
+}
+
+func uninstallDaemonService() error {
+	path, err := launchdPlistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("no launchd agent installed at %s", path)
+	}
+	_ = launchctl("bootout", launchdDomain()+"/"+launchdLabel)
+	return os.Remove(path)
+}
+
+func startDaemonService() error {
+	path, err := launchdPlistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("no launchd agent installed (run: shurli daemon install)")
+	}
+	target := launchdDomain() + "/" + launchdLabel
+	if err := launchctl("enable", target); err != nil {
+		return err
+	}
+	// Loading starts the daemon (RunAtLoad). If the agent is already loaded
+	// bootstrap fails and kickstart starts it instead.
+	if launchctl("bootstrap", launchdDomain(), path) == nil {
+		return nil
+	}
+	return launchctl("kickstart", target)
+}
+
+func stopDaemonService() error {
+	// SIGTERM triggers the daemon's graceful shutdown; the clean exit keeps
+	// launchd from restarting it (KeepAlive SuccessfulExit=false).
+	return launchctl("kill", "SIGTERM", launchdDomain()+"/"+launchdLabel)
+}
+
+func daemonServiceDescription() string {
+	path, _ := launchdPlistPath()
+	return "launchd agent " + path + ", logs: " + launchdLogPath
+}
+
+// daemonServiceControl is only active under the Windows service manager;
+// elsewhere the daemon is stopped by signals.
+func daemonServiceControl() (<-chan struct{}, func()) { return nil, func() {} }
@@ -0,0 +1,17 @@
+//go:build !darwin && !windows
+
+package main
+
+// Linux (and other Unix) daemons run under systemd, installed by
+// `shurli init` / `make install-service`. These hooks make the
+// install/uninstall/start/stop subcommands graceful no-ops.
+
+func installDaemonService(daemonServiceOptions) error { return errServiceUnsupported }
+func uninstallDaemonService() error                   { return errServiceUnsupported }
+func startDaemonService() error                       { return errServiceUnsupported }
+func stopDaemonService() error                        { return errServiceUnsupported }
+func daemonServiceDescription() string                { return "" }
+
+// daemonServiceControl is only active under the Windows service manager;
+// elsewhere the daemon is stopped by signals.
+func daemonServiceControl() (<-chan struct{}, func()) { return nil, func() {} }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGenerateLaunchdPlist(t *testing.T) {
+	opts := daemonServiceOptions{
+		BinaryPath: "/Users/me/bin/shurli",
+		ConfigPath: "/Users/me/My <Configs>/shurli.yaml",
+	}
+	plist := generateLaunchdPlist("com.shurli.daemon", opts, "/tmp/shurli-daemon.log")
+
+	// Must be well-formed XML even with special characters in paths.
+	dec := xml.NewDecoder(strings.NewReader(plist))
+	dec.Strict = false // tolerate the DOCTYPE
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatalf("plist is not well-formed XML: %v", err)
+		}
+	}
+
+	for _, want := range []string{
+		"<string>com.shurli.daemon</string>",
+		"<string>/Users/me/bin/shurli</string>",
+		"<string>daemon</string>",
+		"<string>--config</string>",
+		"<string>/Users/me/My &lt;Configs&gt;/shurli.yaml</string>",
+		"<key>SuccessfulExit</key>",
+		"<string>/tmp/shurli-daemon.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q", want)
+		}
+	}
+}
+
+func TestDaemonServiceOptions_DaemonArgs(t *testing.T) {
+	if got := (daemonServiceOptions{}).daemonArgs(); len(got) != 1 || got[0] != "daemon" {
+		t.Errorf("daemonArgs() = %v, want [daemon]", got)
+	}
+	got := daemonServiceOptions{ConfigPath: "/etc/shurli/config.yaml"}.daemonArgs()
+	if strings.Join(got, " ") != "daemon --config /etc/shurli/config.yaml" {
+		t.Errorf("daemonArgs() = %v", got)
+	}
+}
+
+func TestDoDaemonInstall_NoOpOnLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd no-op path is Linux-only")
+	}
+	var out bytes.Buffer
+	if err := doDaemonInstall(nil, &out); err != nil {
+		t.Fatalf("doDaemonInstall: %v", err)
+	}
+	if !strings.Contains(out.String(), "systemd") {
+		t.Errorf("expected systemd guidance, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := doDaemonServiceAction("service-stop", stopDaemonService, "stopped", &out); err != nil {
+		t.Fatalf("doDaemonServiceAction: %v", err)
+	}
+	if strings.Contains(out.String(), "stopped") {
+		t.Error("no-op action should not report success")
+	}
+}
+
+func TestDoDaemonInstall_MissingConfig(t *testing.T) {
+	var out bytes.Buffer
+	err := doDaemonInstall([]string{"--config", t.TempDir() + "/missing.yaml"}, &out)
+	if err == nil {
+		t.Fatal("expected error for missing config file")
+	}
+}
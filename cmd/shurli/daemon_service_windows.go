@@ -0,0 +1,163 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// windowsServiceStopTimeout bounds how long the control handler waits for
+// the daemon's graceful shutdown before reporting the service stopped.
+const windowsServiceStopTimeout = 60 * time.Second
+
+func openDaemonService() (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to service manager (run as Administrator): %w", err)
+	}
+	s, err := m.OpenService(windowsServiceName)
+	if err != nil {
+		m.Disconnect()
+		return nil, nil, fmt.Errorf("service %s not installed (run: shurli daemon install): %w", windowsServiceName, err)
+	}
+	return m, s, nil
+}
+
+func installDaemonService(opts daemonServiceOptions) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager (run as Administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(windowsServiceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already installed (run: shurli daemon uninstall first)", windowsServiceName)
+	}
+
+	s, err := m.CreateService(windowsServiceName, opts.BinaryPath, mgr.Config{
+		DisplayName: "Shurli daemon",
+		Description: "Shurli daemon - P2P network service",
+		StartType:   mgr.StartAutomatic,
+	}, opts.daemonArgs()...)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+
+	// Mirror the systemd unit: restart 5s after a crash.
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+	}, uint32((24 * time.Hour).Seconds())); err != nil {
+		slog.Warn("windows service: failed to set recovery actions", "error", err)
+	}
+	return nil
+}
+
+func uninstallDaemonService() error {
+	m, s, err := openDaemonService()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+
+	// Stop first; an already-stopped service rejects the control, which is fine.
+	_, _ = s.Control(svc.Stop)
+	return s.Delete()
+}
+
+func startDaemonService() error {
+	m, s, err := openDaemonService()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+	return s.Start()
+}
+
+func stopDaemonService() error {
+	m, s, err := openDaemonService()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer s.Close()
+	_, err = s.Control(svc.Stop)
+	return err
+}
+
+func daemonServiceDescription() string {
+	return "Windows service " + windowsServiceName
+}
+
+// daemonServiceControl connects the daemon to the Service Control Manager
+// when launched as a Windows service. The returned channel closes when the
+// SCM asks the service to stop; call stopped once shutdown has finished so
+// the SCM sees the service stop only after the daemon has cleaned up.
+// Outside the SCM it returns a nil channel and a no-op.
+func daemonServiceControl() (<-chan struct{}, func()) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return nil, func() {}
+	}
+
+	h := &daemonServiceHandler{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		if err := svc.Run(windowsServiceName, h); err != nil {
+			slog.Error("windows service: control handler failed", "error", err)
+		}
+	}()
+
+	stopped := func() {
+		close(h.done)
+		select {
+		case <-exited:
+		case <-time.After(5 * time.Second):
+		}
+	}
+	return h.stop, stopped
+}
+
+// daemonServiceHandler implements svc.Handler for the daemon.
+type daemonServiceHandler struct {
+	stop chan struct{} // closed on SCM stop/shutdown
+	done chan struct{} // closed by the daemon after graceful shutdown
+}
+
+func (h *daemonServiceHandler) Execute(_ []string, reqs <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case <-h.done:
+			// Daemon exited on its own (API shutdown or fatal error).
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case req := <-reqs:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: uint32(windowsServiceStopTimeout.Milliseconds())}
+				close(h.stop)
+				select {
+				case <-h.done:
+				case <-time.After(windowsServiceStopTimeout):
+				}
+				return false, 0
+			}
+		}
+	}
+}
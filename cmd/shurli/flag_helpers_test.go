@@ -69,3 +69,30 @@ func TestReorderArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractProfileFlag(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantProfile string
+		wantArgs    []string
+	}{
+		{[]string{"daemon"}, "", []string{"daemon"}},
+		{[]string{"--profile", "work", "daemon", "status"}, "work", []string{"daemon", "status"}},
+		{[]string{"--profile=work", "daemon"}, "work", []string{"daemon"}},
+		// Only a leading --profile is global; later ones belong to the command.
+		{[]string{"daemon", "--profile", "work"}, "", []string{"daemon", "--profile", "work"}},
+	}
+	for _, tt := range tests {
+		profile, args, err := extractProfileFlag(tt.args)
+		if err != nil {
+			t.Fatalf("extractProfileFlag(%v): %v", tt.args, err)
+		}
+		if profile != tt.wantProfile || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("extractProfileFlag(%v) = %q, %v; want %q, %v", tt.args, profile, args, tt.wantProfile, tt.wantArgs)
+		}
+	}
+
+	if _, _, err := extractProfileFlag([]string{"--profile"}); err == nil {
+		t.Error("expected error for --profile without a name")
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+// hookMaxRunning caps how many hook commands run at once. Events that
+// arrive while every slot is busy are dropped, so a flapping peer cannot
+// fork processes without bound.
+const hookMaxRunning = 8
+
+// hookOutputLimit caps how much of a hook's output is logged.
+const hookOutputLimit = 4096
+
+// hookEvent is what a hook command is told about the event that ran it.
+type hookEvent struct {
+	Event    string // config.HookPeerConnected etc.
+	PeerID   peer.ID
+	PeerName string // friendly name from config; empty if none
+	Service  string // service_accessed only
+}
+
+// hookRunner matches daemon events against the configured hooks and runs
+// the commands of those that match.
+type hookRunner struct {
+	ctx      context.Context
+	hooks    []config.HookConfig
+	resolve  func(name string) (peer.ID, error) // for peer filters given as names; nil = IDs only
+	peerName func(peer.ID) string               // nil = no SHURLI_PEER_NAME
+	sem      chan struct{}
+}
+
+func newHookRunner(ctx context.Context, hooks []config.HookConfig) *hookRunner {
+	return &hookRunner{ctx: ctx, hooks: hooks, sem: make(chan struct{}, hookMaxRunning)}
+}
+
+// has reports whether any hook listens for event.
+func (r *hookRunner) has(event string) bool {
+	for _, h := range r.hooks {
+		if h.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether hook h applies to ev. A peer filter may be a
+// peer ID or a name, resolved on every event so config names stay current.
+func (r *hookRunner) matches(h config.HookConfig, ev hookEvent) bool {
+	if h.Event != ev.Event {
+		return false
+	}
+	if h.Service != "" && h.Service != ev.Service {
+		return false
+	}
+	if h.Peer == "" || h.Peer == ev.PeerID.String() {
+		return true
+	}
+	if r.resolve != nil {
+		if id, err := r.resolve(h.Peer); err == nil && id == ev.PeerID {
+			return true
+		}
+	}
+	return false
+}
+
+// fire starts the command of every hook matching ev. It never blocks.
+func (r *hookRunner) fire(ev hookEvent) {
+	if ev.PeerName == "" && r.peerName != nil {
+		ev.PeerName = r.peerName(ev.PeerID)
+	}
+	for _, h := range r.hooks {
+		if !r.matches(h, ev) {
+			continue
+		}
+		select {
+		case r.sem <- struct{}{}:
+		default:
+			slog.Warn("hook: too many commands running, event dropped",
+				"event", ev.Event, "command", h.Command, "peer", shortPeerID(ev.PeerID))
+			continue
+		}
+		go func() {
+			defer func() { <-r.sem }()
+			out, err := runEventCommand(r.ctx, h.Command, h.TimeoutOrDefault(), ev.env()...)
+			output := truncateHookOutput(out)
+			if err != nil {
+				slog.Warn("hook: command failed", "event", ev.Event, "command", h.Command,
+					"peer", shortPeerID(ev.PeerID), "error", err, "output", output)
+				return
+			}
+			slog.Info("hook: command ran", "event", ev.Event, "command", h.Command,
+				"peer", shortPeerID(ev.PeerID), "output", output)
+		}()
+	}
+}
+
+// env describes the event to a hook command in SHURLI_EVENT,
+// SHURLI_PEER_ID, SHURLI_PEER_NAME and SHURLI_SERVICE.
+func (ev hookEvent) env() []string {
+	return []string{
+		"SHURLI_EVENT=" + ev.Event,
+		"SHURLI_PEER_ID=" + ev.PeerID.String(),
+		"SHURLI_PEER_NAME=" + ev.PeerName,
+		"SHURLI_SERVICE=" + ev.Service,
+	}
+}
+
+func truncateHookOutput(out []byte) string {
+	s := strings.TrimSpace(string(out))
+	if len(s) > hookOutputLimit {
+		s = s[:hookOutputLimit] + "... (truncated)"
+	}
+	return s
+}
+
+// watchPeerHooks feeds peer connect and disconnect events from h into r
+// until ctx is cancelled. It subscribes before returning, so no event
+// after the call is missed.
+func watchPeerHooks(ctx context.Context, h host.Host, r *hookRunner) error {
+	sub, err := h.EventBus().Subscribe(new(event.EvtPeerConnectednessChanged))
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer sub.Close()
+		// Peers a connect hook has fired for, so a relay upgrading to a
+		// direct connection (Limited -> Connected) does not fire twice.
+		connected := make(map[peer.ID]bool)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				e := evt.(event.EvtPeerConnectednessChanged)
+				switch e.Connectedness {
+				case network.Connected, network.Limited:
+					// A relay-only peer is Limited, not Connected. It is
+					// reachable, and it fires peer_disconnected when it
+					// goes, so it must fire peer_connected too.
+					if connected[e.Peer] {
+						continue
+					}
+					connected[e.Peer] = true
+					r.fire(hookEvent{Event: config.HookPeerConnected, PeerID: e.Peer})
+				case network.NotConnected:
+					delete(connected, e.Peer)
+					r.fire(hookEvent{Event: config.HookPeerDisconnected, PeerID: e.Peer})
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// StartHooks wires the hooks from config into peer connectivity events and
+// service access. Must run before the service registry is sealed. Hooks
+// are read once at startup; changing them needs a daemon restart.
+func (rt *serveRuntime) StartHooks() {
+	if len(rt.config.Hooks) == 0 {
+		return
+	}
+	r := newHookRunner(rt.ctx, rt.config.Hooks)
+	pnet := rt.network
+	r.resolve = pnet.ResolveName
+	r.peerName = func(id peer.ID) string {
+		for name, pid := range pnet.ListNames() {
+			if pid == id {
+				return name
+			}
+		}
+		return ""
+	}
+
+	if r.has(config.HookPeerConnected) || r.has(config.HookPeerDisconnected) {
+		if err := watchPeerHooks(rt.ctx, pnet.Host(), r); err != nil {
+			slog.Warn("hooks: failed to subscribe to peer events", "error", err)
+		}
+	}
+	if r.has(config.HookServiceAccessed) {
+		pnet.ServiceRegistry().SetServiceAccessHook(func(service string, p peer.ID) {
+			r.fire(hookEvent{Event: config.HookServiceAccessed, PeerID: p, Service: service})
+		})
+	}
+	slog.Info("hooks: enabled", "count", len(rt.config.Hooks))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+// waitForFile polls until path exists with content or the deadline passes.
+func waitForFile(t *testing.T, path string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+			return strings.TrimSpace(string(data))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("hook never wrote %s", path)
+	return ""
+}
+
+func TestHookRunsOnPeerConnect(t *testing.T) {
+	script := fakeEditor(t, `echo "$SHURLI_EVENT $SHURLI_PEER_ID $SHURLI_PEER_NAME" > "$1"`)
+	out := script + ".out"
+
+	server, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	client, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newHookRunner(ctx, []config.HookConfig{
+		{Event: config.HookPeerConnected, Peer: "laptop", Command: script + " " + out},
+	})
+	r.resolve = func(name string) (peer.ID, error) { return client.ID(), nil }
+	r.peerName = func(peer.ID) string { return "laptop" }
+	if err := watchPeerHooks(ctx, server, r); err != nil {
+		t.Fatalf("watchPeerHooks: %v", err)
+	}
+
+	if err := client.Connect(ctx, peer.AddrInfo{ID: server.ID(), Addrs: server.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	want := config.HookPeerConnected + " " + client.ID().String() + " laptop"
+	if got := waitForFile(t, out); got != want {
+		t.Errorf("hook saw %q, want %q", got, want)
+	}
+}
+
+func TestHookRunnerMatches(t *testing.T) {
+	home, _ := peer.Decode(generateTestPeerID(t))
+	other, _ := peer.Decode(generateTestPeerID(t))
+	r := newHookRunner(context.Background(), nil)
+	r.resolve = func(name string) (peer.ID, error) {
+		if name == "home" {
+			return home, nil
+		}
+		return "", os.ErrNotExist
+	}
+
+	tests := []struct {
+		name string
+		hook config.HookConfig
+		ev   hookEvent
+		want bool
+	}{
+		{"any peer", config.HookConfig{Event: config.HookPeerConnected}, hookEvent{Event: config.HookPeerConnected, PeerID: other}, true},
+		{"wrong event", config.HookConfig{Event: config.HookPeerDisconnected}, hookEvent{Event: config.HookPeerConnected, PeerID: home}, false},
+		{"peer by name", config.HookConfig{Event: config.HookPeerConnected, Peer: "home"}, hookEvent{Event: config.HookPeerConnected, PeerID: home}, true},
+		{"peer by ID", config.HookConfig{Event: config.HookPeerConnected, Peer: home.String()}, hookEvent{Event: config.HookPeerConnected, PeerID: home}, true},
+		{"other peer", config.HookConfig{Event: config.HookPeerConnected, Peer: "home"}, hookEvent{Event: config.HookPeerConnected, PeerID: other}, false},
+		{"unknown name", config.HookConfig{Event: config.HookPeerConnected, Peer: "nas"}, hookEvent{Event: config.HookPeerConnected, PeerID: home}, false},
+		{"service match", config.HookConfig{Event: config.HookServiceAccessed, Service: "ssh"}, hookEvent{Event: config.HookServiceAccessed, PeerID: home, Service: "ssh"}, true},
+		{"service mismatch", config.HookConfig{Event: config.HookServiceAccessed, Service: "ssh"}, hookEvent{Event: config.HookServiceAccessed, PeerID: home, Service: "web"}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.matches(tc.hook, tc.ev); got != tc.want {
+				t.Errorf("matches = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHookCommand(t *testing.T) {
+	id, _ := peer.Decode(generateTestPeerID(t))
+	script := fakeEditor(t, `echo "$SHURLI_EVENT $SHURLI_SERVICE"; echo oops >&2; exit 2`)
+
+	ev := hookEvent{Event: config.HookServiceAccessed, PeerID: id, Service: "ssh"}
+	out, err := runEventCommand(context.Background(), script, config.HookConfig{}.TimeoutOrDefault(), ev.env()...)
+	if err == nil {
+		t.Fatal("expected error from a failing command")
+	}
+	if got := truncateHookOutput(out); got != "service_accessed ssh\noops" {
+		t.Errorf("output = %q", got)
+	}
+
+	slow := fakeEditor(t, "sleep 5")
+	start := time.Now()
+	if _, err := runEventCommand(context.Background(), slow, 100*time.Millisecond, hookEvent{PeerID: id}.env()...); err == nil {
+		t.Error("expected timeout error")
+	}
+	if d := time.Since(start); d > 3*time.Second {
+		t.Errorf("timeout not enforced, ran %s", d)
+	}
+}
+
+func TestTruncateHookOutput(t *testing.T) {
+	long := strings.Repeat("x", hookOutputLimit+10)
+	if got := truncateHookOutput([]byte(long)); !strings.HasSuffix(got, "... (truncated)") || len(got) != hookOutputLimit+len("... (truncated)") {
+		t.Errorf("long output not truncated: %d bytes", len(got))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/notify"
+)
+
+// isolationCommandTimeout bounds how long an on_isolated command may run.
+const isolationCommandTimeout = 30 * time.Second
+
+// isolationWatch tracks how long the node has had no connected peers. The
+// clock starts when the watch is created, so a node that is still finding
+// its first peers after startup counts as alone only once after has passed.
+type isolationWatch struct {
+	after    time.Duration
+	aloneAt  time.Time // when the peer count last dropped to zero; zero while connected
+	isolated bool      // the isolated alert has fired and recovery has not
+}
+
+func newIsolationWatch(after time.Duration, now time.Time) *isolationWatch {
+	return &isolationWatch{after: after, aloneAt: now}
+}
+
+// observe records the current peer count and reports whether the node just
+// became isolated or just recovered. since is when it was last connected.
+func (w *isolationWatch) observe(peers int, now time.Time) (changed, isolated bool, since time.Time) {
+	if peers > 0 {
+		w.aloneAt = time.Time{}
+		if w.isolated {
+			w.isolated = false
+			return true, false, time.Time{}
+		}
+		return false, false, time.Time{}
+	}
+	if w.aloneAt.IsZero() {
+		w.aloneAt = now
+	}
+	if !w.isolated && now.Sub(w.aloneAt) >= w.after {
+		w.isolated = true
+		return true, true, w.aloneAt
+	}
+	return false, w.isolated, w.aloneAt
+}
+
+// isolationCheckInterval polls often enough to alert within a tenth of
+// after, clamped to 1s-30s.
+func isolationCheckInterval(after time.Duration) time.Duration {
+	return min(max(after/10, time.Second), 30*time.Second)
+}
+
+// StartIsolationAlert runs the telemetry.on_isolated hook: it fires when
+// the daemon has had no connected peers for the configured duration, and
+// again when a peer connects.
+func (rt *serveRuntime) StartIsolationAlert() {
+	hook := rt.config.Telemetry.OnIsolated
+	if !hook.Enabled() {
+		return
+	}
+	h := rt.network.Host()
+	after := hook.AfterOrDefault()
+	var webhook *notify.WebhookSink
+	if hook.URL != "" {
+		webhook = notify.NewWebhookSink(notify.WebhookConfig{URL: hook.URL, Headers: hook.Headers}, slog.Default())
+	}
+
+	go func() {
+		w := newIsolationWatch(after, time.Now())
+		ticker := time.NewTicker(isolationCheckInterval(after))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-rt.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			changed, isolated, since := w.observe(len(h.Network().Peers()), time.Now())
+			if !changed {
+				continue
+			}
+			event := isolationEvent(h.ID().String(), isolated, since)
+			if isolated {
+				slog.Warn("isolation: no connected peers", "since", since.Format(time.RFC3339))
+			} else {
+				slog.Info("isolation: connectivity restored")
+			}
+			go fireIsolationHook(rt.ctx, hook.Command, webhook, event)
+		}
+	}()
+}
+
+// isolationEvent builds the node_isolated / node_recovered event.
+func isolationEvent(self string, isolated bool, since time.Time) notify.Event {
+	if !isolated {
+		return notify.NewEvent(notify.EventNodeRecovered, notify.SeverityInfo, self, "", "node has connected peers again")
+	}
+	event := notify.NewEvent(notify.EventNodeIsolated, notify.SeverityWarn, self, "",
+		fmt.Sprintf("node has had no connected peers since %s", since.Format(time.RFC3339)))
+	return event.WithMetadata("since", since.Format(time.RFC3339))
+}
+
+// fireIsolationHook delivers event to the webhook and runs the command.
+// Failures are logged; the alert is best effort.
+func fireIsolationHook(ctx context.Context, command string, webhook *notify.WebhookSink, event notify.Event) {
+	if webhook != nil {
+		if err := webhook.Notify(event); err != nil {
+			slog.Warn("isolation: webhook failed", "error", err)
+		}
+	}
+	if command != "" {
+		if err := runIsolationCommand(ctx, command, event); err != nil {
+			slog.Warn("isolation: command failed", "command", command, "error", err)
+		}
+	}
+}
+
+// runIsolationCommand runs command (split on whitespace, no shell) with
+// the event described in SHURLI_EVENT ("isolated" or "recovered"),
+// SHURLI_PEER_ID and, for isolation, SHURLI_ISOLATED_SINCE.
+func runIsolationCommand(ctx context.Context, command string, event notify.Event) error {
+	state := "recovered"
+	if event.Type == notify.EventNodeIsolated {
+		state = "isolated"
+	}
+	env := []string{
+		"SHURLI_EVENT=" + state,
+		"SHURLI_PEER_ID=" + event.PeerID,
+	}
+	if since := event.Metadata["since"]; since != "" {
+		env = append(env, "SHURLI_ISOLATED_SINCE="+since)
+	}
+	if out, err := runEventCommand(ctx, command, isolationCommandTimeout, env...); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// eventCommandWaitDelay is how long a timed-out event command's output is
+// still read after the command is killed. Children it started may hold the
+// pipe open.
+const eventCommandWaitDelay = time.Second
+
+// runEventCommand runs an operator-configured command (split on whitespace,
+// no shell) for a daemon event, with env added to the daemon's environment,
+// and returns its combined output. The command is killed after timeout.
+// Shared by the isolation alert and the peer/service hooks.
+func runEventCommand(ctx context.Context, command string, timeout time.Duration, env ...string) ([]byte, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.WaitDelay = eventCommandWaitDelay
+	return cmd.CombinedOutput()
+}
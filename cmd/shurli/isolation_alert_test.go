@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/notify"
+)
+
+func TestIsolationWatch(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	w := newIsolationWatch(5*time.Minute, start)
+
+	// Startup with no peers yet is not isolation until after has passed.
+	if changed, _, _ := w.observe(0, start.Add(time.Minute)); changed {
+		t.Fatal("fired during startup grace")
+	}
+	// Brief outages shorter than after never fire.
+	w.observe(3, start.Add(2*time.Minute))
+	if changed, _, _ := w.observe(0, start.Add(3*time.Minute)); changed {
+		t.Fatal("fired as soon as peers dropped")
+	}
+	if changed, _, _ := w.observe(0, start.Add(7*time.Minute)); changed {
+		t.Fatal("fired before after elapsed since the last peer left")
+	}
+
+	changed, isolated, since := w.observe(0, start.Add(8*time.Minute))
+	if !changed || !isolated {
+		t.Fatalf("observe = changed %v isolated %v, want isolation", changed, isolated)
+	}
+	if !since.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("since = %v, want when the last peer left", since)
+	}
+	if changed, _, _ := w.observe(0, start.Add(20*time.Minute)); changed {
+		t.Error("isolation fired twice")
+	}
+
+	changed, isolated, _ = w.observe(1, start.Add(21*time.Minute))
+	if !changed || isolated {
+		t.Fatalf("observe = changed %v isolated %v, want recovery", changed, isolated)
+	}
+	if changed, _, _ := w.observe(2, start.Add(22*time.Minute)); changed {
+		t.Error("recovery fired twice")
+	}
+}
+
+func TestIsolationWatch_NoRecoveryWithoutIsolation(t *testing.T) {
+	start := time.Now()
+	w := newIsolationWatch(time.Minute, start)
+	if changed, _, _ := w.observe(1, start.Add(10*time.Second)); changed {
+		t.Error("recovery fired without a prior isolation alert")
+	}
+}
+
+func TestIsolationCheckInterval(t *testing.T) {
+	for _, tc := range []struct{ after, want time.Duration }{
+		{30 * time.Second, 3 * time.Second},
+		{5 * time.Second, time.Second},
+		{time.Hour, 30 * time.Second},
+	} {
+		if got := isolationCheckInterval(tc.after); got != tc.want {
+			t.Errorf("isolationCheckInterval(%v) = %v, want %v", tc.after, got, tc.want)
+		}
+	}
+}
+
+func TestRunIsolationCommand(t *testing.T) {
+	script := fakeEditor(t, `echo "$SHURLI_EVENT $SHURLI_ISOLATED_SINCE $1" > "$2"`)
+	out := script + ".out"
+	since := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	event := isolationEvent("12D3KooWSelf", true, since)
+	if err := runIsolationCommand(context.Background(), script+" arg "+out, event); err != nil {
+		t.Fatalf("runIsolationCommand: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "isolated 2026-01-01T12:00:00Z arg"; strings.TrimSpace(string(got)) != want {
+		t.Errorf("command saw %q, want %q", strings.TrimSpace(string(got)), want)
+	}
+
+	event = isolationEvent("12D3KooWSelf", false, time.Time{})
+	if event.Type != notify.EventNodeRecovered {
+		t.Errorf("event type = %s, want %s", event.Type, notify.EventNodeRecovered)
+	}
+	if err := runIsolationCommand(context.Background(), script+" arg "+out, event); err != nil {
+		t.Fatalf("runIsolationCommand: %v", err)
+	}
+	if got, _ := os.ReadFile(out); strings.TrimSpace(string(got)) != "recovered  arg" {
+		t.Errorf("command saw %q, want recovered", strings.TrimSpace(string(got)))
+	}
+
+	if err := runIsolationCommand(context.Background(), fakeEditor(t, "exit 3"), event); err == nil {
+		t.Error("expected error from a failing command")
+	}
+}
@@ -27,6 +27,17 @@ func main() {
 		Level: slog.LevelInfo,
 	})))
 
+	// A leading --profile selects a named profile before any config lookup.
+	profile, args, err := extractProfileFlag(os.Args[1:])
+	if err == nil {
+		err = config.SetProfile(profile)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		osExit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+
 	// Apply cli.color config setting (best-effort, no error on missing config).
 	applyColorConfig()
 
@@ -49,6 +60,8 @@ func main() {
 		runPing(os.Args[2:])
 	case "traceroute":
 		runTraceroute(os.Args[2:])
+	case "bwtest":
+		runBwtest(os.Args[2:])
 	case "resolve":
 		runResolve(os.Args[2:])
 	case "whoami":
@@ -91,8 +104,14 @@ func main() {
 		runPlugin(os.Args[2:])
 	case "reconnect":
 		runReconnect(os.Args[2:])
+	case "remote":
+		runRemote(os.Args[2:])
+	case "msg":
+		runMsg(os.Args[2:])
 	case "notify":
 		runNotify(os.Args[2:])
+	case "history":
+		runHistory(os.Args[2:])
 	case "doctor":
 		runDoctor(os.Args[2:])
 	case "completion":
@@ -120,6 +139,34 @@ func main() {
 	}
 }
 
+// extractProfileFlag strips a global "--profile <name>" (or
+// "--profile=<name>") placed before the command, e.g.
+// "shurli --profile work daemon". The name is validated by config.SetProfile.
+func extractProfileFlag(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", args, nil
+	}
+	if name, ok := strings.CutPrefix(args[0], "--profile="); ok {
+		return name, args[1:], nil
+	}
+	if args[0] != "--profile" {
+		return "", args, nil
+	}
+	if len(args) < 2 {
+		return "", nil, fmt.Errorf("--profile requires a name")
+	}
+	return args[1], args[2:], nil
+}
+
+// profileArgs returns the global flags that re-select the active profile,
+// for commands that spawn another shurli process.
+func profileArgs() []string {
+	if p := config.Profile(); p != "" {
+		return []string{"--profile", p}
+	}
+	return nil
+}
+
 // applyColorConfig checks the config file for cli.color setting.
 // Best-effort: silently skips if no config is found (e.g., before init).
 // Tries node config first, then relay config.
@@ -149,34 +196,49 @@ func printVersion() {
 }
 
 func printUsage() {
-	fmt.Println("Usage: shurli <command> [options]")
+	fmt.Println("Usage: shurli [--profile <name>] <command> [options]")
 	fmt.Println()
 	fmt.Println("Daemon:")
 	fmt.Println("  daemon                                Start daemon (P2P host + control API)")
 	fmt.Println("  daemon status [--json]                Query running daemon")
-	fmt.Println("  daemon stop                           Graceful shutdown")
+	fmt.Println("  daemon stop [--drain [--timeout 30s]] Graceful shutdown")
 	fmt.Println("  daemon ping <target> [-c N] [--json]  Ping via daemon")
 	fmt.Println("  daemon services [--json]              List services via daemon")
-	fmt.Println("  daemon peers [--all] [--json]         List connected peers via daemon")
+	fmt.Println("  daemon peers [--all] [--peer p]       List connected peers via daemon")
+	fmt.Println("  daemon peers --watched|--direct|--relayed [--sort latency|lastseen|peerid]")
 	fmt.Println("  daemon paths [--json]                 Show connection paths")
-	fmt.Println("  daemon connect --peer <p> --service <s> --listen <addr>")
+	fmt.Println("  daemon inbound [--json]               Show peers using local services")
+	fmt.Println("  daemon holepunch [--json]             Hole punch attempts and outcomes per peer")
+	fmt.Println("  daemon connect --peer <p> --service <s> --listen <addr> [--proto udp]")
+	fmt.Println("  daemon connect --peer <p> --service <s> --stdio")
 	fmt.Println("  daemon disconnect <id>                Tear down proxy")
+	fmt.Println("  daemon events [--since 5m] [--peer p] Follow daemon events")
+	fmt.Println("  daemon messages [--clear] [--json]    Show received peer messages")
+	fmt.Println("  daemon install|uninstall              Register as launchd agent / Windows service")
+	fmt.Println("  daemon service-start|service-stop     Control the installed service")
 	fmt.Println()
 	fmt.Println("Network tools:")
 	fmt.Println("  ping <target> [-c N] [--json]         P2P ping")
+	fmt.Println("  ping --targets <a,b,...> [-c N]       Ping several peers, summary table")
 	fmt.Println("  traceroute <target> [--json]           P2P traceroute")
-	fmt.Println("  resolve <name> [--json]                Resolve name to peer ID")
+	fmt.Println("  traceroute <target> --watch            Report direct/relayed path flaps")
+	fmt.Println("  bwtest <target> [--bytes 100MB]        Measure throughput (--direction up|down|both)")
+	fmt.Println("  resolve <name> [--json] [--refresh]    Resolve name to peer ID")
 	fmt.Println("  proxy add <name> <peer> <svc> <port>   Create persistent proxy")
 	fmt.Println("  proxy list [--json]                    List all proxies")
 	fmt.Println("  proxy remove <name>                    Remove a proxy")
 	fmt.Println("  proxy enable/disable <name>            Toggle a proxy")
 	fmt.Println("  proxy <target> <service> <local-port>  Ephemeral foreground proxy")
 	fmt.Println("  reconnect <peer> [--json]              Clear backoffs and force redial")
+	fmt.Println("  msg <target> \"text\" [--json]           Send a short text message to a peer")
+	fmt.Println("  remote --peer <node> <cmd> [--json]    Run status|peers|... on another node's daemon")
 	fmt.Println()
 	fmt.Println("Identity & access:")
-	fmt.Println("  whoami                                 Show your peer ID")
-	fmt.Println("  auth add <peer-id> [--comment \"...\"]   Authorize a peer")
-	fmt.Println("  auth list                              List authorized peers")
+	fmt.Println("  whoami [--addresses] [--json]          Show peer ID, connect hint (and addresses)")
+	fmt.Println("  whoami --watch-reachability            Report when the node becomes reachable")
+	fmt.Println("  auth add <peer-id> [--comment \"...\"]   Authorize a peer (--ttl 24h for temporary access)")
+	fmt.Println("  auth list [--format f]                 List authorized peers")
+	fmt.Println("  auth list --group <id>|--ungrouped [--comment-contains <text>]")
 	fmt.Println("  auth remove <peer-id>                  Revoke a peer's access")
 	fmt.Println("  auth validate [file]                   Validate authorized_keys format")
 	fmt.Println("  auth set-attr <peer> <key> <value>     Set peer attribute (e.g. bandwidth_budget 1GB)")
@@ -189,37 +251,45 @@ func printUsage() {
 	fmt.Println("  auth audit [--verify]                  Audit grant log integrity")
 	fmt.Println()
 	fmt.Println("Configuration:")
-	fmt.Println("  init                                   Set up shurli configuration")
+	fmt.Println("  init [--template name]                 Set up shurli configuration")
+	fmt.Println("  init --from <bundle|url> [--as name]   Set up from a bootstrap bundle (relays, network, invite)")
 	fmt.Println("  config validate [--config path]        Validate config")
 	fmt.Println("  config show [--config path]            Show resolved config")
 	fmt.Println("  config set <key> <value>               Set a config value")
 	fmt.Println("  config reload [--json]                 Reload config into running daemon")
 	fmt.Println("  config rollback [--config path]        Restore last-known-good config")
-	fmt.Println("  config apply <new> [--confirm-timeout] Apply with auto-revert")
+	fmt.Println("  config diff <new> [--config path]      Show what applying a config would change")
+	fmt.Println("  config apply <new> [--confirm-timeout] Apply with auto-revert (--dry-run: preview only)")
 	fmt.Println("  config confirm [--config path]         Confirm applied config")
+	fmt.Println("  config edit [--config path]            Edit in $EDITOR, save only if valid")
+	fmt.Println("  config schema                          Print JSON Schema for editor validation")
 	fmt.Println()
 	fmt.Println("Relay client:")
-	fmt.Println("  relay add <address> [--peer-id <ID>]   Add a relay server")
-	fmt.Println("  relay list                             List relay servers")
+	fmt.Println("  relay add <address> [--peer-id <ID>] [--verify]")
+	fmt.Println("                                         Add a relay server")
+	fmt.Println("  relay list [--format f]                List relay servers")
 	fmt.Println("  relay remove <multiaddr>               Remove a relay server")
 	fmt.Println("  relay seeds <add|remove>               Add/remove public seed nodes")
 	fmt.Println()
 	fmt.Println("Relay server:")
 	fmt.Println("  relay setup                            Initialize relay server config")
 	fmt.Println("  relay serve [--config path]            Start the relay server")
-	fmt.Println("  relay info                             Show peer ID and multiaddrs")
+	fmt.Println("  relay info [--json]                    Show peer ID, region and multiaddrs")
 	fmt.Println("  relay authorize <peer-id> [comment]    Allow a peer")
 	fmt.Println("  relay deauthorize <peer-id>            Remove a peer's access")
 	fmt.Println("  relay set-attr <peer> <key> <value>    Set peer attribute (e.g. role admin)")
 	fmt.Println("  relay grant <peer-id> --duration 1h    Grant time-limited data relay access")
 	fmt.Println("  relay grants                           List active data relay grants")
+	fmt.Println("  relay stats [--json] [--days]          Show data relayed per peer and group")
 	fmt.Println("  relay revoke <peer-id>                 Revoke data relay access")
 	fmt.Println("  relay extend <peer-id> --duration 2h   Extend data relay grant")
 	fmt.Println("  relay list-peers                       List authorized peers")
 	fmt.Println("  relay verify <peer-id>                 Verify peer identity (SAS)")
+	fmt.Println("  relay selftest [--size 4MB]            Test a circuit through this relay")
 	fmt.Println("  relay show                             Show resolved relay config")
 	fmt.Println("  relay config validate                  Validate relay config")
 	fmt.Println("  relay config rollback                  Restore last-known-good config")
+	fmt.Println("  relay config schema                    Print JSON Schema for relay-server.yaml")
 	fmt.Println("  relay recover                          Recover relay identity from seed")
 	fmt.Println("  relay version                          Show relay server version")
 	fmt.Println()
@@ -235,8 +305,8 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Relay invites:")
 	fmt.Println("  relay invite create [--ttl 1h]         Generate an invite code")
-	fmt.Println("  relay invite list                      List invite deposits")
-	fmt.Println("  relay invite revoke <id>               Revoke a pending invite")
+	fmt.Println("  relay invite list [--all]              List outstanding invites")
+	fmt.Println("  relay invite revoke <code|group-id>    Revoke an invite before it is used")
 	fmt.Println()
 	fmt.Println("Operator announcements:")
 	fmt.Println("  relay motd set <message> [--remote]    Set message of the day")
@@ -256,7 +326,7 @@ func printUsage() {
 	fmt.Println("  service remove <name>                  Remove a service")
 	fmt.Println("  service enable <name>                  Enable a service")
 	fmt.Println("  service disable <name>                 Disable a service")
-	fmt.Println("  service list                           List configured services")
+	fmt.Println("  service list [--format f]              List configured services")
 	fmt.Println()
 	fmt.Println("Pairing:")
 	fmt.Println("  invite [--as \"home\"]                   Generate pairing invite")
@@ -284,7 +354,9 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Other:")
 	fmt.Println("  status [--config path]                 Show local config and services")
-	fmt.Println("  doctor [--fix]                         Check installation health")
+	fmt.Println("  history export [--format csv|json]     Export peer interaction history")
+	fmt.Println("  history peer <peer> [--json]           Recent connections to a peer")
+	fmt.Println("  doctor [--fix] [--json]                Check installation and config health")
 	fmt.Println("  completion <bash|zsh|fish>             Generate shell completion script")
 	fmt.Println("  man                                    Show manual page")
 	fmt.Println("  version                                Show version information")
@@ -313,6 +385,11 @@ func printUsage() {
 	fmt.Println("All commands support --config <path> to specify a config file.")
 	fmt.Println("Without --config, shurli searches: ./shurli.yaml, /etc/shurli/config.yaml, ~/.shurli/config.yaml")
 	fmt.Println()
+	fmt.Println("Global option (before the command):")
+	fmt.Println("  --profile <name>   Use <name>.yaml, <name>.key and <name>-authorized_keys")
+	fmt.Println("                     in the config directory; the daemon socket and state files")
+	fmt.Println("                     are namespaced too, so several profiles can run side by side.")
+	fmt.Println()
 	fmt.Println("Get started:  shurli init")
 }
 
@@ -616,7 +616,7 @@ func TestRunRelayServerVersion(t *testing.T) {
 func TestRunDaemonStop_NoDaemon(t *testing.T) {
 	t.Setenv("HOME", t.TempDir())
 	code, exited := captureExit(func() {
-		runDaemonStop()
+		runDaemonStop(nil)
 	})
 	if !exited || code != 1 {
 		t.Errorf("expected exit(1) when no daemon, got exited=%v code=%d", exited, code)
@@ -889,7 +889,7 @@ func TestRunRelayServe_InvalidConfig(t *testing.T) {
 
 func TestRunRelayInfo_ConfigNotFound(t *testing.T) {
 	code, exited := captureExit(func() {
-		runRelayInfo("/tmp/nonexistent-shurli-test/relay-server.yaml")
+		runRelayInfo(nil, "/tmp/nonexistent-shurli-test/relay-server.yaml")
 	})
 	if !exited || code != 1 {
 		t.Errorf("expected exit(1), got exited=%v code=%d", exited, code)
@@ -922,7 +922,7 @@ resources:
 	os.WriteFile(cfgFile, []byte(cfg), 0600)
 
 	code, exited := captureExit(func() {
-		runRelayInfo(cfgFile)
+		runRelayInfo(nil, cfgFile)
 	})
 	if !exited || code != 1 {
 		t.Errorf("expected exit(1) for missing key, got exited=%v code=%d", exited, code)
@@ -958,7 +958,7 @@ resources:
 	os.WriteFile(cfgFile, []byte(cfg), 0600)
 
 	code, exited := captureExit(func() {
-		runRelayInfo(cfgFile)
+		runRelayInfo(nil, cfgFile)
 	})
 	if !exited || code != 1 {
 		t.Errorf("expected exit(1) for invalid key, got exited=%v code=%d", exited, code)
@@ -969,7 +969,7 @@ func TestRunRelayInfo_Success(t *testing.T) {
 	cfgFile := writeRelayServerTestConfig(t)
 
 	code, exited := captureExit(func() {
-		runRelayInfo(cfgFile)
+		runRelayInfo(nil, cfgFile)
 	})
 	if exited {
 		t.Errorf("should not have exited, got code=%d", code)
@@ -1188,6 +1188,37 @@ func TestGaterReloader_ReloadFromFile(t *testing.T) {
 	}
 }
 
+func TestGaterReloader_ReloadFromFile_DisconnectsExpired(t *testing.T) {
+	dir := t.TempDir()
+	guest := generateTestPeerID(t)
+	home := generateTestPeerID(t)
+	guestID, _ := peer.Decode(guest)
+	homeID, _ := peer.Decode(home)
+
+	// Both peers were authorized when the daemon last loaded the file;
+	// the guest's authorization has since expired.
+	gater := auth.NewAuthorizedPeerGater(map[peer.ID]bool{guestID: true, homeID: true})
+	authFile := writeAuthKeysFile(t, dir, guest+"  expires="+time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)+"\n"+home+"\n")
+
+	var disconnected []peer.ID
+	reloader := &gaterReloader{gater: gater, authKeysPath: authFile, disconnect: func(p peer.ID) {
+		disconnected = append(disconnected, p)
+	}}
+	if err := reloader.ReloadFromFile(); err != nil {
+		t.Fatalf("ReloadFromFile() error: %v", err)
+	}
+
+	if gater.IsAuthorized(guestID) {
+		t.Error("expired peer still authorized after reload")
+	}
+	if !gater.IsAuthorized(homeID) {
+		t.Error("peer without a TTL lost authorization on reload")
+	}
+	if len(disconnected) != 1 || disconnected[0] != guestID {
+		t.Errorf("disconnected = %v, want [%s]", disconnected, guestID)
+	}
+}
+
 func TestGaterReloader_ReloadFromFile_MissingFile(t *testing.T) {
 	gater := auth.NewAuthorizedPeerGater(map[peer.ID]bool{})
 	reloader := &gaterReloader{gater: gater, authKeysPath: "/tmp/nonexistent-shurli-test/authorized_keys"}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -12,7 +13,10 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,6 +24,7 @@ import (
 
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
@@ -28,6 +33,7 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/shurlinet/shurli/internal/auth"
+	"github.com/shurlinet/shurli/internal/clock"
 	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/daemon"
 	"github.com/shurlinet/shurli/internal/grants"
@@ -57,6 +63,7 @@ type serveRuntime struct {
 	cancel     context.CancelFunc
 	version    string
 	startTime  time.Time
+	clock      clock.Clock  // time source for commit-confirmed and auth TTLs
 	kdht       *dht.IpfsDHT // stored for peer discovery from daemon API
 
 	// Interface discovery (populated at startup)
@@ -83,8 +90,9 @@ type serveRuntime struct {
 	// Network intelligence presence protocol (nil when disabled)
 	netIntel *sdk.NetIntel
 
-	// Routing discovery for DHT re-advertising on network change
-	routingDiscovery *drouting.RoutingDiscovery
+	// Rendezvous advertiser: keeps DHT provider records fresh, re-advertises
+	// on network change, and reports their health in daemon status
+	advertiser *sdk.RendezvousAdvertiser
 
 	// Peer relay (auto-enabled when public IP detected)
 	peerRelay *sdk.PeerRelay
@@ -92,12 +100,16 @@ type serveRuntime struct {
 	// Relay discovery (static + DHT-discovered relays)
 	relayDiscovery *sdk.RelayDiscovery
 
+	// Latest watchdog health check results (empty before StartWatchdog)
+	watchdogState *watchdog.State
+
 	// Observability (nil when telemetry disabled)
 	metrics       *sdk.Metrics
 	audit         *sdk.AuditLogger
 	metricsServer *http.Server
 	bwTracker     *sdk.BandwidthTracker
 	relayHealth   *sdk.RelayHealth
+	relaySelector *sdk.RelaySelector // nil when relays are disabled
 
 	// Sovereign per-peer interaction history
 	peerHistory *reputation.PeerHistory
@@ -105,6 +117,20 @@ type serveRuntime struct {
 	// MOTD client for relay message queries (populated by SetupMOTDClient)
 	motdClient *relay.MOTDClient
 
+	// Peer-to-peer text messages (populated by SetupMessaging)
+	messenger *sdk.Messenger
+
+	// Provisional peers awaiting the introduction challenge, so identify
+	// events for everyone else skip the authorized_keys read.
+	provisional sync.Map // peer.ID -> struct{}
+
+	// Provisional peers with an introduction challenge in flight.
+	challenging sync.Map // peer.ID -> struct{}
+
+	// Ping-pong handler registration; toggled on config reload.
+	pingPongMu    sync.Mutex
+	pingPongProto protocol.ID // "" when no handler is registered
+
 	// Per-peer data access grants (macaroon capability tokens)
 	grantStore    *grants.Store
 	grantPouch    *grants.Pouch
@@ -126,14 +152,59 @@ type serveRuntime struct {
 	deferredMu             sync.Mutex
 }
 
+// checkKeyFilePerms warns about an identity key or authorized_keys file
+// that other users can read (key) or write (authorized_keys). With fix
+// set, it repairs the mode instead. Files that don't exist yet are
+// skipped; on Windows the checks are no-ops.
+func checkKeyFilePerms(w io.Writer, cfg *config.NodeConfig, fix bool) {
+	checks := []struct {
+		path  string
+		check func(string) error
+		fix   func(string) (bool, error)
+	}{
+		{cfg.Identity.KeyFile, identity.CheckKeyFilePermissions, identity.FixKeyFilePermissions},
+		{cfg.Security.AuthorizedKeysFile, auth.CheckAuthorizedKeysPermissions, auth.FixAuthorizedKeysPermissions},
+	}
+	for _, c := range checks {
+		if c.path == "" {
+			continue
+		}
+		if _, err := os.Stat(c.path); err != nil {
+			continue
+		}
+		err := c.check(c.path)
+		if err == nil {
+			continue
+		}
+		if !fix {
+			fmt.Fprintf(w, "WARNING: %v\n", err)
+			fmt.Fprintln(w, "         (or start the daemon with --fix-perms to repair it)")
+			continue
+		}
+		if _, err := c.fix(c.path); err != nil {
+			fmt.Fprintf(w, "WARNING: could not fix permissions: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(w, "Fixed insecure permissions on %s\n", c.path)
+	}
+}
+
 // newServeRuntime creates a new serve runtime: loads config, creates P2P network,
 // handles commit-confirmed. The caller owns the context and cancel function.
-func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag, ver string) (*serveRuntime, error) {
+func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag, ver string, fixPerms bool) (*serveRuntime, error) {
 	rt := &serveRuntime{
-		ctx:       ctx,
-		cancel:    cancel,
-		version:   ver,
-		startTime: time.Now(),
+		ctx:           ctx,
+		cancel:        cancel,
+		version:       ver,
+		startTime:     time.Now(),
+		clock:         clock.Real,
+		watchdogState: watchdog.NewState(),
+	}
+
+	// Commit-confirmed, grant and invite expiry all read the wall clock.
+	if !config.ClockPlausible(rt.startTime) {
+		slog.Warn("system clock looks wrong; time-based expiry may misbehave until it is synced",
+			"now", rt.startTime.UTC().Format(time.RFC3339))
 	}
 
 	// Find and load configuration
@@ -150,6 +221,13 @@ func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag,
 	if err := config.ValidateNodeConfig(cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	for _, w := range config.NodeConfigWarnings(cfg) {
+		slog.Warn("config: " + w)
+	}
+
+	// Catch key files whose permissions drifted (restored from a backup,
+	// copied with cp -r, ...) before anything reads them.
+	checkKeyFilePerms(os.Stderr, cfg, fixPerms)
 
 	// Archive last-known-good config on successful validation
 	if err := config.Archive(cfgFile); err != nil {
@@ -161,13 +239,14 @@ func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag,
 
 	// Check for pending commit-confirmed
 	if deadline, err := config.CheckPending(cfgFile); err == nil && !deadline.IsZero() {
-		go config.EnforceCommitConfirmed(ctx, cfgFile, deadline, os.Exit)
-		remaining := time.Until(deadline).Round(time.Second)
+		go config.EnforceCommitConfirmed(ctx, rt.clock, cfgFile, deadline, os.Exit)
+		remaining, _ := config.CommitConfirmedRemaining(cfgFile, deadline, rt.clock.Now())
+		remaining = remaining.Round(time.Second)
 		fmt.Printf("Commit-confirmed active: %s remaining (run 'shurli config confirm' to keep this config)\n", remaining)
 	}
 
 	fmt.Printf("Loaded configuration from %s\n", cfgFile)
-	fmt.Printf("Rendezvous: %s\n", cfg.Discovery.Rendezvous)
+	fmt.Printf("Rendezvous: %s\n", strings.Join(cfg.Discovery.RendezvousList(), ", "))
 	fmt.Println()
 
 	// Set up connection gater
@@ -186,6 +265,7 @@ func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag,
 			return nil, fmt.Errorf("failed to load authorized_keys: %w", err)
 		}
 		rt.gater = auth.NewAuthorizedPeerGater(authorizedPeers)
+		applyPeerRestrictions(rt.gater, authorizedKeysFile)
 	} else {
 		fmt.Println("WARNING: Connection gating is DISABLED - any peer can connect!")
 	}
@@ -206,19 +286,12 @@ func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag,
 
 	// Initialize relay discovery with static relays from config.
 	// DHT discovery is enabled later in Bootstrap() after DHT creation.
-	staticRelayInfos, _ := sdk.ParseRelayAddrs(cfg.Relay.Addresses)
+	staticRelayInfos, _ := sdk.ParseRelayAddrs(cfg.Relay.ActiveAddresses())
 	rt.relayDiscovery = sdk.NewRelayDiscovery(staticRelayInfos, cfg.Discovery.Network, rt.metrics)
 
 	// Wire auth decision callback (metrics + audit)
 	if rt.gater != nil && (rt.metrics != nil || rt.audit != nil) {
-		rt.gater.SetDecisionCallback(func(peerID, result string) {
-			if rt.metrics != nil {
-				rt.metrics.AuthDecisionsTotal.WithLabelValues(result).Inc()
-			}
-			if rt.audit != nil {
-				rt.audit.AuthDecision(peerID, "inbound", result)
-			}
-		})
+		rt.gater.SetDecisionCallback(rt.observeAuthDecision)
 	}
 
 	// Resolve identity password for SHRL-encrypted key.
@@ -247,8 +320,9 @@ func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag,
 		Namespace:          cfg.Discovery.Network,
 		Metrics:            rt.metrics,
 		BandwidthTracker:      rt.bwTracker,
-		EnableRelay:           true,
-		RelayAddrs:            cfg.Relay.Addresses,
+		EnableRelay:           cfg.Relay.IsEnabled(),
+		RelayAddrs:            cfg.Relay.ActiveAddresses(),
+		PreferQUICRelay:       cfg.Relay.PreferQUIC,
 		ForcePrivate:          true, // Always maintain relay reservations in daemon mode. Network changes are frequent; relay must be a permanent fallback.
 		EnableNATPortMap:      true,
 		EnableHolePunching:    true,
@@ -282,10 +356,33 @@ func newServeRuntime(ctx context.Context, cancel context.CancelFunc, configFlag,
 	fmt.Printf("Peer ID: %s\n", net.Host().ID())
 	fmt.Println()
 
+	// A node whose own peer ID shows up as a relay, bootstrap peer, or name
+	// target almost always has a copied identity key. Warn loudly: the
+	// resulting network behavior is otherwise very hard to diagnose.
+	if conflicts := selfIdentityConflicts(net.Host().ID(), cfg); len(conflicts) > 0 {
+		fmt.Println("WARNING: this node's peer ID appears elsewhere in its own config:")
+		for _, c := range conflicts {
+			fmt.Printf("  - %s\n", c)
+		}
+		fmt.Println("  Two nodes sharing one identity key will fight over connections and relay reservations.")
+		fmt.Println("  If this config was copied from another machine, generate a new identity with 'shurli init'.")
+		fmt.Println()
+		slog.Error("identity: own peer ID found in config", "conflicts", len(conflicts))
+	}
+
 	// Initialize sovereign peer interaction history.
-	historyPath := filepath.Join(filepath.Dir(cfgFile), "peer_history.json")
+	historyPath := filepath.Join(filepath.Dir(cfgFile), config.ProfileFileName("peer_history.json"))
 	rt.peerHistory = reputation.NewPeerHistory(historyPath)
 
+	// Note DCUtR outcomes per peer, so the history shows which peers were
+	// ever upgraded to a direct connection.
+	rt.network.OnEvent(func(e sdk.Event) {
+		switch e.Type {
+		case sdk.EventHolePunchSucceeded, sdk.EventHolePunchFailed:
+			rt.peerHistory.RecordHolePunch(e.PeerID.String(), e.Type == sdk.EventHolePunchSucceeded, e.Detail)
+		}
+	})
+
 	return rt, nil
 }
 
@@ -314,11 +411,18 @@ func (rt *serveRuntime) Bootstrap() error {
 	h := rt.network.Host()
 	cfg := rt.config
 
-	// Discover network interfaces and log IPv6/IPv4 availability
+	// Discover network interfaces and log IPv6/IPv4 availability.
+	// Outside dual mode the disabled family is dropped from the summary so
+	// reachability grading and upgrade probes only see the family in use.
+	ipMode := cfg.Network.EffectiveIPMode()
+	if ipMode != config.IPModeDual {
+		fmt.Printf("IP mode: %s\n", sdk.IPModeLabel(ipMode))
+	}
 	ifSummary, err := sdk.DiscoverInterfaces()
 	if err != nil {
 		fmt.Printf("Warning: interface discovery failed: %v\n", err)
 	} else {
+		ifSummary = ifSummary.ForIPMode(ipMode)
 		rt.ifSummary = ifSummary
 		fmt.Printf("Network interfaces: %d with global addresses\n", len(ifSummary.Interfaces))
 		if ifSummary.HasGlobalIPv6 {
@@ -328,7 +432,11 @@ func (rt *serveRuntime) Bootstrap() error {
 			fmt.Printf("  Global IPv4: %d addresses\n", len(ifSummary.GlobalIPv4Addrs))
 		}
 		if !ifSummary.HasGlobalIPv6 && !ifSummary.HasGlobalIPv4 {
-			fmt.Println("  No global addresses detected - relay will be required")
+			if cfg.Relay.IsEnabled() {
+				fmt.Println("  No global addresses detected - relay will be required")
+			} else {
+				fmt.Println("  Warning: no global addresses and relay is disabled - peers outside this LAN may be unable to reach this node")
+			}
 		}
 		fmt.Println()
 
@@ -345,62 +453,11 @@ func (rt *serveRuntime) Bootstrap() error {
 		}
 	}
 
-	// Parse relay addresses for manual connection
-	relayInfos, err := sdk.ParseRelayAddrs(cfg.Relay.Addresses)
+	relayInfos, err := rt.bootstrapRelays(h)
 	if err != nil {
-		return fmt.Errorf("failed to parse relay addresses: %w", err)
-	}
-
-	// Connect to the relay
-	for _, ai := range relayInfos {
-		if err := h.Connect(rt.ctx, ai); err != nil {
-			fmt.Printf("Could not connect to relay %s: %v\n", ai.ID.String()[:16], err)
-		} else {
-			fmt.Printf("Connected to relay %s\n", ai.ID.String()[:16])
-		}
-	}
-
-	// Give AutoRelay a moment to make reservations
-	fmt.Println("Waiting for AutoRelay to establish reservations...")
-	time.Sleep(5 * time.Second)
-
-	// Check if we got relay addresses
-	hasRelay := false
-	for _, addr := range h.Addrs() {
-		if strings.Contains(addr.String(), "p2p-circuit") {
-			fmt.Printf("Relay address: %s\n", addr)
-			hasRelay = true
-		}
-	}
-	if !hasRelay {
-		fmt.Println("No relay addresses yet - trying manual reservation...")
-		for _, ai := range relayInfos {
-			_, err := circuitv2client.Reserve(rt.ctx, h, ai)
-			if err != nil {
-				fmt.Printf("Manual reservation failed: %v\n", err)
-			} else {
-				fmt.Printf("Manual relay reservation active on %s\n", ai.ID.String()[:16])
-			}
-		}
+		return err
 	}
 
-	// Keep reservation alive
-	go func() {
-		ticker := time.NewTicker(cfg.Relay.ReservationInterval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-rt.ctx.Done():
-				return
-			case <-ticker.C:
-				for _, ai := range relayInfos {
-					h.Connect(rt.ctx, ai)
-					circuitv2client.Reserve(rt.ctx, h, ai)
-				}
-			}
-		}
-	}()
-
 	// Bootstrap the DHT
 	dhtPrefix := sdk.DHTProtocolPrefixForNamespace(cfg.Discovery.Network)
 	if cfg.Discovery.Network != "" {
@@ -481,7 +538,7 @@ func (rt *serveRuntime) Bootstrap() error {
 
 	// Layer 4: Relay addresses (fallback, preserved from original behavior)
 	if len(bootstrapPeers) == 0 {
-		for _, addr := range cfg.Relay.Addresses {
+		for _, addr := range cfg.Relay.ActiveAddresses() {
 			maddr, err := ma.NewMultiaddr(addr)
 			if err != nil {
 				fmt.Printf("Invalid relay bootstrap addr %s: %v\n", addr, err)
@@ -498,63 +555,96 @@ func (rt *serveRuntime) Bootstrap() error {
 		fmt.Printf("Bootstrap source: %s (%d peers)\n", bootstrapSource, len(bootstrapPeers))
 	}
 
-	var wg sync.WaitGroup
-	var connected atomic.Int32
-	for _, pAddr := range bootstrapPeers {
-		pi, err := peer.AddrInfoFromP2pAddr(pAddr)
-		if err != nil {
-			continue
-		}
-		wg.Add(1)
-		go func(pi peer.AddrInfo) {
-			defer wg.Done()
-			if err := h.Connect(rt.ctx, pi); err == nil {
-				connected.Add(1)
-			}
-		}(*pi)
-	}
-	wg.Wait()
-	fmt.Printf("Connected to %d bootstrap peers\n", connected.Load())
-
-	// Advertise ourselves on the DHT using a rendezvous string
-	routingDiscovery := drouting.NewRoutingDiscovery(kdht)
-	rt.routingDiscovery = routingDiscovery
-	fmt.Printf("Advertising on rendezvous: %s\n", cfg.Discovery.Rendezvous)
+	connected := connectBootstrapPeers(rt.ctx, h, bootstrapPeers)
+	fmt.Printf("Connected to %d bootstrap peers\n", connected)
 
-	// Keep advertising in the background
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-		for {
-			routingDiscovery.Advertise(rt.ctx, cfg.Discovery.Rendezvous)
+	// discovery.require_bootstrap: refuse to run isolated. Relays count as
+	// a foothold too, since they also serve as bootstrap peers.
+	if cfg.Discovery.RequireBootstrap {
+		for attempt := 0; connected == 0 && connectedRelays(h, relayInfos) == 0; attempt++ {
+			if attempt == len(bootstrapRetryDelays) {
+				return fmt.Errorf("no bootstrap or relay peer reachable after %d attempts (discovery.require_bootstrap is set)", attempt+1)
+			}
+			delay := bootstrapRetryDelays[attempt]
+			fmt.Printf("No bootstrap peers reachable - retrying in %s (discovery.require_bootstrap)\n", delay)
 			select {
 			case <-rt.ctx.Done():
-				return
-			case <-ticker.C:
+				return rt.ctx.Err()
+			case <-time.After(delay):
 			}
+			connected = connectBootstrapPeers(rt.ctx, h, bootstrapPeers)
+			for _, ai := range relayInfos {
+				h.Connect(rt.ctx, ai)
+			}
+			fmt.Printf("Connected to %d bootstrap peers\n", connected)
 		}
-	}()
+	}
+
+	// Advertise ourselves on the DHT under the rendezvous string and any
+	// channels, all from one background timer. Failed advertises are
+	// retried well before the next regular refresh.
+	rendezvous := cfg.Discovery.RendezvousList()
+	rt.advertiser = sdk.NewRendezvousAdvertiser(drouting.NewRoutingDiscovery(kdht), rendezvous...)
+	fmt.Printf("Advertising on rendezvous: %s\n", strings.Join(rendezvous, ", "))
+	go rt.advertiser.Run(rt.ctx)
 
 	// Initialize path dialer for parallel connection racing
 	rt.pathDialer = sdk.NewPathDialer(h, kdht, rt.relayDiscovery, rt.metrics)
+	rt.pathDialer.SetPreferredRegion(cfg.Relay.PreferredRegion)
+	rt.pathDialer.SetIPMode(ipMode)
+	rt.pathDialer.SetRendezvous(rendezvous)
+	peerCachePath := filepath.Join(filepath.Dir(rt.configFile), config.ProfileFileName("peer_cache.json"))
+	rt.pathDialer.SetPeerCache(sdk.NewPeerCache(peerCachePath, cfg.Discovery.PeerCacheTTL))
+
+	// Initialize PeerManager for background reconnection of authorized peers.
+	// Peer history is fed by the path tracker below, which also sees
+	// inbound connections and relay-to-direct upgrades.
+	rt.peerManager = sdk.NewPeerManager(h, rt.pathDialer, rt.metrics, nil, rt.network.GetLANRegistry())
+	if rt.gater != nil {
+		rt.peerManager.SetWatchlist(rt.gater.ActivePeerIDs())
+	}
+	if grace := rt.config.Discovery.DisconnectGrace; grace > 0 { // 0 = default
+		rt.peerManager.SetDisconnectGrace(grace)
+	}
+	if ka := rt.config.Network.KeepaliveInterval; ka > 0 { // 0 = disabled
+		rt.peerManager.SetKeepalive(ka)
+	}
+	if burst := rt.config.Discovery.ReconnectLogBurst; burst > 0 { // 0 = default
+		rt.peerManager.SetReconnectLogSampling(burst, 0)
+	}
+	rt.peerManager.Start(rt.ctx)
 
-	// Initialize path tracker for per-peer connection visibility
+	// Initialize path tracker for per-peer connection visibility. Every
+	// new connection or path change to a watched peer goes into its
+	// history; DHT and relay peers are left out.
 	rt.pathTracker = sdk.NewPathTracker(h, rt.metrics)
+	rt.pathTracker.SetConnectionRecorder(func(peerID, pathType string, latencyMs float64) {
+		pid, err := peer.Decode(peerID)
+		if err != nil || rt.peerHistory == nil || !rt.peerManager.IsWatched(pid) {
+			return
+		}
+		rt.peerHistory.RecordConnection(peerID, pathType, latencyMs)
+	})
 	go rt.pathTracker.Start(rt.ctx)
 
-	// Initialize PeerManager for background reconnection of authorized peers.
-	rt.peerManager = sdk.NewPeerManager(h, rt.pathDialer, rt.metrics,
-		func(peerID, pathType string, latencyMs float64) {
-			if rt.peerHistory != nil {
-				rt.peerHistory.RecordConnection(peerID, pathType, latencyMs)
+	// network.idle_connection_timeout: trim idle connections, never those
+	// to watched peers or relays (reservations need the relay connection).
+	if idle := rt.config.Network.IdleConnectionTimeout; idle > 0 {
+		pm, rd := rt.peerManager, rt.relayDiscovery
+		sweeper := sdk.NewIdleConnSweeper(h, idle, func(p peer.ID) bool {
+			if pm.IsWatched(p) {
+				return true
 			}
-		},
-		rt.network.GetLANRegistry(),
-	)
-	if rt.gater != nil {
-		rt.peerManager.SetWatchlist(rt.gater.GetAuthorizedPeerIDs())
+			for _, ai := range rd.AllRelays() {
+				if ai.ID == p {
+					return true
+				}
+			}
+			return false
+		})
+		go sweeper.Run(rt.ctx)
+		fmt.Printf("Idle connection timeout: %s (watched peers and relays exempt)\n", idle)
 	}
-	rt.peerManager.Start(rt.ctx)
 
 	// TS-5: Wire PathProtector to PeerManager (PathProtector was created in
 	// SetupPathProtection() before plugins started, PeerManager was just created above).
@@ -611,6 +701,17 @@ func (rt *serveRuntime) Bootstrap() error {
 			},
 			announceInterval,
 		)
+		rt.netIntel.SetOnIdentityConflict(func(forwardedBy peer.ID) {
+			fmt.Println()
+			fmt.Println("WARNING: another node is announcing itself with this node's peer ID.")
+			fmt.Println("  Two machines are sharing one identity key. Generate a new identity on one of them.")
+			if rt.notifyRouter != nil {
+				event := notify.NewEvent(notify.EventIdentityConflict, notify.SeverityWarn,
+					h.ID().String(), "", "duplicate identity key detected on the network")
+				event = event.WithMetadata("forwarded_by", forwardedBy.String())
+				rt.notifyRouter.Emit(event)
+			}
+		})
 		rt.netIntel.Start(rt.ctx)
 		fmt.Println("Network intelligence (presence) enabled")
 	}
@@ -623,6 +724,7 @@ func (rt *serveRuntime) Bootstrap() error {
 			fmt.Printf("Warning: interface re-discovery failed: %v\n", err)
 			return
 		}
+		newSummary = newSummary.ForIPMode(ipMode)
 		rt.ifSummary = newSummary
 
 		// Update metrics
@@ -761,7 +863,7 @@ func (rt *serveRuntime) Bootstrap() error {
 		//
 		// We poll bind-readiness for DAD, then retry the probe up to
 		// 3 times with 2s spacing to cover NDP neighbor resolution.
-		if rt.peerManager != nil && len(change.Added) > 0 {
+		if rt.peerManager != nil && len(change.Added) > 0 && ipMode != config.IPModeIPv4 {
 			go func() {
 				// Wait for mDNS grace period before probing. ProbeAndUpgradeRelayed
 				// calls host.Connect which creates swarm dial workers — same
@@ -827,9 +929,9 @@ func (rt *serveRuntime) Bootstrap() error {
 						slog.Info("netmonitor: DHT re-bootstrapped after network change")
 					}
 				}
-				if rt.routingDiscovery != nil {
-					rt.routingDiscovery.Advertise(rt.ctx, cfg.Discovery.Rendezvous)
-					slog.Info("netmonitor: re-advertised on rendezvous")
+				if rt.advertiser != nil {
+					rt.advertiser.AdvertiseNow()
+					slog.Info("netmonitor: re-advertising on rendezvous")
 				}
 			}()
 		})
@@ -842,7 +944,7 @@ func (rt *serveRuntime) Bootstrap() error {
 			sysIPs := currentSystemIPs()
 			for _, addr := range h.Addrs() {
 				addrStr := addr.String()
-				if strings.Contains(addrStr, "/p2p-circuit") {
+				if sdk.ClassifyAddr(addr) == sdk.AddrCircuit {
 					continue
 				}
 				if ip := extractIPFromMultiaddr(addrStr); ip != nil {
@@ -857,30 +959,16 @@ func (rt *serveRuntime) Bootstrap() error {
 	go netmon.Run(rt.ctx)
 
 	// STUN probe for NAT type detection and external address discovery.
-	// Run in background so it doesn't block startup.
-	rt.stunProber = sdk.NewSTUNProber(nil, rt.metrics) // default STUN servers
-	go func() {
-		probeCtx, probeCancel := context.WithTimeout(rt.ctx, 10*time.Second)
-		defer probeCancel()
-		result, err := rt.stunProber.Probe(probeCtx)
-		if err != nil {
-			fmt.Printf("Warning: STUN probe failed: %v\n", err)
-			return
-		}
-		// Check for CGNAT after probe completes.
-		result.DetectCGNAT(rt.config.Network.ForceCGNAT)
-
-		fmt.Printf("NAT type: %s", result.NATType)
-		if len(result.ExternalAddrs) > 0 {
-			fmt.Printf(" (external: %s)", result.ExternalAddrs[0])
-		}
-		if result.BehindCGNAT {
-			fmt.Print(" [CGNAT]")
-		} else if result.NATType.HolePunchable() {
-			fmt.Print(" [hole-punchable]")
-		}
-		fmt.Println()
-	}()
+	// Run in background so it doesn't block startup: unreachable servers
+	// only cost a warning once the probe times out.
+	// The STUN prober only speaks IPv4, so IPv6-only mode skips it too.
+	if cfg.Network.DisableSTUN {
+		fmt.Println("STUN probing disabled (NAT type unknown)")
+	} else if ipMode == config.IPModeIPv6 {
+		fmt.Println("STUN probing skipped in IPv6-only mode (NAT type unknown)")
+	} else {
+		rt.startSTUNProbe(cfg.Network.STUNServers)
+	}
 
 	// Start bandwidth tracker background publish loop (every 30s).
 	// Scrapes libp2p's BandwidthCounter and updates Prometheus gauges.
@@ -890,8 +978,13 @@ func (rt *serveRuntime) Bootstrap() error {
 
 	// Start mDNS local discovery (default: enabled).
 	// Discovered peers go through ConnectionGater; no auth bypass.
+	// Only authorized peers in the same DHT namespace are dialed.
 	if cfg.Discovery.IsMDNSEnabled() {
 		rt.mdnsDiscovery = sdk.NewMDNSDiscovery(h, rt.metrics, rt.network.GetLANRegistry())
+		rt.mdnsDiscovery.SetNamespace(cfg.Discovery.Network)
+		if rt.gater != nil {
+			rt.mdnsDiscovery.SetPeerFilter(rt.gater.IsAuthorized)
+		}
 		if rt.peerManager != nil {
 			rt.mdnsDiscovery.SetPeerReconnector(rt.peerManager)
 		}
@@ -931,124 +1024,554 @@ func (rt *serveRuntime) Bootstrap() error {
 	// Start background relay health probes (every 60s)
 	go relayHealth.Start(rt.ctx, 60*time.Second)
 
-	// Start background relay discovery loop (finds DHT-advertised relays)
-	go rt.relayDiscovery.StartDiscoveryLoop(rt.ctx, 5*time.Minute)
+	// Start background relay discovery loop (finds DHT-advertised relays).
+	// Skipped when relays are disabled so no relay is ever dialed for
+	// reservations or circuits.
+	if cfg.Relay.IsEnabled() {
+		go rt.relayDiscovery.StartDiscoveryLoop(rt.ctx, 5*time.Minute)
+	}
 
 	return nil
 }
 
-// ExposeConfiguredServices registers all enabled services from config on the P2P host.
-// Also registers the service-query protocol handler (always, even with no services).
-func (rt *serveRuntime) ExposeConfiguredServices() {
-	// Register service-query protocol so remote peers can discover our services.
-	// Must run even when no services are configured (node still answers "no services").
-	if err := rt.network.RegisterServiceQuery(); err != nil {
-		log.Printf("Warning: failed to register service-query handler: %v", err)
+// relayReserve makes a circuit v2 reservation. A variable so tests can
+// observe reservation attempts without a live relay.
+var relayReserve = circuitv2client.Reserve
+
+// bootstrapRelays connects to the configured static relays, makes manual
+// reservations if AutoRelay has not, and keeps them refreshed. With
+// relay.enabled: false it does nothing and returns no relays.
+func (rt *serveRuntime) bootstrapRelays(h host.Host) ([]peer.AddrInfo, error) {
+	cfg := rt.config
+	if !cfg.Relay.IsEnabled() {
+		fmt.Println("Relay disabled (relay.enabled: false) - direct connections only")
+		return nil, nil
 	}
 
-	if rt.config.Services == nil {
-		return
+	// Parse relay addresses for manual connection
+	relayInfos, err := sdk.ParseRelayAddrs(cfg.Relay.ActiveAddresses())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse relay addresses: %w", err)
+	}
+
+	// Connect to the relay
+	for _, ai := range relayInfos {
+		if err := h.Connect(rt.ctx, ai); err != nil {
+			fmt.Printf("Could not connect to relay %s: %v\n", ai.ID.String()[:16], err)
+		} else {
+			fmt.Printf("Connected to relay %s\n", ai.ID.String()[:16])
+		}
 	}
-	for name, svc := range rt.config.Services {
-		if svc.Enabled {
-			fmt.Printf("Exposing service: %s -> %s\n", name, svc.LocalAddress)
-
-			// Convert AllowedPeers string slice to peer.ID set
-			var allowedPeers map[peer.ID]struct{}
-			if len(svc.AllowedPeers) > 0 {
-				allowedPeers = make(map[peer.ID]struct{}, len(svc.AllowedPeers))
-				for _, pidStr := range svc.AllowedPeers {
-					pid, err := peer.Decode(pidStr)
-					if err != nil {
-						log.Printf("Invalid peer ID %q in allowed_peers for %s: %v", pidStr, name, err)
-						continue
-					}
-					allowedPeers[pid] = struct{}{}
-				}
-				fmt.Printf("  ACL: %d allowed peers\n", len(allowedPeers))
-			}
 
-			if err := rt.network.ExposeService(name, svc.LocalAddress, allowedPeers); err != nil {
-				log.Printf("Failed to expose service %s: %v", name, err)
+	// Give AutoRelay a moment to make reservations. With relay.startup_wait
+	// of 0 (e.g. a node on a public IP), startup proceeds immediately and
+	// any missing reservations are made in the background.
+	if wait := cfg.Relay.StartupWaitOrDefault(); wait > 0 {
+		fmt.Println("Waiting for AutoRelay to establish reservations...")
+		time.Sleep(wait)
+		ensureRelayReservations(rt.ctx, h, relayInfos)
+	} else {
+		fmt.Println("Not waiting for relay reservations - establishing them in the background")
+		go ensureRelayReservations(rt.ctx, h, relayInfos)
+	}
+
+	// Rank relays by latency: AutoRelay is offered, and reservations are
+	// refreshed on, only the fastest (primary) and one backup. A newly
+	// selected relay is reserved on right away so a dead primary is
+	// replaced without waiting for the next refresh, and an idle demoted
+	// relay is disconnected so AutoRelay moves its reservation.
+	rt.relaySelector = sdk.NewRelaySelector(h, relayInfos)
+	if rt.network != nil {
+		rt.network.SetRelaySelector(rt.relaySelector)
+	}
+	rt.relaySelector.OnChange(func(primary, backup peer.ID) {
+		slog.Info("relay: selection changed", "primary", shortRelayID(primary), "backup", shortRelayID(backup))
+		if primary == "" {
+			return
+		}
+		for _, ai := range relayInfos {
+			if ai.ID != primary && ai.ID != backup {
+				if sdk.ReleaseRelay(h, ai.ID) {
+					slog.Info("relay: released demoted relay", "relay", shortRelayID(ai.ID))
+				}
+				continue
 			}
+			go func(ai peer.AddrInfo) {
+				h.Connect(rt.ctx, ai)
+				relayReserve(rt.ctx, h, ai)
+			}(ai)
 		}
+	})
+	go rt.relaySelector.Start(rt.ctx, relayLatencyProbeInterval)
+
+	// Keep reservations alive, each relay on its own interval.
+	for _, ai := range relayInfos {
+		go keepRelayReservation(rt.ctx, h, ai, cfg.Relay.ReservationIntervalFor(ai.ID.String()), rt.relaySelector.Preferred)
 	}
 
-	fmt.Println()
+	return relayInfos, nil
 }
 
-// SetupPingPong registers the ping-pong stream handler if enabled in config.
-func (rt *serveRuntime) SetupPingPong() {
-	if !rt.config.Protocols.PingPong.Enabled {
-		fmt.Println("Ping-pong protocol disabled in config")
-		return
-	}
+// relayLatencyProbeInterval is how often the configured relays are pinged
+// to pick the primary and backup.
+const relayLatencyProbeInterval = 30 * time.Second
 
-	h := rt.network.Host()
-	h.SetStreamHandler(protocol.ID(rt.config.Protocols.PingPong.ID), func(s network.Stream) {
-		remotePeer := s.Conn().RemotePeer()
+// shortRelayID truncates a relay peer ID for logs ("none" when empty).
+func shortRelayID(p peer.ID) string {
+	if p == "" {
+		return "none"
+	}
+	s := p.String()
+	if len(s) > 16 {
+		s = s[:16]
+	}
+	return s
+}
 
-		connType := "DIRECT"
-		if s.Conn().Stat().Limited {
-			connType = "RELAYED"
+// ensureRelayReservations makes manual reservations on the given relays
+// when AutoRelay has not produced any relay address yet.
+func ensureRelayReservations(ctx context.Context, h host.Host, relayInfos []peer.AddrInfo) {
+	// Check if we got relay addresses
+	hasRelay := false
+	for _, addr := range h.Addrs() {
+		if sdk.ClassifyAddr(addr) == sdk.AddrCircuit {
+			fmt.Printf("Relay address: %s\n", addr)
+			hasRelay = true
 		}
-		fmt.Printf("\nIncoming stream from %s [%s]\n", remotePeer.String()[:16], connType)
-
-		reader := bufio.NewReader(s)
-		msg, err := reader.ReadString('\n')
+	}
+	if hasRelay {
+		return
+	}
+	fmt.Println("No relay addresses yet - trying manual reservation...")
+	for _, ai := range relayInfos {
+		_, err := relayReserve(ctx, h, ai)
 		if err != nil {
-			fmt.Printf("   Read error: %v\n", err)
-			s.Close()
-			return
-		}
-		msg = strings.TrimSpace(msg)
-		fmt.Printf("   Received: %s\n", msg)
-
-		if msg == "ping" {
-			fmt.Println("   PONG!")
-			s.Write([]byte("pong\n"))
+			fmt.Printf("Manual reservation failed: %v\n", err)
 		} else {
-			fmt.Printf("   Unknown message: %s\n", msg)
-			s.Write([]byte("unknown\n"))
+			fmt.Printf("Manual relay reservation active on %s\n", ai.ID.String()[:16])
 		}
-		s.Close()
-	})
+	}
 }
 
-// SetupMOTDClient registers the MOTD client stream handler so the daemon
-// can receive MOTD and goodbye announcements from relays.
-func (rt *serveRuntime) SetupMOTDClient() {
-	h := rt.network.Host()
-	configDir := filepath.Dir(rt.configFile)
-
-	motdClient := relay.NewMOTDClient(func(msg relay.MOTDMessage) {
-		switch msg.Type {
-		case 0x01: // MOTD
-			fmt.Printf("\n[RELAY MOTD] %s\n", validate.SanitizeForDisplay(msg.Message))
-		case 0x02: // Goodbye
-			fmt.Printf("\n[RELAY GOODBYE] %s\n", validate.SanitizeForDisplay(msg.Message))
-		case 0x03: // Retract
-			fmt.Printf("\n[RELAY] Goodbye retracted\n")
+// keepRelayReservation refreshes the reservation on one relay every
+// interval until ctx is done. When the relay grants a reservation that
+// expires before the next refresh would happen, the interval is shortened
+// to half the advertised TTL so the reservation never lapses. When
+// preferred is set and reports false for the relay, the connection is kept
+// open (so its latency can still be measured) but the reservation is not
+// refreshed and lapses.
+func keepRelayReservation(ctx context.Context, h host.Host, ai peer.AddrInfo, interval time.Duration, preferred func(peer.ID) bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Connect(ctx, ai)
+			if preferred != nil && !preferred(ai.ID) {
+				continue
+			}
+			rsvp, err := relayReserve(ctx, h, ai)
+			if err != nil || rsvp == nil || rsvp.Expiration.IsZero() {
+				continue
+			}
+			if ttl := time.Until(rsvp.Expiration); ttl > 0 && interval >= ttl {
+				slog.Warn("relay: reservation interval is not shorter than the relay's reservation TTL; refreshing at half the TTL",
+					"relay", ai.ID.String()[:16], "interval", interval, "ttl", ttl.Round(time.Second))
+				interval = ttl / 2
+				ticker.Reset(interval)
+			}
 		}
-	}, configDir)
-	rt.motdClient = motdClient
-
-	h.SetStreamHandler(protocol.ID(relay.MOTDProtocol), func(s network.Stream) {
-		motdClient.HandleStream(s)
-	})
+	}
 }
 
-// SetupPeerNotify registers the peer-notify stream handler so the daemon
-// can receive peer introductions from the relay. This is the "mailbox
-// receiver" - when the relay delivers an introduction, the daemon
-// processes it and updates authorized_keys.
-func (rt *serveRuntime) SetupPeerNotify() {
-	if rt.authKeys == "" {
-		return // no authorized_keys = no gating = nothing to update
-	}
+// bootstrapRetryDelays are the waits between bootstrap attempts when
+// discovery.require_bootstrap is set. Once exhausted, Bootstrap fails so a
+// supervisor restarts the daemon instead of it running isolated.
+var bootstrapRetryDelays = []time.Duration{5 * time.Second, 15 * time.Second, 30 * time.Second}
 
-	h := rt.network.Host()
-	h.SetStreamHandler(protocol.ID(relay.PeerNotifyProtocol), func(s network.Stream) {
+// connectBootstrapPeers dials all bootstrap peers in parallel and returns
+// how many connected.
+func connectBootstrapPeers(ctx context.Context, h host.Host, addrs []ma.Multiaddr) int {
+	var wg sync.WaitGroup
+	var connected atomic.Int32
+	for _, pAddr := range addrs {
+		pi, err := peer.AddrInfoFromP2pAddr(pAddr)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(pi peer.AddrInfo) {
+			defer wg.Done()
+			if err := h.Connect(ctx, pi); err == nil {
+				connected.Add(1)
+			}
+		}(*pi)
+	}
+	wg.Wait()
+	return int(connected.Load())
+}
+
+// connectedRelays counts the configured relays h is currently connected to.
+func connectedRelays(h host.Host, relays []peer.AddrInfo) int {
+	n := 0
+	for _, ai := range relays {
+		if h.Network().Connectedness(ai.ID) == network.Connected {
+			n++
+		}
+	}
+	return n
+}
+
+// ExposeConfiguredServices registers all enabled services from config on the P2P host.
+// Also registers the service-query protocol handler (always, even with no services).
+func (rt *serveRuntime) ExposeConfiguredServices() {
+	// Register service-query protocol so remote peers can discover our services.
+	// Must run even when no services are configured (node still answers "no services").
+	if err := rt.network.RegisterServiceQuery(); err != nil {
+		log.Printf("Warning: failed to register service-query handler: %v", err)
+	}
+
+	for name, svc := range rt.config.Services {
+		if svc.Enabled {
+			rt.exposeConfiguredService(name, svc)
+		}
+	}
+
+	fmt.Println()
+}
+
+// exposeConfiguredService registers one enabled config service: every
+// member of a port range, or a single HTTP routing service.
+func (rt *serveRuntime) exposeConfiguredService(name string, svc config.ServiceConfig) {
+	var members []config.ServiceMember
+	if len(svc.HTTPRoutes) == 0 {
+		var err error
+		members, err = svc.Members(name)
+		if err != nil {
+			log.Printf("Failed to expose service %s: %v", name, err)
+			return
+		}
+	}
+	fmt.Printf("Exposing service: %s -> %s\n", name, svc.Target())
+	if len(members) > 1 {
+		fmt.Printf("  Port range: %d ports as %s .. %s\n", len(members), members[0].Name, members[len(members)-1].Name)
+	}
+
+	allowedPeers := serviceAllowedPeers(name, svc)
+	if allowedPeers != nil {
+		fmt.Printf("  ACL: %d allowed peers\n", len(allowedPeers))
+	}
+	if svc.MaxBandwidthMbps > 0 {
+		fmt.Printf("  Bandwidth limit: %g Mbps\n", svc.MaxBandwidthMbps)
+	}
+	if svc.MaxConcurrent > 0 || svc.RatePerMinute > 0 {
+		fmt.Printf("  Per-peer limits: %s\n", formatServicePeerLimits(svc.MaxConcurrent, svc.RatePerMinute))
+	}
+	if svc.RequireVerified {
+		fmt.Println("  Verified peers only")
+	}
+	if svc.Network == "udp" {
+		fmt.Println("  Network: udp")
+	}
+
+	opts := sdk.ExposeOptions{
+		ServiceLimits: sdk.ServiceLimits{
+			MaxBandwidthMbps: svc.MaxBandwidthMbps,
+			MaxConcurrent:    svc.MaxConcurrent,
+			RatePerMinute:    svc.RatePerMinute,
+		},
+		RequireVerified: svc.RequireVerified,
+		LocalNetwork:    svc.Network,
+	}
+
+	if len(svc.HTTPRoutes) > 0 {
+		routes := make([]sdk.HTTPRoute, len(svc.HTTPRoutes))
+		for i, r := range svc.HTTPRoutes {
+			routes[i] = sdk.HTTPRoute{Path: r.Path, Host: r.Host, Backend: r.Backend, StripPrefix: r.StripPrefix}
+		}
+		if err := rt.network.ExposeHTTPServiceWithOptions(name, routes, allowedPeers, opts); err != nil {
+			log.Printf("Failed to expose service %s: %v", name, err)
+		}
+		return
+	}
+
+	for _, m := range members {
+		if err := rt.network.ExposeServiceWithOptions(m.Name, m.LocalAddress, allowedPeers, opts); err != nil {
+			log.Printf("Failed to expose service %s: %v", m.Name, err)
+		}
+	}
+}
+
+// serviceAllowedPeers converts a service's allowed_peers to a peer ID set,
+// or nil when every authorized peer may connect. Invalid IDs are logged
+// and skipped.
+func serviceAllowedPeers(name string, svc config.ServiceConfig) map[peer.ID]struct{} {
+	if len(svc.AllowedPeers) == 0 {
+		return nil
+	}
+	allowedPeers := make(map[peer.ID]struct{}, len(svc.AllowedPeers))
+	for _, pidStr := range svc.AllowedPeers {
+		pid, err := peer.Decode(pidStr)
+		if err != nil {
+			log.Printf("Invalid peer ID %q in allowed_peers for %s: %v", pidStr, name, err)
+			continue
+		}
+		allowedPeers[pid] = struct{}{}
+	}
+	return allowedPeers
+}
+
+// registeredServiceNames returns the registry names a config service is
+// exposed under: one per port of a range, otherwise just name.
+func registeredServiceNames(name string, svc config.ServiceConfig) []string {
+	if len(svc.HTTPRoutes) > 0 {
+		return []string{name}
+	}
+	members, err := svc.Members(name)
+	if err != nil {
+		return []string{name}
+	}
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// reconcileServices applies a reloaded services section to the live host.
+// Services that were added or enabled are exposed and those removed or
+// disabled are unexposed. A service whose only changes are allowed_peers
+// or require_verified is updated in place, keeping its open streams; any
+// other change re-registers it. Services exposed at runtime through the
+// daemon API and absent from both configs are left alone. Returns the
+// names of the services that changed.
+func (rt *serveRuntime) reconcileServices(oldSvcs, newSvcs map[string]config.ServiceConfig) []string {
+	reg := rt.network.ServiceRegistry()
+	var changed []string
+
+	for name, old := range oldSvcs {
+		if !old.Enabled {
+			continue
+		}
+		if svc, ok := newSvcs[name]; ok && svc.Enabled {
+			continue
+		}
+		for _, n := range registeredServiceNames(name, old) {
+			if err := rt.network.UnexposeService(n); err != nil && !errors.Is(err, sdk.ErrServiceNotFound) {
+				slog.Warn("config reload: unexpose failed", "service", n, "err", err)
+			}
+		}
+		slog.Info("config reload: service unexposed", "service", name)
+		changed = append(changed, name)
+	}
+
+	for name, svc := range newSvcs {
+		if !svc.Enabled {
+			continue
+		}
+		old, wasEnabled := oldSvcs[name]
+		wasEnabled = wasEnabled && old.Enabled
+		names := registeredServiceNames(name, svc)
+		_, live := reg.GetService(names[0])
+
+		switch {
+		case wasEnabled && live && reflect.DeepEqual(old, svc):
+			continue
+		case wasEnabled && live && serviceACLOnlyChange(old, svc):
+			allowedPeers := serviceAllowedPeers(name, svc)
+			for _, n := range names {
+				if err := reg.SetAllowedPeers(n, allowedPeers); err != nil {
+					slog.Warn("config reload: ACL update failed", "service", n, "err", err)
+				}
+				if err := reg.SetRequireVerified(n, svc.RequireVerified); err != nil {
+					slog.Warn("config reload: require_verified update failed", "service", n, "err", err)
+				}
+			}
+			slog.Info("config reload: service ACL updated", "service", name)
+		default:
+			// Drop the old registration, or a same-named one exposed
+			// through the daemon API, before registering the new config.
+			stale := names
+			if wasEnabled {
+				stale = append(registeredServiceNames(name, old), names...)
+			}
+			for _, n := range stale {
+				_ = rt.network.UnexposeService(n) // not registered is fine
+			}
+			rt.exposeConfiguredService(name, svc)
+			slog.Info("config reload: service exposed", "service", name, "target", svc.Target())
+		}
+		changed = append(changed, name)
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// serviceACLOnlyChange reports whether two configs of an enabled service
+// differ only in fields that can be changed without re-registering it.
+func serviceACLOnlyChange(a, b config.ServiceConfig) bool {
+	a.AllowedPeers, b.AllowedPeers = nil, nil
+	a.RequireVerified, b.RequireVerified = false, false
+	return reflect.DeepEqual(a, b)
+}
+
+// formatServicePeerLimits describes a service's per-peer stream limits,
+// e.g. "4 concurrent, 30/min". Unset limits are omitted.
+func formatServicePeerLimits(maxConcurrent, ratePerMinute int) string {
+	var parts []string
+	if maxConcurrent > 0 {
+		parts = append(parts, fmt.Sprintf("%d concurrent", maxConcurrent))
+	}
+	if ratePerMinute > 0 {
+		parts = append(parts, fmt.Sprintf("%d/min", ratePerMinute))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// startSTUNProbe creates the STUN prober (built-in servers when servers
+// is empty) and runs the first probe in the background, printing the NAT
+// type. Each unreachable server is logged by the prober.
+func (rt *serveRuntime) startSTUNProbe(servers []string) {
+	rt.stunProber = sdk.NewSTUNProber(servers, rt.metrics)
+	go func() {
+		probeCtx, probeCancel := context.WithTimeout(rt.ctx, 10*time.Second)
+		defer probeCancel()
+		result, err := rt.stunProber.Probe(probeCtx)
+		if err != nil {
+			fmt.Printf("Warning: STUN probe failed: %v\n", err)
+			return
+		}
+		// Check for CGNAT after probe completes.
+		result.DetectCGNAT(rt.config.Network.ForceCGNAT)
+
+		fmt.Printf("NAT type: %s", result.NATType)
+		if len(result.ExternalAddrs) > 0 {
+			fmt.Printf(" (external: %s)", result.ExternalAddrs[0])
+		}
+		if result.BehindCGNAT {
+			fmt.Print(" [CGNAT]")
+		} else if result.NATType.HolePunchable() {
+			fmt.Print(" [hole-punchable]")
+		}
+		fmt.Println()
+		fmt.Println(sdk.AssessTraversal(result, rt.network.HolePunchStats()).Summary)
+	}()
+}
+
+// SetupPingPong registers the ping-pong stream handler if enabled in config.
+func (rt *serveRuntime) SetupPingPong() {
+	if !rt.config.Protocols.PingPong.Enabled {
+		fmt.Println("Ping-pong protocol disabled in config")
+		return
+	}
+	rt.SetPingPongEnabled(true)
+}
+
+// SetPingPongEnabled attaches or removes the ping-pong stream handler at
+// runtime. Enabling (re)registers it under the current
+// protocols.ping_pong.id, replacing any handler registered under a previous
+// ID. Once disabled, new ping streams are refused by protocol negotiation.
+func (rt *serveRuntime) SetPingPongEnabled(enabled bool) {
+	rt.pingPongMu.Lock()
+	defer rt.pingPongMu.Unlock()
+
+	h := rt.network.Host()
+	if rt.pingPongProto != "" {
+		h.RemoveStreamHandler(rt.pingPongProto)
+		rt.pingPongProto = ""
+	}
+	if !enabled {
+		return
+	}
+	rt.pingPongProto = protocol.ID(rt.config.Protocols.PingPong.ID)
+	h.SetStreamHandler(rt.pingPongProto, handlePingPong)
+}
+
+// handlePingPong answers "ping" with "pong" and logs the exchange.
+func handlePingPong(s network.Stream) {
+	remotePeer := s.Conn().RemotePeer()
+
+	connType := "DIRECT"
+	if s.Conn().Stat().Limited {
+		connType = "RELAYED"
+	}
+	fmt.Printf("\nIncoming stream from %s [%s]\n", remotePeer.String()[:16], connType)
+
+	reader := bufio.NewReader(s)
+	msg, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("   Read error: %v\n", err)
+		s.Close()
+		return
+	}
+	msg = strings.TrimSpace(msg)
+	fmt.Printf("   Received: %s\n", msg)
+
+	if msg == "ping" {
+		fmt.Println("   PONG!")
+		s.Write([]byte("pong\n"))
+	} else {
+		fmt.Printf("   Unknown message: %s\n", msg)
+		s.Write([]byte("unknown\n"))
+	}
+	s.Close()
+}
+
+// SetupMessaging registers the /shurli/msg/1.0.0 handler. Only authorized
+// peers may deliver messages; received ones are kept in a bounded inbox
+// exposed through the daemon API.
+func (rt *serveRuntime) SetupMessaging() {
+	rt.messenger = sdk.NewMessenger(rt.network.Host(), 0, func(p peer.ID) bool {
+		return rt.gater == nil || rt.gater.IsAuthorized(p)
+	})
+	rt.messenger.Register()
+}
+
+// SetupBandwidthTest registers the /shurli/bwtest/1.0.0 responder so
+// authorized peers can measure throughput to this node.
+func (rt *serveRuntime) SetupBandwidthTest() {
+	sdk.RegisterBandwidthTest(rt.network.Host(), func(p peer.ID) bool {
+		return rt.gater == nil || rt.gater.IsAuthorized(p)
+	})
+}
+
+// SetupMOTDClient registers the MOTD client stream handler so the daemon
+// can receive MOTD and goodbye announcements from relays.
+func (rt *serveRuntime) SetupMOTDClient() {
+	h := rt.network.Host()
+	configDir := filepath.Dir(rt.configFile)
+
+	motdClient := relay.NewMOTDClient(func(msg relay.MOTDMessage) {
+		switch msg.Type {
+		case 0x01: // MOTD
+			fmt.Printf("\n[RELAY MOTD] %s\n", validate.SanitizeForDisplay(msg.Message))
+		case 0x02: // Goodbye
+			fmt.Printf("\n[RELAY GOODBYE] %s\n", validate.SanitizeForDisplay(msg.Message))
+		case 0x03: // Retract
+			fmt.Printf("\n[RELAY] Goodbye retracted\n")
+		}
+	}, configDir)
+	rt.motdClient = motdClient
+
+	h.SetStreamHandler(protocol.ID(relay.MOTDProtocol), func(s network.Stream) {
+		motdClient.HandleStream(s)
+	})
+}
+
+// SetupPeerNotify registers the peer-notify stream handler so the daemon
+// can receive peer introductions from the relay. This is the "mailbox
+// receiver" - when the relay delivers an introduction, the daemon
+// processes it and updates authorized_keys.
+func (rt *serveRuntime) SetupPeerNotify() {
+	if rt.authKeys == "" {
+		return // no authorized_keys = no gating = nothing to update
+	}
+
+	h := rt.network.Host()
+	h.SetStreamHandler(protocol.ID(relay.PeerNotifyProtocol), func(s network.Stream) {
 		defer s.Close()
 		remotePeer := s.Conn().RemotePeer()
 
@@ -1101,6 +1624,18 @@ func (rt *serveRuntime) SetupPeerNotify() {
 				}
 			}
 
+			// SECURITY Layer 5: with security.introduction_challenge, a
+			// newly introduced peer stays provisional until it proves it
+			// holds its key (see challengeIntroduced).
+			if !alreadyExists && rt.config.Security.IntroductionChallenge {
+				if err := auth.MarkProvisional(rt.authKeys, p.PeerID, time.Now().Add(introductionChallengeWindow)); err != nil {
+					slog.Error("peer-notify: mark provisional failed",
+						"peer", p.PeerID[:16]+"...", "err", err)
+				} else if pid, err := peer.Decode(p.PeerID); err == nil {
+					rt.provisional.Store(pid, struct{}{})
+				}
+			}
+
 			// Always update attributes (even for existing peers that may
 			// be missing group/hmac from a previous incomplete pairing).
 			auth.SetPeerAttr(rt.authKeys, p.PeerID, "group", groupID)
@@ -1143,11 +1678,22 @@ func (rt *serveRuntime) SetupPeerNotify() {
 				slog.Error("peer-notify: gater reload failed", "err", err)
 			} else {
 				rt.gater.UpdateAuthorizedPeers(newPeers)
+				applyPeerRestrictions(rt.gater, rt.authKeys)
 				slog.Info("peer-notify: gater reloaded", "peers", len(newPeers))
 
 				// Update PeerManager watchlist with newly introduced peers.
 				if rt.peerManager != nil {
-					rt.peerManager.SetWatchlist(rt.gater.GetAuthorizedPeerIDs())
+					rt.peerManager.SetWatchlist(rt.gater.ActivePeerIDs())
+				}
+			}
+		}
+
+		// Provisional peers already connected get challenged now; the
+		// rest when they connect (StartIntroductionChallenges).
+		if rt.config.Security.IntroductionChallenge {
+			for _, p := range peers {
+				if pid, err := peer.Decode(p.PeerID); err == nil && h.Network().Connectedness(pid) == network.Connected {
+					go rt.challengeIntroduced(pid)
 				}
 			}
 		}
@@ -1155,6 +1701,122 @@ func (rt *serveRuntime) SetupPeerNotify() {
 
 }
 
+// introductionChallengeWindow is how long a peer introduced through
+// peer-notify stays provisionally authorized before it must have answered
+// the introduction challenge.
+const introductionChallengeWindow = 24 * time.Hour
+
+// SetupAuthChallenge registers the answering side of the introduction
+// challenge, so a node that was told about us through peer-notify can
+// confirm we hold our key. Every node answers; only nodes with
+// security.introduction_challenge ask.
+func (rt *serveRuntime) SetupAuthChallenge() {
+	h := rt.network.Host()
+	priv := h.Peerstore().PrivKey(h.ID())
+	if priv == nil {
+		return
+	}
+	h.SetStreamHandler(protocol.ID(relay.AuthChallengeProtocol), func(s network.Stream) {
+		if err := relay.HandleAuthChallenge(s, priv); err != nil {
+			slog.Debug("auth-challenge: answer failed",
+				"peer", s.Conn().RemotePeer().String()[:16]+"...", "err", err)
+		}
+	})
+}
+
+// StartIntroductionChallenges challenges provisional peers (see
+// SetupPeerNotify) each time one finishes identify, so the challenge
+// protocol is known to be supported before a stream is opened.
+func (rt *serveRuntime) StartIntroductionChallenges() {
+	if !rt.config.Security.IntroductionChallenge || rt.gater == nil || rt.authKeys == "" {
+		return
+	}
+	entries, err := auth.ListPeers(rt.authKeys)
+	if err != nil {
+		slog.Warn("auth-challenge: failed to list provisional peers", "err", err)
+	}
+	for _, e := range entries {
+		if e.Provisional {
+			rt.provisional.Store(e.PeerID, struct{}{})
+		}
+	}
+	sub, err := rt.network.Host().EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		slog.Warn("auth-challenge: failed to subscribe to identify events", "err", err)
+		return
+	}
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-rt.ctx.Done():
+				return
+			case evt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				pid := evt.(event.EvtPeerIdentificationCompleted).Peer
+				if _, ok := rt.provisional.Load(pid); ok {
+					go rt.challengeIntroduced(pid)
+				}
+			}
+		}
+	}()
+}
+
+// challengeIntroduced runs the introduction challenge against a
+// provisional peer. A valid signature makes its authorization permanent;
+// a wrong one means the introduction named a key the other end does not
+// hold, so the peer is removed and disconnected. Anything else (offline,
+// an older node without the protocol) leaves it provisional until the
+// next connect or until its expiry removes it.
+func (rt *serveRuntime) challengeIntroduced(pid peer.ID) {
+	if _, ok := rt.provisional.Load(pid); !ok {
+		return
+	}
+	if auth.GetPeerAttr(rt.authKeys, pid.String(), "provisional") == "" {
+		// Promoted or removed outside the daemon; never put at risk by a challenge.
+		rt.provisional.Delete(pid)
+		return
+	}
+	if _, busy := rt.challenging.LoadOrStore(pid, struct{}{}); busy {
+		return
+	}
+	defer rt.challenging.Delete(pid)
+
+	short := pid.String()[:16] + "..."
+	err := relay.ChallengePeer(rt.ctx, rt.network.Host(), pid)
+	switch {
+	case err == nil:
+		if err := auth.PromotePeer(rt.authKeys, pid.String()); err != nil {
+			slog.Error("auth-challenge: promote failed", "peer", short, "err", err)
+			return
+		}
+		rt.provisional.Delete(pid)
+		rt.gater.SetPeerExpiry(pid, time.Time{})
+		rt.audit.AuthChange("promote", pid.String())
+		slog.Info("auth-challenge: peer proved its key, authorization is permanent", "peer", short)
+	case errors.Is(err, relay.ErrChallengeFailed):
+		slog.Warn("auth-challenge: peer failed the challenge, removing", "peer", short)
+		if err := auth.RemovePeer(rt.authKeys, pid.String()); err != nil {
+			slog.Error("auth-challenge: remove failed", "peer", short, "err", err)
+			return
+		}
+		rt.provisional.Delete(pid)
+		if peers, err := auth.LoadAuthorizedKeys(rt.authKeys); err == nil {
+			rt.gater.UpdateAuthorizedPeers(peers)
+		}
+		rt.gater.SetPeerExpiry(pid, time.Time{})
+		rt.audit.AuthChange("remove", pid.String())
+		if rt.peerManager != nil {
+			rt.peerManager.SetWatchlist(rt.gater.ActivePeerIDs())
+		}
+		rt.disconnectDeauthorized(pid)
+	default:
+		slog.Debug("auth-challenge: no answer, peer stays provisional", "peer", short, "err", err)
+	}
+}
+
 // S7 (revised): receipt rate limiting removed. The original 10s-per-relay
 // rate limit blocked the second receipt in revoke+re-grant sequences.
 // Configured relay check in setupGrantReceiptHandler already limits
@@ -1298,7 +1960,7 @@ func (rt *serveRuntime) clearBackoffsAndReconnect() {
 
 // isConfiguredRelay checks if a peer ID matches one of the configured relay addresses.
 func (rt *serveRuntime) isConfiguredRelay(p peer.ID) bool {
-	for _, addr := range rt.config.Relay.Addresses {
+	for _, addr := range rt.config.Relay.ActiveAddresses() {
 		maddr, err := ma.NewMultiaddr(addr)
 		if err != nil {
 			continue
@@ -1314,6 +1976,39 @@ func (rt *serveRuntime) isConfiguredRelay(p peer.ID) bool {
 	return false
 }
 
+// selfIdentityConflicts returns a description of every place in cfg where
+// the node's own peer ID appears as a remote peer: relay addresses,
+// bootstrap peers, and name mappings. Any hit is a copy-paste error
+// (a config or key file copied from another node).
+func selfIdentityConflicts(self peer.ID, cfg *config.NodeConfig) []string {
+	var conflicts []string
+	isSelf := func(addr string) bool {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return false
+		}
+		ai, err := peer.AddrInfoFromP2pAddr(maddr)
+		return err == nil && ai.ID == self
+	}
+	for _, addr := range cfg.Relay.Addresses {
+		if isSelf(addr) {
+			conflicts = append(conflicts, "relay.addresses: "+addr)
+		}
+	}
+	for _, addr := range cfg.Discovery.BootstrapPeers {
+		if isSelf(addr) {
+			conflicts = append(conflicts, "discovery.bootstrap_peers: "+addr)
+		}
+	}
+	for name, pidStr := range cfg.Names {
+		if pid, err := peer.Decode(pidStr); err == nil && pid == self {
+			conflicts = append(conflicts, "names."+name)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
 // hasGroupMembership checks if this peer participated in the given pairing group.
 // Returns true if any peer in authorized_keys has a matching group attribute.
 func (rt *serveRuntime) hasGroupMembership(groupID string) bool {
@@ -1372,19 +2067,74 @@ func (rt *serveRuntime) StartWatchdog(extraChecks ...watchdog.HealthCheck) {
 				if time.Since(rt.startTime) < 60*time.Second {
 					return nil
 				}
-				for _, addr := range h.Addrs() {
-					if strings.Contains(addr.String(), "p2p-circuit") {
-						return nil
-					}
+				if sdk.HasRelayReservation(h) {
+					return nil
 				}
 				return fmt.Errorf("no relay addresses; add one with 'shurli relay add <address>' or run 'shurli init' to configure")
 			},
 		},
 	}
+	// Re-reserve on the configured relays when the reservation stays missing.
+	if len(rt.config.Relay.ActiveAddresses()) > 0 {
+		for i := range checks {
+			if checks[i].Name == "relay-reservation" {
+				checks[i].Recover = func() error { return rt.reserveConfiguredRelays(h) }
+			}
+		}
+	}
 
 	checks = append(checks, extraChecks...)
 
-	go watchdog.Run(rt.ctx, watchdog.Config{Interval: 30 * time.Second}, checks)
+	go watchdog.Run(rt.ctx, watchdog.Config{
+		Interval: 30 * time.Second,
+		State:    rt.watchdogState,
+		OnRecover: func(check string, err error) {
+			if rt.metrics == nil {
+				return
+			}
+			result := "success"
+			if err != nil {
+				result = "failure"
+			}
+			rt.metrics.WatchdogRecoveryTotal.WithLabelValues(check, result).Inc()
+		},
+	}, checks)
+}
+
+// reserveRecoveryBudget caps the total time reserveConfiguredRelays spends
+// across all configured relays.
+const reserveRecoveryBudget = 60 * time.Second
+
+// reserveConfiguredRelays makes a fresh reservation on every configured
+// relay. It is the watchdog's recovery action when the relay reservation
+// has been missing for several checks, and succeeds if any relay accepts.
+// The whole attempt is capped at reserveRecoveryBudget so a list of dead
+// relays cannot keep the recovery running indefinitely.
+func (rt *serveRuntime) reserveConfiguredRelays(h host.Host) error {
+	relayInfos, err := sdk.ParseRelayAddrs(rt.config.Relay.ActiveAddresses())
+	if err != nil {
+		return err
+	}
+	if len(relayInfos) == 0 {
+		return fmt.Errorf("no relays configured")
+	}
+	budget, cancelBudget := context.WithTimeout(rt.ctx, reserveRecoveryBudget)
+	defer cancelBudget()
+	var lastErr error
+	for _, ai := range relayInfos {
+		if budget.Err() != nil {
+			break
+		}
+		ctx, cancel := context.WithTimeout(budget, 30*time.Second)
+		_, err := relayReserve(ctx, h, ai)
+		cancel()
+		if err == nil {
+			slog.Info("watchdog: relay reservation restored", "relay", ai.ID.String()[:16]+"...")
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("reserve on %d relay(s) failed: %w", len(relayInfos), lastErr)
 }
 
 // StartStatusPrinter runs a background goroutine that periodically prints status.
@@ -1407,9 +2157,9 @@ func (rt *serveRuntime) StartStatusPrinter() {
 			fmt.Println("Addresses:")
 			currentIPs := currentSystemIPs()
 			for _, addr := range h.Addrs() {
-				label := classifyMultiaddr(addr.String())
+				label := sdk.AddrLabel(addr.String())
 				// Cross-check non-relay addresses against actual interfaces.
-				if label != "RELAY" {
+				if label != sdk.AddrLabelRelay {
 					if ip := extractIPFromMultiaddr(addr.String()); ip != nil {
 						if _, ok := currentIPs[ip.String()]; !ok {
 							label += ",stale?"
@@ -1470,20 +2220,6 @@ func (rt *serveRuntime) StartDHTHealthCheck() {
 	}()
 }
 
-// classifyMultiaddr returns a human-readable label for a multiaddr:
-// "RELAY", "public", or "local".
-func classifyMultiaddr(addrStr string) string {
-	if strings.Contains(addrStr, "/p2p-circuit") {
-		return "RELAY"
-	}
-	// Extract IP from multiaddr string (e.g. "/ip4/10.0.1.5/tcp/1234").
-	ip := extractIPFromMultiaddr(addrStr)
-	if ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !isCGNATAddr(ip) {
-		return "public"
-	}
-	return "local"
-}
-
 // extractIPFromMultiaddr parses the IP address from a multiaddr string.
 func extractIPFromMultiaddr(addrStr string) net.IP {
 	parts := strings.Split(addrStr, "/")
@@ -1512,15 +2248,6 @@ func currentSystemIPs() map[string]struct{} {
 	return ips
 }
 
-// isCGNATAddr checks if an IP is in the CGNAT range (100.64.0.0/10, RFC 6598).
-func isCGNATAddr(ip net.IP) bool {
-	ip4 := ip.To4()
-	if ip4 == nil {
-		return false
-	}
-	return ip4[0] == 100 && ip4[1] >= 64 && ip4[1] <= 127
-}
-
 // ConnectToPeer ensures the host can reach the target peer using parallel
 // path racing. It launches DHT discovery and relay circuit attempts
 // concurrently, returning as soon as the first path succeeds.
@@ -1640,6 +2367,160 @@ func (rt *serveRuntime) StartPeerHistorySaver() {
 	}()
 }
 
+// authExpirySweepInterval is how often the daemon removes peers whose
+// authorized_keys expires attribute (from auth add --ttl) has passed.
+const authExpirySweepInterval = time.Minute
+
+// StartAuthExpirySweep runs a background goroutine that deauthorizes peers
+// past their TTL: removes them from authorized_keys, hot-reloads the gater
+// and PeerManager watchlist, and closes any live connections.
+func (rt *serveRuntime) StartAuthExpirySweep() {
+	if rt.gater == nil || rt.authKeys == "" {
+		return
+	}
+	onRemoved := func() {
+		if rt.peerManager != nil {
+			rt.peerManager.SetWatchlist(rt.gater.ActivePeerIDs())
+		}
+	}
+	go runAuthExpirySweep(rt.ctx, rt.clock, rt.authKeys, rt.gater, rt.disconnectDeauthorized, onRemoved)
+}
+
+// scheduleSweepInterval is how often the daemon closes connections to
+// peers whose authorized_keys schedule window has ended. Schedules have
+// minute resolution.
+const scheduleSweepInterval = time.Minute
+
+// StartScheduleSweep runs a background goroutine that enforces peer
+// schedules on live connections: when a peer's window closes it is
+// disconnected and dropped from the PeerManager watchlist, and when the
+// window opens again it is put back.
+func (rt *serveRuntime) StartScheduleSweep() {
+	if rt.gater == nil {
+		return
+	}
+	onChange := func() {
+		if rt.peerManager != nil {
+			rt.peerManager.SetWatchlist(rt.gater.ActivePeerIDs())
+		}
+	}
+	go runScheduleSweep(rt.ctx, rt.clock, rt.gater, rt.disconnectDeauthorized, onChange)
+}
+
+// runScheduleSweep checks schedules every scheduleSweepInterval of clk
+// time until ctx is done. Peers outside their schedule are passed to
+// disconnect on every sweep (a no-op once they are gone); onChange runs
+// whenever the set of out-of-schedule peers changes.
+func runScheduleSweep(ctx context.Context, clk clock.Clock, gater *auth.AuthorizedPeerGater, disconnect func(peer.ID), onChange func()) {
+	ticker := clk.NewTicker(scheduleSweepInterval)
+	defer ticker.Stop()
+	var last []peer.ID
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		blocked := gater.OutOfSchedulePeers(clk.Now())
+		slices.Sort(blocked)
+		if !slices.Equal(blocked, last) {
+			last = blocked
+			if onChange != nil {
+				onChange()
+			}
+		}
+		if disconnect != nil {
+			for _, p := range blocked {
+				disconnect(p)
+			}
+		}
+	}
+}
+
+// observeAuthDecision records an inbound auth decision in metrics and the
+// audit log. Both are optional.
+func (rt *serveRuntime) observeAuthDecision(peerID, result string) {
+	if rt.metrics != nil {
+		rt.metrics.AuthDecisionsTotal.WithLabelValues(result).Inc()
+	}
+	if rt.audit != nil {
+		rt.audit.AuthDecision(peerID, "inbound", result)
+	}
+}
+
+// disconnectDeauthorized closes every connection to p after its
+// authorization was removed or expired.
+func (rt *serveRuntime) disconnectDeauthorized(p peer.ID) {
+	if len(rt.network.Host().Network().ConnsToPeer(p)) == 0 {
+		return
+	}
+	slog.Info("auth: disconnecting deauthorized peer", "peer", p.String()[:16]+"...")
+	if err := rt.network.Host().Network().ClosePeer(p); err != nil {
+		slog.Warn("auth: failed to disconnect peer", "peer", p.String()[:16]+"...", "err", err)
+	}
+}
+
+// runAuthExpirySweep sweeps authKeysPath every authExpirySweepInterval of
+// clk time until ctx is done. onRemoved runs after a sweep that removed
+// at least one peer.
+func runAuthExpirySweep(ctx context.Context, clk clock.Clock, authKeysPath string, gater *auth.AuthorizedPeerGater, disconnect func(peer.ID), onRemoved func()) {
+	ticker := clk.NewTicker(authExpirySweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			removed, err := sweepExpiredPeers(authKeysPath, gater, clk.Now(), disconnect)
+			if err != nil {
+				slog.Warn("auth-expiry: sweep failed", "err", err)
+			}
+			if len(removed) > 0 && onRemoved != nil {
+				onRemoved()
+			}
+		}
+	}
+}
+
+// sweepExpiredPeers removes expired peers from authKeysPath, reloads gater,
+// and calls disconnect for each removed peer. Returns the removed peers.
+func sweepExpiredPeers(authKeysPath string, gater *auth.AuthorizedPeerGater, now time.Time, disconnect func(peer.ID)) ([]peer.ID, error) {
+	removed, err := auth.RemoveExpiredPeers(authKeysPath, now)
+	if len(removed) == 0 {
+		return nil, err
+	}
+
+	peers, loadErr := auth.LoadAuthorizedKeys(authKeysPath)
+	if loadErr != nil {
+		return removed, fmt.Errorf("failed to reload authorized_keys: %w", loadErr)
+	}
+	gater.UpdateAuthorizedPeers(peers)
+
+	for _, p := range removed {
+		slog.Info("auth-expiry: peer authorization expired", "peer", p.String()[:16]+"...")
+		gater.SetPeerExpiry(p, time.Time{})
+		if disconnect != nil {
+			disconnect(p)
+		}
+	}
+	return removed, err
+}
+
+// applyPeerRestrictions loads expires and schedule attributes from
+// authorized_keys into the gater so inbound connections are refused the
+// moment a TTL passes (even before the next sweep removes the peer) and
+// outside a peer's scheduled time windows.
+func applyPeerRestrictions(gater *auth.AuthorizedPeerGater, authKeysPath string) {
+	entries, err := auth.ListPeers(authKeysPath)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		gater.SetPeerExpiry(e.PeerID, e.ExpiresAt)
+		gater.SetPeerSchedule(e.PeerID, e.Schedule)
+	}
+}
+
 // ComputeReputationScores computes reputation scores from peer history and returns
 // a map of peer ID string -> score (0-100). This is the bridge between PeerHistory
 // data collection and score consumption (PluginContext, ZKP tree).
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	circuitv2client "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/client"
+
+	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+func TestSelfIdentityConflicts(t *testing.T) {
+	selfStr := generateTestPeerID(t)
+	otherStr := generateTestPeerID(t)
+	self, err := peer.Decode(selfStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.NodeConfig{}
+	cfg.Relay.Addresses = []string{
+		"/ip4/203.0.113.50/tcp/7777/p2p/" + otherStr,
+		"/ip4/203.0.113.51/tcp/7777/p2p/" + selfStr,
+	}
+	cfg.Discovery.BootstrapPeers = []string{"/ip4/198.51.100.1/udp/4001/quic-v1/p2p/" + selfStr}
+	cfg.Names = config.NamesConfig{"home": selfStr, "laptop": otherStr}
+
+	conflicts := selfIdentityConflicts(self, cfg)
+	if len(conflicts) != 3 {
+		t.Fatalf("got %d conflicts, want 3: %v", len(conflicts), conflicts)
+	}
+	joined := strings.Join(conflicts, "\n")
+	for _, want := range []string{"relay.addresses: /ip4/203.0.113.51", "discovery.bootstrap_peers:", "names.home"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("conflicts missing %q:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "laptop") {
+		t.Errorf("unexpected conflict for other peer:\n%s", joined)
+	}
+}
+
+func TestSelfIdentityConflicts_Clean(t *testing.T) {
+	self, err := peer.Decode(generateTestPeerID(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.NodeConfig{}
+	cfg.Relay.Addresses = []string{"/ip4/203.0.113.50/tcp/7777/p2p/" + generateTestPeerID(t), "not-a-multiaddr"}
+	if conflicts := selfIdentityConflicts(self, cfg); len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v", conflicts)
+	}
+}
+
+// TestBootstrapRelays_Disabled verifies relay.enabled: false makes no relay
+// connections or reservation attempts, even with relay addresses configured.
+func TestBootstrapRelays_Disabled(t *testing.T) {
+	relayHost, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("relay host: %v", err)
+	}
+	defer relayHost.Close()
+	h, err := libp2p.New(libp2p.NoListenAddrs)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	defer h.Close()
+
+	reserves := 0
+	orig := relayReserve
+	relayReserve = func(ctx context.Context, h host.Host, ai peer.AddrInfo) (*circuitv2client.Reservation, error) {
+		reserves++
+		return nil, context.Canceled
+	}
+	defer func() { relayReserve = orig }()
+
+	disabled := false
+	cfg := &config.NodeConfig{}
+	cfg.Relay.Enabled = &disabled
+	cfg.Relay.Addresses = []string{relayHost.Addrs()[0].String() + "/p2p/" + relayHost.ID().String()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := &serveRuntime{config: cfg, ctx: ctx}
+
+	infos, err := rt.bootstrapRelays(h)
+	if err != nil {
+		t.Fatalf("bootstrapRelays: %v", err)
+	}
+	if len(infos) != 0 {
+		t.Errorf("got %d relays, want none", len(infos))
+	}
+	if reserves != 0 {
+		t.Errorf("got %d reservation attempts, want 0", reserves)
+	}
+	if h.Network().Connectedness(relayHost.ID()) == network.Connected {
+		t.Error("connected to relay despite relay.enabled: false")
+	}
+}
+
+// TestBootstrapRelays_NoStartupWait verifies relay.startup_wait: 0 returns
+// without the startup wait and still attempts the manual reservation in
+// the background.
+func TestBootstrapRelays_NoStartupWait(t *testing.T) {
+	relayHost, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("relay host: %v", err)
+	}
+	defer relayHost.Close()
+	h, err := libp2p.New(libp2p.NoListenAddrs)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	defer h.Close()
+
+	reserved := make(chan peer.ID, 1)
+	orig := relayReserve
+	relayReserve = func(ctx context.Context, h host.Host, ai peer.AddrInfo) (*circuitv2client.Reservation, error) {
+		select {
+		case reserved <- ai.ID:
+		default:
+		}
+		return nil, context.Canceled
+	}
+	defer func() { relayReserve = orig }()
+
+	var noWait time.Duration
+	cfg := &config.NodeConfig{}
+	cfg.Relay.ReservationInterval = time.Hour
+	cfg.Relay.StartupWait = &noWait
+	cfg.Relay.Addresses = []string{relayHost.Addrs()[0].String() + "/p2p/" + relayHost.ID().String()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := &serveRuntime{config: cfg, ctx: ctx}
+
+	start := time.Now()
+	if _, err := rt.bootstrapRelays(h); err != nil {
+		t.Fatalf("bootstrapRelays: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= config.DefaultRelayStartupWait {
+		t.Errorf("bootstrapRelays took %s with startup_wait 0", elapsed)
+	}
+	select {
+	case id := <-reserved:
+		if id != relayHost.ID() {
+			t.Errorf("reserved on %s, want %s", id, relayHost.ID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no background reservation attempt")
+	}
+}
+
+// TestKeepRelayReservation_PerRelayInterval verifies each relay's
+// reservation is refreshed on its own interval.
+func TestKeepRelayReservation_PerRelayInterval(t *testing.T) {
+	h, err := libp2p.New(libp2p.NoListenAddrs)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	defer h.Close()
+
+	var mu sync.Mutex
+	reserves := map[peer.ID]int{}
+	orig := relayReserve
+	relayReserve = func(ctx context.Context, h host.Host, ai peer.AddrInfo) (*circuitv2client.Reservation, error) {
+		mu.Lock()
+		reserves[ai.ID]++
+		mu.Unlock()
+		return nil, context.Canceled
+	}
+	defer func() { relayReserve = orig }()
+
+	fast, err := peer.Decode(generateTestPeerID(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := peer.Decode(generateTestPeerID(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.NodeConfig{}
+	cfg.Relay.ReservationInterval = time.Hour
+	cfg.Relay.ReservationIntervals = map[string]time.Duration{
+		fast.String(): 20 * time.Millisecond,
+		slow.String(): 200 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, id := range []peer.ID{fast, slow} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keepRelayReservation(ctx, h, peer.AddrInfo{ID: id}, cfg.Relay.ReservationIntervalFor(id.String()), nil)
+		}()
+	}
+	time.Sleep(500 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if reserves[slow] < 1 || reserves[slow] > 3 {
+		t.Errorf("slow relay refreshed %d times in 500ms at 200ms, want 1-3", reserves[slow])
+	}
+	if reserves[fast] < 5*reserves[slow] {
+		t.Errorf("fast relay refreshed %d times, slow %d; want the 20ms relay refreshed far more often", reserves[fast], reserves[slow])
+	}
+}
+
+// TestBootstrap_RequireBootstrap verifies discovery.require_bootstrap makes
+// Bootstrap fail, after its retries, when no bootstrap peer is reachable.
+func TestBootstrap_RequireBootstrap(t *testing.T) {
+	// A peer that was listening but has gone away: its address refuses dials.
+	gone, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatalf("bootstrap host: %v", err)
+	}
+	goneAddr := gone.Addrs()[0].String() + "/p2p/" + gone.ID().String()
+	gone.Close()
+
+	nw, err := sdk.New(&sdk.Config{
+		KeyFile: filepath.Join(t.TempDir(), "identity.key"),
+		Config: &config.Config{
+			Network: config.NetworkConfig{ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("network: %v", err)
+	}
+	defer nw.Close()
+
+	origDelays := bootstrapRetryDelays
+	bootstrapRetryDelays = []time.Duration{10 * time.Millisecond}
+	defer func() { bootstrapRetryDelays = origDelays }()
+
+	disabled := false
+	cfg := &config.NodeConfig{}
+	cfg.Relay.Enabled = &disabled
+	cfg.Discovery.BootstrapPeers = []string{goneAddr}
+	cfg.Discovery.RequireBootstrap = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	rt := &serveRuntime{
+		network:        nw,
+		config:         cfg,
+		ctx:            ctx,
+		relayDiscovery: sdk.NewRelayDiscovery(nil, "", nil),
+	}
+
+	err = rt.Bootstrap()
+	if err == nil {
+		t.Fatal("Bootstrap succeeded with no reachable peers and require_bootstrap set")
+	}
+	if !strings.Contains(err.Error(), "require_bootstrap") {
+		t.Errorf("error should name the option, got: %v", err)
+	}
+}
+
+// TestSetPingPongEnabled verifies the ping-pong handler can be detached and
+// re-attached at runtime: while disabled, new ping streams are refused.
+func TestSetPingPongEnabled(t *testing.T) {
+	newNet := func() *sdk.Network {
+		nw, err := sdk.New(&sdk.Config{
+			KeyFile: filepath.Join(t.TempDir(), "identity.key"),
+			Config: &config.Config{
+				Network: config.NetworkConfig{ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("network: %v", err)
+		}
+		t.Cleanup(func() { nw.Close() })
+		return nw
+	}
+	server, client := newNet(), newNet()
+
+	cfg := &config.NodeConfig{}
+	cfg.Protocols.PingPong = config.PingPongConfig{Enabled: true, ID: "/pingpong/1.0.0"}
+	rt := &serveRuntime{network: server, config: cfg}
+	rt.SetupPingPong()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Host().Connect(ctx, peer.AddrInfo{ID: server.PeerID(), Addrs: server.Host().Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	ping := func() error {
+		s, err := client.Host().NewStream(ctx, server.PeerID(), protocol.ID(cfg.Protocols.PingPong.ID))
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		if _, err := s.Write([]byte("ping\n")); err != nil {
+			return err
+		}
+		reply, err := bufio.NewReader(s).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if reply != "pong\n" {
+			return fmt.Errorf("reply = %q", reply)
+		}
+		return nil
+	}
+
+	if err := ping(); err != nil {
+		t.Fatalf("ping with handler enabled: %v", err)
+	}
+	rt.SetPingPongEnabled(false)
+	if err := ping(); err == nil {
+		t.Fatal("ping succeeded after disabling the handler")
+	}
+	rt.SetPingPongEnabled(true)
+	if err := ping(); err != nil {
+		t.Fatalf("ping after re-enabling: %v", err)
+	}
+}
+
+func TestCheckKeyFilePerms(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions not applicable on Windows")
+	}
+
+	dir := t.TempDir()
+	cfg := &config.NodeConfig{}
+	cfg.Identity.KeyFile = filepath.Join(dir, "identity.key")
+	cfg.Security.AuthorizedKeysFile = filepath.Join(dir, "authorized_keys")
+	os.WriteFile(cfg.Identity.KeyFile, []byte("key"), 0600)
+	os.WriteFile(cfg.Security.AuthorizedKeysFile, []byte(""), 0600)
+	os.Chmod(cfg.Identity.KeyFile, 0644)
+	os.Chmod(cfg.Security.AuthorizedKeysFile, 0666)
+
+	var buf bytes.Buffer
+	checkKeyFilePerms(&buf, cfg, false)
+	out := buf.String()
+	if strings.Count(out, "WARNING:") != 2 || !strings.Contains(out, "--fix-perms") {
+		t.Errorf("expected two warnings with --fix-perms hint, got:\n%s", out)
+	}
+	if info, _ := os.Stat(cfg.Identity.KeyFile); info.Mode().Perm() != 0644 {
+		t.Errorf("check without fix changed key mode to %04o", info.Mode().Perm())
+	}
+
+	buf.Reset()
+	checkKeyFilePerms(&buf, cfg, true)
+	if strings.Contains(buf.String(), "WARNING") {
+		t.Errorf("unexpected warning with fix:\n%s", buf.String())
+	}
+	for path, want := range map[string]os.FileMode{
+		cfg.Identity.KeyFile:            0600,
+		cfg.Security.AuthorizedKeysFile: 0644,
+	} {
+		info, _ := os.Stat(path)
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s mode after fix = %04o, want %04o", filepath.Base(path), got, want)
+		}
+	}
+
+	// Once fixed, nothing more to report.
+	buf.Reset()
+	checkKeyFilePerms(&buf, cfg, false)
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output after fix:\n%s", buf.String())
+	}
+}
+
+// TestReconcileServices verifies a reloaded services section is applied to
+// the live registry: removed services are unexposed, enabled ones exposed,
+// and an ACL-only change keeps the existing registration.
+func TestReconcileServices(t *testing.T) {
+	nw, err := sdk.New(&sdk.Config{
+		KeyFile: filepath.Join(t.TempDir(), "identity.key"),
+		Config: &config.Config{
+			Network: config.NetworkConfig{ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("network: %v", err)
+	}
+	t.Cleanup(func() { nw.Close() })
+	reg := nw.ServiceRegistry()
+	peerA, peerB := generateTestPeerID(t), generateTestPeerID(t)
+
+	oldSvcs := map[string]config.ServiceConfig{
+		"ssh": {Enabled: true, LocalAddress: "localhost:22"},
+		"web": {Enabled: true, LocalAddress: "localhost:80", AllowedPeers: []string{peerA}},
+		"off": {Enabled: false, LocalAddress: "localhost:8080"},
+	}
+	rt := &serveRuntime{network: nw, config: &config.NodeConfig{Services: oldSvcs}}
+	rt.ExposeConfiguredServices()
+	web, ok := reg.GetService("web")
+	if !ok {
+		t.Fatal("web not exposed at start")
+	}
+
+	newSvcs := map[string]config.ServiceConfig{
+		"web": {Enabled: true, LocalAddress: "localhost:80", AllowedPeers: []string{peerB}, RequireVerified: true},
+		"off": {Enabled: true, LocalAddress: "localhost:8080"},
+	}
+	changed := rt.reconcileServices(oldSvcs, newSvcs)
+	if got := strings.Join(changed, ","); got != "off,ssh,web" {
+		t.Errorf("changed = %q, want off,ssh,web", got)
+	}
+	if _, ok := reg.GetService("ssh"); ok {
+		t.Error("removed service still exposed")
+	}
+	if _, ok := reg.GetService("off"); !ok {
+		t.Error("enabled service not exposed")
+	}
+	if got, _ := reg.GetService("web"); got != web {
+		t.Error("ACL-only change re-registered the service")
+	}
+	pidB, _ := peer.Decode(peerB)
+	if _, ok := web.AllowedPeers[pidB]; !ok || len(web.AllowedPeers) != 1 {
+		t.Errorf("web ACL = %v, want only %s", web.AllowedPeers, peerB)
+	}
+	if !reg.RequiresVerified("web") {
+		t.Error("require_verified not applied in place")
+	}
+
+	if changed := rt.reconcileServices(newSvcs, newSvcs); len(changed) != 0 {
+		t.Errorf("unchanged config reported changes: %v", changed)
+	}
+
+	// Any other change re-registers the service.
+	moved := map[string]config.ServiceConfig{
+		"web": {Enabled: true, LocalAddress: "localhost:81", AllowedPeers: []string{peerB}, RequireVerified: true},
+		"off": newSvcs["off"],
+	}
+	rt.reconcileServices(newSvcs, moved)
+	got, ok := reg.GetService("web")
+	if !ok || got == web || got.LocalAddress != "localhost:81" {
+		t.Errorf("web not re-registered with the new address: %+v", got)
+	}
+}
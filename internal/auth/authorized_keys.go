@@ -3,8 +3,10 @@ package auth
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 )
@@ -12,7 +14,15 @@ import (
 // LoadAuthorizedKeys loads and parses an authorized_keys file.
 // Returns a simple peer ID -> bool map for backward compatibility.
 // Format: <peer-id> [key=value attrs...] [# comment]
+// Peers whose expires attribute has passed are skipped (and logged), so an
+// expired authorization is never loaded into a gater.
 func LoadAuthorizedKeys(path string) (map[peer.ID]bool, error) {
+	return loadAuthorizedKeys(path, time.Now())
+}
+
+// loadAuthorizedKeys loads path, skipping peers expired at now. A zero now
+// keeps every entry (used for duplicate checks).
+func loadAuthorizedKeys(path string, now time.Time) (map[peer.ID]bool, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open authorized_keys file: %w", err)
@@ -25,7 +35,7 @@ func LoadAuthorizedKeys(path string) (map[peer.ID]bool, error) {
 
 	for scanner.Scan() {
 		lineNum++
-		peerIDStr, _, _ := parseLine(scanner.Text())
+		peerIDStr, attrs, _ := parseLine(scanner.Text())
 		if peerIDStr == "" {
 			continue
 		}
@@ -35,6 +45,13 @@ func LoadAuthorizedKeys(path string) (map[peer.ID]bool, error) {
 			return nil, fmt.Errorf("invalid peer ID at line %d: %s (error: %w)", lineNum, peerIDStr, err)
 		}
 
+		if v, ok := attrs["expires"]; ok && !now.IsZero() {
+			if exp, err := time.Parse(time.RFC3339, v); err == nil && now.After(exp) {
+				slog.Info("authorized_keys: skipping expired peer", "peer", peerID.String()[:16]+"...", "expired", v)
+				continue
+			}
+		}
+
 		authorizedPeers[peerID] = true
 	}
 
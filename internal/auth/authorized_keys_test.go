@@ -101,11 +101,11 @@ func TestLoadAuthorizedKeysWithAttributes(t *testing.T) {
 	pid2 := genPeerIDStr(t)
 
 	dir := t.TempDir()
-	content := pid1 + "  expires=2026-03-15T00:00:00Z  # contractor\n" +
+	content := pid1 + "  expires=2099-03-15T00:00:00Z  # contractor\n" +
 		pid2 + "  verified=sha256:abc123  # mum\n"
 	path := writeAuthKeys(t, dir, content)
 
-	// LoadAuthorizedKeys still works (ignores attributes)
+	// LoadAuthorizedKeys still works (ignores attributes other than a past expiry)
 	peers, err := LoadAuthorizedKeys(path)
 	if err != nil {
 		t.Fatalf("LoadAuthorizedKeys: %v", err)
@@ -115,6 +115,35 @@ func TestLoadAuthorizedKeysWithAttributes(t *testing.T) {
 	}
 }
 
+func TestLoadAuthorizedKeysSkipsExpired(t *testing.T) {
+	expired := genPeerIDStr(t)
+	current := genPeerIDStr(t)
+	plain := genPeerIDStr(t)
+
+	dir := t.TempDir()
+	content := expired + "  expires=" + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) + "  # guest\n" +
+		current + "  expires=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + "\n" +
+		plain + "\n"
+	path := writeAuthKeys(t, dir, content)
+
+	peers, err := LoadAuthorizedKeys(path)
+	if err != nil {
+		t.Fatalf("LoadAuthorizedKeys: %v", err)
+	}
+	expiredID, _ := peer.Decode(expired)
+	if peers[expiredID] {
+		t.Error("expired peer was loaded")
+	}
+	if len(peers) != 2 {
+		t.Errorf("loaded %d peers, want 2", len(peers))
+	}
+
+	// The expired line still blocks a duplicate add until it is pruned.
+	if err := AddPeer(path, expired, ""); err == nil {
+		t.Error("AddPeer accepted a peer whose expired entry is still in the file")
+	}
+}
+
 func TestListPeersWithAttributes(t *testing.T) {
 	pid1 := genPeerIDStr(t)
 	pid2 := genPeerIDStr(t)
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// ExportedPeer is an authorized_keys entry in portable form, used to copy
+// peers between nodes with `shurli auth export --json` and `auth import`.
+type ExportedPeer struct {
+	PeerID  string            `json:"peer_id"`
+	Comment string            `json:"comment,omitempty"`
+	Group   string            `json:"group,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"` // all attributes except group
+}
+
+// ImportConflict records a peer that exists locally with a different comment.
+// The local comment is kept.
+type ImportConflict struct {
+	PeerID          string `json:"peer_id"`
+	ExistingComment string `json:"existing_comment"`
+	ImportedComment string `json:"imported_comment"`
+}
+
+// ImportInvalid records an entry that could not be imported.
+type ImportInvalid struct {
+	Index  int    `json:"index"` // position in the imported list
+	PeerID string `json:"peer_id"`
+	Error  string `json:"error"`
+}
+
+// ImportResult summarizes an ImportPeers merge.
+type ImportResult struct {
+	Added     []string         `json:"added,omitempty"`     // new peers appended
+	Merged    []string         `json:"merged,omitempty"`    // existing peers that gained attributes
+	Skipped   []string         `json:"skipped,omitempty"`   // existing peers with nothing new
+	Conflicts []ImportConflict `json:"conflicts,omitempty"` // existing peers with a different comment
+	Invalid   []ImportInvalid  `json:"invalid,omitempty"`   // malformed entries, not imported
+}
+
+// Changed reports whether the import modified the file.
+func (r *ImportResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Merged) > 0
+}
+
+// ExportPeers returns every valid entry in the authorized_keys file with
+// its comment and attributes. Lines with malformed peer IDs are skipped.
+func ExportPeers(authKeysPath string) ([]ExportedPeer, error) {
+	file, err := os.Open(authKeysPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // no file = no peers
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var out []ExportedPeer
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pidStr, attrs, comment := parseLine(scanner.Text())
+		if pidStr == "" {
+			continue
+		}
+		peerID, err := peer.Decode(pidStr)
+		if err != nil {
+			continue
+		}
+		e := ExportedPeer{PeerID: peerID.String(), Comment: comment}
+		for k, v := range attrs {
+			if k == "group" {
+				e.Group = v
+				continue
+			}
+			if e.Attrs == nil {
+				e.Attrs = make(map[string]string)
+			}
+			e.Attrs[k] = v
+		}
+		out = append(out, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return out, nil
+}
+
+// FormatExportedPeer renders an entry as an authorized_keys line.
+func FormatExportedPeer(e ExportedPeer) string {
+	attrs := make(map[string]string, len(e.Attrs)+1)
+	for k, v := range e.Attrs {
+		attrs[k] = v
+	}
+	if e.Group != "" {
+		attrs["group"] = e.Group
+	}
+	return formatLine(e.PeerID, attrs, e.Comment)
+}
+
+// ImportPeers merges peers into the authorized_keys file, creating it if
+// needed. New peers are appended. For a peer already in the file, imported
+// attributes it lacks are added, but existing attributes and the existing
+// comment are never overwritten; a differing comment is reported as a
+// conflict. Entries with invalid peer IDs are reported and skipped without
+// aborting the rest. The file is rewritten atomically, once.
+func ImportPeers(authKeysPath string, peers []ExportedPeer) (*ImportResult, error) {
+	var lines []string
+	if data, err := os.ReadFile(authKeysPath); err == nil {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		if len(lines) == 1 && lines[0] == "" {
+			lines = nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Index existing entries by peer ID. The first line wins, matching
+	// how the loader treats duplicates.
+	index := make(map[peer.ID]int)
+	for i, line := range lines {
+		pidStr, _, _ := parseLine(line)
+		if pidStr == "" {
+			continue
+		}
+		if pid, err := peer.Decode(pidStr); err == nil {
+			if _, dup := index[pid]; !dup {
+				index[pid] = i
+			}
+		}
+	}
+
+	result := &ImportResult{}
+	for i, e := range peers {
+		pid, err := peer.Decode(strings.TrimSpace(e.PeerID))
+		if err != nil {
+			result.Invalid = append(result.Invalid, ImportInvalid{
+				Index:  i,
+				PeerID: e.PeerID,
+				Error:  fmt.Sprintf("%v: %v", ErrInvalidPeerID, err),
+			})
+			continue
+		}
+		comment := sanitizeComment(e.Comment)
+		attrs := sanitizeImportAttrs(e)
+
+		li, exists := index[pid]
+		if !exists {
+			index[pid] = len(lines)
+			lines = append(lines, formatLine(pid.String(), attrs, comment))
+			result.Added = append(result.Added, pid.String())
+			continue
+		}
+
+		pidStr, existingAttrs, existingComment := parseLine(lines[li])
+		if existingAttrs == nil {
+			existingAttrs = make(map[string]string)
+		}
+		added := false
+		for _, k := range sortedKeys(attrs) {
+			if _, ok := existingAttrs[k]; !ok {
+				existingAttrs[k] = attrs[k]
+				added = true
+			}
+		}
+		if comment != "" && comment != existingComment {
+			if existingComment == "" {
+				existingComment = comment
+				added = true
+			} else {
+				result.Conflicts = append(result.Conflicts, ImportConflict{
+					PeerID:          pid.String(),
+					ExistingComment: existingComment,
+					ImportedComment: comment,
+				})
+			}
+		}
+		if added {
+			lines[li] = formatLine(pidStr, existingAttrs, existingComment)
+			result.Merged = append(result.Merged, pid.String())
+		} else {
+			result.Skipped = append(result.Skipped, pid.String())
+		}
+	}
+
+	if !result.Changed() {
+		return result, nil
+	}
+	if err := atomicWriteLines(authKeysPath, lines); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// sanitizeImportAttrs returns the entry's attributes (with group folded
+// back in) stripped of anything that could corrupt the file format.
+// Attributes whose key or value sanitizes to empty are dropped.
+func sanitizeImportAttrs(e ExportedPeer) map[string]string {
+	attrs := make(map[string]string, len(e.Attrs)+1)
+	for k, v := range e.Attrs {
+		k, v = sanitizeAttrValue(k), sanitizeAttrValue(v)
+		if k == "" || v == "" {
+			continue
+		}
+		attrs[k] = v
+	}
+	if g := sanitizeAttrValue(e.Group); g != "" {
+		attrs["group"] = g
+	}
+	return attrs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportPeers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+	pidA, pidB := genPeerIDStr(t), genPeerIDStr(t)
+	content := "# fleet\n" +
+		pidA + "  role=admin  group=g1  # laptop\n" +
+		"not-a-peer-id  # broken\n" +
+		pidB + "\n"
+	os.WriteFile(path, []byte(content), 0600)
+
+	peers, err := ExportPeers(path)
+	if err != nil {
+		t.Fatalf("ExportPeers: %v", err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("exported %d peers, want 2", len(peers))
+	}
+	a := peers[0]
+	if a.PeerID != pidA || a.Comment != "laptop" || a.Group != "g1" || a.Attrs["role"] != "admin" {
+		t.Errorf("peer A = %+v", a)
+	}
+	if _, ok := a.Attrs["group"]; ok {
+		t.Error("group should not be duplicated in attrs")
+	}
+	if peers[1].PeerID != pidB || peers[1].Attrs != nil {
+		t.Errorf("peer B = %+v", peers[1])
+	}
+
+	if peers, err := ExportPeers(filepath.Join(dir, "missing")); err != nil || peers != nil {
+		t.Errorf("missing file: %v, %v", peers, err)
+	}
+}
+
+func TestImportPeersRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	pid := genPeerIDStr(t)
+	os.WriteFile(src, []byte(pid+"  role=admin  expires=2099-01-01T00:00:00Z  group=g1  # home\n"), 0600)
+
+	peers, err := ExportPeers(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportPeers(dst, peers)
+	if err != nil {
+		t.Fatalf("ImportPeers: %v", err)
+	}
+	if len(result.Added) != 1 {
+		t.Fatalf("result = %+v, want one added", result)
+	}
+
+	got, _ := ListPeers(dst)
+	if len(got) != 1 || got[0].Role != "admin" || got[0].Group != "g1" || got[0].Comment != "home" || got[0].ExpiresAt.IsZero() {
+		t.Errorf("imported entry = %+v", got)
+	}
+}
+
+func TestImportPeersMerge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+	same, merged, conflict := genPeerIDStr(t), genPeerIDStr(t), genPeerIDStr(t)
+	content := "# keep me\n" +
+		same + "  # laptop\n" +
+		merged + "  role=admin  # nas\n" +
+		conflict + "  # phone\n"
+	os.WriteFile(path, []byte(content), 0600)
+
+	fresh := genPeerIDStr(t)
+	result, err := ImportPeers(path, []ExportedPeer{
+		{PeerID: same, Comment: "laptop"},
+		{PeerID: "12D3KooWbroken"},
+		{PeerID: merged, Comment: "nas", Attrs: map[string]string{"role": "member", "verified": "sha256:abcd"}},
+		{PeerID: conflict, Comment: "tablet"},
+		{PeerID: fresh, Comment: "new\nline", Group: "g2"},
+	})
+	if err != nil {
+		t.Fatalf("ImportPeers: %v", err)
+	}
+
+	if len(result.Added) != 1 || result.Added[0] != fresh {
+		t.Errorf("Added = %v, want [%s]", result.Added, fresh)
+	}
+	if len(result.Merged) != 1 || result.Merged[0] != merged {
+		t.Errorf("Merged = %v, want [%s]", result.Merged, merged)
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("Skipped = %v, want same and conflict", result.Skipped)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].ExistingComment != "phone" || result.Conflicts[0].ImportedComment != "tablet" {
+		t.Errorf("Conflicts = %+v", result.Conflicts)
+	}
+	if len(result.Invalid) != 1 || result.Invalid[0].Index != 1 {
+		t.Errorf("Invalid = %+v, want entry 1", result.Invalid)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "# keep me\n") {
+		t.Error("file comment lines should be preserved")
+	}
+	entries, _ := ListPeers(path)
+	byID := make(map[string]PeerEntry)
+	for _, e := range entries {
+		byID[e.PeerID.String()] = e
+	}
+	if e := byID[merged]; e.Role != "admin" || e.Verified != "sha256:abcd" {
+		t.Errorf("merged entry = %+v, want role kept and verified added", e)
+	}
+	if e := byID[conflict]; e.Comment != "phone" {
+		t.Errorf("conflict comment = %q, want existing kept", e.Comment)
+	}
+	if e := byID[fresh]; e.Comment != "newline" || e.Group != "g2" {
+		t.Errorf("new entry = %+v", e)
+	}
+}
+
+func TestImportPeersNoChangesLeavesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+	pid := genPeerIDStr(t)
+	content := pid + "  # laptop\n"
+	os.WriteFile(path, []byte(content), 0600)
+
+	result, err := ImportPeers(path, []ExportedPeer{{PeerID: pid, Comment: "laptop"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed() {
+		t.Errorf("result = %+v, want no changes", result)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("file rewritten: %q", data)
+	}
+}
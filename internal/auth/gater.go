@@ -11,6 +11,8 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
 )
 
 // AuthDecisionFunc is called on every inbound auth decision with the peer ID
@@ -18,13 +20,21 @@ import (
 // without creating a circular dependency on pkg/sdk.
 type AuthDecisionFunc func(peerID, result string)
 
+// KnownPeerDecisionFunc is called on inbound auth decisions for peers in the
+// authorized list, including expired and out-of-schedule ones, with the
+// full peer ID. Unknown peers are reported only through AuthDecisionFunc.
+type KnownPeerDecisionFunc func(p peer.ID, result string)
+
 // AuthorizedPeerGater implements the ConnectionGater interface.
 // It blocks connections from peers that are not in the authorized list.
 // Supports enrollment mode for relay pairing and expiring peer authorization.
 type AuthorizedPeerGater struct {
 	authorizedPeers map[peer.ID]bool
 	peerExpiry      map[peer.ID]time.Time // zero = never expires
+	peerSchedule    map[peer.ID]*Schedule // absent = no time-of-day restriction
 	onDecision      AuthDecisionFunc      // nil-safe
+	onKnownDecision KnownPeerDecisionFunc // nil-safe
+	clock           clock.Clock           // injectable clock for expiry and schedules
 	mu              sync.RWMutex
 
 	// Enrollment mode: temporarily allows unknown peers during pairing.
@@ -49,6 +59,8 @@ func NewAuthorizedPeerGater(authorizedPeers map[peer.ID]bool) *AuthorizedPeerGat
 	return &AuthorizedPeerGater{
 		authorizedPeers:      authorizedPeers,
 		peerExpiry:           make(map[peer.ID]time.Time),
+		peerSchedule:         make(map[peer.ID]*Schedule),
+		clock:                clock.Real,
 		probationPeers:       make(map[peer.ID]time.Time),
 		probationLimit:       10,
 		probationTimeout:     10 * time.Second,
@@ -115,17 +127,19 @@ func (g *AuthorizedPeerGater) InterceptSecured(dir network.Direction, p peer.ID,
 	// Check if peer is in the authorized list.
 	if g.authorizedPeers[p] {
 		// Check expiry if set.
-		if exp, ok := g.peerExpiry[p]; ok && !exp.IsZero() && time.Now().After(exp) {
+		now := g.clock.Now()
+		if exp, ok := g.peerExpiry[p]; ok && !exp.IsZero() && now.After(exp) {
 			slog.Warn("inbound connection denied (expired)", "peer", short)
-			if g.onDecision != nil {
-				g.onDecision(short, "deny")
-			}
+			g.reportDecision(p, short, "deny")
 			return false
 		}
-		slog.Info("inbound connection allowed", "peer", short)
-		if g.onDecision != nil {
-			g.onDecision(short, "allow")
+		if sched, ok := g.peerSchedule[p]; ok && !sched.Allows(now) {
+			slog.Warn("inbound connection denied", "peer", short, "reason", "outside schedule", "schedule", sched.String())
+			g.reportDecision(p, short, "deny")
+			return false
 		}
+		slog.Info("inbound connection allowed", "peer", short)
+		g.reportDecision(p, short, "allow")
 		return true
 	}
 
@@ -154,9 +168,7 @@ func (g *AuthorizedPeerGater) InterceptSecured(dir network.Direction, p peer.ID,
 			if lastAdmit, ok := g.probationIPCooldown[remoteIP]; ok {
 				if time.Since(lastAdmit) < g.probationCooldownDur {
 					slog.Warn("inbound connection denied (IP cooldown)", "peer", short)
-					if g.onDecision != nil {
-						g.onDecision(short, "deny")
-					}
+					g.reportDecision(p, short, "deny")
 					return false
 				}
 			}
@@ -173,16 +185,12 @@ func (g *AuthorizedPeerGater) InterceptSecured(dir network.Direction, p peer.ID,
 		}
 		g.probationPeers[p] = time.Now()
 		slog.Info("inbound connection allowed (probation)", "peer", short)
-		if g.onDecision != nil {
-			g.onDecision(short, "allow")
-		}
+		g.reportDecision(p, short, "allow")
 		return true
 	}
 
 	slog.Warn("inbound connection denied", "peer", short)
-	if g.onDecision != nil {
-		g.onDecision(short, "deny")
-	}
+	g.reportDecision(p, short, "deny")
 	return false
 }
 
@@ -207,15 +215,64 @@ func (g *AuthorizedPeerGater) GetAuthorizedPeersCount() int {
 	return len(g.authorizedPeers)
 }
 
-// IsAuthorized checks if a peer is authorized
+// IsAuthorized checks if a peer is authorized. A peer whose expiry (see
+// SetPeerExpiry) has passed, or who is outside its schedule (see
+// SetPeerSchedule), is not.
 func (g *AuthorizedPeerGater) IsAuthorized(p peer.ID) bool {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	return g.authorizedPeers[p]
+	return g.allowedLocked(p, g.clock.Now())
+}
+
+// allowedLocked reports whether p is authorized, unexpired and inside its
+// schedule at now. Caller must hold g.mu.
+func (g *AuthorizedPeerGater) allowedLocked(p peer.ID, now time.Time) bool {
+	if !g.authorizedPeers[p] {
+		return false
+	}
+	if exp, ok := g.peerExpiry[p]; ok && !exp.IsZero() && now.After(exp) {
+		return false
+	}
+	if sched, ok := g.peerSchedule[p]; ok && !sched.Allows(now) {
+		return false
+	}
+	return true
 }
 
-// GetAuthorizedPeerIDs returns a slice of all currently authorized peer IDs.
-// Used by PeerManager to build the reconnection watchlist.
+// ActivePeerIDs returns the authorized peers that may connect right now:
+// not expired and inside their schedule. PeerManager watches only these,
+// so a peer is not redialed outside its schedule.
+func (g *AuthorizedPeerGater) ActivePeerIDs() []peer.ID {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	now := g.clock.Now()
+	ids := make([]peer.ID, 0, len(g.authorizedPeers))
+	for pid := range g.authorizedPeers {
+		if g.allowedLocked(pid, now) {
+			ids = append(ids, pid)
+		}
+	}
+	return ids
+}
+
+// OutOfSchedulePeers returns the authorized peers whose schedule does not
+// allow a connection at now. Their existing connections are closed by the
+// daemon's schedule sweep.
+func (g *AuthorizedPeerGater) OutOfSchedulePeers(now time.Time) []peer.ID {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var ids []peer.ID
+	for pid, sched := range g.peerSchedule {
+		if g.authorizedPeers[pid] && !sched.Allows(now) {
+			ids = append(ids, pid)
+		}
+	}
+	return ids
+}
+
+// GetAuthorizedPeerIDs returns a slice of all currently authorized peer IDs,
+// including expired and out-of-schedule ones. See ActivePeerIDs for the
+// reconnection watchlist.
 func (g *AuthorizedPeerGater) GetAuthorizedPeerIDs() []peer.ID {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
@@ -235,6 +292,25 @@ func (g *AuthorizedPeerGater) SetDecisionCallback(fn AuthDecisionFunc) {
 	g.onDecision = fn
 }
 
+// SetKnownPeerDecisionCallback sets a callback invoked on inbound auth
+// decisions for peers in the authorized list. The daemon streams these to
+// event subscribers without recording every internet scanner that is denied.
+func (g *AuthorizedPeerGater) SetKnownPeerDecisionCallback(fn KnownPeerDecisionFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onKnownDecision = fn
+}
+
+// reportDecision notifies the decision callbacks. Called with g.mu held.
+func (g *AuthorizedPeerGater) reportDecision(p peer.ID, short, result string) {
+	if g.onDecision != nil {
+		g.onDecision(short, result)
+	}
+	if g.onKnownDecision != nil && g.authorizedPeers[p] {
+		g.onKnownDecision(p, result)
+	}
+}
+
 // PrintAuthorizedPeers prints the list of authorized peers (for debugging)
 func (g *AuthorizedPeerGater) PrintAuthorizedPeers() {
 	g.mu.RLock()
@@ -293,6 +369,19 @@ func (g *AuthorizedPeerGater) SetPeerExpiry(p peer.ID, expiresAt time.Time) {
 	}
 }
 
+// SetPeerSchedule restricts an authorized peer to the schedule's time
+// windows: inbound connections and IsAuthorized checks outside them are
+// refused. A nil schedule removes the restriction.
+func (g *AuthorizedPeerGater) SetPeerSchedule(p peer.ID, sched *Schedule) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if sched == nil {
+		delete(g.peerSchedule, p)
+	} else {
+		g.peerSchedule[p] = sched
+	}
+}
+
 // CleanupProbation evicts probation peers that have exceeded the timeout.
 // The disconnect callback is called for each evicted peer (outside the lock).
 func (g *AuthorizedPeerGater) CleanupProbation(disconnect func(peer.ID)) {
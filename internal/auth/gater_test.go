@@ -8,6 +8,8 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
 )
 
 // mockConnMultiaddrs satisfies network.ConnMultiaddrs for testing.
@@ -287,6 +289,130 @@ func TestNoExpiryPeerAllowed(t *testing.T) {
 	}
 }
 
+func TestIsAuthorizedHonorsExpiry(t *testing.T) {
+	p := genPeerID(t)
+	g := NewAuthorizedPeerGater(map[peer.ID]bool{p: true})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	g.clock = clock.NewFake(start)
+	g.SetPeerExpiry(p, start.Add(time.Hour))
+
+	if !g.IsAuthorized(p) {
+		t.Fatal("peer should be authorized before its expiry")
+	}
+	g.clock = clock.NewFake(start.Add(2 * time.Hour))
+	if g.IsAuthorized(p) {
+		t.Error("peer should not be authorized after its expiry")
+	}
+	g.SetPeerExpiry(p, time.Time{})
+	if !g.IsAuthorized(p) {
+		t.Error("clearing the expiry should restore authorization")
+	}
+}
+
+// --- Schedule tests ---
+
+func TestScheduledPeerAllowedInWindow(t *testing.T) {
+	p := genPeerID(t)
+	g := NewAuthorizedPeerGater(map[peer.ID]bool{p: true})
+	sched, err := ParseSchedule("mon-fri@09:00-17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetPeerSchedule(p, sched)
+	g.clock = clock.NewFake(time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)) // Wednesday
+
+	if !g.InterceptSecured(network.DirInbound, p, testConnMultiaddrs()) {
+		t.Error("peer inside its schedule should be allowed")
+	}
+}
+
+func TestScheduledPeerDeniedOutsideWindow(t *testing.T) {
+	p := genPeerID(t)
+	g := NewAuthorizedPeerGater(map[peer.ID]bool{p: true})
+	sched, err := ParseSchedule("mon-fri@09:00-17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetPeerSchedule(p, sched)
+
+	var result string
+	g.SetDecisionCallback(func(_, r string) { result = r })
+
+	cm := testConnMultiaddrs()
+	g.clock = clock.NewFake(time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)) // Wednesday evening
+	if g.InterceptSecured(network.DirInbound, p, cm) {
+		t.Error("peer outside its schedule should be denied")
+	}
+	if result != "deny" {
+		t.Errorf("decision = %q, want deny", result)
+	}
+
+	g.clock = clock.NewFake(time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC)) // Saturday
+	if g.InterceptSecured(network.DirInbound, p, cm) {
+		t.Error("peer should be denied on a day outside its schedule")
+	}
+
+	// Clearing the schedule lifts the restriction.
+	g.SetPeerSchedule(p, nil)
+	if !g.InterceptSecured(network.DirInbound, p, cm) {
+		t.Error("peer should be allowed once its schedule is cleared")
+	}
+}
+
+func TestIsAuthorizedHonorsSchedule(t *testing.T) {
+	p, other := genPeerID(t), genPeerID(t)
+	g := NewAuthorizedPeerGater(map[peer.ID]bool{p: true, other: true})
+	sched, err := ParseSchedule("mon-fri@09:00-17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetPeerSchedule(p, sched)
+
+	inside := time.Date(2026, 3, 4, 16, 59, 0, 0, time.UTC) // Wednesday
+	g.clock = clock.NewFake(inside)
+	if !g.IsAuthorized(p) {
+		t.Fatal("peer inside its schedule should be authorized")
+	}
+	if got := g.ActivePeerIDs(); len(got) != 2 {
+		t.Errorf("ActivePeerIDs inside window = %v, want both peers", got)
+	}
+	if got := g.OutOfSchedulePeers(inside); len(got) != 0 {
+		t.Errorf("OutOfSchedulePeers inside window = %v, want none", got)
+	}
+
+	after := inside.Add(time.Minute)
+	g.clock = clock.NewFake(after)
+	if g.IsAuthorized(p) {
+		t.Error("peer outside its schedule should not be authorized")
+	}
+	if got := g.ActivePeerIDs(); len(got) != 1 || got[0] != other {
+		t.Errorf("ActivePeerIDs after window = %v, want only the unscheduled peer", got)
+	}
+	if got := g.OutOfSchedulePeers(after); len(got) != 1 || got[0] != p {
+		t.Errorf("OutOfSchedulePeers after window = %v, want [%s]", got, p)
+	}
+}
+
+func TestKnownPeerDecisionCallback(t *testing.T) {
+	known, unknown := genPeerID(t), genPeerID(t)
+	g := NewAuthorizedPeerGater(map[peer.ID]bool{known: true})
+
+	var all int
+	var got []peer.ID
+	g.SetDecisionCallback(func(_, _ string) { all++ })
+	g.SetKnownPeerDecisionCallback(func(p peer.ID, _ string) { got = append(got, p) })
+
+	g.InterceptSecured(network.DirInbound, known, testConnMultiaddrs())
+	g.InterceptSecured(network.DirInbound, unknown, testConnMultiaddrs())
+
+	if all != 2 {
+		t.Errorf("decision callback fired %d times, want 2", all)
+	}
+	if len(got) != 1 || got[0] != known {
+		t.Errorf("known-peer callback got %v, want only %s", got, known)
+	}
+}
+
 // --- Per-IP cooldown tests ---
 
 func TestProbationIPCooldown(t *testing.T) {
@@ -23,6 +23,10 @@ type PeerEntry struct {
 	Verified  string    // empty = unverified, otherwise fingerprint prefix
 	Group     string    // pairing group ID (empty = manually added or invited)
 	Role      string    // "admin" or "member" (empty = member, backward compatible)
+	Schedule  *Schedule // nil = may connect at any time
+	// Provisional marks a peer introduced through peer-notify that has not
+	// yet proven it holds its key (see MarkProvisional).
+	Provisional bool
 }
 
 // maxCommentLen is the maximum length for a peer comment in authorized_keys.
@@ -311,7 +315,8 @@ func AddPeer(authKeysPath, peerIDStr, comment string) error {
 
 	// Check for duplicates if file exists
 	if _, err := os.Stat(authKeysPath); err == nil {
-		existing, err := LoadAuthorizedKeys(authKeysPath)
+		// Expired entries still occupy their line until pruned.
+		existing, err := loadAuthorizedKeys(authKeysPath, time.Time{})
 		if err != nil {
 			return fmt.Errorf("failed to read existing file: %w", err)
 		}
@@ -397,6 +402,28 @@ func RemovePeer(authKeysPath, peerIDStr string) error {
 	return atomicWriteLines(authKeysPath, newLines)
 }
 
+// RemoveExpiredPeers removes every peer whose expires attribute is before
+// now and returns the removed peer IDs. Peers without an expires attribute
+// are never removed.
+func RemoveExpiredPeers(authKeysPath string, now time.Time) ([]peer.ID, error) {
+	entries, err := ListPeers(authKeysPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []peer.ID
+	for _, e := range entries {
+		if e.ExpiresAt.IsZero() || !now.After(e.ExpiresAt) {
+			continue
+		}
+		if err := RemovePeer(authKeysPath, e.PeerID.String()); err != nil {
+			return removed, fmt.Errorf("failed to remove expired peer %s: %w", e.PeerID.String()[:16]+"...", err)
+		}
+		removed = append(removed, e.PeerID)
+	}
+	return removed, nil
+}
+
 // ListPeers reads the authorized_keys file and returns all peer entries
 // including attributes (expires, verified).
 func ListPeers(authKeysPath string) ([]PeerEntry, error) {
@@ -439,6 +466,18 @@ func ListPeers(authKeysPath string) ([]PeerEntry, error) {
 		if v, ok := attrs["role"]; ok {
 			entry.Role = v
 		}
+		if attrs["provisional"] == "true" {
+			entry.Provisional = true
+		}
+		if v, ok := attrs["schedule"]; ok {
+			sched, err := ParseSchedule(v)
+			if err != nil {
+				// Fail closed: a schedule that can't be read allows nothing.
+				slog.Warn("authorized_keys: invalid schedule, peer denied until fixed", "peer", pidStr, "error", err)
+				sched = &Schedule{raw: v}
+			}
+			entry.Schedule = sched
+		}
 		entries = append(entries, entry)
 	}
 
@@ -449,6 +488,45 @@ func ListPeers(authKeysPath string) ([]PeerEntry, error) {
 	return entries, nil
 }
 
+// PeerFilter selects entries from ListPeers. The zero value matches every
+// peer.
+type PeerFilter struct {
+	Group           *string // nil = any group; "" = only peers with no group
+	CommentContains string  // case-insensitive substring of the comment
+}
+
+// Match reports whether e passes the filter.
+func (f PeerFilter) Match(e PeerEntry) bool {
+	if f.Group != nil && e.Group != *f.Group {
+		return false
+	}
+	if f.CommentContains != "" && !strings.Contains(strings.ToLower(e.Comment), strings.ToLower(f.CommentContains)) {
+		return false
+	}
+	return true
+}
+
+// FilterPeers returns the entries that pass f, in their original order.
+func FilterPeers(entries []PeerEntry, f PeerFilter) []PeerEntry {
+	var out []PeerEntry
+	for _, e := range entries {
+		if f.Match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// GroupCounts returns how many entries belong to each group. Peers with no
+// group are counted under "".
+func GroupCounts(entries []PeerEntry) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		counts[e.Group]++
+	}
+	return counts
+}
+
 // PeerComment returns the comment (friendly name) for a peer in authorized_keys.
 // Returns empty string if the peer is not found or on error.
 func PeerComment(authKeysPath string, peerID peer.ID) string {
@@ -464,6 +542,36 @@ func PeerComment(authKeysPath string, peerID peer.ID) string {
 	return ""
 }
 
+// IsPeerVerified reports whether a peer in authorized_keys carries a
+// verified= attribute (set by 'shurli verify'). The file is read on each
+// call so a fresh verification takes effect without a reload. Returns
+// false if the peer is not found or on error.
+func IsPeerVerified(authKeysPath string, peerID peer.ID) bool {
+	return GetPeerAttr(authKeysPath, peerID.String(), "verified") != ""
+}
+
+// MarkProvisional flags a peer as provisionally authorized until the given
+// time: it may connect, but the expires attribute removes it then unless
+// PromotePeer is called first.
+func MarkProvisional(authKeysPath, peerIDStr string, until time.Time) error {
+	if err := SetPeerAttr(authKeysPath, peerIDStr, "expires", until.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	return SetPeerAttr(authKeysPath, peerIDStr, "provisional", "true")
+}
+
+// PromotePeer makes a provisional authorization permanent by clearing the
+// provisional flag and the expiry set with it. Peers that are not
+// provisional are left alone, so an expiry from 'auth add --ttl' stays.
+func PromotePeer(authKeysPath, peerIDStr string) error {
+	if GetPeerAttr(authKeysPath, peerIDStr, "provisional") == "" {
+		return nil
+	}
+	if err := SetPeerAttr(authKeysPath, peerIDStr, "expires", ""); err != nil {
+		return err
+	}
+	return SetPeerAttr(authKeysPath, peerIDStr, "provisional", "")
+}
 
 // GetPeerAttr returns the value of a specific attribute for a peer.
 // Returns empty string if the peer or attribute is not found.
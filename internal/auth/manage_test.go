@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAddPeer(t *testing.T) {
@@ -137,6 +138,113 @@ func TestListPeers(t *testing.T) {
 	}
 }
 
+func TestIsPeerVerified(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+
+	verified := genPeerID(t)
+	unverified := genPeerID(t)
+	AddPeer(path, verified.String(), "alpha")
+	AddPeer(path, unverified.String(), "beta")
+	if err := SetPeerAttr(path, verified.String(), "verified", "sha256:abcd1234"); err != nil {
+		t.Fatalf("SetPeerAttr: %v", err)
+	}
+
+	if !IsPeerVerified(path, verified) {
+		t.Error("verified peer reported as unverified")
+	}
+	if IsPeerVerified(path, unverified) {
+		t.Error("unverified peer reported as verified")
+	}
+	if IsPeerVerified(filepath.Join(dir, "missing"), verified) {
+		t.Error("missing file should report unverified")
+	}
+}
+
+func TestRemoveExpiredPeers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+
+	expired := genPeerIDStr(t)
+	future := genPeerIDStr(t)
+	forever := genPeerIDStr(t)
+	AddPeer(path, expired, "guest")
+	AddPeer(path, future, "visitor")
+	AddPeer(path, forever, "home")
+
+	now := time.Now()
+	if err := SetPeerAttr(path, expired, "expires", now.Add(-time.Minute).UTC().Format(time.RFC3339)); err != nil {
+		t.Fatalf("SetPeerAttr: %v", err)
+	}
+	if err := SetPeerAttr(path, future, "expires", now.Add(time.Hour).UTC().Format(time.RFC3339)); err != nil {
+		t.Fatalf("SetPeerAttr: %v", err)
+	}
+
+	removed, err := RemoveExpiredPeers(path, now)
+	if err != nil {
+		t.Fatalf("RemoveExpiredPeers: %v", err)
+	}
+	if len(removed) != 1 || removed[0].String() != expired {
+		t.Fatalf("removed = %v, want only %s", removed, expired)
+	}
+
+	entries, _ := ListPeers(path)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 remaining entries, got %d", len(entries))
+	}
+
+	// Far in the future, the TTL peer expires but the peer without a TTL
+	// is never auto-removed.
+	removed, err = RemoveExpiredPeers(path, now.Add(100*365*24*time.Hour))
+	if err != nil {
+		t.Fatalf("RemoveExpiredPeers: %v", err)
+	}
+	if len(removed) != 1 || removed[0].String() != future {
+		t.Fatalf("removed = %v, want only %s", removed, future)
+	}
+	entries, _ = ListPeers(path)
+	if len(entries) != 1 || entries[0].PeerID.String() != forever {
+		t.Fatalf("remaining = %v, want only the peer without a TTL", entries)
+	}
+}
+
+func TestProvisionalPeers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+
+	introduced := genPeerIDStr(t)
+	guest := genPeerIDStr(t)
+	AddPeer(path, introduced, "laptop")
+	AddPeer(path, guest, "guest")
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if err := MarkProvisional(path, introduced, until); err != nil {
+		t.Fatalf("MarkProvisional: %v", err)
+	}
+	if err := SetPeerAttr(path, guest, "expires", until.Format(time.RFC3339)); err != nil {
+		t.Fatalf("SetPeerAttr: %v", err)
+	}
+
+	entries, _ := ListPeers(path)
+	if !entries[0].Provisional || !entries[0].ExpiresAt.Equal(until) {
+		t.Errorf("after MarkProvisional: %+v", entries[0])
+	}
+
+	// Promotion clears the provisional expiry but not an unrelated TTL.
+	for _, p := range []string{introduced, guest} {
+		if err := PromotePeer(path, p); err != nil {
+			t.Fatalf("PromotePeer: %v", err)
+		}
+	}
+	entries, _ = ListPeers(path)
+	if entries[0].Provisional || !entries[0].ExpiresAt.IsZero() {
+		t.Errorf("promoted peer still provisional: %+v", entries[0])
+	}
+	if !entries[1].ExpiresAt.Equal(until) {
+		t.Errorf("PromotePeer cleared a --ttl expiry: %+v", entries[1])
+	}
+}
+
 func TestListPeersMissingFile(t *testing.T) {
 	entries, err := ListPeers("/nonexistent/authorized_keys")
 	if err != nil {
@@ -291,3 +399,51 @@ func TestWriteFilePreserveOwnership(t *testing.T) {
 		t.Errorf("content = %q, want %q", data2, "new")
 	}
 }
+
+func TestFilterPeers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+	ids := []string{genPeerIDStr(t), genPeerIDStr(t), genPeerIDStr(t), genPeerIDStr(t)}
+	content := ids[0] + " group=family # Mum's Laptop\n" +
+		ids[1] + " group=family # phone\n" +
+		ids[2] + " group=work # work laptop\n" +
+		ids[3] + " # nas\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ListPeers(path)
+	if err != nil {
+		t.Fatalf("ListPeers: %v", err)
+	}
+
+	family, none := "family", ""
+	tests := []struct {
+		name   string
+		filter PeerFilter
+		want   []string
+	}{
+		{"zero filter", PeerFilter{}, ids},
+		{"group", PeerFilter{Group: &family}, ids[:2]},
+		{"ungrouped", PeerFilter{Group: &none}, ids[3:]},
+		{"comment case-insensitive", PeerFilter{CommentContains: "LAPTOP"}, []string{ids[0], ids[2]}},
+		{"group and comment", PeerFilter{Group: &family, CommentContains: "laptop"}, ids[:1]},
+		{"no match", PeerFilter{CommentContains: "tablet"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterPeers(entries, tt.filter)
+			var gotIDs []string
+			for _, e := range got {
+				gotIDs = append(gotIDs, e.PeerID.String())
+			}
+			if strings.Join(gotIDs, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", gotIDs, tt.want)
+			}
+		})
+	}
+
+	counts := GroupCounts(entries)
+	if counts["family"] != 2 || counts["work"] != 1 || counts[""] != 1 || len(counts) != 3 {
+		t.Errorf("GroupCounts = %v", counts)
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// CheckAuthorizedKeysPermissions verifies that an authorized_keys file is
+// not writable by group or others. It lists public peer IDs only, so 0644
+// is fine, but anyone who can write it can authorize themselves.
+func CheckAuthorizedKeysPermissions(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil // Windows file permissions work differently
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot stat authorized_keys file %s: %w", path, err)
+	}
+	mode := info.Mode().Perm()
+	if mode&0022 != 0 {
+		return fmt.Errorf("authorized_keys file %s has insecure permissions %04o (expected 0600 or 0644); fix with: chmod 600 %s", path, mode, path)
+	}
+	return nil
+}
+
+// FixAuthorizedKeysPermissions clears the group and other write bits on an
+// authorized_keys file, keeping read access as it was (0666 becomes 0644).
+// It reports whether the mode was changed.
+func FixAuthorizedKeysPermissions(path string) (bool, error) {
+	if runtime.GOOS == "windows" {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("cannot stat authorized_keys file %s: %w", path, err)
+	}
+	mode := info.Mode().Perm()
+	if mode&0022 == 0 {
+		return false, nil
+	}
+	if err := os.Chmod(path, mode&^0022); err != nil {
+		return false, fmt.Errorf("chmod authorized_keys file %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAuthorizedKeysPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions not applicable on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "authorized_keys")
+	if err := os.WriteFile(path, []byte(genPeerIDStr(t)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range []os.FileMode{0600, 0644} {
+		os.Chmod(path, mode)
+		if err := CheckAuthorizedKeysPermissions(path); err != nil {
+			t.Errorf("%04o should pass: %v", mode, err)
+		}
+		if fixed, err := FixAuthorizedKeysPermissions(path); fixed || err != nil {
+			t.Errorf("%04o: fix = %v, %v; want no change", mode, fixed, err)
+		}
+	}
+
+	os.Chmod(path, 0666)
+	if err := CheckAuthorizedKeysPermissions(path); err == nil {
+		t.Fatal("0666 should fail")
+	}
+	fixed, err := FixAuthorizedKeysPermissions(path)
+	if err != nil || !fixed {
+		t.Fatalf("fix = %v, %v; want fixed", fixed, err)
+	}
+	info, _ := os.Stat(path)
+	if got := info.Mode().Perm(); got != 0644 {
+		t.Errorf("mode after fix = %04o, want 0644", got)
+	}
+	if err := CheckAuthorizedKeysPermissions(path); err != nil {
+		t.Errorf("after fix: %v", err)
+	}
+}
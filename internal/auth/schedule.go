@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule restricts when an authorized peer may connect. It is stored as
+// the schedule attribute in authorized_keys: a comma-separated list of
+// windows, each "[days@]HH:MM-HH:MM" in the node's local time, e.g.
+//
+//	schedule=mon-fri@09:00-17:00
+//	schedule=mon-fri@08:00-12:00,mon-fri@13:00-18:00,sat@10:00-14:00
+//	schedule=22:00-06:00
+//
+// Days are mon..sun, as a single day, a range (mon-fri, fri-mon) or a
+// "+"-separated list (mon+wed+fri); no days means every day. A window whose
+// end is before its start runs past midnight and belongs to its start day.
+type Schedule struct {
+	raw     string
+	windows []scheduleWindow
+}
+
+type scheduleWindow struct {
+	days       [7]bool // indexed by time.Weekday
+	start, end int     // minutes since midnight
+}
+
+var scheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseSchedule parses a schedule attribute value.
+func ParseSchedule(s string) (*Schedule, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return nil, fmt.Errorf("empty schedule")
+	}
+	sched := &Schedule{raw: s}
+	for _, part := range strings.Split(s, ",") {
+		w, err := parseScheduleWindow(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule window %q: %w", part, err)
+		}
+		sched.windows = append(sched.windows, w)
+	}
+	return sched, nil
+}
+
+func parseScheduleWindow(s string) (scheduleWindow, error) {
+	var w scheduleWindow
+	days, times, hasDays := strings.Cut(s, "@")
+	if !hasDays {
+		times = days
+		for d := range w.days {
+			w.days[d] = true
+		}
+	} else {
+		for _, spec := range strings.Split(days, "+") {
+			if err := w.addDays(spec); err != nil {
+				return w, err
+			}
+		}
+	}
+
+	from, to, ok := strings.Cut(times, "-")
+	if !ok {
+		return w, fmt.Errorf("want HH:MM-HH:MM")
+	}
+	var err error
+	if w.start, err = parseClock(from); err != nil {
+		return w, err
+	}
+	if w.end, err = parseClock(to); err != nil {
+		return w, err
+	}
+	if w.start == w.end {
+		return w, fmt.Errorf("window is empty")
+	}
+	return w, nil
+}
+
+// addDays marks a single day or a day range such as mon-fri (wrapping
+// ranges like fri-mon are allowed).
+func (w *scheduleWindow) addDays(spec string) error {
+	first, last, isRange := strings.Cut(spec, "-")
+	from, ok := scheduleDays[first]
+	if !ok {
+		return fmt.Errorf("unknown day %q", first)
+	}
+	to := from
+	if isRange {
+		if to, ok = scheduleDays[last]; !ok {
+			return fmt.Errorf("unknown day %q", last)
+		}
+	}
+	for d := from; ; d = (d + 1) % 7 {
+		w.days[d] = true
+		if d == to {
+			return nil
+		}
+	}
+}
+
+// parseClock parses HH:MM (00:00-24:00) into minutes since midnight.
+func parseClock(s string) (int, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// Allows reports whether t falls inside any window, in t's location.
+func (s *Schedule) Allows(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+	for _, w := range s.windows {
+		if w.start < w.end {
+			if w.days[today] && minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+		// Overnight window: the evening part today, or the morning part
+		// of a window that started yesterday.
+		if (w.days[today] && minute >= w.start) || (w.days[yesterday] && minute < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the schedule as written in authorized_keys.
+func (s *Schedule) String() string {
+	return s.raw
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"09:00",
+		"9:00-17:00",
+		"09:00-25:00",
+		"09:60-17:00",
+		"10:00-10:00",
+		"funday@09:00-17:00",
+		"mon-xyz@09:00-17:00",
+		"mon-fri@09:00-17:00,",
+	} {
+		if _, err := ParseSchedule(s); err == nil {
+			t.Errorf("ParseSchedule(%q) should fail", s)
+		}
+	}
+}
+
+func TestScheduleAllows(t *testing.T) {
+	// 2026-03-02 is a Monday.
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 3, 2+day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		sched string
+		t     time.Time
+		want  bool
+	}{
+		{"mon-fri@09:00-17:00", at(0, 9, 0), true},
+		{"mon-fri@09:00-17:00", at(4, 16, 59), true},
+		{"mon-fri@09:00-17:00", at(4, 17, 0), false},
+		{"mon-fri@09:00-17:00", at(5, 12, 0), false}, // Saturday
+		{"09:00-17:00", at(6, 12, 0), true},          // no days = every day
+		{"fri-mon@10:00-12:00", at(6, 11, 0), true},  // wrapping day range
+		{"fri-mon@10:00-12:00", at(2, 11, 0), false},
+		{"mon+wed@10:00-12:00", at(2, 11, 0), true},
+		{"mon+wed@10:00-12:00", at(1, 11, 0), false},
+		{"mon-fri@08:00-12:00,sat@10:00-14:00", at(5, 13, 0), true},
+		// Overnight windows belong to their start day.
+		{"fri@22:00-06:00", at(4, 23, 0), true},
+		{"fri@22:00-06:00", at(5, 5, 59), true},
+		{"fri@22:00-06:00", at(5, 6, 0), false},
+		{"fri@22:00-06:00", at(3, 23, 0), false},
+		{"fri@22:00-06:00", at(4, 5, 0), false},
+		{"12:00-24:00", at(0, 23, 59), true},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.sched)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tt.sched, err)
+		}
+		if got := s.Allows(tt.t); got != tt.want {
+			t.Errorf("%q.Allows(%s) = %v, want %v", tt.sched, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestListPeersSchedule(t *testing.T) {
+	path := t.TempDir() + "/authorized_keys"
+	p := genPeerID(t)
+	if err := AddPeer(path, p.String(), "office"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPeerAttr(path, p.String(), "schedule", "mon-fri@09:00-17:00"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ListPeers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Schedule == nil {
+		t.Fatalf("expected one peer with a schedule, got %+v", entries)
+	}
+	if got := entries[0].Schedule.String(); got != "mon-fri@09:00-17:00" {
+		t.Errorf("schedule = %q", got)
+	}
+}
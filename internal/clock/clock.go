@@ -0,0 +1,206 @@
+// Package clock abstracts time for subsystems whose behavior depends on it
+// (reconnect backoff, commit-confirmed deadlines, authorization TTLs), so
+// tests can fast-forward through hours of backoff or expiry without
+// sleeping.
+//
+// Production code uses Real. Tests use a Fake and move it with Advance,
+// which fires any timers and tickers that come due.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates timers and tickers.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the part of *time.Timer that callers use.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker is the part of *time.Ticker that callers use.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the system clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) NewTimer(d time.Duration) Timer  { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct{ t *time.Timer }
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+func (r realTimer) Stop() bool          { return r.t.Stop() }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+// Fake is a manually driven clock. Its time only moves on Advance. Timers
+// and tickers created from it fire during Advance when their deadline is
+// reached; like the real ones, their channels hold one pending tick and
+// drop the rest.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// NewFake returns a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTimer creates a timer firing once d after the current fake time.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return fakeTimer{f.addWaiter(d, 0)}
+}
+
+// NewTicker creates a ticker firing every d of fake time. d must be
+// positive, as for time.NewTicker.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.addWaiter(d, d)}
+}
+
+// Advance moves the fake time forward by d, firing every timer and ticker
+// that comes due, in deadline order. A ticker passed over several periods
+// fires once per period, but its channel keeps only the first undelivered
+// tick.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		w := f.nextDueLocked(end)
+		if w == nil {
+			break
+		}
+		f.now = w.deadline
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			w.stopped = true
+		}
+	}
+	f.now = end
+	f.pruneLocked()
+}
+
+// Waiters returns the number of active timers and tickers. Tests use it to
+// wait until the code under test has created its timer before advancing.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pruneLocked()
+	return len(f.waiters)
+}
+
+func (f *Fake) addWaiter(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{
+		clock:    f,
+		deadline: f.now.Add(d),
+		period:   period,
+		ch:       make(chan time.Time, 1),
+	}
+	if d <= 0 && period == 0 {
+		// Like time.NewTimer: a non-positive duration fires at once.
+		w.ch <- f.now
+		w.stopped = true
+		return w
+	}
+	f.waiters = append(f.waiters, w)
+	return w
+}
+
+// nextDueLocked returns the active waiter with the earliest deadline at or
+// before end, or nil.
+func (f *Fake) nextDueLocked(end time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range f.waiters {
+		if w.stopped || w.deadline.After(end) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+func (f *Fake) pruneLocked() {
+	live := f.waiters[:0]
+	for _, w := range f.waiters {
+		if !w.stopped {
+			live = append(live, w)
+		}
+	}
+	clear(f.waiters[len(live):])
+	f.waiters = live
+}
+
+// fakeWaiter is a Fake timer (period 0) or ticker.
+type fakeWaiter struct {
+	clock    *Fake
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	stopped  bool // guarded by clock.mu
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.ch }
+
+// stop deactivates the waiter and reports whether it was still active.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	active := !w.stopped
+	w.stopped = true
+	return active
+}
+
+type fakeTimer struct{ *fakeWaiter }
+
+func (t fakeTimer) Stop() bool { return t.stop() }
+
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.stop() }
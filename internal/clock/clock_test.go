@@ -0,0 +1,120 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeNowAndSince(t *testing.T) {
+	f := NewFake(start)
+	if !f.Now().Equal(start) {
+		t.Fatalf("Now = %v, want %v", f.Now(), start)
+	}
+	f.Advance(90 * time.Minute)
+	if got := f.Since(start); got != 90*time.Minute {
+		t.Errorf("Since = %v, want 1h30m", got)
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(start)
+	timer := f.NewTimer(time.Hour)
+
+	f.Advance(59 * time.Minute)
+	if _, ok := fired(timer.C()); ok {
+		t.Fatal("timer fired before its deadline")
+	}
+	f.Advance(time.Minute)
+	got, ok := fired(timer.C())
+	if !ok || !got.Equal(start.Add(time.Hour)) {
+		t.Fatalf("timer fired = %v at %v, want true at %v", ok, got, start.Add(time.Hour))
+	}
+	if timer.Stop() {
+		t.Error("Stop after firing reported an active timer")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("Waiters = %d after the timer fired, want 0", f.Waiters())
+	}
+
+	stopped := f.NewTimer(time.Minute)
+	if !stopped.Stop() {
+		t.Error("Stop before firing reported an inactive timer")
+	}
+	f.Advance(time.Hour)
+	if _, ok := fired(stopped.C()); ok {
+		t.Error("stopped timer fired")
+	}
+
+	if _, ok := fired(f.NewTimer(0).C()); !ok {
+		t.Error("zero-duration timer did not fire at once")
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(start)
+	ticker := f.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		f.Advance(time.Minute)
+		got, ok := fired(ticker.C())
+		if want := start.Add(time.Duration(i) * time.Minute); !ok || !got.Equal(want) {
+			t.Fatalf("tick %d = %v (%v), want %v", i, got, ok, want)
+		}
+	}
+
+	// Skipping several periods leaves one pending tick, like time.Ticker.
+	f.Advance(10 * time.Minute)
+	if _, ok := fired(ticker.C()); !ok {
+		t.Fatal("no tick after a long advance")
+	}
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("more than one tick buffered")
+	}
+
+	ticker.Stop()
+	f.Advance(time.Hour)
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("stopped ticker ticked")
+	}
+}
+
+func TestFakeAdvanceFiresInOrder(t *testing.T) {
+	f := NewFake(start)
+	late := f.NewTimer(2 * time.Hour)
+	early := f.NewTimer(time.Hour)
+
+	f.Advance(3 * time.Hour)
+	e, _ := fired(early.C())
+	l, _ := fired(late.C())
+	if !e.Equal(start.Add(time.Hour)) || !l.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("fired at %v and %v, want each timer's own deadline", e, l)
+	}
+	if !f.Now().Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("Now = %v after advance", f.Now())
+	}
+}
+
+func TestRealClock(t *testing.T) {
+	before := time.Now()
+	if now := Real.Now(); now.Before(before) {
+		t.Errorf("Real.Now = %v, before %v", now, before)
+	}
+	timer := Real.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("real timer did not fire")
+	}
+}
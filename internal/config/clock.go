@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// minPlausibleTime is the earliest wall-clock time shurli trusts. A device
+// without a battery-backed RTC (e.g. a Raspberry Pi) boots near 1970 until
+// NTP syncs; wall-clock deadlines computed before that are meaningless.
+var minPlausibleTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ClockPlausible reports whether t looks like a real, synced wall-clock time.
+func ClockPlausible(t time.Time) bool {
+	return !t.Before(minPlausibleTime)
+}
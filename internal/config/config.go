@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,6 +32,8 @@ type HomeNodeConfig struct {
 	Plugins       PluginsConfig       `yaml:"plugins,omitempty"`
 	Grants        GrantsConfig        `yaml:"grants,omitempty"`
 	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+	Control       ControlConfig       `yaml:"control,omitempty"`
+	Hooks         []HookConfig        `yaml:"hooks,omitempty"`
 }
 
 // GrantsConfig holds per-peer data access grant settings.
@@ -303,6 +306,25 @@ type PeerRelayResourcesConfig struct {
 	CircuitDataLimit       string `yaml:"circuit_data_limit,omitempty"`        // default: "128KB"
 }
 
+// ControlConfig holds limits for the daemon's local control API.
+type ControlConfig struct {
+	// MaxProxies caps concurrent ad-hoc proxies created with
+	// 'shurli daemon connect'. Persistent proxies (shurli proxy add) are not
+	// counted. 0 = DefaultMaxProxies.
+	MaxProxies int `yaml:"max_proxies,omitempty"`
+}
+
+// DefaultMaxProxies is the control.max_proxies limit when unset.
+const DefaultMaxProxies = 64
+
+// MaxProxiesOrDefault returns MaxProxies, or DefaultMaxProxies when unset.
+func (c *ControlConfig) MaxProxiesOrDefault() int {
+	if c.MaxProxies <= 0 {
+		return DefaultMaxProxies
+	}
+	return c.MaxProxies
+}
+
 // CLIConfig holds settings for CLI subcommand behavior.
 type CLIConfig struct {
 	// AllowStandalone permits subcommands (proxy, ping, traceroute) to create
@@ -341,6 +363,7 @@ type ClientNodeConfig struct {
 type RelayServerConfig struct {
 	Version   int                  `yaml:"version,omitempty"`
 	Name      string               `yaml:"name,omitempty"`
+	Region    string               `yaml:"region,omitempty"` // advertised location hint, e.g. "au-sydney"
 	Identity  IdentityConfig       `yaml:"identity"`
 	Network   RelayNetworkConfig   `yaml:"network"`
 	Discovery RelayDiscoveryConfig `yaml:"discovery,omitempty"`
@@ -354,7 +377,7 @@ type RelayServerConfig struct {
 
 // RelayLoggingConfig holds relay server logging settings.
 type RelayLoggingConfig struct {
-	PeerListInterval string `yaml:"peer_list_interval,omitempty"` // default: "60s" (was 15s)
+	PeerListInterval string `yaml:"peer_list_interval,omitempty" schema:"duration"` // default: "60s" (was 15s)
 }
 
 // PeerListIntervalDuration returns the peer list logging interval.
@@ -379,8 +402,9 @@ func (c *RelayLoggingConfig) PeerListIntervalDuration() time.Duration {
 // TelemetryConfig holds observability settings.
 // All features are disabled by default (opt-in).
 type TelemetryConfig struct {
-	Metrics MetricsConfig `yaml:"metrics,omitempty"`
-	Audit   AuditConfig   `yaml:"audit,omitempty"`
+	Metrics    MetricsConfig       `yaml:"metrics,omitempty"`
+	Audit      AuditConfig         `yaml:"audit,omitempty"`
+	OnIsolated IsolationHookConfig `yaml:"on_isolated,omitempty"`
 }
 
 // MetricsConfig controls Prometheus metrics exposure.
@@ -394,6 +418,33 @@ type AuditConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+// IsolationHookConfig is a dead-man's switch for unattended nodes: it fires
+// when the daemon has had no connected peers for After, and again when a
+// peer connects. Either or both of URL and Command may be set.
+type IsolationHookConfig struct {
+	After   time.Duration     `yaml:"after,omitempty"`   // default: 5m, minimum 30s
+	URL     string            `yaml:"url,omitempty"`     // POST a JSON event (http or https)
+	Headers map[string]string `yaml:"headers,omitempty"` // extra headers for URL, e.g. auth tokens
+	Command string            `yaml:"command,omitempty"` // run with SHURLI_EVENT=isolated|recovered
+}
+
+// DefaultIsolationAfter is how long the node must have no peers before the
+// on_isolated hook fires, when after is not set.
+const DefaultIsolationAfter = 5 * time.Minute
+
+// Enabled reports whether an isolation hook is configured.
+func (c IsolationHookConfig) Enabled() bool {
+	return c.URL != "" || c.Command != ""
+}
+
+// AfterOrDefault returns After, or DefaultIsolationAfter when unset.
+func (c IsolationHookConfig) AfterOrDefault() time.Duration {
+	if c.After == 0 {
+		return DefaultIsolationAfter
+	}
+	return c.After
+}
+
 // HealthConfig holds HTTP health check endpoint configuration.
 type HealthConfig struct {
 	Enabled       bool   `yaml:"enabled"`
@@ -402,21 +453,98 @@ type HealthConfig struct {
 
 // IdentityConfig holds identity-related configuration
 type IdentityConfig struct {
-	KeyFile string `yaml:"key_file"`
+	KeyFile string `yaml:"key_file" schema:"required"`
 }
 
 // NetworkConfig holds network-related configuration
 type NetworkConfig struct {
-	ListenAddresses          []string `yaml:"listen_addresses"`
+	ListenAddresses          []string `yaml:"listen_addresses" schema:"required,nonempty"`
 	ForcePrivateReachability bool     `yaml:"force_private_reachability"`
 	ForceCGNAT               bool     `yaml:"force_cgnat,omitempty"`
 	ResourceLimitsEnabled    bool     `yaml:"resource_limits_enabled"`
 	MemoryLimit              string   `yaml:"memory_limit,omitempty"` // systemd MemoryMax (e.g. "2G", "4G"). Default: 2G.
+	// KeepaliveInterval enables periodic pings to watched peers over direct
+	// connections to keep NAT mappings open. 0 (default) disables them.
+	KeepaliveInterval time.Duration `yaml:"keepalive_interval,omitempty"`
+	// IdleConnectionTimeout closes connections that carried no streams
+	// for this long, except to watched peers, relays and peers holding a
+	// reservation on this node. 0 (default) leaves idle connections to
+	// libp2p's connection manager.
+	IdleConnectionTimeout time.Duration `yaml:"idle_connection_timeout,omitempty"`
+	// QUIC tunes the QUIC transport for unusual links such as satellite.
+	// Zero values keep the libp2p defaults.
+	QUIC QUICConfig `yaml:"quic,omitempty"`
+	// Tor routes dials to /onion3 peer and relay addresses through a Tor
+	// SOCKS5 proxy. Unset (default) disables onion support.
+	Tor TorConfig `yaml:"tor,omitempty"`
+	// AdvertiseExclude lists address classes (AdvertiseAddrClasses) left
+	// out of the addresses this node advertises. Empty advertises all.
+	AdvertiseExclude []string `yaml:"advertise_exclude,omitempty"`
+	// STUNServers (host:port) replace the built-in public STUN servers
+	// used for NAT type detection. Empty keeps the defaults.
+	STUNServers []string `yaml:"stun_servers,omitempty"`
+	// DisableSTUN turns STUN probing off; NAT type is then reported as
+	// unknown.
+	DisableSTUN bool `yaml:"disable_stun,omitempty"`
+	// EnabledTransports (network.transports) limits the transports the
+	// host runs to these TransportNames. Empty enables all of them.
+	EnabledTransports []string `yaml:"transports,omitempty"`
+	// IPMode (network.ip_mode) restricts listening, dialing and advertising
+	// to one address family: IPModeDual (default), IPModeIPv6 or IPModeIPv4.
+	IPMode string `yaml:"ip_mode,omitempty"`
+}
+
+// AdvertiseAddrClasses are the address classes network.advertise_exclude
+// accepts. Global and relay circuit addresses are always advertised.
+var AdvertiseAddrClasses = []string{"loopback", "link-local", "private", "cgnat", "ula"}
+
+// QUICConfig tunes QUIC connections this node dials. Zero values keep the
+// libp2p defaults: 1280-byte initial packets, path MTU discovery on and a
+// 15s keepalive.
+type QUICConfig struct {
+	InitialPacketSize   uint16        `yaml:"initial_packet_size,omitempty"`   // 1200-1452 bytes
+	DisableMTUDiscovery bool          `yaml:"disable_mtu_discovery,omitempty"` // stay at initial_packet_size
+	KeepalivePeriod     time.Duration `yaml:"keepalive_period,omitempty"`      // 1s-15s
+}
+
+// TorConfig configures the optional Tor transport.
+type TorConfig struct {
+	SOCKSProxy string `yaml:"socks_proxy,omitempty"` // Tor SOCKS5 listener, e.g. "127.0.0.1:9050"
+}
+
+// Enabled reports whether a Tor SOCKS proxy is configured.
+func (t TorConfig) Enabled() bool {
+	return t.SOCKSProxy != ""
+}
+
+// Transports returns the multiaddr transports this node enables:
+// NodeTransports (narrowed by network.transports), plus onion3 when Tor is
+// configured.
+func (n NetworkConfig) Transports() []string {
+	enabled := NodeTransports
+	if len(n.EnabledTransports) > 0 {
+		enabled = nil
+		for _, t := range NodeTransports {
+			if n.TransportEnabled(transportGroup(t)) {
+				enabled = append(enabled, t)
+			}
+		}
+	}
+	if n.Tor.Enabled() {
+		return append(slices.Clone(enabled), TorTransport)
+	}
+	return enabled
+}
+
+// TransportEnabled reports whether the named transport (one of
+// TransportNames) is enabled by network.transports.
+func (n NetworkConfig) TransportEnabled(name string) bool {
+	return len(n.EnabledTransports) == 0 || slices.Contains(n.EnabledTransports, name)
 }
 
 // RelayNetworkConfig holds relay server network configuration
 type RelayNetworkConfig struct {
-	ListenAddresses []string `yaml:"listen_addresses"`
+	ListenAddresses []string `yaml:"listen_addresses" schema:"required,nonempty"`
 }
 
 // RelayConfig holds relay-related configuration
@@ -424,6 +552,78 @@ type RelayConfig struct {
 	Addresses           []string          `yaml:"addresses"`
 	ReservationInterval time.Duration     `yaml:"reservation_interval"`
 	Names               map[string]string `yaml:"names,omitempty"` // peer ID (or prefix) -> friendly name
+
+	// ReservationIntervals overrides ReservationInterval for individual
+	// relays, keyed like Names. Relays with long reservation TTLs can be
+	// refreshed less often, short-TTL ones more often.
+	ReservationIntervals map[string]time.Duration `yaml:"reservation_intervals,omitempty"`
+
+	// Enabled turns relay usage off for direct-only networks (LAN-only or
+	// every node on a public IP): no relay connections, reservations or
+	// AutoRelay. nil = enabled (default).
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// StartupWait is how long daemon startup waits for AutoRelay
+	// reservations before checking them. 0 skips the wait and makes any
+	// missing reservations in the background. nil = DefaultRelayStartupWait.
+	StartupWait *time.Duration `yaml:"startup_wait,omitempty"`
+
+	// PreferredRegion makes relays that advertise this region (relay
+	// server config "region") race first when dialing peers through
+	// relays. Empty = no preference.
+	PreferredRegion string `yaml:"preferred_region,omitempty"`
+
+	// PreferQUIC dials a relay's QUIC addresses on their own before its
+	// other addresses, so relay connections (and the circuits over them)
+	// use QUIC when both ends support it. TCP remains the fallback.
+	PreferQUIC bool `yaml:"prefer_quic,omitempty"`
+}
+
+// DefaultRelayStartupWait is the startup wait for relay reservations when
+// relay.startup_wait is not set.
+const DefaultRelayStartupWait = 5 * time.Second
+
+// StartupWaitOrDefault returns StartupWait, or DefaultRelayStartupWait
+// when it is not set.
+func (rc *RelayConfig) StartupWaitOrDefault() time.Duration {
+	if rc.StartupWait == nil {
+		return DefaultRelayStartupWait
+	}
+	return *rc.StartupWait
+}
+
+// IsEnabled returns whether relays are used. Defaults to true if not set.
+func (rc *RelayConfig) IsEnabled() bool {
+	if rc.Enabled == nil {
+		return true
+	}
+	return *rc.Enabled
+}
+
+// ActiveAddresses returns the relay addresses the node should use: the
+// configured addresses, or nil when relays are disabled. Runtime code
+// (connections, reservations, AutoRelay) should read this rather than
+// Addresses, so a disabled relay section can keep its addresses for later.
+func (rc *RelayConfig) ActiveAddresses() []string {
+	if !rc.IsEnabled() {
+		return nil
+	}
+	return rc.Addresses
+}
+
+// ReservationIntervalFor returns how often to refresh the reservation on a
+// relay: its reservation_intervals entry (full peer ID first, then prefix
+// of at least 8 chars), or the global ReservationInterval.
+func (rc *RelayConfig) ReservationIntervalFor(peerID string) time.Duration {
+	if d, ok := rc.ReservationIntervals[peerID]; ok {
+		return d
+	}
+	for prefix, d := range rc.ReservationIntervals {
+		if len(prefix) >= 8 && strings.HasPrefix(peerID, prefix) {
+			return d
+		}
+	}
+	return rc.ReservationInterval
 }
 
 // RelayName returns the friendly name for a relay peer ID.
@@ -448,13 +648,35 @@ func (rc *RelayConfig) RelayName(peerID string) string {
 
 // DiscoveryConfig holds DHT discovery configuration
 type DiscoveryConfig struct {
-	Rendezvous       string        `yaml:"rendezvous"`
+	Rendezvous       string        `yaml:"rendezvous" schema:"required"`
+	Channels         []string      `yaml:"channels,omitempty"`           // extra rendezvous strings, e.g. one per group; same DHT namespace
 	Network          string        `yaml:"network,omitempty"`            // DHT namespace for private networks (empty = global)
 	BootstrapPeers   []string      `yaml:"bootstrap_peers"`
 	DNSSeedDomain    string        `yaml:"dns_seed_domain,omitempty"`   // DNS seed domain (default: seeds.shurli.io)
 	MDNSEnabled      *bool         `yaml:"mdns_enabled,omitempty"`      // LAN peer discovery (default: true)
 	NetIntelEnabled  *bool         `yaml:"net_intel_enabled,omitempty"` // Presence announcements (default: true)
 	AnnounceInterval time.Duration `yaml:"announce_interval,omitempty"` // How often to push state (default: 5m)
+	DisconnectGrace  time.Duration `yaml:"disconnect_grace,omitempty"`  // Debounce before a watched peer counts as disconnected (default: 5s)
+	RequireBootstrap bool          `yaml:"require_bootstrap,omitempty"` // Fail startup when no bootstrap/relay peer is reachable (default: false)
+	// ReconnectLogBurst is how many identical consecutive reconnect
+	// failures per peer are logged before switching to one line per 15m
+	// (default: 3).
+	ReconnectLogBurst int `yaml:"reconnect_log_burst,omitempty"`
+	// PeerCacheTTL is how long peer addresses found through the DHT are
+	// kept in peer_cache.json and tried first on the next dial (default: 1h).
+	PeerCacheTTL time.Duration `yaml:"peer_cache_ttl,omitempty"`
+}
+
+// RendezvousList returns the main rendezvous followed by the channels,
+// without duplicates or empty entries.
+func (d *DiscoveryConfig) RendezvousList() []string {
+	var out []string
+	for _, r := range append([]string{d.Rendezvous}, d.Channels...) {
+		if r != "" && !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	return out
 }
 
 // IsMDNSEnabled returns whether mDNS local discovery is enabled.
@@ -486,6 +708,20 @@ type SecurityConfig struct {
 	EnableConnectionGating bool      `yaml:"enable_connection_gating"`
 	InvitePolicy           string    `yaml:"invite_policy,omitempty"` // "admin-only" (default) or "open"
 	ZKP                    ZKPConfig `yaml:"zkp,omitempty"`
+
+	// UserAgentPolicy disconnects peers whose identify agent version does
+	// not match. Advisory only: agent strings are self-reported.
+	UserAgentPolicy UserAgentPolicy `yaml:"user_agent_policy,omitempty"`
+
+	// AdminPeers lists the peer IDs allowed to drive this node's daemon
+	// API remotely over /shurli/admin/1.0.0 ("shurli remote"). Peer IDs,
+	// not names, so editing the names map can never grant admin access.
+	AdminPeers []string `yaml:"admin_peers,omitempty"`
+
+	// IntroductionChallenge keeps peers introduced by a relay
+	// (peer-notify) provisional, expiring after 24h, until they sign a
+	// random nonce with the key behind their peer ID.
+	IntroductionChallenge bool `yaml:"introduction_challenge,omitempty"`
 }
 
 // ZKPConfig holds zero-knowledge proof configuration.
@@ -505,6 +741,49 @@ type RelaySecurityConfig struct {
 	RequireTOTP            bool      `yaml:"require_totp,omitempty"`      // require TOTP for vault unseal
 	AutoSealMinutes        int       `yaml:"auto_seal_minutes,omitempty"` // auto-seal after N minutes (0 = disabled)
 	ZKP                    ZKPConfig `yaml:"zkp,omitempty"`
+
+	// MaxReservationsPerMinute refuses reservations from a peer that
+	// reserves more often than this (churn protection). 0 = default (10).
+	MaxReservationsPerMinute int `yaml:"max_reservations_per_minute,omitempty"`
+
+	// InviteClockSkew is how long past its expiry an invite is still
+	// accepted, to tolerate clock skew between the admin who minted it and
+	// this relay. Empty = no tolerance. Max 1h.
+	InviteClockSkew string `yaml:"invite_clock_skew,omitempty" schema:"duration"`
+
+	// UserAgentPolicy disconnects peers whose identify agent version does
+	// not match. Advisory only: agent strings are self-reported.
+	UserAgentPolicy UserAgentPolicy `yaml:"user_agent_policy,omitempty"`
+
+	// WatchAuthorizedKeys reloads authorized_keys when the file changes on
+	// disk (default: true). Only applies with connection gating enabled.
+	WatchAuthorizedKeys *bool `yaml:"watch_authorized_keys,omitempty"`
+}
+
+// IsWatchAuthorizedKeysEnabled returns whether authorized_keys is watched
+// for changes. Defaults to true when not explicitly set.
+func (c *RelaySecurityConfig) IsWatchAuthorizedKeysEnabled() bool {
+	if c.WatchAuthorizedKeys == nil {
+		return true
+	}
+	return *c.WatchAuthorizedKeys
+}
+
+// maxInviteClockSkew bounds security.invite_clock_skew: skew beyond this is
+// a broken clock to fix, not one to paper over.
+const maxInviteClockSkew = time.Hour
+
+// InviteClockSkewDuration returns the invite expiry tolerance. Invalid
+// values (rejected by ValidateRelayServerConfig) yield zero.
+func (c *RelaySecurityConfig) InviteClockSkewDuration() time.Duration {
+	if c.InviteClockSkew == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(c.InviteClockSkew)
+	if err != nil || d < 0 || d > maxInviteClockSkew {
+		return 0
+	}
+	return d
 }
 
 // RelayResourcesConfig holds relay v2 resource limit configuration.
@@ -515,9 +794,9 @@ type RelayResourcesConfig struct {
 	BufferSize           int    `yaml:"buffer_size"`              // default: 2048
 	MaxReservationsPerIP int    `yaml:"max_reservations_per_ip"`  // default: 8
 	MaxReservationsPerASN int   `yaml:"max_reservations_per_asn"` // default: 32
-	ReservationTTL       string `yaml:"reservation_ttl"`          // default: "1h"
-	SessionDuration      string `yaml:"session_duration"`         // default: "10m"
-	SessionDataLimit     string `yaml:"session_data_limit"`       // default: "64MB"
+	ReservationTTL       string `yaml:"reservation_ttl" schema:"duration"`    // default: "1h"
+	SessionDuration      string `yaml:"session_duration" schema:"duration"`   // default: "10m"
+	SessionDataLimit     string `yaml:"session_data_limit" schema:"datasize"` // default: "64MB"
 }
 
 // ProtocolsConfig holds protocol-specific configuration
@@ -528,7 +807,7 @@ type ProtocolsConfig struct {
 // PingPongConfig holds ping-pong protocol configuration
 type PingPongConfig struct {
 	Enabled bool   `yaml:"enabled"`
-	ID      string `yaml:"id"`
+	ID      string `yaml:"id" schema:"required"`
 }
 
 // ServicesConfig holds service exposure configuration
@@ -540,6 +819,36 @@ type ServiceConfig struct {
 	LocalAddress string   `yaml:"local_address"`
 	Protocol     string   `yaml:"protocol,omitempty"`        // Optional custom protocol ID
 	AllowedPeers []string `yaml:"allowed_peers,omitempty"`   // Restrict to specific peer IDs (nil = all authorized peers)
+
+	// Network is the local_address socket type: "tcp" (default) or "udp".
+	// UDP services are reached with 'shurli daemon connect --proto udp'.
+	Network string `yaml:"network,omitempty"`
+
+	// MaxBandwidthMbps caps this service's proxy throughput per direction
+	// (node-side shaping, independent of relay limits). 0 = unlimited.
+	MaxBandwidthMbps float64 `yaml:"max_bandwidth_mbps,omitempty"`
+
+	// MaxConcurrent and RatePerMinute cap each remote peer's open streams
+	// and new streams per minute on this service. 0 = unlimited.
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
+	RatePerMinute int `yaml:"rate_per_minute,omitempty"`
+
+	// HTTPRoutes makes the service an HTTP reverse proxy that routes each
+	// request by path (and optionally Host) to one of several local
+	// backends. Mutually exclusive with LocalAddress.
+	HTTPRoutes []HTTPRouteConfig `yaml:"http_routes,omitempty"`
+
+	// RequireVerified restricts the service to peers verified with
+	// 'shurli verify'. Authorized but unverified peers are refused.
+	RequireVerified bool `yaml:"require_verified,omitempty"`
+}
+
+// HTTPRouteConfig is one route of an http_routes service.
+type HTTPRouteConfig struct {
+	Path        string `yaml:"path"`                   // URL path prefix, e.g. "/app1"
+	Host        string `yaml:"host,omitempty"`         // Optional Host header match
+	Backend     string `yaml:"backend"`                // Local backend host:port
+	StripPrefix bool   `yaml:"strip_prefix,omitempty"` // Remove path prefix before forwarding
 }
 
 // NamesConfig holds name resolution configuration
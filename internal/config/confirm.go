@@ -10,12 +10,19 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/shurlinet/shurli/internal/clock"
 )
 
 // pendingState is the JSON structure stored in the pending marker file.
 type pendingState struct {
 	Deadline   time.Time `json:"deadline"`
 	BackupFile string    `json:"backup"`
+
+	// Timeout is the confirm window requested at apply time. It caps the
+	// countdown when the wall clock has moved since the marker was written.
+	// Zero in markers written by older versions.
+	Timeout time.Duration `json:"timeout,omitempty"`
 }
 
 // PendingPath returns the commit-confirmed marker path for a config file.
@@ -60,6 +67,7 @@ func BeginCommitConfirmed(configPath string, timeout time.Duration) error {
 	state := pendingState{
 		Deadline:   time.Now().Add(timeout),
 		BackupFile: filepath.Base(backup),
+		Timeout:    timeout,
 	}
 	marker, err := json.Marshal(state)
 	if err != nil {
@@ -151,6 +159,42 @@ func CheckPending(configPath string) (time.Time, error) {
 	return state.Deadline, nil
 }
 
+// CommitConfirmedRemaining returns how long the pending commit-confirmed on
+// configPath has left before it reverts, given its wall-clock deadline.
+//
+// The deadline alone is not trusted: if the clock was stepped backward after
+// apply (or NTP fixed a bad clock), it could be hours or years away. The
+// countdown is therefore capped at the timeout recorded at apply time. If
+// now itself is implausible (clock not yet synced), the full timeout is
+// used. A non-empty warning describes any such correction.
+func CommitConfirmedRemaining(configPath string, deadline, now time.Time) (time.Duration, string) {
+	var timeout time.Duration
+	if data, err := os.ReadFile(PendingPath(configPath)); err == nil {
+		var state pendingState
+		if json.Unmarshal(data, &state) == nil {
+			timeout = state.Timeout
+		}
+	}
+	return commitConfirmedRemaining(deadline, timeout, now)
+}
+
+func commitConfirmedRemaining(deadline time.Time, timeout time.Duration, now time.Time) (time.Duration, string) {
+	if !ClockPlausible(now) {
+		if timeout > 0 {
+			return timeout, fmt.Sprintf("system clock looks wrong (%s); counting down the full %s timeout instead of the recorded deadline",
+				now.UTC().Format(time.RFC3339), timeout)
+		}
+		return deadline.Sub(now), fmt.Sprintf("system clock looks wrong (%s); commit-confirmed deadline may be unreliable",
+			now.UTC().Format(time.RFC3339))
+	}
+	remaining := deadline.Sub(now)
+	if timeout > 0 && remaining > timeout {
+		return timeout, fmt.Sprintf("commit-confirmed deadline is %s away, longer than its %s timeout (clock moved back?); using the timeout",
+			remaining.Round(time.Second), timeout)
+	}
+	return remaining, ""
+}
+
 // revertPending restores the pre-confirmed backup over the current config
 // and removes the pending marker. Used by EnforceCommitConfirmed on timeout.
 func revertPending(configPath string) error {
@@ -192,9 +236,17 @@ func revertPending(configPath string) error {
 // it calls exitFunc to terminate the process (systemd will restart with
 // the restored config).
 //
-// Pass os.Exit as exitFunc in production; use a custom function in tests.
-func EnforceCommitConfirmed(ctx context.Context, configPath string, deadline time.Time, exitFunc func(int)) {
-	remaining := time.Until(deadline)
+// The deadline is converted to a countdown once, at start; the timer itself
+// runs on the monotonic clock, so later wall-clock steps (NTP sync on a
+// device without an RTC) neither fire it early nor delay it.
+//
+// Pass clock.Real and os.Exit in production; tests use a clock.Fake to
+// reach the deadline without waiting and a custom exitFunc.
+func EnforceCommitConfirmed(ctx context.Context, clk clock.Clock, configPath string, deadline time.Time, exitFunc func(int)) {
+	remaining, warning := CommitConfirmedRemaining(configPath, deadline, clk.Now())
+	if warning != "" {
+		slog.Warn(warning, "config", configPath)
+	}
 	if remaining <= 0 {
 		slog.Warn("commit-confirmed deadline already passed, reverting config",
 			"config", configPath)
@@ -209,14 +261,14 @@ func EnforceCommitConfirmed(ctx context.Context, configPath string, deadline tim
 		"deadline", deadline.Format(time.RFC3339),
 		"remaining", remaining.Round(time.Second))
 
-	timer := time.NewTimer(remaining)
+	timer := clk.NewTimer(remaining)
 	defer timer.Stop()
 
 	select {
 	case <-ctx.Done():
 		// Context cancelled (shutdown or confirm called) - nothing to do
 		return
-	case <-timer.C:
+	case <-timer.C():
 		slog.Warn("commit-confirmed timeout - reverting config",
 			"config", configPath,
 			"deadline", deadline.Format(time.RFC3339))
@@ -229,8 +281,11 @@ func EnforceCommitConfirmed(ctx context.Context, configPath string, deadline tim
 
 // EnforceCommitConfirmedWriter is like EnforceCommitConfirmed but writes
 // status messages to w instead of using slog. Used for testing.
-func EnforceCommitConfirmedWriter(ctx context.Context, w io.Writer, configPath string, deadline time.Time, exitFunc func(int)) {
-	remaining := time.Until(deadline)
+func EnforceCommitConfirmedWriter(ctx context.Context, clk clock.Clock, w io.Writer, configPath string, deadline time.Time, exitFunc func(int)) {
+	remaining, warning := CommitConfirmedRemaining(configPath, deadline, clk.Now())
+	if warning != "" {
+		fmt.Fprintf(w, "warning: %s\n", warning)
+	}
 	if remaining <= 0 {
 		fmt.Fprintf(w, "commit-confirmed deadline already passed, reverting\n")
 		if err := revertPending(configPath); err != nil {
@@ -240,13 +295,13 @@ func EnforceCommitConfirmedWriter(ctx context.Context, w io.Writer, configPath s
 		return
 	}
 
-	timer := time.NewTimer(remaining)
+	timer := clk.NewTimer(remaining)
 	defer timer.Stop()
 
 	select {
 	case <-ctx.Done():
 		return
-	case <-timer.C:
+	case <-timer.C():
 		fmt.Fprintf(w, "commit-confirmed timeout, reverting\n")
 		if err := revertPending(configPath); err != nil {
 			fmt.Fprintf(w, "revert error: %v\n", err)
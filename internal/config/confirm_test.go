@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/shurlinet/shurli/internal/clock"
 )
 
 func TestPendingPath(t *testing.T) {
@@ -172,6 +175,69 @@ func TestApplyCommitConfirmed(t *testing.T) {
 	}
 }
 
+func TestEnforceCommitConfirmedFakeClock(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	original := []byte("version: 1\noriginal: true\n")
+	if err := os.WriteFile(cfgPath, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := BeginCommitConfirmed(cfgPath, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfgPath, []byte("version: 1\nmodified: true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	deadline, err := CheckPending(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := clock.NewFake(time.Now())
+	var exitCode atomic.Int32
+	exitCode.Store(-1)
+	done := make(chan struct{})
+	go func() {
+		EnforceCommitConfirmedWriter(context.Background(), fake, io.Discard, cfgPath, deadline, func(code int) {
+			exitCode.Store(int32(code))
+		})
+		close(done)
+	}()
+
+	// Wait for the enforcer to arm its timer before moving the clock.
+	for i := 0; fake.Waiters() == 0; i++ {
+		if i > 1000 {
+			t.Fatal("enforcer never armed its timer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	fake.Advance(9 * time.Minute)
+	select {
+	case <-done:
+		t.Fatal("enforcer reverted before the deadline")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	fake.Advance(2 * time.Minute)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("enforcer did not revert after the deadline")
+	}
+	if exitCode.Load() != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode.Load())
+	}
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("config after revert = %q, want %q", data, original)
+	}
+}
+
 func TestEnforceCommitConfirmedTimeout(t *testing.T) {
 	dir := t.TempDir()
 	cfgPath := filepath.Join(dir, "config.yaml")
@@ -209,7 +275,7 @@ func TestEnforceCommitConfirmedTimeout(t *testing.T) {
 	ctx := context.Background()
 	done := make(chan struct{})
 	go func() {
-		EnforceCommitConfirmed(ctx, cfgPath, deadline, exitFunc)
+		EnforceCommitConfirmed(ctx, clock.Real, cfgPath, deadline, exitFunc)
 		close(done)
 	}()
 
@@ -260,7 +326,7 @@ func TestEnforceCommitConfirmedCancelled(t *testing.T) {
 
 	done := make(chan struct{})
 	go func() {
-		EnforceCommitConfirmed(ctx, cfgPath, deadline, exitFunc)
+		EnforceCommitConfirmed(ctx, clock.Real, cfgPath, deadline, exitFunc)
 		close(done)
 	}()
 
@@ -362,7 +428,7 @@ func TestEnforceCommitConfirmedWriterShortTimer(t *testing.T) {
 	deadline := time.Now().Add(50 * time.Millisecond)
 	done := make(chan struct{})
 	go func() {
-		EnforceCommitConfirmedWriter(context.Background(), &buf, cfgPath, deadline, exitFunc)
+		EnforceCommitConfirmedWriter(context.Background(), clock.Real, &buf, cfgPath, deadline, exitFunc)
 		close(done)
 	}()
 
@@ -406,7 +472,7 @@ func TestEnforceCommitConfirmedWriterTimeout(t *testing.T) {
 	exitFunc := func(code int) { exitCode.Store(int32(code)) }
 
 	// Deadline in the past triggers immediate revert
-	EnforceCommitConfirmedWriter(context.Background(), &buf, cfgPath, time.Now().Add(-time.Second), exitFunc)
+	EnforceCommitConfirmedWriter(context.Background(), clock.Real, &buf, cfgPath, time.Now().Add(-time.Second), exitFunc)
 
 	if exitCode.Load() != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode.Load())
@@ -438,7 +504,7 @@ func TestEnforceCommitConfirmedWriterCancelled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	done := make(chan struct{})
 	go func() {
-		EnforceCommitConfirmedWriter(ctx, &buf, cfgPath, time.Now().Add(10*time.Second), exitFunc)
+		EnforceCommitConfirmedWriter(ctx, clock.Real, &buf, cfgPath, time.Now().Add(10*time.Second), exitFunc)
 		close(done)
 	}()
 
@@ -481,7 +547,7 @@ func TestEnforceDeadlineAlreadyPassed(t *testing.T) {
 	}
 
 	// Deadline is in the past
-	EnforceCommitConfirmed(context.Background(), cfgPath, time.Now().Add(-time.Second), exitFunc)
+	EnforceCommitConfirmed(context.Background(), clock.Real, cfgPath, time.Now().Add(-time.Second), exitFunc)
 
 	if exitCode.Load() != 1 {
 		t.Errorf("exit code = %d, want 1", exitCode.Load())
@@ -495,3 +561,60 @@ func TestEnforceDeadlineAlreadyPassed(t *testing.T) {
 		t.Errorf("config = %q, want %q", data, original)
 	}
 }
+
+func TestCommitConfirmedRemaining(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		deadline time.Time
+		timeout  time.Duration
+		now      time.Time
+		want     time.Duration
+		warn     bool
+	}{
+		{"normal", now.Add(3 * time.Minute), 5 * time.Minute, now, 3 * time.Minute, false},
+		{"passed", now.Add(-time.Minute), 5 * time.Minute, now, -time.Minute, false},
+		{"clock moved back", now.Add(3 * time.Hour), 5 * time.Minute, now, 5 * time.Minute, true},
+		{"clock not synced", now.Add(3 * time.Minute), 5 * time.Minute, time.Unix(60, 0), 5 * time.Minute, true},
+		{"legacy marker", now.Add(3 * time.Hour), 0, now, 3 * time.Hour, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, warning := commitConfirmedRemaining(tc.deadline, tc.timeout, tc.now)
+			if got != tc.want {
+				t.Errorf("remaining = %s, want %s", got, tc.want)
+			}
+			if (warning != "") != tc.warn {
+				t.Errorf("warning = %q, want warning=%v", warning, tc.warn)
+			}
+		})
+	}
+}
+
+func TestCommitConfirmedRemainingReadsTimeout(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("original\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := BeginCommitConfirmed(cfgPath, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// A deadline far beyond the recorded timeout is capped to the timeout.
+	got, warning := CommitConfirmedRemaining(cfgPath, time.Now().Add(24*time.Hour), time.Now())
+	if got != 5*time.Minute {
+		t.Errorf("remaining = %s, want 5m", got)
+	}
+	if warning == "" {
+		t.Error("expected a warning for a deadline beyond the timeout")
+	}
+}
+
+func TestClockPlausible(t *testing.T) {
+	if ClockPlausible(time.Unix(0, 0)) {
+		t.Error("1970 should be implausible")
+	}
+	if !ClockPlausible(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("2026 should be plausible")
+	}
+}
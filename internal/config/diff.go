@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeKind classifies a single config difference.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change is one semantic difference between two node configs, keyed by the
+// dotted YAML path (e.g. "security.enable_connection_gating"). List entries
+// such as relay addresses are compared as sets and reported one per
+// added or removed element, so reordering a list is not a change.
+type Change struct {
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// DiffNodeConfigs returns the semantic differences between old and new,
+// sorted by path. Both configs are compared as they would be written to
+// YAML, so unset optional fields and defaults compare equal.
+func DiffNodeConfigs(old, new *NodeConfig) ([]Change, error) {
+	a, err := flattenConfig(old)
+	if err != nil {
+		return nil, fmt.Errorf("old config: %w", err)
+	}
+	b, err := flattenConfig(new)
+	if err != nil {
+		return nil, fmt.Errorf("new config: %w", err)
+	}
+
+	var changes []Change
+	for path, av := range a {
+		bv, ok := b[path]
+		if !ok {
+			for _, v := range av {
+				changes = append(changes, Change{Path: path, Kind: ChangeRemoved, Old: v})
+			}
+			continue
+		}
+		changes = append(changes, diffValues(path, av, bv)...)
+	}
+	for path, bv := range b {
+		if _, ok := a[path]; ok {
+			continue
+		}
+		for _, v := range bv {
+			changes = append(changes, Change{Path: path, Kind: ChangeAdded, New: v})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes, nil
+}
+
+// diffValues compares the values stored at one path. Scalars produce a
+// single "changed" entry; lists are compared as sets.
+func diffValues(path string, a, b []string) []Change {
+	if len(a) == 1 && len(b) == 1 {
+		if a[0] == b[0] {
+			return nil
+		}
+		return []Change{{Path: path, Kind: ChangeChanged, Old: a[0], New: b[0]}}
+	}
+
+	inA := make(map[string]bool, len(a))
+	for _, v := range a {
+		inA[v] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, v := range b {
+		inB[v] = true
+	}
+	var out []Change
+	for _, v := range a {
+		if !inB[v] {
+			out = append(out, Change{Path: path, Kind: ChangeRemoved, Old: v})
+		}
+	}
+	for _, v := range b {
+		if !inA[v] {
+			out = append(out, Change{Path: path, Kind: ChangeAdded, New: v})
+		}
+	}
+	return out
+}
+
+// flattenConfig renders cfg to YAML and flattens it to dotted paths.
+// Scalars map to a one-element slice; scalar lists map to their elements.
+// Lists of mappings are rendered one element per entry in flow style.
+func flattenConfig(cfg *NodeConfig) (map[string][]string, error) {
+	out := make(map[string][]string)
+	if cfg == nil {
+		return out, nil
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return out, nil
+	}
+	flattenNode(root.Content[0], "", out)
+	return out, nil
+}
+
+func flattenNode(n *yaml.Node, prefix string, out map[string][]string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenNode(n.Content[i+1], key, out)
+		}
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			return
+		}
+		vals := make([]string, 0, len(n.Content))
+		for _, c := range n.Content {
+			vals = append(vals, nodeString(c))
+		}
+		out[prefix] = vals
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return
+		}
+		out[prefix] = []string{n.Value}
+	}
+}
+
+// nodeString renders a list element on one line.
+func nodeString(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	c := *n
+	c.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(&c)
+	if err != nil {
+		return n.Value
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// FormatChanges writes a human-readable diff: "+" for added values, "-" for
+// removed values and "~" for changed scalars.
+func FormatChanges(w io.Writer, changes []Change) {
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangeAdded:
+			fmt.Fprintf(w, "  + %s: %s\n", c.Path, c.New)
+		case ChangeRemoved:
+			fmt.Fprintf(w, "  - %s: %s\n", c.Path, c.Old)
+		case ChangeChanged:
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", c.Path, c.Old, c.New)
+		}
+	}
+}
+
+// LockoutWarnings returns the changes from old to new most likely to cut
+// off remote access to the node, worded for display: turning connection
+// gating off, and removing (or disabling) the last relay. Either is fine
+// when intended, so they are warnings rather than validation errors.
+func LockoutWarnings(old, new *NodeConfig) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+	var warnings []string
+	if old.Security.EnableConnectionGating && !new.Security.EnableConnectionGating {
+		warnings = append(warnings, "connection gating would be DISABLED: any peer that can reach this node could connect, and authorized_keys would no longer be enforced")
+	}
+	if len(old.Relay.ActiveAddresses()) > 0 && len(new.Relay.ActiveAddresses()) == 0 {
+		warnings = append(warnings, "the LAST RELAY would be removed: behind NAT, this node would only be reachable from its LAN, including by you")
+	}
+	return warnings
+}
+
+// FormatLockoutWarnings writes warnings so they stand out from the diff.
+func FormatLockoutWarnings(w io.Writer, warnings []string) {
+	for _, msg := range warnings {
+		fmt.Fprintf(w, "WARNING: %s\n", msg)
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffNodeConfigs(t *testing.T) {
+	old := &NodeConfig{
+		Relay: RelayConfig{Addresses: []string{"/ip4/203.0.113.1/tcp/7777/p2p/A", "/ip4/203.0.113.2/tcp/7777/p2p/B"}},
+		Security: SecurityConfig{
+			AuthorizedKeysFile:     "authorized_keys",
+			EnableConnectionGating: true,
+		},
+		Services: ServicesConfig{
+			"ssh": {Enabled: true, LocalAddress: "localhost:22"},
+		},
+	}
+	new := &NodeConfig{
+		// Reordered, one removed, one added.
+		Relay: RelayConfig{Addresses: []string{"/ip4/203.0.113.3/tcp/7777/p2p/C", "/ip4/203.0.113.1/tcp/7777/p2p/A"}},
+		Security: SecurityConfig{
+			AuthorizedKeysFile:     "authorized_keys",
+			EnableConnectionGating: false,
+		},
+		Services: ServicesConfig{
+			"ssh": {Enabled: true, LocalAddress: "localhost:22"},
+			"web": {Enabled: true, LocalAddress: "localhost:8080"},
+		},
+	}
+
+	changes, err := DiffNodeConfigs(old, new)
+	if err != nil {
+		t.Fatalf("DiffNodeConfigs: %v", err)
+	}
+
+	var buf bytes.Buffer
+	FormatChanges(&buf, changes)
+	out := buf.String()
+	for _, want := range []string{
+		"  - relay.addresses: /ip4/203.0.113.2/tcp/7777/p2p/B\n",
+		"  + relay.addresses: /ip4/203.0.113.3/tcp/7777/p2p/C\n",
+		"  ~ security.enable_connection_gating: true -> false\n",
+		"  + services.web.local_address: localhost:8080\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff missing %q\ngot:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"203.0.113.1", "services.ssh", "authorized_keys_file"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("diff should not mention unchanged %q\ngot:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestDiffNodeConfigsIdentical(t *testing.T) {
+	cfg := &NodeConfig{
+		Relay:     RelayConfig{Addresses: []string{"/ip4/203.0.113.1/tcp/7777/p2p/A"}},
+		Discovery: DiscoveryConfig{Rendezvous: "net"},
+	}
+	changes, err := DiffNodeConfigs(cfg, cfg)
+	if err != nil {
+		t.Fatalf("DiffNodeConfigs: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestLockoutWarnings(t *testing.T) {
+	off := false
+	base := func() *NodeConfig {
+		return &NodeConfig{
+			Relay:    RelayConfig{Addresses: []string{"/ip4/203.0.113.1/tcp/7777/p2p/A"}},
+			Security: SecurityConfig{EnableConnectionGating: true},
+		}
+	}
+
+	if w := LockoutWarnings(base(), base()); len(w) != 0 {
+		t.Errorf("unchanged config: warnings = %q", w)
+	}
+
+	gatingOff := base()
+	gatingOff.Security.EnableConnectionGating = false
+	if w := LockoutWarnings(base(), gatingOff); len(w) != 1 || !strings.Contains(w[0], "gating") {
+		t.Errorf("gating disabled: warnings = %q", w)
+	}
+	// Turning gating on is not a lockout warning.
+	if w := LockoutWarnings(gatingOff, base()); len(w) != 0 {
+		t.Errorf("gating enabled: warnings = %q", w)
+	}
+
+	noRelays := base()
+	noRelays.Relay.Addresses = nil
+	relaysOff := base()
+	relaysOff.Relay.Enabled = &off
+	for name, cfg := range map[string]*NodeConfig{"removed": noRelays, "disabled": relaysOff} {
+		if w := LockoutWarnings(base(), cfg); len(w) != 1 || !strings.Contains(w[0], "LAST RELAY") {
+			t.Errorf("relays %s: warnings = %q", name, w)
+		}
+	}
+
+	// Swapping one relay for another keeps a relay.
+	swapped := base()
+	swapped.Relay.Addresses = []string{"/ip4/203.0.113.2/tcp/7777/p2p/B"}
+	if w := LockoutWarnings(base(), swapped); len(w) != 0 {
+		t.Errorf("relay swapped: warnings = %q", w)
+	}
+}
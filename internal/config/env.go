@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// expandEnv replaces ${VAR} and $VAR references in s with values from the
+// environment. $$ yields a literal $. A reference to an unset variable is
+// an error naming both the variable and field, so a missing systemd
+// Environment= line fails loudly instead of expanding to an empty path.
+func expandEnv(field, s string) (string, error) {
+	var missing string
+	out := os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return v
+	})
+	if missing != "" {
+		return "", fmt.Errorf("%w: %s (referenced by %s)", ErrEnvVarUnset, missing, field)
+	}
+	return out, nil
+}
+
+// envField is a config string eligible for environment expansion, keyed by
+// its YAML path for error messages.
+type envField struct {
+	name  string
+	value *string
+}
+
+// expandEnvFields expands each field in place, stopping at the first error.
+func expandEnvFields(fields []envField) error {
+	for _, f := range fields {
+		v, err := expandEnv(f.name, *f.value)
+		if err != nil {
+			return err
+		}
+		*f.value = v
+	}
+	return nil
+}
+
+// expandNodeConfigEnv expands environment references in the node config
+// fields that commonly differ per deployment: file paths and listen
+// addresses. Other fields are left untouched, since values such as hook
+// commands legitimately contain $.
+func expandNodeConfigEnv(cfg *NodeConfig) error {
+	fields := []envField{
+		{"identity.key_file", &cfg.Identity.KeyFile},
+		{"security.authorized_keys_file", &cfg.Security.AuthorizedKeysFile},
+		{"telemetry.metrics.listen_address", &cfg.Telemetry.Metrics.ListenAddress},
+	}
+	for i := range cfg.Network.ListenAddresses {
+		fields = append(fields, envField{fmt.Sprintf("network.listen_addresses[%d]", i), &cfg.Network.ListenAddresses[i]})
+	}
+	return expandEnvFields(fields)
+}
+
+// expandRelayConfigEnv is the relay server counterpart of expandNodeConfigEnv.
+func expandRelayConfigEnv(cfg *RelayServerConfig) error {
+	fields := []envField{
+		{"identity.key_file", &cfg.Identity.KeyFile},
+		{"security.authorized_keys_file", &cfg.Security.AuthorizedKeysFile},
+		{"security.vault_file", &cfg.Security.VaultFile},
+		{"telemetry.metrics.listen_address", &cfg.Telemetry.Metrics.ListenAddress},
+	}
+	for i := range cfg.Network.ListenAddresses {
+		fields = append(fields, envField{fmt.Sprintf("network.listen_addresses[%d]", i), &cfg.Network.ListenAddresses[i]})
+	}
+	return expandEnvFields(fields)
+}
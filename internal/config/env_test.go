@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("SHURLI_TEST_DIR", "/srv/shurli")
+	t.Setenv("SHURLI_TEST_EMPTY", "")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"identity.key", "identity.key"},
+		{"${SHURLI_TEST_DIR}/identity.key", "/srv/shurli/identity.key"},
+		{"$SHURLI_TEST_DIR/keys/relay/identity.key", "/srv/shurli/keys/relay/identity.key"},
+		{"price$$5", "price$5"},
+		{"$${SHURLI_TEST_DIR}", "${SHURLI_TEST_DIR}"},
+		{"${SHURLI_TEST_EMPTY}identity.key", "identity.key"},
+	}
+	for _, tt := range tests {
+		got, err := expandEnv("identity.key_file", tt.in)
+		if err != nil {
+			t.Errorf("expandEnv(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandEnvUnset(t *testing.T) {
+	os.Unsetenv("SHURLI_TEST_UNSET")
+	_, err := expandEnv("security.authorized_keys_file", "${SHURLI_TEST_UNSET}/authorized_keys")
+	if !errors.Is(err, ErrEnvVarUnset) {
+		t.Fatalf("err = %v, want ErrEnvVarUnset", err)
+	}
+	for _, want := range []string{"SHURLI_TEST_UNSET", "security.authorized_keys_file"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadRelayServerConfigExpandsEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SHURLI_TEST_STATE", filepath.Join(dir, "state", "relay"))
+	t.Setenv("SHURLI_TEST_PORT", "7777")
+	yaml := `
+identity:
+  key_file: "${SHURLI_TEST_STATE}/relay.key"
+network:
+  listen_addresses:
+    - "/ip4/0.0.0.0/tcp/$SHURLI_TEST_PORT"
+security:
+  authorized_keys_file: "$SHURLI_TEST_STATE/authorized_keys"
+  enable_connection_gating: true
+telemetry:
+  metrics:
+    enabled: true
+    listen_address: "127.0.0.1:9$SHURLI_TEST_PORT"
+`
+	path := filepath.Join(dir, "relay.yaml")
+	os.WriteFile(path, []byte(yaml), 0600)
+
+	cfg, err := LoadRelayServerConfig(path)
+	if err != nil {
+		t.Fatalf("LoadRelayServerConfig: %v", err)
+	}
+	if want := filepath.Join(dir, "state", "relay", "relay.key"); cfg.Identity.KeyFile != want {
+		t.Errorf("KeyFile = %q, want %q", cfg.Identity.KeyFile, want)
+	}
+	if want := filepath.Join(dir, "state", "relay", "authorized_keys"); cfg.Security.AuthorizedKeysFile != want {
+		t.Errorf("AuthorizedKeysFile = %q, want %q", cfg.Security.AuthorizedKeysFile, want)
+	}
+	if got := cfg.Network.ListenAddresses[0]; got != "/ip4/0.0.0.0/tcp/7777" {
+		t.Errorf("ListenAddresses[0] = %q", got)
+	}
+	if got := cfg.Telemetry.Metrics.ListenAddress; got != "127.0.0.1:97777" {
+		t.Errorf("metrics ListenAddress = %q", got)
+	}
+	if err := ValidateRelayServerConfig(cfg); err != nil {
+		t.Errorf("ValidateRelayServerConfig: %v", err)
+	}
+}
+
+func TestLoadNodeConfigUnsetEnv(t *testing.T) {
+	os.Unsetenv("SHURLI_TEST_UNSET")
+	dir := t.TempDir()
+	yaml := strings.Replace(testConfigYAML, `key_file: "identity.key"`, `key_file: "${SHURLI_TEST_UNSET}/identity.key"`, 1)
+	path := writeTestConfig(t, dir, yaml)
+
+	_, err := LoadNodeConfig(path)
+	if !errors.Is(err, ErrEnvVarUnset) {
+		t.Fatalf("err = %v, want ErrEnvVarUnset", err)
+	}
+	if !strings.Contains(err.Error(), "SHURLI_TEST_UNSET") || !strings.Contains(err.Error(), "identity.key_file") {
+		t.Errorf("error %q should name the variable and field", err)
+	}
+}
+
+func TestLoadNodeConfigEnvInvalidMultiaddr(t *testing.T) {
+	t.Setenv("SHURLI_TEST_LISTEN", "0.0.0.0:9100")
+	dir := t.TempDir()
+	yaml := strings.Replace(testConfigYAML, `"/ip4/0.0.0.0/tcp/0"`, `"${SHURLI_TEST_LISTEN}"`, 1)
+	path := writeTestConfig(t, dir, yaml)
+
+	cfg, err := LoadNodeConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNodeConfig: %v", err)
+	}
+	err = ValidateNodeConfig(cfg)
+	if err == nil || !strings.Contains(err.Error(), "network.listen_addresses[0]") {
+		t.Errorf("ValidateNodeConfig = %v, want invalid multiaddr error", err)
+	}
+}
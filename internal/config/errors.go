@@ -22,4 +22,16 @@ var (
 	// ErrNoPending is returned when trying to confirm but no
 	// commit-confirmed is active.
 	ErrNoPending = errors.New("no commit-confirmed pending")
+
+	// ErrConfigFetch is returned when a config URL cannot be downloaded
+	// or does not contain a valid config.
+	ErrConfigFetch = errors.New("config fetch failed")
+
+	// ErrInsecureConfigURL is returned for an http:// config URL when
+	// plain http has not been explicitly allowed.
+	ErrInsecureConfigURL = errors.New("insecure config URL")
+
+	// ErrEnvVarUnset is returned when a config value references an
+	// environment variable that is not set.
+	ErrEnvVarUnset = errors.New("environment variable not set")
 )
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Events a hook can run on.
+const (
+	HookPeerConnected    = "peer_connected"
+	HookPeerDisconnected = "peer_disconnected"
+	HookServiceAccessed  = "service_accessed"
+)
+
+// HookEvents lists the valid hook events.
+var HookEvents = []string{HookPeerConnected, HookPeerDisconnected, HookServiceAccessed}
+
+// MaxHooks caps the number of configured hooks.
+const MaxHooks = 64
+
+// DefaultHookTimeout bounds a hook command when timeout is not set.
+const DefaultHookTimeout = 30 * time.Second
+
+// maxHookTimeout is the longest timeout a hook may ask for.
+const maxHookTimeout = 10 * time.Minute
+
+// HookConfig runs a local command when the daemon sees an event. Hooks are
+// read only from the local config file: nothing a peer sends can add one or
+// change what runs.
+type HookConfig struct {
+	Event   string        `yaml:"event"`             // peer_connected, peer_disconnected or service_accessed
+	Command string        `yaml:"command"`           // split on whitespace and run without a shell
+	Peer    string        `yaml:"peer,omitempty"`    // only this peer (name or peer ID); empty = any peer
+	Service string        `yaml:"service,omitempty"` // service_accessed only: only this service
+	Timeout time.Duration `yaml:"timeout,omitempty"` // default: 30s, max 10m
+}
+
+// TimeoutOrDefault returns Timeout, or DefaultHookTimeout when unset.
+func (h HookConfig) TimeoutOrDefault() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultHookTimeout
+	}
+	return h.Timeout
+}
+
+// validateHooks checks the hooks list.
+func validateHooks(hooks []HookConfig) error {
+	if len(hooks) > MaxHooks {
+		return fmt.Errorf("hooks: at most %d hooks allowed, got %d", MaxHooks, len(hooks))
+	}
+	for i, h := range hooks {
+		field := fmt.Sprintf("hooks[%d]", i)
+		valid := false
+		for _, e := range HookEvents {
+			if h.Event == e {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%s.event must be one of %s, got %q", field, strings.Join(HookEvents, ", "), h.Event)
+		}
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("%s.command is required", field)
+		}
+		if h.Service != "" && h.Event != HookServiceAccessed {
+			return fmt.Errorf("%s.service only applies to %s hooks", field, HookServiceAccessed)
+		}
+		if h.Timeout < 0 || h.Timeout > maxHookTimeout {
+			return fmt.Errorf("%s.timeout must be between 0 (default %s) and %s, got %s", field, DefaultHookTimeout, maxHookTimeout, h.Timeout)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   []HookConfig
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"valid", []HookConfig{
+			{Event: HookPeerConnected, Peer: "home", Command: "/usr/local/bin/wake-nas"},
+			{Event: HookServiceAccessed, Service: "ssh", Command: "notify-send ssh", Timeout: time.Minute},
+		}, ""},
+		{"unknown event", []HookConfig{{Event: "peer_joined", Command: "true"}}, "hooks[0].event"},
+		{"blank command", []HookConfig{{Event: HookPeerDisconnected, Command: "  "}}, "hooks[0].command is required"},
+		{"service on peer hook", []HookConfig{{Event: HookPeerConnected, Service: "ssh", Command: "true"}}, "service only applies"},
+		{"timeout too long", []HookConfig{{Event: HookPeerConnected, Command: "true", Timeout: time.Hour}}, "timeout"},
+		{"negative timeout", []HookConfig{{Event: HookPeerConnected, Command: "true", Timeout: -time.Second}}, "timeout"},
+		{"too many", make([]HookConfig, MaxHooks+1), "at most"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateHooks(tc.hooks)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestHookTimeoutOrDefault(t *testing.T) {
+	if got := (HookConfig{}).TimeoutOrDefault(); got != DefaultHookTimeout {
+		t.Errorf("default = %s, want %s", got, DefaultHookTimeout)
+	}
+	if got := (HookConfig{Timeout: 5 * time.Second}).TimeoutOrDefault(); got != 5*time.Second {
+		t.Errorf("explicit = %s, want 5s", got)
+	}
+}
+
+func TestLoadNodeConfigHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `version: 1
+identity:
+  key_file: "identity.key"
+network:
+  listen_addresses:
+    - "/ip4/0.0.0.0/tcp/0"
+relay:
+  enabled: false
+discovery:
+  rendezvous: "test"
+protocols:
+  ping_pong:
+    id: "/pingpong/1.0.0"
+hooks:
+  - event: peer_connected
+    peer: home
+    command: "/usr/local/bin/wake-nas --now"
+    timeout: 10s
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadNodeConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNodeConfig: %v", err)
+	}
+	if err := ValidateNodeConfig(cfg); err != nil {
+		t.Fatalf("ValidateNodeConfig: %v", err)
+	}
+	if len(cfg.Hooks) != 1 {
+		t.Fatalf("got %d hooks, want 1", len(cfg.Hooks))
+	}
+	h := cfg.Hooks[0]
+	if h.Event != HookPeerConnected || h.Peer != "home" || h.Command != "/usr/local/bin/wake-nas --now" || h.Timeout != 10*time.Second {
+		t.Errorf("hook = %+v", h)
+	}
+}
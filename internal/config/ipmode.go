@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// IP modes accepted by network.ip_mode.
+const (
+	IPModeDual = "dual" // IPv4 and IPv6 (default)
+	IPModeIPv6 = "ipv6" // IPv6 only
+	IPModeIPv4 = "ipv4" // IPv4 only
+)
+
+// IPModes are the values network.ip_mode accepts.
+var IPModes = []string{IPModeDual, IPModeIPv6, IPModeIPv4}
+
+// EffectiveIPMode returns network.ip_mode, with unset meaning IPModeDual.
+func (n NetworkConfig) EffectiveIPMode() string {
+	if n.IPMode == "" {
+		return IPModeDual
+	}
+	return n.IPMode
+}
+
+// AddrIPFamily returns IPModeIPv4 or IPModeIPv6 for a multiaddr whose first
+// component pins an address family (/ip4, /dns4, /ip6, /dns6), and "" for
+// anything else (/dns, /dnsaddr, bare /p2p). A relay circuit address takes
+// the family of the relay's own address in front of /p2p-circuit.
+func AddrIPFamily(addr ma.Multiaddr) string {
+	if len(addr) == 0 {
+		return ""
+	}
+	switch addr[0].Protocol().Code {
+	case ma.P_IP4, ma.P_DNS4:
+		return IPModeIPv4
+	case ma.P_IP6, ma.P_DNS6, ma.P_IP6ZONE:
+		return IPModeIPv6
+	}
+	return ""
+}
+
+// IPFamilyAllowed reports whether network.ip_mode permits addr. Addresses
+// with no fixed family are allowed; their resolved forms are checked when
+// dialed.
+func (n NetworkConfig) IPFamilyAllowed(addr ma.Multiaddr) bool {
+	mode := n.EffectiveIPMode()
+	if mode == IPModeDual {
+		return true
+	}
+	family := AddrIPFamily(addr)
+	return family == "" || family == mode
+}
+
+// AddrAllowed reports whether a listen or relay address string is
+// permitted by network.ip_mode. Unparseable addresses are reported as
+// allowed, so libp2p gets to reject them as before.
+func (n NetworkConfig) AddrAllowed(addr string) bool {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return true
+	}
+	return n.IPFamilyAllowed(maddr)
+}
+
+// validateIPMode checks network.ip_mode and that at least one listen
+// address is of the selected family.
+func validateIPMode(n NetworkConfig) error {
+	if n.IPMode == "" {
+		return nil
+	}
+	if !slices.Contains(IPModes, n.IPMode) {
+		return fmt.Errorf("network.ip_mode: unknown mode %q (valid: %s)", n.IPMode, strings.Join(IPModes, ", "))
+	}
+	if len(n.ListenAddresses) > 0 && !slices.ContainsFunc(n.ListenAddresses, n.AddrAllowed) {
+		return fmt.Errorf("network.listen_addresses: no address matches network.ip_mode %s", n.IPMode)
+	}
+	return nil
+}
+
+// RelayIPModeWarnings returns a warning when network.ip_mode rules out
+// every relay address, so the node could never hold a reservation.
+func RelayIPModeWarnings(addrs []string, n NetworkConfig) []string {
+	if n.EffectiveIPMode() == IPModeDual || len(addrs) == 0 {
+		return nil
+	}
+	for _, addr := range addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil || n.IPFamilyAllowed(maddr) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("network.ip_mode is %s but no relay address is %s; relays will be unreachable", n.IPMode, n.IPMode)}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestAddrIPFamily(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"/ip4/1.2.3.4/tcp/7777/p2p/" + testRelayPeer, IPModeIPv4},
+		{"/dns4/relay.example.com/udp/7777/quic-v1", IPModeIPv4},
+		{"/ip6/::1/tcp/443/ws", IPModeIPv6},
+		{"/dns6/relay.example.com/tcp/7777", IPModeIPv6},
+		{"/ip6/2001:db8::1/tcp/7777/p2p/" + testRelayPeer + "/p2p-circuit", IPModeIPv6},
+		{"/dns/relay.example.com/tcp/7777", ""},
+		{"/dnsaddr/relay.example.com/p2p/" + testRelayPeer, ""},
+	}
+	for _, tt := range tests {
+		if got := AddrIPFamily(ma.StringCast(tt.addr)); got != tt.want {
+			t.Errorf("AddrIPFamily(%s) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestIPFamilyAllowed(t *testing.T) {
+	v4 := "/ip4/0.0.0.0/tcp/0"
+	v6 := "/ip6/::/tcp/0"
+	dns := "/dns/relay.example.com/tcp/7777"
+	for _, tc := range []struct {
+		mode           string
+		v4, v6, anyDNS bool
+	}{
+		{"", true, true, true},
+		{IPModeDual, true, true, true},
+		{IPModeIPv6, false, true, true},
+		{IPModeIPv4, true, false, true},
+	} {
+		n := NetworkConfig{IPMode: tc.mode}
+		if n.AddrAllowed(v4) != tc.v4 || n.AddrAllowed(v6) != tc.v6 || n.AddrAllowed(dns) != tc.anyDNS {
+			t.Errorf("mode %q: v4=%v v6=%v dns=%v, want %v %v %v", tc.mode,
+				n.AddrAllowed(v4), n.AddrAllowed(v6), n.AddrAllowed(dns), tc.v4, tc.v6, tc.anyDNS)
+		}
+	}
+	if !(NetworkConfig{IPMode: IPModeIPv6}).AddrAllowed("garbage") {
+		t.Error("unparseable address should be left for libp2p to reject")
+	}
+}
+
+func TestRelayIPModeWarnings(t *testing.T) {
+	v4only := []string{"/ip4/1.2.3.4/tcp/7777/p2p/" + testRelayPeer}
+	if w := RelayIPModeWarnings(v4only, NetworkConfig{}); len(w) != 0 {
+		t.Errorf("dual mode: warnings = %v", w)
+	}
+	if w := RelayIPModeWarnings(v4only, NetworkConfig{IPMode: IPModeIPv6}); len(w) != 1 {
+		t.Errorf("ipv6 mode, IPv4 relay: warnings = %v, want 1", w)
+	}
+	mixed := append(v4only, "/ip6/2001:db8::1/tcp/7777/p2p/"+testRelayPeer)
+	if w := RelayIPModeWarnings(mixed, NetworkConfig{IPMode: IPModeIPv6}); len(w) != 0 {
+		t.Errorf("ipv6 mode, mixed relays: warnings = %v", w)
+	}
+}
@@ -1,13 +1,20 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 	"gopkg.in/yaml.v3"
 
 	"github.com/shurlinet/shurli/internal/validate"
@@ -29,7 +36,9 @@ func checkConfigFilePermissions(path string) error {
 	return nil
 }
 
-// LoadHomeNodeConfig loads home node configuration from a YAML file
+// LoadHomeNodeConfig loads home node configuration from a YAML file.
+// ${VAR} and $VAR references in file paths and listen addresses are
+// expanded from the environment; see expandNodeConfigEnv.
 func LoadHomeNodeConfig(path string) (*HomeNodeConfig, error) {
 	if err := checkConfigFilePermissions(path); err != nil {
 		return nil, err
@@ -45,9 +54,14 @@ func LoadHomeNodeConfig(path string) (*HomeNodeConfig, error) {
 		Identity  IdentityConfig  `yaml:"identity"`
 		Network   NetworkConfig   `yaml:"network"`
 		Relay     struct {
-			Addresses           []string          `yaml:"addresses"`
-			ReservationInterval string            `yaml:"reservation_interval"`
-			Names               map[string]string `yaml:"names,omitempty"`
+			Addresses            []string          `yaml:"addresses"`
+			ReservationInterval  string            `yaml:"reservation_interval"`
+			ReservationIntervals map[string]string `yaml:"reservation_intervals,omitempty"`
+			Names                map[string]string `yaml:"names,omitempty"`
+			Enabled              *bool             `yaml:"enabled,omitempty"`
+			StartupWait          string            `yaml:"startup_wait,omitempty"`
+			PreferredRegion      string            `yaml:"preferred_region,omitempty"`
+			PreferQUIC           bool              `yaml:"prefer_quic,omitempty"`
 		} `yaml:"relay"`
 		Discovery DiscoveryConfig `yaml:"discovery"`
 		Security  SecurityConfig  `yaml:"security"`
@@ -58,6 +72,8 @@ func LoadHomeNodeConfig(path string) (*HomeNodeConfig, error) {
 		PeerRelay PeerRelayConfig `yaml:"peer_relay,omitempty"`
 		Transfer  TransferConfig  `yaml:"transfer,omitempty"`
 		CLI       CLIConfig       `yaml:"cli,omitempty"`
+		Control   ControlConfig   `yaml:"control,omitempty"`
+		Hooks     []HookConfig    `yaml:"hooks,omitempty"`
 	}
 
 	if err := yaml.Unmarshal(data, &rawConfig); err != nil {
@@ -74,9 +90,17 @@ func LoadHomeNodeConfig(path string) (*HomeNodeConfig, error) {
 	}
 
 	// Parse duration
-	reservationInterval, err := time.ParseDuration(rawConfig.Relay.ReservationInterval)
+	reservationInterval, err := parseReservationInterval(rawConfig.Relay.ReservationInterval, rawConfig.Relay.Enabled)
 	if err != nil {
-		return nil, fmt.Errorf("invalid reservation_interval: %w", err)
+		return nil, err
+	}
+	reservationIntervals, err := parseReservationIntervals(rawConfig.Relay.ReservationIntervals)
+	if err != nil {
+		return nil, err
+	}
+	startupWait, err := parseRelayStartupWait(rawConfig.Relay.StartupWait)
+	if err != nil {
+		return nil, err
 	}
 
 	config := &HomeNodeConfig{
@@ -92,13 +116,24 @@ func LoadHomeNodeConfig(path string) (*HomeNodeConfig, error) {
 		PeerRelay: rawConfig.PeerRelay,
 		Transfer:  rawConfig.Transfer,
 		CLI:       rawConfig.CLI,
+		Control:   rawConfig.Control,
+		Hooks:     rawConfig.Hooks,
 		Relay: RelayConfig{
-			Addresses:           rawConfig.Relay.Addresses,
-			ReservationInterval: reservationInterval,
-			Names:               rawConfig.Relay.Names,
+			Addresses:            rawConfig.Relay.Addresses,
+			ReservationInterval:  reservationInterval,
+			ReservationIntervals: reservationIntervals,
+			Names:                rawConfig.Relay.Names,
+			Enabled:              rawConfig.Relay.Enabled,
+			StartupWait:          startupWait,
+			PreferredRegion:      rawConfig.Relay.PreferredRegion,
+			PreferQUIC:           rawConfig.Relay.PreferQUIC,
 		},
 	}
 
+	if err := expandNodeConfigEnv(config); err != nil {
+		return nil, err
+	}
+
 	applyTelemetryDefaults(&config.Telemetry)
 
 	return config, nil
@@ -119,9 +154,14 @@ func LoadClientNodeConfig(path string) (*ClientNodeConfig, error) {
 		Identity  IdentityConfig  `yaml:"identity"`
 		Network   NetworkConfig   `yaml:"network"`
 		Relay     struct {
-			Addresses           []string          `yaml:"addresses"`
-			ReservationInterval string            `yaml:"reservation_interval"`
-			Names               map[string]string `yaml:"names,omitempty"`
+			Addresses            []string          `yaml:"addresses"`
+			ReservationInterval  string            `yaml:"reservation_interval"`
+			ReservationIntervals map[string]string `yaml:"reservation_intervals,omitempty"`
+			Names                map[string]string `yaml:"names,omitempty"`
+			Enabled              *bool             `yaml:"enabled,omitempty"`
+			StartupWait          string            `yaml:"startup_wait,omitempty"`
+			PreferredRegion      string            `yaml:"preferred_region,omitempty"`
+			PreferQUIC           bool              `yaml:"prefer_quic,omitempty"`
 		} `yaml:"relay"`
 		Discovery DiscoveryConfig `yaml:"discovery"`
 		Security  SecurityConfig  `yaml:"security"`
@@ -134,9 +174,17 @@ func LoadClientNodeConfig(path string) (*ClientNodeConfig, error) {
 	}
 
 	// Parse duration
-	reservationInterval, err := time.ParseDuration(rawConfig.Relay.ReservationInterval)
+	reservationInterval, err := parseReservationInterval(rawConfig.Relay.ReservationInterval, rawConfig.Relay.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	reservationIntervals, err := parseReservationIntervals(rawConfig.Relay.ReservationIntervals)
 	if err != nil {
-		return nil, fmt.Errorf("invalid reservation_interval: %w", err)
+		return nil, err
+	}
+	startupWait, err := parseRelayStartupWait(rawConfig.Relay.StartupWait)
+	if err != nil {
+		return nil, err
 	}
 
 	config := &ClientNodeConfig{
@@ -147,18 +195,75 @@ func LoadClientNodeConfig(path string) (*ClientNodeConfig, error) {
 		Protocols: rawConfig.Protocols,
 		Names:     rawConfig.Names,
 		Relay: RelayConfig{
-			Addresses:           rawConfig.Relay.Addresses,
-			ReservationInterval: reservationInterval,
-			Names:               rawConfig.Relay.Names,
+			Addresses:            rawConfig.Relay.Addresses,
+			ReservationInterval:  reservationInterval,
+			ReservationIntervals: reservationIntervals,
+			Names:                rawConfig.Relay.Names,
+			Enabled:              rawConfig.Relay.Enabled,
+			StartupWait:          startupWait,
+			PreferredRegion:      rawConfig.Relay.PreferredRegion,
+			PreferQUIC:           rawConfig.Relay.PreferQUIC,
 		},
 	}
 
 	return config, nil
 }
 
+// parseReservationInterval parses relay.reservation_interval. The field may
+// be omitted when relays are disabled, since nothing reserves.
+func parseReservationInterval(s string, enabled *bool) (time.Duration, error) {
+	if s == "" && enabled != nil && !*enabled {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid reservation_interval: %w", err)
+	}
+	return d, nil
+}
+
+// parseRelayStartupWait parses relay.startup_wait. Empty means unset (the
+// default wait); "0s" skips the wait.
+func parseRelayStartupWait(s string) (*time.Duration, error) {
+	if s == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid relay.startup_wait: %w", err)
+	}
+	if d < 0 || d > time.Minute {
+		return nil, fmt.Errorf("relay.startup_wait must be between 0s and 1m, got %s", d)
+	}
+	return &d, nil
+}
+
+// parseReservationIntervals parses relay.reservation_intervals, the
+// per-relay overrides of reservation_interval keyed by relay peer ID or
+// prefix. Each interval must be positive.
+func parseReservationIntervals(raw map[string]string) (map[string]time.Duration, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	intervals := make(map[string]time.Duration, len(raw))
+	for relay, s := range raw {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay.reservation_intervals[%s]: %w", relay, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("relay.reservation_intervals[%s] must be positive, got %s", relay, d)
+		}
+		intervals[relay] = d
+	}
+	return intervals, nil
+}
+
 // LoadRelayServerConfig loads relay server configuration from a YAML file.
 // Relative paths (key_file, authorized_keys_file, vault_file) are resolved
 // against the config file's directory, so relay commands work from any cwd.
+// Environment references in those paths and the listen addresses are
+// expanded first.
 func LoadRelayServerConfig(path string) (*RelayServerConfig, error) {
 	if err := checkConfigFilePermissions(path); err != nil {
 		return nil, err
@@ -184,6 +289,10 @@ func LoadRelayServerConfig(path string) (*RelayServerConfig, error) {
 		return nil, fmt.Errorf("%w: version %d is newer than supported version %d; please upgrade relay-server", ErrConfigVersionTooNew, config.Version, CurrentConfigVersion)
 	}
 
+	if err := expandRelayConfigEnv(&config); err != nil {
+		return nil, err
+	}
+
 	// Apply defaults for zero-valued resource fields.
 	// Self-hosted relays (enable_data_relay: true) get relaxed session limits
 	// to support file transfer. Seeds keep the painful 64MB default.
@@ -210,12 +319,21 @@ func ValidateHomeNodeConfig(cfg *HomeNodeConfig) error {
 	if len(cfg.Network.ListenAddresses) == 0 {
 		return fmt.Errorf("network.listen_addresses must contain at least one address")
 	}
-	if len(cfg.Relay.Addresses) == 0 {
-		return fmt.Errorf("relay.addresses must contain at least one address")
+	if cfg.Relay.IsEnabled() && len(cfg.Relay.Addresses) == 0 {
+		return fmt.Errorf("relay.addresses must contain at least one address (or set relay.enabled: false)")
 	}
 	if cfg.Discovery.Rendezvous == "" {
 		return fmt.Errorf("discovery.rendezvous is required")
 	}
+	if err := validateChannels(cfg.Discovery); err != nil {
+		return err
+	}
+	if err := validateTransports(cfg.Network); err != nil {
+		return err
+	}
+	if err := validateIPMode(cfg.Network); err != nil {
+		return err
+	}
 	if cfg.Protocols.PingPong.ID == "" {
 		return fmt.Errorf("protocols.ping_pong.id is required")
 	}
@@ -230,12 +348,21 @@ func ValidateClientNodeConfig(cfg *ClientNodeConfig) error {
 	if len(cfg.Network.ListenAddresses) == 0 {
 		return fmt.Errorf("network.listen_addresses must contain at least one address")
 	}
-	if len(cfg.Relay.Addresses) == 0 {
-		return fmt.Errorf("relay.addresses must contain at least one address")
+	if cfg.Relay.IsEnabled() && len(cfg.Relay.Addresses) == 0 {
+		return fmt.Errorf("relay.addresses must contain at least one address (or set relay.enabled: false)")
 	}
 	if cfg.Discovery.Rendezvous == "" {
 		return fmt.Errorf("discovery.rendezvous is required")
 	}
+	if err := validateChannels(cfg.Discovery); err != nil {
+		return err
+	}
+	if err := validateTransports(cfg.Network); err != nil {
+		return err
+	}
+	if err := validateIPMode(cfg.Network); err != nil {
+		return err
+	}
 	if cfg.Protocols.PingPong.ID == "" {
 		return fmt.Errorf("protocols.ping_pong.id is required")
 	}
@@ -269,10 +396,35 @@ func FindRelayConfigFile(explicitPath string) (string, error) {
 	return "", fmt.Errorf("%w; searched:\n  %s\n\nRun 'shurli relay setup' to create one, or use --config <path>", ErrConfigNotFound, strings.Join(searchPaths, "\n  "))
 }
 
+// ConfigEnvVar names the environment variable that points at a config file.
+// It sits between --config and the standard search paths, which suits
+// containers and switching between several configs.
+const ConfigEnvVar = "SHURLI_CONFIG"
+
+// legacyConfigEnvVar is the pre-rename name, still honored when
+// ConfigEnvVar is unset.
+const legacyConfigEnvVar = "PEERUP_CONFIG"
+
 // FindConfigFile searches for a shurli config file in standard locations.
-// Search order: explicitPath (if given), ./shurli.yaml, /etc/shurli/config.yaml,
-// ~/.shurli/config.yaml
+// Search order: explicitPath (if given), $SHURLI_CONFIG (or $PEERUP_CONFIG),
+// ./shurli.yaml, /etc/shurli/config.yaml, ~/.shurli/config.yaml. With a
+// profile selected (SetProfile), <profile>.yaml is searched for in ./,
+// /etc/shurli/ and ~/.shurli/ instead.
+//
+// A path named by the environment must exist: a typo there is an error,
+// not a silent fallback to whichever default config happens to be found.
+//
+// An explicit http(s) URL is downloaded with FetchConfigURL into the user
+// config directory and the local copy's path is returned. A failed fetch
+// is an error; there is no fallback to a local config.
 func FindConfigFile(explicitPath string) (string, error) {
+	if IsConfigURL(explicitPath) {
+		dir, err := UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return FetchConfigURL(context.Background(), explicitPath, dir, allowInsecureConfigURL)
+	}
 	if explicitPath != "" {
 		if _, err := os.Stat(explicitPath); err != nil {
 			return "", fmt.Errorf("%w: %s", ErrConfigNotFound, explicitPath)
@@ -280,16 +432,31 @@ func FindConfigFile(explicitPath string) (string, error) {
 		return explicitPath, nil
 	}
 
+	for _, name := range []string{ConfigEnvVar, legacyConfigEnvVar} {
+		envPath := os.Getenv(name)
+		if envPath == "" {
+			continue
+		}
+		if _, err := os.Stat(envPath); err != nil {
+			return "", fmt.Errorf("%w: %s (from $%s)", ErrConfigNotFound, envPath, name)
+		}
+		return envPath, nil
+	}
+
+	// A named profile looks for <profile>.yaml in the same places instead.
 	searchPaths := []string{
 		"shurli.yaml",
 	}
+	if profile != "" {
+		searchPaths = []string{ProfileConfigName()}
+	}
 
 	// /etc/shurli/config.yaml (system default, checked first)
-	searchPaths = append(searchPaths, filepath.Join("/etc", "shurli", "config.yaml"))
+	searchPaths = append(searchPaths, filepath.Join("/etc", "shurli", ProfileConfigName()))
 
 	if home, err := os.UserHomeDir(); err == nil {
 		// ~/.shurli/config.yaml (user-level default)
-		searchPaths = append(searchPaths, filepath.Join(home, ".shurli", "config.yaml"))
+		searchPaths = append(searchPaths, filepath.Join(home, ".shurli", ProfileConfigName()))
 	}
 
 	for _, path := range searchPaths {
@@ -298,7 +465,7 @@ func FindConfigFile(explicitPath string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("%w; searched:\n  %s\n\nRun 'shurli init' to create one, or use --config <path>", ErrConfigNotFound, strings.Join(searchPaths, "\n  "))
+	return "", fmt.Errorf("%w; searched:\n  %s\n\nRun 'shurli init' to create one, or use --config <path> or $SHURLI_CONFIG", ErrConfigNotFound, strings.Join(searchPaths, "\n  "))
 }
 
 // LoadNodeConfig loads unified node configuration from a YAML file.
@@ -334,6 +501,21 @@ func ResolveRelayConfigPaths(cfg *RelayServerConfig, configDir string) {
 	}
 }
 
+// NodeConfigWarnings returns setup mistakes in a valid node config that
+// don't stop the node from starting. Relays on a transport or IP family the
+// node doesn't run are skipped while other relays may still work, and a node
+// without relays or a global address can only be reached from its LAN. It
+// probes the host's interfaces, so callers run it at startup and reload
+// rather than inside ValidateNodeConfig.
+func NodeConfigWarnings(cfg *NodeConfig) []string {
+	warnings := RelayTransportWarnings(cfg.Relay.ActiveAddresses(), cfg.Network.Transports())
+	warnings = append(warnings, RelayIPModeWarnings(cfg.Relay.ActiveAddresses(), cfg.Network)...)
+	if !cfg.Relay.IsEnabled() && !hasGlobalAddress() {
+		warnings = append(warnings, "relay.enabled is false and no global IPv4/IPv6 address was found; peers outside this LAN may be unable to reach this node")
+	}
+	return warnings
+}
+
 // ValidateNodeConfig validates unified node configuration.
 func ValidateNodeConfig(cfg *NodeConfig) error {
 	if cfg.Identity.KeyFile == "" {
@@ -342,29 +524,148 @@ func ValidateNodeConfig(cfg *NodeConfig) error {
 	if len(cfg.Network.ListenAddresses) == 0 {
 		return fmt.Errorf("network.listen_addresses must contain at least one address")
 	}
-	if len(cfg.Relay.Addresses) == 0 {
-		return fmt.Errorf("relay.addresses must contain at least one address")
+	if err := validateListenAddresses(cfg.Network.ListenAddresses); err != nil {
+		return err
+	}
+	if cfg.Relay.IsEnabled() && len(cfg.Relay.Addresses) == 0 {
+		return fmt.Errorf("relay.addresses must contain at least one address (or set relay.enabled: false)")
 	}
 	if cfg.Discovery.Rendezvous == "" {
 		return fmt.Errorf("discovery.rendezvous is required")
 	}
+	if err := validateChannels(cfg.Discovery); err != nil {
+		return err
+	}
+	if err := validateTransports(cfg.Network); err != nil {
+		return err
+	}
+	if err := validateIPMode(cfg.Network); err != nil {
+		return err
+	}
 	if cfg.Protocols.PingPong.ID == "" {
 		return fmt.Errorf("protocols.ping_pong.id is required")
 	}
 	if cfg.Security.EnableConnectionGating && cfg.Security.AuthorizedKeysFile == "" {
 		return fmt.Errorf("security.authorized_keys_file is required when connection gating is enabled")
 	}
+	if err := cfg.Security.UserAgentPolicy.Validate("security.user_agent_policy"); err != nil {
+		return err
+	}
+	for _, id := range cfg.Security.AdminPeers {
+		if _, err := peer.Decode(id); err != nil {
+			return fmt.Errorf("security.admin_peers: invalid peer ID %q: %w", id, err)
+		}
+	}
 	// Validate network namespace if set
 	if cfg.Discovery.Network != "" {
 		if err := validate.NetworkName(cfg.Discovery.Network); err != nil {
 			return fmt.Errorf("discovery.network: %w", err)
 		}
 	}
+	// A grace period longer than a minute would hide real outages from
+	// the reconnect loop.
+	if cfg.Discovery.DisconnectGrace < 0 || cfg.Discovery.DisconnectGrace > time.Minute {
+		return fmt.Errorf("discovery.disconnect_grace must be between 0 and 1m, got %s", cfg.Discovery.DisconnectGrace)
+	}
+	if cfg.Discovery.ReconnectLogBurst < 0 {
+		return fmt.Errorf("discovery.reconnect_log_burst must be 0 (default) or positive, got %d", cfg.Discovery.ReconnectLogBurst)
+	}
+	if cfg.Discovery.PeerCacheTTL < 0 {
+		return fmt.Errorf("discovery.peer_cache_ttl must be 0 (default) or positive, got %s", cfg.Discovery.PeerCacheTTL)
+	}
+	// Keepalives faster than every 5s add traffic without keeping NAT
+	// mappings open any better.
+	if ka := cfg.Network.KeepaliveInterval; ka < 0 || (ka > 0 && ka < 5*time.Second) {
+		return fmt.Errorf("network.keepalive_interval must be 0 (disabled) or at least 5s, got %s", ka)
+	}
+	// Shorter timeouts close DHT connections between routine queries
+	// only for them to be dialed again.
+	if idle := cfg.Network.IdleConnectionTimeout; idle < 0 || (idle > 0 && idle < 30*time.Second) {
+		return fmt.Errorf("network.idle_connection_timeout must be 0 (disabled) or at least 30s, got %s", idle)
+	}
+	// quic-go rejects packets below the 1200-byte QUIC minimum and cannot
+	// buffer more than 1452. Keepalives are capped at half the 30s idle
+	// timeout, so longer periods would be silently ignored.
+	if size := cfg.Network.QUIC.InitialPacketSize; size != 0 && (size < 1200 || size > 1452) {
+		return fmt.Errorf("network.quic.initial_packet_size must be 0 (default) or between 1200 and 1452, got %d", size)
+	}
+	if ka := cfg.Network.QUIC.KeepalivePeriod; ka < 0 || (ka > 0 && (ka < time.Second || ka > 15*time.Second)) {
+		return fmt.Errorf("network.quic.keepalive_period must be 0 (default) or between 1s and 15s, got %s", ka)
+	}
+	for _, srv := range cfg.Network.STUNServers {
+		if _, _, err := net.SplitHostPort(srv); err != nil {
+			return fmt.Errorf("network.stun_servers: %q must be host:port (e.g. stun.example.com:3478)", srv)
+		}
+	}
+	if proxy := cfg.Network.Tor.SOCKSProxy; proxy != "" {
+		if _, _, err := net.SplitHostPort(proxy); err != nil {
+			return fmt.Errorf("network.tor.socks_proxy must be host:port (e.g. 127.0.0.1:9050), got %q", proxy)
+		}
+	}
+	if cfg.Control.MaxProxies < 0 {
+		return fmt.Errorf("control.max_proxies must be 0 (default %d) or positive, got %d", DefaultMaxProxies, cfg.Control.MaxProxies)
+	}
+	if cfg.Relay.PreferredRegion != "" {
+		if err := validate.RegionName(cfg.Relay.PreferredRegion); err != nil {
+			return fmt.Errorf("relay.preferred_region: %w", err)
+		}
+	}
+	for _, c := range cfg.Network.AdvertiseExclude {
+		if !slices.Contains(AdvertiseAddrClasses, c) {
+			return fmt.Errorf("network.advertise_exclude: unknown address class %q (valid: %s)", c, strings.Join(AdvertiseAddrClasses, ", "))
+		}
+	}
+	if err := validateIsolationHook(cfg.Telemetry.OnIsolated); err != nil {
+		return err
+	}
+	if err := validateHooks(cfg.Hooks); err != nil {
+		return err
+	}
 	// Validate service names (prevent protocol ID injection)
-	for name := range cfg.Services {
+	for name, svc := range cfg.Services {
 		if err := validate.ServiceName(name); err != nil {
 			return fmt.Errorf("services: %w", err)
 		}
+		if svc.MaxBandwidthMbps < 0 {
+			return fmt.Errorf("services.%s.max_bandwidth_mbps must be >= 0", name)
+		}
+		if svc.MaxConcurrent < 0 {
+			return fmt.Errorf("services.%s.max_concurrent must be >= 0", name)
+		}
+		if svc.RatePerMinute < 0 {
+			return fmt.Errorf("services.%s.rate_per_minute must be >= 0", name)
+		}
+		if svc.Network != "" && svc.Network != "tcp" && svc.Network != "udp" {
+			return fmt.Errorf("services.%s.network must be tcp or udp, got %q", name, svc.Network)
+		}
+		if svc.Network == "udp" && len(svc.HTTPRoutes) > 0 {
+			return fmt.Errorf("services.%s: http_routes cannot be used with network: udp", name)
+		}
+		if err := validateHTTPRoutes(name, svc); err != nil {
+			return err
+		}
+	}
+	if err := ValidateServiceRanges(cfg.Services); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateIsolationHook checks telemetry.on_isolated. A short after would
+// alert on every brief outage, including the first seconds after startup
+// before any peer has connected.
+func validateIsolationHook(c IsolationHookConfig) error {
+	if c.After != 0 && c.After < 30*time.Second {
+		return fmt.Errorf("telemetry.on_isolated.after must be at least 30s, got %s", c.After)
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("telemetry.on_isolated.url must be an http or https URL, got %q", c.URL)
+		}
+	}
+	if c.Command != "" && strings.TrimSpace(c.Command) == "" {
+		return fmt.Errorf("telemetry.on_isolated.command is blank")
 	}
 	return nil
 }
@@ -393,6 +694,9 @@ func ValidateRelayServerConfig(cfg *RelayServerConfig) error {
 	if len(cfg.Network.ListenAddresses) == 0 {
 		return fmt.Errorf("network.listen_addresses must contain at least one address")
 	}
+	if err := validateListenAddresses(cfg.Network.ListenAddresses); err != nil {
+		return err
+	}
 	if cfg.Security.EnableConnectionGating && cfg.Security.AuthorizedKeysFile == "" {
 		return fmt.Errorf("security.authorized_keys_file is required when connection gating is enabled")
 	}
@@ -412,6 +716,28 @@ func ValidateRelayServerConfig(cfg *RelayServerConfig) error {
 			return fmt.Errorf("resources.session_data_limit: %w", err)
 		}
 	}
+	// Reservation churn limit: clients re-reserve every few minutes, so a
+	// limit below 2/min could refuse a legitimate refresh after a reconnect.
+	if cfg.Security.MaxReservationsPerMinute < 0 || cfg.Security.MaxReservationsPerMinute == 1 {
+		return fmt.Errorf("security.max_reservations_per_minute must be 0 (default) or at least 2, got %d", cfg.Security.MaxReservationsPerMinute)
+	}
+	if cfg.Security.InviteClockSkew != "" {
+		d, err := time.ParseDuration(cfg.Security.InviteClockSkew)
+		if err != nil {
+			return fmt.Errorf("security.invite_clock_skew: %w", err)
+		}
+		if d < 0 || d > maxInviteClockSkew {
+			return fmt.Errorf("security.invite_clock_skew must be between 0 and %s, got %s", maxInviteClockSkew, d)
+		}
+	}
+	if err := cfg.Security.UserAgentPolicy.Validate("security.user_agent_policy"); err != nil {
+		return err
+	}
+	if cfg.Region != "" {
+		if err := validate.RegionName(cfg.Region); err != nil {
+			return fmt.Errorf("region: %w", err)
+		}
+	}
 	// Validate network namespace if set
 	if cfg.Discovery.Network != "" {
 		if err := validate.NetworkName(cfg.Discovery.Network); err != nil {
@@ -421,6 +747,114 @@ func ValidateRelayServerConfig(cfg *RelayServerConfig) error {
 	return nil
 }
 
+// validateChannels rejects empty and repeated discovery.channels entries,
+// including one that repeats discovery.rendezvous.
+func validateChannels(d DiscoveryConfig) error {
+	seen := map[string]bool{d.Rendezvous: true}
+	for i, c := range d.Channels {
+		if c == "" {
+			return fmt.Errorf("discovery.channels[%d]: must not be empty", i)
+		}
+		if seen[c] {
+			return fmt.Errorf("discovery.channels[%d]: %q is listed twice (or repeats discovery.rendezvous)", i, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// validateTransports checks network.transports names and that at least
+// one listen address survives the filter, so the node can still listen.
+func validateTransports(n NetworkConfig) error {
+	if len(n.EnabledTransports) == 0 {
+		return nil
+	}
+	for i, t := range n.EnabledTransports {
+		if !slices.Contains(TransportNames, t) {
+			return fmt.Errorf("network.transports[%d]: unknown transport %q (valid: %s)", i, t, strings.Join(TransportNames, ", "))
+		}
+		if slices.Contains(n.EnabledTransports[:i], t) {
+			return fmt.Errorf("network.transports[%d]: %q is listed twice", i, t)
+		}
+	}
+	if len(n.ListenAddresses) > 0 && !slices.ContainsFunc(n.ListenAddresses, n.ListenAddrEnabled) {
+		return fmt.Errorf("network.listen_addresses: no address uses an enabled transport (network.transports: %s)", strings.Join(n.EnabledTransports, ", "))
+	}
+	return nil
+}
+
+// validateListenAddresses checks that every listen address is a multiaddr
+// and that no two of them would bind the same socket, which otherwise
+// surfaces as an opaque libp2p bind error deep in startup. Every offending
+// address is reported in one error.
+func validateListenAddresses(addrs []string) error {
+	var errs []error
+	sockets := make([]listenSocket, len(addrs))
+	for i, a := range addrs {
+		maddr, err := ma.NewMultiaddr(a)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("network.listen_addresses[%d]: invalid multiaddr %q: %w", i, a, err))
+			continue
+		}
+		sockets[i] = parseListenSocket(maddr)
+		for j := range i {
+			if sockets[i].collides(sockets[j]) {
+				errs = append(errs, fmt.Errorf("network.listen_addresses[%d]: %q binds the same %s port %s as [%d] %q", i, a, sockets[i].proto, sockets[i].port, j, addrs[j]))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listenSocket is the socket a listen address binds. ip is "" when the
+// address does not start with a literal IP (e.g. /dns), which is never
+// checked for collisions.
+type listenSocket struct {
+	ip    net.IP
+	proto string // "tcp" or "udp"
+	port  string
+	stack string // protocols after the port, e.g. "/quic-v1" or "/ws"
+}
+
+func parseListenSocket(addr ma.Multiaddr) listenSocket {
+	var s listenSocket
+	for i, c := range addr {
+		switch c.Protocol().Code {
+		case ma.P_IP4, ma.P_IP6:
+			if i == 0 {
+				s.ip = net.ParseIP(c.Value())
+			}
+		case ma.P_TCP, ma.P_UDP:
+			if s.proto == "" {
+				s.proto = c.Protocol().Name
+				s.port = c.Value()
+				s.stack = addr[i+1:].String()
+			}
+		}
+	}
+	return s
+}
+
+// collides reports whether two listen addresses would fight over a
+// socket. Port 0 never collides (the OS picks a free port), an unspecified
+// IP (0.0.0.0, ::) overlaps every IP of its family, and IPv4 and IPv6 are
+// bound separately. On UDP, QUIC, WebTransport and WebRTC share one socket,
+// so only the same transport twice collides; on TCP, plain TCP and
+// WebSocket each need the port to themselves.
+func (s listenSocket) collides(o listenSocket) bool {
+	if s.ip == nil || o.ip == nil || s.proto == "" || s.proto != o.proto || s.port != o.port || s.port == "0" {
+		return false
+	}
+	if (s.ip.To4() == nil) != (o.ip.To4() == nil) {
+		return false
+	}
+	if !s.ip.Equal(o.ip) && !s.ip.IsUnspecified() && !o.ip.IsUnspecified() {
+		return false
+	}
+	return s.proto == "tcp" || s.stack == o.stack
+}
+
 // DefaultRelayResources returns the default relay resource configuration.
 // Values are tuned for a private relay serving 2-10 peers with SSH/XRDP workloads.
 func DefaultRelayResources() RelayResourcesConfig {
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Minimal valid YAML for loading tests.
@@ -77,6 +83,25 @@ func TestLoadNodeConfig(t *testing.T) {
 	}
 }
 
+func TestLoadNodeConfigDNSRelayAddress(t *testing.T) {
+	dir := t.TempDir()
+	addr := "/dns4/relay.example.com/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An"
+	content := strings.Replace(testConfigYAML,
+		"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An", addr, 1)
+	path := writeTestConfig(t, dir, content)
+
+	cfg, err := LoadNodeConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNodeConfig: %v", err)
+	}
+	if err := ValidateNodeConfig(cfg); err != nil {
+		t.Fatalf("ValidateNodeConfig: %v", err)
+	}
+	if len(cfg.Relay.Addresses) != 1 || cfg.Relay.Addresses[0] != addr {
+		t.Errorf("Relay.Addresses = %v, want [%s]", cfg.Relay.Addresses, addr)
+	}
+}
+
 func TestLoadNodeConfigMissingFile(t *testing.T) {
 	_, err := LoadNodeConfig("/nonexistent/path.yaml")
 	if err == nil {
@@ -122,6 +147,76 @@ protocols:
 	}
 }
 
+func TestLoadNodeConfigReservationIntervals(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Replace(testConfigYAML, `  reservation_interval: "2m"
+`, `  reservation_interval: "2m"
+  reservation_intervals:
+    12D3KooWRzaGMTqQ: "10m"
+`, 1)
+	cfg, err := LoadNodeConfig(writeTestConfig(t, dir, content))
+	if err != nil {
+		t.Fatalf("LoadNodeConfig: %v", err)
+	}
+	if got := cfg.Relay.ReservationIntervalFor("12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An"); got != 10*time.Minute {
+		t.Errorf("overridden relay interval = %v, want 10m", got)
+	}
+	if got := cfg.Relay.ReservationIntervalFor("12D3KooWPrmh163sTHW3mYQm7YsLsSR2wr71fPp4g6yjuGv3sGQt"); got != 2*time.Minute {
+		t.Errorf("other relay interval = %v, want the global 2m", got)
+	}
+
+	for _, bad := range []string{`"0s"`, `"-1m"`, `"soon"`} {
+		content := strings.Replace(testConfigYAML, `  reservation_interval: "2m"
+`, `  reservation_interval: "2m"
+  reservation_intervals:
+    12D3KooWRzaGMTqQ: `+bad+`
+`, 1)
+		if _, err := LoadNodeConfig(writeTestConfig(t, dir, content)); err == nil {
+			t.Errorf("expected error for reservation_intervals value %s", bad)
+		}
+	}
+}
+
+func TestLoadNodeConfigRelayStartupWait(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadNodeConfig(writeTestConfig(t, dir, testConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadNodeConfig: %v", err)
+	}
+	if got := cfg.Relay.StartupWaitOrDefault(); got != DefaultRelayStartupWait {
+		t.Errorf("unset startup_wait = %v, want %v", got, DefaultRelayStartupWait)
+	}
+
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{`"0s"`, 0}, {`"2s"`, 2 * time.Second},
+	} {
+		content := strings.Replace(testConfigYAML, `  reservation_interval: "2m"
+`, `  reservation_interval: "2m"
+  startup_wait: `+tc.value+`
+`, 1)
+		cfg, err := LoadNodeConfig(writeTestConfig(t, dir, content))
+		if err != nil {
+			t.Fatalf("startup_wait %s: %v", tc.value, err)
+		}
+		if got := cfg.Relay.StartupWaitOrDefault(); got != tc.want {
+			t.Errorf("startup_wait %s = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{`"-1s"`, `"2m"`, `"soon"`} {
+		content := strings.Replace(testConfigYAML, `  reservation_interval: "2m"
+`, `  reservation_interval: "2m"
+  startup_wait: `+bad+`
+`, 1)
+		if _, err := LoadNodeConfig(writeTestConfig(t, dir, content)); err == nil {
+			t.Errorf("expected error for startup_wait %s", bad)
+		}
+	}
+}
+
 func TestValidateNodeConfig(t *testing.T) {
 	valid := &NodeConfig{
 		Identity:  IdentityConfig{KeyFile: "key"},
@@ -143,7 +238,7 @@ func TestValidateNodeConfigMissingFields(t *testing.T) {
 		cfg  NodeConfig
 	}{
 		{"no key_file", NodeConfig{
-			Network:   NetworkConfig{ListenAddresses: []string{"x"}},
+			Network:   NetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
 			Relay:     RelayConfig{Addresses: []string{"x"}},
 			Discovery: DiscoveryConfig{Rendezvous: "x"},
 			Protocols: ProtocolsConfig{PingPong: PingPongConfig{ID: "x"}},
@@ -156,25 +251,25 @@ func TestValidateNodeConfigMissingFields(t *testing.T) {
 		}},
 		{"no relay_addresses", NodeConfig{
 			Identity:  IdentityConfig{KeyFile: "x"},
-			Network:   NetworkConfig{ListenAddresses: []string{"x"}},
+			Network:   NetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
 			Discovery: DiscoveryConfig{Rendezvous: "x"},
 			Protocols: ProtocolsConfig{PingPong: PingPongConfig{ID: "x"}},
 		}},
 		{"no rendezvous", NodeConfig{
 			Identity:  IdentityConfig{KeyFile: "x"},
-			Network:   NetworkConfig{ListenAddresses: []string{"x"}},
+			Network:   NetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
 			Relay:     RelayConfig{Addresses: []string{"x"}},
 			Protocols: ProtocolsConfig{PingPong: PingPongConfig{ID: "x"}},
 		}},
 		{"no pingpong_id", NodeConfig{
 			Identity:  IdentityConfig{KeyFile: "x"},
-			Network:   NetworkConfig{ListenAddresses: []string{"x"}},
+			Network:   NetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
 			Relay:     RelayConfig{Addresses: []string{"x"}},
 			Discovery: DiscoveryConfig{Rendezvous: "x"},
 		}},
 		{"gating without auth_keys", NodeConfig{
 			Identity:  IdentityConfig{KeyFile: "x"},
-			Network:   NetworkConfig{ListenAddresses: []string{"x"}},
+			Network:   NetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
 			Relay:     RelayConfig{Addresses: []string{"x"}},
 			Discovery: DiscoveryConfig{Rendezvous: "x"},
 			Security:  SecurityConfig{EnableConnectionGating: true, AuthorizedKeysFile: ""},
@@ -267,6 +362,78 @@ func TestFindConfigFileLocalDir(t *testing.T) {
 	}
 }
 
+func TestFindConfigFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	envPath := writeTestConfig(t, t.TempDir(), "identity:\n  key_file: x")
+	flagPath := writeTestConfig(t, t.TempDir(), "identity:\n  key_file: x")
+
+	// A ./shurli.yaml that the default search would otherwise pick up.
+	if err := os.WriteFile(filepath.Join(dir, "shurli.yaml"), []byte("identity:\n  key_file: x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	origDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(origDir)
+
+	t.Run("flag wins over env", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, envPath)
+		found, err := FindConfigFile(flagPath)
+		if err != nil {
+			t.Fatalf("FindConfigFile: %v", err)
+		}
+		if found != flagPath {
+			t.Errorf("found = %q, want %q", found, flagPath)
+		}
+	})
+
+	t.Run("env wins over default search", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, envPath)
+		found, err := FindConfigFile("")
+		if err != nil {
+			t.Fatalf("FindConfigFile: %v", err)
+		}
+		if found != envPath {
+			t.Errorf("found = %q, want %q", found, envPath)
+		}
+	})
+
+	t.Run("legacy env honored", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, "")
+		t.Setenv(legacyConfigEnvVar, envPath)
+		found, err := FindConfigFile("")
+		if err != nil {
+			t.Fatalf("FindConfigFile: %v", err)
+		}
+		if found != envPath {
+			t.Errorf("found = %q, want %q", found, envPath)
+		}
+	})
+
+	t.Run("SHURLI_CONFIG wins over legacy", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, envPath)
+		t.Setenv(legacyConfigEnvVar, flagPath)
+		found, err := FindConfigFile("")
+		if err != nil {
+			t.Fatalf("FindConfigFile: %v", err)
+		}
+		if found != envPath {
+			t.Errorf("found = %q, want %q", found, envPath)
+		}
+	})
+
+	t.Run("missing env file errors", func(t *testing.T) {
+		missing := filepath.Join(dir, "nope.yaml")
+		t.Setenv(ConfigEnvVar, missing)
+		found, err := FindConfigFile("")
+		if !errors.Is(err, ErrConfigNotFound) {
+			t.Fatalf("err = %v (found %q), want ErrConfigNotFound", err, found)
+		}
+		if !strings.Contains(err.Error(), missing) || !strings.Contains(err.Error(), ConfigEnvVar) {
+			t.Errorf("error should name the path and variable: %v", err)
+		}
+	})
+}
+
 func TestLoadRelayServerConfig(t *testing.T) {
 	dir := t.TempDir()
 	yaml := `
@@ -400,14 +567,461 @@ func TestValidateRelayServerConfig(t *testing.T) {
 	}
 }
 
-func TestValidateNodeConfigServiceNames(t *testing.T) {
-	base := NodeConfig{
+func TestValidateRelayServerConfig_MaxReservationsPerMinute(t *testing.T) {
+	for _, tc := range []struct {
+		val     int
+		wantErr bool
+	}{
+		{0, false}, {2, false}, {30, false}, {1, true}, {-1, true},
+	} {
+		cfg := &RelayServerConfig{
+			Identity: IdentityConfig{KeyFile: "key"},
+			Network:  RelayNetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/7777"}},
+			Security: RelaySecurityConfig{MaxReservationsPerMinute: tc.val},
+		}
+		err := ValidateRelayServerConfig(cfg)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("max_reservations_per_minute=%d: err=%v, wantErr=%v", tc.val, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateRelayServerConfig_InviteClockSkew(t *testing.T) {
+	for _, tc := range []struct {
+		skew    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false}, {"2m", 2 * time.Minute, false}, {"1h", time.Hour, false},
+		{"-1s", 0, true}, {"2h", 0, true}, {"soon", 0, true},
+	} {
+		cfg := &RelayServerConfig{
+			Identity: IdentityConfig{KeyFile: "relay.key"},
+			Network:  RelayNetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/7777"}},
+			Security: RelaySecurityConfig{InviteClockSkew: tc.skew},
+		}
+		err := ValidateRelayServerConfig(cfg)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("invite_clock_skew=%q: err=%v, wantErr=%v", tc.skew, err, tc.wantErr)
+		}
+		if got := cfg.Security.InviteClockSkewDuration(); got != tc.want {
+			t.Errorf("invite_clock_skew=%q: duration=%s, want %s", tc.skew, got, tc.want)
+		}
+	}
+}
+
+// validNodeConfig returns the smallest NodeConfig that ValidateNodeConfig
+// accepts. Tests change the one field they cover.
+func validNodeConfig() NodeConfig {
+	return NodeConfig{
 		Identity:  IdentityConfig{KeyFile: "x"},
-		Network:   NetworkConfig{ListenAddresses: []string{"x"}},
+		Network:   NetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
 		Relay:     RelayConfig{Addresses: []string{"x"}},
 		Discovery: DiscoveryConfig{Rendezvous: "x"},
 		Protocols: ProtocolsConfig{PingPong: PingPongConfig{ID: "x"}},
 	}
+}
+
+// checkValidateNodeConfig reports whether ValidateNodeConfig's verdict on
+// cfg matches wantErr, labelling the failure with the value under test.
+func checkValidateNodeConfig(t *testing.T, label string, cfg NodeConfig, wantErr bool) {
+	t.Helper()
+	if err := ValidateNodeConfig(&cfg); (err != nil) != wantErr {
+		t.Errorf("%s: err=%v, wantErr=%v", label, err, wantErr)
+	}
+}
+
+func TestValidateNodeConfigDisconnectGrace(t *testing.T) {
+	for _, tc := range []struct {
+		grace   time.Duration
+		wantErr bool
+	}{
+		{0, false}, {5 * time.Second, false}, {time.Minute, false},
+		{-time.Second, true}, {2 * time.Minute, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Discovery.DisconnectGrace = tc.grace
+		checkValidateNodeConfig(t, fmt.Sprintf("disconnect_grace=%s", tc.grace), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigPeerCacheTTL(t *testing.T) {
+	for _, tc := range []struct {
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{0, false}, {time.Hour, false}, {-time.Second, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Discovery.PeerCacheTTL = tc.ttl
+		checkValidateNodeConfig(t, fmt.Sprintf("peer_cache_ttl=%s", tc.ttl), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigChannels(t *testing.T) {
+	for _, tc := range []struct {
+		channels []string
+		wantErr  bool
+	}{
+		{nil, false},
+		{[]string{"family", "work"}, false},
+		{[]string{"family", ""}, true},
+		{[]string{"family", "family"}, true},
+		{[]string{"x"}, true}, // repeats discovery.rendezvous
+	} {
+		cfg := validNodeConfig()
+		cfg.Discovery.Channels = tc.channels
+		checkValidateNodeConfig(t, fmt.Sprintf("channels=%q", tc.channels), cfg, tc.wantErr)
+	}
+
+	d := DiscoveryConfig{Rendezvous: "main", Channels: []string{"a", "main", "", "b"}}
+	if got := d.RendezvousList(); !slices.Equal(got, []string{"main", "a", "b"}) {
+		t.Errorf("RendezvousList = %q", got)
+	}
+}
+
+func TestValidateNodeConfigTransports(t *testing.T) {
+	listen := []string{"/ip4/0.0.0.0/tcp/9100", "/ip4/0.0.0.0/udp/9100/quic-v1"}
+	for _, tc := range []struct {
+		transports []string
+		listen     []string
+		wantErr    bool
+	}{
+		{nil, listen, false},
+		{[]string{"tcp"}, listen, false},
+		{[]string{"quic", "tcp", "ws"}, listen, false},
+		{[]string{"udp"}, listen, true},
+		{[]string{"tcp", "tcp"}, listen, true},
+		{[]string{"ws"}, listen, true}, // no listen address left
+		{[]string{"quic"}, listen[:1], true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.ListenAddresses = tc.listen
+		cfg.Network.EnabledTransports = tc.transports
+		checkValidateNodeConfig(t, fmt.Sprintf("transports=%q listen=%q", tc.transports, tc.listen), cfg, tc.wantErr)
+	}
+
+	n := NetworkConfig{EnabledTransports: []string{"tcp", "ws"}}
+	if got := n.Transports(); !slices.Equal(got, []string{"tcp", "ws", "wss"}) {
+		t.Errorf("Transports = %q", got)
+	}
+	if n.ListenAddrEnabled(listen[1]) || !n.ListenAddrEnabled(listen[0]) {
+		t.Error("ListenAddrEnabled should drop only the QUIC address")
+	}
+	if got := (NetworkConfig{}).Transports(); !slices.Equal(got, NodeTransports) {
+		t.Errorf("default Transports = %q, want NodeTransports", got)
+	}
+}
+
+func TestValidateListenAddresses(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		addrs []string
+		bad   []string // indexes expected in the error
+	}{
+		{"dual-stack tcp+quic", []string{
+			"/ip4/0.0.0.0/tcp/9100", "/ip4/0.0.0.0/udp/9100/quic-v1",
+			"/ip6/::/tcp/9100", "/ip6/::/udp/9100/quic-v1",
+		}, nil},
+		{"ephemeral ports", []string{"/ip4/0.0.0.0/tcp/0", "/ip4/0.0.0.0/tcp/0/ws"}, nil},
+		{"quic and webtransport share udp", []string{
+			"/ip4/0.0.0.0/udp/9100/quic-v1", "/ip4/0.0.0.0/udp/9100/quic-v1/webtransport",
+		}, nil},
+		{"different IPs", []string{"/ip4/10.0.0.1/tcp/9100", "/ip4/10.0.0.2/tcp/9100"}, nil},
+		{"duplicate tcp", []string{"/ip4/0.0.0.0/tcp/9100", "/ip4/0.0.0.0/tcp/9100"}, []string{"[1]"}},
+		{"wildcard overlaps specific IP", []string{"/ip4/0.0.0.0/tcp/9100", "/ip4/10.0.0.1/tcp/9100"}, []string{"[1]"}},
+		{"tcp and ws on one port", []string{"/ip6/::/tcp/443", "/ip6/::/tcp/443/ws"}, []string{"[1]"}},
+		{"two quic on one port", []string{"/ip4/0.0.0.0/udp/9100/quic-v1", "/ip4/0.0.0.0/udp/9100/quic-v1"}, []string{"[1]"}},
+		{"every problem reported", []string{
+			"/ip4/0.0.0.0/tcp/9100", "not-a-multiaddr", "/ip4/0.0.0.0/tcp/9100",
+			"/ip4/0.0.0.0/udp/9100/quic-v1", "/ip4/1.2.3.4/udp/9100/quic-v1",
+		}, []string{"[1]", "[2]", "[4]"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateListenAddresses(tc.addrs)
+			if len(tc.bad) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := strings.Count(err.Error(), "network.listen_addresses["); got != len(tc.bad) {
+				t.Errorf("error lists %d addresses, want %d: %v", got, len(tc.bad), err)
+			}
+			for _, idx := range tc.bad {
+				if !strings.Contains(err.Error(), "network.listen_addresses"+idx) {
+					t.Errorf("error does not mention %s: %v", idx, err)
+				}
+			}
+		})
+	}
+
+	relay := RelayServerConfig{
+		Identity: IdentityConfig{KeyFile: "x"},
+		Network:  RelayNetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/7777", "/ip4/0.0.0.0/tcp/7777/ws"}},
+	}
+	if err := ValidateRelayServerConfig(&relay); err == nil || !strings.Contains(err.Error(), "binds the same tcp port 7777") {
+		t.Errorf("ValidateRelayServerConfig = %v, want port collision error", err)
+	}
+}
+
+func TestValidateNodeConfigIPMode(t *testing.T) {
+	dual := []string{"/ip4/0.0.0.0/tcp/9100", "/ip6/::/tcp/9100"}
+	v4 := dual[:1]
+	for _, tc := range []struct {
+		mode    string
+		listen  []string
+		wantErr bool
+	}{
+		{"", dual, false},
+		{"dual", dual, false},
+		{"ipv6", dual, false},
+		{"ipv4", v4, false},
+		{"ipv6", v4, true}, // no IPv6 listen address left
+		{"IPv6", dual, true},
+		{"v6", dual, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.ListenAddresses = tc.listen
+		cfg.Network.IPMode = tc.mode
+		checkValidateNodeConfig(t, fmt.Sprintf("ip_mode=%q listen=%q", tc.mode, tc.listen), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigKeepaliveInterval(t *testing.T) {
+	for _, tc := range []struct {
+		interval time.Duration
+		wantErr  bool
+	}{
+		{0, false}, {5 * time.Second, false}, {25 * time.Second, false},
+		{-time.Second, true}, {time.Second, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.KeepaliveInterval = tc.interval
+		checkValidateNodeConfig(t, fmt.Sprintf("keepalive_interval=%s", tc.interval), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigIdleConnectionTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		timeout time.Duration
+		wantErr bool
+	}{
+		{0, false}, {30 * time.Second, false}, {10 * time.Minute, false},
+		{-time.Second, true}, {10 * time.Second, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.IdleConnectionTimeout = tc.timeout
+		checkValidateNodeConfig(t, fmt.Sprintf("idle_connection_timeout=%s", tc.timeout), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigAdminPeers(t *testing.T) {
+	for _, tc := range []struct {
+		peers   []string
+		wantErr bool
+	}{
+		{nil, false},
+		{[]string{"12D3KooWLbSsXnt4ANrYQ5YBEyefgFTxV3nNRdtAuWqEGQMUUiGf"}, false},
+		{[]string{"home"}, true}, // names are not accepted
+		{[]string{"12D3KooWLbSsXnt4ANrYQ5YBEyefgFTxV3nNRdtAuWqEGQMUUiGf", ""}, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Security.AdminPeers = tc.peers
+		checkValidateNodeConfig(t, fmt.Sprintf("admin_peers=%q", tc.peers), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigQUICTuning(t *testing.T) {
+	for _, tc := range []struct {
+		quic    QUICConfig
+		wantErr bool
+	}{
+		{QUICConfig{}, false},
+		{QUICConfig{InitialPacketSize: 1200, DisableMTUDiscovery: true, KeepalivePeriod: 5 * time.Second}, false},
+		{QUICConfig{InitialPacketSize: 1452}, false},
+		{QUICConfig{InitialPacketSize: 1000}, true},
+		{QUICConfig{InitialPacketSize: 1500}, true},
+		{QUICConfig{KeepalivePeriod: 500 * time.Millisecond}, true},
+		{QUICConfig{KeepalivePeriod: time.Minute}, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.QUIC = tc.quic
+		checkValidateNodeConfig(t, fmt.Sprintf("quic=%+v", tc.quic), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigTorProxy(t *testing.T) {
+	for _, tc := range []struct {
+		proxy   string
+		wantErr bool
+	}{
+		{"", false}, {"127.0.0.1:9050", false}, {"localhost:9150", false},
+		{"127.0.0.1", true}, {"tor", true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.Tor.SOCKSProxy = tc.proxy
+		checkValidateNodeConfig(t, fmt.Sprintf("tor.socks_proxy=%q", tc.proxy), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigSTUNServers(t *testing.T) {
+	for _, tc := range []struct {
+		servers []string
+		wantErr bool
+	}{
+		{nil, false},
+		{[]string{"stun.example.lan:3478"}, false},
+		{[]string{"10.0.0.1:3478", "[fd00::1]:3478"}, false},
+		{[]string{"stun.example.lan"}, true},
+		{[]string{"10.0.0.1:3478", "stun:3478:1"}, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.STUNServers = tc.servers
+		checkValidateNodeConfig(t, fmt.Sprintf("stun_servers=%q", tc.servers), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigAdvertiseExclude(t *testing.T) {
+	for _, tc := range []struct {
+		exclude []string
+		wantErr bool
+	}{
+		{nil, false}, {[]string{"ula"}, false}, {[]string{"ula", "link-local", "loopback"}, false},
+		{[]string{"global"}, true}, {[]string{"ULA"}, true}, {[]string{"lan"}, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Network.AdvertiseExclude = tc.exclude
+		checkValidateNodeConfig(t, fmt.Sprintf("advertise_exclude=%v", tc.exclude), cfg, tc.wantErr)
+	}
+}
+
+func TestValidateRegions(t *testing.T) {
+	for _, tc := range []struct {
+		region  string
+		wantErr bool
+	}{
+		{"", false}, {"au-sydney", false}, {"eu-west-1", false},
+		{"AU-Sydney", true}, {"au sydney", true}, {"(au)", true},
+	} {
+		relay := &RelayServerConfig{
+			Identity: IdentityConfig{KeyFile: "key"},
+			Network:  RelayNetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/7777"}},
+			Region:   tc.region,
+		}
+		if err := ValidateRelayServerConfig(relay); (err != nil) != tc.wantErr {
+			t.Errorf("relay region=%q: err=%v, wantErr=%v", tc.region, err, tc.wantErr)
+		}
+		node := validNodeConfig()
+		node.Relay.PreferredRegion = tc.region
+		checkValidateNodeConfig(t, fmt.Sprintf("relay.preferred_region=%q", tc.region), node, tc.wantErr)
+	}
+}
+
+func TestValidateControlMaxProxies(t *testing.T) {
+	for _, tc := range []struct {
+		max     int
+		wantErr bool
+	}{{0, false}, {1, false}, {500, false}, {-1, true}} {
+		node := validNodeConfig()
+		node.Control.MaxProxies = tc.max
+		checkValidateNodeConfig(t, fmt.Sprintf("control.max_proxies=%d", tc.max), node, tc.wantErr)
+	}
+	if got := (&ControlConfig{}).MaxProxiesOrDefault(); got != DefaultMaxProxies {
+		t.Errorf("MaxProxiesOrDefault() = %d, want %d", got, DefaultMaxProxies)
+	}
+}
+
+func TestValidateNodeConfigIsolationHook(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		hook    IsolationHookConfig
+		wantErr bool
+	}{
+		{"unset", IsolationHookConfig{}, false},
+		{"url", IsolationHookConfig{URL: "https://alerts.example.com/hook"}, false},
+		{"command with after", IsolationHookConfig{Command: "/usr/local/bin/alert", After: 10 * time.Minute}, false},
+		{"after too short", IsolationHookConfig{Command: "alert", After: 5 * time.Second}, true},
+		{"non-http url", IsolationHookConfig{URL: "ftp://example.com/hook"}, true},
+		{"url without host", IsolationHookConfig{URL: "https://"}, true},
+		{"blank command", IsolationHookConfig{Command: "   "}, true},
+	} {
+		cfg := validNodeConfig()
+		cfg.Telemetry.OnIsolated = tc.hook
+		checkValidateNodeConfig(t, tc.name, cfg, tc.wantErr)
+	}
+}
+
+func TestValidateNodeConfigRelayDisabled(t *testing.T) {
+	disabled := false
+	cfg := validNodeConfig()
+	cfg.Relay = RelayConfig{Enabled: &disabled}
+
+	origGlobal := hasGlobalAddress
+	defer func() { hasGlobalAddress = origGlobal }()
+
+	hasGlobalAddress = func() bool { return false }
+	if err := ValidateNodeConfig(&cfg); err != nil {
+		t.Fatalf("relay disabled with no addresses rejected: %v", err)
+	}
+	if w := NodeConfigWarnings(&cfg); len(w) != 1 || !strings.Contains(w[0], "may be unable to reach this node") {
+		t.Errorf("warnings = %q, want the unreachability warning", w)
+	}
+
+	hasGlobalAddress = func() bool { return true }
+	if w := NodeConfigWarnings(&cfg); len(w) != 0 {
+		t.Errorf("unexpected warnings with a global address: %q", w)
+	}
+
+	// Enabled (the default) still requires at least one relay address.
+	cfg.Relay.Enabled = nil
+	if err := ValidateNodeConfig(&cfg); err == nil {
+		t.Error("expected error for enabled relay with no addresses")
+	}
+}
+
+func TestRelayConfigActiveAddresses(t *testing.T) {
+	rc := RelayConfig{Addresses: []string{"/ip4/1.2.3.4/tcp/7777/p2p/X"}}
+	if !rc.IsEnabled() || len(rc.ActiveAddresses()) != 1 {
+		t.Errorf("default relay config: enabled=%v active=%v", rc.IsEnabled(), rc.ActiveAddresses())
+	}
+	disabled := false
+	rc.Enabled = &disabled
+	if rc.IsEnabled() || rc.ActiveAddresses() != nil {
+		t.Errorf("disabled relay config: enabled=%v active=%v", rc.IsEnabled(), rc.ActiveAddresses())
+	}
+	if len(rc.Addresses) != 1 {
+		t.Error("disabling relays should keep configured addresses")
+	}
+}
+
+func TestIsGlobalIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		want bool
+	}{
+		{"203.0.113.50", true},
+		{"2001:db8::1", true},
+		{"192.168.1.10", false},
+		{"10.0.0.1", false},
+		{"100.64.1.1", false},
+		{"127.0.0.1", false},
+		{"169.254.1.1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::1", false},
+	} {
+		if got := isGlobalIP(net.ParseIP(tc.ip)); got != tc.want {
+			t.Errorf("isGlobalIP(%s) = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestValidateNodeConfigServiceNames(t *testing.T) {
+	base := validNodeConfig()
 
 	// Valid service names should pass
 	valid := base
@@ -445,6 +1059,61 @@ func TestValidateNodeConfigServiceNames(t *testing.T) {
 	if err := ValidateNodeConfig(&invalid3); err == nil {
 		t.Error("expected error for uppercase service name 'SSH'")
 	}
+
+	// Negative bandwidth limit should fail; 0 (unlimited) and positive pass
+	limited := base
+	limited.Services = ServicesConfig{
+		"files": {Enabled: true, LocalAddress: "localhost:8080", MaxBandwidthMbps: 20},
+	}
+	if err := ValidateNodeConfig(&limited); err != nil {
+		t.Errorf("positive max_bandwidth_mbps rejected: %v", err)
+	}
+	negative := base
+	negative.Services = ServicesConfig{
+		"files": {Enabled: true, LocalAddress: "localhost:8080", MaxBandwidthMbps: -1},
+	}
+	if err := ValidateNodeConfig(&negative); err == nil {
+		t.Error("expected error for negative max_bandwidth_mbps")
+	}
+
+	// Per-peer stream limits: positive pass, negative fail
+	peerLimited := base
+	peerLimited.Services = ServicesConfig{
+		"ssh": {Enabled: true, LocalAddress: "localhost:22", MaxConcurrent: 4, RatePerMinute: 30},
+	}
+	if err := ValidateNodeConfig(&peerLimited); err != nil {
+		t.Errorf("positive per-peer limits rejected: %v", err)
+	}
+	for _, svc := range []ServiceConfig{
+		{Enabled: true, LocalAddress: "localhost:22", MaxConcurrent: -1},
+		{Enabled: true, LocalAddress: "localhost:22", RatePerMinute: -1},
+	} {
+		bad := base
+		bad.Services = ServicesConfig{"ssh": svc}
+		if err := ValidateNodeConfig(&bad); err == nil {
+			t.Errorf("expected error for negative limit in %+v", svc)
+		}
+	}
+
+	// Service network: tcp and udp pass, anything else fails, and UDP
+	// services can't have HTTP routes.
+	for _, network := range []string{"", "tcp", "udp"} {
+		ok := base
+		ok.Services = ServicesConfig{"dns": {Enabled: true, LocalAddress: "localhost:53", Network: network}}
+		if err := ValidateNodeConfig(&ok); err != nil {
+			t.Errorf("network %q rejected: %v", network, err)
+		}
+	}
+	for _, svc := range []ServiceConfig{
+		{Enabled: true, LocalAddress: "localhost:53", Network: "sctp"},
+		{Enabled: true, LocalAddress: "localhost:53", Network: "udp", HTTPRoutes: []HTTPRouteConfig{{Path: "/", Backend: "localhost:80"}}},
+	} {
+		bad := base
+		bad.Services = ServicesConfig{"dns": svc}
+		if err := ValidateNodeConfig(&bad); err == nil {
+			t.Errorf("expected error for %+v", svc)
+		}
+	}
 }
 
 func TestParseDataSize(t *testing.T) {
@@ -664,6 +1333,38 @@ names:
 	}
 }
 
+func TestLoadClientNodeConfigRelayDisabled(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `
+identity:
+  key_file: "client.key"
+network:
+  listen_addresses:
+    - "/ip4/0.0.0.0/tcp/0"
+relay:
+  enabled: false
+discovery:
+  rendezvous: "shurli-test-net"
+protocols:
+  ping_pong:
+    enabled: true
+    id: "/pingpong/1.0.0"
+`
+	path := filepath.Join(dir, "client.yaml")
+	os.WriteFile(path, []byte(yaml), 0600)
+
+	cfg, err := LoadClientNodeConfig(path)
+	if err != nil {
+		t.Fatalf("LoadClientNodeConfig: %v", err)
+	}
+	if cfg.Relay.IsEnabled() {
+		t.Error("relay should be disabled")
+	}
+	if err := ValidateClientNodeConfig(cfg); err != nil {
+		t.Errorf("ValidateClientNodeConfig: %v", err)
+	}
+}
+
 func TestLoadClientNodeConfigMissing(t *testing.T) {
 	_, err := LoadClientNodeConfig("/nonexistent/path.yaml")
 	if err == nil {
@@ -753,3 +1454,68 @@ func TestValidateClientNodeConfig(t *testing.T) {
 	}
 }
 
+func TestFindConfigFileProfile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"shurli.yaml", "work.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("identity:\n  key_file: x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ConfigEnvVar, "")
+	t.Setenv(legacyConfigEnvVar, "")
+	origDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(origDir)
+	defer SetProfile("")
+
+	if err := SetProfile("work"); err != nil {
+		t.Fatalf("SetProfile: %v", err)
+	}
+	found, err := FindConfigFile("")
+	if err != nil {
+		t.Fatalf("FindConfigFile: %v", err)
+	}
+	if found != "work.yaml" {
+		t.Errorf("found = %q, want %q", found, "work.yaml")
+	}
+
+	// A profile never falls back to the default profile's config.
+	if err := SetProfile("home-lab"); err != nil {
+		t.Fatalf("SetProfile: %v", err)
+	}
+	if found, err := FindConfigFile(""); !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("FindConfigFile = %q, %v; want ErrConfigNotFound", found, err)
+	}
+}
+
+func TestSetProfile(t *testing.T) {
+	defer SetProfile("")
+
+	if err := SetProfile("../etc"); err == nil {
+		t.Error("SetProfile accepted a path")
+	}
+	if err := SetProfile("config"); err == nil {
+		t.Error("SetProfile accepted a reserved name")
+	}
+
+	if err := SetProfile(""); err != nil {
+		t.Fatalf("SetProfile(\"\"): %v", err)
+	}
+	if got := []string{ProfileConfigName(), ProfileKeyFileName(), ProfileAuthorizedKeysName()}; got[0] != "config.yaml" || got[1] != "identity.key" || got[2] != "authorized_keys" {
+		t.Errorf("default profile files = %v", got)
+	}
+	if got := ProfileFileName("plugins"); got != "plugins" {
+		t.Errorf("default profile plugins dir = %q, want %q", got, "plugins")
+	}
+
+	if err := SetProfile("work"); err != nil {
+		t.Fatalf("SetProfile: %v", err)
+	}
+	if got := []string{ProfileConfigName(), ProfileKeyFileName(), ProfileAuthorizedKeysName()}; got[0] != "work.yaml" || got[1] != "work.key" || got[2] != "work-authorized_keys" {
+		t.Errorf("work profile files = %v", got)
+	}
+	if got := ProfileFileName("plugins"); got != "work-plugins" {
+		t.Errorf("work profile plugins dir = %q, want %q", got, "work-plugins")
+	}
+}
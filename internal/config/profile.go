@@ -0,0 +1,61 @@
+package config
+
+import "github.com/shurlinet/shurli/internal/validate"
+
+// profile is the named profile selected with the global --profile flag.
+// Empty means the default profile (config.yaml, identity.key,
+// authorized_keys). It is set once at startup, before any config lookup.
+var profile string
+
+// SetProfile selects a named profile for this process. An empty name
+// selects the default profile.
+func SetProfile(name string) error {
+	if name != "" {
+		if err := validate.ProfileName(name); err != nil {
+			return err
+		}
+	}
+	profile = name
+	return nil
+}
+
+// Profile returns the active profile name, or "" for the default profile.
+func Profile() string {
+	return profile
+}
+
+// ProfileConfigName returns the config file name for the active profile:
+// config.yaml by default, <profile>.yaml otherwise.
+func ProfileConfigName() string {
+	if profile == "" {
+		return "config.yaml"
+	}
+	return profile + ".yaml"
+}
+
+// ProfileKeyFileName returns the identity key file name for the active
+// profile: identity.key by default, <profile>.key otherwise.
+func ProfileKeyFileName() string {
+	if profile == "" {
+		return "identity.key"
+	}
+	return profile + ".key"
+}
+
+// ProfileAuthorizedKeysName returns the authorized_keys file name for the
+// active profile: authorized_keys by default, <profile>-authorized_keys
+// otherwise.
+func ProfileAuthorizedKeysName() string {
+	return ProfileFileName("authorized_keys")
+}
+
+// ProfileFileName namespaces a per-node file kept in the config directory
+// (authorized_keys, grants.json, peer_history.json, ...): name itself for
+// the default profile, <profile>-<name> otherwise. Two profiles sharing a
+// directory therefore never write the same state file.
+func ProfileFileName(name string) string {
+	if profile == "" {
+		return name
+	}
+	return profile + "-" + name
+}
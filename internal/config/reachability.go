@@ -0,0 +1,30 @@
+package config
+
+import "net"
+
+// cgnatRange is RFC 6598 shared address space: routable only inside the
+// carrier's network, so it doesn't make a node reachable.
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// hasGlobalAddress reports whether any local interface has a publicly
+// routable IPv4 or IPv6 address. It is a variable so tests can simulate
+// either case without depending on the host's network.
+var hasGlobalAddress = func() bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if ok && isGlobalIP(ipNet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGlobalIP reports whether ip is a public unicast address (not loopback,
+// link-local, RFC 1918 / ULA private, or CGNAT).
+func isGlobalIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnatRange.Contains(ip)
+}
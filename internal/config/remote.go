@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxRemoteConfigSize caps how much FetchConfigURL reads from a config
+// server. Real configs are a few KB; anything larger is refused.
+const MaxRemoteConfigSize = 1 << 20
+
+// remoteConfigFileName is the local copy of a config fetched from a URL,
+// kept in the user config directory so relative key_file and
+// authorized_keys_file paths still resolve to local files.
+const remoteConfigFileName = "remote-config.yaml"
+
+// remoteConfigClient fetches config URLs. Replaced in tests.
+var remoteConfigClient = &http.Client{Timeout: 30 * time.Second}
+
+// allowInsecureConfigURL permits http:// config URLs. Set once at startup
+// from --insecure-config-url.
+var allowInsecureConfigURL bool
+
+// SetInsecureConfigURL allows (or disallows) plain http config URLs for
+// this process. https is always allowed.
+func SetInsecureConfigURL(allow bool) {
+	allowInsecureConfigURL = allow
+}
+
+// IsConfigURL reports whether a --config value is an http(s) URL rather
+// than a file path.
+func IsConfigURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// FetchConfigURL downloads a node config from rawURL into dir and returns
+// the path of the local copy. The download goes to a temp file that must
+// parse as a node config before it replaces any previous copy, so a failed
+// or partial fetch never leaves a config behind. Only https is accepted
+// unless allowHTTP is set, including across redirects.
+func FetchConfigURL(ctx context.Context, rawURL, dir string, allowHTTP bool) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid URL: %v", ErrConfigFetch, err)
+	}
+	if err := checkConfigURLScheme(u, allowHTTP); err != nil {
+		return "", err
+	}
+
+	client := *remoteConfigClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("too many redirects")
+		}
+		return checkConfigURLScheme(req.URL, allowHTTP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConfigFetch, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrConfigFetch, u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s: HTTP %d", ErrConfigFetch, u.Redacted(), resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxRemoteConfigSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrConfigFetch, u.Redacted(), err)
+	}
+	if len(data) > MaxRemoteConfigSize {
+		return "", fmt.Errorf("%w: %s: config larger than %d bytes", ErrConfigFetch, u.Redacted(), MaxRemoteConfigSize)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConfigFetch, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".remote-config-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConfigFetch, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("%w: %v", ErrConfigFetch, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConfigFetch, err)
+	}
+
+	if _, err := LoadNodeConfig(tmpPath); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrConfigFetch, u.Redacted(), err)
+	}
+
+	dest := filepath.Join(dir, ProfileFileName(remoteConfigFileName))
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConfigFetch, err)
+	}
+	return dest, nil
+}
+
+func checkConfigURLScheme(u *url.URL, allowHTTP bool) error {
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return nil
+	case "http":
+		if allowHTTP {
+			return nil
+		}
+		return fmt.Errorf("%w: %s (use --insecure-config-url to allow plain http)", ErrInsecureConfigURL, u.Redacted())
+	default:
+		return fmt.Errorf("%w: unsupported scheme %q", ErrConfigFetch, u.Scheme)
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useRemoteConfigClient points FetchConfigURL at a test server's client
+// (which trusts the server's self-signed certificate).
+func useRemoteConfigClient(t *testing.T, c *http.Client) {
+	t.Helper()
+	old := remoteConfigClient
+	remoteConfigClient = c
+	t.Cleanup(func() { remoteConfigClient = old })
+}
+
+func TestFetchConfigURL(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("version: 1\n" + testConfigYAML))
+	}))
+	defer srv.Close()
+	useRemoteConfigClient(t, srv.Client())
+
+	dir := t.TempDir()
+	path, err := FetchConfigURL(context.Background(), srv.URL+"/node.yaml", dir, false)
+	if err != nil {
+		t.Fatalf("FetchConfigURL: %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("config written to %s, want it in %s", path, dir)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config mode = %04o, want 0600", perm)
+	}
+	cfg, err := LoadNodeConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNodeConfig: %v", err)
+	}
+	if cfg.Discovery.Rendezvous != "shurli-test-net" {
+		t.Errorf("Rendezvous = %q", cfg.Discovery.Rendezvous)
+	}
+
+	// Relative identity paths resolve to the local directory, not the server.
+	ResolveConfigPaths(cfg, filepath.Dir(path))
+	if want := filepath.Join(dir, "identity.key"); cfg.Identity.KeyFile != want {
+		t.Errorf("KeyFile = %q, want %q", cfg.Identity.KeyFile, want)
+	}
+}
+
+func TestFetchConfigURLRequiresHTTPS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("version: 1\n" + testConfigYAML))
+	}))
+	defer srv.Close()
+	useRemoteConfigClient(t, srv.Client())
+
+	dir := t.TempDir()
+	_, err := FetchConfigURL(context.Background(), srv.URL, dir, false)
+	if !errors.Is(err, ErrInsecureConfigURL) {
+		t.Fatalf("http without opt-in: err = %v, want ErrInsecureConfigURL", err)
+	}
+	if _, err := FetchConfigURL(context.Background(), srv.URL, dir, true); err != nil {
+		t.Fatalf("http with opt-in: %v", err)
+	}
+}
+
+func TestFetchConfigURLRefusesRedirectToHTTP(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("version: 1\n" + testConfigYAML))
+	}))
+	defer plain.Close()
+	tlsSrv := httptest.NewTLSServer(http.RedirectHandler(plain.URL, http.StatusFound))
+	defer tlsSrv.Close()
+	useRemoteConfigClient(t, tlsSrv.Client())
+
+	_, err := FetchConfigURL(context.Background(), tlsSrv.URL, t.TempDir(), false)
+	if !errors.Is(err, ErrInsecureConfigURL) {
+		t.Fatalf("err = %v, want ErrInsecureConfigURL", err)
+	}
+}
+
+func TestFetchConfigURLFailsClosed(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"not found", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}},
+		{"too large", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("version: 1\n" + testConfigYAML + "#" + strings.Repeat("x", MaxRemoteConfigSize) + "\n"))
+		}},
+		{"invalid yaml", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("identity: [unterminated\n"))
+		}},
+		{"version too new", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("version: 999\n" + testConfigYAML))
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(tc.handler)
+			defer srv.Close()
+			useRemoteConfigClient(t, srv.Client())
+
+			// A copy from an earlier successful fetch must not be used or
+			// replaced when the fetch fails.
+			dir := t.TempDir()
+			prev := filepath.Join(dir, ProfileFileName(remoteConfigFileName))
+			if err := os.WriteFile(prev, []byte("previous"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			path, err := FetchConfigURL(context.Background(), srv.URL, dir, false)
+			if !errors.Is(err, ErrConfigFetch) {
+				t.Fatalf("err = %v, want ErrConfigFetch", err)
+			}
+			if path != "" {
+				t.Errorf("path = %q on failure, want empty", path)
+			}
+			if data, _ := os.ReadFile(prev); string(data) != "previous" {
+				t.Errorf("previous copy overwritten: %q", data)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Errorf("temp files left behind: %v", entries)
+			}
+		})
+	}
+}
+
+func TestFindConfigFileURL(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("version: 1\n" + testConfigYAML))
+	}))
+	defer srv.Close()
+	useRemoteConfigClient(t, srv.Client())
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path, err := FindConfigFile(srv.URL + "/node.yaml")
+	if err != nil {
+		t.Fatalf("FindConfigFile: %v", err)
+	}
+	if want := filepath.Join(home, ".shurli", "remote-config.yaml"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSON Schemas for the config files, generated from the structs so they
+// can't drift from what the loader accepts. Editors pick them up through
+// yaml-language-server, e.g. a first line of
+//
+//	# yaml-language-server: $schema=./shurli.schema.json
+//
+// Struct fields can refine the generated schema with a `schema` tag, a
+// comma-separated list of:
+//
+//	required  the validator rejects the config without it
+//	nonempty  a list that needs at least one entry
+//	duration  a string parsed with time.ParseDuration
+//	datasize  a string parsed with ParseDataSize
+//
+// Fields of type time.Duration are durations without a tag. Enumerated
+// values come from schemaEnums.
+
+// durationPattern matches what time.ParseDuration accepts.
+const durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+// dataSizePattern matches what ParseDataSize accepts.
+const dataSizePattern = `^\s*[0-9]+\s*([bB]|[kK][bB]|[mM][bB]|[gG][bB])?\s*$`
+
+// schemaEnums lists the allowed values of fields the validator checks
+// against a fixed set, keyed by "Type.Field".
+var schemaEnums = map[string][]string{
+	"HookConfig.Event":      HookEvents,
+	"ServiceConfig.Network": {"tcp", "udp"},
+}
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// NodeConfigSchema returns the JSON Schema for the node config
+// (config.yaml / shurli.yaml).
+func NodeConfigSchema() ([]byte, error) {
+	return configSchema(reflect.TypeOf(NodeConfig{}), "shurli node config")
+}
+
+// RelayServerConfigSchema returns the JSON Schema for relay-server.yaml.
+func RelayServerConfigSchema() ([]byte, error) {
+	return configSchema(reflect.TypeOf(RelayServerConfig{}), "shurli relay server config")
+}
+
+func configSchema(t reflect.Type, title string) ([]byte, error) {
+	s := schemaFor(t)
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["title"] = title
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// schemaFor returns the schema of a Go type as the YAML decoder sees it.
+func schemaFor(t reflect.Type) map[string]any {
+	if t == durationType {
+		// The decoder also takes a bare integer (nanoseconds), as in "0".
+		return map[string]any{"type": []string{"string", "integer"}, "pattern": durationPattern}
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		// Decoded by hand (e.g. plugins): accept any mapping.
+		return map[string]any{"type": "object"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema builds an object schema from a struct's yaml fields. A
+// nested section is required when it has required fields itself.
+func structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	addFields(t, props, &required)
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func addFields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			addFields(f.Type, props, required)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name) // yaml.v3 default
+		}
+
+		fs := schemaFor(f.Type)
+		isRequired := false
+		for _, opt := range strings.Split(f.Tag.Get("schema"), ",") {
+			switch opt {
+			case "required":
+				isRequired = true
+			case "nonempty":
+				fs["minItems"] = 1
+			case "duration":
+				fs["pattern"] = durationPattern
+			case "datasize":
+				fs["pattern"] = dataSizePattern
+			}
+		}
+		if values, ok := schemaEnums[t.Name()+"."+f.Name]; ok {
+			fs["enum"] = values
+		}
+		if _, ok := fs["required"]; ok {
+			isRequired = true
+		}
+
+		props[name] = fs
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
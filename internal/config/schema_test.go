@@ -0,0 +1,211 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func decodeSchema(t *testing.T, gen func() ([]byte, error)) map[string]any {
+	t.Helper()
+	data, err := gen()
+	if err != nil {
+		t.Fatalf("generate schema: %v", err)
+	}
+	var s map[string]any
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	return s
+}
+
+// property walks a dotted path of object properties.
+func property(t *testing.T, s map[string]any, path ...string) map[string]any {
+	t.Helper()
+	for _, p := range path {
+		props, _ := s["properties"].(map[string]any)
+		next, ok := props[p].(map[string]any)
+		if !ok {
+			t.Fatalf("schema has no property %q", p)
+		}
+		s = next
+	}
+	return s
+}
+
+func requiredOf(s map[string]any) []string {
+	var out []string
+	for _, r := range s["required"].([]any) {
+		out = append(out, r.(string))
+	}
+	return out
+}
+
+func TestNodeConfigSchema(t *testing.T) {
+	s := decodeSchema(t, NodeConfigSchema)
+
+	// Sections with required fields are required; optional ones are not.
+	req := requiredOf(s)
+	for _, want := range []string{"identity", "network", "discovery", "protocols"} {
+		if !slices.Contains(req, want) {
+			t.Errorf("required = %v, missing %q", req, want)
+		}
+	}
+	if slices.Contains(req, "services") {
+		t.Errorf("required = %v, services should be optional", req)
+	}
+	if got := requiredOf(property(t, s, "protocols", "ping_pong")); !slices.Equal(got, []string{"id"}) {
+		t.Errorf("ping_pong required = %v, want [id]", got)
+	}
+	if n := property(t, s, "network", "listen_addresses")["minItems"]; n != 1.0 {
+		t.Errorf("listen_addresses minItems = %v, want 1", n)
+	}
+
+	// time.Duration fields, including map values, are duration strings.
+	for _, path := range [][]string{
+		{"relay", "reservation_interval"},
+		{"discovery", "peer_cache_ttl"},
+	} {
+		if p := property(t, s, path...)["pattern"]; p != durationPattern {
+			t.Errorf("%v pattern = %v, want the duration pattern", path, p)
+		}
+	}
+	if p := property(t, s, "relay", "reservation_intervals")["additionalProperties"].(map[string]any)["pattern"]; p != durationPattern {
+		t.Errorf("reservation_intervals values pattern = %v", p)
+	}
+
+	// Enums come from the validator's lists.
+	svc := property(t, s, "services")["additionalProperties"].(map[string]any)
+	if enum := property(t, svc, "network")["enum"]; len(enum.([]any)) != 2 {
+		t.Errorf("services.*.network enum = %v", enum)
+	}
+}
+
+func TestRelayServerConfigSchema(t *testing.T) {
+	s := decodeSchema(t, RelayServerConfigSchema)
+
+	for path, want := range map[string]string{
+		"session_duration":   durationPattern,
+		"reservation_ttl":    durationPattern,
+		"session_data_limit": dataSizePattern,
+	} {
+		if p := property(t, s, "resources", path)["pattern"]; p != want {
+			t.Errorf("resources.%s pattern = %v, want %v", path, p, want)
+		}
+	}
+	if req := requiredOf(s); !slices.Contains(req, "identity") {
+		t.Errorf("required = %v, missing identity", req)
+	}
+}
+
+func TestSchemaPatterns(t *testing.T) {
+	dur := regexp.MustCompile(durationPattern)
+	for _, v := range []string{"0", "5m", "1h30m", "1.5h", "500ms", "10us", "-5s", ".5s"} {
+		if _, err := time.ParseDuration(v); err != nil {
+			t.Fatalf("bad test value %q: %v", v, err)
+		}
+		if !dur.MatchString(v) {
+			t.Errorf("duration pattern rejects %q", v)
+		}
+	}
+	for _, v := range []string{"5", "7d", "1 h", "h", ""} {
+		if _, err := time.ParseDuration(v); err == nil {
+			t.Fatalf("bad test value %q parses", v)
+		}
+		if dur.MatchString(v) {
+			t.Errorf("duration pattern accepts %q", v)
+		}
+	}
+
+	size := regexp.MustCompile(dataSizePattern)
+	for _, v := range []string{"64MB", "128kb", "1GB", "100", "0B", " 2 MB "} {
+		if _, err := ParseDataSize(v); err != nil {
+			t.Fatalf("bad test value %q: %v", v, err)
+		}
+		if !size.MatchString(v) {
+			t.Errorf("data size pattern rejects %q", v)
+		}
+	}
+	for _, v := range []string{"1TB", "1.5MB", "-1", "MB", ""} {
+		if _, err := ParseDataSize(v); err == nil {
+			t.Fatalf("bad test value %q parses", v)
+		}
+		if size.MatchString(v) {
+			t.Errorf("data size pattern accepts %q", v)
+		}
+	}
+}
+
+// TestSchemaSampleConfigs checks the shipped sample configs against the
+// schemas, so a key the loader accepts but the schema lacks (or the
+// reverse) shows up here.
+func TestSchemaSampleConfigs(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		gen  func() ([]byte, error)
+	}{
+		{"../../configs/shurli.sample.yaml", NodeConfigSchema},
+		{"../../configs/relay-server.sample.yaml", RelayServerConfigSchema},
+	} {
+		data, err := os.ReadFile(tc.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+		checkAgainstSchema(t, tc.file, doc.Content[0], decodeSchema(t, tc.gen))
+	}
+}
+
+// checkAgainstSchema is a minimal validator for the schema features the
+// generator emits.
+func checkAgainstSchema(t *testing.T, path string, n *yaml.Node, s map[string]any) {
+	t.Helper()
+	switch s["type"] {
+	case "object":
+		if n.Kind != yaml.MappingNode {
+			t.Errorf("%s: want a mapping", path)
+			return
+		}
+		props, _ := s["properties"].(map[string]any)
+		extra, _ := s["additionalProperties"].(map[string]any)
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i].Value, n.Content[i+1]
+			seen[key] = true
+			if ps, ok := props[key].(map[string]any); ok {
+				checkAgainstSchema(t, path+"."+key, val, ps)
+			} else if extra != nil {
+				checkAgainstSchema(t, path+"."+key, val, extra)
+			} else if s["additionalProperties"] == false {
+				t.Errorf("%s: unknown key %q", path, key)
+			}
+		}
+		if req, ok := s["required"].([]any); ok {
+			for _, r := range req {
+				if !seen[r.(string)] {
+					t.Errorf("%s: missing required %q", path, r)
+				}
+			}
+		}
+	case "array":
+		if n.Kind != yaml.SequenceNode {
+			t.Errorf("%s: want a list", path)
+			return
+		}
+		for _, item := range n.Content {
+			checkAgainstSchema(t, path+"[]", item, s["items"].(map[string]any))
+		}
+	case "string":
+		if p, ok := s["pattern"].(string); ok && !regexp.MustCompile(p).MatchString(n.Value) {
+			t.Errorf("%s: %q does not match %s", path, n.Value, p)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shurlinet/shurli/internal/validate"
+)
+
+// MaxServicePortRange bounds how many ports one range service may span.
+// Each port is a separate libp2p protocol handler; a typo like 1-65535
+// must not register tens of thousands of them.
+const MaxServicePortRange = 256
+
+// ServiceMember is one exposed port of a service. A single-address service
+// has one member named after the service; a port-range service has one
+// member per port, named by ServiceMemberName.
+type ServiceMember struct {
+	Name         string
+	LocalAddress string
+}
+
+// ParseServicePortRange splits a local_address of the form host:first-last
+// (e.g. "localhost:8000-8010"). ok is false when addr is a plain host:port;
+// err reports a malformed or unreasonable range.
+func ParseServicePortRange(addr string) (host string, first, last int, ok bool, err error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, 0, false, err
+	}
+	lo, hi, isRange := strings.Cut(port, "-")
+	if !isRange {
+		return host, 0, 0, false, nil
+	}
+	first, err1 := strconv.Atoi(lo)
+	last, err2 := strconv.Atoi(hi)
+	if err1 != nil || err2 != nil {
+		return "", 0, 0, true, fmt.Errorf("invalid port range %q", port)
+	}
+	if first < 1 || last > 65535 || first > last {
+		return "", 0, 0, true, fmt.Errorf("invalid port range %q: need 1 <= first <= last <= 65535", port)
+	}
+	if n := last - first + 1; n > MaxServicePortRange {
+		return "", 0, 0, true, fmt.Errorf("port range %q spans %d ports (max %d)", port, n, MaxServicePortRange)
+	}
+	return host, first, last, true, nil
+}
+
+// ServiceMemberName names the member of range service name that serves
+// port, e.g. "myapp-8005". Each member is exposed as its own protocol
+// (/shurli/myapp-8005/1.0.0), so peers address one port of the set.
+func ServiceMemberName(name string, port int) string {
+	return fmt.Sprintf("%s-%d", name, port)
+}
+
+// ResolveServiceRef maps a "name:port" reference to a port within a range
+// service onto its member name. Any other reference is returned unchanged.
+func ResolveServiceRef(ref string) string {
+	name, portStr, ok := strings.Cut(ref, ":")
+	if !ok {
+		return ref
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return ref
+	}
+	return ServiceMemberName(name, port)
+}
+
+// Members expands the service into the addresses it exposes.
+func (s ServiceConfig) Members(name string) ([]ServiceMember, error) {
+	host, first, last, isRange, err := ParseServicePortRange(s.LocalAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !isRange {
+		return []ServiceMember{{Name: name, LocalAddress: s.LocalAddress}}, nil
+	}
+	members := make([]ServiceMember, 0, last-first+1)
+	for p := first; p <= last; p++ {
+		members = append(members, ServiceMember{
+			Name:         ServiceMemberName(name, p),
+			LocalAddress: net.JoinHostPort(host, strconv.Itoa(p)),
+		})
+	}
+	return members, nil
+}
+
+// ValidateServiceRanges checks port-range services: the range must be sane,
+// a custom protocol can't apply to a whole set, every member name must be a
+// valid service name not already used by another service, and the range
+// must not overlap another service's ports on the same host.
+func ValidateServiceRanges(services ServicesConfig) error {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type portSpan struct {
+		service     string
+		host        string
+		first, last int
+		isRange     bool
+	}
+	var spans []portSpan
+	for _, name := range names {
+		svc := services[name]
+		host, first, last, isRange, err := ParseServicePortRange(svc.LocalAddress)
+		if err != nil {
+			if isRange {
+				return fmt.Errorf("services.%s.local_address: %w", name, err)
+			}
+			continue // plain addresses are checked when exposed
+		}
+		if !isRange {
+			_, portStr, _ := net.SplitHostPort(svc.LocalAddress)
+			if port, err := strconv.Atoi(portStr); err == nil {
+				spans = append(spans, portSpan{name, host, port, port, false})
+			}
+			continue
+		}
+		if svc.Protocol != "" {
+			return fmt.Errorf("services.%s: protocol cannot be set on a port range", name)
+		}
+		for p := first; p <= last; p++ {
+			member := ServiceMemberName(name, p)
+			if err := validate.ServiceName(member); err != nil {
+				return fmt.Errorf("services.%s: member %w", name, err)
+			}
+			if _, exists := services[member]; exists {
+				return fmt.Errorf("services.%s: member %q collides with service %q", name, member, member)
+			}
+		}
+		spans = append(spans, portSpan{name, host, first, last, true})
+	}
+
+	for i := range spans {
+		for j := i + 1; j < len(spans); j++ {
+			a, b := spans[i], spans[j]
+			if !a.isRange && !b.isRange {
+				continue // two plain services may share a backend
+			}
+			if a.host == b.host && a.first <= b.last && b.first <= a.last {
+				return fmt.Errorf("services.%s and services.%s overlap on %s ports", a.service, b.service, a.host)
+			}
+		}
+	}
+	return nil
+}
+
+// Target describes where the service forwards to, for listings: the local
+// address, or a summary of its HTTP routes.
+func (s ServiceConfig) Target() string {
+	if len(s.HTTPRoutes) == 0 {
+		return s.LocalAddress
+	}
+	parts := make([]string, 0, len(s.HTTPRoutes))
+	for _, r := range s.HTTPRoutes {
+		parts = append(parts, r.Host+r.Path+"->"+r.Backend)
+	}
+	return "http " + strings.Join(parts, ", ")
+}
+
+// validateHTTPRoutes checks an http_routes service. Routes replace
+// local_address, and the bandwidth cap only applies to raw TCP proxying.
+func validateHTTPRoutes(name string, svc ServiceConfig) error {
+	if len(svc.HTTPRoutes) == 0 {
+		return nil
+	}
+	if svc.LocalAddress != "" {
+		return fmt.Errorf("services.%s: local_address and http_routes are mutually exclusive", name)
+	}
+	if svc.MaxBandwidthMbps != 0 {
+		return fmt.Errorf("services.%s: max_bandwidth_mbps is not supported with http_routes", name)
+	}
+	if svc.MaxConcurrent != 0 || svc.RatePerMinute != 0 {
+		return fmt.Errorf("services.%s: max_concurrent and rate_per_minute are not supported with http_routes", name)
+	}
+	seen := make(map[string]bool, len(svc.HTTPRoutes))
+	for i, r := range svc.HTTPRoutes {
+		if !strings.HasPrefix(r.Path, "/") {
+			return fmt.Errorf("services.%s.http_routes[%d].path must start with /, got %q", name, i, r.Path)
+		}
+		if _, _, err := net.SplitHostPort(r.Backend); err != nil {
+			return fmt.Errorf("services.%s.http_routes[%d].backend must be host:port, got %q", name, i, r.Backend)
+		}
+		key := strings.ToLower(r.Host) + r.Path
+		if seen[key] {
+			return fmt.Errorf("services.%s.http_routes[%d]: duplicate route for %s%s", name, i, r.Host, r.Path)
+		}
+		seen[key] = true
+	}
+	return nil
+}
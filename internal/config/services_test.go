@@ -0,0 +1,180 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseServicePortRange(t *testing.T) {
+	for _, tc := range []struct {
+		addr        string
+		host        string
+		first, last int
+		isRange     bool
+		wantErr     bool
+	}{
+		{"localhost:22", "localhost", 0, 0, false, false},
+		{"localhost:8000-8010", "localhost", 8000, 8010, true, false},
+		{"[::1]:9000-9000", "::1", 9000, 9000, true, false},
+		{"localhost:8010-8000", "", 0, 0, true, true},
+		{"localhost:0-10", "", 0, 0, true, true},
+		{"localhost:65000-65536", "", 0, 0, true, true},
+		{"localhost:1000-2000", "", 0, 0, true, true}, // > MaxServicePortRange
+		{"localhost:a-b", "", 0, 0, true, true},
+		{"localhost", "", 0, 0, false, true},
+	} {
+		host, first, last, isRange, err := ParseServicePortRange(tc.addr)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err=%v, wantErr=%v", tc.addr, err, tc.wantErr)
+			continue
+		}
+		if isRange != tc.isRange {
+			t.Errorf("%s: isRange=%v, want %v", tc.addr, isRange, tc.isRange)
+		}
+		if err == nil && (host != tc.host || first != tc.first || last != tc.last) {
+			t.Errorf("%s: got (%q, %d, %d), want (%q, %d, %d)", tc.addr, host, first, last, tc.host, tc.first, tc.last)
+		}
+	}
+}
+
+func TestServiceMembers(t *testing.T) {
+	single, err := ServiceConfig{LocalAddress: "localhost:22"}.Members("ssh")
+	if err != nil || len(single) != 1 || single[0].Name != "ssh" || single[0].LocalAddress != "localhost:22" {
+		t.Errorf("single: %+v, %v", single, err)
+	}
+
+	ranged, err := ServiceConfig{LocalAddress: "127.0.0.1:8000-8002"}.Members("myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ServiceMember{
+		{"myapp-8000", "127.0.0.1:8000"},
+		{"myapp-8001", "127.0.0.1:8001"},
+		{"myapp-8002", "127.0.0.1:8002"},
+	}
+	if len(ranged) != len(want) {
+		t.Fatalf("got %d members, want %d", len(ranged), len(want))
+	}
+	for i := range want {
+		if ranged[i] != want[i] {
+			t.Errorf("member %d = %+v, want %+v", i, ranged[i], want[i])
+		}
+	}
+}
+
+func TestResolveServiceRef(t *testing.T) {
+	for ref, want := range map[string]string{
+		"ssh":        "ssh",
+		"myapp:8005": "myapp-8005",
+		"myapp:abc":  "myapp:abc",
+	} {
+		if got := ResolveServiceRef(ref); got != want {
+			t.Errorf("ResolveServiceRef(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestValidateServiceRanges(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		services ServicesConfig
+		wantErr  string
+	}{
+		{"plain services sharing a port", ServicesConfig{
+			"ssh":     {LocalAddress: "localhost:22"},
+			"ssh-alt": {LocalAddress: "localhost:22"},
+		}, ""},
+		{"disjoint ranges", ServicesConfig{
+			"a": {LocalAddress: "localhost:8000-8010"},
+			"b": {LocalAddress: "localhost:8011-8020"},
+		}, ""},
+		{"same ports on another host", ServicesConfig{
+			"a": {LocalAddress: "localhost:8000-8010"},
+			"b": {LocalAddress: "10.0.0.5:8000-8010"},
+		}, ""},
+		{"overlapping ranges", ServicesConfig{
+			"a": {LocalAddress: "localhost:8000-8010"},
+			"b": {LocalAddress: "localhost:8010-8020"},
+		}, "overlap"},
+		{"range over plain service", ServicesConfig{
+			"a":   {LocalAddress: "localhost:8000-8010"},
+			"web": {LocalAddress: "localhost:8005"},
+		}, "overlap"},
+		{"member name collision", ServicesConfig{
+			"a":      {LocalAddress: "localhost:8000-8010"},
+			"a-8003": {LocalAddress: "10.0.0.5:80"},
+		}, "collides"},
+		{"protocol on range", ServicesConfig{
+			"a": {LocalAddress: "localhost:8000-8010", Protocol: "/custom/1.0.0"},
+		}, "protocol"},
+		{"bad range", ServicesConfig{
+			"a": {LocalAddress: "localhost:9000-8000"},
+		}, "invalid port range"},
+	} {
+		err := ValidateServiceRanges(tc.services)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err=%v, want containing %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateHTTPRoutes(t *testing.T) {
+	route := func(path, backend string) HTTPRouteConfig {
+		return HTTPRouteConfig{Path: path, Backend: backend}
+	}
+	for _, tc := range []struct {
+		name    string
+		svc     ServiceConfig
+		wantErr string
+	}{
+		{"no routes", ServiceConfig{LocalAddress: "localhost:80"}, ""},
+		{"valid routes", ServiceConfig{HTTPRoutes: []HTTPRouteConfig{
+			route("/app1", "localhost:8001"),
+			route("/app2", "localhost:8002"),
+			{Path: "/app1", Host: "docs.example", Backend: "localhost:8003"},
+		}}, ""},
+		{"with local_address", ServiceConfig{LocalAddress: "localhost:80",
+			HTTPRoutes: []HTTPRouteConfig{route("/", "localhost:8001")}}, "mutually exclusive"},
+		{"with bandwidth cap", ServiceConfig{MaxBandwidthMbps: 10,
+			HTTPRoutes: []HTTPRouteConfig{route("/", "localhost:8001")}}, "max_bandwidth_mbps"},
+		{"with peer limits", ServiceConfig{MaxConcurrent: 2,
+			HTTPRoutes: []HTTPRouteConfig{route("/", "localhost:8001")}}, "max_concurrent"},
+		{"relative path", ServiceConfig{HTTPRoutes: []HTTPRouteConfig{route("app1", "localhost:8001")}}, "must start with /"},
+		{"backend without port", ServiceConfig{HTTPRoutes: []HTTPRouteConfig{route("/app1", "localhost")}}, "host:port"},
+		{"duplicate", ServiceConfig{HTTPRoutes: []HTTPRouteConfig{
+			route("/app1", "localhost:8001"),
+			route("/app1", "localhost:8002"),
+		}}, "duplicate"},
+	} {
+		err := validateHTTPRoutes("web", tc.svc)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err=%v, want containing %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestServiceTarget(t *testing.T) {
+	if got := (ServiceConfig{LocalAddress: "localhost:22"}).Target(); got != "localhost:22" {
+		t.Errorf("Target() = %q, want localhost:22", got)
+	}
+	svc := ServiceConfig{HTTPRoutes: []HTTPRouteConfig{
+		{Path: "/app1", Backend: "localhost:8001"},
+		{Path: "/", Host: "docs.example", Backend: "localhost:8002"},
+	}}
+	want := "http /app1->localhost:8001, docs.example/->localhost:8002"
+	if got := svc.Target(); got != want {
+		t.Errorf("Target() = %q, want %q", got, want)
+	}
+}
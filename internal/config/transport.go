@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// NodeTransports lists the transports every node enables (see the host
+// options in pkg/sdk/network.go). A relay address using any other
+// transport can never be dialed.
+var NodeTransports = []string{"quic-v1", "tcp", "ws", "wss"}
+
+// TransportNames are the values network.transports accepts. Each switches
+// on a group of NodeTransports: quic is quic-v1, ws covers ws and wss.
+var TransportNames = []string{"tcp", "quic", "ws"}
+
+// transportGroup maps a NodeTransports entry to its TransportNames entry.
+func transportGroup(transport string) string {
+	switch transport {
+	case "quic-v1":
+		return "quic"
+	case "wss":
+		return "ws"
+	}
+	return transport
+}
+
+// ListenAddrEnabled reports whether a listen address uses a transport the
+// node runs. Addresses on transports outside NodeTransports (or that don't
+// parse) are reported as enabled, so libp2p gets to reject them as before.
+func (n NetworkConfig) ListenAddrEnabled(addr string) bool {
+	transport, err := AddrTransport(addr)
+	if err != nil || !IsNodeTransport(transport) {
+		return true
+	}
+	return n.TransportEnabled(transportGroup(transport))
+}
+
+// TorTransport is enabled in addition to NodeTransports when network.tor
+// is configured.
+const TorTransport = "onion3"
+
+// nonTransportProtocols are multiaddr components that address or wrap a
+// connection without being the transport itself.
+var nonTransportProtocols = map[int]bool{
+	ma.P_IP4:      true,
+	ma.P_IP6:      true,
+	ma.P_IP6ZONE:  true,
+	ma.P_DNS:      true,
+	ma.P_DNS4:     true,
+	ma.P_DNS6:     true,
+	ma.P_DNSADDR:  true,
+	ma.P_P2P:      true,
+	ma.P_TLS:      true,
+	ma.P_SNI:      true,
+	ma.P_CERTHASH: true,
+}
+
+// AddrTransport returns the outermost transport protocol of a multiaddr,
+// e.g. "quic-v1" for /ip4/1.2.3.4/udp/7777/quic-v1/p2p/... It returns ""
+// when the address names no transport (/dnsaddr records are resolved to
+// concrete addresses at dial time).
+func AddrTransport(addr string) (string, error) {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return "", err
+	}
+	transport := ""
+	for _, p := range maddr.Protocols() {
+		if !nonTransportProtocols[p.Code] {
+			transport = p.Name
+		}
+	}
+	return transport, nil
+}
+
+// IsNodeTransport reports whether name is one of NodeTransports.
+func IsNodeTransport(name string) bool {
+	return slices.Contains(NodeTransports, name)
+}
+
+// RelayTransportWarnings returns one warning per relay address whose
+// transport is not in enabled (see NetworkConfig.Transports). Unparseable
+// addresses are skipped; they are reported by the code that dials them.
+func RelayTransportWarnings(addrs, enabled []string) []string {
+	var warnings []string
+	for _, addr := range addrs {
+		transport, err := AddrTransport(addr)
+		if err != nil || transport == "" || slices.Contains(enabled, transport) {
+			continue
+		}
+		hint := ""
+		if transport == TorTransport {
+			hint = " (set network.tor.socks_proxy to dial onion addresses)"
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"relay address %s uses transport %q, which this node does not enable (supported: %v); it will never connect%s",
+			addr, transport, enabled, hint))
+	}
+	return warnings
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const testRelayPeer = "12D3KooWSC3sb3uKwHJ8g6GkjqVK5pN2JFSasBknoT3ciVdFWf3q"
+
+func TestAddrTransport(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"/ip4/1.2.3.4/tcp/7777/p2p/" + testRelayPeer, "tcp"},
+		{"/ip4/1.2.3.4/udp/7777/quic-v1/p2p/" + testRelayPeer, "quic-v1"},
+		{"/ip6/::1/tcp/443/ws/p2p/" + testRelayPeer, "ws"},
+		{"/dns4/relay.example.com/tcp/443/tls/sni/relay.example.com/ws/p2p/" + testRelayPeer, "ws"},
+		{"/dns4/relay.example.com/tcp/443/wss/p2p/" + testRelayPeer, "wss"},
+		{"/ip4/1.2.3.4/udp/443/quic-v1/webtransport/p2p/" + testRelayPeer, "webtransport"},
+		{"/ip4/1.2.3.4/udp/443/webrtc-direct/p2p/" + testRelayPeer, "webrtc-direct"},
+		{"/dnsaddr/relay.example.com/p2p/" + testRelayPeer, ""},
+	}
+	for _, tt := range tests {
+		got, err := AddrTransport(tt.addr)
+		if err != nil {
+			t.Errorf("AddrTransport(%s): %v", tt.addr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("AddrTransport(%s) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+
+	if _, err := AddrTransport("not-a-multiaddr"); err == nil {
+		t.Error("expected error for invalid multiaddr")
+	}
+}
+
+func TestRelayTransportWarnings(t *testing.T) {
+	addrs := []string{
+		"/ip4/1.2.3.4/tcp/7777/p2p/" + testRelayPeer,
+		"/ip4/1.2.3.4/udp/7777/quic-v1/p2p/" + testRelayPeer,
+		"/ip4/1.2.3.4/tcp/443/ws/p2p/" + testRelayPeer,
+		"/ip4/1.2.3.4/udp/443/quic-v1/webtransport/p2p/" + testRelayPeer,
+		"garbage",
+	}
+
+	warnings := RelayTransportWarnings(addrs, NodeTransports)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "webtransport") {
+		t.Fatalf("default transports: warnings = %v, want one webtransport warning", warnings)
+	}
+
+	// With WebSocket disabled, the /ws relay is flagged too.
+	orig := NodeTransports
+	NodeTransports = []string{"quic-v1", "tcp"}
+	defer func() { NodeTransports = orig }()
+
+	warnings = RelayTransportWarnings(addrs, NodeTransports)
+	if len(warnings) != 2 {
+		t.Fatalf("ws disabled: warnings = %v, want 2", warnings)
+	}
+	if !strings.Contains(warnings[0], `"ws"`) {
+		t.Errorf("expected ws warning first, got %q", warnings[0])
+	}
+}
+
+func TestRelayTransportWarningsOnion(t *testing.T) {
+	onion := "/onion3/vww6ybal4bd7szmgncyruucpgfkqahzddi37ktceo3ah7ngmcopnpyyd:7777/p2p/" + testRelayPeer
+	if got, err := AddrTransport(onion); err != nil || got != TorTransport {
+		t.Fatalf("AddrTransport(onion) = %q, %v", got, err)
+	}
+
+	var n NetworkConfig
+	warnings := RelayTransportWarnings([]string{onion}, n.Transports())
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "network.tor.socks_proxy") {
+		t.Fatalf("without tor: warnings = %v, want one onion3 warning", warnings)
+	}
+
+	n.Tor.SOCKSProxy = "127.0.0.1:9050"
+	if warnings := RelayTransportWarnings([]string{onion}, n.Transports()); len(warnings) != 0 {
+		t.Errorf("with tor: warnings = %v, want none", warnings)
+	}
+	if len(NodeTransports) != 4 {
+		t.Errorf("Transports() must not modify NodeTransports: %v", NodeTransports)
+	}
+}
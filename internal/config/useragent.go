@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxUserAgentPatterns bounds each of the allow and deny lists.
+const maxUserAgentPatterns = 64
+
+// UserAgentPolicy is soft admission control on the agent version a peer
+// announces via identify (e.g. "shurli/0.9.1", "relay-server/0.3.0 (syd-1)").
+// Patterns are globs where * matches any run of characters (including /)
+// and matching is case-sensitive. A deny match always refuses; if Allow is
+// non-empty, the agent must also match one of its patterns.
+//
+// Agent strings are chosen by the remote peer and trivially spoofed, so the
+// policy keeps outdated or unknown clients away; it is not authentication.
+type UserAgentPolicy struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+// Enabled reports whether any pattern is configured.
+func (p UserAgentPolicy) Enabled() bool {
+	return len(p.Allow) > 0 || len(p.Deny) > 0
+}
+
+// Allows reports whether agent passes the policy. An empty policy allows
+// everything; with an allow list, an empty (unreported) agent is refused.
+func (p UserAgentPolicy) Allows(agent string) bool {
+	for _, pat := range p.Deny {
+		if matchAgentGlob(pat, agent) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pat := range p.Allow {
+		if matchAgentGlob(pat, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks pattern counts and rejects empty patterns. field is the
+// config path used in error messages.
+func (p UserAgentPolicy) Validate(field string) error {
+	for _, list := range []struct {
+		name string
+		pats []string
+	}{{"allow", p.Allow}, {"deny", p.Deny}} {
+		if len(list.pats) > maxUserAgentPatterns {
+			return fmt.Errorf("%s.%s has %d patterns, max %d", field, list.name, len(list.pats), maxUserAgentPatterns)
+		}
+		for i, pat := range list.pats {
+			if strings.TrimSpace(pat) == "" {
+				return fmt.Errorf("%s.%s[%d] is empty", field, list.name, i)
+			}
+		}
+	}
+	return nil
+}
+
+// matchAgentGlob matches s against pattern, where * matches any run of
+// characters and every other character matches itself.
+func matchAgentGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
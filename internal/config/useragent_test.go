@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserAgentPolicyAllows(t *testing.T) {
+	policy := UserAgentPolicy{
+		Allow: []string{"shurli/0.*", "relay-server/*"},
+		Deny:  []string{"shurli/0.1.*"},
+	}
+	for _, tc := range []struct {
+		agent string
+		want  bool
+	}{
+		{"shurli/0.9.1", true},
+		{"shurli/0.10.0", true},
+		{"relay-server/0.3.0 (syd-1) region/au-sydney", true},
+		{"shurli/0.1.4", false},     // denied: outdated
+		{"shurli/1.0.0", false},     // not in allow list
+		{"go-libp2p/0.48.0", false}, // unknown client
+		{"", false},                 // agent not reported
+	} {
+		if got := policy.Allows(tc.agent); got != tc.want {
+			t.Errorf("Allows(%q) = %v, want %v", tc.agent, got, tc.want)
+		}
+	}
+
+	// Deny-only: everything not denied passes, including unreported agents.
+	denyOnly := UserAgentPolicy{Deny: []string{"*bot*"}}
+	if denyOnly.Allows("scanbot/1.0") {
+		t.Error("deny-only policy allowed a denied agent")
+	}
+	if !denyOnly.Allows("shurli/0.9.1") || !denyOnly.Allows("") {
+		t.Error("deny-only policy refused an agent it does not deny")
+	}
+
+	if !(UserAgentPolicy{}).Allows("anything") {
+		t.Error("empty policy should allow everything")
+	}
+}
+
+func TestMatchAgentGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"shurli/0.9.1", "shurli/0.9.1", true},
+		{"shurli/0.9.1", "shurli/0.9.10", false},
+		{"*", "", true},
+		{"shurli/*", "shurli/", true},
+		{"*/0.9.*", "shurli/0.9.1", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "aXbYc", true},
+		{"a*b*c", "acb", false},
+		{"ab*ba", "aba", false}, // prefix and suffix must not overlap
+	} {
+		if got := matchAgentGlob(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("matchAgentGlob(%q, %q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestUserAgentPolicyValidate(t *testing.T) {
+	if err := (UserAgentPolicy{Allow: []string{"shurli/*"}}).Validate("security.user_agent_policy"); err != nil {
+		t.Errorf("valid policy: %v", err)
+	}
+	err := (UserAgentPolicy{Deny: []string{"x", " "}}).Validate("security.user_agent_policy")
+	if err == nil || !strings.Contains(err.Error(), "security.user_agent_policy.deny[1]") {
+		t.Errorf("empty pattern: err = %v", err)
+	}
+	err = (UserAgentPolicy{Allow: make([]string, maxUserAgentPatterns+1)}).Validate("security.user_agent_policy")
+	if err == nil || !strings.Contains(err.Error(), "max") {
+		t.Errorf("too many patterns: err = %v", err)
+	}
+}
+
+func TestValidateUserAgentPolicyInConfigs(t *testing.T) {
+	bad := UserAgentPolicy{Allow: []string{""}}
+
+	node := validNodeConfig()
+	node.Security.UserAgentPolicy = bad
+	if err := ValidateNodeConfig(&node); err == nil || !strings.Contains(err.Error(), "user_agent_policy") {
+		t.Errorf("node: err = %v, want user_agent_policy error", err)
+	}
+
+	relay := RelayServerConfig{
+		Identity: IdentityConfig{KeyFile: "x"},
+		Network:  RelayNetworkConfig{ListenAddresses: []string{"/ip4/0.0.0.0/tcp/0"}},
+		Security: RelaySecurityConfig{UserAgentPolicy: bad},
+	}
+	if err := ValidateRelayServerConfig(&relay); err == nil || !strings.Contains(err.Error(), "user_agent_policy") {
+		t.Errorf("relay: err = %v, want user_agent_policy error", err)
+	}
+}
@@ -1,17 +1,21 @@
 package daemon
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/shurlinet/shurli/internal/notify"
 	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
@@ -79,6 +83,7 @@ func (c *Client) do(method, path string, body io.Reader, headers map[string]stri
 		return nil, 0, err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Accept-Encoding", "gzip")
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -92,7 +97,19 @@ func (c *Client) do(method, path string, body io.Reader, headers map[string]stri
 	}
 	defer resp.Body.Close()
 
-	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20)) // 10 MB max
+	// Setting Accept-Encoding ourselves turns off the transport's automatic
+	// decompression, so undo it here. The size cap applies after inflation.
+	var rd io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		defer gz.Close()
+		rd = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rd, 10<<20)) // 10 MB max
 	if err != nil {
 		return nil, resp.StatusCode, err
 	}
@@ -158,6 +175,45 @@ func (c *Client) Status() (*StatusResponse, error) {
 	return &resp, nil
 }
 
+// Health returns the daemon's health summary. Unlike most methods, a 503
+// (unhealthy) response is not an error: the decoded body is returned with
+// OK set to false.
+func (c *Client) Health() (*HealthResponse, error) {
+	data, status, err := c.do("GET", "/v1/health", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK && status != http.StatusServiceUnavailable {
+		var errResp ErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("daemon: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("daemon returned HTTP %d", status)
+	}
+	var raw struct {
+		Data HealthResponse `json:"data"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &raw.Data, nil
+}
+
+// PeerHistory returns the recorded connection history for a peer (name or
+// peer ID).
+func (c *Client) PeerHistory(target string) (*PeerHistoryResponse, error) {
+	var resp PeerHistoryResponse
+	if err := c.doJSON("GET", "/v1/peers/"+url.PathEscape(target)+"/history", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PeerHistoryText returns a peer's connection history as plain text.
+func (c *Client) PeerHistoryText(target string) (string, error) {
+	return c.doText("GET", "/v1/peers/"+url.PathEscape(target)+"/history", nil)
+}
+
 // StatusText returns the daemon's status as plain text.
 func (c *Client) StatusText() (string, error) {
 	return c.doText("GET", "/v1/status", nil)
@@ -195,26 +251,59 @@ func (c *Client) RemoteServicesText(peer string) (string, error) {
 	return c.doText("POST", "/v1/services/remote", strings.NewReader(string(body)))
 }
 
-// Peers returns the list of connected peers. If all is true, includes non-shurli DHT peers.
-func (c *Client) Peers(all bool) ([]PeerInfo, error) {
-	path := "/v1/peers"
-	if all {
-		path += "?all=true"
+// Remote runs one API call on another node's daemon over the admin
+// protocol and returns the "data" of its response.
+func (c *Client) Remote(req RemoteAdminRequest) (json.RawMessage, error) {
+	body, _ := json.Marshal(req)
+	var resp json.RawMessage
+	if err := c.doJSON("POST", "/v1/remote", strings.NewReader(string(body)), &resp); err != nil {
+		return nil, err
 	}
+	return resp, nil
+}
+
+// RemoteText runs one API call on another node's daemon, returns plain text.
+// Endpoints without a text rendering return their JSON envelope.
+func (c *Client) RemoteText(req RemoteAdminRequest) (string, error) {
+	body, _ := json.Marshal(req)
+	return c.doText("POST", "/v1/remote", strings.NewReader(string(body)))
+}
+
+// Peers returns the peers selected by opts. See PeerListOptions.
+func (c *Client) Peers(opts PeerListOptions) ([]PeerInfo, error) {
 	var resp []PeerInfo
-	if err := c.doJSON("GET", path, nil, &resp); err != nil {
+	if err := c.doJSON("GET", peersPath(opts), nil, &resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-// PeersText returns peers as plain text. If all is true, includes non-shurli DHT peers.
-func (c *Client) PeersText(all bool) (string, error) {
-	path := "/v1/peers"
-	if all {
-		path += "?all=true"
+// PeersText returns the peers selected by opts as plain text.
+func (c *Client) PeersText(opts PeerListOptions) (string, error) {
+	return c.doText("GET", peersPath(opts), nil)
+}
+
+func peersPath(opts PeerListOptions) string {
+	q := url.Values{}
+	if opts.All {
+		q.Set("all", "true")
+	}
+	if opts.Peer != "" {
+		q.Set("peer", opts.Peer)
+	}
+	if opts.Watched {
+		q.Set("watched", "true")
 	}
-	return c.doText("GET", path, nil)
+	if opts.Path != "" {
+		q.Set("path", opts.Path)
+	}
+	if opts.Sort != "" {
+		q.Set("sort", opts.Sort)
+	}
+	if len(q) == 0 {
+		return "/v1/peers"
+	}
+	return "/v1/peers?" + q.Encode()
 }
 
 // AuthList returns the authorized peers.
@@ -245,8 +334,45 @@ func (c *Client) PathsText() (string, error) {
 	return c.doText("GET", "/v1/paths", nil)
 }
 
+// Inbound returns the inbound service streams currently open on the daemon.
+func (c *Client) Inbound() (*InboundResponse, error) {
+	var resp InboundResponse
+	if err := c.doJSON("GET", "/v1/inbound", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// InboundText returns the open inbound service streams as plain text.
+func (c *Client) InboundText() (string, error) {
+	return c.doText("GET", "/v1/inbound", nil)
+}
+
+// HolePunch returns the per-peer DCUtR hole punch outcomes.
+func (c *Client) HolePunch() (*HolePunchResponse, error) {
+	var resp HolePunchResponse
+	if err := c.doJSON("GET", "/v1/holepunch", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// HolePunchText returns the per-peer DCUtR hole punch outcomes as plain text.
+func (c *Client) HolePunchText() (string, error) {
+	return c.doText("GET", "/v1/holepunch", nil)
+}
+
 // --- Mutation methods ---
 
+// ResetStats zeroes the daemon's session counters (bandwidth, per-peer RTT).
+func (c *Client) ResetStats() (*StatsResetResponse, error) {
+	var resp StatsResetResponse
+	if err := c.doJSON("POST", "/v1/stats/reset", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // AuthAdd adds an authorized peer.
 func (c *Client) AuthAdd(peerID, comment string) error {
 	req := AuthAddRequest{PeerID: peerID, Comment: comment}
@@ -277,6 +403,24 @@ func (c *Client) PingText(peer string, count, intervalMs int) (string, error) {
 	return c.doText("POST", "/v1/ping", strings.NewReader(string(body)))
 }
 
+// BandwidthTest measures throughput to a peer via the daemon.
+func (c *Client) BandwidthTest(peer, direction string, size int64) (*sdk.BandwidthResult, error) {
+	req := BandwidthTestRequest{Peer: peer, Bytes: size, Direction: direction}
+	body, _ := json.Marshal(req)
+	var resp sdk.BandwidthResult
+	if err := c.doJSON("POST", "/v1/bwtest", strings.NewReader(string(body)), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BandwidthTestText measures throughput to a peer, returns plain text output.
+func (c *Client) BandwidthTestText(peer, direction string, size int64) (string, error) {
+	req := BandwidthTestRequest{Peer: peer, Bytes: size, Direction: direction}
+	body, _ := json.Marshal(req)
+	return c.doText("POST", "/v1/bwtest", strings.NewReader(string(body)))
+}
+
 // Traceroute traces the path to a peer and returns the result as JSON.
 func (c *Client) Traceroute(peer string) (*sdk.TraceResult, error) {
 	req := TraceRequest{Peer: peer}
@@ -315,7 +459,13 @@ func (c *Client) ResolveText(name string) (string, error) {
 
 // Connect creates a TCP proxy to a remote service via the daemon.
 func (c *Client) Connect(peer, service, listen string) (*ConnectResponse, error) {
-	req := ConnectRequest{Peer: peer, Service: service, Listen: listen}
+	return c.ConnectProto(peer, service, listen, "")
+}
+
+// ConnectProto creates an ephemeral proxy of the given kind: "tcp" (the
+// default when empty) or "udp". Use ConnectStdio for stdio proxies.
+func (c *Client) ConnectProto(peer, service, listen, proto string) (*ConnectResponse, error) {
+	req := ConnectRequest{Peer: peer, Service: service, Listen: listen, Proto: proto}
 	body, _ := json.Marshal(req)
 	var resp ConnectResponse
 	if err := c.doJSON("POST", "/v1/connect", strings.NewReader(string(body)), &resp); err != nil {
@@ -324,6 +474,80 @@ func (c *Client) Connect(peer, service, listen string) (*ConnectResponse, error)
 	return &resp, nil
 }
 
+// ConnectStdio opens a stdio proxy to a remote service. The returned
+// connection carries the service stream directly; CloseWrite signals the
+// end of input while still reading the service's reply. The proxy ID is
+// returned for use with Disconnect. Cancelling ctx only bounds the setup.
+func (c *Client) ConnectStdio(ctx context.Context, peer, service string) (sdk.HalfCloseConn, string, error) {
+	body, _ := json.Marshal(ConnectRequest{Peer: peer, Service: service, Proto: "stdio"})
+	req, err := http.NewRequest("POST", "http://daemon/v1/connect", bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", StreamUpgradeProtocol)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.socketPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	// Setup includes reaching the peer, so bound it like any request.
+	deadline := time.Now().Add(c.httpClient.Timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, "", fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		return nil, "", fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer conn.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		var errResp ErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			return nil, "", fmt.Errorf("daemon: %s", errResp.Error)
+		}
+		return nil, "", fmt.Errorf("daemon returned HTTP %d", resp.StatusCode)
+	}
+	if !stop() {
+		return nil, "", ctx.Err() // ctx fired and closed conn
+	}
+	conn.SetDeadline(time.Time{})
+	return &streamConn{Reader: br, Conn: conn}, resp.Header.Get(ProxyIDHeader), nil
+}
+
+// streamConn is the client end of a stdio proxy. Reads drain the bytes
+// buffered while parsing the 101 response before reading the socket.
+type streamConn struct {
+	*bufio.Reader
+	net.Conn
+}
+
+func (c *streamConn) Read(p []byte) (int, error) { return c.Reader.Read(p) }
+
+func (c *streamConn) CloseWrite() error {
+	if uc, ok := c.Conn.(*net.UnixConn); ok {
+		return uc.CloseWrite()
+	}
+	return nil
+}
+
 // Disconnect tears down an ephemeral proxy.
 func (c *Client) Disconnect(id string) error {
 	return c.doJSON("DELETE", "/v1/connect/"+id, nil, nil)
@@ -364,9 +588,10 @@ func (c *Client) ProxyDisable(name string) error {
 	return c.doJSON("POST", "/v1/proxies/"+name+"/disable", nil, nil)
 }
 
-// Expose registers a service on the P2P host.
-func (c *Client) Expose(name, localAddress string) error {
-	req := ExposeRequest{Name: name, LocalAddress: localAddress}
+// Expose registers a service on the P2P host. With requireVerified, only
+// peers verified with 'shurli verify' may use it.
+func (c *Client) Expose(name, localAddress string, requireVerified bool) error {
+	req := ExposeRequest{Name: name, LocalAddress: localAddress, RequireVerified: requireVerified}
 	body, _ := json.Marshal(req)
 	return c.doJSON("POST", "/v1/expose", strings.NewReader(string(body)), nil)
 }
@@ -381,6 +606,13 @@ func (c *Client) Shutdown() error {
 	return c.doJSON("POST", "/v1/shutdown", nil, nil)
 }
 
+// ShutdownDrain requests a draining shutdown: the daemon refuses new proxy
+// connections, waits up to timeout for open ones to finish, then stops.
+func (c *Client) ShutdownDrain(timeout time.Duration) error {
+	body, _ := json.Marshal(ShutdownRequest{Drain: true, Timeout: timeout.String()})
+	return c.doJSON("POST", "/v1/shutdown", strings.NewReader(string(body)), nil)
+}
+
 // Lock disables sensitive operations on the running daemon.
 func (c *Client) Lock() error {
 	return c.doJSON("POST", "/v1/lock", nil, nil)
@@ -441,6 +673,105 @@ func (c *Client) InviteWait(ctx context.Context, id string) (*InviteWaitResponse
 	return &raw.Data, nil
 }
 
+// Events streams notification events until ctx is cancelled or the daemon
+// closes the stream. A non-zero since replays buffered events from that
+// time before live ones. A non-empty peerFilter (peer ID or name) limits
+// the stream to events about that peer. fn is called for each event in
+// order; a non-nil return stops the stream and is returned.
+func (c *Client) Events(ctx context.Context, since time.Time, peerFilter string, fn func(notify.Event) error) error {
+	resp, err := c.openEvents(ctx, since, peerFilter)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var e notify.Event
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+// SubscribeEvents opens the event stream and delivers events on the
+// returned channel, with the same since and peerFilter semantics as
+// Events. The channel is closed when ctx is cancelled, the daemon closes
+// the stream, or an event fails to decode. Errors opening the stream
+// (including authentication failures) are returned directly.
+func (c *Client) SubscribeEvents(ctx context.Context, since time.Time, peerFilter string) (<-chan notify.Event, error) {
+	resp, err := c.openEvents(ctx, since, peerFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan notify.Event)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var e notify.Event
+			if err := dec.Decode(&e); err != nil {
+				return
+			}
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// openEvents issues GET /v1/events and returns the response once the
+// daemon has accepted the stream.
+func (c *Client) openEvents(ctx context.Context, since time.Time, peerFilter string) (*http.Response, error) {
+	q := url.Values{}
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339Nano))
+	}
+	if peerFilter != "" {
+		q.Set("peer", peerFilter)
+	}
+	path := "http://daemon/v1/events"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.authToken)
+
+	// The stream is open-ended, so the per-request timeout can't apply.
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		var errResp ErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("daemon: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("daemon returned HTTP %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
 // InviteCancel cancels an active invite session.
 func (c *Client) InviteCancel(id string) error {
 	return c.doJSON("DELETE", "/v1/invite/"+id, nil, nil)
@@ -591,6 +922,35 @@ func (c *Client) Reconnect(peer string) (*ReconnectResponse, error) {
 	return &result, nil
 }
 
+// SendMessage delivers a short text message to a peer via the daemon.
+func (c *Client) SendMessage(peer, text string) (*MessageSendResponse, error) {
+	body, _ := json.Marshal(MessageSendRequest{Peer: peer, Text: text})
+	var result MessageSendResponse
+	if err := c.doJSON("POST", "/v1/messages", bytes.NewReader(body), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Messages returns the daemon's inbox of received messages, oldest first.
+func (c *Client) Messages() ([]MessageInfo, error) {
+	var result []MessageInfo
+	if err := c.doJSON("GET", "/v1/messages", nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ClearMessages empties the daemon's inbox and returns how many messages
+// were removed.
+func (c *Client) ClearMessages() (int, error) {
+	var result map[string]int
+	if err := c.doJSON("DELETE", "/v1/messages", nil, &result); err != nil {
+		return 0, err
+	}
+	return result["cleared"], nil
+}
+
 // NotifySinks returns all configured notification sinks.
 func (c *Client) NotifySinks() ([]NotifySinkInfo, error) {
 	var result []NotifySinkInfo
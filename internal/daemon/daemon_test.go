@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -16,8 +17,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 
+	"github.com/shurlinet/shurli/internal/auth"
 	"github.com/shurlinet/shurli/internal/grants"
 	"github.com/shurlinet/shurli/internal/notify"
+	"github.com/shurlinet/shurli/internal/reputation"
+	"github.com/shurlinet/shurli/internal/watchdog"
 	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
@@ -42,6 +46,7 @@ func (m *mockRuntime) PathTracker() *sdk.PathTracker           { return nil }
 func (m *mockRuntime) PathProtector() *sdk.PathProtector       { return nil }
 func (m *mockRuntime) BandwidthTracker() *sdk.BandwidthTracker { return nil }
 func (m *mockRuntime) RelayHealth() *sdk.RelayHealth           { return nil }
+func (m *mockRuntime) RelaySelector() *sdk.RelaySelector       { return nil }
 func (m *mockRuntime) STUNResult() *sdk.STUNResult             { return nil }
 func (m *mockRuntime) IsRelaying() bool                            { return false }
 func (m *mockRuntime) RelayAddresses() []string                    { return nil }
@@ -57,6 +62,11 @@ func (m *mockRuntime) GrantsMaxRefreshDuration() string             { return ""
 func (m *mockRuntime) NotifyRouter() *notify.Router                 { return nil }
 func (m *mockRuntime) PeerManager() *sdk.PeerManager             { return nil }
 func (m *mockRuntime) GrantCacheSnapshot() []*grants.GrantReceipt   { return nil }
+func (m *mockRuntime) Messenger() *sdk.Messenger                        { return nil }
+func (m *mockRuntime) Advertiser() *sdk.RendezvousAdvertiser            { return nil }
+func (m *mockRuntime) MDNSDiscovery() *sdk.MDNSDiscovery                { return nil }
+func (m *mockRuntime) HealthChecks() []watchdog.CheckStatus             { return nil }
+func (m *mockRuntime) PeerHistory() *reputation.PeerHistory             { return nil }
 
 func newMockRuntime() *mockRuntime {
 	return &mockRuntime{
@@ -365,6 +375,32 @@ func TestClientNewClient_CookieNotFound(t *testing.T) {
 	}
 }
 
+func TestClientHealth_NoNetwork(t *testing.T) {
+	srv, dir := newTestServer(t)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer srv.Stop()
+
+	client, err := NewClient(filepath.Join(dir, "test.sock"), filepath.Join(dir, ".test-cookie"))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	// The mock runtime has no network, so the daemon reports 503. Health
+	// returns the body rather than an error.
+	resp, err := client.Health()
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if resp.OK {
+		t.Error("expected ok = false without a network")
+	}
+	if resp.UptimeSeconds < 60 {
+		t.Errorf("uptime_seconds = %d, want >= 60", resp.UptimeSeconds)
+	}
+}
+
 func TestClientIntegration(t *testing.T) {
 	// This test creates a real server + client and tests end-to-end
 	// communication. The mock runtime doesn't have a real P2P network,
@@ -514,7 +550,7 @@ func TestNetworkClientIntegration(t *testing.T) {
 
 	// --- Expose / Unexpose ---
 	t.Run("Expose", func(t *testing.T) {
-		if err := client.Expose("ssh", "localhost:22"); err != nil {
+		if err := client.Expose("ssh", "localhost:22", false); err != nil {
 			t.Fatalf("Expose: %v", err)
 		}
 
@@ -551,7 +587,7 @@ func TestNetworkClientIntegration(t *testing.T) {
 
 	// --- Peers ---
 	t.Run("Peers", func(t *testing.T) {
-		peers, err := client.Peers(false)
+		peers, err := client.Peers(PeerListOptions{})
 		if err != nil {
 			t.Fatalf("Peers: %v", err)
 		}
@@ -562,7 +598,7 @@ func TestNetworkClientIntegration(t *testing.T) {
 	})
 
 	t.Run("PeersAll", func(t *testing.T) {
-		peers, err := client.Peers(true)
+		peers, err := client.Peers(PeerListOptions{All: true})
 		if err != nil {
 			t.Fatalf("Peers(all): %v", err)
 		}
@@ -570,7 +606,7 @@ func TestNetworkClientIntegration(t *testing.T) {
 	})
 
 	t.Run("PeersText", func(t *testing.T) {
-		text, err := client.PeersText(false)
+		text, err := client.PeersText(PeerListOptions{})
 		if err != nil {
 			t.Fatalf("PeersText: %v", err)
 		}
@@ -903,6 +939,181 @@ func TestP2PHandlerIntegration(t *testing.T) {
 		}
 	})
 
+	// --- Connect limit (control.max_proxies) ---
+	t.Run("Connect_MaxProxies", func(t *testing.T) {
+		netB.ExposeService("echo", "localhost:9999", nil)
+		defer netB.UnexposeService("echo")
+		srv.SetMaxProxies(2)
+		defer srv.SetMaxProxies(0)
+
+		var ids []string
+		for range 2 {
+			resp, err := client.Connect("remote", "echo", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Connect within limit: %v", err)
+			}
+			ids = append(ids, resp.ID)
+		}
+		defer func() {
+			for _, id := range ids {
+				client.Disconnect(id)
+			}
+		}()
+
+		_, err := client.Connect("remote", "echo", "127.0.0.1:0")
+		if err == nil || !strings.Contains(err.Error(), ErrProxyLimit.Error()) {
+			t.Fatalf("Connect over limit: err = %v, want %q", err, ErrProxyLimit)
+		}
+		status, err := client.Status()
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		if status.ActiveProxies != 2 || status.MaxProxies != 2 {
+			t.Errorf("status proxies = %d/%d, want 2/2", status.ActiveProxies, status.MaxProxies)
+		}
+
+		// Disconnecting frees a slot.
+		if err := client.Disconnect(ids[0]); err != nil {
+			t.Fatalf("Disconnect: %v", err)
+		}
+		resp, err := client.Connect("remote", "echo", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Connect after disconnect: %v", err)
+		}
+		ids = append(ids[1:], resp.ID)
+	})
+
+	// --- Connect --stdio (hijacked API connection carries the stream) ---
+	t.Run("Connect_Stdio", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		go func() {
+			for {
+				c, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go func() { defer c.Close(); io.Copy(c, c) }()
+			}
+		}()
+		netB.ExposeService("echo", ln.Addr().String(), nil)
+		defer netB.UnexposeService("echo")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		conn, id, err := client.ConnectStdio(ctx, "remote", "echo")
+		if err != nil {
+			t.Fatalf("ConnectStdio: %v", err)
+		}
+		defer conn.Close()
+		if id == "" {
+			t.Error("proxy ID empty")
+		}
+
+		// Half-closing the input still lets the echo come back.
+		if _, err := conn.Write([]byte("hello")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := conn.CloseWrite(); err != nil {
+			t.Fatalf("CloseWrite: %v", err)
+		}
+		got, err := io.ReadAll(conn)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if string(got) != "hello" {
+			t.Errorf("echo = %q, want %q", got, "hello")
+		}
+
+		// The proxy is gone once the stream has closed.
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			err := client.Disconnect(id)
+			if err != nil && strings.Contains(err.Error(), ErrProxyNotFound.Error()) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("proxy %s still registered after stream closed (err = %v)", id, err)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+
+	// --- Connect --proto udp ---
+	t.Run("Connect_UDP", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pc.Close()
+		go func() {
+			buf := make([]byte, 1500)
+			for {
+				n, addr, err := pc.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				pc.WriteTo(buf[:n], addr)
+			}
+		}()
+		netB.ExposeService("dns", pc.LocalAddr().String(), nil)
+		defer netB.UnexposeService("dns")
+		if err := netB.ServiceRegistry().SetLocalNetwork("dns", "udp"); err != nil {
+			t.Fatalf("SetLocalNetwork: %v", err)
+		}
+
+		// A UDP service refuses a TCP proxy, with a hint. The check
+		// needs the service-query protocol on both ends.
+		for _, n := range []*sdk.Network{netA, netB} {
+			if err := n.RegisterServiceQuery(); err != nil {
+				t.Fatalf("RegisterServiceQuery: %v", err)
+			}
+			defer n.ServiceRegistry().UnregisterService("service-query")
+		}
+		_, err = client.Connect("remote", "dns", "127.0.0.1:0")
+		if err == nil || !strings.Contains(err.Error(), "--proto udp") {
+			t.Fatalf("tcp connect to udp service: err = %v, want --proto udp hint", err)
+		}
+
+		resp, err := client.ConnectProto("remote", "dns", "127.0.0.1:0", "udp")
+		if err != nil {
+			t.Fatalf("ConnectProto udp: %v", err)
+		}
+		defer client.Disconnect(resp.ID)
+		if resp.Proto != "udp" {
+			t.Errorf("Proto = %q, want udp", resp.Proto)
+		}
+
+		conn, err := net.Dial("udp", resp.ListenAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("query")); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("read reply: %v", err)
+		}
+		if string(buf[:n]) != "query" {
+			t.Errorf("reply = %q, want %q", buf[:n], "query")
+		}
+	})
+
+	// --- Connect with an unknown proto ---
+	t.Run("Connect_BadProto", func(t *testing.T) {
+		_, err := client.ConnectProto("remote", "echo", "127.0.0.1:0", "sctp")
+		if err == nil || !strings.Contains(err.Error(), "unknown proto") {
+			t.Fatalf("err = %v, want unknown proto", err)
+		}
+	})
+
 	// --- Connect with unresolvable peer ---
 	t.Run("Connect_UnresolvablePeer", func(t *testing.T) {
 		_, err := client.Connect("nonexistent", "ssh", ":0")
@@ -912,6 +1123,81 @@ func TestP2PHandlerIntegration(t *testing.T) {
 	})
 }
 
+// dhtLessRuntime fails any name-based connect, so a handler that reaches
+// ConnectToPeer (the DHT lookup + relay path) is detected.
+type dhtLessRuntime struct {
+	*networkMockRuntime
+	connectCalls int
+}
+
+func (m *dhtLessRuntime) ConnectToPeer(_ context.Context, _ peer.ID) error {
+	m.connectCalls++
+	return context.DeadlineExceeded
+}
+
+// TestPingTracerouteMultiaddrTarget verifies a full multiaddr target is
+// dialed directly, without a DHT lookup, for both ping and traceroute.
+func TestPingTracerouteMultiaddrTarget(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	netA := newListeningTestNetwork(t)
+	netB := newListeningTestNetwork(t)
+	pingProto := "/shurli/ping/1.0.0"
+	netB.Host().SetStreamHandler(protocol.ID(pingProto), func(s network.Stream) {
+		defer s.Close()
+		buf := make([]byte, 64)
+		n, err := s.Read(buf)
+		if err == nil && strings.TrimSpace(string(buf[:n])) == "ping" {
+			s.Write([]byte("pong\n"))
+		}
+	})
+
+	rt := &dhtLessRuntime{networkMockRuntime: &networkMockRuntime{
+		net:       netA,
+		version:   "test",
+		startTime: time.Now(),
+		pingProto: pingProto,
+	}}
+	srv := NewServer(rt, socketPath, cookiePath, "test")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	target := netB.Host().Addrs()[0].String() + "/p2p/" + netB.Host().ID().String()
+
+	resp, err := client.Ping(target, 1, 100)
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if resp.Stats.Received != 1 {
+		t.Errorf("received %d pongs, want 1", resp.Stats.Received)
+	}
+
+	text, err := client.TracerouteText(target)
+	if err != nil {
+		t.Fatalf("TracerouteText: %v", err)
+	}
+	if !strings.Contains(text, "path: [DIRECT]") {
+		t.Errorf("traceroute text missing direct path: %s", text)
+	}
+
+	if rt.connectCalls != 0 {
+		t.Errorf("ConnectToPeer (DHT lookup) called %d times for multiaddr targets", rt.connectCalls)
+	}
+
+	// Multiaddr without a peer ID is rejected up front.
+	if _, err := client.Ping(netB.Host().Addrs()[0].String(), 1, 100); err == nil {
+		t.Error("expected error for a multiaddr without /p2p/<peer-id>")
+	}
+}
+
 func TestComputePingStats_Empty(t *testing.T) {
 	stats := sdk.ComputePingStats(nil)
 	if stats.Sent != 0 || stats.Received != 0 || stats.Lost != 0 {
@@ -990,3 +1276,289 @@ func TestComputePingStats_AllErrors(t *testing.T) {
 		t.Errorf("expected all RTT stats to be 0, got min=%f avg=%f max=%f", stats.MinMs, stats.AvgMs, stats.MaxMs)
 	}
 }
+
+func TestSocketAndCookiePaths_PerProfile(t *testing.T) {
+	dir := "/etc/shurli"
+	if got := SocketPath(dir, ""); got != filepath.Join(dir, "shurli.sock") {
+		t.Errorf("default socket = %q", got)
+	}
+	if got := CookiePath(dir, ""); got != filepath.Join(dir, ".daemon-cookie") {
+		t.Errorf("default cookie = %q", got)
+	}
+
+	// Every profile gets its own socket and cookie, distinct from the default.
+	seen := map[string]string{}
+	for _, p := range []string{"", "work", "home"} {
+		for _, path := range []string{SocketPath(dir, p), CookiePath(dir, p)} {
+			if other, dup := seen[path]; dup {
+				t.Errorf("profile %q path %s collides with profile %q", p, path, other)
+			}
+			seen[path] = p
+		}
+	}
+}
+
+func TestMessagesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	netA := newListeningTestNetwork(t)
+	netB := newListeningTestNetwork(t)
+	msgA := sdk.NewMessenger(netA.Host(), 0, nil)
+	msgA.Register()
+	msgB := sdk.NewMessenger(netB.Host(), 0, nil)
+	msgB.Register()
+
+	rt := &dhtLessRuntime{networkMockRuntime: &networkMockRuntime{
+		net:       netA,
+		version:   "test",
+		startTime: time.Now(),
+		messenger: msgA,
+	}}
+	srv := NewServer(rt, socketPath, cookiePath, "test")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// Send through the daemon API.
+	target := netB.Host().Addrs()[0].String() + "/p2p/" + netB.Host().ID().String()
+	resp, err := client.SendMessage(target, "I'm online, try connecting now")
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if resp.Status != "delivered" || resp.PeerID != netB.Host().ID().String() {
+		t.Errorf("SendMessage response = %+v", resp)
+	}
+	if got := msgB.Messages(); len(got) != 1 || got[0].Text != "I'm online, try connecting now" {
+		t.Fatalf("receiver inbox = %+v", got)
+	}
+
+	// Reply, and read it back from the daemon's inbox.
+	if err := msgB.Send(context.Background(), netA.Host().ID(), "on my way"); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	msgs, err := client.Messages()
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Text != "on my way" || msgs[0].From != netB.Host().ID().String() {
+		t.Fatalf("daemon inbox = %+v", msgs)
+	}
+
+	if _, err := client.SendMessage(target, ""); err == nil {
+		t.Error("expected error for empty message")
+	}
+
+	n, err := client.ClearMessages()
+	if err != nil || n != 1 {
+		t.Errorf("ClearMessages = %d, %v; want 1", n, err)
+	}
+	if msgs, _ := client.Messages(); len(msgs) != 0 {
+		t.Errorf("inbox not empty after clear: %+v", msgs)
+	}
+}
+
+// statsRuntime exposes real bandwidth and path trackers.
+type statsRuntime struct {
+	*networkMockRuntime
+	bt *sdk.BandwidthTracker
+	pt *sdk.PathTracker
+}
+
+func (m *statsRuntime) BandwidthTracker() *sdk.BandwidthTracker { return m.bt }
+func (m *statsRuntime) PathTracker() *sdk.PathTracker           { return m.pt }
+
+func TestStatsReset(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	netA := newListeningTestNetwork(t)
+	netB := newListeningTestNetwork(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rt := &statsRuntime{
+		networkMockRuntime: &networkMockRuntime{net: netA, version: "test", startTime: time.Now()},
+		bt:                 sdk.NewBandwidthTracker(nil),
+		pt:                 sdk.NewPathTracker(netA.Host(), nil),
+	}
+	go rt.pt.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	if err := netA.Host().Connect(ctx, peer.AddrInfo{ID: netB.Host().ID(), Addrs: netB.Host().Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, ok := rt.pt.GetPeerPath(netB.Host().ID()); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("path tracker never saw the connection")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	rt.pt.UpdateRTT(netB.Host().ID(), 12.5)
+
+	// Bandwidth totals are folded in by the meter's sweeper; wait for them.
+	rt.bt.Counter().LogSentMessage(4096)
+	for rt.bt.Totals().TotalOut == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("bandwidth total never updated")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	srv := NewServer(rt, socketPath, cookiePath, "test")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	resp, err := client.ResetStats()
+	if err != nil {
+		t.Fatalf("ResetStats: %v", err)
+	}
+	if strings.Join(resp.Reset, ",") != "bandwidth,path_rtt" {
+		t.Errorf("Reset = %v, want [bandwidth path_rtt]", resp.Reset)
+	}
+	if got := rt.bt.Totals().TotalOut; got != 0 {
+		t.Errorf("TotalOut after reset = %d, want 0", got)
+	}
+	info, ok := rt.pt.GetPeerPath(netB.Host().ID())
+	if !ok {
+		t.Fatal("reset dropped the live path")
+	}
+	if info.LastRTTMs != 0 {
+		t.Errorf("LastRTTMs after reset = %v, want 0", info.LastRTTMs)
+	}
+}
+
+func TestInboundStreams(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	// Local TCP echo service exposed by the serving network.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() { defer c.Close(); io.Copy(c, c) }()
+		}
+	}()
+
+	netA := newListeningTestNetwork(t)
+	netB := newListeningTestNetwork(t)
+	if err := netA.ExposeService("echo", ln.Addr().String(), nil); err != nil {
+		t.Fatalf("ExposeService: %v", err)
+	}
+
+	authPath := filepath.Join(dir, "authorized_keys")
+	if err := os.WriteFile(authPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	clientID := netB.Host().ID().String()
+	if err := auth.AddPeer(authPath, clientID, "laptop"); err != nil {
+		t.Fatalf("AddPeer: %v", err)
+	}
+	if err := auth.SetPeerAttr(authPath, clientID, "verified", "sha256:a1b2c3d4"); err != nil {
+		t.Fatalf("SetPeerAttr: %v", err)
+	}
+
+	rt := &networkMockRuntime{net: netA, version: "test", startTime: time.Now(), authKeysPath: authPath}
+	srv := NewServer(rt, socketPath, cookiePath, "test")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	resp, err := client.Inbound()
+	if err != nil {
+		t.Fatalf("Inbound: %v", err)
+	}
+	if len(resp.Streams) != 0 {
+		t.Fatalf("got %d streams before any connection, want 0", len(resp.Streams))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := netB.Host().Connect(ctx, peer.AddrInfo{ID: netA.Host().ID(), Addrs: netA.Host().Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	s, err := netB.Host().NewStream(ctx, netA.Host().ID(), "/shurli/echo/1.0.0")
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	if _, err := s.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := io.ReadFull(s, make([]byte, 4)); err != nil {
+		t.Fatalf("read echo: %v", err)
+	}
+
+	resp, err = client.Inbound()
+	if err != nil {
+		t.Fatalf("Inbound: %v", err)
+	}
+	if len(resp.Streams) != 1 {
+		t.Fatalf("got %d streams, want 1", len(resp.Streams))
+	}
+	in := resp.Streams[0]
+	if in.Service != "echo" || in.PeerID != clientID || in.Path != "direct" {
+		t.Errorf("stream = %+v", in)
+	}
+	if in.BytesIn != 4 || in.BytesOut != 4 {
+		t.Errorf("bytes in/out = %d/%d, want 4/4", in.BytesIn, in.BytesOut)
+	}
+	if !in.Authorized || !in.Verified {
+		t.Errorf("authorized=%v verified=%v, want both true", in.Authorized, in.Verified)
+	}
+
+	text, err := client.doText("GET", "/v1/inbound", nil)
+	if err != nil {
+		t.Fatalf("inbound text: %v", err)
+	}
+	if !strings.Contains(text, "echo\t"+clientID) || !strings.Contains(text, "verified") {
+		t.Errorf("text output = %q", text)
+	}
+
+	// Closing the stream removes it from the list.
+	s.Close()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		resp, err = client.Inbound()
+		if err != nil {
+			t.Fatalf("Inbound: %v", err)
+		}
+		if len(resp.Streams) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stream still listed after close: %+v", resp.Streams)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
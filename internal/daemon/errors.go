@@ -15,6 +15,10 @@ var (
 	// that does not exist or has already been torn down.
 	ErrProxyNotFound = errors.New("proxy not found")
 
+	// ErrProxyLimit is returned when creating a proxy would exceed
+	// control.max_proxies.
+	ErrProxyLimit = errors.New("proxy limit reached")
+
 	// ErrUnauthorized is returned when a request lacks valid authentication.
 	ErrUnauthorized = errors.New("unauthorized")
 )
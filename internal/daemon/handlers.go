@@ -1,14 +1,17 @@
 package daemon
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"math"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,7 +21,9 @@ import (
 
 	"github.com/shurlinet/shurli/internal/auth"
 	"github.com/shurlinet/shurli/internal/grants"
+	"github.com/shurlinet/shurli/internal/notify"
 	"github.com/shurlinet/shurli/internal/relay"
+	"github.com/shurlinet/shurli/internal/reputation"
 	"github.com/shurlinet/shurli/internal/validate"
 	"github.com/shurlinet/shurli/pkg/sdk"
 )
@@ -31,13 +36,19 @@ const MaxRequestBodySize = 1 << 20 // 1 MB
 func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// Read-only
 	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/health", s.handleHealth)
 	mux.HandleFunc("GET /v1/services", s.handleServiceList)
 	mux.HandleFunc("POST /v1/services/remote", s.handleRemoteServiceList)
+	mux.HandleFunc("POST /v1/remote", s.handleRemoteAdmin)
 	mux.HandleFunc("GET /v1/peers", s.handlePeerList)
+	mux.HandleFunc("GET /v1/peers/{id}/history", s.handlePeerHistory)
 	mux.HandleFunc("GET /v1/auth", s.handleAuthList)
 
 	mux.HandleFunc("GET /v1/paths", s.handlePaths)
+	mux.HandleFunc("GET /v1/inbound", s.handleInbound)
+	mux.HandleFunc("GET /v1/holepunch", s.handleHolePunch)
 	mux.HandleFunc("GET /v1/bandwidth", s.handleBandwidth)
+	mux.HandleFunc("POST /v1/stats/reset", s.handleStatsReset)
 	mux.HandleFunc("GET /v1/relay-health", s.handleRelayHealth)
 
 	// Mutations
@@ -45,6 +56,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /v1/auth/{peer_id}", s.handleAuthRemove)
 	mux.HandleFunc("POST /v1/ping", s.handlePing)
 	mux.HandleFunc("POST /v1/traceroute", s.handleTraceroute)
+	mux.HandleFunc("POST /v1/bwtest", s.handleBandwidthTest)
 	mux.HandleFunc("POST /v1/resolve", s.handleResolve)
 	mux.HandleFunc("POST /v1/connect", s.handleConnect)
 	mux.HandleFunc("DELETE /v1/connect/{id}", s.handleDisconnect)
@@ -85,6 +97,12 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// Notifications
 	mux.HandleFunc("GET /v1/notify/sinks", s.handleNotifySinks)
 	mux.HandleFunc("POST /v1/notify/test", s.handleNotifyTest)
+	mux.HandleFunc("GET /v1/events", s.handleEvents)
+
+	// Messages (short peer-to-peer coordination notes)
+	mux.HandleFunc("GET /v1/messages", s.handleMessageList)
+	mux.HandleFunc("POST /v1/messages", s.handleMessageSend)
+	mux.HandleFunc("DELETE /v1/messages", s.handleMessageClear)
 
 	// Plugins
 	mux.HandleFunc("GET /v1/plugins", s.handlePluginList)
@@ -97,11 +115,11 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	if s.registry != nil {
 		// Build set of core route keys for conflict detection.
 		coreRouteKeys := map[string]bool{
-			"GET /v1/status": true, "GET /v1/services": true, "POST /v1/services/remote": true,
-			"GET /v1/peers": true, "GET /v1/auth": true, "GET /v1/paths": true,
-			"GET /v1/bandwidth": true, "GET /v1/relay-health": true,
+			"GET /v1/status": true, "GET /v1/health": true, "GET /v1/services": true, "POST /v1/services/remote": true, "POST /v1/remote": true,
+			"GET /v1/peers": true, "GET /v1/peers/{id}/history": true, "GET /v1/auth": true, "GET /v1/paths": true, "GET /v1/holepunch": true,
+			"GET /v1/bandwidth": true, "POST /v1/stats/reset": true, "GET /v1/relay-health": true,
 			"POST /v1/auth": true, "DELETE /v1/auth/{peer_id}": true,
-			"POST /v1/ping": true, "POST /v1/traceroute": true, "POST /v1/resolve": true,
+			"POST /v1/ping": true, "POST /v1/traceroute": true, "POST /v1/bwtest": true, "POST /v1/resolve": true,
 			"POST /v1/connect": true, "DELETE /v1/connect/{id}": true,
 			"POST /v1/expose": true, "DELETE /v1/expose/{name}": true,
 			"POST /v1/shutdown": true, "POST /v1/lock": true, "POST /v1/unlock": true, "GET /v1/lock": true,
@@ -112,6 +130,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 			"GET /v1/proxies": true, "POST /v1/proxies": true,
 			"DELETE /v1/proxies/{name}": true, "POST /v1/proxies/{name}/enable": true, "POST /v1/proxies/{name}/disable": true,
 			"GET /v1/notify/sinks": true, "POST /v1/notify/test": true,
+			"GET /v1/messages": true, "POST /v1/messages": true, "DELETE /v1/messages": true,
 			"GET /v1/plugins": true, "POST /v1/plugins/disable-all": true,
 			"GET /v1/plugins/{name}": true, "POST /v1/plugins/{name}/enable": true, "POST /v1/plugins/{name}/disable": true,
 		}
@@ -179,6 +198,61 @@ func isShurliAgent(agent string) bool {
 
 // --- Handlers ---
 
+// handleHealth is a cheap liveness check for monitoring. A node with relays
+// configured is healthy only while it holds a relay reservation (the same
+// check the watchdog runs); a node with no relays configured is healthy
+// whenever its network is up.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	rt := s.runtime
+	resp := HealthResponse{
+		UptimeSeconds: int(time.Since(rt.StartTime()).Seconds()),
+		Checks:        rt.HealthChecks(),
+	}
+
+	if p2p := rt.Network(); p2p != nil {
+		h := p2p.Host()
+		resp.PeerCount = len(h.Network().Peers())
+		resp.RelayConnected = sdk.HasRelayReservation(h)
+		resp.OK = resp.RelayConnected || len(rt.RelayAddresses()) == 0
+	}
+	if s.Draining() {
+		resp.Draining = true
+		resp.OK = false
+	}
+
+	status := http.StatusOK
+	if !resp.OK {
+		status = http.StatusServiceUnavailable
+	}
+
+	if WantsText(r) {
+		state := "ok"
+		switch {
+		case resp.Draining:
+			state = "draining"
+		case !resp.OK:
+			state = "unhealthy"
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s: uptime %ds, %d peers, relay connected: %v\n",
+			state, resp.UptimeSeconds, resp.PeerCount, resp.RelayConnected)
+		for _, c := range resp.Checks {
+			if c.Healthy {
+				fmt.Fprintf(&b, "  %-20s ok\n", c.Name)
+				continue
+			}
+			fmt.Fprintf(&b, "  %-20s FAILING x%d: %s", c.Name, c.ConsecutiveFailures, c.LastError)
+			if c.RecoveryAttempts > 0 {
+				fmt.Fprintf(&b, " (%d recovery attempts)", c.RecoveryAttempts)
+			}
+			b.WriteString("\n")
+		}
+		RespondText(w, status, b.String())
+		return
+	}
+	RespondJSON(w, status, resp)
+}
+
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	rt := s.runtime
 	h := rt.Network().Host()
@@ -187,7 +261,7 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	var listenAddrs, relayAddrs []string
 	for _, addr := range h.Addrs() {
 		addrStr := addr.String()
-		if strings.Contains(addrStr, "/p2p-circuit") {
+		if sdk.ClassifyAddr(addr) == sdk.AddrCircuit {
 			relayAddrs = append(relayAddrs, addrStr)
 		} else {
 			listenAddrs = append(listenAddrs, addrStr)
@@ -202,6 +276,8 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		ListenAddrs:    listenAddrs,
 		RelayAddrs:     relayAddrs,
 		ServicesCount:  len(rt.Network().ListServices()),
+		ObservedAddrs:  rt.Network().ObservedAddrs(),
+		IPMode:         rt.Network().IPMode(),
 	}
 
 	// Populate interface discovery flags if available
@@ -223,6 +299,21 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	grade := sdk.ComputeReachabilityGrade(rt.Interfaces(), rt.STUNResult())
 	resp.Reachability = &grade
 
+	// NAT traversal assessment: STUN prediction refined by real hole punches.
+	traversal := sdk.AssessTraversal(rt.STUNResult(), rt.Network().HolePunchStats())
+	resp.Traversal = &traversal
+
+	// Relay latency and reservation roles (primary/backup).
+	latencies := make(map[peer.ID]sdk.RelayLatency)
+	if sel := rt.RelaySelector(); sel != nil {
+		for _, l := range sel.Latencies() {
+			latencies[l.PeerID] = l
+		}
+		if p := sel.Primary(); p != "" {
+			resp.PrimaryRelay = p.String()
+		}
+	}
+
 	// Relay connectivity status
 	for _, addrStr := range rt.RelayAddresses() {
 		maddr, err := ma.NewMultiaddr(addrStr)
@@ -245,11 +336,18 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 			ShortID:   short,
 			Connected: h.Network().Connectedness(info.ID) == network.Connected,
 		}
+		if l, ok := latencies[info.ID]; ok {
+			rs.Role = l.Role
+			if l.Reachable {
+				rs.LatencyMs = l.RTTMs
+			}
+		}
 
 		// Parse relay name and agent version from peerstore.
 		if av, avErr := h.Peerstore().Get(info.ID, "AgentVersion"); avErr == nil {
 			if s, ok := av.(string); ok {
 				rs.AgentVersion = s
+				rs.Region = sdk.RelayAgentRegion(s)
 				if start := strings.Index(s, "("); start >= 0 {
 					if end := strings.LastIndex(s, ")"); end > start+1 {
 						rs.RelayName = s[start+1 : end]
@@ -320,6 +418,26 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	// MOTD/goodbye messages from relays
 	resp.MOTDs = rt.RelayMOTDs()
 
+	// Rendezvous provider record health
+	if adv := rt.Advertiser(); adv != nil {
+		st := adv.Status()
+		resp.Advertise = &st
+	}
+
+	// Peers discovered on the LAN via mDNS
+	if md := rt.MDNSDiscovery(); md != nil {
+		if peers := md.Peers(); len(peers) > 0 {
+			reverseNames := s.buildReverseNames()
+			for _, p := range peers {
+				resp.MDNSPeers = append(resp.MDNSPeers, MDNSPeerInfo{
+					PeerID:   p.ID.String(),
+					Name:     reverseNames[p.ID.String()],
+					LastSeen: p.LastSeen.UTC().Format(time.RFC3339),
+				})
+			}
+		}
+	}
+
 	// Expiring grants (E3 mitigation: MOTD-style notification on CLI commands)
 	if gs := rt.GrantStore(); gs != nil {
 		expiring := gs.ExpiringWithin(10 * time.Minute)
@@ -430,6 +548,10 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 
 	// Proxy status (F8: single-command overview).
 	resp.Proxies = s.ProxyStatusList()
+	s.mu.Lock()
+	resp.ActiveProxies = s.ephemeralProxyCountLocked()
+	resp.MaxProxies = s.maxProxies
+	s.mu.Unlock()
 
 	if WantsText(r) {
 		var sb strings.Builder
@@ -440,19 +562,36 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(&sb, "services: %d\n", resp.ServicesCount)
 		fmt.Fprintf(&sb, "global_ipv6: %v\n", resp.HasGlobalIPv6)
 		fmt.Fprintf(&sb, "global_ipv4: %v\n", resp.HasGlobalIPv4)
+		fmt.Fprintf(&sb, "ip_mode: %s\n", resp.IPMode)
 		fmt.Fprintf(&sb, "is_relaying: %v\n", resp.IsRelaying)
+		if resp.MaxProxies > 0 {
+			fmt.Fprintf(&sb, "proxies: %d/%d\n", resp.ActiveProxies, resp.MaxProxies)
+		} else {
+			fmt.Fprintf(&sb, "proxies: %d\n", resp.ActiveProxies)
+		}
 		if resp.Reachability != nil {
 			fmt.Fprintf(&sb, "reachability: [%s] %s - %s\n", resp.Reachability.Grade, resp.Reachability.Label, resp.Reachability.Description)
 		}
 		if resp.NATType != "" {
 			fmt.Fprintf(&sb, "nat_type: %s\n", resp.NATType)
 		}
+		if resp.Traversal != nil {
+			fmt.Fprintf(&sb, "traversal: [%s] %s\n", resp.Traversal.Verdict, resp.Traversal.Summary)
+			fmt.Fprintf(&sb, "hole_punch: %s\n", formatHolePunchCounts(resp.Traversal.HolePunches.Attempts, resp.Traversal.HolePunches.Successes))
+		}
 		if len(resp.STUNExternalAddrs) > 0 {
 			fmt.Fprintf(&sb, "stun_external_addrs: %d\n", len(resp.STUNExternalAddrs))
 			for _, a := range resp.STUNExternalAddrs {
 				fmt.Fprintf(&sb, "  %s\n", a)
 			}
 		}
+		if len(resp.ObservedAddrs) > 0 {
+			fmt.Fprintf(&sb, "observed_addresses: %d (observed by peers)\n", len(resp.ObservedAddrs))
+			names := s.buildReverseNames()
+			for _, oa := range resp.ObservedAddrs {
+				fmt.Fprintf(&sb, "  %s  reported_by=%s\n", oa.Addr, formatReporters(oa.ReportedBy, names))
+			}
+		}
 		fmt.Fprintf(&sb, "listen_addresses: %d\n", len(resp.ListenAddrs))
 		for _, a := range resp.ListenAddrs {
 			fmt.Fprintf(&sb, "  %s\n", a)
@@ -461,6 +600,67 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		for _, a := range resp.RelayAddrs {
 			fmt.Fprintf(&sb, "  %s\n", a)
 		}
+		if len(resp.Relays) > 0 {
+			fmt.Fprintf(&sb, "relays: %d\n", len(resp.Relays))
+			for _, rs := range resp.Relays {
+				name := rs.RelayName
+				if name == "" {
+					name = rs.ShortID
+				}
+				state := "disconnected"
+				if rs.Connected {
+					state = "connected"
+				}
+				role := rs.Role
+				switch {
+				case role != "":
+				case resp.PrimaryRelay == "":
+					role = "unranked" // no latency probe answered yet
+				default:
+					role = "standby"
+				}
+				latency := "unmeasured"
+				if rs.LatencyMs > 0 {
+					latency = fmt.Sprintf("%.1fms", rs.LatencyMs)
+				}
+				fmt.Fprintf(&sb, "  %s: %s, %s, %s\n", name, role, latency, state)
+			}
+		}
+		if adv := resp.Advertise; adv != nil {
+			switch {
+			case adv.LastAdvertised.IsZero() && adv.LastAttempt.IsZero():
+				fmt.Fprintf(&sb, "advertise: pending (rendezvous %s)\n", adv.Rendezvous)
+			case adv.LastAdvertised.IsZero():
+				fmt.Fprintf(&sb, "advertise: FAILED (never succeeded) error: %s\n", adv.LastError)
+			default:
+				state := "ok"
+				if !adv.Healthy {
+					state = "STALE"
+				}
+				fmt.Fprintf(&sb, "advertise: %s (last advertised %s ago)\n", state, time.Since(adv.LastAdvertised).Round(time.Second))
+				if adv.LastError != "" {
+					fmt.Fprintf(&sb, "  last_error: %s (consecutive_failures: %d)\n", adv.LastError, adv.ConsecutiveFailures)
+				}
+			}
+			for _, g := range adv.Groups {
+				state := "ok"
+				switch {
+				case g.LastAttempt.IsZero():
+					state = "pending"
+				case g.LastAdvertised.IsZero():
+					state = "FAILED"
+				case !g.Healthy:
+					state = "STALE"
+				}
+				fmt.Fprintf(&sb, "  %s: %s\n", g.Rendezvous, state)
+			}
+		}
+		if len(resp.MDNSPeers) > 0 {
+			fmt.Fprintf(&sb, "mdns_peers: %d\n", len(resp.MDNSPeers))
+			for _, p := range resp.MDNSPeers {
+				fmt.Fprintf(&sb, "  %s %s\n", p.PeerID, p.Name)
+			}
+		}
 		if resp.ConfigReload != nil {
 			cr := resp.ConfigReload
 			ago := time.Since(cr.LastReloadTime).Round(time.Second)
@@ -513,16 +713,33 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, http.StatusOK, resp)
 }
 
+// formatReporters renders the peers that reported an observed address,
+// preferring configured names over truncated peer IDs.
+func formatReporters(ids []string, names map[string]string) string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			out = append(out, name)
+		} else {
+			out = append(out, truncatePeerID(id))
+		}
+	}
+	return strings.Join(out, ",")
+}
+
 func (s *Server) handleServiceList(w http.ResponseWriter, r *http.Request) {
 	services := s.runtime.Network().ListServices()
 
 	infos := make([]ServiceInfo, 0, len(services))
 	for _, svc := range services {
 		infos = append(infos, ServiceInfo{
-			Name:         svc.Name,
-			Protocol:     svc.Protocol,
-			LocalAddress: svc.LocalAddress,
-			Enabled:      svc.Enabled,
+			Name:             svc.Name,
+			Protocol:         svc.Protocol,
+			LocalAddress:     svc.LocalAddress,
+			Enabled:          svc.Enabled,
+			MaxBandwidthMbps: svc.MaxBandwidthMbps,
+			MaxConcurrent:    svc.MaxConcurrent,
+			RatePerMinute:    svc.RatePerMinute,
 		})
 	}
 
@@ -533,7 +750,17 @@ func (s *Server) handleServiceList(w http.ResponseWriter, r *http.Request) {
 			if !svc.Enabled {
 				status = "disabled"
 			}
-			fmt.Fprintf(&sb, "%s\t%s\t%s\t%s\n", svc.Name, svc.LocalAddress, svc.Protocol, status)
+			fmt.Fprintf(&sb, "%s\t%s\t%s\t%s", svc.Name, svc.LocalAddress, svc.Protocol, status)
+			if svc.MaxBandwidthMbps > 0 {
+				fmt.Fprintf(&sb, "\tlimit=%gMbps", svc.MaxBandwidthMbps)
+			}
+			if svc.MaxConcurrent > 0 {
+				fmt.Fprintf(&sb, "\tmax_concurrent=%d", svc.MaxConcurrent)
+			}
+			if svc.RatePerMinute > 0 {
+				fmt.Fprintf(&sb, "\trate=%d/min", svc.RatePerMinute)
+			}
+			fmt.Fprintln(&sb)
 		}
 		RespondText(w, http.StatusOK, sb.String())
 		return
@@ -582,7 +809,14 @@ func (s *Server) handleRemoteServiceList(w http.ResponseWriter, r *http.Request)
 	if WantsText(r) {
 		var sb strings.Builder
 		for _, svc := range services {
-			fmt.Fprintf(&sb, "%-16s %s\n", svc.Name, svc.Protocol)
+			fmt.Fprintf(&sb, "%-16s %s", svc.Name, svc.Protocol)
+			if svc.Network == "udp" {
+				sb.WriteString("  (udp)")
+			}
+			if svc.RequiresVerification {
+				sb.WriteString("  (requires verification)")
+			}
+			sb.WriteString("\n")
 		}
 		if len(services) == 0 {
 			sb.WriteString("(no services)\n")
@@ -594,14 +828,136 @@ func (s *Server) handleRemoteServiceList(w http.ResponseWriter, r *http.Request)
 	RespondJSON(w, http.StatusOK, RemoteServiceResponse{Services: services})
 }
 
+// peerHistoryEventLimit is how many recent connection events the text form
+// of GET /v1/peers/{id}/history lists. JSON returns the full window.
+const peerHistoryEventLimit = 20
+
+func (s *Server) handlePeerHistory(w http.ResponseWriter, r *http.Request) {
+	history := s.runtime.PeerHistory()
+	if history == nil {
+		RespondError(w, http.StatusServiceUnavailable, "peer history not available")
+		return
+	}
+
+	target := r.PathValue("id")
+	var pid peer.ID
+	var err error
+	if p2p := s.runtime.Network(); p2p != nil {
+		pid, err = p2p.ResolveName(target)
+	} else {
+		pid, err = peer.Decode(target)
+	}
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, fmt.Sprintf("cannot resolve peer %q: %v", target, err))
+		return
+	}
+
+	record := history.Get(pid.String())
+	if record == nil {
+		RespondError(w, http.StatusNotFound, fmt.Sprintf("no history for peer %s", pid))
+		return
+	}
+	resp := PeerHistoryResponse{
+		Record:  record,
+		Last24h: reputation.SummarizeEvents(record.Events, time.Now().Add(-24*time.Hour)),
+	}
+
+	if WantsText(r) {
+		RespondText(w, http.StatusOK, formatPeerHistory(target, resp))
+		return
+	}
+	RespondJSON(w, http.StatusOK, resp)
+}
+
+// formatPeerHistory renders a peer's history for the text API and CLI:
+// lifetime totals, the past day's path mix and flaps, then recent events.
+func formatPeerHistory(name string, resp PeerHistoryResponse) string {
+	rec := resp.Record
+	var b strings.Builder
+	if name != rec.PeerID {
+		fmt.Fprintf(&b, "%s (%s)\n", name, rec.PeerID)
+	} else {
+		fmt.Fprintf(&b, "%s\n", rec.PeerID)
+	}
+	fmt.Fprintf(&b, "  first seen: %s, last seen: %s\n",
+		rec.FirstSeen.Local().Format(time.DateTime), rec.LastSeen.Local().Format(time.DateTime))
+	fmt.Fprintf(&b, "  connections: %d, avg latency %.1f ms\n", rec.ConnectionCount, rec.AvgLatencyMs)
+
+	day := resp.Last24h
+	if day.Connections == 0 {
+		b.WriteString("  last 24h: no connections\n")
+	} else {
+		paths := make([]string, 0, len(day.PathTypes))
+		for _, t := range slices.Sorted(maps.Keys(day.PathTypes)) {
+			paths = append(paths, fmt.Sprintf("%s %d", t, day.PathTypes[t]))
+		}
+		fmt.Fprintf(&b, "  last 24h: %d connections (%s), %d path changes, latency avg %.1f ms / max %.1f ms\n",
+			day.Connections, strings.Join(paths, ", "), day.PathChanges, day.AvgLatencyMs, day.MaxLatencyMs)
+	}
+
+	if rec.HolePunchAttempts > 0 {
+		fmt.Fprintf(&b, "  hole punch: %s", formatHolePunchCounts(rec.HolePunchAttempts, rec.HolePunchSuccesses))
+		if rec.HolePunchSuccesses > 0 {
+			fmt.Fprintf(&b, ", last direct upgrade %s\n", rec.LastDirectUpgrade.Local().Format(time.DateTime))
+		} else if rec.LastHolePunchError != "" {
+			fmt.Fprintf(&b, ", never upgraded to direct (last error: %s)\n", rec.LastHolePunchError)
+		} else {
+			b.WriteString(", never upgraded to direct\n")
+		}
+	}
+
+	events := rec.Events
+	if len(events) > peerHistoryEventLimit {
+		events = events[len(events)-peerHistoryEventLimit:]
+	}
+	if len(events) > 0 {
+		b.WriteString("  recent:\n")
+		for _, e := range slices.Backward(events) {
+			fmt.Fprintf(&b, "    %s  %-8s %8.1f ms\n", e.Time.Local().Format(time.DateTime), e.PathType, e.LatencyMs)
+		}
+	}
+	return b.String()
+}
+
 func (s *Server) handlePeerList(w http.ResponseWriter, r *http.Request) {
-	h := s.runtime.Network().Host()
-	peerIDs := h.Network().Peers()
-	showAll := r.URL.Query().Get("all") == "true"
+	q := r.URL.Query()
+	opts := PeerListOptions{
+		All:     q.Get("all") == "true",
+		Watched: q.Get("watched") == "true",
+		Path:    q.Get("path"),
+		Sort:    q.Get("sort"),
+	}
+	switch opts.Path {
+	case "", PeerPathDirect, PeerPathRelayed:
+	default:
+		RespondError(w, http.StatusBadRequest, fmt.Sprintf("invalid path %q (use %s or %s)", opts.Path, PeerPathDirect, PeerPathRelayed))
+		return
+	}
+	switch opts.Sort {
+	case "", PeerSortPeerID, PeerSortLatency, PeerSortLastSeen:
+	default:
+		RespondError(w, http.StatusBadRequest, fmt.Sprintf("invalid sort %q (use %s, %s or %s)", opts.Sort, PeerSortLatency, PeerSortLastSeen, PeerSortPeerID))
+		return
+	}
+
+	net := s.runtime.Network()
+	h := net.Host()
+	match := s.peerFilter(r)
 
-	peers := make([]PeerInfo, 0, len(peerIDs))
+	names := make(map[string]string)
+	for name, pid := range net.ListNames() {
+		names[pid.String()] = name
+	}
+	var managed []sdk.ManagedPeerInfo
+	if pm := s.runtime.PeerManager(); pm != nil {
+		managed = pm.GetManagedPeers()
+	}
+	tracker := s.runtime.PathTracker()
+
+	peerIDs := h.Network().Peers()
+	live := make([]PeerInfo, 0, len(peerIDs))
 	for _, pid := range peerIDs {
-		info := PeerInfo{ID: pid.String()}
+		info := PeerInfo{ID: pid.String(), Name: names[pid.String()], Connected: true}
 
 		// Get agent version from peerstore
 		if av, err := h.Peerstore().Get(pid, "AgentVersion"); err == nil {
@@ -610,28 +966,59 @@ func (s *Server) handlePeerList(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		// By default, only show shurli and relay-server peers
-		if !showAll && !isShurliAgent(info.AgentVersion) {
-			continue
-		}
-
 		// Get addresses
 		addrs := h.Peerstore().Addrs(pid)
 		for _, a := range addrs {
 			info.Addresses = append(info.Addresses, a.String())
 		}
 
-		peers = append(peers, info)
+		info.Path = PeerPathDirect
+		if conns := h.Network().ConnsToPeer(pid); len(conns) > 0 && allConnsLimited(conns) {
+			info.Path = PeerPathRelayed
+		}
+		if tracker != nil {
+			if pp, ok := tracker.GetPeerPath(pid); ok {
+				info.LatencyMs = pp.LastRTTMs
+			}
+		}
+		if info.LatencyMs == 0 {
+			if ewma := h.Peerstore().LatencyEWMA(pid); ewma > 0 {
+				info.LatencyMs = float64(ewma.Microseconds()) / 1000
+			}
+		}
+		live = append(live, info)
 	}
 
+	peers := selectPeers(mergeWatchedPeers(live, managed, names, opts.Watched), opts, match)
+
 	if WantsText(r) {
 		var sb strings.Builder
 		for _, p := range peers {
+			id := p.ID[:16] + "..."
+			if p.Name != "" {
+				id += " (" + p.Name + ")"
+			}
+			if !p.Connected {
+				lastSeen := p.LastSeen
+				if lastSeen == "" {
+					lastSeen = "never"
+				}
+				fmt.Fprintf(&sb, "%s\tdisconnected\tlast seen %s\tfailures=%d", id, lastSeen, p.ConsecFailures)
+				if p.BackoffUntil != "" {
+					fmt.Fprintf(&sb, "\tbackoff until %s", p.BackoffUntil)
+				}
+				sb.WriteString("\n")
+				continue
+			}
 			agent := p.AgentVersion
 			if agent == "" {
 				agent = "unknown"
 			}
-			fmt.Fprintf(&sb, "%s\t%s\t%d addrs\n", p.ID[:16]+"...", agent, len(p.Addresses))
+			rtt := "-"
+			if p.LatencyMs > 0 {
+				rtt = fmt.Sprintf("%.1fms", p.LatencyMs)
+			}
+			fmt.Fprintf(&sb, "%s\t%s\t%s\trtt=%s\t%d addrs\n", id, agent, p.Path, rtt, len(p.Addresses))
 		}
 		RespondText(w, http.StatusOK, sb.String())
 		return
@@ -640,6 +1027,122 @@ func (s *Server) handlePeerList(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, http.StatusOK, peers)
 }
 
+// mergeWatchedPeers marks the live peers that the PeerManager watches with
+// their reconnect state. With includeDisconnected (--watched), watched peers
+// that are not connected are appended so they show up with their backoff.
+func mergeWatchedPeers(live []PeerInfo, managed []sdk.ManagedPeerInfo, names map[string]string, includeDisconnected bool) []PeerInfo {
+	byID := make(map[string]int, len(live))
+	for i, p := range live {
+		byID[p.ID] = i
+	}
+	for _, m := range managed {
+		i, ok := byID[m.PeerID]
+		if !ok {
+			if !includeDisconnected {
+				continue
+			}
+			live = append(live, PeerInfo{ID: m.PeerID, Name: names[m.PeerID]})
+			i = len(live) - 1
+		}
+		p := &live[i]
+		p.Watched = true
+		p.LastSeen = m.LastSeen
+		p.LastDialError = m.LastDialError
+		p.ConsecFailures = m.ConsecFailures
+		p.BackoffUntil = m.BackoffUntil
+	}
+	return live
+}
+
+// selectPeers applies the list filters and sort order. Watched peers are
+// always shurli peers, so the default agent filter does not hide them.
+func selectPeers(peers []PeerInfo, opts PeerListOptions, match func(peerID, peerName string) bool) []PeerInfo {
+	out := make([]PeerInfo, 0, len(peers))
+	for _, p := range peers {
+		switch {
+		case !match(p.ID, p.Name):
+		case opts.Watched && !p.Watched:
+		case !opts.All && !p.Watched && !isShurliAgent(p.AgentVersion):
+		case opts.Path != "" && p.Path != opts.Path:
+		default:
+			out = append(out, p)
+		}
+	}
+
+	byID := func(a, b PeerInfo) int { return strings.Compare(a.ID, b.ID) }
+	switch opts.Sort {
+	case PeerSortLatency:
+		slices.SortStableFunc(out, func(a, b PeerInfo) int {
+			// Unmeasured peers sort last.
+			if (a.LatencyMs == 0) != (b.LatencyMs == 0) {
+				if a.LatencyMs == 0 {
+					return 1
+				}
+				return -1
+			}
+			if c := cmp.Compare(a.LatencyMs, b.LatencyMs); c != 0 {
+				return c
+			}
+			return byID(a, b)
+		})
+	case PeerSortLastSeen:
+		slices.SortStableFunc(out, func(a, b PeerInfo) int {
+			if a.Connected != b.Connected {
+				if a.Connected {
+					return -1
+				}
+				return 1
+			}
+			// Most recently seen first; never-seen peers last.
+			if c := lastSeenTime(b).Compare(lastSeenTime(a)); c != 0 {
+				return c
+			}
+			return byID(a, b)
+		})
+	default:
+		slices.SortFunc(out, byID)
+	}
+	return out
+}
+
+// lastSeenTime parses PeerInfo.LastSeen; unset or invalid is the zero time.
+func lastSeenTime(p PeerInfo) time.Time {
+	t, _ := time.Parse(time.RFC3339, p.LastSeen)
+	return t
+}
+
+// allConnsLimited reports whether every connection is relay-limited.
+func allConnsLimited(conns []network.Conn) bool {
+	for _, c := range conns {
+		if !c.Stat().Limited {
+			return false
+		}
+	}
+	return true
+}
+
+// peerFilter returns a matcher for the ?peer= query parameter, which takes
+// a peer ID or a name known to the resolver. Without the parameter every
+// peer matches. A filter that resolves to no peer matches nothing, so the
+// endpoint returns an empty result rather than an error.
+func (s *Server) peerFilter(r *http.Request) func(peerID, peerName string) bool {
+	q := r.URL.Query().Get("peer")
+	if q == "" {
+		return func(string, string) bool { return true }
+	}
+	var target string
+	if net := s.runtime.Network(); net != nil {
+		if pid, err := net.ResolveName(q); err == nil {
+			target = pid.String()
+		}
+	} else if pid, err := peer.Decode(q); err == nil {
+		target = pid.String()
+	}
+	return func(peerID, peerName string) bool {
+		return (target != "" && peerID == target) || (peerName != "" && peerName == q)
+	}
+}
+
 func (s *Server) handlePaths(w http.ResponseWriter, r *http.Request) {
 	tracker := s.runtime.PathTracker()
 	if tracker == nil {
@@ -841,6 +1344,90 @@ func (s *Server) reloadGater() error {
 	return gater.ReloadFromFile()
 }
 
+// handleRemoteAdmin forwards one API call to another node's daemon over
+// the admin protocol and relays its response unchanged.
+func (s *Server) handleRemoteAdmin(w http.ResponseWriter, r *http.Request) {
+	var req RemoteAdminRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, MaxRequestBodySize)).Decode(&req); err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Peer == "" || req.Method == "" || req.Path == "" {
+		RespondError(w, http.StatusBadRequest, "peer, method and path are required")
+		return
+	}
+
+	targetPeerID, ok := s.resolveAndConnectTarget(r.Context(), w, req.Peer)
+	if !ok {
+		return
+	}
+
+	client := NewRemoteAdminClient(s.runtime.Network().Host(), targetPeerID)
+	resp, err := client.Do(r.Context(), AdminRequest{
+		Method: req.Method,
+		Path:   req.Path,
+		Body:   req.Body,
+		Text:   WantsText(r),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "protocols not supported") {
+			RespondError(w, http.StatusBadGateway, fmt.Sprintf("%s does not accept remote administration (add this node to its security.admin_peers)", req.Peer))
+			return
+		}
+		RespondError(w, http.StatusBadGateway, fmt.Sprintf("remote admin request to %s failed: %v", req.Peer, err))
+		return
+	}
+
+	// Prefix remote errors so they are not mistaken for local ones.
+	if resp.Status >= 400 {
+		var errResp ErrorResponse
+		if json.Unmarshal(resp.Body, &errResp) == nil && errResp.Error != "" {
+			RespondError(w, resp.Status, req.Peer+": "+errResp.Error)
+			return
+		}
+	}
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// resolveAndConnectTarget resolves a ping or traceroute target and makes
+// sure the peer is reachable, writing the error response on failure. Names
+// and peer IDs go through the DHT with relay fallback; a full multiaddr is
+// dialed directly at that address.
+func (s *Server) resolveAndConnectTarget(ctx context.Context, w http.ResponseWriter, target string) (peer.ID, bool) {
+	net := s.runtime.Network()
+	if sdk.IsMultiaddrTarget(target) {
+		if _, err := sdk.ParseMultiaddrTarget(target); err != nil {
+			RespondError(w, http.StatusBadRequest, err.Error())
+			return "", false
+		}
+		targetPeerID, err := net.ConnectMultiaddrTarget(ctx, target)
+		if err != nil {
+			RespondError(w, http.StatusBadGateway, fmt.Sprintf("cannot reach %s: %s", target, sdk.HumanizeError(err.Error())))
+			return "", false
+		}
+		return targetPeerID, true
+	}
+
+	targetPeerID, err := net.ResolveName(target)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, fmt.Sprintf("cannot resolve peer %q: %v", target, err))
+		return "", false
+	}
+
+	// Ensure the peer is reachable (DHT lookup + relay fallback)
+	if err := s.runtime.ConnectToPeer(ctx, targetPeerID); err != nil {
+		RespondError(w, http.StatusBadGateway, fmt.Sprintf("cannot reach peer %q: %s", target, sdk.HumanizeError(err.Error())))
+		return "", false
+	}
+	return targetPeerID, true
+}
+
 func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 	var req PingRequest
 	if err := json.NewDecoder(io.LimitReader(r.Body, MaxRequestBodySize)).Decode(&req); err != nil {
@@ -857,17 +1444,9 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 		interval = time.Duration(req.IntervalMs) * time.Millisecond
 	}
 
-	// Resolve peer name
 	net := s.runtime.Network()
-	targetPeerID, err := net.ResolveName(req.Peer)
-	if err != nil {
-		RespondError(w, http.StatusBadRequest, fmt.Sprintf("cannot resolve peer %q: %v", req.Peer, err))
-		return
-	}
-
-	// Ensure the peer is reachable (DHT lookup + relay fallback)
-	if err := s.runtime.ConnectToPeer(r.Context(), targetPeerID); err != nil {
-		RespondError(w, http.StatusBadGateway, fmt.Sprintf("cannot reach peer %q: %s", req.Peer, sdk.HumanizeError(err.Error())))
+	targetPeerID, ok := s.resolveAndConnectTarget(r.Context(), w, req.Peer)
+	if !ok {
 		return
 	}
 
@@ -893,17 +1472,7 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 
 	if WantsText(r) {
 		var sb strings.Builder
-		fmt.Fprintf(&sb, "PING %s (%s):\n", req.Peer, targetPeerID.String()[:16]+"...")
-		for _, pr := range results {
-			if pr.Error != "" {
-				fmt.Fprintf(&sb, "seq=%d error=%s\n", pr.Seq, pr.Error)
-			} else {
-				fmt.Fprintf(&sb, "seq=%d rtt=%.1fms path=[%s]\n", pr.Seq, pr.RttMs, pr.Path)
-			}
-		}
-		fmt.Fprintf(&sb, "--- %s ping statistics ---\n", req.Peer)
-		fmt.Fprintf(&sb, "%d sent, %d received, %.0f%% loss, rtt min/avg/max = %.1f/%.1f/%.1f ms\n",
-			stats.Sent, stats.Received, stats.LossPct, stats.MinMs, stats.AvgMs, stats.MaxMs)
+		sdk.WritePingReport(&sb, req.Peer, targetPeerID.String(), results, stats, false)
 		RespondText(w, http.StatusOK, sb.String())
 		return
 	}
@@ -911,8 +1480,8 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, http.StatusOK, PingResponse{Results: results, Stats: stats})
 }
 
-func (s *Server) handleTraceroute(w http.ResponseWriter, r *http.Request) {
-	var req TraceRequest
+func (s *Server) handleBandwidthTest(w http.ResponseWriter, r *http.Request) {
+	var req BandwidthTestRequest
 	if err := json.NewDecoder(io.LimitReader(r.Body, MaxRequestBodySize)).Decode(&req); err != nil {
 		RespondError(w, http.StatusBadRequest, "invalid request body")
 		return
@@ -921,17 +1490,71 @@ func (s *Server) handleTraceroute(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, http.StatusBadRequest, "peer is required")
 		return
 	}
+	if req.Direction == "" {
+		req.Direction = sdk.BandwidthBoth
+	}
+	if err := sdk.ValidateBandwidthDirection(req.Direction); err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	size := req.Bytes
+	if size <= 0 {
+		size = sdk.DefaultBandwidthTestBytes
+	}
+	if size > sdk.MaxBandwidthTestBytes {
+		RespondError(w, http.StatusBadRequest, fmt.Sprintf("bytes must be at most %d", sdk.MaxBandwidthTestBytes))
+		return
+	}
 
 	net := s.runtime.Network()
-	targetPeerID, err := net.ResolveName(req.Peer)
+	targetPeerID, ok := s.resolveAndConnectTarget(r.Context(), w, req.Peer)
+	if !ok {
+		return
+	}
+
+	// Extend write deadline: a large test over a slow relay runs for minutes.
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(15 * time.Minute))
+
+	result, err := sdk.BandwidthTest(r.Context(), net.Host(), targetPeerID, req.Direction, size)
 	if err != nil {
-		RespondError(w, http.StatusBadRequest, fmt.Sprintf("cannot resolve peer %q: %v", req.Peer, err))
+		RespondError(w, http.StatusBadGateway, fmt.Sprintf("bandwidth test to %s failed: %v", req.Peer, err))
 		return
 	}
 
-	// Ensure the peer is reachable (DHT lookup + relay fallback)
-	if err := s.runtime.ConnectToPeer(r.Context(), targetPeerID); err != nil {
-		RespondError(w, http.StatusBadGateway, fmt.Sprintf("cannot reach peer %q: %s", req.Peer, sdk.HumanizeError(err.Error())))
+	if WantsText(r) {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "BWTEST %s (%s) path=[%s]:\n", req.Peer, targetPeerID.String()[:16]+"...", result.Path)
+		writeBandwidthSample(&sb, "upload", result.Upload)
+		writeBandwidthSample(&sb, "download", result.Download)
+		RespondText(w, http.StatusOK, sb.String())
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, result)
+}
+
+func writeBandwidthSample(sb *strings.Builder, label string, s *sdk.BandwidthSample) {
+	if s == nil {
+		return
+	}
+	fmt.Fprintf(sb, "%-8s %s in %.2fs = %.1f Mbps\n", label, sdk.FormatBytes(s.Bytes), s.ElapsedMs/1000, s.Mbps)
+}
+
+func (s *Server) handleTraceroute(w http.ResponseWriter, r *http.Request) {
+	var req TraceRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, MaxRequestBodySize)).Decode(&req); err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Peer == "" {
+		RespondError(w, http.StatusBadRequest, "peer is required")
+		return
+	}
+
+	net := s.runtime.Network()
+	targetPeerID, ok := s.resolveAndConnectTarget(r.Context(), w, req.Peer)
+	if !ok {
 		return
 	}
 
@@ -950,17 +1573,20 @@ func (s *Server) handleTraceroute(w http.ResponseWriter, r *http.Request) {
 			if len(peerShort) > 16 {
 				peerShort = peerShort[:16] + "..."
 			}
+			if peerShort == "" {
+				peerShort = "?"
+			}
 			if hop.Error != "" {
-				fmt.Fprintf(&sb, " %d  %s  %s  *\n", hop.Hop, peerShort, hop.Address)
-			} else {
-				name := ""
-				if hop.Name != "" {
-					name = " (" + hop.Name + ")"
-				}
-				fmt.Fprintf(&sb, " %d  %s%s  %s  %.1fms\n", hop.Hop, peerShort, name, hop.Address, hop.RttMs)
+				fmt.Fprintf(&sb, " %d  %s%s  %s  *\n", hop.Hop, peerShort, hop.Label(), hop.Address)
+				continue
+			}
+			fmt.Fprintf(&sb, " %d  %s%s  %s  %.1fms", hop.Hop, peerShort, hop.Label(), hop.Address, hop.RttMs)
+			if note := hop.SegmentNote(); note != "" {
+				sb.WriteString("  " + note)
 			}
+			sb.WriteString("\n")
 		}
-		fmt.Fprintf(&sb, "--- path: [%s] ---\n", result.Path)
+		fmt.Fprintf(&sb, "--- path: [%s] ---\n", result.PathSummary())
 		RespondText(w, http.StatusOK, sb.String())
 		return
 	}
@@ -1109,6 +1735,7 @@ func (s *Server) handleProxyRemove(w http.ResponseWriter, r *http.Request) {
 	s.mu.Unlock()
 
 	if exists {
+		s.recordProxyEvent(notify.EventProxyStopped, proxy, "", "removed")
 		proxy.cancel()
 		if proxy.listener != nil {
 			proxy.listener.GracefulClose(5 * time.Second)
@@ -1192,7 +1819,7 @@ func (s *Server) handleProxyDisable(w http.ResponseWriter, r *http.Request) {
 	// Stop the running proxy. Release mutex before GracefulClose (up to 5s).
 	s.mu.Lock()
 	proxy, exists := s.proxies[name]
-	var oldListener *sdk.TCPListener
+	var oldListener proxyListener
 	if exists && proxy.persistent {
 		proxy.cancel()
 		oldListener = proxy.listener
@@ -1222,8 +1849,37 @@ func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	if req.Peer == "" || req.Service == "" || req.Listen == "" {
-		RespondError(w, http.StatusBadRequest, "peer, service, and listen are required")
+	proto := req.Proto
+	if proto == "" {
+		proto = "tcp"
+	}
+	switch proto {
+	case "tcp", "udp":
+		if req.Peer == "" || req.Service == "" || req.Listen == "" {
+			RespondError(w, http.StatusBadRequest, "peer, service, and listen are required")
+			return
+		}
+	case "stdio":
+		if req.Peer == "" || req.Service == "" {
+			RespondError(w, http.StatusBadRequest, "peer and service are required")
+			return
+		}
+		if !strings.EqualFold(r.Header.Get("Upgrade"), StreamUpgradeProtocol) {
+			RespondError(w, http.StatusBadRequest, "stdio proxies need an Upgrade: "+StreamUpgradeProtocol+" request")
+			return
+		}
+	default:
+		RespondError(w, http.StatusBadRequest, fmt.Sprintf("unknown proto %q (want tcp, udp or stdio)", req.Proto))
+		return
+	}
+
+	// Refuse early, before dialing the peer or binding a port. The limit
+	// is checked again when the proxy is registered.
+	s.mu.Lock()
+	limitErr := s.proxyLimitErrLocked()
+	s.mu.Unlock()
+	if limitErr != nil {
+		RespondError(w, http.StatusTooManyRequests, limitErr.Error())
 		return
 	}
 
@@ -1242,13 +1898,42 @@ func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check the peer actually offers the service before binding a local
+	// port, so a typo or a service renamed on the remote fails now with
+	// the list of what is available, not on the first proxied connection.
+	// A UDP service only makes sense over a udp proxy and vice versa.
+	// Peers that don't answer service queries are not blocked.
+	if services, err := pnet.QueryServices(r.Context(), targetPeerID); err != nil {
+		slog.Debug("service query before connect failed", "peer", req.Peer, "error", err)
+	} else if info, err := sdk.FindRemoteService(services, req.Service); err != nil {
+		RespondError(w, http.StatusNotFound, fmt.Sprintf("peer %q: %v", req.Peer, err))
+		return
+	} else if udp := info.Network == "udp"; udp != (proto == "udp") {
+		if udp {
+			RespondError(w, http.StatusBadRequest, fmt.Sprintf("peer %q: service %q is a UDP service; connect with --proto udp", req.Peer, req.Service))
+		} else {
+			RespondError(w, http.StatusBadRequest, fmt.Sprintf("peer %q: service %q is not a UDP service", req.Peer, req.Service))
+		}
+		return
+	}
+
 	// Create dial function with retry
 	dialFunc := sdk.DialWithRetry(func() (sdk.ServiceConn, error) {
 		return pnet.ConnectToService(targetPeerID, req.Service)
 	}, 3)
 
-	// Create TCP listener
-	listener, err := sdk.NewTCPListener(req.Listen, dialFunc)
+	if proto == "stdio" {
+		s.serveStdioProxy(w, req, targetPeerID, dialFunc)
+		return
+	}
+
+	// Create the local listener
+	var listener proxyListener
+	if proto == "udp" {
+		listener, err = sdk.NewUDPListener(req.Listen, dialFunc)
+	} else {
+		listener, err = sdk.NewTCPListener(req.Listen, dialFunc)
+	}
 	if err != nil {
 		RespondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to create listener: %v", err))
 		return
@@ -1256,6 +1941,12 @@ func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 
 	// Generate proxy ID
 	s.mu.Lock()
+	if err := s.proxyLimitErrLocked(); err != nil {
+		s.mu.Unlock()
+		listener.Close()
+		RespondError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
 	s.nextID++
 	id := fmt.Sprintf("~proxy-%d", s.nextID)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -1265,6 +1956,7 @@ func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 		Peer:     req.Peer,
 		Service:  req.Service,
 		Listen:   listener.Addr().String(),
+		Proto:    proto,
 		listener: listener,
 		cancel:   cancel,
 		done:     done,
@@ -1286,7 +1978,9 @@ func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 			case <-ctx.Done():
 				// Expected - proxy was disconnected
 			default:
-				slog.Error("proxy listener stopped", "id", id, "error", err)
+				if !s.Draining() {
+					slog.Error("proxy listener stopped", "id", id, "error", err)
+				}
 			}
 		}
 	}()
@@ -1295,15 +1989,121 @@ func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 	h := pnet.Host()
 	pathType, addr := sdk.PeerConnInfo(h, targetPeerID)
 
-	slog.Info("proxy created via API", "id", id, "peer", req.Peer, "service", req.Service, "listen", proxy.Listen, "path", pathType)
+	slog.Info("proxy created via API", "id", id, "peer", req.Peer, "service", req.Service, "proto", proto, "listen", proxy.Listen, "path", pathType)
+	s.recordProxyEvent(notify.EventProxyStarted, proxy, targetPeerID, "created via API")
 	RespondJSON(w, http.StatusOK, ConnectResponse{
 		ID:            id,
 		ListenAddress: proxy.Listen,
+		Proto:         proto,
 		PathType:      pathType,
 		Address:       addr,
 	})
 }
 
+// serveStdioProxy dials the service and hands the caller's connection over
+// to it: the response is a 101 switch to StreamUpgradeProtocol, after which
+// the socket carries the service stream's bytes both ways. The proxy is
+// registered under an ID (sent in the ProxyIDHeader) so it shows up and
+// can be disconnected like any other, and it ends when either side closes.
+func (s *Server) serveStdioProxy(w http.ResponseWriter, req ConnectRequest, targetPeerID peer.ID, dialFunc func() (sdk.ServiceConn, error)) {
+	serviceConn, err := dialFunc()
+	if err != nil {
+		RespondError(w, http.StatusBadGateway, fmt.Sprintf("cannot open service %q on peer %q: %s", req.Service, req.Peer, sdk.HumanizeError(err.Error())))
+		return
+	}
+
+	s.mu.Lock()
+	if err := s.proxyLimitErrLocked(); err != nil {
+		s.mu.Unlock()
+		serviceConn.Close()
+		RespondError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	s.nextID++
+	id := fmt.Sprintf("~proxy-%d", s.nextID)
+	s.mu.Unlock()
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		serviceConn.Close()
+		RespondError(w, http.StatusInternalServerError, fmt.Sprintf("cannot take over connection: %v", err))
+		return
+	}
+	conn.SetDeadline(time.Time{}) // the server's read/write timeouts no longer apply
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: %s\r\n%s: %s\r\n\r\n", StreamUpgradeProtocol, ProxyIDHeader, id)
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		serviceConn.Close()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	proxy := &activeProxy{
+		ID:      id,
+		Peer:    req.Peer,
+		Service: req.Service,
+		Listen:  "stdio",
+		Proto:   "stdio",
+		cancel:  cancel,
+		done:    done,
+	}
+	s.mu.Lock()
+	s.proxies[id] = proxy
+	s.mu.Unlock()
+	slog.Info("proxy created via API", "id", id, "peer", req.Peer, "service", req.Service, "proto", "stdio")
+	s.recordProxyEvent(notify.EventProxyStarted, proxy, targetPeerID, "created via API")
+
+	// Disconnect or daemon shutdown cancels ctx, which closes both ends.
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+		serviceConn.Close()
+	}()
+
+	go func() {
+		// Bytes the client sent right after the request are already buffered.
+		client := &upgradedConn{Reader: conn, Conn: conn}
+		if n := rw.Reader.Buffered(); n > 0 {
+			client.Reader = io.MultiReader(io.LimitReader(rw.Reader, int64(n)), conn)
+		}
+		sdk.BidirectionalProxy(client, serviceConn, "stdio")
+		cancel()
+		close(done) // before taking s.mu: Stop waits on done while holding it
+
+		// Ended by the client or the remote side: drop the registry entry.
+		// Disconnect has already removed it otherwise.
+		s.mu.Lock()
+		current, ok := s.proxies[id]
+		if ok && current == proxy {
+			delete(s.proxies, id)
+		}
+		s.mu.Unlock()
+		if ok && current == proxy {
+			slog.Info("stdio proxy closed", "id", id)
+			s.recordProxyEvent(notify.EventProxyStopped, proxy, targetPeerID, "stream closed")
+		}
+	}()
+}
+
+// upgradedConn is a hijacked API connection carrying a stdio proxy. Reads
+// go through Reader (bytes the HTTP server had already buffered, then the
+// conn); CloseWrite half-closes the socket so the client sees the service
+// finish sending.
+type upgradedConn struct {
+	io.Reader
+	net.Conn
+}
+
+func (c *upgradedConn) Read(p []byte) (int, error) { return c.Reader.Read(p) }
+
+func (c *upgradedConn) CloseWrite() error {
+	if hc, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return hc.CloseWrite()
+	}
+	return nil
+}
+
 func (s *Server) handleDisconnect(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
@@ -1330,6 +2130,7 @@ func (s *Server) handleDisconnect(w http.ResponseWriter, r *http.Request) {
 	<-proxy.done
 
 	slog.Info("proxy disconnected via API", "id", id)
+	s.recordProxyEvent(notify.EventProxyStopped, proxy, "", "disconnected via API")
 	RespondJSON(w, http.StatusOK, map[string]string{"status": "disconnected"})
 }
 
@@ -1344,12 +2145,14 @@ func (s *Server) handleExpose(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.runtime.Network().ExposeService(req.Name, req.LocalAddress, nil); err != nil {
+	pnet := s.runtime.Network()
+	opts := sdk.ExposeOptions{RequireVerified: req.RequireVerified}
+	if err := pnet.ExposeServiceWithOptions(req.Name, req.LocalAddress, nil, opts); err != nil {
 		RespondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	slog.Info("service exposed via API", "service", req.Name, "local", req.LocalAddress)
+	slog.Info("service exposed via API", "service", req.Name, "local", req.LocalAddress, "require_verified", req.RequireVerified)
 	RespondJSON(w, http.StatusOK, map[string]string{"status": "exposed"})
 }
 
@@ -1370,13 +2173,31 @@ func (s *Server) handleUnexpose(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
-	RespondJSON(w, http.StatusOK, map[string]string{"status": "shutting down"})
+	// The body is optional: a bare POST keeps the immediate stop.
+	var req ShutdownRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, MaxRequestBodySize)).Decode(&req); err != nil && err != io.EOF {
+		RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
 
-	// Signal shutdown after response is sent
-	go func() {
-		time.Sleep(100 * time.Millisecond) // let response flush
-		close(s.shutdownCh)
-	}()
+	if !req.Drain {
+		RespondJSON(w, http.StatusOK, map[string]string{"status": "shutting down"})
+		s.requestShutdown(0)
+		return
+	}
+
+	timeout := DefaultDrainTimeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 || d > MaxDrainTimeout {
+			RespondError(w, http.StatusBadRequest, fmt.Sprintf("timeout must be a duration between 0 and %s", MaxDrainTimeout))
+			return
+		}
+		timeout = d
+	}
+	slog.Info("draining shutdown requested via API", "timeout", timeout)
+	RespondJSON(w, http.StatusOK, map[string]string{"status": "draining", "timeout": timeout.String()})
+	s.requestShutdown(timeout)
 }
 
 func (s *Server) handleLock(w http.ResponseWriter, r *http.Request) {
@@ -1536,6 +2357,151 @@ func (s *Server) handleBandwidth(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, http.StatusOK, resp)
 }
 
+// handleInbound lists the inbound service streams open right now, with
+// each remote peer's authorization and verification status.
+// GET /v1/inbound
+func (s *Server) handleInbound(w http.ResponseWriter, r *http.Request) {
+	resp := InboundResponse{Streams: []InboundStreamInfo{}}
+	pnet := s.runtime.Network()
+	if pnet == nil {
+		RespondJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	// Snapshot authorized_keys once for the whole list.
+	authorized := make(map[peer.ID]auth.PeerEntry)
+	if authPath := s.runtime.AuthKeysPath(); authPath != "" {
+		if peers, err := auth.ListPeers(authPath); err == nil {
+			for _, p := range peers {
+				authorized[p.PeerID] = p
+			}
+		}
+	}
+	names := make(map[peer.ID]string)
+	for name, pid := range pnet.ListNames() {
+		names[pid] = name
+	}
+
+	now := time.Now()
+	for _, in := range pnet.ServiceRegistry().InboundStreams() {
+		entry, ok := authorized[in.PeerID]
+		path := "direct"
+		if in.Relayed {
+			path = "relayed"
+		}
+		resp.Streams = append(resp.Streams, InboundStreamInfo{
+			Service:         in.Service,
+			PeerID:          in.PeerID.String(),
+			PeerName:        names[in.PeerID],
+			Path:            path,
+			StartedAt:       in.StartedAt.UTC(),
+			DurationSeconds: now.Sub(in.StartedAt).Seconds(),
+			BytesIn:         in.BytesIn,
+			BytesOut:        in.BytesOut,
+			Authorized:      ok,
+			Verified:        ok && entry.Verified != "",
+		})
+	}
+
+	if WantsText(r) {
+		var sb strings.Builder
+		for _, in := range resp.Streams {
+			peerCol := in.PeerID
+			if in.PeerName != "" {
+				peerCol += " (" + in.PeerName + ")"
+			}
+			status := "unauthorized"
+			switch {
+			case in.Verified:
+				status = "verified"
+			case in.Authorized:
+				status = "authorized"
+			}
+			dur := time.Duration(in.DurationSeconds * float64(time.Second)).Truncate(time.Second)
+			fmt.Fprintf(&sb, "%s\t%s\t%s\t%s\tin=%s\tout=%s\t%s\n",
+				in.Service, peerCol, in.Path, dur,
+				sdk.FormatBytes(in.BytesIn), sdk.FormatBytes(in.BytesOut), status)
+		}
+		RespondText(w, http.StatusOK, sb.String())
+		return
+	}
+	RespondJSON(w, http.StatusOK, resp)
+}
+
+// handleHolePunch lists the DCUtR hole punch outcomes per peer, so a pair
+// that never upgrades from relayed to direct can be told apart from one
+// that simply hasn't tried.
+func (s *Server) handleHolePunch(w http.ResponseWriter, r *http.Request) {
+	resp := HolePunchResponse{Peers: []HolePunchPeerInfo{}}
+	if pnet := s.runtime.Network(); pnet != nil {
+		names := s.buildReverseNames()
+		resp.Summary = pnet.HolePunchStats()
+		for _, ps := range pnet.HolePunchPeers() {
+			resp.Peers = append(resp.Peers, HolePunchPeerInfo{HolePunchPeerStats: ps, Name: names[ps.PeerID]})
+		}
+	}
+
+	if WantsText(r) {
+		RespondText(w, http.StatusOK, formatHolePunch(resp))
+		return
+	}
+	RespondJSON(w, http.StatusOK, resp)
+}
+
+// formatHolePunch renders GET /v1/holepunch as text: the totals, then
+// one line per peer with its last error if it has never upgraded.
+func formatHolePunch(resp HolePunchResponse) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "hole_punch: %s\n", formatHolePunchCounts(resp.Summary.Attempts, resp.Summary.Successes))
+	now := time.Now()
+	for _, p := range resp.Peers {
+		label := truncatePeerID(p.PeerID)
+		if p.Name != "" {
+			label = p.Name + " (" + label + ")"
+		}
+		state := "never direct"
+		if p.Successes > 0 {
+			state = "upgraded to direct"
+		}
+		fmt.Fprintf(&sb, "  %s: %s, %s, last attempt %s ago\n", label, formatHolePunchCounts(p.Attempts, p.Successes),
+			state, now.Sub(p.LastAttempt).Truncate(time.Second))
+		if p.Successes == 0 && p.LastError != "" {
+			fmt.Fprintf(&sb, "    last error: %s\n", p.LastError)
+		}
+	}
+	return sb.String()
+}
+
+// formatHolePunchCounts renders e.g. "3 attempts, 1 success".
+func formatHolePunchCounts(attempts, successes int) string {
+	a, s := "attempts", "successes"
+	if attempts == 1 {
+		a = "attempt"
+	}
+	if successes == 1 {
+		s = "success"
+	}
+	return fmt.Sprintf("%d %s, %d %s", attempts, a, successes, s)
+}
+
+// handleStatsReset zeroes the session counters (bandwidth totals and
+// rates, last RTT per peer) so a benchmark can measure from a clean start.
+// Persistent peer history and monotonic Prometheus counters are untouched.
+// POST /v1/stats/reset
+func (s *Server) handleStatsReset(w http.ResponseWriter, r *http.Request) {
+	resp := StatsResetResponse{Reset: []string{}, ResetAt: time.Now().UTC()}
+	if bt := s.runtime.BandwidthTracker(); bt != nil {
+		bt.Reset()
+		resp.Reset = append(resp.Reset, "bandwidth")
+	}
+	if pt := s.runtime.PathTracker(); pt != nil {
+		pt.ResetStats()
+		resp.Reset = append(resp.Reset, "path_rtt")
+	}
+	slog.Info("daemon: session stats reset", "reset", resp.Reset)
+	RespondJSON(w, http.StatusOK, resp)
+}
+
 func (s *Server) handleRelayHealth(w http.ResponseWriter, r *http.Request) {
 	rh := s.runtime.RelayHealth()
 	if rh == nil {
@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// handleMessageSend delivers a short text message to a peer.
+// POST /v1/messages
+func (s *Server) handleMessageSend(w http.ResponseWriter, r *http.Request) {
+	messenger := s.runtime.Messenger()
+	if messenger == nil {
+		RespondError(w, http.StatusServiceUnavailable, "messaging not available")
+		return
+	}
+
+	var req MessageSendRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBodySize)).Decode(&req); err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Peer == "" {
+		RespondError(w, http.StatusBadRequest, "peer is required")
+		return
+	}
+	if err := sdk.ValidateMessageText(req.Text); err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	peerID, ok := s.resolveAndConnectTarget(r.Context(), w, req.Peer)
+	if !ok {
+		return
+	}
+	if err := messenger.Send(r.Context(), peerID, req.Text); err != nil {
+		RespondError(w, http.StatusBadGateway, fmt.Sprintf("send failed: %v", err))
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, MessageSendResponse{
+		Peer:   req.Peer,
+		PeerID: peerID.String(),
+		Status: "delivered",
+	})
+}
+
+// handleMessageList returns the inbox of received messages, oldest first.
+// GET /v1/messages
+func (s *Server) handleMessageList(w http.ResponseWriter, r *http.Request) {
+	messenger := s.runtime.Messenger()
+	if messenger == nil {
+		RespondError(w, http.StatusServiceUnavailable, "messaging not available")
+		return
+	}
+
+	reverseNames := s.buildReverseNames()
+	msgs := messenger.Messages()
+	list := make([]MessageInfo, 0, len(msgs))
+	for _, m := range msgs {
+		list = append(list, MessageInfo{
+			ID:       m.ID,
+			From:     m.From,
+			FromName: reverseNames[m.From],
+			Text:     m.Text,
+			Received: m.Received,
+		})
+	}
+
+	if WantsText(r) {
+		var sb strings.Builder
+		if len(list) == 0 {
+			sb.WriteString("No messages.\n")
+		}
+		for _, m := range list {
+			from := m.FromName
+			if from == "" {
+				from = m.From[:16] + "..."
+			}
+			fmt.Fprintf(&sb, "[%s] %s: %s\n", m.Received.Format("2006-01-02 15:04:05"), from, m.Text)
+		}
+		RespondText(w, http.StatusOK, sb.String())
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, list)
+}
+
+// handleMessageClear empties the inbox.
+// DELETE /v1/messages
+func (s *Server) handleMessageClear(w http.ResponseWriter, r *http.Request) {
+	messenger := s.runtime.Messenger()
+	if messenger == nil {
+		RespondError(w, http.StatusServiceUnavailable, "messaging not available")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]int{"cleared": messenger.Clear()})
+}
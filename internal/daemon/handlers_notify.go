@@ -1,8 +1,12 @@
 package daemon
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/shurlinet/shurli/internal/notify"
 )
@@ -48,3 +52,93 @@ func (s *Server) handleNotifyTest(w http.ResponseWriter, r *http.Request) {
 		"sinks":  sinksStr,
 	})
 }
+
+// handleEvents streams notification events as newline-delimited JSON, or
+// as Server-Sent Events when the client sends Accept: text/event-stream.
+// With ?since=<RFC3339 timestamp>, buffered events from that point are
+// replayed first, then live events follow until the client disconnects.
+// With ?peer=<id-or-name>, only events about that peer are sent.
+// GET /v1/events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	router := s.runtime.NotifyRouter()
+	if router == nil {
+		RespondError(w, http.StatusServiceUnavailable, "notification system not available")
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "invalid since: expected RFC3339 timestamp")
+			return
+		}
+		since = t
+	}
+
+	match := s.peerFilter(r)
+
+	replay, live, cancel := router.History().Subscribe(since)
+	defer cancel()
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	write := writeNDJSONEvent
+	if wantsSSE(r) {
+		write = writeSSEEvent
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range replay {
+		if !match(e.PeerID, e.PeerName) {
+			continue
+		}
+		if err := write(w, e); err != nil {
+			return
+		}
+	}
+	_ = rc.Flush()
+
+	for {
+		select {
+		case e := <-live:
+			if !match(e.PeerID, e.PeerName) {
+				continue
+			}
+			if err := write(w, e); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.streamStop:
+			return
+		}
+	}
+}
+
+// wantsSSE reports whether the client asked for a Server-Sent Events stream.
+func wantsSSE(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeNDJSONEvent(w io.Writer, e notify.Event) error {
+	return json.NewEncoder(w).Encode(e)
+}
+
+// writeSSEEvent writes one event in Server-Sent Events framing. The event
+// ID doubles as the SSE id and the event type as the SSE event name.
+func writeSSEEvent(w io.Writer, e notify.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -0,0 +1,254 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/notify"
+)
+
+type notifyMockRuntime struct {
+	*mockRuntime
+	router *notify.Router
+}
+
+func (m *notifyMockRuntime) NotifyRouter() *notify.Router { return m.router }
+
+func TestClientEvents_ReplayPrecedesLive(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	router := notify.NewRouter(nil, "")
+	rt := &notifyMockRuntime{mockRuntime: newMockRuntime(), router: router}
+	srv := NewServer(rt, socketPath, cookiePath, "test-0.1.0")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer srv.Stop()
+
+	now := time.Now()
+	emitAt := func(id string, ts time.Time) {
+		e := notify.NewEvent(notify.EventTest, notify.SeverityInfo, "", "", id)
+		e.Timestamp = ts
+		router.Emit(e)
+	}
+	emitAt("too-old", now.Add(-10*time.Minute))
+	emitAt("replay-1", now.Add(-3*time.Minute))
+	emitAt("replay-2", now.Add(-time.Minute))
+
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errDone := errors.New("done")
+	var got []string
+	err = client.Events(ctx, now.Add(-5*time.Minute), "", func(e notify.Event) error {
+		got = append(got, e.Message)
+		switch e.Message {
+		case "replay-2":
+			// Stream is established; anything emitted now is live.
+			emitAt("live", time.Now())
+		case "live":
+			return errDone
+		}
+		return nil
+	})
+	if !errors.Is(err, errDone) {
+		t.Fatalf("Events returned %v, want stream to reach the live event", err)
+	}
+
+	want := []string{"replay-1", "replay-2", "live"}
+	if len(got) != len(want) {
+		t.Fatalf("got events %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHandleEvents_NoRouter(t *testing.T) {
+	srv, _ := newTestServer(t)
+	req := httptest.NewRequest("GET", "/v1/events", nil)
+	rec := httptest.NewRecorder()
+	srv.handleEvents(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestHandleEvents_PeerFilter(t *testing.T) {
+	router := notify.NewRouter(nil, "")
+	rt := &notifyMockRuntime{mockRuntime: newMockRuntime(), router: router}
+	srv := NewServer(rt, filepath.Join(t.TempDir(), "test.sock"), filepath.Join(t.TempDir(), ".cookie"), "test-0.1.0")
+
+	pidA := genHandlerPeerID(t).String()
+	pidB := genHandlerPeerID(t).String()
+	router.Emit(notify.NewEvent(notify.EventTest, notify.SeverityInfo, pidA, "home", "a-1"))
+	router.Emit(notify.NewEvent(notify.EventTest, notify.SeverityInfo, pidB, "", "b-1"))
+	router.Emit(notify.NewEvent(notify.EventTest, notify.SeverityInfo, pidA, "home", "a-2"))
+
+	// Replay only: the cancelled context ends the stream after the backlog.
+	stream := func(filter string) []string {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		since := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339Nano))
+		req := httptest.NewRequest("GET", "/v1/events?since="+since+"&peer="+url.QueryEscape(filter), nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		srv.handleEvents(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		var got []string
+		dec := json.NewDecoder(rec.Body)
+		for {
+			var e notify.Event
+			if err := dec.Decode(&e); err != nil {
+				break
+			}
+			got = append(got, e.Message)
+		}
+		return got
+	}
+
+	if got := stream(pidA); strings.Join(got, ",") != "a-1,a-2" {
+		t.Errorf("by ID: got %v, want [a-1 a-2]", got)
+	}
+	if got := stream("home"); strings.Join(got, ",") != "a-1,a-2" {
+		t.Errorf("by name: got %v, want [a-1 a-2]", got)
+	}
+	if got := stream(genHandlerPeerID(t).String()); len(got) != 0 {
+		t.Errorf("no match: got %v, want none", got)
+	}
+	if got := stream(""); len(got) != 3 {
+		t.Errorf("unfiltered: got %v, want all 3", got)
+	}
+}
+
+func TestHandleEvents_SSEFraming(t *testing.T) {
+	router := notify.NewRouter(nil, "")
+	rt := &notifyMockRuntime{mockRuntime: newMockRuntime(), router: router}
+	srv := NewServer(rt, filepath.Join(t.TempDir(), "test.sock"), filepath.Join(t.TempDir(), ".cookie"), "test-0.1.0")
+
+	e := notify.NewEvent(notify.EventPeerConnected, notify.SeverityInfo, "", "", "peer connected")
+	router.Record(e)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	since := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339Nano))
+	req := httptest.NewRequest("GET", "/v1/events?since="+since, nil).WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	srv.handleEvents(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	body := rec.Body.String()
+	prefix := "id: " + e.ID + "\nevent: peer_connected\ndata: {"
+	if !strings.HasPrefix(body, prefix) || !strings.HasSuffix(body, "}\n\n") {
+		t.Errorf("unexpected SSE frame:\n%s", body)
+	}
+}
+
+func TestClientSubscribeEvents(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	router := notify.NewRouter(nil, "")
+	rt := &notifyMockRuntime{mockRuntime: newMockRuntime(), router: router}
+	srv := NewServer(rt, socketPath, cookiePath, "test-0.1.0")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer srv.Stop()
+
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := client.SubscribeEvents(ctx, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("SubscribeEvents failed: %v", err)
+	}
+
+	waitSubscribers := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for router.History().Subscribers() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("subscribers = %d, want %d", router.History().Subscribers(), want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitSubscribers(1)
+
+	router.Record(notify.NewEvent(notify.EventProxyStarted, notify.SeverityInfo, "", "", "proxy ssh started"))
+	select {
+	case e := <-events:
+		if e.Type != notify.EventProxyStarted {
+			t.Errorf("event type = %q, want %q", e.Type, notify.EventProxyStarted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+
+	// Cancelling the context closes the channel and releases the
+	// server-side subscription.
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("received event after cancel, want closed channel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	waitSubscribers(0)
+}
+
+func TestClientSubscribeEvents_RequiresAuth(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	router := notify.NewRouter(nil, "")
+	rt := &notifyMockRuntime{mockRuntime: newMockRuntime(), router: router}
+	srv := NewServer(rt, socketPath, cookiePath, "test-0.1.0")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer srv.Stop()
+
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.authToken = "wrong"
+
+	if _, err := client.SubscribeEvents(context.Background(), time.Time{}, ""); err == nil {
+		t.Fatal("expected error with invalid token")
+	}
+	if n := router.History().Subscribers(); n != 0 {
+		t.Errorf("subscribers = %d, want 0", n)
+	}
+}
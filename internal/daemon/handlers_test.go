@@ -15,10 +15,13 @@ import (
 
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/shurlinet/shurli/internal/config"
 	"github.com/shurlinet/shurli/internal/grants"
 	"github.com/shurlinet/shurli/internal/notify"
+	"github.com/shurlinet/shurli/internal/reputation"
+	"github.com/shurlinet/shurli/internal/watchdog"
 	"github.com/shurlinet/shurli/pkg/sdk"
 )
 
@@ -31,6 +34,11 @@ type networkMockRuntime struct {
 	pingProto    string
 	authKeysPath string
 	gater        GaterReloader
+	relayAddrs   []string
+	healthChecks []watchdog.CheckStatus
+	peerHistory  *reputation.PeerHistory
+	messenger    *sdk.Messenger
+	mdns         *sdk.MDNSDiscovery
 }
 
 func (m *networkMockRuntime) Network() *sdk.Network         { return m.net }
@@ -48,9 +56,10 @@ func (m *networkMockRuntime) PathTracker() *sdk.PathTracker           { return n
 func (m *networkMockRuntime) PathProtector() *sdk.PathProtector       { return nil }
 func (m *networkMockRuntime) BandwidthTracker() *sdk.BandwidthTracker { return nil }
 func (m *networkMockRuntime) RelayHealth() *sdk.RelayHealth           { return nil }
+func (m *networkMockRuntime) RelaySelector() *sdk.RelaySelector       { return nil }
 func (m *networkMockRuntime) STUNResult() *sdk.STUNResult             { return nil }
 func (m *networkMockRuntime) IsRelaying() bool                            { return false }
-func (m *networkMockRuntime) RelayAddresses() []string                    { return nil }
+func (m *networkMockRuntime) RelayAddresses() []string                    { return m.relayAddrs }
 func (m *networkMockRuntime) RelayNameFromConfig(string) string           { return "" }
 func (m *networkMockRuntime) DiscoveryNetwork() string                    { return "" }
 func (m *networkMockRuntime) RelayMOTDs() []MOTDInfo                      { return nil }
@@ -63,6 +72,11 @@ func (m *networkMockRuntime) GrantsMaxRefreshDuration() string             { ret
 func (m *networkMockRuntime) NotifyRouter() *notify.Router                 { return nil }
 func (m *networkMockRuntime) PeerManager() *sdk.PeerManager             { return nil }
 func (m *networkMockRuntime) GrantCacheSnapshot() []*grants.GrantReceipt   { return nil }
+func (m *networkMockRuntime) Messenger() *sdk.Messenger                  { return m.messenger }
+func (m *networkMockRuntime) Advertiser() *sdk.RendezvousAdvertiser      { return nil }
+func (m *networkMockRuntime) MDNSDiscovery() *sdk.MDNSDiscovery          { return m.mdns }
+func (m *networkMockRuntime) HealthChecks() []watchdog.CheckStatus       { return m.healthChecks }
+func (m *networkMockRuntime) PeerHistory() *reputation.PeerHistory       { return m.peerHistory }
 
 // mockGater implements GaterReloader for testing auth add/remove.
 type mockGater struct {
@@ -198,6 +212,10 @@ func TestHandleStatus_JSON(t *testing.T) {
 	if status.UptimeSeconds < 59 {
 		t.Errorf("UptimeSeconds = %d, expected >= 59", status.UptimeSeconds)
 	}
+	// No STUN result and no hole punches yet: the assessment says so.
+	if status.Traversal == nil || status.Traversal.Verdict != sdk.TraversalUnknown {
+		t.Errorf("Traversal = %+v, want verdict %q", status.Traversal, sdk.TraversalUnknown)
+	}
 }
 
 func TestHandleStatus_Text(t *testing.T) {
@@ -215,13 +233,42 @@ func TestHandleStatus_Text(t *testing.T) {
 	}
 
 	body := rec.Body.String()
-	for _, want := range []string{"peer_id:", "version:", "uptime:", "connected_peers:", "listen_addresses:"} {
+	for _, want := range []string{"peer_id:", "version:", "uptime:", "connected_peers:", "traversal:", "listen_addresses:"} {
 		if !bytes.Contains([]byte(body), []byte(want)) {
 			t.Errorf("text output missing %q", want)
 		}
 	}
 }
 
+func TestHandleStatus_MDNSPeers(t *testing.T) {
+	srv, rt := newNetworkServer(t)
+	rt.mdns = sdk.NewMDNSDiscovery(rt.net.Host(), nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := rt.mdns.Start(ctx); err != nil {
+		t.Fatalf("mdns Start: %v", err)
+	}
+	defer rt.mdns.Close()
+
+	lanPeer := genHandlerPeerID(t)
+	addr, _ := ma.NewMultiaddr("/ip4/192.168.1.100/tcp/9999")
+	rt.mdns.HandlePeerFound(peer.AddrInfo{ID: lanPeer, Addrs: []ma.Multiaddr{addr}})
+
+	req := httptest.NewRequest("GET", "/v1/status", nil)
+	rec := httptest.NewRecorder()
+	srv.handleStatus(rec, req)
+
+	var envelope DataResponse
+	json.NewDecoder(rec.Body).Decode(&envelope)
+	dataBytes, _ := json.Marshal(envelope.Data)
+	var status StatusResponse
+	json.Unmarshal(dataBytes, &status)
+
+	if len(status.MDNSPeers) != 1 || status.MDNSPeers[0].PeerID != lanPeer.String() {
+		t.Errorf("MDNSPeers = %+v, want [%s]", status.MDNSPeers, lanPeer)
+	}
+}
+
 // --- handleServiceList ---
 
 func TestHandleServiceList_Empty(t *testing.T) {
@@ -289,6 +336,35 @@ func TestHandleServiceList_Text(t *testing.T) {
 	}
 }
 
+func TestHandleServiceList_BandwidthLimit(t *testing.T) {
+	srv, rt := newNetworkServer(t)
+	opts := sdk.ExposeOptions{ServiceLimits: sdk.ServiceLimits{MaxBandwidthMbps: 25}}
+	if err := rt.net.ExposeServiceWithOptions("files", "localhost:8080", nil, opts); err != nil {
+		t.Fatalf("ExposeServiceWithOptions: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/v1/services", nil)
+	rec := httptest.NewRecorder()
+	srv.handleServiceList(rec, req)
+
+	var envelope DataResponse
+	json.NewDecoder(rec.Body).Decode(&envelope)
+	dataBytes, _ := json.Marshal(envelope.Data)
+	var services []ServiceInfo
+	json.Unmarshal(dataBytes, &services)
+
+	if len(services) != 1 || services[0].MaxBandwidthMbps != 25 {
+		t.Fatalf("services = %+v, want files with 25 Mbps limit", services)
+	}
+
+	req = httptest.NewRequest("GET", "/v1/services?format=text", nil)
+	rec = httptest.NewRecorder()
+	srv.handleServiceList(rec, req)
+	if body := rec.Body.String(); !strings.Contains(body, "limit=25Mbps") {
+		t.Errorf("text output missing limit: %q", body)
+	}
+}
+
 // --- handlePeerList ---
 
 func TestHandlePeerList_Empty(t *testing.T) {
@@ -779,6 +855,236 @@ func TestHandleTraceroute_InvalidBody(t *testing.T) {
 	}
 }
 
+func TestHandleHealth(t *testing.T) {
+	srv, rt := newNetworkServer(t)
+
+	get := func() (int, HealthResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		srv.handleHealth(rec, httptest.NewRequest("GET", "/v1/health", nil))
+		var resp struct {
+			Data HealthResponse `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return rec.Code, resp.Data
+	}
+
+	// No relays configured: healthy without a reservation.
+	code, resp := get()
+	if code != http.StatusOK || !resp.OK {
+		t.Errorf("no relays: status = %d, ok = %v; want 200, true", code, resp.OK)
+	}
+	if resp.RelayConnected {
+		t.Error("relay_connected should be false without a reservation")
+	}
+
+	// Watchdog results are passed through.
+	rt.healthChecks = []watchdog.CheckStatus{{Name: "relay-reservation", ConsecutiveFailures: 3, RecoveryAttempts: 1}}
+	_, resp = get()
+	if len(resp.Checks) != 1 || resp.Checks[0].RecoveryAttempts != 1 {
+		t.Errorf("checks = %+v, want the runtime's watchdog state", resp.Checks)
+	}
+
+	// Relays configured but no reservation held: unhealthy.
+	rt.relayAddrs = []string{"/ip4/203.0.113.1/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An"}
+	code, resp = get()
+	if code != http.StatusServiceUnavailable || resp.OK {
+		t.Errorf("relay missing: status = %d, ok = %v; want 503, false", code, resp.OK)
+	}
+}
+
+func TestHandleShutdownDrain(t *testing.T) {
+	post := func(srv *Server, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		srv.handleShutdown(rec, httptest.NewRequest("POST", "/v1/shutdown", strings.NewReader(body)))
+		return rec
+	}
+
+	// Bad timeouts are refused without starting a shutdown.
+	srv, _ := newNetworkServer(t)
+	for _, body := range []string{`{"drain":true,"timeout":"soon"}`, `{"drain":true,"timeout":"-1s"}`, `{"drain":true,"timeout":"48h"}`} {
+		if rec := post(srv, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, rec.Code)
+		}
+	}
+	if srv.Draining() || srv.DrainTimeout() != 0 {
+		t.Fatal("rejected request must not start a drain")
+	}
+
+	// An empty body keeps the immediate stop.
+	if rec := post(srv, ""); rec.Code != http.StatusOK {
+		t.Fatalf("plain stop: status = %d", rec.Code)
+	}
+	if srv.DrainTimeout() != 0 {
+		t.Errorf("plain stop: DrainTimeout = %v, want 0", srv.DrainTimeout())
+	}
+
+	srv, _ = newNetworkServer(t)
+	if rec := post(srv, `{"drain":true,"timeout":"5s"}`); rec.Code != http.StatusOK {
+		t.Fatalf("drain: status = %d", rec.Code)
+	}
+	if srv.DrainTimeout() != 5*time.Second {
+		t.Errorf("DrainTimeout = %v, want 5s", srv.DrainTimeout())
+	}
+	select {
+	case <-srv.ShutdownCh():
+	case <-time.After(2 * time.Second):
+		t.Fatal("ShutdownCh was not closed after drain request")
+	}
+
+	// Health reports the drain and fails so load balancers move away.
+	rec := httptest.NewRecorder()
+	srv.handleHealth(rec, httptest.NewRequest("GET", "/v1/health", nil))
+	var resp struct {
+		Data HealthResponse `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusServiceUnavailable || !resp.Data.Draining {
+		t.Errorf("health while draining: status = %d, draining = %v", rec.Code, resp.Data.Draining)
+	}
+
+	// Nothing open: Drain returns at once.
+	start := time.Now()
+	srv.Drain(context.Background(), 5*time.Second)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Drain with no connections took %v", d)
+	}
+}
+
+func TestHandlePeerHistory(t *testing.T) {
+	srv, rt := newNetworkServer(t)
+	pid := genHandlerPeerID(t)
+
+	get := func(target string, text bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/v1/peers/"+target+"/history", nil)
+		req.SetPathValue("id", target)
+		if text {
+			req.Header.Set("Accept", "text/plain")
+		}
+		rec := httptest.NewRecorder()
+		srv.handlePeerHistory(rec, req)
+		return rec
+	}
+
+	if rec := get(pid.String(), false); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("no history: status = %d, want 503", rec.Code)
+	}
+
+	rt.peerHistory = reputation.NewPeerHistory(filepath.Join(t.TempDir(), "peer_history.json"))
+	if rec := get(pid.String(), false); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown peer: status = %d, want 404", rec.Code)
+	}
+	if rec := get("not-a-peer", false); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad peer: status = %d, want 400", rec.Code)
+	}
+
+	rt.peerHistory.RecordConnection(pid.String(), "DIRECT", 12)
+	rt.peerHistory.RecordConnection(pid.String(), "RELAYED", 95)
+	rec := get(pid.String(), false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data PeerHistoryResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Data.Record.Events) != 2 {
+		t.Errorf("events = %d, want 2", len(resp.Data.Record.Events))
+	}
+	day := resp.Data.Last24h
+	if day.Connections != 2 || day.PathChanges != 1 || day.PathTypes["RELAYED"] != 1 {
+		t.Errorf("last_24h = %+v", day)
+	}
+
+	rt.peerHistory.RecordHolePunch(pid.String(), false, "all dials failed")
+	text := get(pid.String(), true).Body.String()
+	for _, want := range []string{"DIRECT 1, RELAYED 1", "1 path changes", "recent:",
+		"hole punch: 1 attempt, 0 successes, never upgraded to direct (last error: all dials failed)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandleHolePunch(t *testing.T) {
+	srv, _ := newNetworkServer(t)
+
+	rec := httptest.NewRecorder()
+	srv.handleHolePunch(rec, httptest.NewRequest("GET", "/v1/holepunch", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Data HolePunchResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.Peers == nil || len(resp.Data.Peers) != 0 || resp.Data.Summary.Attempts != 0 {
+		t.Errorf("fresh network: %+v, want zero summary and empty peer list", resp.Data)
+	}
+}
+
+func TestFormatHolePunch(t *testing.T) {
+	now := time.Now()
+	text := formatHolePunch(HolePunchResponse{
+		Summary: sdk.HolePunchStats{Attempts: 3, Successes: 1},
+		Peers: []HolePunchPeerInfo{
+			{HolePunchPeerStats: sdk.HolePunchPeerStats{PeerID: "12D3KooWAAAAAAAAAAAAAAAA", Attempts: 1, Successes: 1,
+				LastAttempt: now, LastSuccess: now}, Name: "laptop"},
+			{HolePunchPeerStats: sdk.HolePunchPeerStats{PeerID: "12D3KooWBBBBBBBBBBBBBBBB", Attempts: 2,
+				LastAttempt: now.Add(-time.Minute), LastError: "all dials failed"}},
+		},
+	})
+	for _, want := range []string{
+		"hole_punch: 3 attempts, 1 success\n",
+		"laptop (12D3KooWAAAAAAAA...): 1 attempt, 1 success, upgraded to direct",
+		"12D3KooWBBBBBBBB...: 2 attempts, 0 successes, never direct, last attempt 1m0s ago",
+		"    last error: all dials failed\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Count(text, "last error") != 1 {
+		t.Errorf("last error shown for an upgraded peer:\n%s", text)
+	}
+}
+
+func TestHandleBandwidthTest_Validation(t *testing.T) {
+	srv, _ := newNetworkServer(t)
+
+	tests := []struct {
+		name string
+		req  *BandwidthTestRequest // nil sends an unparseable body
+	}{
+		{"invalid body", nil},
+		{"empty peer", &BandwidthTestRequest{}},
+		{"bad direction", &BandwidthTestRequest{Peer: "home", Direction: "sideways"}},
+		{"too large", &BandwidthTestRequest{Peer: "home", Bytes: 2 << 30}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte("bad")
+			if tt.req != nil {
+				body, _ = json.Marshal(tt.req)
+			}
+			req := httptest.NewRequest("POST", "/v1/bwtest", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+			srv.handleBandwidthTest(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
 // --- handleConnect input validation ---
 
 func TestHandleConnect_MissingFields(t *testing.T) {
@@ -906,6 +1212,148 @@ func TestHandlePeerList_WithConnectedPeers(t *testing.T) {
 	})
 }
 
+// --- handlePeerList ?peer= filter ---
+
+func TestHandlePeerList_PeerFilter(t *testing.T) {
+	dir := t.TempDir()
+	netA := newListeningTestNetwork(t)
+	netB := newListeningTestNetwork(t)
+	netC := newListeningTestNetwork(t)
+	for _, other := range []*sdk.Network{netB, netC} {
+		info := peer.AddrInfo{ID: other.Host().ID(), Addrs: other.Host().Addrs()}
+		if err := netA.Host().Connect(context.Background(), info); err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		netA.Host().Peerstore().Put(other.Host().ID(), "AgentVersion", "shurli/test-0.1.0")
+	}
+	if err := netA.RegisterName("home", netB.Host().ID()); err != nil {
+		t.Fatalf("RegisterName: %v", err)
+	}
+
+	rt := &networkMockRuntime{net: netA, version: "test-0.1.0", startTime: time.Now()}
+	srv := NewServer(rt, filepath.Join(dir, "test.sock"), filepath.Join(dir, ".test-cookie"), "test-0.1.0")
+
+	list := func(t *testing.T, filter string) []PeerInfo {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/v1/peers?peer="+filter, nil)
+		rec := httptest.NewRecorder()
+		srv.handlePeerList(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		var envelope DataResponse
+		json.NewDecoder(rec.Body).Decode(&envelope)
+		dataBytes, _ := json.Marshal(envelope.Data)
+		var peers []PeerInfo
+		json.Unmarshal(dataBytes, &peers)
+		return peers
+	}
+
+	t.Run("full ID", func(t *testing.T) {
+		peers := list(t, netC.Host().ID().String())
+		if len(peers) != 1 || peers[0].ID != netC.Host().ID().String() {
+			t.Errorf("peers = %+v, want only C", peers)
+		}
+	})
+	t.Run("name", func(t *testing.T) {
+		peers := list(t, "home")
+		if len(peers) != 1 || peers[0].ID != netB.Host().ID().String() {
+			t.Errorf("peers = %+v, want only B", peers)
+		}
+	})
+	t.Run("no match", func(t *testing.T) {
+		if peers := list(t, genHandlerPeerID(t).String()); len(peers) != 0 {
+			t.Errorf("peers = %+v, want none", peers)
+		}
+		if peers := list(t, "nobody"); len(peers) != 0 {
+			t.Errorf("peers = %+v, want none for unknown name", peers)
+		}
+	})
+}
+
+// --- handlePeerList watched/path/sort ---
+
+func TestMergeWatchedPeers(t *testing.T) {
+	live := []PeerInfo{
+		{ID: "peer-a", Connected: true, Path: PeerPathDirect, AgentVersion: "shurli/1"},
+		{ID: "peer-dht", Connected: true, Path: PeerPathDirect, AgentVersion: "kubo/0.30"},
+	}
+	managed := []sdk.ManagedPeerInfo{
+		{PeerID: "peer-a", Connected: true, LastSeen: "2026-03-01T10:00:00Z"},
+		{PeerID: "peer-b", LastSeen: "2026-03-01T09:00:00Z", ConsecFailures: 3, BackoffUntil: "2026-03-01T10:05:00Z", LastDialError: "no route"},
+	}
+	if got := mergeWatchedPeers(live, managed, nil, false); len(got) != 2 || !got[0].Watched {
+		t.Errorf("without --watched got %+v, want only the live peers with peer-a watched", got)
+	}
+
+	got := mergeWatchedPeers(live, managed, map[string]string{"peer-b": "laptop"}, true)
+	if len(got) != 3 {
+		t.Fatalf("got %d peers, want 3", len(got))
+	}
+	if !got[0].Watched || got[0].LastSeen == "" || !got[0].Connected {
+		t.Errorf("peer-a = %+v, want connected and watched", got[0])
+	}
+	if got[1].Watched {
+		t.Error("DHT peer marked watched")
+	}
+	b := got[2]
+	if b.ID != "peer-b" || b.Connected || !b.Watched || b.Name != "laptop" || b.ConsecFailures != 3 || b.BackoffUntil == "" || b.LastDialError != "no route" {
+		t.Errorf("peer-b = %+v, want disconnected watched peer with backoff", b)
+	}
+}
+
+func TestSelectPeers(t *testing.T) {
+	peers := []PeerInfo{
+		{ID: "c", Connected: true, Path: PeerPathRelayed, AgentVersion: "shurli/1", LatencyMs: 80, Watched: true, LastSeen: "2026-03-01T08:00:00Z"},
+		{ID: "a", Connected: true, Path: PeerPathDirect, AgentVersion: "shurli/1", LatencyMs: 12},
+		{ID: "d", Connected: true, Path: PeerPathDirect, AgentVersion: "kubo/0.30", LatencyMs: 5},
+		{ID: "b", Watched: true, LastSeen: "2026-03-01T09:00:00Z"},
+		{ID: "e", Watched: true},
+	}
+	all := func(string, string) bool { return true }
+	ids := func(ps []PeerInfo) string {
+		var s []string
+		for _, p := range ps {
+			s = append(s, p.ID)
+		}
+		return strings.Join(s, ",")
+	}
+
+	tests := []struct {
+		name string
+		opts PeerListOptions
+		want string
+	}{
+		{"default hides DHT peers, sorts by ID", PeerListOptions{}, "a,b,c,e"},
+		{"all", PeerListOptions{All: true}, "a,b,c,d,e"},
+		{"watched", PeerListOptions{Watched: true}, "b,c,e"},
+		{"direct", PeerListOptions{Path: PeerPathDirect}, "a"},
+		{"relayed", PeerListOptions{Path: PeerPathRelayed}, "c"},
+		{"direct all", PeerListOptions{All: true, Path: PeerPathDirect}, "a,d"},
+		{"latency", PeerListOptions{All: true, Sort: PeerSortLatency}, "d,a,c,b,e"},
+		{"lastseen", PeerListOptions{Watched: true, Sort: PeerSortLastSeen}, "c,b,e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(selectPeers(peers, tt.opts, all)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlePeerList_InvalidOptions(t *testing.T) {
+	srv, _ := newNetworkServer(t)
+	for _, q := range []string{"path=sideways", "sort=name"} {
+		req := httptest.NewRequest("GET", "/v1/peers?"+q, nil)
+		rec := httptest.NewRecorder()
+		srv.handlePeerList(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
 // --- handleAuthRemove additional error paths ---
 
 func TestHandleAuthRemove_EmptyPeerID(t *testing.T) {
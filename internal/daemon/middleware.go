@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"compress/gzip"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,6 +21,12 @@ func (sr *statusRecorder) WriteHeader(code int) {
 	sr.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap exposes the underlying writer so http.ResponseController can
+// flush and adjust deadlines on streaming responses.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
 // InstrumentHandler wraps an HTTP handler with Prometheus metrics and audit logging.
 // If both metrics and audit are nil, the handler is returned unchanged (zero overhead).
 func InstrumentHandler(next http.Handler, metrics *sdk.Metrics, audit *sdk.AuditLogger) http.Handler {
@@ -69,3 +76,117 @@ func sanitizePath(path string) string {
 	}
 	return path
 }
+
+// gzipMinSize is the smallest response worth compressing. Most daemon
+// replies are a few hundred bytes, where gzip framing costs more than it saves.
+const gzipMinSize = 1024
+
+// uncompressedPaths are streaming endpoints. Compression would hold output
+// in the gzip buffer between flushes, so they always go out as-is.
+var uncompressedPaths = map[string]bool{
+	"/v1/events": true,
+}
+
+// GzipHandler compresses responses for clients that send
+// Accept-Encoding: gzip. Responses shorter than gzipMinSize, streaming
+// endpoints and protocol upgrades are passed through unchanged.
+func GzipHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if uncompressedPaths[r.URL.Path] || r.Header.Get("Upgrade") != "" || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.finish()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		enc, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(enc, "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the start of a response and only switches to
+// gzip once it has grown past gzipMinSize. The status code is held back
+// until then, since Content-Encoding must be set before the header is sent.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	buf         []byte
+	gz          *gzip.Writer
+	passthrough bool // header sent uncompressed; write straight through
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	g.status = code
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	switch {
+	case g.gz != nil:
+		return g.gz.Write(p)
+	case g.passthrough:
+		return g.ResponseWriter.Write(p)
+	}
+	g.buf = append(g.buf, p...)
+	if len(g.buf) >= gzipMinSize {
+		if err := g.startGzip(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (g *gzipResponseWriter) startGzip() error {
+	h := g.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	g.ResponseWriter.WriteHeader(g.status)
+	g.gz = gzip.NewWriter(g.ResponseWriter)
+	_, err := g.gz.Write(g.buf)
+	g.buf = nil
+	return err
+}
+
+// startPassthrough sends the header and anything buffered uncompressed.
+func (g *gzipResponseWriter) startPassthrough() {
+	g.passthrough = true
+	g.ResponseWriter.WriteHeader(g.status)
+	if len(g.buf) > 0 {
+		g.ResponseWriter.Write(g.buf)
+		g.buf = nil
+	}
+}
+
+// Flush lets a handler push partial output. Before compression has started
+// the response falls back to uncompressed, so nothing is held back.
+func (g *gzipResponseWriter) Flush() {
+	switch {
+	case g.gz != nil:
+		g.gz.Flush()
+	case !g.passthrough:
+		g.startPassthrough()
+	}
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer for deadline control.
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+func (g *gzipResponseWriter) finish() {
+	switch {
+	case g.gz != nil:
+		g.gz.Close()
+	case !g.passthrough:
+		g.startPassthrough()
+	}
+}
@@ -1,9 +1,13 @@
 package daemon
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
+	"strings"
 	"testing"
 
 	dto "github.com/prometheus/client_model/go"
@@ -261,3 +265,103 @@ func labelsMatch(pairs []*dto.LabelPair, expected map[string]string) bool {
 	}
 	return true
 }
+
+// encodingRecorder records the Content-Encoding of each response the
+// client receives, before the client decompresses it.
+type encodingRecorder struct {
+	next      http.RoundTripper
+	encodings []string
+}
+
+func (e *encodingRecorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := e.next.RoundTrip(r)
+	if err == nil {
+		e.encodings = append(e.encodings, resp.Header.Get("Content-Encoding"))
+	}
+	return resp, err
+}
+
+func TestGzipHandler_ClientRoundTrip(t *testing.T) {
+	peers := make([]PeerInfo, 500)
+	for i := range peers {
+		peers[i] = PeerInfo{ID: fmt.Sprintf("12D3KooWpeer%04d", i), AgentVersion: "shurli/test"}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/peers", func(w http.ResponseWriter, r *http.Request) {
+		RespondJSON(w, http.StatusOK, peers)
+	})
+	mux.HandleFunc("GET /v1/status", func(w http.ResponseWriter, r *http.Request) {
+		RespondJSON(w, http.StatusOK, map[string]string{"version": "test"})
+	})
+	ts := httptest.NewServer(GzipHandler(mux))
+	defer ts.Close()
+
+	rec := &encodingRecorder{next: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", ts.Listener.Addr().String())
+		},
+	}}
+	c := &Client{authToken: "test", httpClient: &http.Client{Transport: rec}}
+
+	var got []PeerInfo
+	if err := c.doJSON("GET", "/v1/peers", nil, &got); err != nil {
+		t.Fatalf("doJSON: %v", err)
+	}
+	if len(got) != len(peers) || got[499].ID != peers[499].ID {
+		t.Fatalf("decoded %d peers, want %d", len(got), len(peers))
+	}
+	if rec.encodings[0] != "gzip" {
+		t.Errorf("large response Content-Encoding = %q, want gzip", rec.encodings[0])
+	}
+
+	var status map[string]string
+	if err := c.doJSON("GET", "/v1/status", nil, &status); err != nil {
+		t.Fatalf("doJSON small: %v", err)
+	}
+	if rec.encodings[1] != "" {
+		t.Errorf("small response Content-Encoding = %q, want none", rec.encodings[1])
+	}
+}
+
+func TestGzipHandler_SkipsStreamsAndPlainClients(t *testing.T) {
+	big := strings.Repeat("x", 4*gzipMinSize)
+	h := GzipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(big))
+	}))
+
+	for _, tc := range []struct {
+		path, accept, want string
+	}{
+		{"/v1/peers", "gzip", "gzip"},
+		{"/v1/peers", "deflate, gzip;q=0.5", "gzip"},
+		{"/v1/peers", "", ""},
+		{"/v1/events", "gzip", ""},
+	} {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept-Encoding", tc.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if got := w.Header().Get("Content-Encoding"); got != tc.want {
+			t.Errorf("%s with Accept-Encoding %q: Content-Encoding = %q, want %q", tc.path, tc.accept, got, tc.want)
+		}
+		if tc.want == "" && w.Body.String() != big {
+			t.Errorf("%s: uncompressed body altered", tc.path)
+		}
+	}
+}
+
+func TestGzipHandler_PreservesStatus(t *testing.T) {
+	h := GzipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RespondError(w, http.StatusNotFound, "not found")
+	}))
+	req := httptest.NewRequest("GET", "/v1/auth/x", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,22 @@
+package daemon
+
+import "path/filepath"
+
+// SocketPath returns the daemon API socket in configDir for profile:
+// shurli.sock for the default profile, <profile>.sock otherwise, so daemons
+// for different profiles sharing a config directory never collide.
+func SocketPath(configDir, profile string) string {
+	if profile == "" {
+		return filepath.Join(configDir, "shurli.sock")
+	}
+	return filepath.Join(configDir, profile+".sock")
+}
+
+// CookiePath returns the daemon auth cookie in configDir for profile:
+// .daemon-cookie for the default profile, .<profile>-daemon-cookie otherwise.
+func CookiePath(configDir, profile string) string {
+	if profile == "" {
+		return filepath.Join(configDir, ".daemon-cookie")
+	}
+	return filepath.Join(configDir, "."+profile+"-daemon-cookie")
+}
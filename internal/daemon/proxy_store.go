@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"sync"
+
+	"github.com/shurlinet/shurli/internal/config"
 )
 
 // proxyNameRe validates persistent proxy names: must start with a letter,
@@ -239,7 +241,8 @@ func isSymlinkError(err error) bool {
 }
 
 
-// ProxiesFilePath returns the path to proxies.json given a config directory.
+// ProxiesFilePath returns the path to proxies.json given a config directory,
+// namespaced for the active profile (see config.ProfileFileName).
 func ProxiesFilePath(configDir string) string {
-	return filepath.Join(configDir, "proxies.json")
+	return filepath.Join(configDir, config.ProfileFileName("proxies.json"))
 }
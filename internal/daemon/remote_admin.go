@@ -0,0 +1,263 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// AdminProtocol is the libp2p protocol that tunnels the daemon control API
+// to the peers listed in security.admin_peers, so a node can be managed
+// from another of the operator's nodes ("shurli remote") without SSH.
+const AdminProtocol = "/shurli/admin/1.0.0"
+
+// Wire format (one request per stream, stateless):
+//   Request:  [4-byte BE frame length] [JSON: AdminRequest]
+//   Response: [4-byte BE frame length] [JSON: AdminResponse]
+//
+// Same framing as the relay's /shurli/relay-admin/1.0.0.
+
+const (
+	maxAdminRequestFrame  = 64 * 1024
+	maxAdminResponseFrame = 10 << 20 // matches the local client's response cap
+	adminStreamTimeout    = 2 * time.Minute
+)
+
+// AdminRequest is the request frame of the admin protocol: one daemon API
+// call, exactly as it would be made on the local socket.
+type AdminRequest struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"` // may carry a query string
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   bool            `json:"text,omitempty"` // ask for the text/plain rendering
+}
+
+// AdminResponse is the response frame of the admin protocol.
+type AdminResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"` // raw HTTP body: JSON envelope or text
+}
+
+// remoteLocalOnlyPaths are API endpoints that stay local-only even for
+// admin peers, with the reason given back to the caller.
+var remoteLocalOnlyPaths = map[string]string{
+	"/v1/shutdown": "stopping the daemon would cut off the remote session; use the local CLI",
+	"/v1/lock":     "the sensitive-operations lock is toggled by the local operator only",
+	"/v1/unlock":   "the sensitive-operations lock is toggled by the local operator only",
+	"/v1/events":   "event streams cannot be tunnelled over the admin protocol",
+	"/v1/invite":   "invites need an interactive local session",
+	"/v1/connect":  "proxies listen on the remote node's loopback, unreachable from here",
+	"/v1/remote":   "remote requests cannot be chained through another node",
+}
+
+// remoteLocalOnlyReason returns why path cannot be used remotely, or ""
+// when it can. A listed path also covers its sub-paths.
+func remoteLocalOnlyReason(path string) string {
+	for p, reason := range remoteLocalOnlyPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return reason
+		}
+	}
+	return ""
+}
+
+// RemoteAdminHandler serves AdminProtocol streams by dispatching each
+// request to the daemon's API handlers. Only admin peers are served; every
+// request, allowed or not, is logged and written to the audit log.
+type RemoteAdminHandler struct {
+	server *Server
+	admins map[peer.ID]bool
+}
+
+// NewRemoteAdminHandler creates a handler serving the given admin peers.
+// Changes to security.admin_peers take effect on daemon restart.
+func NewRemoteAdminHandler(server *Server, admins []peer.ID) *RemoteAdminHandler {
+	h := &RemoteAdminHandler{server: server, admins: make(map[peer.ID]bool, len(admins))}
+	for _, p := range admins {
+		h.admins[p] = true
+	}
+	return h
+}
+
+// HandleStream processes one admin request.
+// Flow: verify admin -> read request -> check path -> dispatch -> write response -> close.
+func (h *RemoteAdminHandler) HandleStream(s network.Stream) {
+	defer s.Close()
+	remotePeer := s.Conn().RemotePeer()
+	short := remotePeer.String()
+	if len(short) > 16 {
+		short = short[:16] + "..."
+	}
+	s.SetDeadline(time.Now().Add(adminStreamTimeout))
+
+	req, err := readAdminFrame[AdminRequest](s, maxAdminRequestFrame)
+	if err != nil {
+		slog.Warn("remote-admin: invalid request", "peer", short, "error", err)
+		writeAdminFrame(s, adminError(http.StatusBadRequest, "invalid request frame"))
+		return
+	}
+
+	reply := func(resp AdminResponse) {
+		h.server.audit.RemoteAdminAccess(remotePeer.String(), req.Method, req.Path, resp.Status)
+		writeAdminFrame(s, resp)
+	}
+
+	// Check the peer only after reading the frame so the denial is
+	// audited with what was attempted.
+	if !h.admins[remotePeer] {
+		slog.Warn("remote-admin: denied peer not in security.admin_peers",
+			"peer", short, "method", req.Method, "path", req.Path)
+		reply(adminError(http.StatusForbidden, "permission denied: not an admin peer of this node"))
+		return
+	}
+
+	u, err := url.Parse(req.Path)
+	if req.Method == "" || err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/v1/") {
+		slog.Warn("remote-admin: malformed request", "peer", short, "method", req.Method, "path", req.Path)
+		reply(adminError(http.StatusBadRequest, "method and a /v1/ path are required"))
+		return
+	}
+	if reason := remoteLocalOnlyReason(u.Path); reason != "" {
+		slog.Warn("remote-admin: blocked local-only endpoint", "peer", short, "method", req.Method, "path", u.Path)
+		reply(adminError(http.StatusForbidden, "local-only endpoint: "+reason))
+		return
+	}
+
+	status, contentType, body := h.server.HandleRemoteRequest(req.Method, req.Path, req.Body, req.Text)
+	slog.Info("remote-admin: admin request", "peer", short, "method", req.Method, "path", req.Path, "status", status)
+	// Leave room for the frame's JSON and base64 overhead.
+	if len(body) > maxAdminResponseFrame/2 {
+		reply(adminError(http.StatusInternalServerError, "response too large"))
+		return
+	}
+	reply(AdminResponse{Status: status, ContentType: contentType, Body: body})
+}
+
+// HandleRemoteRequest runs one API request through the daemon's handlers
+// without the cookie check, for the admin protocol. The caller has already
+// authorized the peer. It returns the status, content type and body.
+func (s *Server) HandleRemoteRequest(method, path string, body []byte, text bool) (int, string, []byte) {
+	s.remoteMuxOnce.Do(func() {
+		mux := http.NewServeMux()
+		s.registerRoutes(mux)
+		s.remoteMux = mux
+	})
+
+	var reqBody io.Reader
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, "http://daemon"+path, reqBody)
+	if err != nil {
+		b, _ := json.Marshal(ErrorResponse{Error: "invalid request"})
+		return http.StatusBadRequest, "application/json", b
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if text {
+		req.Header.Set("Accept", "text/plain")
+	}
+
+	rec := httptest.NewRecorder()
+	s.remoteMux.ServeHTTP(rec, req)
+	return rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()
+}
+
+// RemoteAdminClient sends daemon API requests to another node over
+// AdminProtocol. The remote node must list this node's peer ID in its
+// security.admin_peers.
+type RemoteAdminClient struct {
+	host host.Host
+	peer peer.ID
+}
+
+// NewRemoteAdminClient creates a client for the daemon of target.
+func NewRemoteAdminClient(h host.Host, target peer.ID) *RemoteAdminClient {
+	return &RemoteAdminClient{host: h, peer: target}
+}
+
+// Do sends one request and returns the remote daemon's response.
+func (c *RemoteAdminClient) Do(ctx context.Context, req AdminRequest) (*AdminResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, adminStreamTimeout)
+	defer cancel()
+
+	s, err := c.host.NewStream(ctx, c.peer, protocol.ID(AdminProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open admin stream: %w", err)
+	}
+	defer s.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		s.SetDeadline(deadline)
+	}
+
+	if err := writeAdminFrame(s, req); err != nil {
+		s.Reset()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	s.CloseWrite()
+
+	resp, err := readAdminFrame[AdminResponse](s, maxAdminResponseFrame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &resp, nil
+}
+
+// adminError builds an error response in the daemon's JSON error format.
+func adminError(status int, msg string) AdminResponse {
+	b, _ := json.Marshal(ErrorResponse{Error: msg})
+	return AdminResponse{Status: status, ContentType: "application/json", Body: b}
+}
+
+// writeAdminFrame writes v as a length-prefixed JSON frame.
+func writeAdminFrame(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readAdminFrame reads one length-prefixed JSON frame of at most max bytes.
+func readAdminFrame[T any](r io.Reader, max uint32) (T, error) {
+	var v T
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return v, err
+	}
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if n == 0 || n > max {
+		return v, fmt.Errorf("invalid frame length %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return v, errors.New("invalid frame JSON")
+	}
+	return v, nil
+}
@@ -0,0 +1,185 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the audit logger to write from
+// stream handler goroutines while the test reads it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func connectTestNetworks(t *testing.T, from, to *sdk.Network) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := from.Host().Connect(ctx, peer.AddrInfo{ID: to.Host().ID(), Addrs: to.Host().Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+}
+
+// TestRemoteAdminEndToEnd drives a managed node's daemon from an admin
+// node's daemon over /shurli/admin/1.0.0, the path "shurli remote" takes.
+func TestRemoteAdminEndToEnd(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+	cookiePath := filepath.Join(dir, ".test-cookie")
+
+	netAdmin := newListeningTestNetwork(t)
+	netManaged := newListeningTestNetwork(t)
+	netOther := newListeningTestNetwork(t)
+	managedID := netManaged.Host().ID()
+
+	// Managed node: only netAdmin is an admin peer.
+	var auditLog lockedBuffer
+	managed := NewServer(&networkMockRuntime{net: netManaged, version: "managed-1.0", startTime: time.Now()},
+		filepath.Join(dir, "managed.sock"), filepath.Join(dir, ".managed-cookie"), "managed-1.0")
+	managed.SetInstrumentation(nil, sdk.NewAuditLogger(slog.NewJSONHandler(&auditLog, nil)))
+	handler := NewRemoteAdminHandler(managed, []peer.ID{netAdmin.Host().ID()})
+	netManaged.Host().SetStreamHandler(AdminProtocol, handler.HandleStream)
+
+	// Admin node: a running daemon the CLI talks to.
+	rt := &networkMockRuntime{net: netAdmin, version: "admin-1.0", startTime: time.Now()}
+	srv := NewServer(rt, socketPath, cookiePath, "admin-1.0")
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+	client, err := NewClient(socketPath, cookiePath)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	connectTestNetworks(t, netAdmin, netManaged)
+	connectTestNetworks(t, netAdmin, netOther)
+	connectTestNetworks(t, netOther, netManaged)
+
+	// JSON: the status is the managed node's, not the local one.
+	data, err := client.Remote(RemoteAdminRequest{Peer: managedID.String(), Method: "GET", Path: "/v1/status"})
+	if err != nil {
+		t.Fatalf("remote status: %v", err)
+	}
+	var status StatusResponse
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.PeerID != managedID.String() || status.Version != "managed-1.0" {
+		t.Errorf("remote status = peer %s version %s, want %s managed-1.0", status.PeerID, status.Version, managedID)
+	}
+
+	// Text rendering is passed through.
+	text, err := client.RemoteText(RemoteAdminRequest{Peer: managedID.String(), Method: "GET", Path: "/v1/status"})
+	if err != nil {
+		t.Fatalf("remote status text: %v", err)
+	}
+	if !strings.Contains(text, managedID.String()) || strings.HasPrefix(text, "{") {
+		t.Errorf("remote status text = %q", text)
+	}
+
+	// Local-only endpoints are refused and have no effect.
+	_, err = client.Remote(RemoteAdminRequest{Peer: managedID.String(), Method: "POST", Path: "/v1/shutdown"})
+	if err == nil || !strings.Contains(err.Error(), "local-only") {
+		t.Errorf("remote shutdown err = %v, want local-only refusal", err)
+	}
+	select {
+	case <-managed.ShutdownCh():
+		t.Fatal("remote shutdown request stopped the managed daemon")
+	default:
+	}
+
+	// A peer missing from admin_peers is refused, even though it is
+	// connected and would pass the connection gater.
+	resp, err := NewRemoteAdminClient(netOther.Host(), managedID).Do(context.Background(),
+		AdminRequest{Method: "GET", Path: "/v1/peers"})
+	if err != nil {
+		t.Fatalf("non-admin request: %v", err)
+	}
+	if resp.Status != http.StatusForbidden {
+		t.Errorf("non-admin status = %d, want 403 (body %s)", resp.Status, resp.Body)
+	}
+
+	// A node that does not serve the admin protocol gets a useful error.
+	_, err = client.Remote(RemoteAdminRequest{Peer: netOther.Host().ID().String(), Method: "GET", Path: "/v1/status"})
+	if err == nil || !strings.Contains(err.Error(), "admin_peers") {
+		t.Errorf("unconfigured node err = %v, want admin_peers hint", err)
+	}
+
+	// Every request was audited, allowed or not.
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(auditLog.String()), "\n") {
+		var e struct {
+			Msg   string         `json:"msg"`
+			Audit map[string]any `json:"audit"`
+		}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("audit line %q: %v", line, err)
+		}
+		if e.Msg == "remote_admin_access" {
+			entries = append(entries, e.Audit)
+		}
+	}
+	want := []struct {
+		peer   peer.ID
+		path   string
+		status float64
+	}{
+		{netAdmin.Host().ID(), "/v1/status", 200},
+		{netAdmin.Host().ID(), "/v1/status", 200},
+		{netAdmin.Host().ID(), "/v1/shutdown", 403},
+		{netOther.Host().ID(), "/v1/peers", 403},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("audit entries = %v, want %d", entries, len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e["peer"] != w.peer.String() || e["path"] != w.path || e["status"] != w.status {
+			t.Errorf("audit entry %d = %v, want peer %s path %s status %v", i, e, w.peer, w.path, w.status)
+		}
+	}
+}
+
+func TestRemoteLocalOnlyReason(t *testing.T) {
+	for path, blocked := range map[string]bool{
+		"/v1/status":          false,
+		"/v1/peers":           false,
+		"/v1/config/reload":   false,
+		"/v1/connections":     false, // prefix of /v1/connect, but a different path
+		"/v1/shutdown":        true,
+		"/v1/unlock":          true,
+		"/v1/invite/abc/wait": true,
+		"/v1/connect/proxy-1": true,
+		"/v1/remote":          true,
+	} {
+		if got := remoteLocalOnlyReason(path) != ""; got != blocked {
+			t.Errorf("remoteLocalOnlyReason(%q) blocked = %v, want %v", path, got, blocked)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
@@ -19,6 +20,8 @@ import (
 	"github.com/shurlinet/shurli/internal/grants"
 	"github.com/shurlinet/shurli/internal/notify"
 	"github.com/shurlinet/shurli/internal/platform"
+	"github.com/shurlinet/shurli/internal/reputation"
+	"github.com/shurlinet/shurli/internal/watchdog"
 	"github.com/shurlinet/shurli/pkg/sdk"
 	"github.com/shurlinet/shurli/pkg/plugin"
 )
@@ -39,6 +42,7 @@ type RuntimeInfo interface {
 	PathProtector() *sdk.PathProtector                   // nil before bootstrap (TS-5)
 	BandwidthTracker() *sdk.BandwidthTracker              // nil when disabled
 	RelayHealth() *sdk.RelayHealth                        // nil when disabled
+	RelaySelector() *sdk.RelaySelector                    // nil when relays are disabled
 	STUNResult() *sdk.STUNResult                          // nil before probe
 	IsRelaying() bool                                        // true if peer relay enabled
 	RelayAddresses() []string                                // relay multiaddrs from config
@@ -54,6 +58,11 @@ type RuntimeInfo interface {
 	NotifyRouter() *notify.Router                             // nil before initialization
 	PeerManager() *sdk.PeerManager                         // nil before initialization
 	GrantCacheSnapshot() []*grants.GrantReceipt               // nil if no grant cache
+	Messenger() *sdk.Messenger                               // nil before initialization
+	Advertiser() *sdk.RendezvousAdvertiser                   // nil before bootstrap
+	MDNSDiscovery() *sdk.MDNSDiscovery                       // nil when mDNS is disabled
+	HealthChecks() []watchdog.CheckStatus                    // nil before the watchdog starts
+	PeerHistory() *reputation.PeerHistory                    // nil before initialization
 }
 
 // GaterReloader allows hot-reloading the authorized peers list.
@@ -101,14 +110,25 @@ const proxyMaxQuickDeaths = 3
 // ProxyPortRetryInterval is how often we retry binding port_conflict proxies (EDGE-4).
 const ProxyPortRetryInterval = 30 * time.Second
 
-// activeProxy tracks a dynamically created TCP proxy (both ephemeral and persistent).
+// proxyListener is the local end of a proxy: an sdk.TCPListener, or an
+// sdk.UDPListener for ephemeral --proto udp proxies.
+type proxyListener interface {
+	Serve() error
+	Close() error
+	GracefulClose(timeout time.Duration)
+	Addr() net.Addr
+	ActiveConns() int
+}
+
+// activeProxy tracks a dynamically created proxy (both ephemeral and persistent).
 type activeProxy struct {
 	ID       string
 	Peer     string
 	Service  string
 	Listen   string
-	Port     int // intended port (always set for persistent, used when listener is nil)
-	listener *sdk.TCPListener
+	Proto    string // "tcp", "udp" or "stdio" (ephemeral only; persistent proxies are tcp)
+	Port     int    // intended port (always set for persistent, used when listener is nil)
+	listener proxyListener // nil for placeholders and stdio proxies
 	cancel   context.CancelFunc
 	done     chan struct{} // closed when the proxy goroutine exits
 
@@ -131,6 +151,7 @@ func newPlaceholderProxy(name, peerName, service, listen, status string, port in
 		Peer:       peerName,
 		Service:    service,
 		Listen:     listen,
+		Proto:      "tcp",
 		Port:       port,
 		persistent: true,
 		status:     status,
@@ -149,6 +170,11 @@ type Server struct {
 	authToken  string
 	version    string
 	shutdownCh chan struct{} // closed to signal shutdown to the daemon main loop
+	streamStop chan struct{} // closed when the HTTP server shuts down; ends event streams
+
+	shutdownOnce sync.Once
+	draining     atomic.Bool  // set once a drain has been requested; reported by /v1/health
+	drainTimeout atomic.Int64 // nanoseconds; 0 = stop immediately
 
 	// Optional plugin registry (nil if plugin system not initialized)
 	registry *plugin.Registry
@@ -162,12 +188,17 @@ type Server struct {
 	pendingInvite *activeInvite // nil when no invite active
 	nextID       int
 	locked       bool // sensitive ops disabled when true (default: true)
+	maxProxies   int  // cap on ephemeral (daemon connect) proxies; no cap until SetMaxProxies
 
 	// Persistent proxy store (nil until SetProxyStore called).
 	proxyStore *proxyStore
 
 	// Config reload self-healing state
 	reloadState ConfigReloadState
+
+	// Handler mux for admin protocol requests, built on first use.
+	remoteMuxOnce sync.Once
+	remoteMux     *http.ServeMux
 }
 
 // NewServer creates a new daemon API server.
@@ -178,6 +209,7 @@ func NewServer(runtime RuntimeInfo, socketPath, cookiePath, version string) *Ser
 		cookiePath: cookiePath,
 		version:    version,
 		shutdownCh: make(chan struct{}),
+		streamStop: make(chan struct{}),
 		proxies:    make(map[string]*activeProxy),
 		locked:     true, // sensitive ops locked by default
 	}
@@ -202,6 +234,93 @@ func (s *Server) ShutdownCh() <-chan struct{} {
 	return s.shutdownCh
 }
 
+// Bounds for a draining shutdown (POST /v1/shutdown with drain set).
+const (
+	DefaultDrainTimeout = 30 * time.Second
+	MaxDrainTimeout     = time.Hour
+)
+
+// DrainTimeout returns how long the daemon should wait for open proxy
+// connections before stopping, or 0 when an immediate stop was requested.
+func (s *Server) DrainTimeout() time.Duration {
+	return time.Duration(s.drainTimeout.Load())
+}
+
+// Draining reports whether a draining shutdown is in progress.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// requestShutdown signals the daemon main loop. Safe to call repeatedly;
+// only the first call's drain setting counts.
+func (s *Server) requestShutdown(drain time.Duration) {
+	s.shutdownOnce.Do(func() {
+		if drain > 0 {
+			s.drainTimeout.Store(int64(drain))
+			s.draining.Store(true)
+		}
+		go func() {
+			time.Sleep(100 * time.Millisecond) // let response flush
+			close(s.shutdownCh)
+		}()
+	})
+}
+
+// Drain stops accepting new proxy connections and inbound service streams,
+// then waits until the open ones finish, timeout passes or ctx is done.
+// Existing connections are left running either way; Stop closes them.
+// UDP proxies carry no in-flight state and keep running until Stop.
+func (s *Server) Drain(ctx context.Context, timeout time.Duration) {
+	s.draining.Store(true)
+
+	var reg *sdk.ServiceRegistry
+	if p2p := s.runtime.Network(); p2p != nil {
+		reg = p2p.ServiceRegistry()
+		reg.SetDraining(true)
+	}
+
+	s.mu.Lock()
+	var listeners []proxyListener
+	for _, proxy := range s.proxies {
+		if proxy.listener != nil && proxy.Proto != "udp" {
+			proxy.listener.Close()
+			listeners = append(listeners, proxy.listener)
+		}
+	}
+	s.mu.Unlock()
+
+	active := func() int {
+		n := 0
+		for _, l := range listeners {
+			n += l.ActiveConns()
+		}
+		if reg != nil {
+			n += reg.ActiveInbound()
+		}
+		return n
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		n := active()
+		if n == 0 {
+			slog.Info("drain complete")
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			slog.Warn("drain timed out, closing remaining connections", "active", n, "timeout", timeout)
+			return
+		case <-ctx.Done():
+			slog.Warn("drain interrupted", "active", n)
+			return
+		}
+	}
+}
+
 // Start creates the Unix socket, writes the cookie file, and starts serving.
 // It returns immediately - the server runs in a background goroutine.
 func (s *Server) Start() error {
@@ -243,10 +362,13 @@ func (s *Server) Start() error {
 	s.registerRoutes(mux)
 
 	s.httpServer = &http.Server{
-		Handler:      InstrumentHandler(s.authMiddleware(mux), s.metrics, s.audit),
+		Handler:      InstrumentHandler(s.authMiddleware(GzipHandler(mux)), s.metrics, s.audit),
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 60 * time.Second, // longer for streaming ping
 	}
+	// Long-lived event streams don't count as idle, so Shutdown would
+	// otherwise wait out its full timeout on them.
+	s.httpServer.RegisterOnShutdown(func() { close(s.streamStop) })
 
 	go func() {
 		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
@@ -258,6 +380,40 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// SetMaxProxies caps how many ephemeral proxies (POST /v1/connect) may be
+// active at once. The daemon passes control.max_proxies resolved with
+// MaxProxiesOrDefault, so an unset config value means 64, not unlimited.
+// Persistent proxies are not counted.
+func (s *Server) SetMaxProxies(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxProxies = n
+}
+
+// ephemeralProxyCountLocked returns the number of active ephemeral
+// proxies. Caller must hold s.mu.
+func (s *Server) ephemeralProxyCountLocked() int {
+	n := 0
+	for _, p := range s.proxies {
+		if !p.persistent {
+			n++
+		}
+	}
+	return n
+}
+
+// proxyLimitErrLocked returns a non-nil error when another ephemeral proxy
+// would exceed the limit. Caller must hold s.mu.
+func (s *Server) proxyLimitErrLocked() error {
+	if s.maxProxies <= 0 {
+		return nil
+	}
+	if n := s.ephemeralProxyCountLocked(); n >= s.maxProxies {
+		return fmt.Errorf("%w: %d of %d active; disconnect one with 'shurli daemon disconnect <id>' or raise control.max_proxies", ErrProxyLimit, n, s.maxProxies)
+	}
+	return nil
+}
+
 // SetProxyStore configures persistent proxy storage.
 // Must be called before RestoreProxies(). Nil-safe.
 func (s *Server) SetProxyStore(store *proxyStore) {
@@ -382,6 +538,7 @@ func (s *Server) startPersistentProxy(name, peerName, service, listenAddr string
 		Peer:       peerName,
 		Service:    service,
 		Listen:     listener.Addr().String(),
+		Proto:      "tcp",
 		Port:       port,
 		listener:   listener,
 		cancel:     cancel,
@@ -401,7 +558,9 @@ func (s *Server) startPersistentProxy(name, peerName, service, listenAddr string
 			select {
 			case <-ctx.Done():
 			default:
-				slog.Error("persistent proxy listener stopped", "name", name, "error", err)
+				if !s.Draining() {
+					slog.Error("persistent proxy listener stopped", "name", name, "error", err)
+				}
 			}
 		}
 	}()
@@ -410,9 +569,48 @@ func (s *Server) startPersistentProxy(name, peerName, service, listenAddr string
 	return proxy
 }
 
+// recordEvent adds a stream-only event to the notification history so
+// /v1/events subscribers see it. No-op without a notification router.
+func (s *Server) recordEvent(e notify.Event) {
+	if router := s.runtime.NotifyRouter(); router != nil {
+		router.Record(e)
+	}
+}
+
+// recordPeerEvent records a connectivity change for a watched peer. Other
+// peers (DHT neighbours, relays, probes) churn constantly and would push
+// everything else out of the bounded event history.
+func (s *Server) recordPeerEvent(typ notify.EventType, pid peer.ID, msg string) {
+	if pm := s.runtime.PeerManager(); pm == nil || !pm.IsWatched(pid) {
+		return
+	}
+	s.recordEvent(notify.NewEvent(typ, notify.SeverityInfo, pid.String(), "", msg))
+}
+
+// recordProxyEvent records a proxy lifecycle change. pid is the resolved
+// target peer, or empty when unknown; reason explains the transition.
+func (s *Server) recordProxyEvent(typ notify.EventType, proxy *activeProxy, pid peer.ID, reason string) {
+	var peerID string
+	if pid != "" {
+		peerID = pid.String()
+	}
+	msg := "proxy " + proxy.ID + " started"
+	if typ == notify.EventProxyStopped {
+		msg = "proxy " + proxy.ID + " stopped"
+	}
+	s.recordEvent(notify.NewEvent(typ, notify.SeverityInfo, peerID, "", msg).
+		WithMetadata("proxy", proxy.ID).
+		WithMetadata("peer", proxy.Peer).
+		WithMetadata("service", proxy.Service).
+		WithMetadata("listen", proxy.Listen).
+		WithMetadata("reason", reason))
+}
+
 // OnPeerConnected is called when a peer connects (via libp2p event bus subscription).
 // Flips persistent proxies targeting that peer from "waiting" to "active".
 func (s *Server) OnPeerConnected(pid peer.ID) {
+	s.recordPeerEvent(notify.EventPeerConnected, pid, "peer connected")
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -439,6 +637,7 @@ func (s *Server) OnPeerConnected(pid peer.ID) {
 					proxy.connectedAt = time.Now()
 					proxy.quickDeathCount = 0
 					slog.Info("proxy active", "name", proxy.ID, "peer", proxy.Peer)
+					s.recordProxyEvent(notify.EventProxyStarted, proxy, pid, "peer connected")
 				}
 			}
 		}
@@ -449,6 +648,8 @@ func (s *Server) OnPeerConnected(pid peer.ID) {
 // Flips persistent proxies targeting that peer from "active" to "waiting".
 // Applies GATETIME logic (NOVEL-2): rapid disconnects increment quickDeathCount.
 func (s *Server) OnPeerDisconnected(pid peer.ID) {
+	s.recordPeerEvent(notify.EventPeerDisconnected, pid, "peer disconnected")
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -468,11 +669,13 @@ func (s *Server) OnPeerDisconnected(pid peer.ID) {
 				if proxy.quickDeathCount >= proxyMaxQuickDeaths {
 					proxy.status = "error: peer connection unstable (3 rapid failures)"
 					slog.Warn("proxy error: rapid failures", "name", proxy.ID, "peer", proxy.Peer, "deaths", proxy.quickDeathCount)
+					s.recordProxyEvent(notify.EventProxyStopped, proxy, pid, "peer connection unstable")
 					continue
 				}
 			}
 			proxy.status = "waiting"
 			slog.Info("proxy waiting (peer disconnected)", "name", proxy.ID, "peer", proxy.Peer)
+			s.recordProxyEvent(notify.EventProxyStopped, proxy, pid, "peer disconnected")
 		}
 	}
 }
@@ -495,6 +698,7 @@ func (s *Server) OnPeerDeauthorized(pid peer.ID) {
 		if targetPeerID == pid {
 			proxy.status = "error: peer not authorized"
 			slog.Warn("proxy stopped: peer deauthorized", "name", proxy.ID, "peer", proxy.Peer)
+			s.recordProxyEvent(notify.EventProxyStopped, proxy, pid, "peer deauthorized")
 		}
 	}
 }
@@ -546,6 +750,7 @@ func (s *Server) DetectAlreadyConnected() {
 			proxy.status = "active"
 			proxy.connectedAt = time.Now()
 			slog.Info("proxy active (peer already connected)", "name", proxy.ID, "peer", proxy.Peer)
+			s.recordProxyEvent(notify.EventProxyStarted, proxy, targetPeerID, "peer already connected")
 		}
 	}
 }
@@ -594,12 +799,14 @@ func (s *Server) PollProxyStatus() {
 			proxy.connectedAt = time.Now()
 			proxy.quickDeathCount = 0
 			slog.Info("proxy active (poll detected connection)", "name", proxy.ID, "peer", proxy.Peer)
+			s.recordProxyEvent(notify.EventProxyStarted, proxy, targetPeerID, "peer connected")
 
 		case proxy.status == "active" && !connected:
 			// Missed disconnect event — correct status without GATETIME
 			// (this is stale detection, not a fresh disconnect).
 			proxy.status = "waiting"
 			slog.Info("proxy waiting (poll detected disconnection)", "name", proxy.ID, "peer", proxy.Peer)
+			s.recordProxyEvent(notify.EventProxyStopped, proxy, targetPeerID, "peer disconnected")
 		}
 	}
 }
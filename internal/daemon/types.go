@@ -1,6 +1,13 @@
 package daemon
 
-import "github.com/shurlinet/shurli/pkg/sdk"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/reputation"
+	"github.com/shurlinet/shurli/internal/watchdog"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
 
 // StatusResponse is returned by GET /v1/status.
 type StatusResponse struct {
@@ -13,11 +20,15 @@ type StatusResponse struct {
 	ServicesCount  int      `json:"services_count"`
 	HasGlobalIPv6     bool     `json:"has_global_ipv6"`
 	HasGlobalIPv4     bool     `json:"has_global_ipv4"`
+	IPMode            string   `json:"ip_mode"` // network.ip_mode: dual, ipv6 or ipv4
 	NATType           string   `json:"nat_type,omitempty"`
 	STUNExternalAddrs []string `json:"stun_external_addrs,omitempty"`
+	ObservedAddrs     []sdk.ObservedAddr `json:"observed_addresses,omitempty"` // our addresses as seen by peers (identify)
 	IsRelaying        bool     `json:"is_relaying"`
 	Reachability      *sdk.ReachabilityGrade `json:"reachability,omitempty"`
+	Traversal         *sdk.TraversalAssessment `json:"traversal,omitempty"` // STUN NAT type combined with hole punch outcomes
 	Relays            []RelayStatus  `json:"relays,omitempty"`
+	PrimaryRelay      string         `json:"primary_relay,omitempty"` // peer ID of the lowest-latency relay holding the main reservation
 	MOTDs             []MOTDInfo     `json:"motds,omitempty"`
 	ExpiringGrants    []GrantInfo    `json:"expiring_grants,omitempty"` // grants expiring within 10 minutes
 	RelayGrants       []RelayGrantInfo `json:"relay_grants,omitempty"`  // client-side cached relay grant receipts
@@ -26,6 +37,34 @@ type StatusResponse struct {
 	PluginStatus      map[string]map[string]any `json:"plugin_status,omitempty"`
 	PeerPaths         map[string]PeerPathSummary `json:"peer_paths,omitempty"` // peer ID -> connection summary
 	Proxies           []ProxyStatusInfo          `json:"proxies,omitempty"`
+	ActiveProxies     int                        `json:"active_proxies"`        // ephemeral (daemon connect) proxies
+	MaxProxies        int                        `json:"max_proxies,omitempty"` // control.max_proxies; 0 = unlimited
+	Advertise         *sdk.AdvertiseStatus       `json:"advertise,omitempty"`   // rendezvous provider record health
+	MDNSPeers         []MDNSPeerInfo             `json:"mdns_peers,omitempty"`  // authorized peers seen on the LAN via mDNS
+}
+
+// HealthResponse is returned by GET /v1/health. The status code is 200 when
+// OK is true and 503 otherwise.
+type HealthResponse struct {
+	OK             bool                   `json:"ok"`
+	Draining       bool                   `json:"draining,omitempty"` // stopping: finishing open connections, refusing new ones
+	UptimeSeconds  int                    `json:"uptime_seconds"`
+	PeerCount      int                    `json:"peer_count"`
+	RelayConnected bool                   `json:"relay_connected"`  // at least one relay reservation held
+	Checks         []watchdog.CheckStatus `json:"checks,omitempty"` // latest watchdog results
+}
+
+// PeerHistoryResponse is returned by GET /v1/peers/{id}/history.
+type PeerHistoryResponse struct {
+	Record  *reputation.PeerRecord  `json:"record"`
+	Last24h reputation.EventSummary `json:"last_24h"` // summary of Record.Events from the past day
+}
+
+// MDNSPeerInfo describes a peer recently discovered on the LAN via mDNS.
+type MDNSPeerInfo struct {
+	PeerID   string `json:"peer_id"`
+	Name     string `json:"name,omitempty"`
+	LastSeen string `json:"last_seen"` // RFC3339
 }
 
 // PeerPathSummary describes how a peer is connected (for status display).
@@ -37,12 +76,15 @@ type PeerPathSummary struct {
 
 // RelayStatus describes a configured relay's connection state.
 type RelayStatus struct {
-	Address      string `json:"address"`
-	PeerID       string `json:"peer_id"`
-	ShortID      string `json:"short_id"`
-	Connected    bool   `json:"connected"`
-	RelayName    string `json:"relay_name,omitempty"`
-	AgentVersion string `json:"agent_version,omitempty"`
+	Address      string  `json:"address"`
+	PeerID       string  `json:"peer_id"`
+	ShortID      string  `json:"short_id"`
+	Connected    bool    `json:"connected"`
+	RelayName    string  `json:"relay_name,omitempty"`
+	Region       string  `json:"region,omitempty"` // advertised via identify
+	AgentVersion string  `json:"agent_version,omitempty"`
+	Role         string  `json:"role,omitempty"`       // "primary", "backup", or "" (reservation not refreshed)
+	LatencyMs    float64 `json:"latency_ms,omitempty"` // smoothed ping RTT; 0 until measured or unreachable
 }
 
 // MOTDInfo describes a MOTD or goodbye message from a relay.
@@ -56,17 +98,55 @@ type MOTDInfo struct {
 
 // ServiceInfo is returned by GET /v1/services.
 type ServiceInfo struct {
-	Name         string `json:"name"`
-	Protocol     string `json:"protocol"`
-	LocalAddress string `json:"local_address"`
-	Enabled      bool   `json:"enabled"`
-}
-
-// PeerInfo is returned by GET /v1/peers.
+	Name             string  `json:"name"`
+	Protocol         string  `json:"protocol"`
+	LocalAddress     string  `json:"local_address"`
+	Enabled          bool    `json:"enabled"`
+	MaxBandwidthMbps float64 `json:"max_bandwidth_mbps,omitempty"` // 0 = unlimited
+	MaxConcurrent    int     `json:"max_concurrent,omitempty"`     // per peer; 0 = unlimited
+	RatePerMinute    int     `json:"rate_per_minute,omitempty"`    // per peer; 0 = unlimited
+}
+
+// PeerInfo is returned by GET /v1/peers. Watched peers (managed by the
+// PeerManager) are listed even while disconnected, with their reconnect
+// backoff state.
 type PeerInfo struct {
 	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
 	Addresses    []string `json:"addresses"`
 	AgentVersion string   `json:"agent_version,omitempty"`
+	Connected    bool     `json:"connected"`
+	Path         string   `json:"path,omitempty"`       // PeerPathDirect or PeerPathRelayed while connected
+	LatencyMs    float64  `json:"latency_ms,omitempty"` // 0 = not measured
+
+	Watched        bool   `json:"watched,omitempty"`
+	LastSeen       string `json:"last_seen,omitempty"` // RFC 3339, watched peers only
+	LastDialError  string `json:"last_dial_error,omitempty"`
+	ConsecFailures int    `json:"consec_failures,omitempty"`
+	BackoffUntil   string `json:"backoff_until,omitempty"` // RFC 3339
+}
+
+// Connection paths reported in PeerInfo.Path and accepted by
+// GET /v1/peers?path=.
+const (
+	PeerPathDirect  = "direct"
+	PeerPathRelayed = "relayed"
+)
+
+// Sort orders accepted by GET /v1/peers?sort=.
+const (
+	PeerSortPeerID   = "peerid"   // default
+	PeerSortLatency  = "latency"  // lowest first, unmeasured last
+	PeerSortLastSeen = "lastseen" // connected first, then most recently seen
+)
+
+// PeerListOptions selects and orders the peers returned by GET /v1/peers.
+type PeerListOptions struct {
+	All     bool   // include non-shurli peers (DHT neighbors)
+	Peer    string // only this peer (ID or name)
+	Watched bool   // only peers managed by the PeerManager
+	Path    string // PeerPathDirect or PeerPathRelayed; empty = any
+	Sort    string // PeerSort*; empty = PeerSortPeerID
 }
 
 // PathInfo is returned by GET /v1/paths. Mirrors sdk.PeerPathInfo JSON tags.
@@ -110,6 +190,13 @@ type PingResponse struct {
 	Stats   sdk.PingStats   `json:"stats"`
 }
 
+// BandwidthTestRequest is the body for POST /v1/bwtest.
+type BandwidthTestRequest struct {
+	Peer      string `json:"peer"`
+	Bytes     int64  `json:"bytes,omitempty"`     // per direction, default 100MB
+	Direction string `json:"direction,omitempty"` // up, down or both (default both)
+}
+
 // TraceRequest is the body for POST /v1/traceroute.
 type TraceRequest struct {
 	Peer string `json:"peer"`
@@ -131,21 +218,32 @@ type ResolveResponse struct {
 type ConnectRequest struct {
 	Peer    string `json:"peer"`
 	Service string `json:"service"`
-	Listen  string `json:"listen"`
+	Listen  string `json:"listen,omitempty"` // not used for stdio
+	Proto   string `json:"proto,omitempty"`  // "tcp" (default), "udp" or "stdio"
 }
 
+// StreamUpgradeProtocol is the Upgrade token for a stdio proxy: POST
+// /v1/connect with proto "stdio" answers 101 Switching Protocols and the
+// connection then carries the service stream.
+const StreamUpgradeProtocol = "shurli-stream"
+
+// ProxyIDHeader carries the ID of a stdio proxy in the 101 response.
+const ProxyIDHeader = "X-Shurli-Proxy-Id"
+
 // ConnectResponse is returned by POST /v1/connect.
 type ConnectResponse struct {
 	ID            string `json:"id"`
 	ListenAddress string `json:"listen_address"`
+	Proto         string `json:"proto,omitempty"`
 	PathType      string `json:"path_type,omitempty"`
 	Address       string `json:"address,omitempty"`
 }
 
 // ExposeRequest is the body for POST /v1/expose.
 type ExposeRequest struct {
-	Name         string `json:"name"`
-	LocalAddress string `json:"local_address"`
+	Name            string `json:"name"`
+	LocalAddress    string `json:"local_address"`
+	RequireVerified bool   `json:"require_verified,omitempty"` // only peers verified with 'shurli verify'
 }
 
 // BandwidthStats is returned by GET /v1/bandwidth.
@@ -165,6 +263,45 @@ type BandwidthPeer struct {
 	RateOut  float64 `json:"rate_out"`
 }
 
+// HolePunchResponse is returned by GET /v1/holepunch.
+type HolePunchResponse struct {
+	Summary sdk.HolePunchStats  `json:"summary"`
+	Peers   []HolePunchPeerInfo `json:"peers"` // most recent attempt first
+}
+
+// HolePunchPeerInfo is the DCUtR record for one peer, with its name if
+// known.
+type HolePunchPeerInfo struct {
+	sdk.HolePunchPeerStats
+	Name string `json:"name,omitempty"`
+}
+
+// InboundResponse is returned by GET /v1/inbound.
+type InboundResponse struct {
+	Streams []InboundStreamInfo `json:"streams"`
+}
+
+// InboundStreamInfo is one active inbound service stream: a remote peer
+// using a service this node exposes.
+type InboundStreamInfo struct {
+	Service         string    `json:"service"`
+	PeerID          string    `json:"peer_id"`
+	PeerName        string    `json:"peer_name,omitempty"`
+	Path            string    `json:"path"` // "direct" or "relayed"
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	BytesIn         int64     `json:"bytes_in"`   // received from the peer
+	BytesOut        int64     `json:"bytes_out"`  // sent to the peer
+	Authorized      bool      `json:"authorized"` // listed in authorized_keys
+	Verified        bool      `json:"verified"`   // SAS-verified ('shurli verify')
+}
+
+// StatsResetResponse is returned by POST /v1/stats/reset.
+type StatsResetResponse struct {
+	Reset   []string  `json:"reset"` // what was cleared: "bandwidth", "path_rtt"
+	ResetAt time.Time `json:"reset_at"`
+}
+
 // RelayHealthResponse is returned by GET /v1/relay-health.
 type RelayHealthResponse struct {
 	Relays []RelayHealthEntry `json:"relays"`
@@ -209,6 +346,15 @@ type RemoteServiceRequest struct {
 	Peer string `json:"peer"` // peer name or ID
 }
 
+// RemoteAdminRequest is sent to POST /v1/remote: one API call to run on
+// another node's daemon over the admin protocol.
+type RemoteAdminRequest struct {
+	Peer   string          `json:"peer"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
 // RemoteServiceResponse is returned by POST /v1/services/remote.
 type RemoteServiceResponse struct {
 	Services []sdk.RemoteServiceInfo `json:"services"`
@@ -352,6 +498,13 @@ type PouchListResponse struct {
 	Entries []PouchEntryInfo `json:"entries"`
 }
 
+// ShutdownRequest is the optional request body for POST /v1/shutdown. With
+// no body (or Drain false) the daemon stops immediately.
+type ShutdownRequest struct {
+	Drain   bool   `json:"drain,omitempty"`   // let open proxy connections finish first
+	Timeout string `json:"timeout,omitempty"` // upper bound on the drain (default: 30s)
+}
+
 // ReconnectRequest is the request body for POST /v1/reconnect.
 type ReconnectRequest struct {
 	Peer string `json:"peer"` // peer name or ID
@@ -386,3 +539,25 @@ type NotifySinkInfo struct {
 	Name   string `json:"name"`
 	Status string `json:"status"` // "active"
 }
+
+// MessageSendRequest is the request body for POST /v1/messages.
+type MessageSendRequest struct {
+	Peer string `json:"peer"` // peer name, ID, or multiaddr
+	Text string `json:"text"`
+}
+
+// MessageSendResponse is returned by POST /v1/messages.
+type MessageSendResponse struct {
+	Peer   string `json:"peer"`
+	PeerID string `json:"peer_id"`
+	Status string `json:"status"` // "delivered"
+}
+
+// MessageInfo is one message in the daemon's inbox.
+type MessageInfo struct {
+	ID       uint64    `json:"id"`
+	From     string    `json:"from"`                // sender peer ID
+	FromName string    `json:"from_name,omitempty"` // friendly name from config
+	Text     string    `json:"text"`
+	Received time.Time `json:"received"`
+}
@@ -0,0 +1,61 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// fingerprintDomain separates identity fingerprints from every other
+// SHA-256 of a peer ID (SAS codes, verified-attribute prefixes).
+const fingerprintDomain = "shurli/fingerprint/v1"
+
+// FingerprintWordCount is the number of BIP39 words in a fingerprint.
+// 6 words carry 66 bits: far beyond what an attacker can grind for a
+// matching key, while still short enough to read aloud.
+const FingerprintWordCount = 6
+
+// Fingerprint is a short, human-comparable digest of a peer's identity.
+// The peer ID embeds the Ed25519 public key, so the fingerprint commits to
+// the key itself and anyone who knows only the peer ID can recompute it.
+type Fingerprint struct {
+	Words []string // FingerprintWordCount BIP39 words, for reading aloud
+	Hex   string   // first 64 bits as grouped hex, e.g. "3f2a 91c0 7d4e b218"
+}
+
+// PeerFingerprint computes the fingerprint of a peer ID. It is
+// deterministic: the same identity always yields the same fingerprint.
+func PeerFingerprint(id peer.ID) Fingerprint {
+	h := sha256.New()
+	h.Write([]byte(fingerprintDomain))
+	h.Write([]byte(id))
+	sum := h.Sum(nil)
+
+	// Words: consecutive 11-bit groups from the start of the digest, the
+	// same encoding BIP39 uses for seed phrases.
+	words := make([]string, FingerprintWordCount)
+	for i := range words {
+		var idx int
+		for b := 0; b < 11; b++ {
+			bit := i*11 + b
+			if sum[bit/8]>>(7-bit%8)&1 == 1 {
+				idx |= 1 << (10 - b)
+			}
+		}
+		words[i] = Bip39Wordlist[idx]
+	}
+
+	groups := make([]string, 4)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("%02x%02x", sum[i*2], sum[i*2+1])
+	}
+
+	return Fingerprint{Words: words, Hex: strings.Join(groups, " ")}
+}
+
+// String returns the word form of the fingerprint.
+func (f Fingerprint) String() string {
+	return strings.Join(f.Words, " ")
+}
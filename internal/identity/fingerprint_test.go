@@ -0,0 +1,65 @@
+package identity
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestPeerFingerprintStable(t *testing.T) {
+	// Golden values: changing them breaks every fingerprint users have
+	// already compared or written down.
+	tests := []struct {
+		id    string
+		words string
+		hex   string
+	}{
+		{"12D3KooWSC3sb3uKwHJ8g6GkjqVK5pN2JFSasBknoT3ciVdFWf3q", "photo orchard barely special lift prevent", "a3b3 804a 6878 1754"},
+		{"12D3KooWPrmh163sTHW3mYQm7YsLsSR2wr71fPp4g6yjuGv3sGQt", "innocent hard crawl depart crucial east", "748d 24ca 9d63 468b"},
+	}
+	for _, tt := range tests {
+		id, err := peer.Decode(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fp := PeerFingerprint(id)
+		if fp.String() != tt.words {
+			t.Errorf("%s: words = %q, want %q", tt.id, fp.String(), tt.words)
+		}
+		if fp.Hex != tt.hex {
+			t.Errorf("%s: hex = %q, want %q", tt.id, fp.Hex, tt.hex)
+		}
+	}
+}
+
+func TestPeerFingerprintFromKey(t *testing.T) {
+	entropy := make([]byte, SeedEntropyLen)
+	entropy[0] = 1
+	key, err := DeriveIdentityKey(entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := PeerFingerprint(id), PeerFingerprint(id)
+	if a.String() != b.String() || a.Hex != b.Hex {
+		t.Fatalf("fingerprint not deterministic: %v vs %v", a, b)
+	}
+	if len(a.Words) != FingerprintWordCount {
+		t.Errorf("got %d words, want %d", len(a.Words), FingerprintWordCount)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{4}( [0-9a-f]{4}){3}$`).MatchString(a.Hex) {
+		t.Errorf("hex %q not in grouped form", a.Hex)
+	}
+
+	entropy[0] = 2
+	other, _ := DeriveIdentityKey(entropy)
+	otherID, _ := peer.IDFromPrivateKey(other)
+	if PeerFingerprint(otherID).String() == a.String() {
+		t.Error("different identities produced the same fingerprint")
+	}
+}
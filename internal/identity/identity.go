@@ -25,6 +25,25 @@ func CheckKeyFilePermissions(path string) error {
 	return nil
 }
 
+// FixKeyFilePermissions resets a key file to 0600 if it is readable by
+// group or others. It reports whether the mode was changed.
+func FixKeyFilePermissions(path string) (bool, error) {
+	if runtime.GOOS == "windows" {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("cannot stat key file %s: %w", path, err)
+	}
+	if info.Mode().Perm()&0077 == 0 {
+		return false, nil
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return false, fmt.Errorf("chmod key file %s: %w", path, err)
+	}
+	return true, nil
+}
+
 // LoadIdentity loads an encrypted identity key from disk.
 // The file MUST be in SHRL format. Raw (unencrypted) keys are rejected.
 func LoadIdentity(path, password string) (crypto.PrivKey, error) {
@@ -139,6 +139,36 @@ func TestCheckKeyFilePermissions(t *testing.T) {
 	}
 }
 
+func TestFixKeyFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions not applicable on Windows")
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "test.key")
+	LoadOrCreateIdentity(keyPath, testPassword)
+
+	if fixed, err := FixKeyFilePermissions(keyPath); fixed || err != nil {
+		t.Errorf("0600: fix = %v, %v; want no change", fixed, err)
+	}
+
+	// World-readable key is flagged, then restored to 0600.
+	os.Chmod(keyPath, 0644)
+	if err := CheckKeyFilePermissions(keyPath); err == nil {
+		t.Fatal("0644 should fail")
+	}
+	fixed, err := FixKeyFilePermissions(keyPath)
+	if err != nil || !fixed {
+		t.Fatalf("fix = %v, %v; want fixed", fixed, err)
+	}
+	info, _ := os.Stat(keyPath)
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("mode after fix = %04o, want 0600", got)
+	}
+	if _, err := LoadIdentity(keyPath, testPassword); err != nil {
+		t.Errorf("LoadIdentity after fix: %v", err)
+	}
+}
+
 func TestPeerIDFromKeyFile(t *testing.T) {
 	dir := t.TempDir()
 	keyPath := filepath.Join(dir, "test.key")
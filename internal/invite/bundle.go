@@ -0,0 +1,150 @@
+package invite
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/validate"
+)
+
+// BundleVersion is the bootstrap bundle format written by EncodeBundle.
+const BundleVersion = 1
+
+// BundlePrefix marks a bootstrap bundle string. The payload after the
+// prefix is base64url-encoded JSON.
+const BundlePrefix = "shurli-bundle:"
+
+// BundleParam is the URL query parameter (or fragment key) that carries a
+// bundle, e.g. https://example.com/join#bundle=shurli-bundle:eyJ2Ijo...
+const BundleParam = "bundle"
+
+// MaxBundleSize caps the encoded size of a bundle. A bundle with a handful
+// of relays is well under 2 KB.
+const MaxBundleSize = 8 << 10
+
+// ErrInvalidBundle is returned for a bundle that cannot be decoded or fails
+// validation.
+var ErrInvalidBundle = errors.New("invalid bootstrap bundle")
+
+// ErrUnsupportedBundleVersion is returned for a bundle written by a newer
+// (or unknown) format version.
+var ErrUnsupportedBundleVersion = errors.New("unsupported bootstrap bundle version")
+
+// Bundle is everything a fresh device needs to join a network in one
+// step: the relays to reserve on, the DHT namespace, and optionally an
+// invite code to pair with once the config is written.
+type Bundle struct {
+	Version int      `json:"v"`
+	Relays  []string `json:"relays"`
+	Network string   `json:"network,omitempty"` // empty = global network
+	Invite  string   `json:"invite,omitempty"`
+}
+
+// Validate checks the bundle version, that every relay is a full multiaddr
+// with a /p2p/ peer ID, that the namespace is DNS-label safe, and that the
+// invite code (if any) decodes.
+func (b *Bundle) Validate() error {
+	if b.Version != BundleVersion {
+		return fmt.Errorf("%w: %d (this build reads version %d)", ErrUnsupportedBundleVersion, b.Version, BundleVersion)
+	}
+	if len(b.Relays) == 0 {
+		return fmt.Errorf("%w: no relay addresses", ErrInvalidBundle)
+	}
+	for _, r := range b.Relays {
+		if _, err := peer.AddrInfoFromString(r); err != nil {
+			return fmt.Errorf("%w: relay %q: %v", ErrInvalidBundle, r, err)
+		}
+	}
+	if b.Network != "" {
+		if err := validate.NetworkName(b.Network); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+	}
+	if b.Invite != "" {
+		if _, err := Decode(b.Invite); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+	}
+	return nil
+}
+
+// EncodeBundle validates b and returns it as a "shurli-bundle:" string.
+// A zero Version is filled in with BundleVersion.
+func EncodeBundle(b Bundle) (string, error) {
+	if b.Version == 0 {
+		b.Version = BundleVersion
+	}
+	if err := b.Validate(); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	return BundlePrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeBundle parses and validates a bundle. s may be a bare
+// "shurli-bundle:" string or a URL carrying one in the "bundle" query
+// parameter or fragment. The URL is only parsed, never fetched, so the
+// bundle (and its invite code) stays on the command line.
+func DecodeBundle(s string) (*Bundle, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > MaxBundleSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidBundle, MaxBundleSize)
+	}
+	if !strings.HasPrefix(s, BundlePrefix) {
+		extracted, err := bundleFromURL(s)
+		if err != nil {
+			return nil, err
+		}
+		s = extracted
+	}
+
+	payload := strings.TrimRight(strings.TrimPrefix(s, BundlePrefix), "=")
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad encoding: %v", ErrInvalidBundle, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var b Bundle
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// bundleFromURL extracts the bundle from a URL's fragment or query.
+// The fragment is checked first since browsers never send it to a server.
+func bundleFromURL(s string) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return "", fmt.Errorf("%w: expected %q string or a URL carrying one", ErrInvalidBundle, BundlePrefix)
+	}
+	if u.Fragment != "" {
+		if strings.HasPrefix(u.Fragment, BundlePrefix) {
+			return u.Fragment, nil
+		}
+		if q, err := url.ParseQuery(u.Fragment); err == nil {
+			if v := q.Get(BundleParam); strings.HasPrefix(v, BundlePrefix) {
+				return v, nil
+			}
+		}
+	}
+	if v := u.Query().Get(BundleParam); strings.HasPrefix(v, BundlePrefix) {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: URL has no %q parameter", ErrInvalidBundle, BundleParam)
+}
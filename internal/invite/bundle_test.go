@@ -0,0 +1,117 @@
+package invite
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRelay = "/ip4/203.0.113.10/tcp/7777/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"
+
+func testInviteCode(t *testing.T) string {
+	t.Helper()
+	token, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Encode(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Bundle
+	}{
+		{"relay only", Bundle{Relays: []string{testRelay}}},
+		{"namespace", Bundle{Relays: []string{testRelay}, Network: "my-crew"}},
+		{"invite", Bundle{Relays: []string{testRelay, "/dns4/relay.example.com/udp/7777/quic-v1/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"}, Network: "my-crew", Invite: testInviteCode(t)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := EncodeBundle(tt.b)
+			if err != nil {
+				t.Fatalf("EncodeBundle: %v", err)
+			}
+			if !strings.HasPrefix(s, BundlePrefix) {
+				t.Fatalf("bundle %q missing prefix", s)
+			}
+
+			want := tt.b
+			want.Version = BundleVersion
+			for _, in := range []string{
+				s,
+				"  " + s + "\n",
+				"https://example.com/join#" + s,
+				"https://example.com/join#bundle=" + s,
+				"https://example.com/join?bundle=" + s,
+			} {
+				got, err := DecodeBundle(in)
+				if err != nil {
+					t.Fatalf("DecodeBundle(%q): %v", in, err)
+				}
+				if !reflect.DeepEqual(*got, want) {
+					t.Errorf("DecodeBundle(%q) = %+v, want %+v", in, *got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeBundleRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Bundle
+	}{
+		{"no relays", Bundle{}},
+		{"relay without peer ID", Bundle{Relays: []string{"/ip4/203.0.113.10/tcp/7777"}}},
+		{"bad namespace", Bundle{Relays: []string{testRelay}, Network: "My Crew"}},
+		{"bad invite", Bundle{Relays: []string{testRelay}, Invite: "not-a-code"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeBundle(tt.b); !errors.Is(err, ErrInvalidBundle) {
+				t.Errorf("err = %v, want ErrInvalidBundle", err)
+			}
+		})
+	}
+}
+
+func TestDecodeBundleRejects(t *testing.T) {
+	good, err := EncodeBundle(Bundle{Relays: []string{testRelay}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+	}{
+		{"empty", "", ErrInvalidBundle},
+		{"not a bundle", "KXMT-9FWR-PBLZ-4YAN", ErrInvalidBundle},
+		{"bad base64", BundlePrefix + "!!!", ErrInvalidBundle},
+		{"not json", BundlePrefix + "bm90IGpzb24", ErrInvalidBundle},
+		{"unknown field", encodeRaw(`{"v":1,"relays":["` + testRelay + `"],"extra":true}`), ErrInvalidBundle},
+		{"future version", encodeRaw(`{"v":2,"relays":["` + testRelay + `"]}`), ErrUnsupportedBundleVersion},
+		{"missing version", encodeRaw(`{"relays":["` + testRelay + `"]}`), ErrUnsupportedBundleVersion},
+		{"url without bundle", "https://example.com/join?code=abc", ErrInvalidBundle},
+		{"oversized", good + strings.Repeat("A", MaxBundleSize), ErrInvalidBundle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeBundle(tt.in); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func encodeRaw(js string) string {
+	return BundlePrefix + base64.RawURLEncoding.EncodeToString([]byte(js))
+}
@@ -20,7 +20,18 @@ const (
 	EventGrantExtended  EventType = "grant_extended"
 	EventGrantRefreshed   EventType = "grant_refreshed"
 	EventGrantRateLimited EventType = "grant_rate_limited"
+	EventIdentityConflict EventType = "identity_conflict"
+	EventNodeIsolated     EventType = "node_isolated"
+	EventNodeRecovered    EventType = "node_recovered"
 	EventTest             EventType = "test"
+
+	// Stream-only events. These are recorded with Router.Record and reach
+	// /v1/events subscribers, but are never sent to sinks.
+	EventPeerConnected    EventType = "peer_connected"
+	EventPeerDisconnected EventType = "peer_disconnected"
+	EventAuthDecision     EventType = "auth_decision"
+	EventProxyStarted     EventType = "proxy_started"
+	EventProxyStopped     EventType = "proxy_stopped"
 )
 
 // Severity indicates the urgency of an event.
@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+const (
+	// DefaultHistorySize bounds how many recent events the router keeps
+	// for replay (`shurli daemon events --since`). Oldest events are
+	// evicted first once the buffer is full.
+	DefaultHistorySize = 512
+
+	// DefaultHistoryRetention is the maximum age of a replayable event.
+	// Events older than this are never replayed, even if the buffer has room.
+	DefaultHistoryRetention = 24 * time.Hour
+
+	// subscriberBuffer is the per-subscriber channel depth. A subscriber
+	// that falls further behind than this misses events rather than
+	// blocking Emit.
+	subscriberBuffer = 64
+)
+
+// History is a bounded ring buffer of recently emitted events with
+// support for live subscribers. Safe for concurrent use.
+type History struct {
+	mu        sync.Mutex
+	buf       []Event
+	start     int // index of the oldest event in buf
+	n         int // number of events currently stored
+	retention time.Duration
+	clock     clock.Clock
+
+	subs   map[int]chan Event
+	nextID int
+}
+
+// NewHistory creates a History holding at most size events no older than
+// retention. Zero or negative values select the defaults.
+func NewHistory(size int, retention time.Duration) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	if retention <= 0 {
+		retention = DefaultHistoryRetention
+	}
+	return &History{
+		buf:       make([]Event, size),
+		retention: retention,
+		clock:     clock.Real,
+		subs:      make(map[int]chan Event),
+	}
+}
+
+// Add records an event and forwards it to live subscribers. Subscribers
+// whose buffer is full drop the event instead of blocking the caller.
+func (h *History) Add(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.n < len(h.buf) {
+		h.buf[(h.start+h.n)%len(h.buf)] = event
+		h.n++
+	} else {
+		h.buf[h.start] = event
+		h.start = (h.start + 1) % len(h.buf)
+	}
+
+	for _, ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Since returns buffered events with a timestamp at or after t, oldest
+// first. Events beyond the retention window are excluded.
+func (h *History) Since(t time.Time) []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sinceLocked(t)
+}
+
+// Subscribe returns the buffered events since t (oldest first) and a
+// channel of live events emitted afterwards. Replay and subscription are
+// taken atomically, so no event is missed or delivered twice between them.
+// A zero t skips replay. Call cancel to release the subscription.
+func (h *History) Subscribe(since time.Time) (replay []Event, live <-chan Event, cancel func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !since.IsZero() {
+		replay = h.sinceLocked(since)
+	}
+
+	id := h.nextID
+	h.nextID++
+	ch := make(chan Event, subscriberBuffer)
+	h.subs[id] = ch
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+		})
+	}
+	return replay, ch, cancel
+}
+
+// Subscribers reports the number of live subscriptions.
+func (h *History) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+func (h *History) sinceLocked(t time.Time) []Event {
+	if cutoff := h.clock.Now().Add(-h.retention); t.Before(cutoff) {
+		t = cutoff
+	}
+	var out []Event
+	for i := 0; i < h.n; i++ {
+		e := h.buf[(h.start+i)%len(h.buf)]
+		if !e.Timestamp.Before(t) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+func historyEvent(id string, ts time.Time) Event {
+	return Event{ID: id, Type: EventTest, Severity: SeverityInfo, Timestamp: ts}
+}
+
+func TestHistory_EvictsOldestWhenFull(t *testing.T) {
+	h := NewHistory(3, time.Hour)
+	base := time.Now()
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		h.Add(historyEvent(id, base.Add(time.Duration(i)*time.Second)))
+	}
+
+	got := h.Since(base.Add(-time.Minute))
+	if len(got) != 3 {
+		t.Fatalf("got %d events, want 3", len(got))
+	}
+	for i, want := range []string{"c", "d", "e"} {
+		if got[i].ID != want {
+			t.Errorf("event %d = %q, want %q", i, got[i].ID, want)
+		}
+	}
+}
+
+func TestHistory_SinceFiltersByTimeAndRetention(t *testing.T) {
+	now := time.Now()
+	h := NewHistory(10, 30*time.Minute)
+	h.clock = clock.NewFake(now)
+
+	h.Add(historyEvent("stale", now.Add(-time.Hour)))
+	h.Add(historyEvent("old", now.Add(-10*time.Minute)))
+	h.Add(historyEvent("recent", now.Add(-2*time.Minute)))
+
+	if got := h.Since(now.Add(-5 * time.Minute)); len(got) != 1 || got[0].ID != "recent" {
+		t.Errorf("Since(5m) = %v, want [recent]", got)
+	}
+	// Asking for more than the retention window is clamped.
+	got := h.Since(now.Add(-48 * time.Hour))
+	if len(got) != 2 || got[0].ID != "old" || got[1].ID != "recent" {
+		t.Errorf("Since(48h) = %v, want [old recent]", got)
+	}
+}
+
+func TestHistory_SubscribeReplaysThenStreamsLive(t *testing.T) {
+	h := NewHistory(10, time.Hour)
+	now := time.Now()
+	h.Add(historyEvent("before-1", now.Add(-2*time.Minute)))
+	h.Add(historyEvent("before-2", now.Add(-time.Minute)))
+
+	replay, live, cancel := h.Subscribe(now.Add(-5 * time.Minute))
+	defer cancel()
+
+	if len(replay) != 2 || replay[0].ID != "before-1" || replay[1].ID != "before-2" {
+		t.Fatalf("replay = %v, want [before-1 before-2]", replay)
+	}
+
+	h.Add(historyEvent("after", time.Now()))
+	select {
+	case e := <-live:
+		if e.ID != "after" {
+			t.Errorf("live event = %q, want after", e.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("live event not delivered")
+	}
+
+	cancel()
+	h.Add(historyEvent("ignored", time.Now()))
+	select {
+	case e := <-live:
+		t.Errorf("received %q after cancel", e.ID)
+	default:
+	}
+}
+
+func TestHistory_SubscribeZeroSinceSkipsReplay(t *testing.T) {
+	h := NewHistory(10, time.Hour)
+	h.Add(historyEvent("x", time.Now()))
+	replay, _, cancel := h.Subscribe(time.Time{})
+	defer cancel()
+	if len(replay) != 0 {
+		t.Errorf("replay = %v, want none", replay)
+	}
+}
+
+func TestRouter_EmitRecordsHistory(t *testing.T) {
+	r := NewRouter(nil, "")
+	r.Emit(historyEvent("one", time.Now()))
+	r.Emit(historyEvent("one", time.Now())) // duplicate, dropped
+	if got := r.History().Since(time.Now().Add(-time.Minute)); len(got) != 1 {
+		t.Errorf("history has %d events, want 1", len(got))
+	}
+}
+
+func TestRouter_RecordSkipsSinks(t *testing.T) {
+	r := NewRouter(nil, "")
+	sink := &mockSink{name: "test"}
+	r.AddSink(sink)
+
+	_, live, cancel := r.History().Subscribe(time.Time{})
+	defer cancel()
+
+	r.Record(historyEvent("one", time.Now()))
+	r.Record(historyEvent("one", time.Now())) // duplicate, dropped
+
+	select {
+	case e := <-live:
+		if e.ID != "one" {
+			t.Errorf("live event = %q, want one", e.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("recorded event not delivered to subscriber")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if sink.count() != 0 {
+		t.Errorf("sink got %d events, want 0", sink.count())
+	}
+	if got := r.History().Since(time.Now().Add(-time.Minute)); len(got) != 1 {
+		t.Errorf("history has %d events, want 1", len(got))
+	}
+}
+
+func TestHistory_CancelReleasesSubscriber(t *testing.T) {
+	h := NewHistory(4, time.Hour)
+	_, _, cancel := h.Subscribe(time.Time{})
+	if n := h.Subscribers(); n != 1 {
+		t.Fatalf("subscribers = %d, want 1", n)
+	}
+	cancel()
+	cancel() // idempotent
+	if n := h.Subscribers(); n != 0 {
+		t.Errorf("subscribers after cancel = %d, want 0", n)
+	}
+}
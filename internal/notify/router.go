@@ -47,6 +47,9 @@ type Router struct {
 	// NameResolver resolves peer IDs to human names.
 	nameResolver func(peerID string) string
 
+	// history keeps recent events for replay to late subscribers.
+	history *History
+
 	started bool
 	stopCh  chan struct{}
 	done    chan struct{}
@@ -66,6 +69,7 @@ func NewRouter(logger *slog.Logger, logLevel Severity) *Router {
 		stopCh:          make(chan struct{}),
 		done:            make(chan struct{}),
 		logger:          logger,
+		history:         NewHistory(DefaultHistorySize, DefaultHistoryRetention),
 	}
 	// LogSink is always first and always present.
 	r.sinks = append(r.sinks, NewLogSink(logger, logLevel))
@@ -79,6 +83,11 @@ func (r *Router) AddSink(s Sink) {
 	r.sinks = append(r.sinks, s)
 }
 
+// History returns the router's recent-event buffer.
+func (r *Router) History() *History {
+	return r.history
+}
+
 // Sinks returns the names of all registered sinks.
 func (r *Router) Sinks() []string {
 	r.mu.RLock()
@@ -124,10 +133,10 @@ func (r *Router) Emit(event Event) {
 	copy(sinks, r.sinks)
 	r.mu.RUnlock()
 
-	// Resolve peer name if not already set.
-	if event.PeerName == "" && event.PeerID != "" && resolver != nil {
-		event.PeerName = resolver(event.PeerID)
-	}
+	event = resolveName(event, resolver)
+
+	// Recorded synchronously so replay order matches emit order.
+	r.history.Add(event)
 
 	for _, s := range sinks {
 		go func(sink Sink) {
@@ -149,6 +158,30 @@ func (r *Router) Emit(event Event) {
 	}
 }
 
+// Record adds an event to the history and live subscribers without
+// dispatching it to sinks. Used for high-volume stream-only events
+// (peer connectivity, auth decisions, proxy lifecycle) that would
+// otherwise flood desktop and webhook notifications.
+func (r *Router) Record(event Event) {
+	if r.isDuplicate(event.ID) {
+		return
+	}
+
+	r.mu.RLock()
+	resolver := r.nameResolver
+	r.mu.RUnlock()
+
+	r.history.Add(resolveName(event, resolver))
+}
+
+// resolveName fills in the peer name if not already set.
+func resolveName(event Event, resolver func(string) string) Event {
+	if event.PeerName == "" && event.PeerID != "" && resolver != nil {
+		event.PeerName = resolver(event.PeerID)
+	}
+	return event
+}
+
 // Start begins the pre-expiry warning ticker and dedup cleanup.
 // Must be called after SetExpiryChecker. Safe to call only once.
 func (r *Router) Start() {
@@ -0,0 +1,160 @@
+// Package output implements the shared --format flag used by list commands.
+//
+// Every list command accepts --format table|json|yaml, with --json kept as
+// an alias for --format json. Table output stays command-specific; json and
+// yaml are rendered here so all commands emit the same shape. YAML is built
+// node by node from the JSON encoding, so both formats use identical field
+// names and omitempty rules, keep fields in struct order, and write numbers
+// exactly as JSON does.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format for list commands.
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+// ParseFormat validates a --format value. Empty means Table.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", Table:
+		return Table, nil
+	case JSON:
+		return JSON, nil
+	case YAML, "yml":
+		return YAML, nil
+	}
+	return "", fmt.Errorf("invalid --format %q: must be table, json, or yaml", s)
+}
+
+// Flags holds the --format and --json flags registered on a FlagSet.
+type Flags struct {
+	format *string
+	json   *bool
+}
+
+// Register adds --format and its --json alias to fs.
+func Register(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		format: fs.String("format", string(Table), "output format: table, json, or yaml"),
+		json:   fs.Bool("json", false, "output as JSON (alias for --format json)"),
+	}
+}
+
+// Format returns the selected format. Call after fs.Parse.
+func (f *Flags) Format() (Format, error) {
+	format, err := ParseFormat(*f.format)
+	if err != nil {
+		return "", err
+	}
+	if *f.json {
+		if format != Table && format != JSON {
+			return "", fmt.Errorf("--json conflicts with --format %s", format)
+		}
+		return JSON, nil
+	}
+	return format, nil
+}
+
+// Write renders v as JSON or YAML. It must not be called with Table;
+// table rendering belongs to each command.
+func Write(w io.Writer, format Format, v any) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		node, err := yamlNode(dec)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("output.Write: unsupported format %q", format)
+}
+
+// yamlNode converts the next JSON value in dec to a YAML node. Object keys
+// keep their JSON order (struct field order; sorted for maps) and numbers
+// keep their JSON text, so large integers are not rounded through float64.
+func yamlNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, stringNode(key.(string)))
+			}
+			child, err := yamlNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return stringNode(t), nil
+	case json.Number:
+		if strings.ContainsAny(t.String(), ".eE") {
+			return scalar("!!float", t.String()), nil
+		}
+		return scalar("!!int", t.String()), nil
+	case bool:
+		return scalar("!!bool", strconv.FormatBool(t)), nil
+	case nil:
+		return scalar("!!null", "null"), nil
+	}
+	return nil, fmt.Errorf("output.Write: unexpected JSON token %v", tok)
+}
+
+// stringNode lets yaml.v3 pick the style for s, so strings that would read
+// back as another type (including YAML 1.1 booleans like "yes") are quoted.
+func stringNode(s string) *yaml.Node {
+	node := new(yaml.Node)
+	node.Encode(s) // encoding a string cannot fail
+	return node
+}
+
+func scalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
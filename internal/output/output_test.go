@@ -0,0 +1,139 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type testEntry struct {
+	Name    string   `json:"name"`
+	Count   int      `json:"count"`
+	Enabled bool     `json:"enabled"`
+	Tags    []string `json:"tags,omitempty"`
+	Note    string   `json:"note,omitempty"`
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{"": Table, "table": Table, "json": JSON, "yaml": YAML, "yml": YAML}
+	for in, want := range cases {
+		got, err := ParseFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestFlags(t *testing.T) {
+	cases := []struct {
+		args    []string
+		want    Format
+		wantErr bool
+	}{
+		{nil, Table, false},
+		{[]string{"--format", "yaml"}, YAML, false},
+		{[]string{"--json"}, JSON, false},
+		{[]string{"--json", "--format", "json"}, JSON, false},
+		{[]string{"--json", "--format", "yaml"}, "", true},
+		{[]string{"--format", "csv"}, "", true},
+	}
+	for _, tc := range cases {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		f := Register(fs)
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatalf("parse %v: %v", tc.args, err)
+		}
+		got, err := f.Format()
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("%v: got %q, %v; want %q (err=%v)", tc.args, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	in := []testEntry{
+		{Name: "ssh", Count: 2, Enabled: true, Tags: []string{"a", "b"}},
+		{Name: "web", Count: 0, Enabled: false, Note: "disabled: maintenance"},
+	}
+
+	var jsonBuf bytes.Buffer
+	if err := Write(&jsonBuf, JSON, in); err != nil {
+		t.Fatalf("Write json: %v", err)
+	}
+	var fromJSON []testEntry
+	if err := json.Unmarshal(jsonBuf.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if !reflect.DeepEqual(in, fromJSON) {
+		t.Errorf("json round-trip = %+v, want %+v", fromJSON, in)
+	}
+
+	var yamlBuf bytes.Buffer
+	if err := Write(&yamlBuf, YAML, in); err != nil {
+		t.Fatalf("Write yaml: %v", err)
+	}
+	// YAML keys follow the json tags, including omitempty.
+	if !strings.Contains(yamlBuf.String(), "name: ssh") || strings.Contains(yamlBuf.String(), "Name:") {
+		t.Errorf("yaml should use json field names:\n%s", yamlBuf.String())
+	}
+	var generic any
+	if err := yaml.Unmarshal(yamlBuf.Bytes(), &generic); err != nil {
+		t.Fatalf("unmarshal yaml: %v", err)
+	}
+	// Re-encode the decoded YAML as JSON and compare with the direct JSON.
+	reencoded, _ := json.Marshal(generic)
+	var fromYAML []testEntry
+	if err := json.Unmarshal(reencoded, &fromYAML); err != nil {
+		t.Fatalf("yaml→json: %v", err)
+	}
+	if !reflect.DeepEqual(in, fromYAML) {
+		t.Errorf("yaml round-trip = %+v, want %+v", fromYAML, in)
+	}
+}
+
+func TestWrite_YAMLOrderAndNumbers(t *testing.T) {
+	type entry struct {
+		Zeta  string  `json:"zeta"`
+		Alpha uint64  `json:"alpha"`
+		Ratio float64 `json:"ratio"`
+		Word  string  `json:"word"`
+		Empty []int   `json:"empty"`
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, YAML, entry{Zeta: "z", Alpha: 1<<63 + 1, Ratio: 0.25, Word: "yes"}); err != nil {
+		t.Fatalf("Write yaml: %v", err)
+	}
+	want := "zeta: z\nalpha: 9223372036854775809\nratio: 0.25\nword: \"yes\"\nempty: null\n"
+	if buf.String() != want {
+		t.Errorf("yaml =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWrite_Stable(t *testing.T) {
+	v := map[string]int{"zeta": 1, "alpha": 2, "mid": 3}
+	for _, f := range []Format{JSON, YAML} {
+		var first bytes.Buffer
+		Write(&first, f, v)
+		for i := 0; i < 10; i++ {
+			var again bytes.Buffer
+			Write(&again, f, v)
+			if again.String() != first.String() {
+				t.Fatalf("%s output not stable:\n%s\nvs\n%s", f, first.String(), again.String())
+			}
+		}
+	}
+}
+
+func TestWrite_TableUnsupported(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, Table, nil); err == nil {
+		t.Error("Write with Table should error")
+	}
+}
@@ -0,0 +1,444 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/clock"
+	"github.com/shurlinet/shurli/pkg/plugin"
+	"github.com/shurlinet/shurli/pkg/sdk"
+)
+
+// AccountingRetentionDays is how many daily rollups are kept on disk.
+const AccountingRetentionDays = 30
+
+// DefaultAccountingFlushInterval is how often the daily rollup is written to
+// disk while the relay runs. Usage since the last flush is lost on a crash.
+const DefaultAccountingFlushInterval = time.Minute
+
+// accountingCollectInterval is how often byte counts pending on open relay
+// streams are folded into the totals. Closed streams report at close.
+const accountingCollectInterval = 10 * time.Second
+
+// accountingDateFormat names a daily rollup (UTC).
+const accountingDateFormat = "2006-01-02"
+
+// RelayAccounting accumulates bytes relayed per peer and per authorized_keys
+// group. The circuit relay v2 service only reports aggregate byte counts, so
+// LimitingHost wraps each HOP/STOP stream in a countedStream. The stream only
+// bumps its own atomic counters on Read and Write; they are folded in here
+// when the stream closes and every accountingCollectInterval while it is
+// open, so the relay data path never takes the accounting lock. Directions
+// are from the peer's point of view: "in" is data the peer sent into the
+// relay, "out" is data the relay delivered to it.
+//
+// Two views are kept: totals since the relay started, and a daily rollup
+// (UTC) that is persisted so a restart does not lose the picture entirely.
+type RelayAccounting struct {
+	path    string
+	groupOf func(peer.ID) string
+	clock   clock.Clock
+	started time.Time
+
+	// Metrics is optional; when set, per-group counters are exported
+	// alongside the aggregate relay metrics. Per-peer figures are left to
+	// relay stats to keep metric cardinality bounded.
+	Metrics *sdk.Metrics
+
+	mu    sync.Mutex
+	total map[peer.ID]*RelayUsage
+	days  []*RelayDailyUsage // oldest first, last entry is the current day
+	dirty bool
+
+	streamsMu sync.Mutex
+	streams   map[*countedStream]struct{} // open streams with pending counts
+}
+
+// RelayUsage is a pair of byte counters.
+type RelayUsage struct {
+	BytesIn  uint64 `json:"bytes_in"`
+	BytesOut uint64 `json:"bytes_out"`
+}
+
+// Total returns bytes in both directions.
+func (u RelayUsage) Total() uint64 { return u.BytesIn + u.BytesOut }
+
+// RelayDailyUsage is one day's rollup, keyed by peer ID and by group.
+type RelayDailyUsage struct {
+	Date   string                 `json:"date"`
+	Peers  map[string]*RelayUsage `json:"peers"`
+	Groups map[string]*RelayUsage `json:"groups,omitempty"`
+}
+
+// relayAccountingFile is the on-disk format.
+type relayAccountingFile struct {
+	Version int                `json:"version"`
+	Days    []*RelayDailyUsage `json:"days"`
+}
+
+// NewRelayAccounting creates an accounting store persisted at path (empty
+// path disables persistence). groupOf resolves a peer's group attribute and
+// may be nil. Existing rollups are loaded; a missing file is not an error.
+func NewRelayAccounting(path string, groupOf func(peer.ID) string) (*RelayAccounting, error) {
+	a := &RelayAccounting{
+		path:    path,
+		groupOf: groupOf,
+		clock:   clock.Real,
+		total:   make(map[peer.ID]*RelayUsage),
+		streams: make(map[*countedStream]struct{}),
+	}
+	a.started = a.clock.Now()
+	if path == "" {
+		return a, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return a, fmt.Errorf("failed to read relay accounting: %w", err)
+	}
+	var f relayAccountingFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return a, fmt.Errorf("failed to parse relay accounting %s: %w", path, err)
+	}
+	for _, d := range f.Days {
+		if d == nil || d.Date == "" {
+			continue
+		}
+		if d.Peers == nil {
+			d.Peers = make(map[string]*RelayUsage)
+		}
+		a.days = append(a.days, d)
+	}
+	sort.Slice(a.days, func(i, j int) bool { return a.days[i].Date < a.days[j].Date })
+	return a, nil
+}
+
+// Record adds n bytes for peer p in the given direction ("in" or "out").
+func (a *RelayAccounting) Record(p peer.ID, direction string, n int64) {
+	if n <= 0 {
+		return
+	}
+	group := ""
+	if a.groupOf != nil {
+		group = a.groupOf(p)
+	}
+
+	a.mu.Lock()
+	t, ok := a.total[p]
+	if !ok {
+		t = &RelayUsage{}
+		a.total[p] = t
+	}
+	addUsage(t, direction, n)
+
+	day := a.currentDayLocked()
+	d, ok := day.Peers[p.String()]
+	if !ok {
+		d = &RelayUsage{}
+		day.Peers[p.String()] = d
+	}
+	addUsage(d, direction, n)
+	if group != "" {
+		if day.Groups == nil {
+			day.Groups = make(map[string]*RelayUsage)
+		}
+		g, ok := day.Groups[group]
+		if !ok {
+			g = &RelayUsage{}
+			day.Groups[group] = g
+		}
+		addUsage(g, direction, n)
+	}
+	a.dirty = true
+	a.mu.Unlock()
+
+	if a.Metrics != nil && group != "" {
+		a.Metrics.RelayGroupBytesTotal.WithLabelValues(group, direction).Add(float64(n))
+	}
+}
+
+// track registers an open stream so Collect can report its pending counts.
+func (a *RelayAccounting) track(cs *countedStream) {
+	a.streamsMu.Lock()
+	a.streams[cs] = struct{}{}
+	a.streamsMu.Unlock()
+}
+
+// untrack reports a closing stream's remaining counts and forgets it.
+func (a *RelayAccounting) untrack(cs *countedStream) {
+	a.streamsMu.Lock()
+	delete(a.streams, cs)
+	a.streamsMu.Unlock()
+	cs.report()
+}
+
+// Collect folds the counts pending on every open stream into the totals.
+func (a *RelayAccounting) Collect() {
+	a.streamsMu.Lock()
+	open := make([]*countedStream, 0, len(a.streams))
+	for cs := range a.streams {
+		open = append(open, cs)
+	}
+	a.streamsMu.Unlock()
+	for _, cs := range open {
+		cs.report()
+	}
+}
+
+func addUsage(u *RelayUsage, direction string, n int64) {
+	if direction == "in" {
+		u.BytesIn += uint64(n)
+	} else {
+		u.BytesOut += uint64(n)
+	}
+}
+
+// currentDayLocked returns today's rollup, starting a new one (and dropping
+// rollups past the retention window) when the UTC date has changed.
+func (a *RelayAccounting) currentDayLocked() *RelayDailyUsage {
+	date := a.clock.Now().UTC().Format(accountingDateFormat)
+	if n := len(a.days); n > 0 && a.days[n-1].Date == date {
+		return a.days[n-1]
+	}
+	d := &RelayDailyUsage{Date: date, Peers: make(map[string]*RelayUsage)}
+	a.days = append(a.days, d)
+	if len(a.days) > AccountingRetentionDays {
+		a.days = a.days[len(a.days)-AccountingRetentionDays:]
+	}
+	return d
+}
+
+// Flush writes the daily rollups to disk if anything changed since the last
+// flush.
+func (a *RelayAccounting) Flush() error {
+	if a.path == "" {
+		return nil
+	}
+	a.mu.Lock()
+	if !a.dirty {
+		a.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(relayAccountingFile{Version: 1, Days: a.days}, "", "  ")
+	a.dirty = false
+	a.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode relay accounting: %w", err)
+	}
+	if err := plugin.AtomicWriteFile(a.path, data, 0600); err != nil {
+		a.mu.Lock()
+		a.dirty = true
+		a.mu.Unlock()
+		return fmt.Errorf("failed to write relay accounting: %w", err)
+	}
+	return nil
+}
+
+// Run collects open streams' counts every accountingCollectInterval and
+// flushes the rollup every interval until ctx is done, then collects and
+// flushes once more.
+func (a *RelayAccounting) Run(ctx context.Context, interval time.Duration) {
+	collect := a.clock.NewTicker(accountingCollectInterval)
+	defer collect.Stop()
+	ticker := a.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.Collect()
+			if err := a.Flush(); err != nil {
+				slog.Warn("relay accounting: final flush failed", "err", err)
+			}
+			return
+		case <-collect.C():
+			a.Collect()
+		case <-ticker.C():
+			a.Collect()
+			if err := a.Flush(); err != nil {
+				slog.Warn("relay accounting: flush failed", "err", err)
+			}
+		}
+	}
+}
+
+// RelayPeerUsage is one peer's line in a RelayStats report.
+type RelayPeerUsage struct {
+	PeerID string     `json:"peer_id"`
+	Group  string     `json:"group,omitempty"`
+	Total  RelayUsage `json:"total"`
+	Today  RelayUsage `json:"today"`
+}
+
+// RelayGroupUsage is one group's line in a RelayStats report.
+type RelayGroupUsage struct {
+	Group string     `json:"group"`
+	Today RelayUsage `json:"today"`
+}
+
+// RelayStats is the response of GET /v1/relay-stats.
+type RelayStats struct {
+	Since  time.Time          `json:"since"`
+	Date   string             `json:"date"`
+	Peers  []RelayPeerUsage   `json:"peers"`
+	Groups []RelayGroupUsage  `json:"groups"`
+	Days   []*RelayDailyUsage `json:"days,omitempty"`
+}
+
+// Stats returns per-peer totals since start and for the current day, the
+// current day's group totals, and (when withDays is set) every retained
+// daily rollup. Peers are sorted by bytes relayed today, largest first.
+func (a *RelayAccounting) Stats(withDays bool) RelayStats {
+	a.Collect()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	day := a.currentDayLocked()
+	st := RelayStats{
+		Since:  a.started,
+		Date:   day.Date,
+		Peers:  []RelayPeerUsage{},
+		Groups: []RelayGroupUsage{},
+	}
+
+	seen := make(map[string]bool)
+	for p, t := range a.total {
+		u := RelayPeerUsage{PeerID: p.String(), Total: *t}
+		if d, ok := day.Peers[u.PeerID]; ok {
+			u.Today = *d
+		}
+		if a.groupOf != nil {
+			u.Group = a.groupOf(p)
+		}
+		seen[u.PeerID] = true
+		st.Peers = append(st.Peers, u)
+	}
+	// Peers seen today before a restart have no running total yet.
+	for id, d := range day.Peers {
+		if seen[id] {
+			continue
+		}
+		u := RelayPeerUsage{PeerID: id, Today: *d}
+		if a.groupOf != nil {
+			if p, err := peer.Decode(id); err == nil {
+				u.Group = a.groupOf(p)
+			}
+		}
+		st.Peers = append(st.Peers, u)
+	}
+	sort.Slice(st.Peers, func(i, j int) bool {
+		pi, pj := st.Peers[i], st.Peers[j]
+		if pi.Today.Total() != pj.Today.Total() {
+			return pi.Today.Total() > pj.Today.Total()
+		}
+		if pi.Total.Total() != pj.Total.Total() {
+			return pi.Total.Total() > pj.Total.Total()
+		}
+		return st.Peers[i].PeerID < st.Peers[j].PeerID
+	})
+
+	for g, u := range day.Groups {
+		st.Groups = append(st.Groups, RelayGroupUsage{Group: g, Today: *u})
+	}
+	sort.Slice(st.Groups, func(i, j int) bool {
+		if st.Groups[i].Today.Total() != st.Groups[j].Today.Total() {
+			return st.Groups[i].Today.Total() > st.Groups[j].Today.Total()
+		}
+		return st.Groups[i].Group < st.Groups[j].Group
+	})
+
+	if withDays {
+		for _, d := range a.days {
+			st.Days = append(st.Days, copyDailyUsage(d))
+		}
+	}
+	return st
+}
+
+func copyDailyUsage(d *RelayDailyUsage) *RelayDailyUsage {
+	c := &RelayDailyUsage{Date: d.Date, Peers: make(map[string]*RelayUsage, len(d.Peers))}
+	for k, v := range d.Peers {
+		u := *v
+		c.Peers[k] = &u
+	}
+	if len(d.Groups) > 0 {
+		c.Groups = make(map[string]*RelayUsage, len(d.Groups))
+		for k, v := range d.Groups {
+			u := *v
+			c.Groups[k] = &u
+		}
+	}
+	return c
+}
+
+// --- countedStream ---
+
+// countedStream wraps a relay HOP/STOP stream and counts every byte read
+// from or written to the peer. Read and Write only touch atomic counters;
+// RelayAccounting picks them up via report. It sits beneath any
+// limitedStream so admin and non-grant traffic is counted too.
+type countedStream struct {
+	network.Stream
+	peerID peer.ID
+	acct   *RelayAccounting
+
+	in, out   atomic.Int64 // bytes not yet reported
+	closeOnce sync.Once
+}
+
+func newCountedStream(s network.Stream, p peer.ID, acct *RelayAccounting) *countedStream {
+	cs := &countedStream{Stream: s, peerID: p, acct: acct}
+	acct.track(cs)
+	return cs
+}
+
+func (cs *countedStream) Read(p []byte) (int, error) {
+	n, err := cs.Stream.Read(p)
+	if n > 0 {
+		cs.in.Add(int64(n))
+	}
+	return n, err
+}
+
+func (cs *countedStream) Write(p []byte) (int, error) {
+	n, err := cs.Stream.Write(p)
+	if n > 0 {
+		cs.out.Add(int64(n))
+	}
+	return n, err
+}
+
+func (cs *countedStream) Close() error {
+	err := cs.Stream.Close()
+	cs.done()
+	return err
+}
+
+func (cs *countedStream) Reset() error {
+	err := cs.Stream.Reset()
+	cs.done()
+	return err
+}
+
+// done reports the final counts once the relay is finished with the stream.
+func (cs *countedStream) done() {
+	cs.closeOnce.Do(func() { cs.acct.untrack(cs) })
+}
+
+// report moves the pending counts into RelayAccounting.
+func (cs *countedStream) report() {
+	cs.acct.Record(cs.peerID, "in", cs.in.Swap(0))
+	cs.acct.Record(cs.peerID, "out", cs.out.Swap(0))
+}
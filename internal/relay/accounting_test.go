@@ -0,0 +1,195 @@
+package relay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+func TestRelayAccountingRecordAndGroups(t *testing.T) {
+	alice, bob := genTestPeerID(t), genTestPeerID(t)
+	groups := map[peer.ID]string{alice: "home"}
+
+	a, err := NewRelayAccounting("", func(p peer.ID) string { return groups[p] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Record(alice, "in", 100)
+	a.Record(alice, "out", 50)
+	a.Record(bob, "out", 500)
+	a.Record(bob, "in", 0) // ignored
+
+	st := a.Stats(false)
+	if len(st.Peers) != 2 {
+		t.Fatalf("peers = %d, want 2", len(st.Peers))
+	}
+	// Sorted by today's bytes, largest first.
+	if st.Peers[0].PeerID != bob.String() || st.Peers[0].Today.BytesOut != 500 {
+		t.Errorf("first peer = %+v, want bob with 500 out", st.Peers[0])
+	}
+	al := st.Peers[1]
+	if al.Group != "home" || al.Total != (RelayUsage{BytesIn: 100, BytesOut: 50}) || al.Today != al.Total {
+		t.Errorf("alice = %+v", al)
+	}
+	if len(st.Groups) != 1 || st.Groups[0].Group != "home" || st.Groups[0].Today.Total() != 150 {
+		t.Errorf("groups = %+v, want home=150", st.Groups)
+	}
+	if st.Days != nil {
+		t.Error("days included without withDays")
+	}
+}
+
+func TestRelayAccountingPersistAndRollover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay-accounting.json")
+	pid := genTestPeerID(t)
+	day := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	a, err := NewRelayAccounting(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.clock = clock.NewFake(day)
+	a.Record(pid, "in", 1000)
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restart on the same day keeps today's rollup but not the running total.
+	b, err := NewRelayAccounting(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.clock = clock.NewFake(day)
+	st := b.Stats(false)
+	if len(st.Peers) != 1 || st.Peers[0].Today.BytesIn != 1000 || st.Peers[0].Total.Total() != 0 {
+		t.Fatalf("after reload: %+v", st.Peers)
+	}
+
+	// Crossing midnight (UTC) starts a new day.
+	b.clock = clock.NewFake(day.Add(2 * time.Hour))
+	b.Record(pid, "out", 10)
+	st = b.Stats(true)
+	if st.Date != "2026-03-02" || st.Peers[0].Today != (RelayUsage{BytesOut: 10}) {
+		t.Errorf("after rollover: date=%s peers=%+v", st.Date, st.Peers)
+	}
+	if len(st.Days) != 2 || st.Days[0].Peers[pid.String()].BytesIn != 1000 {
+		t.Errorf("days = %+v", st.Days)
+	}
+}
+
+func TestRelayAccountingRunFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay-accounting.json")
+	a, err := NewRelayAccounting(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	a.clock = fake
+	a.Record(genTestPeerID(t), "in", 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx, time.Hour)
+		close(done)
+	}()
+	for fake.Waiters() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fake.Advance(time.Hour)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Run did not flush after the interval elapsed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestRelayAccountingRetention(t *testing.T) {
+	a, _ := NewRelayAccounting("", nil)
+	pid := genTestPeerID(t)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < AccountingRetentionDays+5; i++ {
+		now := start.AddDate(0, 0, i)
+		a.clock = clock.NewFake(now)
+		a.Record(pid, "in", 1)
+	}
+	st := a.Stats(true)
+	if len(st.Days) != AccountingRetentionDays {
+		t.Fatalf("days = %d, want %d", len(st.Days), AccountingRetentionDays)
+	}
+	if want := start.AddDate(0, 0, 5).Format(accountingDateFormat); st.Days[0].Date != want {
+		t.Errorf("oldest day = %s, want %s", st.Days[0].Date, want)
+	}
+}
+
+func TestLimitingHostCountsWithoutBudgets(t *testing.T) {
+	a, _ := NewRelayAccounting("", nil)
+	pid := genTestPeerID(t)
+	lh := NewLimitingHost(nil, nil, nil)
+	lh.SetAccounting(a)
+
+	s := lh.wrapStreamForPeer(nil, pid)
+	cs, ok := s.(*countedStream)
+	if !ok {
+		t.Fatalf("stream = %T, want *countedStream with no budget tracker", s)
+	}
+	if cs.peerID != pid {
+		t.Errorf("peer = %s, want %s", cs.peerID, pid)
+	}
+}
+
+// echoStream is a network.Stream stub whose Read and Write always move the
+// full buffer.
+type echoStream struct {
+	network.Stream
+}
+
+func (echoStream) Read(p []byte) (int, error)  { return len(p), nil }
+func (echoStream) Write(p []byte) (int, error) { return len(p), nil }
+func (echoStream) Close() error                { return nil }
+
+func TestCountedStreamReportsOnCollectAndClose(t *testing.T) {
+	a, _ := NewRelayAccounting("", nil)
+	pid := genTestPeerID(t)
+	cs := newCountedStream(echoStream{}, pid, a)
+
+	cs.Read(make([]byte, 100))
+	cs.Write(make([]byte, 30))
+	a.mu.Lock()
+	pending := len(a.total)
+	a.mu.Unlock()
+	if pending != 0 {
+		t.Fatal("reads and writes should not reach the totals before collection")
+	}
+
+	a.Collect()
+	if st := a.Stats(false); len(st.Peers) != 1 || st.Peers[0].Total != (RelayUsage{BytesIn: 100, BytesOut: 30}) {
+		t.Fatalf("after collect: %+v", st.Peers)
+	}
+
+	cs.Write(make([]byte, 5))
+	cs.Close()
+	cs.Close()
+	if st := a.Stats(false); st.Peers[0].Total != (RelayUsage{BytesIn: 100, BytesOut: 35}) {
+		t.Errorf("after close: %+v", st.Peers[0].Total)
+	}
+	if len(a.streams) != 0 {
+		t.Errorf("%d streams still tracked after close", len(a.streams))
+	}
+}
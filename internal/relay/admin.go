@@ -16,6 +16,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/shurlinet/shurli/internal/platform"
@@ -182,6 +183,8 @@ type AdminServer struct {
 	sessionDataLimit int64           // from relay config, bytes per session per direction (0=unlimited)
 	sessionDuration  time.Duration   // from relay config, max time per circuit session
 	budgetTracker    *BudgetTracker  // per-peer budget enforcement (nil if not configured)
+	accounting       *RelayAccounting // per-peer/group relayed bytes (nil if not configured)
+	authReloadMu     sync.Mutex      // serializes reloadAuth (admin handlers + file watcher)
 }
 
 // SetHost stores the libp2p host reference for connected-peers queries.
@@ -264,6 +267,11 @@ func (s *AdminServer) SetGrantStore(gs *grants.Store) {
 	s.grantStore = gs
 }
 
+// SetAccounting attaches per-peer relay accounting for GET /v1/relay-stats.
+func (s *AdminServer) SetAccounting(a *RelayAccounting) {
+	s.accounting = a
+}
+
 // buildMux creates the HTTP route table. Called once by Start() and reused
 // by HandleRemoteRequest so that the remote admin protocol dispatches to
 // the same handler functions as the local Unix socket.
@@ -272,6 +280,7 @@ func (s *AdminServer) buildMux() *http.ServeMux {
 	mux.HandleFunc("POST /v1/pair", s.handleCreatePair)
 	mux.HandleFunc("GET /v1/pair", s.handleListPairs)
 	mux.HandleFunc("DELETE /v1/pair/{id}", s.handleRevokePair)
+	mux.HandleFunc("DELETE /v1/pair/code/{hash}", s.handleRevokePairCode)
 
 	// Invite deposit endpoints (require unsealed vault for mutation)
 	mux.HandleFunc("POST /v1/invite", s.requireUnsealedOr(s.handleCreateInvite))
@@ -314,6 +323,7 @@ func (s *AdminServer) buildMux() *http.ServeMux {
 	mux.HandleFunc("GET /v1/relay-grants", s.handleRelayGrants)
 	mux.HandleFunc("POST /v1/relay-revoke", s.requireUnsealedOr(s.handleRelayRevoke))
 	mux.HandleFunc("POST /v1/relay-extend", s.requireUnsealedOr(s.handleRelayExtend))
+	mux.HandleFunc("GET /v1/relay-stats", s.handleRelayStats)
 
 	// MOTD and goodbye endpoints
 	mux.HandleFunc("GET /v1/motd", s.handleGetMOTD)
@@ -630,6 +640,8 @@ func (s *AdminServer) handleListPairs(w http.ResponseWriter, r *http.Request) {
 		ExpiresAt string     `json:"expires_at"`
 		Total     int        `json:"total"`
 		Used      int        `json:"used"`
+		Revoked   int        `json:"revoked"`
+		Void      int        `json:"void,omitempty"`
 		Peers     []peerJSON `json:"peers,omitempty"`
 	}
 
@@ -646,6 +658,8 @@ func (s *AdminServer) handleListPairs(w http.ResponseWriter, r *http.Request) {
 			ExpiresAt: g.ExpiresAt.Format(time.RFC3339),
 			Total:     g.Total,
 			Used:      g.Used,
+			Revoked:   g.Revoked,
+			Void:      g.Void,
 		}
 		if g.CreatedBy != "" {
 			gj.CreatedBy = g.CreatedBy.String()
@@ -701,14 +715,10 @@ func (s *AdminServer) handleRevokePair(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.store.Revoke(groupID); err != nil {
-		respondAdminError(w, http.StatusNotFound, err.Error())
+		respondAdminError(w, revokeErrorStatus(err), err.Error())
 		return
 	}
-
-	// Disable enrollment if no active groups remain.
-	if s.gater != nil && s.store.ActiveGroupCount() == 0 {
-		s.gater.SetEnrollmentMode(false, 0, 0)
-	}
+	s.afterRevoke()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "revoked"})
@@ -716,6 +726,54 @@ func (s *AdminServer) handleRevokePair(w http.ResponseWriter, r *http.Request) {
 	slog.Info("pairing group revoked via admin", "group", groupID)
 }
 
+// handleRevokePairCode revokes a single code, addressed by the hex SHA-256 of
+// its token so the code itself never crosses the admin channel.
+func (s *AdminServer) handleRevokePairCode(w http.ResponseWriter, r *http.Request) {
+	hashHex := r.PathValue("hash")
+	raw, err := hex.DecodeString(hashHex)
+	if err != nil || len(raw) != 32 {
+		respondAdminError(w, http.StatusBadRequest, "invalid code hash")
+		return
+	}
+	var tokenHash [32]byte
+	copy(tokenHash[:], raw)
+
+	// Same ownership rule as group revoke, applied to the code's group.
+	if !callerIsAdmin(r) {
+		groupID := s.store.GroupForCode(tokenHash)
+		if groupID == "" || s.store.GroupCreator(groupID) != callerPeerID(r) {
+			respondAdminError(w, http.StatusForbidden, "permission denied: only the group creator or admin can revoke")
+			return
+		}
+	}
+
+	groupID, err := s.store.RevokeCode(tokenHash)
+	if err != nil {
+		respondAdminError(w, revokeErrorStatus(err), err.Error())
+		return
+	}
+	s.afterRevoke()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "revoked", "group_id": groupID})
+
+	slog.Info("pairing code revoked via admin", "group", groupID)
+}
+
+// afterRevoke disables enrollment if no active groups remain.
+func (s *AdminServer) afterRevoke() {
+	if s.gater != nil && s.store.ActiveGroupCount() == 0 {
+		s.gater.SetEnrollmentMode(false, 0, 0)
+	}
+}
+
+func revokeErrorStatus(err error) int {
+	if errors.Is(err, ErrNothingToRevoke) {
+		return http.StatusConflict
+	}
+	return http.StatusNotFound
+}
+
 // --- Invite deposit endpoints ---
 
 func (s *AdminServer) handleCreateInvite(w http.ResponseWriter, r *http.Request) {
@@ -1261,7 +1319,9 @@ func (s *AdminServer) handleAuthorizePeer(w http.ResponseWriter, r *http.Request
 	}
 
 	// Trigger auth reload (gater + ZKP tree) like handleAuthReload does.
-	s.reloadAuth()
+	if _, err := s.reloadAuth(); err != nil {
+		slog.Warn("auth reload after peer mutation failed", "err", err)
+	}
 
 	slog.Info("peer authorized via admin", "peer_id", req.PeerID[:min(16, len(req.PeerID))]+"...")
 	w.Header().Set("Content-Type", "application/json")
@@ -1298,8 +1358,11 @@ func (s *AdminServer) handleDeauthorizePeer(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Trigger auth reload (gater + ZKP tree).
-	s.reloadAuth()
+	// Trigger auth reload (gater + ZKP tree). Also drops the peer's
+	// connections, which releases its reservation.
+	if _, err := s.reloadAuth(); err != nil {
+		slog.Warn("auth reload after peer mutation failed", "err", err)
+	}
 
 	slog.Info("peer deauthorized via admin", "peer_id", req.PeerID[:min(16, len(req.PeerID))]+"...")
 	w.Header().Set("Content-Type", "application/json")
@@ -1359,7 +1422,9 @@ func (s *AdminServer) handleSetPeerAttr(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Trigger auth reload (gater + circuit ACL).
-	s.reloadAuth()
+	if _, err := s.reloadAuth(); err != nil {
+		slog.Warn("auth reload after peer mutation failed", "err", err)
+	}
 
 	short := req.PeerID
 	if len(short) > 16 {
@@ -1380,26 +1445,46 @@ func (s *AdminServer) handleSetPeerAttr(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// reloadAuth reloads the gater from authorized_keys and rebuilds the ZKP tree.
-// Shared by handleAuthorizePeer, handleDeauthorizePeer, and handleAuthReload.
-func (s *AdminServer) reloadAuth() {
+// reloadAuth reloads the gater from authorized_keys, refreshes the circuit
+// ACL and rebuilds the ZKP tree. Peers that were authorized before the
+// reload and are not any more are disconnected, which drops their
+// reservations and circuits. Returns the number of authorized peers.
+// Shared by the peer mutation handlers, handleAuthReload and
+// WatchAuthorizedKeys.
+func (s *AdminServer) reloadAuth() (int, error) {
 	if s.gater == nil || s.authKeysPath == "" {
-		return
+		return 0, nil
 	}
+	s.authReloadMu.Lock()
+	defer s.authReloadMu.Unlock()
+
 	peers, err := auth.LoadAuthorizedKeys(s.authKeysPath)
 	if err != nil {
-		slog.Warn("auth reload after peer mutation failed", "err", err)
-		return
+		return 0, err
 	}
+	previous := s.gater.GetAuthorizedPeerIDs()
 	s.gater.UpdateAuthorizedPeers(peers)
 	if s.circuitACL != nil {
 		s.circuitACL.Reload()
 	}
 	if s.zkpAuth != nil {
 		if err := s.zkpAuth.RebuildTree(); err != nil {
-			slog.Warn("zkp tree rebuild after peer mutation failed", "err", err)
+			slog.Warn("zkp tree rebuild after auth reload failed", "err", err)
+		}
+	}
+
+	if s.host != nil {
+		for _, pid := range previous {
+			if peers[pid] {
+				continue
+			}
+			if s.host.Network().Connectedness(pid) == network.Connected {
+				slog.Info("disconnecting deauthorized peer", "peer", pid.String()[:16]+"...")
+			}
+			s.host.Network().ClosePeer(pid)
 		}
 	}
+	return len(peers), nil
 }
 
 // --- Auth hot-reload endpoint ---
@@ -1417,27 +1502,18 @@ func (s *AdminServer) handleAuthReload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	peers, err := auth.LoadAuthorizedKeys(s.authKeysPath)
+	n, err := s.reloadAuth()
 	if err != nil {
 		respondAdminError(w, http.StatusInternalServerError,
 			fmt.Sprintf("failed to reload authorized_keys: %v", err))
 		return
 	}
 
-	s.gater.UpdateAuthorizedPeers(peers)
-
-	// Rebuild ZKP Merkle tree if enabled (new peers change the tree).
-	if s.zkpAuth != nil {
-		if err := s.zkpAuth.RebuildTree(); err != nil {
-			slog.Warn("auth reload: zkp tree rebuild failed", "err", err)
-		}
-	}
-
-	slog.Info("authorized_keys reloaded via admin socket", "peers", len(peers))
+	slog.Info("authorized_keys reloaded via admin socket", "peers", n)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
 		"status": "reloaded",
-		"peers":  len(peers),
+		"peers":  n,
 	})
 }
 
@@ -1847,6 +1923,21 @@ func (s *AdminServer) handleRelayGrants(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(result)
 }
 
+// handleRelayStats reports bytes relayed per peer and per group. With
+// ?days=1 every retained daily rollup is included.
+func (s *AdminServer) handleRelayStats(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if s.accounting == nil {
+		respondAdminError(w, http.StatusServiceUnavailable, "relay accounting not initialized")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.accounting.Stats(r.URL.Query().Get("days") == "1"))
+}
+
 func (s *AdminServer) handleRelayRevoke(w http.ResponseWriter, r *http.Request) {
 	if !requireAdmin(w, r) {
 		return
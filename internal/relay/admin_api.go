@@ -23,6 +23,7 @@ type RelayAdminAPI interface {
 	CreateGroup(count, ttlSec, expiresSec int, namespace string) (*PairResponse, error)
 	ListGroups() ([]GroupInfo, error)
 	RevokeGroup(id string) error
+	RevokeCode(tokenHashHex string) (string, error)
 	ListPeers() ([]AuthorizedPeerInfo, error)
 	ListConnectedPeers() ([]ConnectedPeerInfo, error)
 	AuthorizePeer(peerID, comment string) error
@@ -44,6 +45,9 @@ type RelayAdminAPI interface {
 	RelayRevoke(peerID string) error
 	RelayExtend(peerID string, durationSecs int, dataBudgetStr string) error
 
+	// Relay data accounting (bytes relayed per peer and per group)
+	RelayStats(withDays bool) (*RelayStats, error)
+
 	// Relay info (peer ID, multiaddrs)
 	GetInfo() (*RelayInfoResponse, error)
 }
@@ -141,6 +141,8 @@ func (c *AdminClient) ListGroups() ([]GroupInfo, error) {
 		ExpiresAt string     `json:"expires_at"`
 		Total     int        `json:"total"`
 		Used      int        `json:"used"`
+		Revoked   int        `json:"revoked"`
+		Void      int        `json:"void,omitempty"`
 		Peers     []peerJSON `json:"peers"`
 	}
 
@@ -156,6 +158,8 @@ func (c *AdminClient) ListGroups() ([]GroupInfo, error) {
 			Namespace: g.Namespace,
 			Total:     g.Total,
 			Used:      g.Used,
+			Revoked:   g.Revoked,
+			Void:      g.Void,
 		}
 		// Parse ExpiresAt if present.
 		if g.ExpiresAt != "" {
@@ -187,6 +191,23 @@ func (c *AdminClient) RevokeGroup(id string) error {
 	return nil
 }
 
+// RevokeCode revokes a single pairing code by the hex SHA-256 of its token.
+// Returns the ID of the group the code belonged to.
+func (c *AdminClient) RevokeCode(tokenHashHex string) (string, error) {
+	data, status, err := c.do("DELETE", "/v1/pair/code/"+tokenHashHex, nil)
+	if err != nil {
+		return "", err
+	}
+	if status >= 400 {
+		return "", parseAdminError(data, status)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp["group_id"], nil
+}
+
 // Unseal sends a passphrase (and optional TOTP code and Yubikey response) to unseal the relay vault.
 func (c *AdminClient) Unseal(passphrase, totpCode string, yubikeyResponse []byte) error {
 	req := UnsealRequest{
@@ -599,6 +620,27 @@ func (c *AdminClient) RelayGrants() ([]RelayGrantInfo, error) {
 	return result, nil
 }
 
+// RelayStats returns bytes relayed per peer and per group. withDays also
+// requests every retained daily rollup.
+func (c *AdminClient) RelayStats(withDays bool) (*RelayStats, error) {
+	path := "/v1/relay-stats"
+	if withDays {
+		path += "?days=1"
+	}
+	data, status, err := c.do("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 400 {
+		return nil, parseAdminError(data, status)
+	}
+	var result RelayStats
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
 // RelayRevoke revokes a relay data grant and terminates active circuits.
 func (c *AdminClient) RelayRevoke(peerID string) error {
 	reqBody, _ := json.Marshal(map[string]string{"peer_id": peerID})
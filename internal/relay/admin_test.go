@@ -2,6 +2,8 @@ package relay
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
@@ -13,6 +15,8 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/invite"
 )
 
 // mockGater implements AdminGaterInterface for testing.
@@ -284,13 +288,59 @@ func TestAdminClientRevokeGroup(t *testing.T) {
 		t.Error("enrollment should be disabled after revoking last group")
 	}
 
-	// List should be empty.
+	// The group stays listed until expiry, with nothing left to redeem.
 	groups, err := client.ListGroups()
 	if err != nil {
 		t.Fatalf("ListGroups: %v", err)
 	}
-	if len(groups) != 0 {
-		t.Errorf("expected 0 groups after revoke, got %d", len(groups))
+	if len(groups) != 1 || groups[0].Revoked != 1 || groups[0].Outstanding() != 0 {
+		t.Errorf("after revoke: groups = %+v, want 1 group with its code revoked", groups)
+	}
+}
+
+func TestAdminClientRevokeCode(t *testing.T) {
+	sock, cookie := tempPaths(t)
+	store := NewTokenStore()
+	gater := &mockGater{}
+
+	srv := NewAdminServer(store, gater, testRelayAddr, "", sock, cookie)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+
+	client, err := NewAdminClient(sock, cookie)
+	if err != nil {
+		t.Fatalf("NewAdminClient: %v", err)
+	}
+
+	resp, err := client.CreateGroup(1, 600, 0, "")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	data, err := invite.Decode(resp.Codes[0])
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	sum := sha256.Sum256(data.Token)
+
+	groupID, err := client.RevokeCode(hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("RevokeCode: %v", err)
+	}
+	if groupID != resp.GroupID {
+		t.Errorf("RevokeCode group = %q, want %q", groupID, resp.GroupID)
+	}
+	if gater.IsEnrollmentEnabled() {
+		t.Error("enrollment should be disabled after revoking the only code")
+	}
+
+	// Revoking again is a conflict, not a silent success.
+	if _, err := client.RevokeCode(hex.EncodeToString(sum[:])); err == nil || !strings.Contains(err.Error(), "nothing to revoke") {
+		t.Errorf("second RevokeCode: err = %v", err)
+	}
+	if _, err := client.RevokeCode("zz"); err == nil {
+		t.Error("malformed hash should fail")
 	}
 }
 
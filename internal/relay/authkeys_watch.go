@@ -0,0 +1,101 @@
+package relay
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// DefaultAuthKeysWatchInterval is how often WatchAuthorizedKeys checks the
+// authorized_keys file for changes.
+const DefaultAuthKeysWatchInterval = 2 * time.Second
+
+// authKeysStamp identifies one version of the authorized_keys file.
+// A missing file has the zero stamp.
+type authKeysStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// authKeysDebounce holds back a change until the file has stopped
+// changing, so an editor or script caught halfway through writing the file
+// does not trigger a reload of a truncated peer list.
+type authKeysDebounce struct {
+	applied    authKeysStamp // version last reloaded (or seen at start)
+	pending    authKeysStamp // changed version waiting to settle
+	hasPending bool
+}
+
+// settled records the stamp seen on this poll and reports whether the file
+// changed since the last reload and then stayed the same for a whole poll
+// interval.
+func (d *authKeysDebounce) settled(cur authKeysStamp) bool {
+	if cur == d.applied {
+		d.hasPending = false
+		return false
+	}
+	if !d.hasPending || cur != d.pending {
+		d.pending, d.hasPending = cur, true
+		return false
+	}
+	d.applied, d.hasPending = cur, false
+	return true
+}
+
+func statAuthKeys(path string) (authKeysStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return authKeysStamp{}, nil
+		}
+		return authKeysStamp{}, err
+	}
+	return authKeysStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+// WatchAuthorizedKeys polls the authorized_keys file every interval and
+// reloads the gater, circuit ACL and ZKP tree when its modification time
+// or size changes, so hand edits take effect without a restart. A change
+// is applied once the file has been left alone for one interval, so a
+// write still in progress is not read half-way. Peers
+// removed from the file are disconnected. A file that fails to parse is
+// logged and the previous peer set stays in force. Edits made through the
+// admin socket trigger a second, harmless reload. Blocks until ctx is done.
+func (s *AdminServer) WatchAuthorizedKeys(ctx context.Context, interval time.Duration) {
+	if s.gater == nil || s.authKeysPath == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultAuthKeysWatchInterval
+	}
+
+	start, err := statAuthKeys(s.authKeysPath)
+	if err != nil {
+		slog.Warn("authorized_keys watch: stat failed", "path", s.authKeysPath, "err", err)
+	}
+	debounce := authKeysDebounce{applied: start}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cur, err := statAuthKeys(s.authKeysPath)
+		if err != nil || !debounce.settled(cur) {
+			continue
+		}
+
+		n, err := s.reloadAuth()
+		if err != nil {
+			slog.Warn("authorized_keys changed but reload failed; keeping previous peers",
+				"path", s.authKeysPath, "err", err)
+			continue
+		}
+		slog.Info("authorized_keys reloaded after file change", "peers", n)
+	}
+}
@@ -0,0 +1,119 @@
+package relay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/auth"
+)
+
+func TestWatchAuthorizedKeys(t *testing.T) {
+	a, b := genPeerID(t), genPeerID(t)
+	path := filepath.Join(t.TempDir(), "authorized_keys")
+	stamp := time.Now()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		// Coarse filesystem timestamps could hide a same-size rewrite.
+		stamp = stamp.Add(time.Second)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(a.String() + "  # a\n")
+
+	gater := auth.NewAuthorizedPeerGater(map[peer.ID]bool{a: true})
+	sock, cookie := tempPaths(t)
+	srv := NewAdminServer(NewTokenStore(), gater, testRelayAddr, "", sock, cookie)
+	srv.SetAuthKeysPath(path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		srv.WatchAuthorizedKeys(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// Let the watcher record the initial stamp before the first edit.
+	time.Sleep(50 * time.Millisecond)
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Replacing a with b swaps the authorized set.
+	write(b.String() + "  # b\n")
+	waitFor("reload", func() bool { return gater.IsAuthorized(b) && !gater.IsAuthorized(a) })
+
+	// A file that no longer parses keeps the previous peers.
+	write("not-a-peer-id\n")
+	time.Sleep(100 * time.Millisecond)
+	if !gater.IsAuthorized(b) {
+		t.Fatal("invalid file replaced the authorized set")
+	}
+
+	// Fixing the file is picked up again.
+	write(a.String() + "\n" + b.String() + "\n")
+	waitFor("reload after fix", func() bool { return gater.IsAuthorized(a) && gater.IsAuthorized(b) })
+}
+
+func TestAuthKeysDebounce(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	v0 := authKeysStamp{modTime: t0, size: 10}
+	half := authKeysStamp{modTime: t0.Add(time.Second), size: 4}
+	full := authKeysStamp{modTime: t0.Add(2 * time.Second), size: 20}
+
+	d := authKeysDebounce{applied: v0}
+	steps := []struct {
+		cur  authKeysStamp
+		want bool
+	}{
+		{v0, false},   // unchanged
+		{half, false}, // write in progress
+		{full, false}, // still changing
+		{full, true},  // stable for one poll: reload
+		{full, false}, // already applied
+		{v0, false},   // changed back
+		{half, false}, // changed again before settling
+		{half, true},
+	}
+	for i, st := range steps {
+		if got := d.settled(st.cur); got != st.want {
+			t.Errorf("step %d: settled(%+v) = %v, want %v", i, st.cur, got, st.want)
+		}
+	}
+}
+
+func TestWatchAuthorizedKeysNoGater(t *testing.T) {
+	sock, cookie := tempPaths(t)
+	srv := NewAdminServer(NewTokenStore(), nil, testRelayAddr, "", sock, cookie)
+	srv.SetAuthKeysPath(filepath.Join(t.TempDir(), "authorized_keys"))
+
+	done := make(chan struct{})
+	go func() {
+		srv.WatchAuthorizedKeys(context.Background(), time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher without a gater should return immediately")
+	}
+}
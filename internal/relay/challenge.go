@@ -0,0 +1,153 @@
+package relay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// AuthChallengeProtocol is the proof-of-possession challenge a node sends
+// to a provisionally authorized peer (one introduced through peer-notify)
+// before making its authorization permanent. The node sends a random
+// nonce, the peer signs it with its identity key, and the node checks the
+// signature against the public key embedded in the peer ID. A relay can
+// claim anyone is in a group; only the holder of the key can answer.
+const AuthChallengeProtocol = "/shurli/auth-challenge/1.0.0"
+
+// Wire format version.
+const challengeVersion byte = 0x01
+
+// ChallengeNonceSize is the length of the random challenge nonce.
+const ChallengeNonceSize = 32
+
+// maxChallengeSigSize bounds the signature a responder may send. Ed25519
+// signatures are 64 bytes; RSA-4096 ones 512.
+const maxChallengeSigSize = 1024
+
+// challengeDomain separates these signatures from anything else signed
+// with the identity key, so a challenge can't be used as a signing oracle.
+const challengeDomain = "shurli-auth-challenge/v1"
+
+// ErrChallengeFailed means the peer answered but its signature does not
+// verify: whoever is on the other end does not hold the key for the peer
+// ID. Transport errors (peer offline, protocol unsupported) are returned
+// as other errors, since they prove nothing either way.
+var ErrChallengeFailed = errors.New("auth challenge: signature does not verify")
+
+// ChallengePayload returns the bytes the responder signs: the domain, both
+// peer IDs and the nonce. Binding both IDs stops a signature made for one
+// challenger from being replayed to another.
+func ChallengePayload(challenger, responder peer.ID, nonce []byte) []byte {
+	buf := make([]byte, 0, len(challengeDomain)+len(challenger)+len(responder)+len(nonce)+4)
+	buf = append(buf, challengeDomain...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(challenger)))
+	buf = append(buf, challenger...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(responder)))
+	buf = append(buf, responder...)
+	return append(buf, nonce...)
+}
+
+// VerifyChallengeSignature checks sig over the challenge payload against
+// the public key derived from the responder's peer ID.
+func VerifyChallengeSignature(challenger, responder peer.ID, nonce, sig []byte) error {
+	pub, err := responder.ExtractPublicKey()
+	if err != nil {
+		return fmt.Errorf("auth challenge: no public key in peer ID %s: %w", responder.String()[:16]+"...", err)
+	}
+	ok, err := pub.Verify(ChallengePayload(challenger, responder, nonce), sig)
+	if err != nil || !ok {
+		return ErrChallengeFailed
+	}
+	return nil
+}
+
+// ChallengePeer sends a fresh nonce to target and verifies the signed
+// answer. It returns nil only when target proved it holds its key.
+//
+// Wire format:
+//
+//	request:  [1] version (0x01)  [32] nonce
+//	response: [1] version (0x01)  [2 BE] signature length  [N] signature
+func ChallengePeer(ctx context.Context, h host.Host, target peer.ID) error {
+	nonce := make([]byte, ChallengeNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("auth challenge: nonce: %w", err)
+	}
+
+	streamCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	streamCtx = network.WithAllowLimitedConn(streamCtx, AuthChallengeProtocol)
+	s, err := h.NewStream(streamCtx, target, protocol.ID(AuthChallengeProtocol))
+	if err != nil {
+		return fmt.Errorf("auth challenge: open stream to %s: %w", target.String()[:16]+"...", err)
+	}
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if _, err := s.Write(append([]byte{challengeVersion}, nonce...)); err != nil {
+		return fmt.Errorf("auth challenge: write: %w", err)
+	}
+	sig, err := readChallengeResponse(s)
+	if err != nil {
+		return err
+	}
+	return VerifyChallengeSignature(h.ID(), target, nonce, sig)
+}
+
+func readChallengeResponse(r io.Reader) ([]byte, error) {
+	var hdr [3]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("auth challenge: read response: %w", err)
+	}
+	if hdr[0] != challengeVersion {
+		return nil, fmt.Errorf("auth challenge: unsupported version: 0x%02x", hdr[0])
+	}
+	n := int(binary.BigEndian.Uint16(hdr[1:]))
+	if n == 0 || n > maxChallengeSigSize {
+		return nil, fmt.Errorf("auth challenge: bad signature length %d", n)
+	}
+	sig := make([]byte, n)
+	if _, err := io.ReadFull(r, sig); err != nil {
+		return nil, fmt.Errorf("auth challenge: read signature: %w", err)
+	}
+	return sig, nil
+}
+
+// HandleAuthChallenge answers a challenge on s by signing it with priv,
+// the key behind the local peer ID.
+func HandleAuthChallenge(s network.Stream, priv crypto.PrivKey) error {
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(10 * time.Second))
+
+	req := make([]byte, 1+ChallengeNonceSize)
+	if _, err := io.ReadFull(s, req); err != nil {
+		return fmt.Errorf("auth challenge: read request: %w", err)
+	}
+	if req[0] != challengeVersion {
+		return fmt.Errorf("auth challenge: unsupported version: 0x%02x", req[0])
+	}
+	payload := ChallengePayload(s.Conn().RemotePeer(), s.Conn().LocalPeer(), req[1:])
+	sig, err := priv.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("auth challenge: sign: %w", err)
+	}
+
+	resp := make([]byte, 0, 3+len(sig))
+	resp = append(resp, challengeVersion)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(sig)))
+	resp = append(resp, sig...)
+	if _, err := s.Write(resp); err != nil {
+		return fmt.Errorf("auth challenge: write response: %w", err)
+	}
+	return nil
+}
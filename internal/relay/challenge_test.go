@@ -0,0 +1,94 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+func newChallengeHost(t *testing.T) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestChallengePeer(t *testing.T) {
+	challenger, responder := newChallengeHost(t), newChallengeHost(t)
+	challenger.Peerstore().AddAddrs(responder.ID(), responder.Addrs(), time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("valid signature", func(t *testing.T) {
+		priv := responder.Peerstore().PrivKey(responder.ID())
+		responder.SetStreamHandler(protocol.ID(AuthChallengeProtocol), func(s network.Stream) {
+			HandleAuthChallenge(s, priv)
+		})
+		if err := ChallengePeer(ctx, challenger, responder.ID()); err != nil {
+			t.Fatalf("ChallengePeer: %v", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		// The responder signs with a key that is not behind its peer ID,
+		// as an impostor relaying for the real peer would have to.
+		other, _, err := crypto.GenerateEd25519Key(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responder.SetStreamHandler(protocol.ID(AuthChallengeProtocol), func(s network.Stream) {
+			HandleAuthChallenge(s, other)
+		})
+		if err := ChallengePeer(ctx, challenger, responder.ID()); !errors.Is(err, ErrChallengeFailed) {
+			t.Fatalf("err = %v, want ErrChallengeFailed", err)
+		}
+	})
+
+	t.Run("no handler", func(t *testing.T) {
+		responder.RemoveStreamHandler(protocol.ID(AuthChallengeProtocol))
+		err := ChallengePeer(ctx, challenger, responder.ID())
+		if err == nil || errors.Is(err, ErrChallengeFailed) {
+			t.Fatalf("err = %v, want a transport error", err)
+		}
+	})
+}
+
+func TestVerifyChallengeSignature(t *testing.T) {
+	priv, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responder, _ := peer.IDFromPrivateKey(priv)
+	challengerKey, _, _ := crypto.GenerateEd25519Key(nil)
+	challenger, _ := peer.IDFromPrivateKey(challengerKey)
+	nonce := make([]byte, ChallengeNonceSize)
+
+	sig, err := priv.Sign(ChallengePayload(challenger, responder, nonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChallengeSignature(challenger, responder, nonce, sig); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+
+	// A signature made for one challenger does not verify for another.
+	if err := VerifyChallengeSignature(responder, responder, nonce, sig); !errors.Is(err, ErrChallengeFailed) {
+		t.Errorf("replayed to another challenger: err = %v", err)
+	}
+	nonce[0] ^= 1
+	if err := VerifyChallengeSignature(challenger, responder, nonce, sig); !errors.Is(err, ErrChallengeFailed) {
+		t.Errorf("different nonce: err = %v", err)
+	}
+}
@@ -30,6 +30,7 @@ type CircuitACL struct {
 	enableConnectionGating bool
 	grantStore           *grants.Store   // time-limited data access grants
 	budgetTracker        *BudgetTracker  // per-peer relay data budgets (nil if not configured)
+	reserveThrottle      *ReservationThrottle // per-peer reservation churn limit (nil = unlimited)
 
 	mu      sync.RWMutex
 	peers   map[peer.ID]bool         // cached authorized peer set
@@ -69,6 +70,12 @@ func (a *CircuitACL) SetBudgetTracker(bt *BudgetTracker) {
 	a.budgetTracker = bt
 }
 
+// SetReservationThrottle wires the per-peer reservation churn limit checked
+// by AllowReserve. Must be called before the relay service starts.
+func (a *CircuitACL) SetReservationThrottle(t *ReservationThrottle) {
+	a.reserveThrottle = t
+}
+
 // IsAdmin returns true if the peer has the admin role in authorized_keys (SEC4).
 // Used by LimitingHost to bypass budget enforcement for admin peers.
 func (a *CircuitACL) IsAdmin(p peer.ID) bool {
@@ -78,6 +85,15 @@ func (a *CircuitACL) IsAdmin(p peer.ID) bool {
 	return ok && e.Role == auth.RoleAdmin
 }
 
+// PeerGroup returns the peer's group attribute from authorized_keys, or ""
+// when it has none. Used by RelayAccounting for per-group totals.
+func (a *CircuitACL) PeerGroup(p peer.ID) string {
+	a.mu.RLock()
+	e := a.entries[p]
+	a.mu.RUnlock()
+	return e.Group
+}
+
 // Reload refreshes the cached authorized_keys data from disk.
 // Called by AdminServer.reloadAuth after peer mutations or auth-reload.
 func (a *CircuitACL) Reload() {
@@ -112,15 +128,21 @@ func (a *CircuitACL) loadFromDisk() {
 // Probation peers (not in authorized_keys) are denied to prevent relay
 // circuit abuse during enrollment mode.
 // If connection gating is disabled or no authKeysPath is configured,
-// all peers are allowed (open relay).
+// all peers are allowed (open relay). In every mode, peers exceeding the
+// reservation throttle are refused until their request rate drops.
 func (a *CircuitACL) AllowReserve(p peer.ID, addr ma.Multiaddr) bool {
-	if !a.enableConnectionGating || a.authKeysPath == "" {
-		return true
+	if a.enableConnectionGating && a.authKeysPath != "" {
+		a.mu.RLock()
+		allowed := a.peers[p]
+		a.mu.RUnlock()
+		if !allowed {
+			return false
+		}
 	}
-	a.mu.RLock()
-	allowed := a.peers[p]
-	a.mu.RUnlock()
-	return allowed
+	if a.reserveThrottle != nil && !a.reserveThrottle.Allow(p) {
+		return false
+	}
+	return true
 }
 
 // AllowConnect controls whether src can establish a data circuit to dest
@@ -30,15 +30,20 @@ import (
 //   - NewStream for STOP: wraps the returned stream with dest peer's data limit.
 //
 // Other protocols (grant-receipt, admin, MOTD, ZKP, etc.) pass through unwrapped (C2/C3).
+//
+// The same two hooks feed RelayAccounting: every HOP/STOP stream, admin
+// peers included, is wrapped in a countedStream beneath the limitedStream.
 type LimitingHost struct {
-	host    host.Host
-	tracker *BudgetTracker
-	acl     *CircuitACL
+	host       host.Host
+	tracker    *BudgetTracker // nil = no budget enforcement (accounting only)
+	acl        *CircuitACL
+	accounting *RelayAccounting // nil = no per-peer accounting
 }
 
 // NewLimitingHost creates a host wrapper that enforces per-peer data budgets
 // on relay circuit streams. Pass the returned host to relayv2.New() instead
-// of the raw host.
+// of the raw host. tracker may be nil when grants are not configured, in
+// which case streams are only counted (see SetAccounting).
 func NewLimitingHost(h host.Host, tracker *BudgetTracker, acl *CircuitACL) *LimitingHost {
 	return &LimitingHost{
 		host:    h,
@@ -47,6 +52,12 @@ func NewLimitingHost(h host.Host, tracker *BudgetTracker, acl *CircuitACL) *Limi
 	}
 }
 
+// SetAccounting wires per-peer byte accounting for relay circuit streams.
+// Must be called before the host is passed to relayv2.New().
+func (lh *LimitingHost) SetAccounting(a *RelayAccounting) {
+	lh.accounting = a
+}
+
 // --- host.Host interface implementation ---
 // All methods delegate to the inner host except SetStreamHandler,
 // SetStreamHandlerMatch, and NewStream.
@@ -99,11 +110,6 @@ func (lh *LimitingHost) NewStream(ctx context.Context, p peer.ID, pids ...protoc
 		return s, nil
 	}
 
-	// Admin peers bypass entirely (SEC4).
-	if lh.acl != nil && lh.acl.IsAdmin(p) {
-		return s, nil
-	}
-
 	return lh.wrapStreamForPeer(s, p), nil
 }
 
@@ -111,23 +117,27 @@ func (lh *LimitingHost) NewStream(ctx context.Context, p peer.ID, pids ...protoc
 // with the source peer's data budget before passing to the relay.
 func (lh *LimitingHost) wrapHopHandler(handler network.StreamHandler) network.StreamHandler {
 	return func(s network.Stream) {
-		p := s.Conn().RemotePeer()
-
-		// Admin peers bypass entirely (SEC4).
-		if lh.acl != nil && lh.acl.IsAdmin(p) {
-			handler(s)
-			return
-		}
-
-		handler(lh.wrapStreamForPeer(s, p))
+		handler(lh.wrapStreamForPeer(s, s.Conn().RemotePeer()))
 	}
 }
 
-// wrapStreamForPeer creates a limitedStream for the given peer.
+// wrapStreamForPeer counts the stream for accounting, then wraps it in a
+// limitedStream for the given peer.
 //
+// Admin peers: counted, never limited (SEC4).
 // Grant peers: cumulative budget from BudgetTracker (shared across circuits).
 // Non-grant peers: per-circuit budget = relay's default session_data_limit (I10).
 func (lh *LimitingHost) wrapStreamForPeer(s network.Stream, p peer.ID) network.Stream {
+	if lh.accounting != nil {
+		s = newCountedStream(s, p, lh.accounting)
+	}
+	if lh.tracker == nil {
+		return s
+	}
+	// Admin peers bypass budgets entirely (SEC4).
+	if lh.acl != nil && lh.acl.IsAdmin(p) {
+		return s
+	}
 	if lh.tracker.HasBudget(p) {
 		// Grant peer: use cumulative tracker.
 		return newLimitedStreamCumulative(s, p, lh.tracker)
@@ -4,6 +4,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -44,6 +45,7 @@ const (
 	PeerArrived    byte = 0x02
 	GroupComplete  byte = 0x03
 	StatusTimeout  byte = 0x04
+	StatusRevoked  byte = 0x05 // invite code was revoked by the relay operator
 	WaitingMarker  byte = 0xFF
 )
 
@@ -175,6 +177,12 @@ func (ph *PairingHandler) HandleStream(s network.Stream) (peer.ID, string) {
 
 	// Look up the token by hash and atomically claim it (InProgress flag).
 	group, idx, rawToken, err := ph.Store.ValidateForPAKE(tokenHash)
+	if errors.Is(err, ErrTokenRevoked) {
+		slog.Warn("pairing: revoked code presented", "peer", short)
+		ph.recordPairing("failure")
+		s.Write([]byte{StatusRevoked})
+		return "", ""
+	}
 	if err != nil {
 		slog.Warn("pairing: token lookup failed", "peer", short)
 		ph.recordPairing("failure")
@@ -350,10 +358,12 @@ func (ph *PairingHandler) verifyMacaroon(m *macaroon.Macaroon, groupID string) e
 	if err != nil {
 		return fmt.Errorf("failed to get root key: %w", err)
 	}
+	// Checking expiry against a slightly earlier "now" applies the same
+	// clock-skew tolerance as token expiry.
 	verifier := macaroon.DefaultVerifier(macaroon.VerifyContext{
 		Group:  groupID,
 		Action: "invite",
-		Now:    time.Now(),
+		Now:    time.Now().Add(-ph.Store.ClockSkewTolerance()),
 	})
 	return m.Verify(rootKey, verifier)
 }
@@ -201,11 +201,16 @@ var invitePaths = map[string]bool{
 }
 
 // isInvitePath checks if the path matches an invite endpoint.
-// Exact match for /v1/pair, plus /v1/pair/{id} for DELETE (revoke).
+// Exact match for /v1/pair, plus /v1/pair/{id} and /v1/pair/code/{hash}
+// for DELETE (revoke).
 func isInvitePath(path string) bool {
 	if invitePaths[path] {
 		return true
 	}
+	if strings.HasPrefix(path, "/v1/pair/code/") {
+		remainder := path[len("/v1/pair/code/"):]
+		return len(remainder) > 0 && !strings.Contains(remainder, "/")
+	}
 	// Allow /v1/pair/{id} where {id} is a hex group ID (16 chars).
 	// Only strip one path segment, no deeper nesting allowed.
 	if strings.HasPrefix(path, "/v1/pair/") {
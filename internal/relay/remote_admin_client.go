@@ -254,6 +254,8 @@ func (c *RemoteAdminClient) ListGroups() ([]GroupInfo, error) {
 		ExpiresAt string     `json:"expires_at"`
 		Total     int        `json:"total"`
 		Used      int        `json:"used"`
+		Revoked   int        `json:"revoked"`
+		Void      int        `json:"void,omitempty"`
 		Peers     []peerJSON `json:"peers"`
 	}
 
@@ -269,6 +271,8 @@ func (c *RemoteAdminClient) ListGroups() ([]GroupInfo, error) {
 			Namespace: g.Namespace,
 			Total:     g.Total,
 			Used:      g.Used,
+			Revoked:   g.Revoked,
+			Void:      g.Void,
 		}
 		if g.ExpiresAt != "" {
 			if t, err := parseTimeStr(g.ExpiresAt); err == nil {
@@ -291,6 +295,23 @@ func (c *RemoteAdminClient) RevokeGroup(id string) error {
 	return nil
 }
 
+// RevokeCode revokes a single pairing code by the hex SHA-256 of its token.
+// Returns the ID of the group the code belonged to.
+func (c *RemoteAdminClient) RevokeCode(tokenHashHex string) (string, error) {
+	data, status, err := c.do("DELETE", "/v1/pair/code/"+tokenHashHex, nil)
+	if err != nil {
+		return "", err
+	}
+	if status >= 400 {
+		return "", parseAdminError(data, status)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp["group_id"], nil
+}
+
 // ListPeers returns all authorized peers from the relay's authorized_keys.
 func (c *RemoteAdminClient) ListPeers() ([]AuthorizedPeerInfo, error) {
 	data, status, err := c.do("GET", "/v1/peers", nil)
@@ -535,6 +556,26 @@ func (c *RemoteAdminClient) RelayGrants() ([]RelayGrantInfo, error) {
 	return result, nil
 }
 
+// RelayStats returns bytes relayed per peer and per group.
+func (c *RemoteAdminClient) RelayStats(withDays bool) (*RelayStats, error) {
+	path := "/v1/relay-stats"
+	if withDays {
+		path += "?days=1"
+	}
+	data, status, err := c.do("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 400 {
+		return nil, parseAdminError(data, status)
+	}
+	var result RelayStats
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
 // RelayRevoke revokes a relay data grant and terminates active circuits.
 func (c *RemoteAdminClient) RelayRevoke(peerID string) error {
 	reqBody, _ := json.Marshal(map[string]string{"peer_id": peerID})
@@ -0,0 +1,123 @@
+package relay
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+// DefaultMaxReservationsPerMinute is the per-peer reservation rate limit
+// used when security.max_reservations_per_minute is unset. Clients refresh
+// their reservation every few minutes, and a network change may add one or
+// two more, so 10/min leaves a wide margin for legitimate traffic while
+// stopping a peer that reserves and drops in a tight loop.
+const DefaultMaxReservationsPerMinute = 10
+
+// reservationWindow is the sliding window for reservation rate tracking.
+const reservationWindow = time.Minute
+
+// ReservationThrottle tracks per-peer reservation requests over a sliding
+// one-minute window and refuses peers that exceed the limit. This protects
+// the relay from authorized-but-misbehaving peers that churn reservations.
+//
+// Refused requests are not recorded, so a throttled peer is released as soon
+// as its older accepted requests age out of the window.
+type ReservationThrottle struct {
+	limit int
+	clock clock.Clock // injectable for tests
+
+	mu        sync.Mutex
+	requests  map[peer.ID][]time.Time // accepted request times within the window
+	throttled map[peer.ID]int         // peers currently throttled → refused count
+	lastSweep time.Time
+}
+
+// NewReservationThrottle creates a throttle allowing limit reservations per
+// peer per minute. limit <= 0 uses DefaultMaxReservationsPerMinute.
+func NewReservationThrottle(limit int) *ReservationThrottle {
+	if limit <= 0 {
+		limit = DefaultMaxReservationsPerMinute
+	}
+	return &ReservationThrottle{
+		limit:     limit,
+		clock:     clock.Real,
+		requests:  make(map[peer.ID][]time.Time),
+		throttled: make(map[peer.ID]int),
+	}
+}
+
+// Limit returns the configured reservations-per-minute limit.
+func (t *ReservationThrottle) Limit() int {
+	return t.limit
+}
+
+// Allow records a reservation request from p and reports whether it is
+// within the limit.
+func (t *ReservationThrottle) Allow(p peer.ID) bool {
+	now := t.clock.Now()
+	cutoff := now.Add(-reservationWindow)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastSweep) >= reservationWindow {
+		t.sweepLocked(cutoff)
+		t.lastSweep = now
+	}
+
+	recent := pruneBefore(t.requests[p], cutoff)
+
+	if len(recent) >= t.limit {
+		t.requests[p] = recent
+		refused, already := t.throttled[p]
+		t.throttled[p] = refused + 1
+		if !already {
+			slog.Warn("relay: reservation throttled (churn)",
+				"peer", shortPeerID(p), "limit_per_minute", t.limit)
+		}
+		return false
+	}
+
+	if refused, ok := t.throttled[p]; ok {
+		delete(t.throttled, p)
+		slog.Info("relay: reservation throttle released",
+			"peer", shortPeerID(p), "refused", refused)
+	}
+	t.requests[p] = append(recent, now)
+	return true
+}
+
+// IsThrottled reports whether p's most recent request was refused.
+func (t *ReservationThrottle) IsThrottled(p peer.ID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.throttled[p]
+	return ok
+}
+
+// sweepLocked drops peers with no requests inside the window so the map
+// doesn't grow with every peer ever seen. Caller must hold t.mu.
+func (t *ReservationThrottle) sweepLocked(cutoff time.Time) {
+	for p, times := range t.requests {
+		if recent := pruneBefore(times, cutoff); len(recent) == 0 {
+			delete(t.requests, p)
+			delete(t.throttled, p)
+		} else {
+			t.requests[p] = recent
+		}
+	}
+}
+
+// pruneBefore returns the suffix of times at or after cutoff. times is
+// sorted ascending because requests are appended in arrival order.
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,129 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+func TestReservationThrottle_EngagesAndReleases(t *testing.T) {
+	p := generateTestPeerID(t)
+	other := generateTestPeerID(t)
+
+	start := time.Unix(1_700_000_000, 0)
+	fake := clock.NewFake(start)
+	th := NewReservationThrottle(3)
+	th.clock = fake
+
+	for i := 0; i < 3; i++ {
+		if !th.Allow(p) {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+		fake.Advance(time.Second)
+	}
+	if th.Allow(p) {
+		t.Fatal("4th request within a minute should be throttled")
+	}
+	if !th.IsThrottled(p) {
+		t.Error("peer should be reported as throttled")
+	}
+	// Other peers are unaffected.
+	if !th.Allow(other) {
+		t.Error("throttle must be per-peer")
+	}
+
+	// Hammering while throttled doesn't extend the penalty: refused
+	// requests aren't recorded.
+	for i := 0; i < 20; i++ {
+		fake.Advance(time.Second)
+		th.Allow(p)
+	}
+
+	// Once the first accepted request is older than a minute, the peer is released.
+	fake.Advance(start.Add(time.Minute + time.Millisecond).Sub(fake.Now()))
+	if !th.Allow(p) {
+		t.Fatal("peer should be released after the window slides")
+	}
+	if th.IsThrottled(p) {
+		t.Error("peer should no longer be throttled")
+	}
+}
+
+func TestReservationThrottle_KeepaliveWellUnderDefault(t *testing.T) {
+	p := generateTestPeerID(t)
+	fake := clock.NewFake(time.Unix(1_700_000_000, 0))
+	th := NewReservationThrottle(0)
+	th.clock = fake
+
+	if th.Limit() != DefaultMaxReservationsPerMinute {
+		t.Fatalf("Limit() = %d, want default %d", th.Limit(), DefaultMaxReservationsPerMinute)
+	}
+
+	// A client refreshing every 2 minutes, plus a burst of 3 quick
+	// re-reservations after a network change, is never throttled.
+	for i := 0; i < 30; i++ {
+		if !th.Allow(p) {
+			t.Fatalf("keepalive %d throttled", i)
+		}
+		fake.Advance(2 * time.Minute)
+	}
+	for i := 0; i < 3; i++ {
+		if !th.Allow(p) {
+			t.Fatalf("reconnect re-reservation %d throttled", i)
+		}
+		fake.Advance(2 * time.Second)
+	}
+}
+
+func TestReservationThrottle_SweepsIdlePeers(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1_700_000_000, 0))
+	th := NewReservationThrottle(5)
+	th.clock = fake
+
+	for i := 0; i < 10; i++ {
+		th.Allow(generateTestPeerID(t))
+	}
+	fake.Advance(2 * time.Minute)
+	th.Allow(generateTestPeerID(t))
+
+	th.mu.Lock()
+	n := len(th.requests)
+	th.mu.Unlock()
+	if n != 1 {
+		t.Errorf("tracked peers = %d, want 1 after sweep", n)
+	}
+}
+
+func TestCircuitACL_AllowReserve_Throttled(t *testing.T) {
+	p := generateTestPeerID(t)
+	authPath := setupAuthKeys(t, p.String())
+	acl := NewCircuitACL(authPath, false, true, nil)
+
+	fake := clock.NewFake(time.Unix(1_700_000_000, 0))
+	th := NewReservationThrottle(2)
+	th.clock = fake
+	acl.SetReservationThrottle(th)
+
+	addr, _ := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/1234")
+	if !acl.AllowReserve(p, addr) || !acl.AllowReserve(p, addr) {
+		t.Fatal("first two reservations should be allowed")
+	}
+	if acl.AllowReserve(p, addr) {
+		t.Error("third reservation within a minute should be refused")
+	}
+
+	// Unauthorized peers are refused before touching the throttle.
+	stranger := generateTestPeerID(t)
+	if acl.AllowReserve(stranger, addr) {
+		t.Error("unauthorized peer should be refused")
+	}
+	th.mu.Lock()
+	_, tracked := th.requests[stranger]
+	th.mu.Unlock()
+	if tracked {
+		t.Error("unauthorized peer should not be tracked by the throttle")
+	}
+}
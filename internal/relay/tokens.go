@@ -37,6 +37,19 @@ var (
 	ErrStoreCapacity  = errors.New("token store at capacity")
 )
 
+// ErrTokenRevoked is returned for a code the relay operator revoked before
+// it was redeemed. Unlike the other token errors it is deliberately specific:
+// only someone holding the code can trigger it, so naming the reason leaks
+// nothing an attacker could use, and it tells an honest joiner to ask for a
+// fresh code instead of retrying.
+var ErrTokenRevoked = errors.New("code revoked")
+
+// Errors returned by the revoke operations.
+var (
+	ErrCodeNotFound    = errors.New("code not found")
+	ErrNothingToRevoke = errors.New("nothing to revoke: no outstanding codes")
+)
+
 // CodeSlot represents a single pairing code within a group.
 type CodeSlot struct {
 	TokenHash  [32]byte  // SHA-256 of raw token
@@ -45,6 +58,7 @@ type CodeSlot struct {
 	PeerID     peer.ID   // filled after use
 	Name       string    // peer's friendly name
 	UsedAt     time.Time // zero = unused
+	RevokedAt  time.Time // zero = not revoked
 	InProgress   bool      // true while PAKE handshake is in flight (prevents TOCTOU)
 	InProgressAt time.Time // when InProgress was set (for timeout cleanup)
 	Attempts     int       // failed attempts (max 3)
@@ -79,22 +93,35 @@ type GroupInfo struct {
 	ExpiresAt time.Time
 	Total     int
 	Used      int
+	Revoked   int
+	Void      int // unused codes restored after a relay restart without their raw token
 	Peers     []PeerInfo
 }
 
+// Outstanding returns how many codes in the group can still be redeemed
+// (neither used, revoked nor void). Expiry is not considered.
+func (g GroupInfo) Outstanding() int {
+	return g.Total - g.Used - g.Revoked - g.Void
+}
+
 // hashEntry maps a token hash to its group and slot index for O(1) lookup.
 type hashEntry struct {
 	groupID string
 	slotIdx int
 }
 
-// TokenStore manages in-memory pairing tokens for the relay.
-// All tokens are lost on relay restart (by design).
+// TokenStore manages pairing tokens for the relay. Raw tokens are kept in
+// memory only. A store created with NewPersistentTokenStore also writes token
+// hashes with their redemption and revocation state to disk so those survive
+// a relay restart; one from NewTokenStore is memory-only.
 type TokenStore struct {
 	mu        sync.RWMutex
 	groups    map[string]*PairingGroup
 	hashIndex map[[32]byte]hashEntry // token hash -> (group, slot) for O(1) lookup
 	maxGroups int                    // 0 = unlimited (default 10000)
+	clockSkew time.Duration          // grace past ExpiresAt (0 = none)
+	path      string                 // persistence file ("" = memory only)
+	saveMu    sync.Mutex             // serializes snapshot + write in persist
 }
 
 // DefaultMaxGroups is the default cap on total pairing groups in memory.
@@ -120,6 +147,28 @@ func NewTokenStoreWithCapacity(maxGroups int) *TokenStore {
 	}
 }
 
+// SetClockSkewTolerance sets how long past its expiry an invite is still
+// accepted. Also applied to macaroon expires caveats checked during pairing,
+// which may have been minted on a machine whose clock disagrees with ours.
+func (ts *TokenStore) SetClockSkewTolerance(d time.Duration) {
+	ts.mu.Lock()
+	ts.clockSkew = d
+	ts.mu.Unlock()
+}
+
+// ClockSkewTolerance returns the tolerance set by SetClockSkewTolerance.
+func (ts *TokenStore) ClockSkewTolerance() time.Duration {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.clockSkew
+}
+
+// expiredAt reports whether group has expired at now, after the skew
+// tolerance. Callers must hold ts.mu or have read clockSkew under it.
+func expiredAt(group *PairingGroup, now time.Time, skew time.Duration) bool {
+	return now.After(group.ExpiresAt.Add(skew))
+}
+
 // CreateGroup generates a pairing group with count codes using the default 16-byte tokens.
 // Returns the raw tokens (caller encodes into invite codes) and the group ID.
 func (ts *TokenStore) CreateGroup(count int, ttl time.Duration, ns string, peerTTL time.Duration, createdBy peer.ID) (tokens [][]byte, groupID string, err error) {
@@ -190,7 +239,7 @@ func (ts *TokenStore) CreateGroupWithTokenSize(count int, ttl time.Duration, ns
 	if maxGroupsPerPeer > 0 && createdBy != "" {
 		peerCount := 0
 		for _, g := range ts.groups {
-			if g.CreatedBy == createdBy && now.Before(g.ExpiresAt) {
+			if g.CreatedBy == createdBy && now.Before(g.ExpiresAt) && !g.closedByRevoke() {
 				peerCount++
 			}
 		}
@@ -211,6 +260,7 @@ func (ts *TokenStore) CreateGroupWithTokenSize(count int, ttl time.Duration, ns
 	}
 	ts.mu.Unlock()
 
+	ts.persist()
 	return tokens, groupID, nil
 }
 
@@ -218,6 +268,14 @@ func (ts *TokenStore) CreateGroupWithTokenSize(count int, ttl time.Duration, ns
 // Returns the group and the slot index on success.
 // Uses the hash index for O(1) lookup instead of scanning all groups.
 func (ts *TokenStore) ValidateAndUse(token []byte, peerID peer.ID, name string) (*PairingGroup, int, error) {
+	group, idx, err := ts.validateAndUse(token, peerID, name)
+	if err == nil {
+		ts.persist()
+	}
+	return group, idx, err
+}
+
+func (ts *TokenStore) validateAndUse(token []byte, peerID peer.ID, name string) (*PairingGroup, int, error) {
 	if len(token) < 8 || len(token) > 32 {
 		return nil, -1, ErrTokenNotFound
 	}
@@ -231,6 +289,7 @@ func (ts *TokenStore) ValidateAndUse(token []byte, peerID peer.ID, name string)
 		return nil, -1, ErrTokenNotFound
 	}
 	group, gok := ts.groups[entry.groupID]
+	skew := ts.clockSkew
 	ts.mu.RUnlock()
 
 	if !gok {
@@ -240,7 +299,7 @@ func (ts *TokenStore) ValidateAndUse(token []byte, peerID peer.ID, name string)
 	group.mu.Lock()
 	defer group.mu.Unlock()
 
-	if time.Now().After(group.ExpiresAt) {
+	if expiredAt(group, time.Now(), skew) {
 		return nil, -1, ErrTokenExpired
 	}
 
@@ -251,6 +310,10 @@ func (ts *TokenStore) ValidateAndUse(token []byte, peerID peer.ID, name string)
 		return nil, -1, ErrTokenNotFound
 	}
 
+	if !slot.RevokedAt.IsZero() {
+		return nil, -1, ErrTokenRevoked
+	}
+
 	if slot.Attempts >= maxAttempts {
 		return nil, -1, ErrTokenBurned
 	}
@@ -296,6 +359,7 @@ func (ts *TokenStore) ValidateForPAKE(tokenHash [32]byte) (*PairingGroup, int, [
 		return nil, -1, nil, ErrTokenNotFound
 	}
 	group, gok := ts.groups[entry.groupID]
+	skew := ts.clockSkew
 	ts.mu.RUnlock()
 
 	if !gok {
@@ -305,7 +369,7 @@ func (ts *TokenStore) ValidateForPAKE(tokenHash [32]byte) (*PairingGroup, int, [
 	group.mu.Lock()
 	defer group.mu.Unlock()
 
-	if time.Now().After(group.ExpiresAt) {
+	if expiredAt(group, time.Now(), skew) {
 		return nil, -1, nil, ErrTokenExpired
 	}
 
@@ -316,6 +380,10 @@ func (ts *TokenStore) ValidateForPAKE(tokenHash [32]byte) (*PairingGroup, int, [
 		return nil, -1, nil, ErrTokenNotFound
 	}
 
+	if !slot.RevokedAt.IsZero() {
+		return nil, -1, nil, ErrTokenRevoked
+	}
+
 	if slot.Attempts >= maxAttempts {
 		return nil, -1, nil, ErrTokenBurned
 	}
@@ -326,7 +394,9 @@ func (ts *TokenStore) ValidateForPAKE(tokenHash [32]byte) (*PairingGroup, int, [
 		return nil, -1, nil, ErrTokenInProgress
 	}
 	if len(slot.RawToken) == 0 {
-		return nil, -1, nil, fmt.Errorf("raw token not available")
+		// Outstanding code restored after a restart: its raw token, the
+		// PAKE salt, was never written to disk.
+		return nil, -1, nil, ErrTokenNotFound
 	}
 
 	// Atomically claim the slot for this PAKE handshake.
@@ -355,6 +425,14 @@ func (ts *TokenStore) ClearInProgress(groupID string, idx int) {
 // MarkUsed marks a code slot as consumed after successful PAKE.
 // Zeroizes the raw token after marking.
 func (ts *TokenStore) MarkUsed(groupID string, idx int, peerID peer.ID, name string) error {
+	if err := ts.markUsed(groupID, idx, peerID, name); err != nil {
+		return err
+	}
+	ts.persist()
+	return nil
+}
+
+func (ts *TokenStore) markUsed(groupID string, idx int, peerID peer.ID, name string) error {
 	ts.mu.RLock()
 	group, ok := ts.groups[groupID]
 	ts.mu.RUnlock()
@@ -518,13 +596,21 @@ func (ts *TokenStore) GroupCount(groupID string) int {
 // CleanExpired removes all expired groups and clears stale InProgress flags.
 // Returns how many groups were removed.
 func (ts *TokenStore) CleanExpired() int {
+	removed := ts.cleanExpired()
+	if removed > 0 {
+		ts.persist()
+	}
+	return removed
+}
+
+func (ts *TokenStore) cleanExpired() int {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
 	now := time.Now()
 	removed := 0
 	for id, group := range ts.groups {
-		if now.After(group.ExpiresAt) {
+		if expiredAt(group, now, ts.clockSkew) {
 			// Remove hash index entries for this group's codes.
 			for i := range group.codes {
 				delete(ts.hashIndex, group.codes[i].TokenHash)
@@ -564,6 +650,12 @@ func (ts *TokenStore) List() []GroupInfo {
 			Total:     len(group.codes),
 		}
 		for _, slot := range group.codes {
+			if !slot.RevokedAt.IsZero() {
+				info.Revoked++
+			}
+			if slot.void() {
+				info.Void++
+			}
 			if !slot.UsedAt.IsZero() {
 				info.Used++
 				info.Peers = append(info.Peers, PeerInfo{
@@ -578,7 +670,8 @@ func (ts *TokenStore) List() []GroupInfo {
 	return infos
 }
 
-// ActiveGroupCount returns the number of non-expired groups.
+// ActiveGroupCount returns the number of non-expired groups, not counting
+// groups whose remaining codes were all revoked.
 func (ts *TokenStore) ActiveGroupCount() int {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
@@ -586,14 +679,15 @@ func (ts *TokenStore) ActiveGroupCount() int {
 	now := time.Now()
 	count := 0
 	for _, group := range ts.groups {
-		if now.Before(group.ExpiresAt) {
+		if now.Before(group.ExpiresAt) && !group.closedByRevoke() {
 			count++
 		}
 	}
 	return count
 }
 
-// AllGroupsUsed returns true if every code in every active group has been used.
+// AllGroupsUsed returns true if every code in every active group has been
+// used or revoked, or is void.
 // Used to auto-disable enrollment mode when all invites are consumed.
 func (ts *TokenStore) AllGroupsUsed() bool {
 	ts.mu.RLock()
@@ -608,7 +702,7 @@ func (ts *TokenStore) AllGroupsUsed() bool {
 		activeCount++
 		group.mu.Lock()
 		for _, slot := range group.codes {
-			if slot.UsedAt.IsZero() {
+			if slot.UsedAt.IsZero() && slot.RevokedAt.IsZero() && !slot.void() {
 				group.mu.Unlock()
 				return false
 			}
@@ -637,26 +731,108 @@ func (ts *TokenStore) ActiveGroupCountByPeer(peerID peer.ID) int {
 	now := time.Now()
 	count := 0
 	for _, group := range ts.groups {
-		if group.CreatedBy == peerID && now.Before(group.ExpiresAt) {
+		if group.CreatedBy == peerID && now.Before(group.ExpiresAt) && !group.closedByRevoke() {
 			count++
 		}
 	}
 	return count
 }
 
-// Revoke removes a pairing group by ID.
+// Revoke invalidates every outstanding code in a pairing group. Codes stay
+// indexed until the group expires so a later join attempt gets
+// ErrTokenRevoked rather than a generic failure; already redeemed codes and
+// the peers they admitted are untouched.
 func (ts *TokenStore) Revoke(groupID string) error {
-	ts.mu.Lock()
-	defer ts.mu.Unlock()
-
+	ts.mu.RLock()
 	group, ok := ts.groups[groupID]
+	ts.mu.RUnlock()
 	if !ok {
 		return ErrGroupNotFound
 	}
-	// Remove hash index entries for this group's codes.
+
+	group.mu.Lock()
+	now := time.Now()
+	revoked := 0
 	for i := range group.codes {
-		delete(ts.hashIndex, group.codes[i].TokenHash)
+		if revokeSlot(&group.codes[i], now) {
+			revoked++
+		}
+	}
+	group.mu.Unlock()
+
+	if revoked == 0 {
+		return ErrNothingToRevoke
 	}
-	delete(ts.groups, groupID)
+	ts.persist()
 	return nil
 }
+
+// RevokeCode invalidates the single code with the given token hash and
+// returns the ID of the group it belongs to.
+func (ts *TokenStore) RevokeCode(tokenHash [32]byte) (string, error) {
+	ts.mu.RLock()
+	entry, ok := ts.hashIndex[tokenHash]
+	var group *PairingGroup
+	if ok {
+		group, ok = ts.groups[entry.groupID]
+	}
+	ts.mu.RUnlock()
+	if !ok {
+		return "", ErrCodeNotFound
+	}
+
+	group.mu.Lock()
+	done := revokeSlot(&group.codes[entry.slotIdx], time.Now())
+	group.mu.Unlock()
+
+	if !done {
+		return entry.groupID, ErrNothingToRevoke
+	}
+	ts.persist()
+	return entry.groupID, nil
+}
+
+// GroupForCode returns the ID of the group holding the code with the given
+// token hash, or "" if the relay does not know it.
+func (ts *TokenStore) GroupForCode(tokenHash [32]byte) string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.hashIndex[tokenHash].groupID
+}
+
+// revokeSlot marks an unredeemed slot revoked and drops its raw token.
+// Reports whether anything changed. Caller must hold the group lock.
+func revokeSlot(slot *CodeSlot, now time.Time) bool {
+	if !slot.UsedAt.IsZero() || !slot.RevokedAt.IsZero() {
+		return false
+	}
+	slot.RevokedAt = now
+	for j := range slot.RawToken {
+		slot.RawToken[j] = 0
+	}
+	slot.RawToken = nil
+	return true
+}
+
+// void reports whether an unused, unrevoked slot has no raw token: it was
+// restored after a relay restart, and the token was never written to disk,
+// so the code can no longer be redeemed over PAKE.
+func (slot *CodeSlot) void() bool {
+	return slot.UsedAt.IsZero() && slot.RevokedAt.IsZero() && len(slot.RawToken) == 0
+}
+
+// closedByRevoke reports whether revocation left the group with no
+// redeemable codes, so it no longer counts as an active invite.
+func (g *PairingGroup) closedByRevoke() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	revoked := false
+	for _, slot := range g.codes {
+		if !slot.RevokedAt.IsZero() {
+			revoked = true
+		} else if slot.UsedAt.IsZero() {
+			return false
+		}
+	}
+	return revoked
+}
@@ -0,0 +1,188 @@
+package relay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/pkg/plugin"
+)
+
+// PairingStateFile returns the conventional pairing state file path for a relay config dir.
+func PairingStateFile(configDir string) string {
+	return filepath.Join(configDir, "relay-pairing.json")
+}
+
+// tokenStoreFile is the on-disk form of a TokenStore.
+type tokenStoreFile struct {
+	Version int              `json:"version"`
+	Groups  []persistedGroup `json:"groups"`
+}
+
+type persistedGroup struct {
+	ID        string          `json:"id"`
+	Namespace string          `json:"namespace,omitempty"`
+	CreatedBy string          `json:"created_by,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	PeerTTL   time.Duration   `json:"peer_ttl,omitempty"`
+	Codes     []persistedCode `json:"codes"`
+}
+
+// persistedCode is one code slot. Only the token hash is stored, never the
+// raw token: codes stay memory-only secrets, and the hash is all a restarted
+// relay needs to keep rejecting a redeemed or revoked code.
+type persistedCode struct {
+	TokenHash string    `json:"token_hash"`
+	PeerID    string    `json:"peer_id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	UsedAt    time.Time `json:"used_at,omitzero"`
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+}
+
+// NewPersistentTokenStore creates a token store backed by the file at path.
+// Groups saved by a previous run are restored, including redeemed and revoked
+// codes, so a revocation stays in force across restarts. Codes still
+// outstanding at the restart come back without their raw token and can no
+// longer be redeemed over PAKE, as before persistence existed; List counts
+// them as Void rather than outstanding.
+//
+// A missing file is not an error. A file that cannot be read or parsed is:
+// starting empty would drop every revocation, so the caller must not run
+// with a nil store, and the bad file is left untouched for the operator.
+func NewPersistentTokenStore(path string) (*TokenStore, error) {
+	ts := NewTokenStore()
+	ts.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pairing state: %w", err)
+	}
+	var f tokenStoreFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse pairing state %s: %w", path, err)
+	}
+
+	for _, pg := range f.Groups {
+		group, err := restoreGroup(pg)
+		if err != nil {
+			slog.Warn("pairing: skipping unreadable group in state file", "group", pg.ID, "err", err)
+			continue
+		}
+		ts.groups[group.ID] = group
+		for i := range group.codes {
+			ts.hashIndex[group.codes[i].TokenHash] = hashEntry{groupID: group.ID, slotIdx: i}
+		}
+	}
+	// Rewrite at once so a file from an older version that still carried
+	// raw tokens is scrubbed.
+	ts.persist()
+	return ts, nil
+}
+
+func restoreGroup(pg persistedGroup) (*PairingGroup, error) {
+	if pg.ID == "" {
+		return nil, fmt.Errorf("missing group ID")
+	}
+	group := &PairingGroup{
+		ID:        pg.ID,
+		Namespace: pg.Namespace,
+		CreatedAt: pg.CreatedAt,
+		ExpiresAt: pg.ExpiresAt,
+		PeerTTL:   pg.PeerTTL,
+		codes:     make([]CodeSlot, len(pg.Codes)),
+	}
+	if pg.CreatedBy != "" {
+		pid, err := peer.Decode(pg.CreatedBy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creator: %w", err)
+		}
+		group.CreatedBy = pid
+	}
+	for i, pc := range pg.Codes {
+		hash, err := hex.DecodeString(pc.TokenHash)
+		if err != nil || len(hash) != 32 {
+			return nil, fmt.Errorf("code %d: invalid token hash", i)
+		}
+		slot := CodeSlot{
+			Name:      pc.Name,
+			UsedAt:    pc.UsedAt,
+			RevokedAt: pc.RevokedAt,
+		}
+		copy(slot.TokenHash[:], hash)
+		if pc.PeerID != "" {
+			pid, err := peer.Decode(pc.PeerID)
+			if err != nil {
+				return nil, fmt.Errorf("code %d: invalid peer ID: %w", i, err)
+			}
+			slot.PeerID = pid
+		}
+		group.codes[i] = slot
+	}
+	return group, nil
+}
+
+// persist writes the store to its state file. It is called only when a code
+// is created, redeemed, revoked or expired; attempt counters, HMAC proofs
+// and deposit links are runtime state and never trigger a write. The
+// snapshot is taken under the locks and encoded and written after they are
+// released. Failures are logged, not returned, so a full disk never blocks
+// pairing. Must be called without ts.mu or any group lock held.
+func (ts *TokenStore) persist() {
+	if ts.path == "" {
+		return
+	}
+	ts.saveMu.Lock()
+	defer ts.saveMu.Unlock()
+
+	f := tokenStoreFile{Version: 1, Groups: []persistedGroup{}}
+	ts.mu.RLock()
+	for _, group := range ts.groups {
+		group.mu.Lock()
+		pg := persistedGroup{
+			ID:        group.ID,
+			Namespace: group.Namespace,
+			CreatedAt: group.CreatedAt,
+			ExpiresAt: group.ExpiresAt,
+			PeerTTL:   group.PeerTTL,
+			Codes:     make([]persistedCode, len(group.codes)),
+		}
+		if group.CreatedBy != "" {
+			pg.CreatedBy = group.CreatedBy.String()
+		}
+		for i, slot := range group.codes {
+			pc := persistedCode{
+				TokenHash: hex.EncodeToString(slot.TokenHash[:]),
+				Name:      slot.Name,
+				UsedAt:    slot.UsedAt,
+				RevokedAt: slot.RevokedAt,
+			}
+			if slot.PeerID != "" {
+				pc.PeerID = slot.PeerID.String()
+			}
+			pg.Codes[i] = pc
+		}
+		group.mu.Unlock()
+		f.Groups = append(f.Groups, pg)
+	}
+	ts.mu.RUnlock()
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		slog.Error("pairing: failed to encode state", "err", err)
+		return
+	}
+	if err := plugin.AtomicWriteFile(ts.path, data, 0600); err != nil {
+		slog.Error("pairing: failed to write state", "file", ts.path, "err", err)
+	}
+}
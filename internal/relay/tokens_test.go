@@ -1,6 +1,13 @@
 package relay
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -151,6 +158,28 @@ func TestValidateAndUseRejectsExpired(t *testing.T) {
 	}
 }
 
+func TestValidateAndUseClockSkewTolerance(t *testing.T) {
+	ts := NewTokenStore()
+	ts.SetClockSkewTolerance(time.Minute)
+	tokens, _, _ := ts.CreateGroup(2, time.Millisecond, "", 0, "")
+
+	time.Sleep(5 * time.Millisecond)
+
+	// Expired by a few ms, well inside the tolerance.
+	if _, _, err := ts.ValidateAndUse(tokens[0], genPeerID(t), "mum"); err != nil {
+		t.Fatalf("token inside skew tolerance rejected: %v", err)
+	}
+	if removed := ts.CleanExpired(); removed != 0 {
+		t.Errorf("CleanExpired removed %d groups inside skew tolerance", removed)
+	}
+
+	// Beyond the tolerance the token is rejected again.
+	ts.SetClockSkewTolerance(time.Millisecond)
+	if _, _, err := ts.ValidateAndUse(tokens[1], genPeerID(t), "dad"); err == nil {
+		t.Error("should reject token expired beyond skew tolerance")
+	}
+}
+
 func TestValidateAndUseRejectsUnknown(t *testing.T) {
 	ts := NewTokenStore()
 	ts.CreateGroup(1, time.Hour, "", 0, "")
@@ -234,10 +263,16 @@ func TestRevoke(t *testing.T) {
 		t.Fatalf("Revoke: %v", err)
 	}
 
-	// Token should no longer work
+	// Token should no longer work, and say why.
 	_, _, err := ts.ValidateAndUse(tokens[0], genPeerID(t), "mum")
-	if err == nil {
-		t.Error("should reject token from revoked group")
+	if !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateAndUse after revoke: err = %v, want ErrTokenRevoked", err)
+	}
+	if _, _, _, err := ts.ValidateForPAKE(sha256.Sum256(tokens[0])); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateForPAKE after revoke: err = %v, want ErrTokenRevoked", err)
+	}
+	if n := ts.ActiveGroupCount(); n != 0 {
+		t.Errorf("ActiveGroupCount = %d, want 0 after revoke", n)
 	}
 
 	// Second revoke should fail
@@ -246,6 +281,154 @@ func TestRevoke(t *testing.T) {
 	}
 }
 
+func TestRevokeCode(t *testing.T) {
+	ts := NewTokenStore()
+	tokens, groupID, _ := ts.CreateGroup(3, time.Hour, "", 0, "")
+
+	// Redeem the first code, revoke the second, leave the third.
+	if _, _, err := ts.ValidateAndUse(tokens[0], genPeerID(t), "mum"); err != nil {
+		t.Fatalf("ValidateAndUse: %v", err)
+	}
+	got, err := ts.RevokeCode(sha256.Sum256(tokens[1]))
+	if err != nil {
+		t.Fatalf("RevokeCode: %v", err)
+	}
+	if got != groupID {
+		t.Errorf("RevokeCode group = %q, want %q", got, groupID)
+	}
+
+	if _, _, err := ts.ValidateAndUse(tokens[1], genPeerID(t), "dad"); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("revoked code: err = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := ts.RevokeCode(sha256.Sum256(tokens[1])); !errors.Is(err, ErrNothingToRevoke) {
+		t.Errorf("second RevokeCode: err = %v, want ErrNothingToRevoke", err)
+	}
+	if _, err := ts.RevokeCode(sha256.Sum256(tokens[0])); !errors.Is(err, ErrNothingToRevoke) {
+		t.Errorf("RevokeCode on used code: err = %v, want ErrNothingToRevoke", err)
+	}
+	if _, err := ts.RevokeCode(sha256.Sum256([]byte("unknown"))); !errors.Is(err, ErrCodeNotFound) {
+		t.Errorf("RevokeCode unknown: err = %v, want ErrCodeNotFound", err)
+	}
+
+	info := ts.List()[0]
+	if info.Used != 1 || info.Revoked != 1 || info.Outstanding() != 1 {
+		t.Errorf("List = used %d revoked %d outstanding %d, want 1/1/1", info.Used, info.Revoked, info.Outstanding())
+	}
+	if ts.ActiveGroupCount() != 1 {
+		t.Error("group with an outstanding code should stay active")
+	}
+
+	// The untouched code still works.
+	if _, _, err := ts.ValidateAndUse(tokens[2], genPeerID(t), "sis"); err != nil {
+		t.Errorf("remaining code: %v", err)
+	}
+}
+
+func TestPersistentTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay-pairing.json")
+	creator := genPeerID(t)
+	joiner := genPeerID(t)
+
+	ts, err := NewPersistentTokenStore(path)
+	if err != nil {
+		t.Fatalf("NewPersistentTokenStore (missing file): %v", err)
+	}
+	tokens, groupID, err := ts.CreateGroup(3, time.Hour, "home", 24*time.Hour, creator)
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if _, _, err := ts.ValidateAndUse(tokens[0], joiner, "mum"); err != nil {
+		t.Fatalf("ValidateAndUse: %v", err)
+	}
+	if _, err := ts.RevokeCode(sha256.Sum256(tokens[1])); err != nil {
+		t.Fatalf("RevokeCode: %v", err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("state file not written: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("state file mode = %o, want 600", perm)
+	}
+
+	// Simulate a relay restart.
+	ts2, err := NewPersistentTokenStore(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if ts2.GroupCreator(groupID) != creator {
+		t.Error("creator not restored")
+	}
+	if _, _, _, err := ts2.ValidateForPAKE(sha256.Sum256(tokens[1])); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("revoked code after restart: err = %v, want ErrTokenRevoked", err)
+	}
+	if _, _, _, err := ts2.ValidateForPAKE(sha256.Sum256(tokens[0])); !errors.Is(err, ErrTokenUsed) {
+		t.Errorf("used code after restart: err = %v, want ErrTokenUsed", err)
+	}
+	// Raw tokens are never written, so an outstanding code does not
+	// survive a restart for PAKE.
+	if _, _, _, err := ts2.ValidateForPAKE(sha256.Sum256(tokens[2])); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("outstanding code after restart: err = %v, want ErrTokenNotFound", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tok := range tokens {
+		if bytes.Contains(data, []byte(hex.EncodeToString(tok))) || bytes.Contains(data, []byte(base64.StdEncoding.EncodeToString(tok))) {
+			t.Errorf("state file contains raw token %d", i)
+		}
+	}
+	peers := ts2.GetGroupPeers(groupID, -1)
+	if len(peers) != 1 || peers[0].PeerID != joiner || peers[0].Name != "mum" {
+		t.Errorf("joined peers after restart = %+v", peers)
+	}
+	infos := ts2.List()
+	if len(infos) != 1 || infos[0].Used != 1 || infos[0].Revoked != 1 || infos[0].Void != 1 || infos[0].Outstanding() != 0 {
+		t.Errorf("List after restart = %+v, want 1 used, 1 revoked, 1 void, none outstanding", infos)
+	}
+}
+
+func TestPersistentTokenStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay-pairing.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := NewPersistentTokenStore(path)
+	if err == nil {
+		t.Error("expected parse error")
+	}
+	if ts != nil {
+		t.Error("no store should be returned for an unreadable state file")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{not json" {
+		t.Error("bad state file was modified")
+	}
+}
+
+func TestPersistentTokenStoreFailedAttemptsDoNotWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay-pairing.json")
+	ts, err := NewPersistentTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, groupID, err := ts.CreateGroup(1, time.Hour, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.RecordFailedAttempt(tokens[0])
+	ts.SetHMACProof(groupID, 0, []byte("proof"))
+	ts.SetDepositID(groupID, "dep")
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("state file rewritten by runtime-only updates")
+	}
+}
+
 func TestGetGroupPeersExcludesSelf(t *testing.T) {
 	ts := NewTokenStore()
 	tokens, groupID, _ := ts.CreateGroup(3, time.Hour, "", 0, "")
@@ -0,0 +1,92 @@
+package reputation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ReadRecords reads a peer history file and returns its records sorted by
+// peer ID. Unlike NewPeerHistory it reports a missing or unreadable file,
+// which is what a read-only reporting tool needs.
+func ReadRecords(path string) ([]*PeerRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	var byID map[string]*PeerRecord
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	records := make([]*PeerRecord, 0, len(byID))
+	for id, r := range byID {
+		if r == nil {
+			continue
+		}
+		if r.PeerID == "" {
+			r.PeerID = id
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].PeerID < records[j].PeerID })
+	return records, nil
+}
+
+// WriteCSV writes one row per peer. Path types are open-ended, so each type
+// seen in any record gets its own path_<type> column holding that peer's
+// connection count over it.
+func WriteCSV(w io.Writer, records []*PeerRecord) error {
+	typeSet := make(map[string]bool)
+	for _, r := range records {
+		for t := range r.PathTypes {
+			typeSet[t] = true
+		}
+	}
+	pathTypes := make([]string, 0, len(typeSet))
+	for t := range typeSet {
+		pathTypes = append(pathTypes, t)
+	}
+	sort.Strings(pathTypes)
+
+	header := []string{"peer_id", "first_seen", "last_seen", "connection_count", "avg_latency_ms"}
+	for _, t := range pathTypes {
+		header = append(header, "path_"+t)
+	}
+	header = append(header, "introduced_by", "intro_method")
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.PeerID,
+			formatTime(r.FirstSeen),
+			formatTime(r.LastSeen),
+			strconv.Itoa(r.ConnectionCount),
+			strconv.FormatFloat(r.AvgLatencyMs, 'f', 2, 64),
+		}
+		for _, t := range pathTypes {
+			row = append(row, strconv.Itoa(r.PathTypes[t]))
+		}
+		row = append(row, r.IntroducedBy, r.IntroMethod)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatTime renders t as RFC 3339 in UTC, or empty for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
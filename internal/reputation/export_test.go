@@ -0,0 +1,72 @@
+package reputation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadRecordsAndWriteCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peer_history.json")
+	h := NewPeerHistory(path)
+	h.RecordConnection("peer-B", "relay", 40.0)
+	h.RecordConnection("peer-A", "direct", 10.0)
+	h.RecordConnection("peer-A", "relay", 30.0)
+	h.RecordIntroduction("peer-A", "relay-001", "invite")
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadRecords(path)
+	if err != nil {
+		t.Fatalf("ReadRecords: %v", err)
+	}
+	if len(records) != 2 || records[0].PeerID != "peer-A" {
+		t.Fatalf("records not sorted by peer ID: %+v", records)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, records); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header + 2", len(rows))
+	}
+	wantHeader := []string{"peer_id", "first_seen", "last_seen", "connection_count", "avg_latency_ms",
+		"path_direct", "path_relay", "introduced_by", "intro_method"}
+	if len(rows[0]) != len(wantHeader) {
+		t.Fatalf("header = %v, want %v", rows[0], wantHeader)
+	}
+	for i := range wantHeader {
+		if rows[0][i] != wantHeader[i] {
+			t.Errorf("header[%d] = %q, want %q", i, rows[0][i], wantHeader[i])
+		}
+	}
+
+	a := rows[1]
+	if a[0] != "peer-A" || a[3] != "2" || a[4] != "20.00" || a[5] != "1" || a[6] != "1" || a[7] != "relay-001" || a[8] != "invite" {
+		t.Errorf("peer-A row = %v", a)
+	}
+	b := rows[2]
+	if b[0] != "peer-B" || b[5] != "0" || b[6] != "1" || b[7] != "" {
+		t.Errorf("peer-B row = %v", b)
+	}
+}
+
+func TestReadRecordsErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadRecords(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte("{not json"), 0600)
+	if _, err := ReadRecords(bad); err == nil {
+		t.Error("expected error for malformed file")
+	}
+}
@@ -8,20 +8,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 	"time"
 )
 
+// MaxConnectionEvents caps the connection events kept per peer, which
+// bounds the history file. The oldest events are dropped first.
+const MaxConnectionEvents = 100
+
 // PeerRecord holds interaction history for a single peer.
 type PeerRecord struct {
-	PeerID          string         `json:"peer_id"`
-	FirstSeen       time.Time      `json:"first_seen"`
-	LastSeen        time.Time      `json:"last_seen"`
-	ConnectionCount int            `json:"connection_count"`
-	AvgLatencyMs    float64        `json:"avg_latency_ms"`
-	PathTypes       map[string]int `json:"path_types"` // "direct":12, "relay":3
-	IntroducedBy    string         `json:"introduced_by,omitempty"`
-	IntroMethod     string         `json:"intro_method,omitempty"` // "invite", "manual"
+	PeerID          string            `json:"peer_id"`
+	FirstSeen       time.Time         `json:"first_seen"`
+	LastSeen        time.Time         `json:"last_seen"`
+	ConnectionCount int               `json:"connection_count"`
+	AvgLatencyMs    float64           `json:"avg_latency_ms"`
+	PathTypes       map[string]int    `json:"path_types"` // "direct":12, "relay":3
+	IntroducedBy    string            `json:"introduced_by,omitempty"`
+	IntroMethod     string            `json:"intro_method,omitempty"` // "invite", "manual"
+	Events          []ConnectionEvent `json:"events,omitempty"`       // oldest first, at most MaxConnectionEvents
+
+	// DCUtR hole punches with this peer. HolePunchSuccesses > 0 means the
+	// pair has been upgraded from relayed to direct at least once.
+	HolePunchAttempts  int       `json:"hole_punch_attempts,omitempty"`
+	HolePunchSuccesses int       `json:"hole_punch_successes,omitempty"`
+	LastDirectUpgrade  time.Time `json:"last_direct_upgrade,omitzero"`
+	LastHolePunchError string    `json:"last_hole_punch_error,omitempty"`
+}
+
+// ConnectionEvent is one recorded connection to a peer.
+type ConnectionEvent struct {
+	Time      time.Time `json:"time"`
+	PathType  string    `json:"path_type,omitempty"`
+	LatencyMs float64   `json:"latency_ms,omitempty"`
 }
 
 // PeerHistory manages the local interaction history file.
@@ -42,7 +62,8 @@ func NewPeerHistory(path string) *PeerHistory {
 }
 
 // RecordConnection updates connection count, last_seen, path type counts,
-// and running average latency for a peer.
+// and running average latency for a peer, and appends the connection to
+// its event window.
 func (h *PeerHistory) RecordConnection(peerID, pathType string, latencyMs float64) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -57,8 +78,13 @@ func (h *PeerHistory) RecordConnection(peerID, pathType string, latencyMs float6
 		h.records[peerID] = r
 	}
 
-	r.LastSeen = time.Now()
+	now := time.Now()
+	r.LastSeen = now
 	r.ConnectionCount++
+	r.Events = append(r.Events, ConnectionEvent{Time: now, PathType: pathType, LatencyMs: latencyMs})
+	if n := len(r.Events) - MaxConnectionEvents; n > 0 {
+		r.Events = slices.Delete(r.Events, 0, n)
+	}
 
 	if pathType != "" {
 		r.PathTypes[pathType]++
@@ -70,6 +96,28 @@ func (h *PeerHistory) RecordConnection(peerID, pathType string, latencyMs float6
 	}
 }
 
+// RecordHolePunch records the outcome of a DCUtR hole punch with a peer.
+// errMsg is kept for failures so a peer that never upgrades shows why.
+// Only peers that already have a record are updated: a hole punch alone
+// does not start a history.
+func (h *PeerHistory) RecordHolePunch(peerID string, success bool, errMsg string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.records[peerID]
+	if !ok {
+		return
+	}
+
+	r.HolePunchAttempts++
+	if success {
+		r.HolePunchSuccesses++
+		r.LastDirectUpgrade = time.Now()
+	} else {
+		r.LastHolePunchError = errMsg
+	}
+}
+
 // RecordIntroduction records how a peer was introduced.
 func (h *PeerHistory) RecordIntroduction(peerID, introducedBy, method string) {
 	h.mu.Lock()
@@ -98,12 +146,59 @@ func (h *PeerHistory) Get(peerID string) *PeerRecord {
 	if !ok {
 		return nil
 	}
-	copy := *r
-	copy.PathTypes = make(map[string]int, len(r.PathTypes))
+	return r.clone()
+}
+
+// clone returns a deep copy of r.
+func (r *PeerRecord) clone() *PeerRecord {
+	cp := *r
+	cp.PathTypes = make(map[string]int, len(r.PathTypes))
 	for k, v := range r.PathTypes {
-		copy.PathTypes[k] = v
+		cp.PathTypes[k] = v
 	}
-	return &copy
+	cp.Events = slices.Clone(r.Events)
+	return &cp
+}
+
+// EventSummary condenses a window of connection events: how often the peer
+// connected over each path, how often the path flipped, and its latency.
+type EventSummary struct {
+	Connections  int            `json:"connections"`
+	PathTypes    map[string]int `json:"path_types"`
+	PathChanges  int            `json:"path_changes"` // consecutive connections over different paths
+	AvgLatencyMs float64        `json:"avg_latency_ms"`
+	MaxLatencyMs float64        `json:"max_latency_ms"`
+}
+
+// SummarizeEvents summarizes the events recorded at or after since.
+// Events without a latency are left out of the latency figures.
+func SummarizeEvents(events []ConnectionEvent, since time.Time) EventSummary {
+	s := EventSummary{PathTypes: make(map[string]int)}
+	var prevPath string
+	var latencySum float64
+	var latencyN int
+	for _, e := range events {
+		if e.Time.Before(since) {
+			continue
+		}
+		s.Connections++
+		if e.PathType != "" {
+			s.PathTypes[e.PathType]++
+			if prevPath != "" && e.PathType != prevPath {
+				s.PathChanges++
+			}
+			prevPath = e.PathType
+		}
+		if e.LatencyMs > 0 {
+			latencySum += e.LatencyMs
+			latencyN++
+			s.MaxLatencyMs = max(s.MaxLatencyMs, e.LatencyMs)
+		}
+	}
+	if latencyN > 0 {
+		s.AvgLatencyMs = latencySum / float64(latencyN)
+	}
+	return s
 }
 
 // Count returns the number of peers tracked.
@@ -119,12 +214,7 @@ func (h *PeerHistory) AllRecords() map[string]*PeerRecord {
 	defer h.mu.RUnlock()
 	result := make(map[string]*PeerRecord, len(h.records))
 	for id, r := range h.records {
-		cp := *r
-		cp.PathTypes = make(map[string]int, len(r.PathTypes))
-		for k, v := range r.PathTypes {
-			cp.PathTypes[k] = v
-		}
-		result[id] = &cp
+		result[id] = r.clone()
 	}
 	return result
 }
@@ -157,6 +247,16 @@ func (h *PeerHistory) Load() error {
 	if err := json.Unmarshal(data, &records); err != nil {
 		return fmt.Errorf("failed to parse history: %w", err)
 	}
+	for id, r := range records {
+		if r == nil {
+			delete(records, id)
+			continue
+		}
+		migrateRecord(id, r)
+	}
+	if records == nil {
+		records = make(map[string]*PeerRecord)
+	}
 
 	h.mu.Lock()
 	h.records = records
@@ -164,6 +264,21 @@ func (h *PeerHistory) Load() error {
 	return nil
 }
 
+// migrateRecord brings a record loaded from an older or hand-edited file up
+// to date. Files written before connection events were recorded have no
+// events field; the record simply starts its window empty.
+func migrateRecord(id string, r *PeerRecord) {
+	if r.PeerID == "" {
+		r.PeerID = id
+	}
+	if r.PathTypes == nil {
+		r.PathTypes = make(map[string]int)
+	}
+	if n := len(r.Events) - MaxConnectionEvents; n > 0 {
+		r.Events = slices.Delete(r.Events, 0, n)
+	}
+}
+
 // Save writes the history file to disk atomically.
 func (h *PeerHistory) Save() error {
 	h.mu.RLock()
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestPeerHistory_RoundTrip(t *testing.T) {
@@ -48,6 +49,38 @@ func TestPeerHistory_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestPeerHistory_RecordHolePunch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peer_history.json")
+
+	h := NewPeerHistory(path)
+	h.RecordConnection("peer-A", "relay", 0)
+	h.RecordConnection("peer-B", "relay", 0)
+	h.RecordHolePunch("peer-A", false, "all dials failed")
+	h.RecordHolePunch("peer-A", true, "")
+	h.RecordHolePunch("peer-B", false, "no good addresses")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	h2 := NewPeerHistory(path)
+	a := h2.Get("peer-A")
+	if a == nil || a.HolePunchAttempts != 2 || a.HolePunchSuccesses != 1 || a.LastDirectUpgrade.IsZero() {
+		t.Errorf("peer-A = %+v, want 2 attempts, 1 success, an upgrade time", a)
+	}
+	b := h2.Get("peer-B")
+	if b == nil || b.HolePunchSuccesses != 0 || !b.LastDirectUpgrade.IsZero() || b.LastHolePunchError != "no good addresses" {
+		t.Errorf("peer-B = %+v, want no upgrade and the failure kept", b)
+	}
+}
+
+func TestPeerHistory_RecordHolePunchUnknownPeer(t *testing.T) {
+	h := NewPeerHistory(filepath.Join(t.TempDir(), "peer_history.json"))
+	h.RecordHolePunch("peer-C", true, "")
+	if r := h.Get("peer-C"); r != nil {
+		t.Errorf("hole punch created a record for an unknown peer: %+v", r)
+	}
+}
+
 func TestPeerHistory_RunningAverage(t *testing.T) {
 	dir := t.TempDir()
 	h := NewPeerHistory(filepath.Join(dir, "history.json"))
@@ -147,3 +180,87 @@ func TestPeerHistory_SaveCreatesFile(t *testing.T) {
 		t.Errorf("permissions = %v, want 0600", info.Mode().Perm())
 	}
 }
+
+func TestPeerHistory_EventWindow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+	h := NewPeerHistory(path)
+
+	for i := range MaxConnectionEvents + 5 {
+		h.RecordConnection("peer-E", "DIRECT", float64(i+1))
+	}
+	r := h.Get("peer-E")
+	if len(r.Events) != MaxConnectionEvents {
+		t.Fatalf("events = %d, want %d", len(r.Events), MaxConnectionEvents)
+	}
+	// The oldest events are dropped first.
+	if r.Events[0].LatencyMs != 6 {
+		t.Errorf("oldest kept latency = %v, want 6", r.Events[0].LatencyMs)
+	}
+	if r.ConnectionCount != MaxConnectionEvents+5 {
+		t.Errorf("connection_count = %d, want %d", r.ConnectionCount, MaxConnectionEvents+5)
+	}
+
+	// Get returns a copy.
+	r.Events[0].PathType = "mutated"
+	if h.Get("peer-E").Events[0].PathType != "DIRECT" {
+		t.Error("Get shares the event slice with the stored record")
+	}
+
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got := len(NewPeerHistory(path).Get("peer-E").Events); got != MaxConnectionEvents {
+		t.Errorf("events after reload = %d, want %d", got, MaxConnectionEvents)
+	}
+}
+
+func TestPeerHistory_LoadWithoutEvents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+	// A file written before connection events existed.
+	old := `{"peer-O": {"first_seen": "2026-01-01T00:00:00Z", "last_seen": "2026-01-02T00:00:00Z", "connection_count": 3, "avg_latency_ms": 12}}`
+	if err := os.WriteFile(path, []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewPeerHistory(path)
+	r := h.Get("peer-O")
+	if r == nil {
+		t.Fatal("peer-O not loaded")
+	}
+	if r.PeerID != "peer-O" || r.ConnectionCount != 3 || len(r.Events) != 0 {
+		t.Errorf("migrated record = %+v", r)
+	}
+
+	// Recording on a migrated record must not panic on the missing maps.
+	h.RecordConnection("peer-O", "RELAYED", 80)
+	r = h.Get("peer-O")
+	if len(r.Events) != 1 || r.PathTypes["RELAYED"] != 1 {
+		t.Errorf("after record: events = %d, path_types = %v", len(r.Events), r.PathTypes)
+	}
+}
+
+func TestSummarizeEvents(t *testing.T) {
+	now := time.Now()
+	events := []ConnectionEvent{
+		{Time: now.Add(-48 * time.Hour), PathType: "RELAYED", LatencyMs: 500},
+		{Time: now.Add(-3 * time.Hour), PathType: "DIRECT", LatencyMs: 10},
+		{Time: now.Add(-2 * time.Hour), PathType: "RELAYED", LatencyMs: 90},
+		{Time: now.Add(-1 * time.Hour), PathType: "DIRECT", LatencyMs: 20},
+		{Time: now.Add(-time.Minute), PathType: "DIRECT"},
+	}
+	s := SummarizeEvents(events, now.Add(-24*time.Hour))
+	if s.Connections != 4 {
+		t.Errorf("connections = %d, want 4", s.Connections)
+	}
+	if s.PathTypes["DIRECT"] != 3 || s.PathTypes["RELAYED"] != 1 {
+		t.Errorf("path_types = %v", s.PathTypes)
+	}
+	if s.PathChanges != 2 {
+		t.Errorf("path_changes = %d, want 2", s.PathChanges)
+	}
+	if s.AvgLatencyMs != 40 || s.MaxLatencyMs != 90 {
+		t.Errorf("latency avg/max = %v/%v, want 40/90", s.AvgLatencyMs, s.MaxLatencyMs)
+	}
+}
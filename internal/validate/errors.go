@@ -10,4 +10,12 @@ var (
 	// ErrInvalidNetworkName is returned when a network namespace does not match
 	// the DNS-label format (1-63 lowercase alphanumeric + hyphens).
 	ErrInvalidNetworkName = errors.New("invalid network name")
+
+	// ErrInvalidProfileName is returned when a --profile name is not a
+	// DNS-label or is reserved for the default profile's files.
+	ErrInvalidProfileName = errors.New("invalid profile name")
+
+	// ErrInvalidRegionName is returned when a relay region hint does not
+	// match the DNS-label format (1-32 lowercase alphanumeric + hyphens).
+	ErrInvalidRegionName = errors.New("invalid region name")
 )
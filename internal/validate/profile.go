@@ -0,0 +1,36 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// profileNameRe uses the same DNS-label format as network and service names.
+// Profile names become file names (work.yaml, work.key, work.sock), so path
+// separators, dots and leading hyphens are ruled out.
+var profileNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// reservedProfileNames would make a profile's files collide with the default
+// profile's: config.yaml is the default config, shurli.sock its socket and
+// identity.key its key.
+var reservedProfileNames = map[string]bool{
+	"config":       true,
+	"shurli":       true,
+	"relay-server": true,
+	"identity":     true,
+}
+
+// ProfileName checks that a profile name is safe to use in file names and
+// does not collide with the default profile's files.
+func ProfileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidProfileName)
+	}
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("%w: %q must be 1-63 lowercase alphanumeric characters or hyphens, starting and ending with alphanumeric", ErrInvalidProfileName, name)
+	}
+	if reservedProfileNames[name] {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidProfileName, name)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProfileName(t *testing.T) {
+	for _, name := range []string{"work", "home-lab", "a", "p2"} {
+		if err := ProfileName(name); err != nil {
+			t.Errorf("ProfileName(%q) = %v, want nil", name, err)
+		}
+	}
+
+	invalid := []struct {
+		name string
+		desc string
+	}{
+		{"", "empty"},
+		{"Work", "uppercase"},
+		{"-work", "starts with hyphen"},
+		{"work.yaml", "dot"},
+		{"../work", "path traversal"},
+		{"a/b", "slash"},
+		{strings.Repeat("a", 64), "too long (64 chars)"},
+		{"config", "reserved: default config.yaml"},
+		{"shurli", "reserved: default shurli.sock"},
+		{"relay-server", "reserved: relay-server.yaml"},
+		{"identity", "reserved: default identity.key"},
+	}
+	for _, tc := range invalid {
+		err := ProfileName(tc.name)
+		if err == nil {
+			t.Errorf("ProfileName(%q) [%s] = nil, want error", tc.name, tc.desc)
+			continue
+		}
+		if !errors.Is(err, ErrInvalidProfileName) {
+			t.Errorf("ProfileName(%q) error = %v, want ErrInvalidProfileName", tc.name, err)
+		}
+	}
+}
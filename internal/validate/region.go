@@ -0,0 +1,20 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// regionNameRe matches relay region hints like "au-sydney" or "eu-west-1":
+// 1-32 lowercase alphanumeric or hyphens, starting and ending with
+// alphanumeric. Regions are carried in the relay's identify agent version,
+// so they must not contain spaces or parentheses.
+var regionNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// RegionName checks that a relay region hint is a short DNS-label-style name.
+func RegionName(name string) error {
+	if !regionNameRe.MatchString(name) {
+		return fmt.Errorf("%w: %q must be 1-32 lowercase alphanumeric characters or hyphens, starting and ending with alphanumeric", ErrInvalidRegionName, name)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegionName(t *testing.T) {
+	for _, name := range []string{"au-sydney", "eu-west-1", "us", "x", strings.Repeat("a", 32)} {
+		if err := RegionName(name); err != nil {
+			t.Errorf("RegionName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "AU-Sydney", "au sydney", "-au", "au-", "au(syd)", "au/syd", strings.Repeat("a", 33)} {
+		err := RegionName(name)
+		if err == nil {
+			t.Errorf("RegionName(%q) = nil, want error", name)
+		} else if !errors.Is(err, ErrInvalidRegionName) {
+			t.Errorf("RegionName(%q) error should wrap ErrInvalidRegionName, got: %v", name, err)
+		}
+	}
+}
@@ -6,29 +6,122 @@ import (
 	"log/slog"
 	"net"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultFailureThreshold is the number of consecutive failures before a
+// check's Recover action runs when FailureThreshold is unset.
+const DefaultFailureThreshold = 3
+
 // Config holds watchdog configuration.
 type Config struct {
 	Interval time.Duration // health check interval (default: 30s)
+
+	// State, if set, is updated after every check so callers (the daemon
+	// API) can report current health.
+	State *State
+
+	// OnRecover, if set, is called after each recovery attempt with the
+	// check name and the error Recover returned (nil on success). Used to
+	// feed metrics without this package depending on them.
+	OnRecover func(check string, err error)
 }
 
 // HealthCheck is a named function that returns nil if healthy.
+//
+// Recover is optional. When set, it runs after FailureThreshold consecutive
+// failures, and again after every further FailureThreshold failures while
+// the check stays unhealthy, so a persistent fault is retried without being
+// hammered on every tick. Recover runs in its own goroutine so a slow
+// recovery never delays the systemd heartbeat; a check whose previous
+// recovery is still running is not recovered again.
 type HealthCheck struct {
-	Name  string
-	Check func() error
+	Name             string
+	Check            func() error
+	Recover          func() error
+	FailureThreshold int // consecutive failures before Recover (default: DefaultFailureThreshold)
+}
+
+func (hc HealthCheck) threshold() int {
+	if hc.FailureThreshold > 0 {
+		return hc.FailureThreshold
+	}
+	return DefaultFailureThreshold
+}
+
+// CheckStatus is the latest result of one health check.
+type CheckStatus struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastCheck           time.Time `json:"last_check"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	RecoveryAttempts    int       `json:"recovery_attempts"`
+	LastRecovery        time.Time `json:"last_recovery,omitzero"`
+	LastRecoveryError   string    `json:"last_recovery_error,omitempty"`
+}
+
+// State holds the latest status of each health check. It is safe for
+// concurrent use; Run writes it and API handlers read it.
+type State struct {
+	mu     sync.Mutex
+	order  []string
+	checks map[string]*CheckStatus
+}
+
+// NewState creates an empty State.
+func NewState() *State {
+	return &State{checks: make(map[string]*CheckStatus)}
+}
+
+// Snapshot returns a copy of every check's status, in the order the checks
+// were first run. A nil State returns nil.
+func (s *State) Snapshot() []CheckStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]CheckStatus, 0, len(s.order))
+	for _, name := range s.order {
+		out = append(out, *s.checks[name])
+	}
+	return out
+}
+
+// update applies fn to the named check's status, creating it if needed.
+func (s *State) update(name string, fn func(*CheckStatus)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.checks[name]
+	if !ok {
+		cs = &CheckStatus{Name: name}
+		s.checks[name] = cs
+		s.order = append(s.order, name)
+	}
+	fn(cs)
 }
 
 // Run starts the watchdog loop. It runs health checks at the configured interval,
-// logs failures via slog, and sends WATCHDOG=1 to systemd on success.
-// Blocks until ctx is cancelled.
+// logs failures via slog, starts recovery actions for checks that keep failing,
+// and sends WATCHDOG=1 to systemd on every tick.
+// Blocks until ctx is cancelled and any running recovery has returned.
 func Run(ctx context.Context, cfg Config, checks []HealthCheck) {
 	interval := cfg.Interval
 	if interval == 0 {
 		interval = 30 * time.Second
 	}
 
+	failures := make([]int, len(checks))
+	recovering := make([]atomic.Bool, len(checks))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -37,15 +130,75 @@ func Run(ctx context.Context, cfg Config, checks []HealthCheck) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for _, hc := range checks {
-				if err := hc.Check(); err != nil {
-					slog.Warn("health check failed", "check", hc.Name, "error", err)
+			var recover []int
+			for i, hc := range checks {
+				err := hc.Check()
+				now := time.Now()
+				if err == nil {
+					if failures[i] > 0 && hc.Recover != nil {
+						slog.Info("health check recovered", "check", hc.Name, "after_failures", failures[i])
+					}
+					failures[i] = 0
+				} else {
+					failures[i]++
+					slog.Warn("health check failed", "check", hc.Name, "error", err, "consecutive", failures[i])
+				}
+				n := failures[i]
+				cfg.State.update(hc.Name, func(cs *CheckStatus) {
+					cs.Healthy = err == nil
+					cs.LastCheck = now
+					cs.ConsecutiveFailures = n
+					cs.LastError = ""
+					if err != nil {
+						cs.LastError = err.Error()
+					}
+				})
+
+				if err != nil && hc.Recover != nil && n%hc.threshold() == 0 {
+					recover = append(recover, i)
 				}
 			}
 			// Always heartbeat. The watchdog proves "I'm alive",
 			// not "all checks pass". Health issues are logged above.
 			Watchdog()
+
+			for _, i := range recover {
+				if !recovering[i].CompareAndSwap(false, true) {
+					slog.Debug("health check recovery still running", "check", checks[i].Name)
+					continue
+				}
+				wg.Add(1)
+				go func(i, n int) {
+					defer wg.Done()
+					defer recovering[i].Store(false)
+					recoverCheck(cfg, checks[i], n)
+				}(i, failures[i])
+			}
+		}
+	}
+}
+
+// recoverCheck runs hc.Recover and records the attempt.
+func recoverCheck(cfg Config, hc HealthCheck, failures int) {
+	slog.Warn("health check recovery attempt", "check", hc.Name, "consecutive", failures)
+	err := hc.Recover()
+	if err != nil {
+		slog.Error("health check recovery failed", "check", hc.Name, "error", err)
+	} else {
+		slog.Info("health check recovery completed", "check", hc.Name)
+	}
+
+	now := time.Now()
+	cfg.State.update(hc.Name, func(cs *CheckStatus) {
+		cs.RecoveryAttempts++
+		cs.LastRecovery = now
+		cs.LastRecoveryError = ""
+		if err != nil {
+			cs.LastRecoveryError = err.Error()
 		}
+	})
+	if cfg.OnRecover != nil {
+		cfg.OnRecover(hc.Name, err)
 	}
 }
 
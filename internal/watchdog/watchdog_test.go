@@ -85,6 +85,161 @@ func TestRunUnhealthy(t *testing.T) {
 	}
 }
 
+func TestRunRecoverAfterThreshold(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})))
+	defer slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	var checkCount, recoverCount atomic.Int32
+	var recoveredAt atomic.Int32
+	var callbacks atomic.Int32
+	state := NewState()
+	checks := []HealthCheck{
+		{
+			Name: "flaky",
+			Check: func() error {
+				checkCount.Add(1)
+				return errors.New("down")
+			},
+			Recover: func() error {
+				if recoverCount.Add(1) == 1 {
+					recoveredAt.Store(checkCount.Load())
+				}
+				return errors.New("still down")
+			},
+			FailureThreshold: 2,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, Config{
+			Interval:  20 * time.Millisecond,
+			State:     state,
+			OnRecover: func(string, error) { callbacks.Add(1) },
+		}, checks)
+		close(done)
+	}()
+
+	time.Sleep(190 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := recoveredAt.Load(); got != 2 {
+		t.Errorf("first recovery after check %d, want 2", got)
+	}
+	// Recovery repeats every threshold failures, not every tick.
+	checksRun, recoveries := checkCount.Load(), recoverCount.Load()
+	if want := checksRun / 2; recoveries != want {
+		t.Errorf("%d recoveries over %d failed checks, want %d", recoveries, checksRun, want)
+	}
+	if callbacks.Load() != recoveries {
+		t.Errorf("OnRecover called %d times, want %d", callbacks.Load(), recoveries)
+	}
+
+	snap := state.Snapshot()
+	if len(snap) != 1 {
+		t.Fatalf("snapshot has %d checks, want 1", len(snap))
+	}
+	cs := snap[0]
+	if cs.Name != "flaky" || cs.Healthy || cs.LastError != "down" {
+		t.Errorf("status = %+v, want unhealthy flaky with last error", cs)
+	}
+	if cs.ConsecutiveFailures != int(checksRun) {
+		t.Errorf("consecutive failures = %d, want %d", cs.ConsecutiveFailures, checksRun)
+	}
+	if cs.RecoveryAttempts != int(recoveries) || cs.LastRecoveryError != "still down" {
+		t.Errorf("recovery attempts = %d (%q), want %d (\"still down\")", cs.RecoveryAttempts, cs.LastRecoveryError, recoveries)
+	}
+}
+
+func TestRunFailureStreakResets(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})))
+	defer slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	// Alternating failures never reach a threshold of 2.
+	var n, recoverCount atomic.Int32
+	checks := []HealthCheck{
+		{
+			Name: "alternating",
+			Check: func() error {
+				if n.Add(1)%2 == 1 {
+					return errors.New("blip")
+				}
+				return nil
+			},
+			Recover: func() error {
+				recoverCount.Add(1)
+				return nil
+			},
+			FailureThreshold: 2,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, Config{Interval: 20 * time.Millisecond}, checks)
+		close(done)
+	}()
+
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	if recoverCount.Load() != 0 {
+		t.Errorf("recover ran %d times for non-consecutive failures", recoverCount.Load())
+	}
+}
+
+func TestRunSlowRecoverDoesNotBlockChecks(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})))
+	defer slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var checkCount, recoverCount atomic.Int32
+	checks := []HealthCheck{
+		{
+			Name: "stuck",
+			Check: func() error {
+				checkCount.Add(1)
+				return errors.New("down")
+			},
+			// Blocks until shutdown, like a recovery dialing dead relays.
+			Recover: func() error {
+				recoverCount.Add(1)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			FailureThreshold: 1,
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, Config{Interval: 20 * time.Millisecond}, checks)
+		close(done)
+	}()
+
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := checkCount.Load(); got < 4 {
+		t.Errorf("only %d checks ran while recovery was blocked", got)
+	}
+	if got := recoverCount.Load(); got != 1 {
+		t.Errorf("recover started %d times, want 1 while the first is still running", got)
+	}
+}
+
+func TestStateSnapshotNil(t *testing.T) {
+	var s *State
+	if s.Snapshot() != nil {
+		t.Error("nil State should snapshot to nil")
+	}
+}
+
 func TestRunCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
@@ -351,7 +351,7 @@ func TestX3_GetPlugin(t *testing.T) {
 // --- G2: Reserved protocol name test ---
 
 func TestG2_ReservedProtocolNameRejected(t *testing.T) {
-	reserved := []string{"relay-pair", "relay-unseal", "relay-admin", "relay-motd", "peer-notify", "zkp-auth", "ping", "kad"}
+	reserved := []string{"relay-pair", "relay-unseal", "relay-admin", "relay-motd", "peer-notify", "auth-challenge", "zkp-auth", "ping", "kad"}
 	for _, name := range reserved {
 		if !isReservedProtocolName(name) {
 			t.Errorf("protocol name %q should be reserved", name)
@@ -417,6 +417,7 @@ type ContextProvider struct {
 	Network         *sdk.Network
 	ServiceRegistry *sdk.ServiceRegistry
 	ConfigDir       string                                      // base config dir (~/.shurli/)
+	PluginsDir      string                                      // plugin state root under ConfigDir ("plugins" when empty)
 	NameResolver    func(name string) (peer.ID, error)
 	PeerConnector   func(ctx context.Context, id peer.ID) error // DHT + relay fallback connection
 	KeyDeriver      func(domain string) []byte                  // HKDF-SHA256 key derivation from identity
@@ -652,6 +652,34 @@ func TestEnableRegistersProtocolsAfterStart(t *testing.T) {
 	}
 }
 
+// TestRegisterPluginsDirPerProfile verifies that two profiles sharing a
+// config dir get separate plugin state roots.
+func TestRegisterPluginsDirPerProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	dirs := make(map[string]string)
+	for _, pluginsDir := range []string{"", "work-plugins"} {
+		r := NewRegistry(&ContextProvider{ConfigDir: tmpDir, PluginsDir: pluginsDir})
+		if err := r.Register(newMockPlugin("profiled")); err != nil {
+			t.Fatalf("Register(PluginsDir=%q): %v", pluginsDir, err)
+		}
+		r.mu.RLock()
+		dirs[pluginsDir] = r.plugins["profiled"].ctx.ConfigDir()
+		r.mu.RUnlock()
+	}
+
+	resolved, err := filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(resolved, "plugins", "test.io", "mock", "profiled"); dirs[""] != want {
+		t.Errorf("default profile dir = %q, want %q", dirs[""], want)
+	}
+	if want := filepath.Join(resolved, "work-plugins", "test.io", "mock", "profiled"); dirs["work-plugins"] != want {
+		t.Errorf("work profile dir = %q, want %q", dirs["work-plugins"], want)
+	}
+}
+
 // TestNotifyConfigReloadWriteUnderRLock proves P19:
 // NotifyConfigReload writes to entry.ctx.configBytes while holding only RLock.
 // Two concurrent NotifyConfigReload calls both hold RLock and both write.
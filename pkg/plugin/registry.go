@@ -163,13 +163,17 @@ func (r *Registry) Register(p Plugin) error {
 	var configDir string
 	var configBytes []byte
 	if r.provider != nil && r.provider.ConfigDir != "" {
-		configDir = filepath.Join(r.provider.ConfigDir, "plugins", id)
+		pluginsDir := r.provider.PluginsDir
+		if pluginsDir == "" {
+			pluginsDir = "plugins"
+		}
+		configDir = filepath.Join(r.provider.ConfigDir, pluginsDir, id)
 		// M2 fix: prevent symlink traversal by resolving the base config dir
 		// and rebuilding the plugin path from the resolved root. Since plugin
 		// IDs are validated (no "..", no "//", alphanumeric segments only),
 		// the rebuilt path is guaranteed to stay under the resolved base.
 		if resolved, err := filepath.EvalSymlinks(r.provider.ConfigDir); err == nil {
-			configDir = filepath.Join(resolved, "plugins", id)
+			configDir = filepath.Join(resolved, pluginsDir, id)
 		}
 		// Create config dir with 0700 if not exists.
 		if err := os.MkdirAll(configDir, 0700); err != nil {
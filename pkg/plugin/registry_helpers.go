@@ -15,14 +15,15 @@ import (
 // reservedProtocolNames is the set of core Shurli protocol names that plugins
 // must not shadow. Package-level to avoid allocation per call.
 var reservedProtocolNames = map[string]bool{
-	"relay-pair":   true,
-	"relay-unseal": true,
-	"relay-admin":  true,
-	"relay-motd":   true,
-	"peer-notify":  true,
-	"zkp-auth":     true,
-	"ping":         true,
-	"kad":          true,
+	"relay-pair":     true,
+	"relay-unseal":   true,
+	"relay-admin":    true,
+	"relay-motd":     true,
+	"peer-notify":    true,
+	"auth-challenge": true,
+	"zkp-auth":       true,
+	"ping":           true,
+	"kad":            true,
 }
 
 // isReservedProtocolName returns true if the name collides with a core Shurli protocol.
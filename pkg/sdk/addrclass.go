@@ -0,0 +1,132 @@
+package sdk
+
+import (
+	"net"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// AddrClass is the reachability class of an address, from the narrowest
+// scope (loopback) to globally routable. The string values are the names
+// used by the network.advertise_exclude config key.
+type AddrClass string
+
+const (
+	AddrLoopback  AddrClass = "loopback"   // 127.0.0.0/8, ::1
+	AddrLinkLocal AddrClass = "link-local" // 169.254.0.0/16, fe80::/10
+	AddrPrivate   AddrClass = "private"    // RFC 1918 IPv4
+	AddrCGNAT     AddrClass = "cgnat"      // RFC 6598 shared space, 100.64.0.0/10
+	AddrULA       AddrClass = "ula"        // IPv6 unique local, fc00::/7
+	AddrGlobal    AddrClass = "global"     // publicly routable unicast
+	AddrCircuit   AddrClass = "circuit"    // relay circuit (/p2p-circuit)
+	AddrOther     AddrClass = "other"      // DNS names, onion, unspecified, multicast
+)
+
+var cgnatNet = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// ClassifyIP returns the class of ip.
+func ClassifyIP(ip net.IP) AddrClass {
+	switch {
+	case ip == nil:
+		return AddrOther
+	case ip.IsLoopback():
+		return AddrLoopback
+	case ip.IsLinkLocalUnicast():
+		return AddrLinkLocal
+	case cgnatNet.Contains(ip):
+		return AddrCGNAT
+	case ip.IsPrivate():
+		if ip.To4() != nil {
+			return AddrPrivate
+		}
+		return AddrULA
+	case ip.IsGlobalUnicast():
+		return AddrGlobal
+	}
+	return AddrOther
+}
+
+// ClassifyAddr returns the class of a multiaddr. Relay circuit addresses
+// are AddrCircuit regardless of the relay's own IP; other addresses are
+// classified by their leading IP component.
+func ClassifyAddr(a ma.Multiaddr) AddrClass {
+	if a == nil {
+		return AddrOther
+	}
+	if _, err := a.ValueForProtocol(ma.P_CIRCUIT); err == nil {
+		return AddrCircuit
+	}
+	first, _ := ma.SplitFirst(a)
+	if first == nil {
+		return AddrOther
+	}
+	switch first.Protocol().Code {
+	case ma.P_IP4, ma.P_IP6:
+		return ClassifyIP(net.ParseIP(first.Value()))
+	}
+	return AddrOther
+}
+
+// ClassifyAddrString is ClassifyAddr for a multiaddr in string form, as
+// returned by the daemon API. Unparseable strings are AddrOther.
+func ClassifyAddrString(s string) AddrClass {
+	a, err := ma.NewMultiaddr(s)
+	if err != nil {
+		return AddrOther
+	}
+	return ClassifyAddr(a)
+}
+
+// Labels the CLI prints next to an address, e.g. in whoami and the
+// daemon's status printer.
+const (
+	AddrLabelRelay  = "RELAY"  // reached through a relay circuit
+	AddrLabelPublic = "public" // direct, globally routable
+	AddrLabelLocal  = "local"  // direct, reachable only nearby (LAN, CGNAT, loopback, ...)
+)
+
+// AddrLabel reduces the class of a multiaddr string to one of the
+// AddrLabel values. Anything that is not a relay circuit is a direct
+// address; only globally routable ones count as public.
+func AddrLabel(s string) string {
+	switch ClassifyAddrString(s) {
+	case AddrCircuit:
+		return AddrLabelRelay
+	case AddrGlobal:
+		return AddrLabelPublic
+	}
+	return AddrLabelLocal
+}
+
+// HasRelayReservation reports whether h currently advertises at least one
+// relay circuit address, which exists only while a reservation is held.
+func HasRelayReservation(h host.Host) bool {
+	for _, a := range h.Addrs() {
+		if ClassifyAddr(a) == AddrCircuit {
+			return true
+		}
+	}
+	return false
+}
+
+// advertiseFilter returns an address factory step that drops addresses in
+// the excluded classes, or nil when nothing is excluded.
+func advertiseFilter(exclude []string) func([]ma.Multiaddr) []ma.Multiaddr {
+	if len(exclude) == 0 {
+		return nil
+	}
+	drop := make(map[AddrClass]bool, len(exclude))
+	for _, c := range exclude {
+		drop[AddrClass(c)] = true
+	}
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		kept := make([]ma.Multiaddr, 0, len(addrs))
+		for _, a := range addrs {
+			if !drop[ClassifyAddr(a)] {
+				kept = append(kept, a)
+			}
+		}
+		return kept
+	}
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"net"
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+func TestClassifyAddr(t *testing.T) {
+	for _, tc := range []struct {
+		addr string
+		want AddrClass
+	}{
+		{"/ip4/127.0.0.1/tcp/4001", AddrLoopback},
+		{"/ip6/::1/udp/4001/quic-v1", AddrLoopback},
+		{"/ip4/10.0.0.5/tcp/4001", AddrPrivate},
+		{"/ip4/172.16.0.1/tcp/4001", AddrPrivate},
+		{"/ip4/172.31.255.255/tcp/4001", AddrPrivate},
+		{"/ip4/192.168.1.1/tcp/4001", AddrPrivate},
+		{"/ip4/172.32.0.1/tcp/4001", AddrGlobal}, // outside 172.16/12
+		{"/ip4/100.64.0.1/tcp/4001", AddrCGNAT},
+		{"/ip4/100.128.0.1/tcp/4001", AddrGlobal}, // outside 100.64/10
+		{"/ip4/169.254.10.1/tcp/4001", AddrLinkLocal},
+		{"/ip6/fe80::1/tcp/4001", AddrLinkLocal},
+		{"/ip6/fd00::1/tcp/4001", AddrULA},
+		{"/ip6/fc12::1/tcp/4001", AddrULA},
+		{"/ip4/203.0.113.7/tcp/4001", AddrGlobal},
+		{"/ip6/2001:db8::1/udp/4001/quic-v1", AddrGlobal},
+		{"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit", AddrCircuit},
+		{"/dns4/relay.example.com/tcp/7777", AddrOther},
+		{"/ip4/0.0.0.0/tcp/4001", AddrOther},
+	} {
+		if got := ClassifyAddrString(tc.addr); got != tc.want {
+			t.Errorf("ClassifyAddrString(%q) = %q, want %q", tc.addr, got, tc.want)
+		}
+	}
+	if got := ClassifyAddrString("not a multiaddr"); got != AddrOther {
+		t.Errorf("unparseable: got %q, want %q", got, AddrOther)
+	}
+	if got := ClassifyIP(net.ParseIP("::ffff:10.1.2.3")); got != AddrPrivate {
+		t.Errorf("IPv4-mapped private: got %q, want %q", got, AddrPrivate)
+	}
+}
+
+func TestAddrLabel(t *testing.T) {
+	for addr, want := range map[string]string{
+		"/ip4/203.0.113.50/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An/p2p-circuit": AddrLabelRelay,
+		"/ip4/203.0.113.7/tcp/4001":         AddrLabelPublic,
+		"/ip6/2001:db8::1/udp/4001/quic-v1": AddrLabelPublic,
+		"/ip4/192.168.1.1/tcp/4001":         AddrLabelLocal,
+		"/ip4/100.64.0.1/tcp/4001":          AddrLabelLocal,
+		"/ip4/127.0.0.1/tcp/4001":           AddrLabelLocal,
+	} {
+		if got := AddrLabel(addr); got != want {
+			t.Errorf("AddrLabel(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestAdvertiseFilter(t *testing.T) {
+	if advertiseFilter(nil) != nil {
+		t.Error("empty exclude list should not install a filter")
+	}
+	var addrs []ma.Multiaddr
+	for _, s := range []string{
+		"/ip4/192.168.1.5/tcp/4001",
+		"/ip6/fd00::5/tcp/4001",
+		"/ip6/fe80::5/tcp/4001",
+		"/ip6/2001:db8::5/tcp/4001",
+	} {
+		addrs = append(addrs, ma.StringCast(s))
+	}
+	got := advertiseFilter([]string{"ula", "link-local"})(addrs)
+	if len(got) != 2 || !got[0].Equal(addrs[0]) || !got[1].Equal(addrs[3]) {
+		t.Errorf("filtered = %v, want [%s %s]", got, addrs[0], addrs[3])
+	}
+}
+
+// Every class the config accepts must be one ClassifyAddr can return, or
+// an exclusion would silently match nothing.
+func TestAdvertiseAddrClassesKnown(t *testing.T) {
+	known := map[AddrClass]bool{
+		AddrLoopback: true, AddrLinkLocal: true, AddrPrivate: true,
+		AddrCGNAT: true, AddrULA: true,
+	}
+	for _, c := range config.AdvertiseAddrClasses {
+		if !known[AddrClass(c)] {
+			t.Errorf("config class %q is not an sdk AddrClass", c)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/discovery"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+const (
+	// DefaultAdvertiseInterval is how often a healthy node re-advertises
+	// on its rendezvous.
+	DefaultAdvertiseInterval = time.Minute
+
+	// DefaultAdvertiseRetry is the first retry delay after a failed
+	// advertise. It doubles on each further failure, up to the interval.
+	DefaultAdvertiseRetry = 10 * time.Second
+
+	// advertiseTimeout bounds one advertise. A DHT provide that hangs would
+	// otherwise stall every later refresh.
+	advertiseTimeout = time.Minute
+)
+
+// AdvertiseStatus is the health of a node's rendezvous provider records.
+// With more than one rendezvous it is the aggregate (healthy only when
+// every rendezvous is) and Groups holds each one's own status.
+type AdvertiseStatus struct {
+	Rendezvous          string            `json:"rendezvous"`
+	LastAdvertised      time.Time         `json:"last_advertised,omitzero"` // last success; zero if none yet
+	LastAttempt         time.Time         `json:"last_attempt,omitzero"`    // zero before the first advertise
+	LastError           string            `json:"last_error,omitempty"`     // error of the last attempt, if it failed
+	ConsecutiveFailures int               `json:"consecutive_failures,omitempty"`
+	Healthy             bool              `json:"healthy"` // last success within the stale threshold
+	Groups              []AdvertiseStatus `json:"groups,omitempty"`
+}
+
+// RendezvousAdvertiser keeps this node's provider records for one or more
+// rendezvous strings fresh in the DHT and records how that is going. A
+// failed advertise is retried after DefaultAdvertiseRetry (backing off to
+// the interval) instead of waiting out the full interval, so a node that
+// silently dropped out of the DHT becomes discoverable again quickly.
+//
+// All rendezvous share one goroutine and one timer: each wake-up
+// advertises the ones that are due and sleeps until the next is.
+type RendezvousAdvertiser struct {
+	disc       discovery.Advertiser
+	interval   time.Duration
+	retry      time.Duration
+	staleAfter time.Duration // no success for this long = unhealthy
+	clock      clock.Clock
+
+	kick chan struct{}
+
+	mu     sync.Mutex
+	groups []*advertiseGroup
+}
+
+// advertiseGroup is the state of one rendezvous. Guarded by the
+// advertiser's mu.
+type advertiseGroup struct {
+	rendezvous  string
+	next        time.Time // zero = due now
+	lastSuccess time.Time
+	lastAttempt time.Time
+	lastErr     error
+	failures    int
+}
+
+// NewRendezvousAdvertiser creates an advertiser for the given rendezvous
+// strings on disc. Call Run to start advertising.
+func NewRendezvousAdvertiser(disc discovery.Advertiser, rendezvous ...string) *RendezvousAdvertiser {
+	a := &RendezvousAdvertiser{
+		disc:       disc,
+		interval:   DefaultAdvertiseInterval,
+		retry:      DefaultAdvertiseRetry,
+		staleAfter: 3 * DefaultAdvertiseInterval,
+		clock:      clock.Real,
+		kick:       make(chan struct{}, 1),
+	}
+	for _, r := range rendezvous {
+		a.groups = append(a.groups, &advertiseGroup{rendezvous: r})
+	}
+	return a
+}
+
+// Run advertises at once and then keeps the records fresh until ctx is
+// cancelled.
+func (a *RendezvousAdvertiser) Run(ctx context.Context) {
+	wait := a.advertiseOnce(ctx)
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-a.kick:
+			timer.Stop()
+			wait = a.advertiseOnce(ctx)
+		case <-timer.C:
+			wait = a.advertiseDue(ctx)
+		}
+	}
+}
+
+// AdvertiseNow makes Run advertise every rendezvous right away (e.g. after
+// a network change) instead of at its next scheduled refresh. It never
+// blocks.
+func (a *RendezvousAdvertiser) AdvertiseNow() {
+	select {
+	case a.kick <- struct{}{}:
+	default:
+	}
+}
+
+// advertiseOnce advertises every rendezvous and returns how long to wait
+// before the next one is due.
+func (a *RendezvousAdvertiser) advertiseOnce(ctx context.Context) time.Duration {
+	a.mu.Lock()
+	for _, g := range a.groups {
+		g.next = time.Time{}
+	}
+	a.mu.Unlock()
+	return a.advertiseDue(ctx)
+}
+
+// advertiseDue advertises the rendezvous whose refresh or retry is due and
+// returns how long to wait before the next one is.
+func (a *RendezvousAdvertiser) advertiseDue(ctx context.Context) time.Duration {
+	a.mu.Lock()
+	now := a.clock.Now()
+	var due []*advertiseGroup
+	for _, g := range a.groups {
+		if !g.next.After(now) {
+			due = append(due, g)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, g := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		a.advertiseGroup(ctx, g, now)
+	}
+
+	// Waits count from the start of the pass, like a single advertiser
+	// waiting out its interval after the call returns.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	wait := a.interval
+	for _, g := range a.groups {
+		if d := g.next.Sub(now); d < wait {
+			wait = max(d, 0)
+		}
+	}
+	return wait
+}
+
+// advertiseGroup advertises one rendezvous and schedules its next attempt,
+// counted from start: the interval after a success, a backed-off retry
+// after a failure.
+func (a *RendezvousAdvertiser) advertiseGroup(ctx context.Context, g *advertiseGroup, start time.Time) {
+	actx, cancel := context.WithTimeout(ctx, advertiseTimeout)
+	_, err := a.disc.Advertise(actx, g.rendezvous)
+	cancel()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	g.lastAttempt = a.clock.Now()
+	g.lastErr = err
+	if err == nil {
+		if g.failures > 0 {
+			slog.Info("rendezvous: advertise recovered", "rendezvous", g.rendezvous, "failures", g.failures)
+		}
+		g.lastSuccess = g.lastAttempt
+		g.failures = 0
+		g.next = start.Add(a.interval)
+		return
+	}
+	if ctx.Err() != nil {
+		g.next = start.Add(a.interval)
+		return
+	}
+	g.failures++
+	wait := a.retry << (g.failures - 1)
+	if wait <= 0 || wait > a.interval {
+		wait = a.interval
+	}
+	g.next = start.Add(wait)
+	slog.Warn("rendezvous: advertise failed, retrying",
+		"rendezvous", g.rendezvous, "error", err, "failures", g.failures, "retry_in", wait)
+}
+
+// Status returns the current health of the provider records.
+func (a *RendezvousAdvertiser) Status() AdvertiseStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.clock.Now()
+	var groups []AdvertiseStatus
+	for _, g := range a.groups {
+		st := AdvertiseStatus{
+			Rendezvous:          g.rendezvous,
+			LastAdvertised:      g.lastSuccess,
+			LastAttempt:         g.lastAttempt,
+			ConsecutiveFailures: g.failures,
+			Healthy:             !g.lastSuccess.IsZero() && now.Sub(g.lastSuccess) <= a.staleAfter,
+		}
+		if g.lastErr != nil {
+			st.LastError = g.lastErr.Error()
+		}
+		groups = append(groups, st)
+	}
+	if len(groups) == 1 {
+		return groups[0]
+	}
+	if len(groups) == 0 {
+		return AdvertiseStatus{}
+	}
+
+	// Aggregate: the oldest success, the newest attempt, the first error
+	// and the worst failure streak.
+	agg := AdvertiseStatus{
+		Rendezvous:     groups[0].Rendezvous,
+		LastAdvertised: groups[0].LastAdvertised,
+		Healthy:        true,
+		Groups:         groups,
+	}
+	for _, st := range groups {
+		if st.LastAdvertised.IsZero() || (!agg.LastAdvertised.IsZero() && st.LastAdvertised.Before(agg.LastAdvertised)) {
+			agg.LastAdvertised = st.LastAdvertised
+		}
+		if st.LastAttempt.After(agg.LastAttempt) {
+			agg.LastAttempt = st.LastAttempt
+		}
+		if agg.LastError == "" && st.LastError != "" {
+			agg.LastError = st.Rendezvous + ": " + st.LastError
+		}
+		agg.ConsecutiveFailures = max(agg.ConsecutiveFailures, st.ConsecutiveFailures)
+		agg.Healthy = agg.Healthy && st.Healthy
+	}
+	return agg
+}
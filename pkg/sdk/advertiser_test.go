@@ -0,0 +1,186 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/discovery"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+// fakeAdvertiser fails its first `fail` calls and succeeds after that.
+type fakeAdvertiser struct {
+	fail  int32
+	calls atomic.Int32
+	done  chan struct{} // receives after every call
+}
+
+func (f *fakeAdvertiser) Advertise(_ context.Context, _ string, _ ...discovery.Option) (time.Duration, error) {
+	n := f.calls.Add(1)
+	defer func() { f.done <- struct{}{} }()
+	if n <= f.fail {
+		return 0, errors.New("no peers in routing table")
+	}
+	return time.Hour, nil
+}
+
+// waitAdvertiseStatus polls until cond holds. The fake signals from inside
+// Advertise, before the advertiser has recorded the outcome.
+func waitAdvertiseStatus(t *testing.T, a *RendezvousAdvertiser, cond func(AdvertiseStatus) bool) AdvertiseStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := a.Status()
+		if cond(st) || time.Now().After(deadline) {
+			return st
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRendezvousAdvertiserRetriesFailure(t *testing.T) {
+	fake := &fakeAdvertiser{fail: 1, done: make(chan struct{}, 4)}
+	a := NewRendezvousAdvertiser(fake, "shurli-test")
+	a.interval = time.Hour
+	a.retry = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.Run(ctx)
+
+	<-fake.done
+	st := waitAdvertiseStatus(t, a, func(st AdvertiseStatus) bool { return !st.LastAttempt.IsZero() })
+	if st.Healthy || st.LastError == "" || st.ConsecutiveFailures != 1 || !st.LastAdvertised.IsZero() {
+		t.Errorf("after failed advertise: %+v", st)
+	}
+
+	// The retry comes after a.retry, long before the hour-long interval.
+	select {
+	case <-fake.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("failed advertise was not retried before the normal interval")
+	}
+	st = waitAdvertiseStatus(t, a, func(st AdvertiseStatus) bool { return st.Healthy })
+	if !st.Healthy || st.LastError != "" || st.ConsecutiveFailures != 0 || st.LastAdvertised.IsZero() {
+		t.Errorf("after retry succeeded: %+v", st)
+	}
+
+	// Healthy now, so the next refresh waits for the full interval.
+	select {
+	case <-fake.done:
+		t.Errorf("advertised again after success, calls = %d", fake.calls.Load())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRendezvousAdvertiserAdvertiseNow(t *testing.T) {
+	fake := &fakeAdvertiser{done: make(chan struct{}, 4)}
+	a := NewRendezvousAdvertiser(fake, "shurli-test")
+	a.interval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.Run(ctx)
+	<-fake.done
+
+	a.AdvertiseNow()
+	select {
+	case <-fake.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("AdvertiseNow did not trigger an advertise")
+	}
+}
+
+func TestRendezvousAdvertiserStale(t *testing.T) {
+	fake := &fakeAdvertiser{done: make(chan struct{}, 1)}
+	a := NewRendezvousAdvertiser(fake, "shurli-test")
+	clk := clock.NewFake(time.Now())
+	a.clock = clk
+
+	if st := a.Status(); st.Healthy {
+		t.Errorf("never advertised should not be healthy: %+v", st)
+	}
+	if wait := a.advertiseOnce(context.Background()); wait != a.interval {
+		t.Errorf("wait after success = %v, want %v", wait, a.interval)
+	}
+	if st := a.Status(); !st.Healthy {
+		t.Errorf("fresh advertise should be healthy: %+v", st)
+	}
+	clk.Advance(a.staleAfter + time.Second)
+	if st := a.Status(); st.Healthy {
+		t.Errorf("advertise older than %v should be stale: %+v", a.staleAfter, st)
+	}
+}
+
+func TestRendezvousAdvertiserBackoff(t *testing.T) {
+	fake := &fakeAdvertiser{fail: 100, done: make(chan struct{}, 100)}
+	a := NewRendezvousAdvertiser(fake, "shurli-test")
+
+	var waits []time.Duration
+	for range 5 {
+		waits = append(waits, a.advertiseOnce(context.Background()))
+	}
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("retry %d = %v, want %v", i+1, waits[i], want[i])
+		}
+	}
+}
+
+// nsAdvertiser records each rendezvous it is asked to advertise and fails
+// the ones in fail.
+type nsAdvertiser struct {
+	mu    sync.Mutex
+	fail  map[string]bool
+	calls []string
+}
+
+func (f *nsAdvertiser) Advertise(_ context.Context, ns string, _ ...discovery.Option) (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, ns)
+	if f.fail[ns] {
+		return 0, errors.New("no peers in routing table")
+	}
+	return time.Hour, nil
+}
+
+func TestRendezvousAdvertiserMultiple(t *testing.T) {
+	fake := &nsAdvertiser{fail: map[string]bool{"work": true}}
+	a := NewRendezvousAdvertiser(fake, "family", "work")
+	clk := clock.NewFake(time.Now())
+	a.clock = clk
+
+	// One failing rendezvous schedules the shared timer for its retry.
+	if wait := a.advertiseOnce(context.Background()); wait != a.retry {
+		t.Errorf("wait = %v, want the retry delay %v", wait, a.retry)
+	}
+	st := a.Status()
+	if st.Healthy || len(st.Groups) != 2 || st.LastError != "work: no peers in routing table" {
+		t.Errorf("aggregate status: %+v", st)
+	}
+	if !st.Groups[0].Healthy || st.Groups[1].Healthy {
+		t.Errorf("group status: %+v", st.Groups)
+	}
+
+	// Only the failed rendezvous is due at the retry.
+	fake.fail = nil
+	fake.calls = nil
+	clk.Advance(a.retry)
+	if wait := a.advertiseDue(context.Background()); wait != a.interval-a.retry {
+		t.Errorf("wait = %v, want the rest of family's interval %v", wait, a.interval-a.retry)
+	}
+	if !slices.Equal(fake.calls, []string{"work"}) {
+		t.Errorf("advertised %q, want only work", fake.calls)
+	}
+	if st := a.Status(); !st.Healthy || st.LastError != "" {
+		t.Errorf("after retry: %+v", st)
+	}
+}
@@ -42,6 +42,18 @@ func (a *AuditLogger) ServiceACLDenied(peerID, service string) {
 	)
 }
 
+// ServiceLimitExceeded logs a stream refused by a service's per-peer limits.
+func (a *AuditLogger) ServiceLimitExceeded(peerID, service, reason string) {
+	if a == nil {
+		return
+	}
+	a.logger.Warn("service_limit_exceeded",
+		"peer", peerID,
+		"service", service,
+		"reason", reason,
+	)
+}
+
 // DaemonAPIAccess logs an API request to the daemon.
 func (a *AuditLogger) DaemonAPIAccess(method, path string, status int) {
 	if a == nil {
@@ -64,3 +76,17 @@ func (a *AuditLogger) AuthChange(action, peerID string) {
 		"peer", peerID,
 	)
 }
+
+// RemoteAdminAccess logs a daemon API request made by a remote admin peer
+// over the P2P admin protocol, including denied ones.
+func (a *AuditLogger) RemoteAdminAccess(peerID, method, path string, status int) {
+	if a == nil {
+		return
+	}
+	a.logger.Info("remote_admin_access",
+		"peer", peerID,
+		"method", method,
+		"path", path,
+		"status", status,
+	)
+}
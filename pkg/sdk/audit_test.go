@@ -15,6 +15,7 @@ func TestAuditLoggerNilSafe(t *testing.T) {
 	a.ServiceACLDenied("12D3KooWTest...", "ssh")
 	a.DaemonAPIAccess("GET", "/v1/status", 200)
 	a.AuthChange("add", "12D3KooWTest...")
+	a.RemoteAdminAccess("12D3KooWTest...", "GET", "/v1/status", 200)
 }
 
 func TestAuditLoggerAuthDecision(t *testing.T) {
@@ -128,3 +129,35 @@ func TestAuditLoggerAuthChange(t *testing.T) {
 		t.Errorf("peer = %q, want %q", audit["peer"], "12D3KooWTest...")
 	}
 }
+
+func TestAuditLoggerRemoteAdminAccess(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, nil)
+	a := NewAuditLogger(handler)
+
+	a.RemoteAdminAccess("12D3KooWTest...", "POST", "/v1/config/reload", 403)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse JSON log: %v", err)
+	}
+
+	if entry["msg"] != "remote_admin_access" {
+		t.Errorf("msg = %q, want %q", entry["msg"], "remote_admin_access")
+	}
+
+	audit, ok := entry["audit"].(map[string]any)
+	if !ok {
+		t.Fatal("missing audit group in log entry")
+	}
+
+	if audit["peer"] != "12D3KooWTest..." {
+		t.Errorf("peer = %q, want %q", audit["peer"], "12D3KooWTest...")
+	}
+	if audit["path"] != "/v1/config/reload" {
+		t.Errorf("path = %q, want %q", audit["path"], "/v1/config/reload")
+	}
+	if audit["status"] != float64(403) {
+		t.Errorf("status = %v, want 403", audit["status"])
+	}
+}
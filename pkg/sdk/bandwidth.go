@@ -85,6 +85,21 @@ func (bt *BandwidthTracker) PublishMetrics() {
 	}
 }
 
+// Reset zeroes the session byte counts and rates, e.g. at the start of a
+// benchmark. The Prometheus bandwidth series are gauges mirroring the
+// counter, so they are cleared and republished too; no Prometheus counter
+// is touched.
+func (bt *BandwidthTracker) Reset() {
+	bt.counter.Reset()
+	if bt.prom == nil {
+		return
+	}
+	bt.prom.PeerBandwidthBytesTotal.Reset()
+	bt.prom.PeerBandwidthRate.Reset()
+	bt.prom.ProtocolBandwidthBytesTotal.Reset()
+	bt.PublishMetrics()
+}
+
 // Start runs a background goroutine that publishes metrics every interval
 // and trims idle peers from the counter. Stops when ctx is cancelled.
 func (bt *BandwidthTracker) Start(ctx context.Context, interval time.Duration) {
@@ -0,0 +1,310 @@
+package sdk
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// BandwidthTestProtocol measures throughput between two peers.
+//
+// Wire format: the initiator writes a 9-byte header (direction byte, then
+// the byte count as big-endian uint64).
+//   - bwtestUpload: the initiator sends the bytes and half-closes; the peer
+//     reads them and answers with the byte count and its receive time in
+//     nanoseconds (two big-endian uint64s).
+//   - bwtestDownload: the peer sends the bytes followed by its send time in
+//     nanoseconds (big-endian uint64) and closes.
+const BandwidthTestProtocol = "/shurli/bwtest/1.0.0"
+
+const (
+	// DefaultBandwidthTestBytes is how much a test transfers per direction
+	// when no size is given.
+	DefaultBandwidthTestBytes int64 = 100 * 1024 * 1024
+
+	// MaxBandwidthTestBytes caps one direction of a test. The responding
+	// peer refuses larger requests.
+	MaxBandwidthTestBytes int64 = 1024 * 1024 * 1024
+
+	bwtestUpload   byte = 1
+	bwtestDownload byte = 2
+
+	bwtestBufSize = 64 * 1024
+	// bwtestMaxStreams bounds concurrent tests the responder serves; a
+	// bidirectional test uses two streams.
+	bwtestMaxStreams = 4
+	bwtestTimeout    = 10 * time.Minute
+)
+
+// Bandwidth test directions, from the initiator's point of view.
+const (
+	BandwidthUp   = "up"
+	BandwidthDown = "down"
+	BandwidthBoth = "both"
+)
+
+// BandwidthSample is the measurement for one direction of a test.
+type BandwidthSample struct {
+	Bytes         int64   `json:"bytes"`
+	ElapsedMs     float64 `json:"elapsed_ms"`      // measured locally
+	PeerElapsedMs float64 `json:"peer_elapsed_ms"` // measured by the remote peer
+	Mbps          float64 `json:"mbps"`            // from the receiving side's elapsed time
+}
+
+// BandwidthResult holds the outcome of a bandwidth test.
+type BandwidthResult struct {
+	PeerID    string           `json:"peer_id"`
+	Direction string           `json:"direction"`
+	Path      string           `json:"path"` // "DIRECT" or "RELAYED"
+	Upload    *BandwidthSample `json:"upload,omitempty"`
+	Download  *BandwidthSample `json:"download,omitempty"`
+}
+
+// ValidateBandwidthDirection checks that dir is up, down or both.
+func ValidateBandwidthDirection(dir string) error {
+	switch dir {
+	case BandwidthUp, BandwidthDown, BandwidthBoth:
+		return nil
+	}
+	return fmt.Errorf("invalid direction %q (want up, down or both)", dir)
+}
+
+// RegisterBandwidthTest installs the BandwidthTestProtocol responder on h.
+// allow decides which peers may run tests; nil accepts any peer the
+// connection gater let in.
+func RegisterBandwidthTest(h host.Host, allow func(peer.ID) bool) {
+	var active atomic.Int32
+	h.SetStreamHandler(protocol.ID(BandwidthTestProtocol), func(s network.Stream) {
+		if active.Add(1) > bwtestMaxStreams {
+			active.Add(-1)
+			slog.Warn("bwtest: too many concurrent tests", "peer", s.Conn().RemotePeer().String()[:16]+"...")
+			s.Reset()
+			return
+		}
+		defer active.Add(-1)
+		handleBandwidthTest(s, allow)
+	})
+}
+
+func handleBandwidthTest(s network.Stream, allow func(peer.ID) bool) {
+	remote := s.Conn().RemotePeer()
+	short := remote.String()[:16] + "..."
+
+	if allow != nil && !allow(remote) {
+		slog.Warn("bwtest: rejected from unauthorized peer", "peer", short)
+		s.Reset()
+		return
+	}
+
+	_ = s.SetDeadline(time.Now().Add(bwtestTimeout))
+
+	var hdr [9]byte
+	if _, err := io.ReadFull(s, hdr[:]); err != nil {
+		s.Reset()
+		return
+	}
+	size := int64(binary.BigEndian.Uint64(hdr[1:]))
+	if size <= 0 || size > MaxBandwidthTestBytes {
+		slog.Warn("bwtest: rejected size", "peer", short, "bytes", size)
+		s.Reset()
+		return
+	}
+
+	start := time.Now()
+	switch hdr[0] {
+	case bwtestUpload:
+		n, err := io.CopyN(io.Discard, s, size)
+		if err != nil {
+			s.Reset()
+			return
+		}
+		var trailer [16]byte
+		binary.BigEndian.PutUint64(trailer[:8], uint64(n))
+		binary.BigEndian.PutUint64(trailer[8:], uint64(time.Since(start)))
+		if _, err := s.Write(trailer[:]); err != nil {
+			s.Reset()
+			return
+		}
+	case bwtestDownload:
+		if err := writeZeros(s, size); err != nil {
+			s.Reset()
+			return
+		}
+		var trailer [8]byte
+		binary.BigEndian.PutUint64(trailer[:], uint64(time.Since(start)))
+		if _, err := s.Write(trailer[:]); err != nil {
+			s.Reset()
+			return
+		}
+	default:
+		s.Reset()
+		return
+	}
+	slog.Info("bwtest: served", "peer", short, "bytes", size, "elapsed", time.Since(start).Round(time.Millisecond))
+	s.Close()
+}
+
+// BandwidthTest measures throughput to peerID by transferring size bytes
+// in the given direction (up, down or both; both runs the two directions
+// concurrently on separate streams). Relayed connections are allowed so
+// relay throughput can be compared with a direct path; the result's Path
+// reports which one was used.
+func BandwidthTest(ctx context.Context, h host.Host, peerID peer.ID, direction string, size int64) (*BandwidthResult, error) {
+	if err := ValidateBandwidthDirection(direction); err != nil {
+		return nil, err
+	}
+	if size <= 0 || size > MaxBandwidthTestBytes {
+		return nil, fmt.Errorf("size must be between 1 byte and %d bytes", MaxBandwidthTestBytes)
+	}
+
+	result := &BandwidthResult{PeerID: peerID.String(), Direction: direction}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	run := func(dir byte, dst **BandwidthSample) {
+		defer wg.Done()
+		sample, path, err := bandwidthStream(ctx, h, peerID, dir, size)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		*dst = sample
+		if result.Path == "" || path == "RELAYED" {
+			result.Path = path
+		}
+	}
+	if direction == BandwidthUp || direction == BandwidthBoth {
+		wg.Add(1)
+		go run(bwtestUpload, &result.Upload)
+	}
+	if direction == BandwidthDown || direction == BandwidthBoth {
+		wg.Add(1)
+		go run(bwtestDownload, &result.Download)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// bandwidthStream runs one direction of a test on its own stream.
+func bandwidthStream(ctx context.Context, h host.Host, peerID peer.ID, dir byte, size int64) (*BandwidthSample, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bwtestTimeout)
+	defer cancel()
+
+	s, err := h.NewStream(network.WithAllowLimitedConn(ctx, BandwidthTestProtocol), peerID, protocol.ID(BandwidthTestProtocol))
+	if err != nil {
+		return nil, "", fmt.Errorf("open bwtest stream: %s", HumanizeError(err.Error()))
+	}
+	defer s.Close()
+
+	path := "DIRECT"
+	if s.Conn().Stat().Limited {
+		path = "RELAYED"
+	}
+
+	// Unblock reads and writes when the caller cancels.
+	stop := context.AfterFunc(ctx, func() { s.Reset() })
+	defer stop()
+
+	var hdr [9]byte
+	hdr[0] = dir
+	binary.BigEndian.PutUint64(hdr[1:], uint64(size))
+
+	start := time.Now()
+	if _, err := s.Write(hdr[:]); err != nil {
+		return nil, path, bwtestErr(ctx, "send header", err)
+	}
+
+	sample := &BandwidthSample{Bytes: size}
+	switch dir {
+	case bwtestUpload:
+		if err := writeZeros(s, size); err != nil {
+			return nil, path, bwtestErr(ctx, "upload", err)
+		}
+		if err := s.CloseWrite(); err != nil {
+			return nil, path, bwtestErr(ctx, "upload", err)
+		}
+		var trailer [16]byte
+		if _, err := io.ReadFull(s, trailer[:]); err != nil {
+			return nil, path, bwtestErr(ctx, "read upload result", err)
+		}
+		sample.ElapsedMs = durationMs(time.Since(start))
+		if got := int64(binary.BigEndian.Uint64(trailer[:8])); got != size {
+			return nil, path, fmt.Errorf("peer received %d of %d bytes", got, size)
+		}
+		sample.PeerElapsedMs = durationMs(time.Duration(binary.BigEndian.Uint64(trailer[8:])))
+		sample.Mbps = mbps(size, sample.PeerElapsedMs)
+	case bwtestDownload:
+		if _, err := io.CopyN(io.Discard, s, size); err != nil {
+			return nil, path, bwtestErr(ctx, "download", err)
+		}
+		sample.ElapsedMs = durationMs(time.Since(start))
+		var trailer [8]byte
+		if _, err := io.ReadFull(s, trailer[:]); err != nil {
+			return nil, path, bwtestErr(ctx, "read download result", err)
+		}
+		sample.PeerElapsedMs = durationMs(time.Duration(binary.BigEndian.Uint64(trailer[:])))
+		sample.Mbps = mbps(size, sample.ElapsedMs)
+	}
+	return sample, path, nil
+}
+
+func bwtestErr(ctx context.Context, what string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	if errors.Is(err, network.ErrReset) {
+		return fmt.Errorf("%s: stream reset by peer (refused, too large, or relay limit reached)", what)
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
+
+// writeZeros writes n zero bytes to w in bwtestBufSize chunks.
+func writeZeros(w io.Writer, n int64) error {
+	buf := make([]byte, bwtestBufSize)
+	for n > 0 {
+		chunk := buf
+		if n < int64(len(chunk)) {
+			chunk = chunk[:n]
+		}
+		written, err := w.Write(chunk)
+		if err != nil {
+			return err
+		}
+		n -= int64(written)
+	}
+	return nil
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
+// mbps converts bytes over elapsedMs into megabits per second.
+func mbps(bytes int64, elapsedMs float64) float64 {
+	if elapsedMs <= 0 {
+		return 0
+	}
+	return float64(bytes) * 8 / (elapsedMs / 1000) / 1e6
+}
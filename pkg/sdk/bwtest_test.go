@@ -0,0 +1,98 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestValidateBandwidthDirection(t *testing.T) {
+	for _, dir := range []string{BandwidthUp, BandwidthDown, BandwidthBoth} {
+		if err := ValidateBandwidthDirection(dir); err != nil {
+			t.Errorf("%q: unexpected error %v", dir, err)
+		}
+	}
+	for _, dir := range []string{"", "UP", "sideways"} {
+		if err := ValidateBandwidthDirection(dir); err == nil {
+			t.Errorf("%q: expected error", dir)
+		}
+	}
+}
+
+func TestMbps(t *testing.T) {
+	// 125,000 bytes in 100ms is 1,000,000 bits in 0.1s: 10 Mbps.
+	if got := mbps(125000, 100); got != 10 {
+		t.Errorf("mbps = %f, want 10", got)
+	}
+	if got := mbps(1000, 0); got != 0 {
+		t.Errorf("mbps with zero elapsed = %f, want 0", got)
+	}
+}
+
+func TestBandwidthTest(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	RegisterBandwidthTest(netB.Host(), nil)
+	connectNetworks(t, netA, netB)
+
+	const size = 256 * 1024
+	tests := []struct {
+		dir          string
+		wantUpload   bool
+		wantDownload bool
+	}{
+		{BandwidthUp, true, false},
+		{BandwidthDown, false, true},
+		{BandwidthBoth, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			res, err := BandwidthTest(ctx, netA.Host(), netB.Host().ID(), tt.dir, size)
+			if err != nil {
+				t.Fatalf("BandwidthTest: %v", err)
+			}
+			if res.Path != "DIRECT" {
+				t.Errorf("Path = %q, want DIRECT", res.Path)
+			}
+			if (res.Upload != nil) != tt.wantUpload || (res.Download != nil) != tt.wantDownload {
+				t.Fatalf("upload=%v download=%v, want %v/%v", res.Upload, res.Download, tt.wantUpload, tt.wantDownload)
+			}
+			for _, s := range []*BandwidthSample{res.Upload, res.Download} {
+				if s == nil {
+					continue
+				}
+				if s.Bytes != size || s.Mbps <= 0 || s.ElapsedMs <= 0 {
+					t.Errorf("bad sample: %+v", s)
+				}
+			}
+		})
+	}
+}
+
+func TestBandwidthTest_Rejected(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	RegisterBandwidthTest(netB.Host(), func(peer.ID) bool { return false })
+	connectNetworks(t, netA, netB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := BandwidthTest(ctx, netA.Host(), netB.Host().ID(), BandwidthDown, 1024); err == nil {
+		t.Fatal("expected error from unauthorized test")
+	}
+}
+
+func TestBandwidthTest_InvalidArgs(t *testing.T) {
+	netA := newListeningNetwork(t)
+	ctx := context.Background()
+	if _, err := BandwidthTest(ctx, netA.Host(), netA.Host().ID(), "sideways", 1024); err == nil {
+		t.Error("expected error for invalid direction")
+	}
+	if _, err := BandwidthTest(ctx, netA.Host(), netA.Host().ID(), BandwidthUp, MaxBandwidthTestBytes+1); err == nil {
+		t.Error("expected error for oversized test")
+	}
+}
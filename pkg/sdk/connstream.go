@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
@@ -83,8 +82,8 @@ func ConnGroups(h HostNetwork, peerID peer.ID) []ConnGroup {
 // extraction. This prevents misclassification when a connection's multiaddr
 // doesn't contain /p2p-circuit but the transport is actually relay-limited.
 func classifyConnGroup(conn network.Conn) string {
-	addr := conn.RemoteMultiaddr().String()
-	isCircuit := strings.Contains(addr, "/p2p-circuit")
+	addr := conn.RemoteMultiaddr()
+	isCircuit := ClassifyAddr(addr) == AddrCircuit
 	isLimited := conn.Stat().Limited
 
 	if !isCircuit && !isLimited {
@@ -94,17 +93,8 @@ func classifyConnGroup(conn network.Conn) string {
 	// Connection is relay (Limited flag set, or circuit address, or both).
 	// Try to extract relay peer ID from the circuit address for grouping.
 	if isCircuit {
-		parts := strings.Split(addr, "/p2p-circuit")
-		if len(parts) >= 2 {
-			relayPart := parts[0]
-			segments := strings.Split(relayPart, "/p2p/")
-			if len(segments) >= 2 {
-				relayPeerID := segments[len(segments)-1]
-				if idx := strings.IndexByte(relayPeerID, '/'); idx >= 0 {
-					relayPeerID = relayPeerID[:idx]
-				}
-				return "relay-" + relayPeerID
-			}
+		if relayPeerID := RelayPeerFromAddr(addr); relayPeerID != "" {
+			return "relay-" + relayPeerID.String()
 		}
 	}
 
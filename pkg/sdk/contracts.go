@@ -99,6 +99,8 @@ const (
 	EventAuthAllow                             // An inbound connection was allowed
 	EventAuthDeny                              // An inbound connection was denied
 	EventTransferPending                       // A transfer is awaiting approval (ask mode)
+	EventHolePunchSucceeded                    // A DCUtR hole punch upgraded a relayed connection to direct
+	EventHolePunchFailed                       // A DCUtR hole punch failed (Detail holds the error)
 )
 
 // Event carries details about a network event.
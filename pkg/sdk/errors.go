@@ -14,6 +14,10 @@ var (
 	// that does not exist in the registry.
 	ErrServiceNotFound = errors.New("service not found")
 
+	// ErrServiceRequiresVerification is returned when a peer offers a
+	// service only to peers it has verified, and has not verified us.
+	ErrServiceRequiresVerification = errors.New("service requires verification")
+
 	// ErrNameNotFound is returned when a name cannot be resolved to a peer ID
 	// and is not a valid peer ID itself.
 	ErrNameNotFound = errors.New("name not found")
@@ -21,6 +25,15 @@ var (
 	// ErrResponseTooLarge is returned when a protocol response exceeds the
 	// maximum allowed size.
 	ErrResponseTooLarge = errors.New("response too large")
+
+	// ErrRelaySessionLimit marks a proxy copy error on a relay circuit that
+	// matches how relays end a circuit at its per-session data or time
+	// limit. The relay gives no reason, so this is the likely cause only.
+	ErrRelaySessionLimit = errors.New("relay circuit reset, likely the relay's session data or time limit")
+
+	// ErrServiceLimitExceeded marks a proxy copy error where the remote
+	// service refused the stream for exceeding its per-peer limits.
+	ErrServiceLimitExceeded = errors.New("remote service refused the connection: per-peer limit reached")
 )
 
 // RemoteError wraps an error message returned by a remote peer.
@@ -0,0 +1,256 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// HTTPRoute maps requests for a path prefix (and optionally a Host header)
+// to a local HTTP backend. Used by ExposeHTTPService so one exposed service
+// can front several local web apps.
+type HTTPRoute struct {
+	Path        string // URL path prefix, e.g. "/app1". "/" matches everything.
+	Host        string // Optional Host header match (port ignored). Empty matches any host.
+	Backend     string // Local backend as host:port, e.g. "localhost:8001".
+	StripPrefix bool   // Remove Path from the request before forwarding.
+}
+
+// HTTPPeerIDHeader carries the requesting peer's ID to HTTP route backends.
+// The request's remote address is a peer ID rather than an IP, so
+// X-Forwarded-For can't identify the caller. Any value the client sent is
+// replaced.
+const HTTPPeerIDHeader = "X-Shurli-Peer-ID"
+
+// peerIDContextKey holds the remote peer of an HTTP-routed stream in the
+// request context.
+type peerIDContextKey struct{}
+
+// httpReadHeaderTimeout bounds how long a peer may take to send request
+// headers on an HTTP-routed stream.
+const httpReadHeaderTimeout = 30 * time.Second
+
+type httpRouteEntry struct {
+	route HTTPRoute
+	proxy *httputil.ReverseProxy
+}
+
+// HTTPRouter is an http.Handler that dispatches each request to the route
+// with the longest matching path prefix. A route with a Host beats a
+// host-less route with the same prefix. Unmatched requests get a 404.
+type HTTPRouter struct {
+	routes []httpRouteEntry
+}
+
+// NewHTTPRouter validates routes and builds a router over them.
+func NewHTTPRouter(routes []HTTPRoute) (*HTTPRouter, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("http router requires at least one route")
+	}
+	router := &HTTPRouter{routes: make([]httpRouteEntry, 0, len(routes))}
+	seen := make(map[string]bool, len(routes))
+	for _, rt := range routes {
+		if !strings.HasPrefix(rt.Path, "/") {
+			return nil, fmt.Errorf("route path %q must start with /", rt.Path)
+		}
+		if _, _, err := net.SplitHostPort(rt.Backend); err != nil {
+			return nil, fmt.Errorf("route %s: backend must be host:port, got %q", rt.Path, rt.Backend)
+		}
+		key := strings.ToLower(rt.Host) + rt.Path
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate route for host %q path %q", rt.Host, rt.Path)
+		}
+		seen[key] = true
+		router.routes = append(router.routes, httpRouteEntry{route: rt, proxy: newRouteProxy(rt)})
+	}
+	return router, nil
+}
+
+// newRouteProxy builds the reverse proxy for one route.
+func newRouteProxy(rt HTTPRoute) *httputil.ReverseProxy {
+	target := &url.URL{Scheme: "http", Host: rt.Backend}
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if rt.StripPrefix && rt.Path != "/" {
+				p := strings.TrimPrefix(pr.In.URL.Path, strings.TrimSuffix(rt.Path, "/"))
+				if !strings.HasPrefix(p, "/") {
+					p = "/" + p
+				}
+				pr.Out.URL.Path = p
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Header.Del(HTTPPeerIDHeader)
+			if id, ok := pr.In.Context().Value(peerIDContextKey{}).(peer.ID); ok {
+				pr.Out.Header.Set(HTTPPeerIDHeader, id.String())
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.Warn("http route backend failed", "path", rt.Path, "backend", rt.Backend, "error", err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+}
+
+// match returns the route for r, or nil when none matches.
+func (h *HTTPRouter) match(r *http.Request) *httpRouteEntry {
+	host := r.Host
+	if hst, _, err := net.SplitHostPort(host); err == nil {
+		host = hst
+	}
+	var best *httpRouteEntry
+	for i := range h.routes {
+		e := &h.routes[i]
+		if e.route.Host != "" && !strings.EqualFold(e.route.Host, host) {
+			continue
+		}
+		if !pathHasPrefix(r.URL.Path, e.route.Path) {
+			continue
+		}
+		if best == nil ||
+			len(e.route.Path) > len(best.route.Path) ||
+			(len(e.route.Path) == len(best.route.Path) && e.route.Host != "" && best.route.Host == "") {
+			best = e
+		}
+	}
+	return best
+}
+
+// pathHasPrefix reports whether path falls under prefix on a segment
+// boundary: "/app" matches "/app" and "/app/x" but not "/apple".
+func pathHasPrefix(path, prefix string) bool {
+	if prefix == "/" || path == prefix {
+		return true
+	}
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(path, prefix)
+	}
+	return strings.HasPrefix(path, prefix+"/")
+}
+
+// ServeHTTP implements http.Handler.
+func (h *HTTPRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e := h.match(r)
+	if e == nil {
+		http.NotFound(w, r)
+		return
+	}
+	e.proxy.ServeHTTP(w, r)
+}
+
+// ServeHTTPStream serves HTTP requests arriving on a single libp2p stream
+// with handler, returning once the stream is done. Keep-alive requests on
+// the same stream are served in order.
+func ServeHTTPStream(s network.Stream, handler http.Handler) {
+	conn := &streamNetConn{Stream: s}
+	ln := &singleConnListener{conn: conn, done: make(chan struct{})}
+	conn.onClose = ln.Close
+	remote := s.Conn().RemotePeer()
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+		ConnContext: func(ctx context.Context, _ net.Conn) context.Context {
+			return context.WithValue(ctx, peerIDContextKey{}, remote)
+		},
+	}
+	if err := srv.Serve(ln); err != nil && err != net.ErrClosed {
+		slog.Debug("http stream serve ended", "error", err)
+	}
+}
+
+// ExposeHTTPService exposes an HTTP reverse proxy that routes requests by
+// path (and optionally Host) to local backends. If allowedPeers is nil, all
+// authorized peers can access the service.
+func (n *Network) ExposeHTTPService(name string, routes []HTTPRoute, allowedPeers map[peer.ID]struct{}) error {
+	return n.ExposeHTTPServiceWithOptions(name, routes, allowedPeers, ExposeOptions{})
+}
+
+// ExposeHTTPServiceWithOptions is ExposeHTTPService with per-peer stream
+// limits and the verified-peers restriction. The bandwidth cap does not
+// apply to HTTP services, and LocalNetwork must be empty or "tcp".
+func (n *Network) ExposeHTTPServiceWithOptions(name string, routes []HTTPRoute, allowedPeers map[peer.ID]struct{}, opts ExposeOptions) error {
+	if err := ValidateServiceName(name); err != nil {
+		return err
+	}
+	if opts.LocalNetwork != "" && opts.LocalNetwork != "tcp" {
+		return fmt.Errorf("HTTP service %q cannot use network %q", name, opts.LocalNetwork)
+	}
+	router, err := NewHTTPRouter(routes)
+	if err != nil {
+		return err
+	}
+	return n.serviceRegistry.RegisterService(&Service{
+		Name:     name,
+		Protocol: fmt.Sprintf("/shurli/%s/1.0.0", name),
+		Handler: func(_ string, s network.Stream) {
+			ServeHTTPStream(s, router)
+		},
+		Enabled:         true,
+		AllowedPeers:    allowedPeers,
+		MaxConcurrent:   opts.MaxConcurrent,
+		RatePerMinute:   opts.RatePerMinute,
+		RequireVerified: opts.RequireVerified,
+	})
+}
+
+// streamAddr is the net.Addr of one side of a libp2p stream: the peer ID.
+type streamAddr struct{ id peer.ID }
+
+func (a streamAddr) Network() string { return "libp2p" }
+func (a streamAddr) String() string  { return a.id.String() }
+
+// streamNetConn adapts a libp2p stream to net.Conn for net/http.
+type streamNetConn struct {
+	network.Stream
+	onClose   func() error
+	closeOnce sync.Once
+}
+
+func (c *streamNetConn) LocalAddr() net.Addr  { return streamAddr{c.Conn().LocalPeer()} }
+func (c *streamNetConn) RemoteAddr() net.Addr { return streamAddr{c.Conn().RemotePeer()} }
+
+func (c *streamNetConn) Close() error {
+	err := c.Stream.Close()
+	c.closeOnce.Do(func() { c.onClose() })
+	return err
+}
+
+// singleConnListener hands out one connection, then blocks Accept until
+// that connection is closed so http.Server.Serve returns with it.
+type singleConnListener struct {
+	conn      net.Conn
+	mu        sync.Mutex
+	accepted  bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if !l.accepted {
+		l.accepted = true
+		l.mu.Unlock()
+		return l.conn, nil
+	}
+	l.mu.Unlock()
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+func (l *singleConnListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr { return l.conn.LocalAddr() }
@@ -0,0 +1,202 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testHTTPBackend starts a backend that replies "<name> <path>".
+func testHTTPBackend(t *testing.T, name string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", name, r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestHTTPRouterPathRouting(t *testing.T) {
+	app1 := testHTTPBackend(t, "app1")
+	app2 := testHTTPBackend(t, "app2")
+	api := testHTTPBackend(t, "api")
+	root := testHTTPBackend(t, "root")
+	vhost := testHTTPBackend(t, "vhost")
+
+	router, err := NewHTTPRouter([]HTTPRoute{
+		{Path: "/app1", Backend: app1},
+		{Path: "/app2", Backend: app2, StripPrefix: true},
+		{Path: "/app2/api", Backend: api},
+		{Path: "/", Backend: root},
+		{Path: "/", Host: "docs.example", Backend: vhost},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPRouter: %v", err)
+	}
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		host, path string
+		want       string
+	}{
+		{"", "/app1", "app1 /app1"},
+		{"", "/app1/x/y", "app1 /app1/x/y"},
+		{"", "/app2/page", "app2 /page"},
+		{"", "/app2", "app2 /"},
+		{"", "/app2/api/v1", "api /app2/api/v1"},
+		{"", "/app10", "root /app10"},
+		{"", "/other", "root /other"},
+		{"docs.example", "/guide", "vhost /guide"},
+		{"docs.example:8080", "/guide", "vhost /guide"},
+		{"docs.example", "/app1/z", "app1 /app1/z"},
+	} {
+		req, _ := http.NewRequest("GET", srv.URL+tc.path, nil)
+		if tc.host != "" {
+			req.Host = tc.host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s%s: %v", tc.host, tc.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != tc.want {
+			t.Errorf("GET %s%s = %q, want %q", tc.host, tc.path, body, tc.want)
+		}
+	}
+}
+
+func TestHTTPRouterNoMatch(t *testing.T) {
+	router, err := NewHTTPRouter([]HTTPRoute{{Path: "/app1", Backend: testHTTPBackend(t, "app1")}})
+	if err != nil {
+		t.Fatalf("NewHTTPRouter: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/app2", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unmatched path: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestHTTPRouterBackendDown(t *testing.T) {
+	router, err := NewHTTPRouter([]HTTPRoute{{Path: "/", Backend: "127.0.0.1:1"}})
+	if err != nil {
+		t.Fatalf("NewHTTPRouter: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("backend down: status = %d, want 502", rec.Code)
+	}
+}
+
+func TestNewHTTPRouterInvalid(t *testing.T) {
+	for name, routes := range map[string][]HTTPRoute{
+		"empty":        nil,
+		"relative":     {{Path: "app", Backend: "localhost:8001"}},
+		"no port":      {{Path: "/app", Backend: "localhost"}},
+		"duplicate":    {{Path: "/a", Backend: "localhost:1"}, {Path: "/a", Backend: "localhost:2"}},
+		"dup any case": {{Path: "/a", Host: "X.example", Backend: "localhost:1"}, {Path: "/a", Host: "x.example", Backend: "localhost:2"}},
+	} {
+		if _, err := NewHTTPRouter(routes); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestExposeHTTPService(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	err := netB.ExposeHTTPService("web", []HTTPRoute{
+		{Path: "/app1", Backend: testHTTPBackend(t, "app1")},
+		{Path: "/app2", Backend: testHTTPBackend(t, "app2")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("ExposeHTTPService: %v", err)
+	}
+
+	conn, err := netA.ConnectToServiceContext(context.Background(), netB.Host().ID(), "web")
+	if err != nil {
+		t.Fatalf("ConnectToService: %v", err)
+	}
+	defer conn.Close()
+
+	// Two requests on one stream: keep-alive must route each independently.
+	br := bufio.NewReader(conn)
+	for _, tc := range []struct{ path, want string }{
+		{"/app2/a", "app2 /app2/a"},
+		{"/app1/b", "app1 /app1/b"},
+	} {
+		fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: web\r\n\r\n", tc.path)
+		resp, err := http.ReadResponse(br, nil)
+		if err != nil {
+			t.Fatalf("read response for %s: %v", tc.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != tc.want {
+			t.Errorf("GET %s over stream = %q, want %q", tc.path, body, tc.want)
+		}
+	}
+}
+
+func TestExposeHTTPServicePeerIDHeader(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get(HTTPPeerIDHeader))
+	}))
+	t.Cleanup(backend.Close)
+	routes := []HTTPRoute{{Path: "/", Backend: strings.TrimPrefix(backend.URL, "http://")}}
+	if err := netB.ExposeHTTPService("web", routes, nil); err != nil {
+		t.Fatalf("ExposeHTTPService: %v", err)
+	}
+
+	conn, err := netA.ConnectToServiceContext(context.Background(), netB.Host().ID(), "web")
+	if err != nil {
+		t.Fatalf("ConnectToService: %v", err)
+	}
+	defer conn.Close()
+
+	// A client-supplied header must not reach the backend.
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: web\r\n%s: spoofed\r\n\r\n", HTTPPeerIDHeader)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if got, want := string(body), netA.Host().ID().String(); got != want {
+		t.Errorf("%s = %q, want %q", HTTPPeerIDHeader, got, want)
+	}
+}
+
+func TestExposeHTTPServiceWithOptions(t *testing.T) {
+	n := newListeningNetwork(t)
+	routes := []HTTPRoute{{Path: "/", Backend: "localhost:1"}}
+
+	opts := ExposeOptions{ServiceLimits: ServiceLimits{MaxConcurrent: 2}, RequireVerified: true}
+	if err := n.ExposeHTTPServiceWithOptions("web", routes, nil, opts); err != nil {
+		t.Fatalf("ExposeHTTPServiceWithOptions: %v", err)
+	}
+	if !n.ServiceRegistry().RequiresVerified("web") {
+		t.Error("web should require verification")
+	}
+	if svc, _ := n.ServiceRegistry().GetService("web"); svc.MaxConcurrent != 2 {
+		t.Errorf("MaxConcurrent = %d, want 2", svc.MaxConcurrent)
+	}
+
+	if err := n.ExposeHTTPServiceWithOptions("web-udp", routes, nil, ExposeOptions{LocalNetwork: "udp"}); err == nil {
+		t.Error("udp HTTP service should be rejected")
+	}
+}
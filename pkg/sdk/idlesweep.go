@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+// IdleConnSweeper closes connections that have carried no streams for a
+// configured time (network.idle_connection_timeout). libp2p keeps idle
+// connections to DHT and random peers until the connection manager's high
+// water mark is reached; on small nodes that memory is better freed early.
+//
+// Connections to exempt peers (watched peers and relays, supplied by the
+// caller), to peers protected in the connection manager and to clients
+// holding a reservation on this node as a peer relay are never closed,
+// whatever their activity.
+type IdleConnSweeper struct {
+	host    host.Host
+	timeout time.Duration
+	exempt  func(peer.ID) bool
+	clock   clock.Clock
+
+	mu       sync.Mutex
+	lastBusy map[network.Conn]time.Time // last sweep that saw open streams
+}
+
+// NewIdleConnSweeper creates a sweeper closing connections idle for
+// timeout. exempt may be nil.
+func NewIdleConnSweeper(h host.Host, timeout time.Duration, exempt func(peer.ID) bool) *IdleConnSweeper {
+	return &IdleConnSweeper{
+		host:     h,
+		timeout:  timeout,
+		exempt:   exempt,
+		clock:    clock.Real,
+		lastBusy: make(map[network.Conn]time.Time),
+	}
+}
+
+// relayReservationTag is the connection manager tag circuit relay v2 puts
+// on a peer while it holds a reservation here. The reservation is idle
+// between circuits, but closing its connection would drop it.
+const relayReservationTag = "relay-reservation"
+
+// sweepInterval is how often Run checks connections: a quarter of the
+// timeout, so a connection is closed at most 25% late, within [1s, 1m].
+func (s *IdleConnSweeper) sweepInterval() time.Duration {
+	return min(max(s.timeout/4, time.Second), time.Minute)
+}
+
+// Run sweeps idle connections until ctx is cancelled.
+func (s *IdleConnSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.sweepInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.Sweep(); n > 0 {
+				slog.Debug("idle sweep: closed idle connections", "count", n, "timeout", s.timeout)
+			}
+		}
+	}
+}
+
+// Sweep closes every non-exempt connection with no open streams whose last
+// activity is at least the timeout ago, and returns how many it closed.
+// Activity is the connection's open time or the last sweep that saw it
+// carrying streams, whichever is later.
+func (s *IdleConnSweeper) Sweep() int {
+	now := s.clock.Now()
+	conns := s.host.Network().Conns()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := make(map[network.Conn]bool, len(conns))
+	closed := 0
+	for _, c := range conns {
+		live[c] = true
+		if len(c.GetStreams()) > 0 {
+			s.lastBusy[c] = now
+			continue
+		}
+		p := c.RemotePeer()
+		if s.exempt != nil && s.exempt(p) {
+			continue
+		}
+		if cm := s.host.ConnManager(); cm.IsProtected(p, "") {
+			continue
+		} else if ti := cm.GetTagInfo(p); ti != nil && ti.Tags[relayReservationTag] != 0 {
+			continue
+		}
+		last := c.Stat().Opened
+		if busy, ok := s.lastBusy[c]; ok && busy.After(last) {
+			last = busy
+		}
+		if now.Sub(last) < s.timeout {
+			continue
+		}
+		slog.Debug("idle sweep: closing idle connection",
+			"peer", shortPeerID(p), "addr", c.RemoteMultiaddr(), "idle", now.Sub(last).Round(time.Second))
+		c.Close()
+		delete(s.lastBusy, c)
+		closed++
+	}
+	for c := range s.lastBusy {
+		if !live[c] {
+			delete(s.lastBusy, c)
+		}
+	}
+	return closed
+}
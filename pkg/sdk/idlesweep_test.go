@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+func connectTestHosts(t *testing.T, from, to host.Host) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := from.Connect(ctx, peer.AddrInfo{ID: to.ID(), Addrs: to.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+}
+
+func TestIdleConnSweeper(t *testing.T) {
+	h := newRawTestHost(t)
+	idle := newRawTestHost(t)
+	watched := newRawTestHost(t)
+	busy := newRawTestHost(t)
+	protected := newRawTestHost(t)
+	reserved := newRawTestHost(t)
+
+	// busy keeps a stream open on its connection.
+	busy.SetStreamHandler("/test/idle/1.0.0", func(s network.Stream) {})
+	for _, p := range []host.Host{idle, watched, busy, protected, reserved} {
+		connectTestHosts(t, h, p)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s, err := h.NewStream(ctx, busy.ID(), "/test/idle/1.0.0")
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer s.Close()
+	h.ConnManager().Protect(protected.ID(), "test")
+	// As a peer relay, reserved holds a reservation on h.
+	h.ConnManager().TagPeer(reserved.ID(), relayReservationTag, 10)
+
+	sweeper := NewIdleConnSweeper(h, time.Minute, func(p peer.ID) bool { return p == watched.ID() })
+	fake := clock.NewFake(time.Now())
+	sweeper.clock = fake
+
+	// Nothing has been idle for a minute yet. Identify streams may still
+	// be open right after connecting, so this also marks them busy.
+	if n := sweeper.Sweep(); n != 0 {
+		t.Fatalf("closed %d fresh connections, want 0", n)
+	}
+
+	fake.Advance(2 * time.Minute)
+	if n := sweeper.Sweep(); n != 1 {
+		t.Errorf("closed %d connections, want 1 (the idle one)", n)
+	}
+	if h.Network().Connectedness(idle.ID()) == network.Connected {
+		t.Error("idle non-watched connection should have been closed")
+	}
+	for name, p := range map[string]host.Host{"watched": watched, "busy": busy, "protected": protected, "reserved": reserved} {
+		if h.Network().Connectedness(p.ID()) != network.Connected {
+			t.Errorf("%s peer connection should have been kept", name)
+		}
+	}
+}
+
+func TestIdleConnSweeperRecentActivity(t *testing.T) {
+	h := newRawTestHost(t)
+	p := newRawTestHost(t)
+	p.SetStreamHandler("/test/idle/1.0.0", func(s network.Stream) {})
+	connectTestHosts(t, h, p)
+
+	sweeper := NewIdleConnSweeper(h, time.Minute, nil)
+	fake := clock.NewFake(time.Now().Add(time.Hour)) // connection opened long ago
+	sweeper.clock = fake
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s, err := h.NewStream(ctx, p.ID(), "/test/idle/1.0.0")
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if n := sweeper.Sweep(); n != 0 {
+		t.Fatalf("closed %d connections with an open stream, want 0", n)
+	}
+	s.Reset()
+
+	// The stream is gone, but the connection was busy 30s ago.
+	fake.Advance(30 * time.Second)
+	if n := sweeper.Sweep(); n != 0 {
+		t.Errorf("closed a connection active %v ago, timeout is a minute", 30*time.Second)
+	}
+	fake.Advance(time.Minute)
+	if n := sweeper.Sweep(); n != 1 {
+		t.Errorf("closed %d connections after the timeout, want 1", n)
+	}
+}
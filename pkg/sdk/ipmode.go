@@ -0,0 +1,87 @@
+package sdk
+
+import (
+	"github.com/libp2p/go-libp2p/core/network"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+// filterAddrsForIPMode drops addresses of the family network.ip_mode rules
+// out. In dual mode the slice is returned unchanged.
+func filterAddrsForIPMode(n config.NetworkConfig, addrs []ma.Multiaddr) []ma.Multiaddr {
+	if n.EffectiveIPMode() == config.IPModeDual {
+		return addrs
+	}
+	out := make([]ma.Multiaddr, 0, len(addrs))
+	for _, a := range addrs {
+		if n.IPFamilyAllowed(a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// filterAddrStringsForIPMode is filterAddrsForIPMode for relay address
+// strings. The input slice is never modified.
+func filterAddrStringsForIPMode(n config.NetworkConfig, addrs []string) []string {
+	if n.EffectiveIPMode() == config.IPModeDual {
+		return addrs
+	}
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		if n.AddrAllowed(a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// ipModeDialRanker wraps next so that addresses of a disabled family are
+// never dialed. The swarm dials exactly the addresses the ranker returns,
+// so this covers every dial path (DHT results, cached addresses, relays).
+// Circuit addresses are judged by the relay address they go through.
+func ipModeDialRanker(n config.NetworkConfig, next network.DialRanker) network.DialRanker {
+	return func(addrs []ma.Multiaddr) []network.AddrDelay {
+		return next(filterAddrsForIPMode(n, addrs))
+	}
+}
+
+// ForIPMode returns a copy of the summary with the global addresses of the
+// family network.ip_mode rules out removed, so reachability grading, STUN
+// decisions and IPv6 upgrade probes only consider the family in use. In
+// dual mode the summary itself is returned.
+func (s *InterfaceSummary) ForIPMode(mode string) *InterfaceSummary {
+	if s == nil || mode == config.IPModeDual || mode == "" {
+		return s
+	}
+	c := *s
+	c.Interfaces = make([]InterfaceInfo, len(s.Interfaces))
+	copy(c.Interfaces, s.Interfaces)
+	switch mode {
+	case config.IPModeIPv6:
+		c.HasGlobalIPv4 = false
+		c.GlobalIPv4Addrs = nil
+		for i := range c.Interfaces {
+			c.Interfaces[i].IPv4Addrs = nil
+		}
+	case config.IPModeIPv4:
+		c.HasGlobalIPv6 = false
+		c.GlobalIPv6Addrs = nil
+		for i := range c.Interfaces {
+			c.Interfaces[i].IPv6Addrs = nil
+		}
+	}
+	return &c
+}
+
+// IPModeLabel describes an ip_mode value for status output.
+func IPModeLabel(mode string) string {
+	switch mode {
+	case config.IPModeIPv6:
+		return "ipv6 (IPv6 only, IPv4 disabled)"
+	case config.IPModeIPv4:
+		return "ipv4 (IPv4 only, IPv6 disabled)"
+	}
+	return "dual (IPv4 + IPv6)"
+}
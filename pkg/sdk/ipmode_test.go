@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+func TestFilterAddrsForIPMode(t *testing.T) {
+	addrs := []ma.Multiaddr{
+		ma.StringCast("/ip4/203.0.113.5/tcp/7777"),
+		ma.StringCast("/ip6/2001:db8::5/udp/7777/quic-v1"),
+		ma.StringCast("/dns/relay.example.com/tcp/7777"),
+	}
+	for _, tc := range []struct {
+		mode string
+		want int
+	}{
+		{"", 3}, {config.IPModeDual, 3}, {config.IPModeIPv6, 2}, {config.IPModeIPv4, 2},
+	} {
+		got := filterAddrsForIPMode(config.NetworkConfig{IPMode: tc.mode}, addrs)
+		if len(got) != tc.want {
+			t.Errorf("mode %q: kept %v, want %d addresses", tc.mode, got, tc.want)
+		}
+	}
+
+	ranked := ipModeDialRanker(config.NetworkConfig{IPMode: config.IPModeIPv6}, swarm.NoDelayDialRanker)(addrs)
+	for _, ad := range ranked {
+		if config.AddrIPFamily(ad.Addr) == config.IPModeIPv4 {
+			t.Errorf("ipv6 mode ranker kept %s", ad.Addr)
+		}
+	}
+}
+
+func TestInterfaceSummaryForIPMode(t *testing.T) {
+	s := &InterfaceSummary{
+		Interfaces: []InterfaceInfo{{
+			Name:      "eth0",
+			IPv4Addrs: []string{"203.0.113.5"},
+			IPv6Addrs: []string{"2001:db8::5"},
+		}},
+		GlobalIPv4Addrs: []string{"203.0.113.5"},
+		GlobalIPv6Addrs: []string{"2001:db8::5"},
+		HasGlobalIPv4:   true,
+		HasGlobalIPv6:   true,
+	}
+
+	if got := s.ForIPMode(config.IPModeDual); got != s {
+		t.Error("dual mode should return the summary unchanged")
+	}
+
+	v6 := s.ForIPMode(config.IPModeIPv6)
+	if v6.HasGlobalIPv4 || len(v6.GlobalIPv4Addrs) != 0 || len(v6.Interfaces[0].IPv4Addrs) != 0 {
+		t.Errorf("ipv6 mode kept IPv4: %+v", v6)
+	}
+	if !v6.HasGlobalIPv6 || len(v6.Interfaces[0].IPv6Addrs) != 1 {
+		t.Errorf("ipv6 mode dropped IPv6: %+v", v6)
+	}
+
+	v4 := s.ForIPMode(config.IPModeIPv4)
+	if v4.HasGlobalIPv6 || len(v4.GlobalIPv6Addrs) != 0 || len(v4.Interfaces[0].IPv6Addrs) != 0 {
+		t.Errorf("ipv4 mode kept IPv6: %+v", v4)
+	}
+
+	// The original is left alone.
+	if !s.HasGlobalIPv4 || len(s.Interfaces[0].IPv4Addrs) != 1 || len(s.Interfaces[0].IPv6Addrs) != 1 {
+		t.Errorf("ForIPMode modified the original: %+v", s)
+	}
+
+	var nilSummary *InterfaceSummary
+	if nilSummary.ForIPMode(config.IPModeIPv6) != nil {
+		t.Error("nil summary should stay nil")
+	}
+}
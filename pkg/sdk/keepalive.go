@@ -0,0 +1,138 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// KeepaliveProtocol is the libp2p protocol ID for PeerManager keepalives.
+// The exchange is a single byte echoed back by the remote peer. It is
+// separate from libp2p's own ping service and from the user-facing
+// ping-pong protocol, which logs every request.
+const KeepaliveProtocol = "/shurli/keepalive/1.0.0"
+
+// keepaliveTimeout bounds a single keepalive round trip. A peer that cannot
+// answer within this window is left to libp2p's own liveness handling;
+// the keepalive never closes connections itself.
+const keepaliveTimeout = 10 * time.Second
+
+// keepaliveLoop periodically pings connected watched peers over each of
+// their direct connections. Consumer NATs and stateful firewalls expire idle
+// UDP/TCP mappings (often after 30-120s); regular traffic on the connection
+// keeps the mapping open so the direct path survives long idle periods
+// instead of silently falling back to relay.
+//
+// Relayed (limited) connections are skipped: the relay keeps its own
+// reservation alive, and pinging through it would spend the circuit's
+// data budget for nothing.
+func (pm *PeerManager) keepaliveLoop() {
+	defer pm.wg.Done()
+
+	ticker := time.NewTicker(pm.keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pm.ctx.Done():
+			return
+		case <-ticker.C:
+			pm.sendKeepalives()
+		}
+	}
+}
+
+// sendKeepalives sends one keepalive on every direct connection to each
+// connected watched peer. Failures are logged at debug level only.
+func (pm *PeerManager) sendKeepalives() {
+	pm.mu.RLock()
+	targets := make([]peer.ID, 0, len(pm.peers))
+	for pid, mp := range pm.peers {
+		if mp.Connected {
+			targets = append(targets, pid)
+		}
+	}
+	pm.mu.RUnlock()
+
+	send := pm.keepaliveSend
+	if send == nil {
+		send = sendKeepalive
+	}
+
+	for _, pid := range targets {
+		for _, c := range keepaliveConns(pm.host.Network().ConnsToPeer(pid)) {
+			ctx, cancel := context.WithTimeout(pm.ctx, keepaliveTimeout)
+			if err := send(ctx, c); err != nil {
+				slog.Debug("peermanager: keepalive failed",
+					"peer", shortID(pid),
+					"addr", c.RemoteMultiaddr(),
+					"error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// keepaliveConns returns the connections eligible for keepalives: direct
+// connections only. Limited connections and /p2p-circuit addresses are
+// relayed and excluded.
+func keepaliveConns(conns []network.Conn) []network.Conn {
+	var out []network.Conn
+	for _, c := range conns {
+		if c.Stat().Limited || isCircuitAddr(c.RemoteMultiaddr()) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// sendKeepalive performs one keepalive exchange on the given connection.
+// The stream is opened on c itself (not via host.NewStream) so the traffic
+// refreshes that connection's NAT mapping rather than whichever connection
+// the swarm would pick.
+func sendKeepalive(ctx context.Context, c network.Conn) error {
+	s, err := OpenStreamOnConn(ctx, c, protocol.ID(KeepaliveProtocol))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(keepaliveTimeout)
+	}
+	s.SetDeadline(deadline)
+
+	if _, err := s.Write([]byte{1}); err != nil {
+		s.Reset()
+		return fmt.Errorf("write: %w", err)
+	}
+	var buf [1]byte
+	if _, err := io.ReadFull(s, buf[:]); err != nil {
+		s.Reset()
+		return fmt.Errorf("read: %w", err)
+	}
+	return nil
+}
+
+// handleKeepalive echoes a single keepalive byte back to the sender.
+func handleKeepalive(s network.Stream) {
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(keepaliveTimeout))
+
+	var buf [1]byte
+	if _, err := io.ReadFull(s, buf[:]); err != nil {
+		s.Reset()
+		return
+	}
+	if _, err := s.Write(buf[:]); err != nil {
+		s.Reset()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"math/rand"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,8 +20,9 @@ import (
 )
 
 // MDNSServiceName is the DNS-SD service type used for LAN discovery.
-// Fixed for all Shurli nodes. Network isolation is handled by the
-// ConnectionGater (authorized_keys), not by mDNS service names.
+// Fixed for all Shurli nodes. Network isolation is enforced by the
+// ConnectionGater (authorized_keys); the namespace TXT record only
+// stops nodes from dialing peers of a different private network.
 const MDNSServiceName = "_shurli._udp"
 
 const (
@@ -58,6 +60,11 @@ const (
 
 	// dnsaddrPrefix matches libp2p's TXT record format for multiaddrs.
 	dnsaddrPrefix = "dnsaddr="
+
+	// namespacePrefix carries the advertiser's DHT namespace
+	// (discovery.network). Absent on older nodes and on the global
+	// network, both of which mean the empty namespace.
+	namespacePrefix = "shurli-ns="
 )
 
 // MDNSDiscovery handles LAN peer discovery using mDNS (DNS-SD).
@@ -82,6 +89,14 @@ type MDNSDiscovery struct {
 	// instead of waiting for the next 30s ticker cycle. Nil-safe.
 	peerReconnector interface{ ReconnectPeer(peer.ID) bool }
 
+	// namespace is this node's DHT namespace. Peers advertising a
+	// different one are ignored. Set before Start.
+	namespace string
+
+	// allowPeer reports whether a discovered peer may be dialed.
+	// Nil allows every peer. Set before Start.
+	allowPeer func(peer.ID) bool
+
 	// Managed context for clean shutdown of connection goroutines.
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -168,6 +183,9 @@ func (md *MDNSDiscovery) startServer() error {
 
 	// Build TXT records for addresses suitable for mDNS (IP-based, no relay).
 	var txts []string
+	if md.namespace != "" {
+		txts = append(txts, namespacePrefix+md.namespace)
+	}
 	for _, addr := range p2pAddrs {
 		if isSuitableForMDNS(addr) {
 			txts = append(txts, dnsaddrPrefix+addr.String())
@@ -202,6 +220,43 @@ func (md *MDNSDiscovery) SetPeerReconnector(pr interface{ ReconnectPeer(peer.ID)
 	md.peerReconnector = pr
 }
 
+// SetNamespace sets the DHT namespace advertised to, and required of, LAN
+// peers, so two private networks sharing a LAN don't dial each other.
+// Empty is the global network. Must be called before Start.
+func (md *MDNSDiscovery) SetNamespace(ns string) {
+	md.namespace = ns
+}
+
+// SetPeerFilter restricts which discovered peers are recorded and dialed,
+// typically to the authorized set. Filtered peers are never contacted;
+// the ConnectionGater still rejects any inbound connection they attempt.
+// Must be called before Start.
+func (md *MDNSDiscovery) SetPeerFilter(allow func(peer.ID) bool) {
+	md.allowPeer = allow
+}
+
+// MDNSPeer is a peer recently discovered on the LAN via mDNS.
+type MDNSPeer struct {
+	ID       peer.ID
+	LastSeen time.Time
+}
+
+// Peers returns the peers discovered via mDNS within the LAN registry TTL,
+// most recently seen first.
+func (md *MDNSDiscovery) Peers() []MDNSPeer {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+	out := make([]MDNSPeer, 0, len(md.lanPeers))
+	for id, seen := range md.lanPeers {
+		if time.Since(seen) > lanRegistryTTL {
+			continue
+		}
+		out = append(out, MDNSPeer{ID: id, LastSeen: seen})
+	}
+	slices.SortFunc(out, func(a, b MDNSPeer) int { return b.LastSeen.Compare(a.LastSeen) })
+	return out
+}
+
 // BrowseNow triggers an immediate mDNS re-browse. Called after network
 // changes to discover LAN peers without waiting for the next 30s cycle.
 // Clears dedup timers since the network context has changed.
@@ -282,7 +337,12 @@ func (md *MDNSDiscovery) runBrowse() {
 // and feeds each through HandlePeerFound.
 func (md *MDNSDiscovery) processTextRecords(txts []string) {
 	addrs := make([]ma.Multiaddr, 0, len(txts))
+	var ns string
 	for _, txt := range txts {
+		if v, ok := strings.CutPrefix(txt, namespacePrefix); ok {
+			ns = v
+			continue
+		}
 		if !strings.HasPrefix(txt, dnsaddrPrefix) {
 			continue
 		}
@@ -296,6 +356,13 @@ func (md *MDNSDiscovery) processTextRecords(txts []string) {
 	if len(addrs) == 0 {
 		return
 	}
+	if ns != md.namespace {
+		slog.Debug("mdns: ignoring peer from another network", "namespace", ns)
+		if md.metrics != nil && md.metrics.MDNSDiscoveredTotal != nil {
+			md.metrics.MDNSDiscoveredTotal.WithLabelValues("namespace_mismatch").Inc()
+		}
+		return
+	}
 
 	infos, err := peer.AddrInfosFromP2pAddrs(addrs...)
 	if err != nil {
@@ -322,6 +389,14 @@ func (md *MDNSDiscovery) HandlePeerFound(pi peer.AddrInfo) {
 		short = short[:16] + "..."
 	}
 
+	if md.allowPeer != nil && !md.allowPeer(pi.ID) {
+		slog.Debug("mdns: ignoring unauthorized peer", "peer", short)
+		if md.metrics != nil && md.metrics.MDNSDiscoveredTotal != nil {
+			md.metrics.MDNSDiscoveredTotal.WithLabelValues("unauthorized").Inc()
+		}
+		return
+	}
+
 	// Check if peer needs upgrading BEFORE dedup. Upgrade is needed when:
 	// 1. All connections are relayed (relay->direct upgrade), OR
 	// 2. Direct connections exist but none use LAN IPv4 (internet->LAN upgrade).
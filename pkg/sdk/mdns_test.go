@@ -248,3 +248,61 @@ func TestMDNSDiscovery_TwoHosts(t *testing.T) {
 		}
 	}
 }
+
+func TestMDNSDiscovery_PeerFilter(t *testing.T) {
+	netA := newMDNSNetwork(t)
+	netB := newMDNSNetwork(t)
+
+	md := NewMDNSDiscovery(netA.Host(), nil, nil)
+	md.SetPeerFilter(func(peer.ID) bool { return false })
+
+	addr, _ := ma.NewMultiaddr("/ip4/192.168.1.100/tcp/9999")
+	md.HandlePeerFound(peer.AddrInfo{
+		ID:    netB.Host().ID(),
+		Addrs: []ma.Multiaddr{addr},
+	})
+
+	if addrs := netA.Host().Peerstore().Addrs(netB.Host().ID()); len(addrs) != 0 {
+		t.Errorf("unauthorized peer's addresses added to peerstore: %v", addrs)
+	}
+	if peers := md.Peers(); len(peers) != 0 {
+		t.Errorf("unauthorized peer listed: %v", peers)
+	}
+}
+
+func TestMDNSDiscovery_Namespace(t *testing.T) {
+	netA := newMDNSNetwork(t)
+	netB := newMDNSNetwork(t)
+
+	md := NewMDNSDiscovery(netA.Host(), nil, nil)
+	md.SetNamespace("home-net")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := md.Start(ctx); err != nil {
+		t.Fatalf("md.Start: %v", err)
+	}
+	defer md.Close()
+
+	dnsaddr := dnsaddrPrefix + "/ip4/192.168.1.100/tcp/9999/p2p/" + netB.Host().ID().String()
+
+	// A peer from another private network is ignored.
+	md.processTextRecords([]string{namespacePrefix + "office-net", dnsaddr})
+	if addrs := netA.Host().Peerstore().Addrs(netB.Host().ID()); len(addrs) != 0 {
+		t.Fatalf("peer from another namespace added to peerstore: %v", addrs)
+	}
+
+	// A peer without a namespace record is on the global network.
+	md.processTextRecords([]string{dnsaddr})
+	if addrs := netA.Host().Peerstore().Addrs(netB.Host().ID()); len(addrs) != 0 {
+		t.Fatalf("global-network peer added to peerstore: %v", addrs)
+	}
+
+	md.processTextRecords([]string{namespacePrefix + "home-net", dnsaddr})
+	if addrs := netA.Host().Peerstore().Addrs(netB.Host().ID()); len(addrs) == 0 {
+		t.Fatal("expected addresses for same-namespace peer")
+	}
+	peers := md.Peers()
+	if len(peers) != 1 || peers[0].ID != netB.Host().ID() {
+		t.Errorf("Peers() = %v, want [%s]", peers, netB.Host().ID())
+	}
+}
@@ -0,0 +1,184 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// MessageProtocol carries short text messages between peers for
+// out-of-band coordination ("I'm online, try connecting now").
+//
+// Wire format: the sender writes the UTF-8 text and half-closes; the
+// receiver answers with one byte (messageAccepted) once the message is in
+// its inbox, or resets the stream.
+const MessageProtocol = "/shurli/msg/1.0.0"
+
+const (
+	// MaxMessageBytes caps one message. Messages are for coordination,
+	// not file transfer.
+	MaxMessageBytes = 1024
+
+	// DefaultInboxSize is how many received messages a Messenger keeps.
+	// The oldest are dropped first.
+	DefaultInboxSize = 100
+
+	messageAccepted byte = 1
+	messageTimeout       = 15 * time.Second
+)
+
+// Message is one received message.
+type Message struct {
+	ID       uint64    `json:"id"`
+	From     string    `json:"from"`
+	Text     string    `json:"text"`
+	Received time.Time `json:"received"`
+}
+
+// Messenger sends and receives MessageProtocol messages and keeps received
+// ones in a bounded in-memory inbox.
+type Messenger struct {
+	host  host.Host
+	allow func(peer.ID) bool
+
+	mu     sync.Mutex
+	inbox  []Message
+	size   int
+	nextID uint64
+}
+
+// NewMessenger creates a messenger whose inbox holds inboxSize messages
+// (DefaultInboxSize if <= 0). allow decides which peers may deliver
+// messages; nil accepts any peer the connection gater let in.
+func NewMessenger(h host.Host, inboxSize int, allow func(peer.ID) bool) *Messenger {
+	if inboxSize <= 0 {
+		inboxSize = DefaultInboxSize
+	}
+	return &Messenger{host: h, allow: allow, size: inboxSize}
+}
+
+// Register installs the MessageProtocol stream handler on the host.
+func (m *Messenger) Register() {
+	m.host.SetStreamHandler(protocol.ID(MessageProtocol), m.handleStream)
+}
+
+// ValidateMessageText checks that text is non-empty, within
+// MaxMessageBytes, valid UTF-8, and free of control characters other than
+// newline and tab (received text is printed to terminals).
+func ValidateMessageText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("message is empty")
+	}
+	if len(text) > MaxMessageBytes {
+		return fmt.Errorf("message is %d bytes (max %d)", len(text), MaxMessageBytes)
+	}
+	if !utf8.ValidString(text) {
+		return errors.New("message is not valid UTF-8")
+	}
+	for _, r := range text {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return fmt.Errorf("message contains control character %U", r)
+		}
+	}
+	return nil
+}
+
+// Send delivers text to p and waits for it to be accepted. Relayed
+// connections are allowed: messages are tiny.
+func (m *Messenger) Send(ctx context.Context, p peer.ID, text string) error {
+	if err := ValidateMessageText(text); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, messageTimeout)
+	defer cancel()
+	s, err := m.host.NewStream(network.WithAllowLimitedConn(ctx, MessageProtocol), p, protocol.ID(MessageProtocol))
+	if err != nil {
+		return fmt.Errorf("open message stream: %w", err)
+	}
+	defer s.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.SetDeadline(deadline)
+	}
+	if _, err := io.WriteString(s, text); err != nil {
+		s.Reset()
+		return fmt.Errorf("send message: %w", err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		s.Reset()
+		return fmt.Errorf("send message: %w", err)
+	}
+	var ack [1]byte
+	if _, err := io.ReadFull(s, ack[:]); err != nil || ack[0] != messageAccepted {
+		return errors.New("message rejected by peer")
+	}
+	return nil
+}
+
+func (m *Messenger) handleStream(s network.Stream) {
+	defer s.Close()
+	remote := s.Conn().RemotePeer()
+	short := remote.String()[:16] + "..."
+
+	if m.allow != nil && !m.allow(remote) {
+		slog.Warn("msg: rejected from unauthorized peer", "peer", short)
+		s.Reset()
+		return
+	}
+
+	_ = s.SetDeadline(time.Now().Add(messageTimeout))
+	data, err := io.ReadAll(io.LimitReader(s, MaxMessageBytes+1))
+	if err != nil {
+		s.Reset()
+		return
+	}
+	text := string(data)
+	if err := ValidateMessageText(text); err != nil {
+		slog.Warn("msg: invalid message", "peer", short, "error", err)
+		s.Reset()
+		return
+	}
+
+	m.add(remote, text, time.Now())
+	slog.Info("msg: received", "peer", short, "bytes", len(data))
+	_, _ = s.Write([]byte{messageAccepted})
+}
+
+func (m *Messenger) add(from peer.ID, text string, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	m.inbox = append(m.inbox, Message{ID: m.nextID, From: from.String(), Text: text, Received: now})
+	if over := len(m.inbox) - m.size; over > 0 {
+		m.inbox = append(m.inbox[:0:0], m.inbox[over:]...)
+	}
+}
+
+// Messages returns the inbox, oldest first.
+func (m *Messenger) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Message, len(m.inbox))
+	copy(out, m.inbox)
+	return out
+}
+
+// Clear empties the inbox and returns how many messages it held.
+func (m *Messenger) Clear() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.inbox)
+	m.inbox = nil
+	return n
+}
@@ -0,0 +1,101 @@
+package sdk
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestMessengerRoundTrip(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	sender := NewMessenger(netA.Host(), 0, nil)
+	receiver := NewMessenger(netB.Host(), 0, nil)
+	receiver.Register()
+
+	ctx := context.Background()
+	for _, text := range []string{"I'm online, try connecting now", "second\nline"} {
+		if err := sender.Send(ctx, netB.PeerID(), text); err != nil {
+			t.Fatalf("Send(%q): %v", text, err)
+		}
+	}
+
+	msgs := receiver.Messages()
+	if len(msgs) != 2 {
+		t.Fatalf("inbox has %d messages, want 2", len(msgs))
+	}
+	if msgs[0].Text != "I'm online, try connecting now" || msgs[1].Text != "second\nline" {
+		t.Errorf("texts = %q, %q", msgs[0].Text, msgs[1].Text)
+	}
+	if msgs[0].From != netA.PeerID().String() {
+		t.Errorf("From = %s, want %s", msgs[0].From, netA.PeerID())
+	}
+	if msgs[1].ID <= msgs[0].ID {
+		t.Errorf("IDs not increasing: %d, %d", msgs[0].ID, msgs[1].ID)
+	}
+
+	if n := receiver.Clear(); n != 2 {
+		t.Errorf("Clear() = %d, want 2", n)
+	}
+	if len(receiver.Messages()) != 0 {
+		t.Error("inbox not empty after Clear")
+	}
+}
+
+func TestMessengerRejectsUnauthorized(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	receiver := NewMessenger(netB.Host(), 0, func(peer.ID) bool { return false })
+	receiver.Register()
+
+	err := NewMessenger(netA.Host(), 0, nil).Send(context.Background(), netB.PeerID(), "hello")
+	if err == nil {
+		t.Fatal("expected rejection from unauthorized peer")
+	}
+	if len(receiver.Messages()) != 0 {
+		t.Error("unauthorized message reached the inbox")
+	}
+}
+
+func TestMessengerInboxBounded(t *testing.T) {
+	m := NewMessenger(nil, 3, nil)
+	from := peer.ID("sender")
+	for i := range 5 {
+		m.add(from, strings.Repeat("x", i+1), time.Now())
+	}
+	msgs := m.Messages()
+	if len(msgs) != 3 {
+		t.Fatalf("inbox has %d messages, want 3", len(msgs))
+	}
+	if msgs[0].Text != "xxx" || msgs[2].Text != "xxxxx" {
+		t.Errorf("kept %q..%q, want the newest three", msgs[0].Text, msgs[2].Text)
+	}
+}
+
+func TestValidateMessageText(t *testing.T) {
+	for _, tc := range []struct {
+		text    string
+		wantErr bool
+	}{
+		{"hello", false},
+		{"multi\nline\twith tab", false},
+		{"héllo ✓", false},
+		{"", true},
+		{"   ", true},
+		{strings.Repeat("a", MaxMessageBytes), false},
+		{strings.Repeat("a", MaxMessageBytes+1), true},
+		{"\x1b[31mred", true},
+		{"bad \xff utf8", true},
+	} {
+		if err := ValidateMessageText(tc.text); (err != nil) != tc.wantErr {
+			t.Errorf("ValidateMessageText(%q): err=%v, wantErr=%v", tc.text, err, tc.wantErr)
+		}
+	}
+}
@@ -25,6 +25,7 @@ type Metrics struct {
 	// Hole punch metrics (enhanced from existing holePunchTracer)
 	HolePunchTotal           *prometheus.CounterVec
 	HolePunchDurationSeconds *prometheus.HistogramVec
+	HolePunchPeers           *prometheus.GaugeVec // labels: outcome (upgraded, failing)
 
 	// Daemon API metrics
 	DaemonRequestsTotal          *prometheus.CounterVec
@@ -49,6 +50,12 @@ type Metrics struct {
 	// PeerManager reconnection metrics
 	PeerManagerReconnectTotal *prometheus.CounterVec
 
+	// Watchdog recovery actions (labels: check, result)
+	WatchdogRecoveryTotal *prometheus.CounterVec
+
+	// Streams refused by per-peer service limits (labels: service, reason)
+	ServiceRejectedTotal *prometheus.CounterVec
+
 	// Network intelligence (presence) metrics
 	NetIntelSentTotal     *prometheus.CounterVec
 	NetIntelReceivedTotal *prometheus.CounterVec
@@ -104,6 +111,13 @@ type Metrics struct {
 	RelayHealthScore *prometheus.GaugeVec   // labels: peer, is_static
 	RelayProbeTotal  *prometheus.CounterVec // labels: result
 
+	// Relay server (populated by RelayMetricsTracer and the relay gater)
+	RelayReservationsActive    prometheus.Gauge
+	RelayCircuitsActive        prometheus.Gauge
+	RelayConnectionsGatedTotal *prometheus.CounterVec // labels: result
+	RelayDataRelayedBytesTotal prometheus.Counter
+	RelayGroupBytesTotal       *prometheus.CounterVec // labels: group, direction
+
 	// TS-5: Managed relay connection metrics (R8-I2)
 	ManagedConnsActive          prometheus.Gauge
 	ManagedConnsEstablishedTotal *prometheus.CounterVec // labels: (none)
@@ -180,6 +194,13 @@ func NewMetrics(version, goVersion string) *Metrics {
 			},
 			[]string{"result"},
 		),
+		HolePunchPeers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "shurli_holepunch_peers",
+				Help: "Peers with hole punch attempts: upgraded to direct at least once, or failing every time.",
+			},
+			[]string{"outcome"},
+		),
 
 		DaemonRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -253,6 +274,22 @@ func NewMetrics(version, goVersion string) *Metrics {
 			[]string{"result"},
 		),
 
+		WatchdogRecoveryTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shurli_watchdog_recovery_total",
+				Help: "Total watchdog recovery actions by health check and result (success, failure).",
+			},
+			[]string{"check", "result"},
+		),
+
+		ServiceRejectedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shurli_service_rejected_total",
+				Help: "Total inbound service streams refused by per-peer limits, by service and reason (max_concurrent, rate_per_minute).",
+			},
+			[]string{"service", "reason"},
+		),
+
 		NetIntelSentTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "shurli_netintel_sent_total",
@@ -496,6 +533,39 @@ func NewMetrics(version, goVersion string) *Metrics {
 			[]string{"result"},
 		),
 
+		RelayReservationsActive: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "shurli_relay_reservations_active",
+				Help: "Number of active relay reservations held by this relay server.",
+			},
+		),
+		RelayCircuitsActive: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "shurli_relay_circuits_active",
+				Help: "Number of open relay circuits on this relay server.",
+			},
+		),
+		RelayConnectionsGatedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shurli_relay_connections_gated_total",
+				Help: "Total inbound connections checked by the relay connection gater, by result (allow, deny).",
+			},
+			[]string{"result"},
+		),
+		RelayDataRelayedBytesTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "shurli_relay_data_relayed_bytes_total",
+				Help: "Total bytes relayed through circuits on this relay server.",
+			},
+		),
+		RelayGroupBytesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "shurli_relay_group_bytes_total",
+				Help: "Bytes relayed through circuits on this relay server, by authorized_keys group and direction (in, out).",
+			},
+			[]string{"group", "direction"},
+		),
+
 		// TS-5: Managed relay connection metrics (R8-I2).
 		ManagedConnsActive: prometheus.NewGauge(
 			prometheus.GaugeOpts{
@@ -543,6 +613,7 @@ func NewMetrics(version, goVersion string) *Metrics {
 		m.AuthDecisionsTotal,
 		m.HolePunchTotal,
 		m.HolePunchDurationSeconds,
+		m.HolePunchPeers,
 		m.DaemonRequestsTotal,
 		m.DaemonRequestDurationSeconds,
 		m.ConnectedPeers,
@@ -550,6 +621,8 @@ func NewMetrics(version, goVersion string) *Metrics {
 		m.STUNProbeTotal,
 		m.MDNSDiscoveredTotal,
 		m.PeerManagerReconnectTotal,
+		m.WatchdogRecoveryTotal,
+		m.ServiceRejectedTotal,
 		m.NetIntelSentTotal,
 		m.NetIntelReceivedTotal,
 		m.InterfaceCount,
@@ -583,6 +656,11 @@ func NewMetrics(version, goVersion string) *Metrics {
 		m.BandwidthBytesTotal,
 		m.RelayHealthScore,
 		m.RelayProbeTotal,
+		m.RelayReservationsActive,
+		m.RelayCircuitsActive,
+		m.RelayConnectionsGatedTotal,
+		m.RelayDataRelayedBytesTotal,
+		m.RelayGroupBytesTotal,
 		m.ManagedConnsActive,
 		m.ManagedConnsEstablishedTotal,
 		m.ManagedConnsFailedTotal,
@@ -128,6 +128,11 @@ const (
 	// (e.g., after a network change with 200+ connected peers). Peers
 	// beyond this limit are skipped for this tick and get the next one.
 	maxConcurrentSends = 20
+
+	// identityConflictWarnInterval rate-limits duplicate identity warnings.
+	// A duplicate node announces every interval and each announcement may
+	// reach us via several gossip paths; one warning per window is enough.
+	identityConflictWarnInterval = 10 * time.Minute
 )
 
 // NodeAnnouncement is the presence message exchanged between peers.
@@ -205,6 +210,16 @@ type NetIntel struct {
 
 	announceCh chan struct{} // triggers immediate re-announce
 
+	// Duplicate identity detection. Timestamps of our own recent
+	// announcements are remembered so that a self-originated announcement
+	// arriving via gossip can be told apart from an echo of our own: if we
+	// never sent that timestamp, another node is running with our key.
+	sentMu             sync.Mutex
+	sentTimestamps     map[int64]time.Time // announcement ts -> when sent
+	startedAt          time.Time
+	lastConflictWarn   time.Time
+	onIdentityConflict func(forwardedBy peer.ID)
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -218,20 +233,32 @@ func NewNetIntel(h host.Host, m *Metrics, pf PeerFilter, sp NodeStateProvider, i
 		interval = defaultAnnounceInterval
 	}
 	return &NetIntel{
-		host:          h,
-		metrics:       m,
-		peerFilter:    pf,
-		stateProvider: sp,
-		interval:      interval,
-		cache:         make(map[peer.ID]*PeerAnnouncement),
-		announceCh:    make(chan struct{}, 1),
+		host:           h,
+		metrics:        m,
+		peerFilter:     pf,
+		stateProvider:  sp,
+		interval:       interval,
+		cache:          make(map[peer.ID]*PeerAnnouncement),
+		announceCh:     make(chan struct{}, 1),
+		sentTimestamps: make(map[int64]time.Time),
 	}
 }
 
+// SetOnIdentityConflict registers a callback invoked when an announcement
+// claiming to originate from this node's own peer ID arrives with a
+// timestamp this node never published. That only happens when a second node
+// shares our identity key (typically a copied config directory). The
+// callback is rate-limited to once per identityConflictWarnInterval.
+// Must be called before Start().
+func (ni *NetIntel) SetOnIdentityConflict(fn func(forwardedBy peer.ID)) {
+	ni.onIdentityConflict = fn
+}
+
 // Start registers the stream handler and spawns background goroutines
 // for publishing, cleanup, and gossip forwarding.
 func (ni *NetIntel) Start(ctx context.Context) {
 	ni.ctx, ni.cancel = context.WithCancel(ctx)
+	ni.startedAt = time.Now()
 
 	ni.host.SetStreamHandler(protocol.ID(PresenceProtocol), ni.streamHandler)
 
@@ -340,7 +367,10 @@ func (ni *NetIntel) streamHandler(s network.Stream) {
 	}
 
 	// Don't cache our own announcements (can happen via gossip forwarding).
+	// An announcement with our ID that we never sent means another node
+	// is using our identity key.
 	if originator == ni.host.ID() {
+		ni.checkIdentityConflict(ann.Timestamp, sender)
 		return
 	}
 
@@ -405,6 +435,7 @@ func (ni *NetIntel) publishToAll() {
 	}
 	ann.From = ni.host.ID().String()
 	ann.Hops = 0
+	ni.recordSent(ann.Timestamp)
 
 	data, err := json.Marshal(ann)
 	if err != nil {
@@ -433,6 +464,48 @@ func (ni *NetIntel) publishToAll() {
 	}
 }
 
+// recordSent remembers the timestamp of an announcement we published and
+// prunes entries older than announceTTL (gossip echoes arrive within
+// seconds, so anything older can no longer come back).
+func (ni *NetIntel) recordSent(ts int64) {
+	now := time.Now()
+	ni.sentMu.Lock()
+	defer ni.sentMu.Unlock()
+	ni.sentTimestamps[ts] = now
+	for t, sentAt := range ni.sentTimestamps {
+		if now.Sub(sentAt) > announceTTL {
+			delete(ni.sentTimestamps, t)
+		}
+	}
+}
+
+// checkIdentityConflict reports a duplicate identity when a self-originated
+// announcement carries a timestamp we did not publish. Announcements from
+// before this process started are ignored: they may be late echoes from a
+// previous daemon run with the same key.
+func (ni *NetIntel) checkIdentityConflict(ts int64, sender peer.ID) {
+	if ts < ni.startedAt.Unix() {
+		return
+	}
+	ni.sentMu.Lock()
+	_, ours := ni.sentTimestamps[ts]
+	report := !ours && time.Since(ni.lastConflictWarn) >= identityConflictWarnInterval
+	if report {
+		ni.lastConflictWarn = time.Now()
+	}
+	ni.sentMu.Unlock()
+
+	if ours || !report {
+		return
+	}
+	ni.incRecvMetric("identity_conflict")
+	slog.Error("netintel: another node is announcing with our peer ID (duplicate identity key?)",
+		"peer_id", shortID(ni.host.ID()), "forwarded_by", shortID(sender), "ts", ts)
+	if ni.onIdentityConflict != nil {
+		ni.onIdentityConflict(sender)
+	}
+}
+
 // sendToPeer opens a stream to the target peer and writes the announcement.
 func (ni *NetIntel) sendToPeer(pid peer.ID, data []byte) {
 	ctx, cancel := context.WithTimeout(ni.ctx, streamTimeout)
@@ -2,10 +2,12 @@ package sdk
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 )
@@ -315,3 +317,59 @@ func readCounterVec(t *testing.T, cv *prometheus.CounterVec, label string) float
 	}
 	return m.GetCounter().GetValue()
 }
+
+func TestNetIntel_IdentityConflict(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conflicts := make(chan peer.ID, 4)
+	niA := NewNetIntel(netA.Host(), nil, acceptAll, testStateProvider("A", "full-cone"), time.Hour)
+	niA.SetOnIdentityConflict(func(forwardedBy peer.ID) { conflicts <- forwardedBy })
+	niA.Start(ctx)
+	defer niA.Close()
+
+	send := func(ts int64) {
+		t.Helper()
+		ann := NodeAnnouncement{Version: 1, From: netA.Host().ID().String(), Timestamp: ts, Hops: 1}
+		data, err := json.Marshal(&ann)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := netB.Host().NewStream(ctx, netA.Host().ID(), protocol.ID(PresenceProtocol))
+		if err != nil {
+			t.Fatalf("open stream: %v", err)
+		}
+		s.Write(data)
+		s.Close()
+	}
+
+	// An echo of our own announcement must not be reported.
+	own := time.Now().Unix()
+	niA.recordSent(own)
+	send(own)
+	select {
+	case <-conflicts:
+		t.Fatal("own gossip echo reported as identity conflict")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// An announcement with our ID and a timestamp we never sent is a duplicate.
+	send(own + 1)
+	select {
+	case by := <-conflicts:
+		if by != netB.Host().ID() {
+			t.Errorf("forwardedBy = %s, want %s", by, netB.Host().ID())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected identity conflict callback")
+	}
+
+	// Self-originated announcements are never cached.
+	if pa := niA.GetPeerState(netA.Host().ID()); pa != nil {
+		t.Error("self announcement should not be cached")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/libp2p/go-libp2p"
@@ -111,9 +112,11 @@ func DHTProtocolPrefixForNamespace(namespace string) string {
 
 // holePunchTracer logs DCUtR hole-punching events and records metrics when available.
 type holePunchTracer struct {
-	metrics *Metrics // nil when metrics disabled
-	udpBH   *swarm.BlackHoleSuccessCounter
-	ipv6BH  *swarm.BlackHoleSuccessCounter
+	metrics  *Metrics // nil when metrics disabled
+	udpBH    *swarm.BlackHoleSuccessCounter
+	ipv6BH   *swarm.BlackHoleSuccessCounter
+	outcomes *holePunchCounter // feeds AssessTraversal and HolePunchPeers
+	events   *EventBus         // EventHolePunchSucceeded / EventHolePunchFailed
 }
 
 // truncateError returns the first line of an error string, capped at 200 chars.
@@ -151,6 +154,10 @@ func HumanizeError(err string) string {
 		return "connection refused. The peer or relay is not accepting connections"
 	case strings.Contains(lower, "context deadline exceeded") || strings.Contains(lower, "i/o timeout"):
 		return "connection timed out. The peer may be offline or behind a restrictive firewall"
+	case strings.Contains(lower, ErrRelaySessionLimit.Error()):
+		return ErrRelaySessionLimit.Error() + ".\n" +
+			"  Hint: reconnect to open a fresh relay circuit. For large transfers,\n" +
+			"    ask the relay admin for a bigger data budget or get a direct path"
 	case strings.Contains(lower, "stream reset"):
 		return "connection lost (stream reset by remote peer).\n" +
 			"  Likely causes:\n" +
@@ -198,6 +205,13 @@ func (t *holePunchTracer) Trace(evt *holepunch.Event) {
 		} else {
 			slog.Warn("hole punch failed", "peer", short, "elapsed", e.EllapsedTime, "error", truncateError(e.Error))
 		}
+		errMsg := ""
+		if !e.Success {
+			errMsg = truncateError(e.Error)
+		}
+		if t.outcomes != nil {
+			t.outcomes.record(evt.Remote, e.Success, errMsg)
+		}
 		if t.metrics != nil {
 			result := "failure"
 			if e.Success {
@@ -205,6 +219,18 @@ func (t *holePunchTracer) Trace(evt *holepunch.Event) {
 			}
 			t.metrics.HolePunchTotal.WithLabelValues(result).Inc()
 			t.metrics.HolePunchDurationSeconds.WithLabelValues(result).Observe(e.EllapsedTime.Seconds())
+			if t.outcomes != nil {
+				upgraded, failing := t.outcomes.peerOutcomes()
+				t.metrics.HolePunchPeers.WithLabelValues("upgraded").Set(float64(upgraded))
+				t.metrics.HolePunchPeers.WithLabelValues("failing").Set(float64(failing))
+			}
+		}
+		if t.events != nil {
+			typ := EventHolePunchFailed
+			if e.Success {
+				typ = EventHolePunchSucceeded
+			}
+			t.events.Emit(Event{Type: typ, PeerID: evt.Remote, Detail: errMsg})
 		}
 	case *holepunch.DirectDialEvt:
 		if e.Success {
@@ -226,6 +252,9 @@ type Network struct {
 	events          *EventBus
 	lanRegistry     *LANRegistry    // mDNS-verified LAN peer/IP tracking
 	pathProtector   *PathProtector  // TS-5: managed relay paths during transfers
+	observed        *observedAddrTracker // our addresses as reported by peers via identify
+	holePunches     *holePunchCounter    // DCUtR outcomes, for AssessTraversal
+	relaySource     *relayPeerSource     // AutoRelay candidates; nil without static relays
 	ctx             context.Context
 	cancel          context.CancelFunc
 
@@ -249,6 +278,7 @@ type Config struct {
 	EnableRelay         bool              // Enable relay support (AutoRelay + hole punching)
 	RelayAddrs          []string          // Relay server multiaddrs (e.g., "/ip4/1.2.3.4/tcp/7777/p2p/12D3Koo...")
 	ForcePrivate        bool              // Force private reachability (required for relay reservations)
+	PreferQUICRelay     bool              // Dial RelayAddrs' QUIC addresses before their TCP ones (relay.prefer_quic)
 	EnableNATPortMap    bool              // Enable NAT port mapping
 	EnableHolePunching  bool              // Enable hole punching
 
@@ -296,24 +326,42 @@ func New(cfg *Config) (*Network, error) {
 		}
 	}
 
+	var netCfg config.NetworkConfig
+	if cfg.Config != nil {
+		netCfg = cfg.Config.Network
+	}
+
 	// Create libp2p host options.
 	// Transport order: QUIC first (3 RTTs, native multiplexing, better hole-punching),
 	// TCP second (4 RTTs, universal fallback), WebSocket last (anti-censorship/DPI evasion).
+	// network.transports can switch any of them off; the default runs all.
+	// Keep config.NodeTransports in sync: it drives the relay-address checks.
 	hostOpts := []libp2p.Option{
 		libp2p.Identity(priv),
-		libp2p.Transport(libp2pquic.NewTransport),
-		// Custom QUIC source-IP selector: mirrors the TCP
-		// sourceBindDialerForAddr fix for macOS utun/VPN IPv6 route
-		// hijacking. Without this, QUIC dials to global IPv6 destinations
-		// leave the socket unbound and the kernel routes them through
-		// dead utun interfaces whose default IPv6 routes outrank the real
-		// interfaces. See macos-utun-ipv6-workaround.md and item #7 in
-		// libp2p-overrides.md.
-		libp2p.QUICReuse(quicreuse.NewConnManager, quicreuse.OverrideSourceIPSelector(newShurliQUICSourceSelector)),
-		libp2p.Transport(tcp.NewTCPTransport, tcp.WithDialerForAddr(sourceBindDialerForAddr)),
-		libp2p.Transport(ws.New),
 		libp2p.EnableAutoNATv2(),
 	}
+	if netCfg.TransportEnabled("quic") {
+		hostOpts = append(hostOpts,
+			libp2p.Transport(libp2pquic.NewTransport),
+			// Custom QUIC source-IP selector: mirrors the TCP
+			// sourceBindDialerForAddr fix for macOS utun/VPN IPv6 route
+			// hijacking. Without this, QUIC dials to global IPv6 destinations
+			// leave the socket unbound and the kernel routes them through
+			// dead utun interfaces whose default IPv6 routes outrank the real
+			// interfaces. See macos-utun-ipv6-workaround.md and item #7 in
+			// libp2p-overrides.md.
+			//
+			// The connection manager also applies network.quic tuning (packet
+			// size, MTU discovery, keepalive) for high-latency links.
+			libp2p.QUICReuse(newTunedQUICConnManager(netCfg.QUIC), quicreuse.OverrideSourceIPSelector(newShurliQUICSourceSelector)),
+		)
+	}
+	if netCfg.TransportEnabled("tcp") {
+		hostOpts = append(hostOpts, libp2p.Transport(tcp.NewTCPTransport, tcp.WithDialerForAddr(sourceBindDialerForAddr)))
+	}
+	if netCfg.TransportEnabled("ws") {
+		hostOpts = append(hostOpts, libp2p.Transport(ws.New))
+	}
 
 	// Metrics: when enabled, register libp2p's built-in Prometheus collectors
 	// on our isolated registry. When disabled, turn off libp2p's default metric
@@ -334,9 +382,23 @@ func New(cfg *Config) (*Network, error) {
 		hostOpts = append(hostOpts, libp2p.UserAgent(cfg.UserAgent))
 	}
 
-	// Add listen addresses if configured
-	if cfg.Config != nil && len(cfg.Config.Network.ListenAddresses) > 0 {
-		hostOpts = append(hostOpts, libp2p.ListenAddrStrings(cfg.Config.Network.ListenAddresses...))
+	// Tor (opt-in): dial /onion3 peers and relays through the configured
+	// SOCKS proxy. Checked up front so a stopped tor daemon fails startup
+	// loudly instead of every onion dial timing out later.
+	if cfg.Config != nil && cfg.Config.Network.Tor.Enabled() {
+		socks := cfg.Config.Network.Tor.SOCKSProxy
+		if err := CheckTorProxy(socks); err != nil {
+			cancel()
+			return nil, err
+		}
+		hostOpts = append(hostOpts, libp2p.Transport(newTorTransportConstructor(socks)))
+	}
+
+	// Add listen addresses if configured. Addresses on a transport that
+	// network.transports switched off are dropped rather than failing the
+	// listen, so a sample config with /quic-v1 entries still works.
+	if listen := enabledListenAddrs(netCfg); len(listen) > 0 {
+		hostOpts = append(hostOpts, libp2p.ListenAddrStrings(listen...))
 	}
 
 	// Ensure global IPv6 addresses from all interfaces are advertised.
@@ -345,15 +407,43 @@ func New(cfg *Config) (*Network, error) {
 	// LAN) has global IPv6 but the primary (e.g., 5G WiFi) does not,
 	// those addresses are silently dropped. This factory adds them back
 	// so identify/DHT advertise the full address set to peers.
-	hostOpts = append(hostOpts, libp2p.AddrsFactory(globalIPv6AddrsFactory))
+	//
+	// network.advertise_exclude then drops whole address classes (e.g. ULA)
+	// from what identify and the DHT announce.
+	addrsFactory := globalIPv6AddrsFactory
+	if cfg.Config != nil {
+		if filter := advertiseFilter(cfg.Config.Network.AdvertiseExclude); filter != nil {
+			addrsFactory = func(addrs []ma.Multiaddr) []ma.Multiaddr {
+				return filter(globalIPv6AddrsFactory(addrs))
+			}
+		}
+	}
+	// network.ip_mode: never advertise the disabled family, including
+	// addresses peers observed for us.
+	if netCfg.EffectiveIPMode() != config.IPModeDual {
+		advertised := addrsFactory
+		addrsFactory = func(addrs []ma.Multiaddr) []ma.Multiaddr {
+			return filterAddrsForIPMode(netCfg, advertised(addrs))
+		}
+	}
+	hostOpts = append(hostOpts, libp2p.AddrsFactory(addrsFactory))
 
 	// Create black hole detector counters. Stored so NetworkMonitor can
 	// reset them on network change, and the hole punch tracer can reset
 	// them before each punch attempt (#36).
 	udpBH := &swarm.BlackHoleSuccessCounter{N: 100, MinSuccesses: 5, Name: "UDP"}
 	ipv6BH := &swarm.BlackHoleSuccessCounter{N: 100, MinSuccesses: 5, Name: "IPv6"}
+	holePunches := &holePunchCounter{}
+	events := NewEventBus()
+
+	// UPnP/NAT-PMP port mapping helps direct connections whether or not
+	// relays are in use.
+	if cfg.EnableNATPortMap {
+		hostOpts = append(hostOpts, libp2p.NATPortMap())
+	}
 
 	// Add relay support if enabled
+	var relaySource *relayPeerSource
 	if cfg.EnableRelay {
 		// Parse relay addresses
 		relayInfos, err := ParseRelayAddrs(cfg.RelayAddrs)
@@ -364,6 +454,13 @@ func New(cfg *Config) (*Network, error) {
 
 		if len(relayInfos) > 0 {
 			// Static relay tuning (all defaults are designed for DHT-discovered relays):
+			// - Peer source instead of WithStaticRelays: offers the relays the
+			//   RelaySelector ranks fastest (see SetRelaySelector), so AutoRelay
+			//   reserves on the primary and backup rather than random relays.
+			// - WithNumRelays(2): a primary and a warm backup. WithStaticRelays
+			//   would reserve on every configured relay.
+			// - WithMaxCandidateAge(2m): drops candidates offered before a
+			//   ranking change so a demoted relay is not picked later.
 			// - WithBackoff(30s): reduced from 1h default. Backoff is set BEFORE every
 			//   reservation attempt. After network change kills the relay connection,
 			//   30s means retry within one rsvpRefreshInterval.
@@ -374,7 +471,11 @@ func New(cfg *Config) (*Network, error) {
 			//   We have known static relays, no discovery phase needed.
 			// - WithMinCandidates(1): default 4. We have 2 static relays and want to
 			//   connect to the first available immediately, not wait for more candidates.
-			hostOpts = append(hostOpts, libp2p.EnableAutoRelayWithStaticRelays(relayInfos,
+			relaySource = &relayPeerSource{static: relayInfos}
+			hostOpts = append(hostOpts, libp2p.EnableAutoRelayWithPeerSource(relaySource.candidates,
+				autorelay.WithNumRelays(min(2, len(relayInfos))),
+				autorelay.WithMaxCandidates(len(relayInfos)),
+				autorelay.WithMaxCandidateAge(2*time.Minute),
 				autorelay.WithBackoff(30*time.Second),
 				autorelay.WithMinInterval(5*time.Second),
 				autorelay.WithBootDelay(0),
@@ -382,15 +483,13 @@ func New(cfg *Config) (*Network, error) {
 			))
 		}
 
-		if cfg.EnableNATPortMap {
-			hostOpts = append(hostOpts, libp2p.NATPortMap())
-		}
-
 		if cfg.EnableHolePunching {
 			hostOpts = append(hostOpts, libp2p.EnableHolePunching(holepunch.WithTracer(&holePunchTracer{
-				metrics: cfg.Metrics,
-				udpBH:   udpBH,
-				ipv6BH:  ipv6BH,
+				metrics:  cfg.Metrics,
+				udpBH:    udpBH,
+				ipv6BH:   ipv6BH,
+				outcomes: holePunches,
+				events:   events,
 			})))
 		}
 
@@ -465,12 +564,43 @@ func New(cfg *Config) (*Network, error) {
 		libp2p.IPv6BlackHoleSuccessCounter(ipv6BH),
 	)
 
+	// relay.prefer_quic: hold back the static relays' TCP addresses so the
+	// relay connection, and every circuit over it, runs on QUIC.
+	var rankerHost atomic.Pointer[host.Host]
+	var dialRanker network.DialRanker
+	if cfg.EnableRelay && cfg.PreferQUICRelay {
+		if infos, err := ParseRelayAddrs(cfg.RelayAddrs); err == nil && len(infos) > 0 {
+			relays := make([]peer.ID, len(infos))
+			for i, ai := range infos {
+				relays[i] = ai.ID
+			}
+			dialRanker = relayQUICDialRanker(func() peerstore.Peerstore {
+				if hp := rankerHost.Load(); hp != nil {
+					return (*hp).Peerstore()
+				}
+				return nil
+			}, relays)
+		}
+	}
+	// network.ip_mode: drop addresses of the disabled family before any
+	// ranking, so no dial path can reach them.
+	if netCfg.EffectiveIPMode() != config.IPModeDual {
+		if dialRanker == nil {
+			dialRanker = swarm.DefaultDialRanker
+		}
+		dialRanker = ipModeDialRanker(netCfg, dialRanker)
+	}
+	if dialRanker != nil {
+		hostOpts = append(hostOpts, libp2p.DialRanker(dialRanker))
+	}
+
 	// Create libp2p host
 	h, err := libp2p.New(hostOpts...)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
 	}
+	rankerHost.Store(&h)
 
 	// Create mDNS-verified LAN registry. Shared between gater, connLogger,
 	// and mDNS discovery. Created here so the gater closure can capture it.
@@ -523,8 +653,6 @@ func New(cfg *Config) (*Network, error) {
 		autoGater.SetLANDialFilter(lanDialFilter)
 	}
 
-	events := NewEventBus()
-
 	// Use custom resolver if provided, otherwise default.
 	var resolver *NameResolver
 	if cfg.Resolver != nil {
@@ -545,6 +673,24 @@ func New(cfg *Config) (*Network, error) {
 		cancel:          cancel,
 		udpBlackHole:    udpBH,
 		ipv6BlackHole:   ipv6BH,
+		observed:        newObservedAddrTracker(),
+		holePunches:     holePunches,
+		relaySource:     relaySource,
+	}
+	net.observed.start(ctx, h)
+
+	// Soft admission control on identify agent versions. Static relays are
+	// exempt so a strict allow list cannot cut the node off from them.
+	if cfg.Config != nil && cfg.Config.Security.UserAgentPolicy.Enabled() {
+		staticRelays := make(map[peer.ID]bool)
+		if infos, err := ParseRelayAddrs(cfg.RelayAddrs); err == nil {
+			for _, ai := range infos {
+				staticRelays[ai.ID] = true
+			}
+		}
+		EnforceUserAgentPolicy(ctx, h, cfg.Config.Security.UserAgentPolicy, func(p peer.ID) bool {
+			return staticRelays[p]
+		})
 	}
 
 	// Share the mDNS-verified LAN registry with the service registry so that
@@ -568,6 +714,36 @@ func (n *Network) HasVerifiedLANConn(id peer.ID) bool {
 	return n.lanRegistry.HasVerifiedLANConn(n.host, id)
 }
 
+// IPMode returns the active network.ip_mode (config.IPModeDual when no
+// config was given).
+func (n *Network) IPMode() string {
+	if n == nil || n.config == nil {
+		return config.IPModeDual
+	}
+	return n.config.Network.EffectiveIPMode()
+}
+
+// enabledListenAddrs returns the configured listen addresses whose
+// transport and address family are enabled, logging a warning for each
+// one dropped.
+func enabledListenAddrs(n config.NetworkConfig) []string {
+	var out []string
+	for _, addr := range n.ListenAddresses {
+		if !n.ListenAddrEnabled(addr) {
+			slog.Warn("listen address dropped: its transport is disabled by network.transports",
+				"addr", addr, "transports", n.EnabledTransports)
+			continue
+		}
+		if !n.AddrAllowed(addr) {
+			slog.Info("listen address skipped: its address family is disabled by network.ip_mode",
+				"addr", addr, "ip_mode", n.IPMode)
+			continue
+		}
+		out = append(out, addr)
+	}
+	return out
+}
+
 // sourceBindDialerForAddr returns a source-bound TCP dialer for global IPv6
 // destinations. This fixes macOS routing on systems where disconnected VPN
 // apps (Mullvad, ExpressVPN, ProtonVPN, etc.) create utun interfaces with
@@ -813,6 +989,15 @@ func (n *Network) Host() host.Host {
 	return n.host
 }
 
+// SetRelaySelector makes AutoRelay follow the selector's latency ranking:
+// from then on it is offered only the primary and backup relays. No-op when
+// the network has no static relays.
+func (n *Network) SetRelaySelector(s *RelaySelector) {
+	if n.relaySource != nil {
+		n.relaySource.selector.Store(s)
+	}
+}
+
 // GetLANRegistry returns the mDNS-verified LAN registry. Used by mDNS
 // discovery to register verified addresses and by PeerManager for trust.
 func (n *Network) GetLANRegistry() *LANRegistry {
@@ -839,15 +1024,38 @@ func (n *Network) PeerID() peer.ID {
 // ExposeService exposes a local TCP service through the P2P network.
 // If allowedPeers is nil, all authorized peers can access the service.
 func (n *Network) ExposeService(name, localAddress string, allowedPeers map[peer.ID]struct{}) error {
+	return n.ExposeServiceWithOptions(name, localAddress, allowedPeers, ExposeOptions{})
+}
+
+// ExposeOptions are the settings of an exposed service. They take effect
+// together with the registration, so no stream reaches the service before
+// they apply.
+type ExposeOptions struct {
+	ServiceLimits
+	RequireVerified bool   // refuse peers that are authorized but not verified
+	LocalNetwork    string // "tcp" (default) or "udp"
+}
+
+// ExposeServiceWithOptions is ExposeService with limits, the verified-peers
+// restriction and the local socket type. See ExposeOptions.
+func (n *Network) ExposeServiceWithOptions(name, localAddress string, allowedPeers map[peer.ID]struct{}, opts ExposeOptions) error {
 	if err := ValidateServiceName(name); err != nil {
 		return err
 	}
+	if opts.MaxBandwidthMbps < 0 {
+		return fmt.Errorf("max bandwidth must be >= 0, got %v", opts.MaxBandwidthMbps)
+	}
 	return n.serviceRegistry.RegisterService(&Service{
-		Name:         name,
-		Protocol:     fmt.Sprintf("/shurli/%s/1.0.0", name),
-		LocalAddress: localAddress,
-		Enabled:      true,
-		AllowedPeers: allowedPeers,
+		Name:             name,
+		Protocol:         fmt.Sprintf("/shurli/%s/1.0.0", name),
+		LocalAddress:     localAddress,
+		Enabled:          true,
+		AllowedPeers:     allowedPeers,
+		MaxBandwidthMbps: opts.MaxBandwidthMbps,
+		MaxConcurrent:    opts.MaxConcurrent,
+		RatePerMinute:    opts.RatePerMinute,
+		RequireVerified:  opts.RequireVerified,
+		LocalNetwork:     opts.LocalNetwork,
 	})
 }
 
@@ -921,6 +1129,17 @@ func (n *Network) RegisterServiceQuery() error {
 	})
 }
 
+// QueryServices asks a remote peer which services it offers to this node,
+// using the service-query protocol (which must be registered locally).
+func (n *Network) QueryServices(ctx context.Context, peerID peer.ID) ([]RemoteServiceInfo, error) {
+	stream, err := n.OpenPluginStream(ctx, peerID, "service-query")
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return QueryPeerServices(stream)
+}
+
 // OpenPluginStream opens a stream to a remote peer for a registered plugin,
 // enforcing the plugin's transport and peer policy.
 //
@@ -1221,7 +1440,9 @@ func (n *Network) ConnectToService(peerID peer.ID, serviceName string) (ServiceC
 }
 
 // ConnectToServiceContext connects to a remote peer's service using the provided context.
+// A "name:port" service name addresses one port of a port-range service.
 func (n *Network) ConnectToServiceContext(ctx context.Context, peerID peer.ID, serviceName string) (ServiceConn, error) {
+	serviceName = config.ResolveServiceRef(serviceName)
 	if err := ValidateServiceName(serviceName); err != nil {
 		return nil, err
 	}
@@ -1280,6 +1501,36 @@ func (n *Network) Events() *EventBus {
 	return n.events
 }
 
+// ObservedAddrs returns this node's addresses as observed by connected peers
+// during identify, most recently seen first, each with the peers that
+// reported it. These reflect what NATs actually map us to and are more
+// authoritative than locally detected IPs. Empty until peers connect.
+func (n *Network) ObservedAddrs() []ObservedAddr {
+	if n.observed == nil {
+		return nil
+	}
+	return n.observed.snapshot()
+}
+
+// HolePunchStats returns the DCUtR hole punch outcomes seen since startup.
+// Combine with a STUN result via AssessTraversal.
+func (n *Network) HolePunchStats() HolePunchStats {
+	if n.holePunches == nil {
+		return HolePunchStats{}
+	}
+	return n.holePunches.snapshot()
+}
+
+// HolePunchPeers returns the per-peer DCUtR outcomes seen since startup,
+// most recent attempt first. A peer whose attempts all failed (typically
+// both sides behind symmetric NAT) will stay relayed.
+func (n *Network) HolePunchPeers() []HolePunchPeerStats {
+	if n.holePunches == nil {
+		return nil
+	}
+	return n.holePunches.peerSnapshot()
+}
+
 // Close shuts down the network
 func (n *Network) Close() error {
 	if n.pathProtector != nil {
@@ -1291,6 +1542,10 @@ func (n *Network) Close() error {
 
 // ParseRelayAddrs parses relay multiaddrs into peer.AddrInfo slices.
 // It deduplicates by peer ID and merges addresses for the same relay peer.
+//
+// DNS forms (/dns, /dns4, /dns6, /dnsaddr) are kept unresolved; the libp2p
+// host resolves them at dial time, so a relay survives IP changes as long as
+// its DNS record follows. Every address must still carry a /p2p/<peer-id>.
 func ParseRelayAddrs(relayAddrs []string) ([]peer.AddrInfo, error) {
 	var infos []peer.AddrInfo
 	seen := make(map[peer.ID]bool)
@@ -1303,6 +1558,9 @@ func ParseRelayAddrs(relayAddrs []string) ([]peer.AddrInfo, error) {
 
 		ai, err := peer.AddrInfoFromP2pAddr(maddr)
 		if err != nil {
+			if strings.HasPrefix(s, "/dnsaddr/") {
+				return nil, fmt.Errorf("cannot parse relay addr %s: %w (dnsaddr relays must end with /p2p/<peer-id>)", s, err)
+			}
 			return nil, fmt.Errorf("cannot parse relay addr %s: %w", s, err)
 		}
 
@@ -101,6 +101,40 @@ func TestParseRelayAddrs(t *testing.T) {
 			t.Error("expected error for addr without peer ID")
 		}
 	})
+
+	t.Run("dns addresses", func(t *testing.T) {
+		addrs := []string{
+			"/dns4/relay.example.com/tcp/7777/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An",
+			"/dns6/relay.example.com/udp/7777/quic-v1/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An",
+			"/dnsaddr/relay.example.com/p2p/12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An",
+		}
+		infos, err := ParseRelayAddrs(addrs)
+		if err != nil {
+			t.Fatalf("ParseRelayAddrs: %v", err)
+		}
+		if len(infos) != 1 {
+			t.Fatalf("got %d infos, want 1", len(infos))
+		}
+		if len(infos[0].Addrs) != 3 {
+			t.Fatalf("got %d addrs, want 3 (merged)", len(infos[0].Addrs))
+		}
+		if got := infos[0].Addrs[0].String(); got != "/dns4/relay.example.com/tcp/7777" {
+			t.Errorf("addr[0] = %s, want unresolved /dns4 form", got)
+		}
+		if got := infos[0].Addrs[2].String(); got != "/dnsaddr/relay.example.com" {
+			t.Errorf("addr[2] = %s, want unresolved /dnsaddr form", got)
+		}
+	})
+
+	t.Run("dnsaddr missing peer ID", func(t *testing.T) {
+		_, err := ParseRelayAddrs([]string{"/dnsaddr/relay.example.com"})
+		if err == nil {
+			t.Fatal("expected error for dnsaddr without peer ID")
+		}
+		if !strings.Contains(err.Error(), "/p2p/<peer-id>") {
+			t.Errorf("error should explain the missing peer ID, got: %v", err)
+		}
+	})
 }
 
 // newListeningNetwork creates a sdk.Network that listens on localhost TCP.
@@ -122,6 +156,62 @@ func newListeningNetwork(t *testing.T) *Network {
 	return net
 }
 
+func TestNewTransportSelection(t *testing.T) {
+	net, err := New(&Config{
+		KeyFile: filepath.Join(t.TempDir(), "test.key"),
+		Config: &config.Config{
+			Network: config.NetworkConfig{
+				ListenAddresses:   []string{"/ip4/127.0.0.1/tcp/0", "/ip4/127.0.0.1/udp/0/quic-v1"},
+				EnabledTransports: []string{"tcp"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer net.Close()
+
+	// The QUIC listen address is dropped instead of failing the listen.
+	var tcpListeners int
+	for _, a := range net.Host().Network().ListenAddresses() {
+		if strings.Contains(a.String(), "quic") {
+			t.Errorf("listening on %s with QUIC disabled", a)
+		}
+		if strings.Contains(a.String(), "/tcp/") {
+			tcpListeners++
+		}
+	}
+	if tcpListeners != 1 {
+		t.Errorf("TCP listeners = %d, want 1", tcpListeners)
+	}
+}
+
+func TestNewIPModeSelection(t *testing.T) {
+	net, err := New(&Config{
+		KeyFile: filepath.Join(t.TempDir(), "test.key"),
+		Config: &config.Config{
+			Network: config.NetworkConfig{
+				ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0", "/ip6/::1/tcp/0"},
+				IPMode:          config.IPModeIPv4,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer net.Close()
+
+	if net.IPMode() != config.IPModeIPv4 {
+		t.Errorf("IPMode = %q, want ipv4", net.IPMode())
+	}
+	// The IPv6 listen address is dropped, and nothing IPv6 is advertised.
+	for _, a := range append(net.Host().Network().ListenAddresses(), net.Host().Addrs()...) {
+		if config.AddrIPFamily(a) == config.IPModeIPv6 {
+			t.Errorf("IPv6 address %s in ipv4 mode", a)
+		}
+	}
+}
+
 // connectNetworks connects Network A to Network B via localhost.
 func connectNetworks(t *testing.T, a, b *Network) {
 	t.Helper()
@@ -304,6 +394,58 @@ func TestTracePeerDirect(t *testing.T) {
 	if result.Hops[0].RttMs <= 0 && result.Hops[0].Error == "" {
 		t.Errorf("expected positive RTT or error, got rtt=%f", result.Hops[0].RttMs)
 	}
+	if result.Hops[0].Role != HopRoleTarget {
+		t.Errorf("hop Role = %q, want %q", result.Hops[0].Role, HopRoleTarget)
+	}
+	if got := result.PathSummary(); got != "DIRECT" {
+		t.Errorf("PathSummary = %q, want DIRECT", got)
+	}
+}
+
+func TestTraceSegments(t *testing.T) {
+	hops := []TraceHop{
+		{Hop: 1, Role: HopRoleRelay, PeerID: "12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An", RttMs: 20},
+		{Hop: 2, Role: HopRoleTarget, RttMs: 45},
+	}
+	fillSegments(hops)
+	if hops[0].SegmentMs != 20 || hops[1].SegmentMs != 25 {
+		t.Errorf("segments = %v, %v; want 20, 25", hops[0].SegmentMs, hops[1].SegmentMs)
+	}
+
+	r := &TraceResult{Path: "RELAYED", Hops: hops}
+	if got := r.PathSummary(); got != "RELAYED via 12D3KooWRzaGMTqQ..." {
+		t.Errorf("PathSummary = %q", got)
+	}
+	r.Hops[0].Name = "relay-server/0.1.0"
+	if got := r.PathSummary(); got != "RELAYED via relay-server/0.1.0" {
+		t.Errorf("PathSummary = %q", got)
+	}
+	if got := r.Hops[0].Label(); got != " (relay, relay-server/0.1.0)" {
+		t.Errorf("relay Label = %q", got)
+	}
+	if got := r.Hops[1].Label(); got != "" {
+		t.Errorf("unnamed target Label = %q, want empty", got)
+	}
+	if got := r.Hops[0].SegmentNote(); got != "" {
+		t.Errorf("first hop SegmentNote = %q, want empty", got)
+	}
+	if got := r.Hops[1].SegmentNote(); got != "(+25.0ms after hop 1)" {
+		t.Errorf("SegmentNote = %q", got)
+	}
+
+	// An unmeasured relay, or jitter making the target look closer than
+	// the relay, leaves the target's segment empty.
+	hops = []TraceHop{
+		{Hop: 1, Role: HopRoleRelay, Error: "relay identity unknown"},
+		{Hop: 2, Role: HopRoleTarget, RttMs: 45},
+		{Hop: 3, Role: HopRoleTarget, RttMs: 40},
+	}
+	fillSegments(hops)
+	for _, h := range hops {
+		if h.SegmentMs != 0 {
+			t.Errorf("hop %d SegmentMs = %v, want 0", h.Hop, h.SegmentMs)
+		}
+	}
 }
 
 func TestTracePeerNotConnected(t *testing.T) {
@@ -0,0 +1,159 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+const (
+	// maxObservedAddrs bounds how many distinct observed addresses are
+	// kept. Peers control what they report, so the table must not grow
+	// without limit; the least recently confirmed address is evicted.
+	maxObservedAddrs = 32
+
+	// maxObservedReporters bounds the reporting peers kept per address.
+	maxObservedReporters = 8
+
+	// observedAddrTTL drops observations no peer has confirmed recently.
+	// Identify runs on every new connection, so active addresses are
+	// refreshed well within this window.
+	observedAddrTTL = time.Hour
+)
+
+// ObservedAddr is one of this node's addresses as seen by remote peers
+// during identify. Unlike locally detected interface IPs or STUN results,
+// it is the address peers actually received our connection from, which
+// makes it the most direct evidence of what a NAT maps us to.
+type ObservedAddr struct {
+	Addr       string    `json:"addr"`
+	ReportedBy []string  `json:"reported_by"` // peer IDs, most recent first
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// observedAddrTracker records the observed address from each completed
+// identify exchange. Safe for concurrent use.
+type observedAddrTracker struct {
+	mu    sync.Mutex
+	addrs map[string]*ObservedAddr
+	clock clock.Clock
+}
+
+func newObservedAddrTracker() *observedAddrTracker {
+	return &observedAddrTracker{
+		addrs: make(map[string]*ObservedAddr),
+		clock: clock.Real,
+	}
+}
+
+// start subscribes to identify completions synchronously (so no event
+// after start returns is missed) and records them until ctx is cancelled.
+func (t *observedAddrTracker) start(ctx context.Context, h host.Host) {
+	sub, err := h.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		slog.Warn("observed-addrs: event bus subscribe failed", "error", err)
+		return
+	}
+	go t.run(ctx, sub)
+}
+
+func (t *observedAddrTracker) run(ctx context.Context, sub event.Subscription) {
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-sub.Out():
+			if !ok {
+				return
+			}
+			e := evt.(event.EvtPeerIdentificationCompleted)
+			t.record(e.Peer, e.ObservedAddr)
+		}
+	}
+}
+
+// record notes that reporter saw us at addr. Relay circuit addresses are
+// ignored: they describe the relay's view of the circuit, not our NAT.
+func (t *observedAddrTracker) record(reporter peer.ID, addr ma.Multiaddr) {
+	if addr == nil || isCircuitAddr(addr) {
+		return
+	}
+	key := addr.String()
+	now := t.clock.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expireLocked(now)
+
+	oa, ok := t.addrs[key]
+	if !ok {
+		if len(t.addrs) >= maxObservedAddrs {
+			t.evictOldestLocked()
+		}
+		oa = &ObservedAddr{Addr: key}
+		t.addrs[key] = oa
+	}
+	oa.LastSeen = now
+
+	rid := reporter.String()
+	reporters := []string{rid}
+	for _, r := range oa.ReportedBy {
+		if r != rid && len(reporters) < maxObservedReporters {
+			reporters = append(reporters, r)
+		}
+	}
+	oa.ReportedBy = reporters
+}
+
+// snapshot returns the current observations, most recently seen first.
+func (t *observedAddrTracker) snapshot() []ObservedAddr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expireLocked(t.clock.Now())
+
+	out := make([]ObservedAddr, 0, len(t.addrs))
+	for _, oa := range t.addrs {
+		c := *oa
+		c.ReportedBy = append([]string(nil), oa.ReportedBy...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.After(out[j].LastSeen)
+		}
+		return out[i].Addr < out[j].Addr
+	})
+	return out
+}
+
+func (t *observedAddrTracker) expireLocked(now time.Time) {
+	for k, oa := range t.addrs {
+		if now.Sub(oa.LastSeen) > observedAddrTTL {
+			delete(t.addrs, k)
+		}
+	}
+}
+
+func (t *observedAddrTracker) evictOldestLocked() {
+	var oldestKey string
+	var oldest time.Time
+	for k, oa := range t.addrs {
+		if oldestKey == "" || oa.LastSeen.Before(oldest) {
+			oldestKey, oldest = k, oa.LastSeen
+		}
+	}
+	delete(t.addrs, oldestKey)
+}
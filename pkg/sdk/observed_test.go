@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+func TestObservedAddrTracker_Record(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1_700_000_000, 0))
+	tr := newObservedAddrTracker()
+	tr.clock = fake
+
+	pA := peer.ID("peer-a")
+	pB := peer.ID("peer-b")
+	addr := ma.StringCast("/ip4/203.0.113.7/udp/4001/quic-v1")
+
+	tr.record(pA, addr)
+	fake.Advance(time.Second)
+	tr.record(pB, addr)
+	fake.Advance(time.Second)
+	tr.record(pA, addr) // repeat reporter moves to the front, no duplicate
+
+	// Circuit addresses describe the relay's view and are ignored.
+	tr.record(pA, ma.StringCast("/ip4/203.0.113.9/tcp/7777/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN/p2p-circuit"))
+	tr.record(pA, nil)
+
+	got := tr.snapshot()
+	if len(got) != 1 {
+		t.Fatalf("snapshot = %+v, want 1 address", got)
+	}
+	if got[0].Addr != addr.String() {
+		t.Errorf("Addr = %s, want %s", got[0].Addr, addr)
+	}
+	if want := []string{pA.String(), pB.String()}; fmt.Sprint(got[0].ReportedBy) != fmt.Sprint(want) {
+		t.Errorf("ReportedBy = %v, want %v", got[0].ReportedBy, want)
+	}
+	if !got[0].LastSeen.Equal(fake.Now()) {
+		t.Errorf("LastSeen = %v, want %v", got[0].LastSeen, fake.Now())
+	}
+
+	// Observations expire once no peer has confirmed them within the TTL.
+	fake.Advance(observedAddrTTL + time.Second)
+	if got := tr.snapshot(); len(got) != 0 {
+		t.Errorf("expected expired observations to be dropped, got %+v", got)
+	}
+}
+
+func TestObservedAddrTracker_Bounded(t *testing.T) {
+	fake := clock.NewFake(time.Unix(1_700_000_000, 0))
+	tr := newObservedAddrTracker()
+	tr.clock = fake
+
+	for i := 0; i < maxObservedAddrs+5; i++ {
+		fake.Advance(time.Second)
+		tr.record(peer.ID(fmt.Sprintf("peer-%d", i)), ma.StringCast(fmt.Sprintf("/ip4/198.51.100.1/tcp/%d", 1000+i)))
+	}
+	got := tr.snapshot()
+	if len(got) != maxObservedAddrs {
+		t.Fatalf("len = %d, want %d", len(got), maxObservedAddrs)
+	}
+	// Oldest were evicted; newest is first.
+	if want := fmt.Sprintf("/ip4/198.51.100.1/tcp/%d", 1000+maxObservedAddrs+4); got[0].Addr != want {
+		t.Errorf("newest = %s, want %s", got[0].Addr, want)
+	}
+
+	addr := ma.StringCast("/ip4/198.51.100.2/tcp/1")
+	for i := 0; i < maxObservedReporters+3; i++ {
+		tr.record(peer.ID(fmt.Sprintf("reporter-%d", i)), addr)
+	}
+	for _, oa := range tr.snapshot() {
+		if oa.Addr == addr.String() && len(oa.ReportedBy) != maxObservedReporters {
+			t.Errorf("reporters = %d, want %d", len(oa.ReportedBy), maxObservedReporters)
+		}
+	}
+}
+
+func TestNetworkObservedAddrs(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, oa := range netA.ObservedAddrs() {
+			for _, r := range oa.ReportedBy {
+				if r == netB.PeerID().String() {
+					return
+				}
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("no observed address reported by peer B; got %+v", netA.ObservedAddrs())
+}
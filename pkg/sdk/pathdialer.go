@@ -11,8 +11,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
 	"github.com/libp2p/go-libp2p/p2p/net/swarm"
 	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/config"
 )
 
 // PathType describes how a peer connection was established.
@@ -31,13 +34,45 @@ type DialResult struct {
 }
 
 // PathDialer connects to peers using parallel path racing. It launches
-// DHT discovery and relay circuit attempts concurrently and returns as
-// soon as the first path succeeds, cancelling the other.
+// DHT discovery and relay circuit attempts concurrently, plus cached
+// addresses when a PeerCache is set, and returns as soon as the first path
+// succeeds, cancelling the others.
 type PathDialer struct {
 	host        host.Host
 	kdht        *dht.IpfsDHT // may be nil (no DHT)
 	relaySource RelaySource  // provides relay addresses (static or dynamic)
 	metrics     *Metrics     // nil-safe
+	region      string       // preferred relay region ("" = no preference)
+	cache       *PeerCache   // may be nil (no address cache)
+	rendezvous  []string     // searched when FindPeer comes up empty
+	ipMode      string       // network.ip_mode ("" = dual)
+}
+
+// SetIPMode limits the cached and relay addresses the dialer tries to one
+// address family (network.ip_mode). Call before the dialer is in use.
+func (pd *PathDialer) SetIPMode(mode string) {
+	pd.ipMode = mode
+}
+
+// SetPreferredRegion makes relays that advertise region (see
+// RelayAgentRegion) race first when dialing through relays. Call before
+// the dialer is in use.
+func (pd *PathDialer) SetPreferredRegion(region string) {
+	pd.region = region
+}
+
+// SetPeerCache makes the dialer race cached peer addresses alongside the
+// DHT and relay legs, and record addresses found through the DHT. Call
+// before the dialer is in use.
+func (pd *PathDialer) SetPeerCache(c *PeerCache) {
+	pd.cache = c
+}
+
+// SetRendezvous makes the DHT leg search the providers of each rendezvous
+// for the target when a plain FindPeer fails. Call before the dialer is in
+// use.
+func (pd *PathDialer) SetRendezvous(rendezvous []string) {
+	pd.rendezvous = rendezvous
 }
 
 // NewPathDialer creates a PathDialer. The DHT and metrics are optional (nil-safe).
@@ -54,8 +89,8 @@ func NewPathDialer(h host.Host, kdht *dht.IpfsDHT, relaySource RelaySource, m *M
 
 // DialPeer connects to the target peer using parallel path racing.
 // If already connected, it returns immediately with the current path type.
-// Otherwise it races DHT discovery against relay circuit, returning the
-// first successful connection.
+// Otherwise it races DHT discovery against relay circuit (and cached
+// addresses, if any), returning the first successful connection.
 func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult, error) {
 	start := time.Now()
 
@@ -77,10 +112,40 @@ func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult
 		err      error
 	}
 
-	resultCh := make(chan raceResult, 2)
+	legs := 2
+	resultCh := make(chan raceResult, 3)
 	raceCtx, raceCancel := context.WithCancel(ctx)
 	defer raceCancel()
 
+	// Cached and relay addresses of a family ip_mode rules out are not
+	// worth a leg: the dial ranker would drop them anyway.
+	ipCfg := config.NetworkConfig{IPMode: pd.ipMode}
+
+	// Leg 0: cached addresses from an earlier DHT lookup. Runs alongside
+	// the other legs so a stale entry only costs its own timeout.
+	if pd.cache != nil {
+		if addrs, _, ok := pd.cache.Lookup(peerID); ok && len(filterAddrsForIPMode(ipCfg, addrs)) > 0 {
+			legs++
+			go func() {
+				connectCtx, connectCancel := context.WithTimeout(raceCtx, 10*time.Second)
+				defer connectCancel()
+
+				if err := pd.host.Connect(connectCtx, peer.AddrInfo{ID: peerID, Addrs: addrs}); err != nil {
+					if raceCtx.Err() == nil {
+						pd.cache.Forget(peerID)
+					}
+					resultCh <- raceResult{err: fmt.Errorf("cache connect: %w", err)}
+					return
+				}
+				pd.cache.Touch(peerID)
+				resultCh <- raceResult{
+					pathType: classifyConnection(pd.host, peerID),
+					addr:     firstConnAddr(pd.host, peerID),
+				}
+			}()
+		}
+	}
+
 	// Leg 1: DHT FindPeer + Connect
 	if pd.kdht != nil {
 		go func() {
@@ -89,8 +154,11 @@ func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult
 
 			pi, err := pd.kdht.FindPeer(findCtx, peerID)
 			if err != nil {
-				resultCh <- raceResult{err: fmt.Errorf("DHT: %w", err)}
-				return
+				var ok bool
+				if pi, ok = pd.findInRendezvous(raceCtx, peerID); !ok {
+					resultCh <- raceResult{err: fmt.Errorf("DHT: %w", err)}
+					return
+				}
 			}
 
 			connectCtx, connectCancel := context.WithTimeout(raceCtx, 15*time.Second)
@@ -100,6 +168,9 @@ func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult
 				resultCh <- raceResult{err: fmt.Errorf("DHT connect: %w", err)}
 				return
 			}
+			if pd.cache != nil {
+				pd.cache.Store(peerID, pi.Addrs)
+			}
 
 			resultCh <- raceResult{
 				pathType: classifyConnection(pd.host, peerID),
@@ -119,7 +190,8 @@ func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult
 	// 100ms stagger. First circuit wins, context cancels losers.
 	var relayAddrs []string
 	if pd.relaySource != nil {
-		relayAddrs = pd.relaySource.RelayAddrs()
+		relayAddrs = preferRelayRegion(pd.host, pd.relaySource.RelayAddrs(), pd.region)
+		relayAddrs = filterAddrStringsForIPMode(ipCfg, relayAddrs)
 	}
 	if len(relayAddrs) > 0 {
 		go func() {
@@ -224,9 +296,9 @@ func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult
 		}()
 	}
 
-	// Wait for the first success or both failures
-	var firstErr, secondErr error
-	for i := 0; i < 2; i++ {
+	// Wait for the first success or every leg to fail
+	var errs []string
+	for i := 0; i < legs; i++ {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
@@ -242,17 +314,13 @@ func (pd *PathDialer) DialPeer(ctx context.Context, peerID peer.ID) (*DialResult
 				pd.recordMetric(result)
 				return result, nil
 			}
-			if firstErr == nil {
-				firstErr = r.err
-			} else {
-				secondErr = r.err
-			}
+			errs = append(errs, r.err.Error())
 		}
 	}
 
-	// Both failed
+	// All legs failed
 	pd.recordFailure()
-	return nil, fmt.Errorf("all paths failed: %v; %v", firstErr, secondErr)
+	return nil, fmt.Errorf("all paths failed: %s", strings.Join(errs, "; "))
 }
 
 // recordMetric records a successful dial in Prometheus.
@@ -356,6 +424,33 @@ func AddRelayAddressesForPeerFunc(h host.Host, relayAddrs []string, target peer.
 	return nil
 }
 
+// findInRendezvous looks for target among the providers of each
+// rendezvous in turn, one lookup at a time. It gets its own time budget,
+// since the FindPeer before it may have used up the first one.
+func (pd *PathDialer) findInRendezvous(ctx context.Context, target peer.ID) (peer.AddrInfo, bool) {
+	if len(pd.rendezvous) == 0 {
+		return peer.AddrInfo{}, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	disc := drouting.NewRoutingDiscovery(pd.kdht)
+	for _, ns := range pd.rendezvous {
+		peers, err := disc.FindPeers(ctx, ns)
+		if err != nil {
+			continue
+		}
+		for pi := range peers {
+			if pi.ID == target && len(pi.Addrs) > 0 {
+				return pi, true
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return peer.AddrInfo{}, false
+}
+
 // groupRelayAddrsByPeer groups relay addresses by relay server peer ID, returning
 // one peer.AddrInfo per relay server with circuit addresses targeting the given peer.
 // Each group becomes an independent hedged connection attempt (TS-1).
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -234,6 +235,89 @@ func TestPathDialer_BothPathsFail(t *testing.T) {
 	t.Logf("Expected error: %v", err)
 }
 
+func TestPathDialer_CachedAddrs(t *testing.T) {
+	// No DHT, no relay: only the cached addresses can reach the target.
+	h1, err := libp2p.New(
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+		libp2p.NoSecurity,
+		libp2p.DisableRelay(),
+	)
+	if err != nil {
+		t.Fatalf("host1: %v", err)
+	}
+	defer h1.Close()
+
+	h2, err := libp2p.New(
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+		libp2p.NoSecurity,
+		libp2p.DisableRelay(),
+	)
+	if err != nil {
+		t.Fatalf("host2: %v", err)
+	}
+	defer h2.Close()
+
+	cache := NewPeerCache("", time.Hour)
+	cache.Store(h2.ID(), h2.Addrs())
+
+	pd := NewPathDialer(h1, nil, nil, nil)
+	pd.SetPeerCache(cache)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := pd.DialPeer(ctx, h2.ID())
+	if err != nil {
+		t.Fatalf("DialPeer: %v", err)
+	}
+	if result.PathType != PathDirect {
+		t.Errorf("PathType = %q, want DIRECT", result.PathType)
+	}
+}
+
+func TestPathDialer_StaleCacheForgotten(t *testing.T) {
+	h, err := libp2p.New(
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+		libp2p.NoSecurity,
+		libp2p.DisableRelay(),
+	)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	defer h.Close()
+
+	h2, err := libp2p.New(
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+		libp2p.NoSecurity,
+		libp2p.DisableRelay(),
+	)
+	if err != nil {
+		t.Fatalf("host2: %v", err)
+	}
+	targetID, addrs := h2.ID(), h2.Addrs()
+	h2.Close() // cached addresses now point nowhere
+
+	cache := NewPeerCache("", time.Hour)
+	cache.Store(targetID, addrs)
+
+	pd := NewPathDialer(h, nil, nil, nil)
+	pd.SetPeerCache(cache)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	_, err = pd.DialPeer(ctx, targetID)
+	if err == nil {
+		t.Fatal("DialPeer should have failed")
+	}
+	if !strings.Contains(err.Error(), "cache connect") {
+		t.Errorf("error = %v, want the cache leg's failure listed", err)
+	}
+	if _, _, ok := cache.Lookup(targetID); ok {
+		t.Error("failing cache entry was not forgotten")
+	}
+}
+
 func TestPathDialer_WithMetrics(t *testing.T) {
 	// Verify metrics are recorded on success
 	m := NewMetrics("test", "go1.26")
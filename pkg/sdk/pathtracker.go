@@ -30,6 +30,8 @@ type PathTracker struct {
 
 	mu    sync.RWMutex
 	peers map[peer.ID]*peerPathEntry
+
+	onPath ConnectionRecorder // nil-safe; set before Start
 }
 
 // peerPathEntry is the internal state for a tracked peer.
@@ -51,6 +53,14 @@ func NewPathTracker(h host.Host, m *Metrics) *PathTracker {
 	}
 }
 
+// SetConnectionRecorder sets a callback invoked whenever a peer connects or
+// its path type changes (e.g. a relayed peer upgrades to direct), whichever
+// side dialed. Latency is the peerstore's RTT estimate, 0 when unknown.
+// Must be called before Start.
+func (pt *PathTracker) SetConnectionRecorder(fn ConnectionRecorder) {
+	pt.onPath = fn
+}
+
 // Start subscribes to peer connectedness events and processes them
 // until the context is cancelled. Call this in a goroutine.
 func (pt *PathTracker) Start(ctx context.Context) {
@@ -116,6 +126,8 @@ func (pt *PathTracker) onConnect(pid peer.ID) {
 	}
 
 	pt.mu.Lock()
+	prev, known := pt.peers[pid]
+	changed := !known || prev.pathType != pathType
 	pt.peers[pid] = &peerPathEntry{
 		pathType:    pathType,
 		address:     addr,
@@ -126,6 +138,11 @@ func (pt *PathTracker) onConnect(pid peer.ID) {
 	pt.mu.Unlock()
 
 	pt.updateMetrics()
+
+	if changed && pt.onPath != nil {
+		latency := pt.host.Peerstore().LatencyEWMA(pid)
+		pt.onPath(pid.String(), string(pathType), float64(latency.Microseconds())/1000)
+	}
 }
 
 // onDisconnect removes a peer from tracking.
@@ -146,6 +163,16 @@ func (pt *PathTracker) UpdateRTT(pid peer.ID, rttMs float64) {
 	pt.mu.Unlock()
 }
 
+// ResetStats clears the per-peer RTT measurements. The paths themselves
+// (type, address, connect time) describe live connections and are kept.
+func (pt *PathTracker) ResetStats() {
+	pt.mu.Lock()
+	for _, entry := range pt.peers {
+		entry.lastRTTMs = 0
+	}
+	pt.mu.Unlock()
+}
+
 // GetPeerPath returns path info for a specific peer.
 func (pt *PathTracker) GetPeerPath(pid peer.ID) (*PeerPathInfo, bool) {
 	pt.mu.RLock()
@@ -248,3 +248,61 @@ func TestPathTracker_SnapshotExisting(t *testing.T) {
 		t.Errorf("PathType = %q, want DIRECT", info.PathType)
 	}
 }
+
+func TestPathTracker_ConnectionRecorder(t *testing.T) {
+	h1, err := libp2p.New(
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+		libp2p.NoSecurity,
+		libp2p.DisableRelay(),
+	)
+	if err != nil {
+		t.Fatalf("host1: %v", err)
+	}
+	defer h1.Close()
+
+	h2, err := libp2p.New(
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+		libp2p.NoSecurity,
+		libp2p.DisableRelay(),
+	)
+	if err != nil {
+		t.Fatalf("host2: %v", err)
+	}
+	defer h2.Close()
+
+	tracker := NewPathTracker(h1, nil)
+	recorded := make(chan string, 4)
+	tracker.SetConnectionRecorder(func(peerID, pathType string, _ float64) {
+		recorded <- peerID + " " + pathType
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracker.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// An inbound connection is recorded, not only ones this side dials.
+	connectCtx, connectCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer connectCancel()
+	if err := h2.Connect(connectCtx, peer.AddrInfo{ID: h1.ID(), Addrs: h1.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	want := h2.ID().String() + " DIRECT"
+	select {
+	case got := <-recorded:
+		if got != want {
+			t.Errorf("recorded %q, want %q", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("inbound connection was not recorded")
+	}
+
+	// Reclassifying an unchanged path records nothing.
+	tracker.onConnect(h2.ID())
+	select {
+	case got := <-recorded:
+		t.Errorf("unchanged path recorded again: %q", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
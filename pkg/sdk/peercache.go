@@ -0,0 +1,166 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+// DefaultPeerCacheTTL is how long cached peer addresses are tried before
+// a fresh DHT lookup is needed.
+const DefaultPeerCacheTTL = time.Hour
+
+// PeerCacheEntry is one cached peer: the direct addresses it was last
+// found at and when they were recorded.
+type PeerCacheEntry struct {
+	Addrs   []string  `json:"addrs"`
+	Updated time.Time `json:"updated"`
+}
+
+// PeerCache remembers peer addresses found through the DHT so the next
+// dial can try them before a slow FindPeer. Entries older than the TTL are
+// ignored. Every change is written to disk; the file is a cache, so read
+// and write errors are logged and otherwise ignored.
+type PeerCache struct {
+	mu      sync.Mutex
+	path    string // "" = memory only
+	ttl     time.Duration
+	entries map[string]*PeerCacheEntry
+	clock   clock.Clock
+}
+
+// NewPeerCache loads the cache at path, if it exists. A ttl of 0 uses
+// DefaultPeerCacheTTL.
+func NewPeerCache(path string, ttl time.Duration) *PeerCache {
+	if ttl <= 0 {
+		ttl = DefaultPeerCacheTTL
+	}
+	c := &PeerCache{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]*PeerCacheEntry),
+		clock:   clock.Real,
+	}
+	if err := c.load(); err != nil {
+		slog.Debug("peercache: ignoring unreadable cache", "path", path, "error", err)
+	}
+	return c
+}
+
+// Lookup returns the cached addresses for id and when they were recorded.
+// ok is false when there is no entry or it is older than the TTL.
+func (c *PeerCache) Lookup(id peer.ID) (addrs []ma.Multiaddr, updated time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, found := c.entries[id.String()]
+	if !found || c.clock.Now().Sub(e.Updated) > c.ttl {
+		return nil, time.Time{}, false
+	}
+	for _, s := range e.Addrs {
+		if a, err := ma.NewMultiaddr(s); err == nil {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs, e.Updated, len(addrs) > 0
+}
+
+// Store records addrs for id. Relay circuit addresses are dropped: they
+// depend on the relay, not the peer, and the relay leg dials them anyway.
+// Storing no usable address removes the entry.
+func (c *PeerCache) Store(id peer.ID, addrs []ma.Multiaddr) {
+	var direct []string
+	for _, a := range addrs {
+		if ClassifyAddr(a) != AddrCircuit {
+			direct = append(direct, a.String())
+		}
+	}
+	if len(direct) == 0 {
+		c.Forget(id)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[id.String()] = &PeerCacheEntry{Addrs: direct, Updated: c.clock.Now()}
+	c.saveLocked()
+}
+
+// Touch marks the entry for id as just confirmed, restarting its TTL. Used
+// when a dial through the cached addresses succeeds, so a peer that is
+// always reached through the cache does not fall back to the DHT once the
+// entry ages out. No-op when there is no entry.
+func (c *PeerCache) Touch(id peer.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, found := c.entries[id.String()]; found {
+		e.Updated = c.clock.Now()
+		c.saveLocked()
+	}
+}
+
+// Forget removes the entry for id, so the next dial goes to the DHT.
+func (c *PeerCache) Forget(id peer.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.entries[id.String()]; found {
+		delete(c.entries, id.String())
+		c.saveLocked()
+	}
+}
+
+func (c *PeerCache) load() error {
+	if c.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var entries map[string]*PeerCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse peer cache: %w", err)
+	}
+	for id, e := range entries {
+		if e == nil {
+			delete(entries, id)
+		}
+	}
+	if entries != nil {
+		c.entries = entries
+	}
+	return nil
+}
+
+// saveLocked writes the cache atomically via temp file + rename. The
+// caller holds c.mu, which also keeps concurrent saves off the temp file.
+func (c *PeerCache) saveLocked() {
+	if c.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		slog.Debug("peercache: marshal failed", "error", err)
+		return
+	}
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		slog.Debug("peercache: write failed", "path", c.path, "error", err)
+		return
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		os.Remove(tmpPath)
+		slog.Debug("peercache: rename failed", "path", c.path, "error", err)
+	}
+}
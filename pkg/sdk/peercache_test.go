@@ -0,0 +1,95 @@
+package sdk
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
+)
+
+func TestPeerCache_StoreLookupPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peer_cache.json")
+	id := genTestPeerID(t)
+
+	c := NewPeerCache(path, time.Hour)
+	c.Store(id, []ma.Multiaddr{
+		ma.StringCast("/ip4/203.0.113.5/tcp/4001"),
+		ma.StringCast("/ip4/198.51.100.1/tcp/7777/p2p/" + genTestPeerID(t).String() + "/p2p-circuit"),
+	})
+
+	// Reload from disk: the circuit address was dropped.
+	addrs, updated, ok := NewPeerCache(path, time.Hour).Lookup(id)
+	if !ok {
+		t.Fatal("Lookup after reload: not found")
+	}
+	if len(addrs) != 1 || addrs[0].String() != "/ip4/203.0.113.5/tcp/4001" {
+		t.Errorf("addrs = %v, want only the direct address", addrs)
+	}
+	if updated.IsZero() {
+		t.Error("updated is zero")
+	}
+}
+
+func TestPeerCache_TTL(t *testing.T) {
+	id := genTestPeerID(t)
+	now := time.Now()
+
+	c := NewPeerCache("", time.Hour)
+	c.clock = clock.NewFake(now)
+	c.Store(id, []ma.Multiaddr{ma.StringCast("/ip4/203.0.113.5/udp/4001/quic-v1")})
+
+	c.clock = clock.NewFake(now.Add(59 * time.Minute))
+	if _, _, ok := c.Lookup(id); !ok {
+		t.Error("entry within TTL not returned")
+	}
+	c.clock = clock.NewFake(now.Add(61 * time.Minute))
+	if _, _, ok := c.Lookup(id); ok {
+		t.Error("expired entry returned")
+	}
+}
+
+func TestPeerCache_TouchRestartsTTL(t *testing.T) {
+	id := genTestPeerID(t)
+	fake := clock.NewFake(time.Now())
+
+	c := NewPeerCache("", time.Hour)
+	c.clock = fake
+	c.Store(id, []ma.Multiaddr{ma.StringCast("/ip4/203.0.113.5/udp/4001/quic-v1")})
+
+	// A successful dial through the cache 50 minutes in keeps the entry
+	// for another full TTL.
+	fake.Advance(50 * time.Minute)
+	c.Touch(id)
+	fake.Advance(50 * time.Minute)
+	if _, _, ok := c.Lookup(id); !ok {
+		t.Error("entry touched within its TTL expired")
+	}
+
+	other := genTestPeerID(t)
+	c.Touch(other)
+	if _, _, ok := c.Lookup(other); ok {
+		t.Error("Touch created an entry")
+	}
+}
+
+func TestPeerCache_Forget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peer_cache.json")
+	id := genTestPeerID(t)
+
+	c := NewPeerCache(path, 0)
+	c.Store(id, []ma.Multiaddr{ma.StringCast("/ip4/203.0.113.5/tcp/4001")})
+	c.Forget(id)
+
+	if _, _, ok := NewPeerCache(path, 0).Lookup(id); ok {
+		t.Error("forgotten entry still on disk")
+	}
+
+	// Storing only circuit addresses leaves nothing worth caching.
+	c.Store(id, []ma.Multiaddr{ma.StringCast("/ip4/198.51.100.1/tcp/7777/p2p/" + genTestPeerID(t).String() + "/p2p-circuit")})
+	if _, _, ok := c.Lookup(id); ok {
+		t.Error("circuit-only entry was cached")
+	}
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/libp2p/go-libp2p/p2p/net/swarm"
 	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/shurlinet/shurli/internal/clock"
 )
 
 // ---------------------------------------------------------------------------
@@ -52,6 +55,13 @@ const (
 	// while still retrying periodically.
 	backoffMax = 15 * time.Minute
 
+	// DefaultReconnectLogBurst is how many identical consecutive reconnect
+	// failures for a peer are logged before the log is sampled: further
+	// repeats are logged once per backoffMax window (with a count of those
+	// suppressed) until the error changes or the peer reconnects. Keeps
+	// logs readable through long outages.
+	DefaultReconnectLogBurst = 3
+
 	// maxConcurrentDials limits simultaneous reconnection attempts.
 	// Prevents flooding the network when many peers disconnect at once
 	// (e.g., after a network outage). 3 is conservative; increase if
@@ -83,10 +93,10 @@ const (
 	churnBackoffThreshold = 5                // churn events before backoff kicks in
 )
 
-// ConnectionRecorder is called on each successful reconnection with
-// the peer ID, path type ("DIRECT"/"RELAYED"), and latency in ms.
+// ConnectionRecorder is called with a peer ID, path type
+// ("DIRECT"/"RELAYED"), and latency in ms when a connection is made.
 // This callback bridges the pkg/sdk -> internal/reputation boundary:
-// serve_common.go wires it to PeerHistory.RecordConnection().
+// serve_common.go wires PathTracker's to PeerHistory.RecordConnection().
 type ConnectionRecorder func(peerID, pathType string, latencyMs float64)
 
 // connLogger implements network.Notifee to log every connection close
@@ -260,7 +270,7 @@ func (cl *connLogger) Disconnected(n network.Network, c network.Conn) {
 		cl.pm.mu.Lock()
 		mp, ok := cl.pm.peers[pid]
 		if ok {
-			now := time.Now()
+			now := cl.pm.clock.Now()
 			// Reset window if it expired.
 			if now.Sub(mp.churnWindowStart) > churnWindow {
 				mp.churnCount = 0
@@ -294,6 +304,12 @@ func (cl *connLogger) Disconnected(n network.Network, c network.Conn) {
 	}
 }
 
+// DefaultDisconnectGrace is how long a watched peer may stay NotConnected
+// before PeerManager treats it as disconnected. Brief blips on flaky
+// (mobile, Wi-Fi roaming) links often heal on their own within this
+// window, and reacting immediately causes reconnect thrash.
+const DefaultDisconnectGrace = 5 * time.Second
+
 // ManagedPeer tracks the lifecycle state of a single watched peer.
 type ManagedPeer struct {
 	ID              peer.ID
@@ -312,6 +328,48 @@ type ManagedPeer struct {
 	// exponentially. Resets when a connection survives >churnThreshold.
 	churnCount    int
 	churnWindowStart time.Time
+
+	// graceTimer confirms a NotConnected event once the disconnect grace
+	// period elapses. Nil when no disconnect is pending.
+	graceTimer *time.Timer
+
+	// failureLog samples reconnect failure logging for this peer.
+	failureLog failureLogSampler
+}
+
+// failureLogSampler rate-limits logging of identical consecutive reconnect
+// failures: the first burst are logged, then one per interval.
+type failureLogSampler struct {
+	lastErr    string    // error of the current run of identical failures
+	repeats    int       // failures in the current run
+	lastLogged time.Time // when a failure was last logged
+	suppressed int       // failures not logged since lastLogged
+}
+
+// sample records a failure and reports whether to log it, and how many
+// failures were suppressed since the last one logged. burst <= 0 logs
+// every failure.
+func (s *failureLogSampler) sample(errStr string, now time.Time, burst int, interval time.Duration) (log bool, suppressed int) {
+	if errStr != s.lastErr {
+		s.lastErr = errStr
+		s.repeats = 0
+	}
+	s.repeats++
+	if burst <= 0 || s.repeats <= burst || now.Sub(s.lastLogged) >= interval {
+		suppressed = s.suppressed
+		s.suppressed = 0
+		s.lastLogged = now
+		return true, suppressed
+	}
+	s.suppressed++
+	return false, 0
+}
+
+// reset starts sampling afresh, returning how many failures went unlogged.
+func (s *failureLogSampler) reset() int {
+	suppressed := s.suppressed
+	*s = failureLogSampler{}
+	return suppressed
 }
 
 // ManagedPeerInfo is a read-only snapshot for the daemon API and status display.
@@ -344,6 +402,19 @@ type PeerManager struct {
 	pathProtector      *PathProtector           // nil-safe, set via SetPathProtector
 	onWatchlistRemoved func(peer.ID)            // callback for deauth cleanup (R7-D1)
 	connGracePeriod    time.Duration            // per-connection grace in closeOnce (R8-C1)
+	disconnectGrace    time.Duration            // debounce before a NotConnected peer counts as disconnected
+
+	// Opt-in application keepalive over direct connections (see keepalive.go).
+	keepaliveInterval time.Duration                                   // 0 = disabled
+	keepaliveSend     func(ctx context.Context, c network.Conn) error // sends one keepalive; overridable in tests
+
+	// Reconnect failure log sampling (see failureLogSampler).
+	reconnectLogBurst    int
+	reconnectLogInterval time.Duration
+
+	// clock drives backoff, probe and last-seen times; tests swap in a
+	// clock.Fake before Start.
+	clock clock.Clock
 
 	mu    sync.RWMutex
 	peers map[peer.ID]*ManagedPeer
@@ -370,15 +441,19 @@ func NewPeerManager(h host.Host, pd *PathDialer, m *Metrics, onReconnect Connect
 		lanReg = NewLANRegistry()
 	}
 	return &PeerManager{
-		host:            h,
-		pathDialer:      pd,
-		metrics:         m,
-		onReconnect:     onReconnect,
-		lanRegistry:     lanReg,
-		connGracePeriod: DefaultConnGracePeriod,
-		peers:           make(map[peer.ID]*ManagedPeer),
-		relayCleanup:    make(map[peer.ID]struct{}),
-		reconnectNow:    make(chan struct{}, 1),
+		host:                 h,
+		pathDialer:           pd,
+		metrics:              m,
+		onReconnect:          onReconnect,
+		lanRegistry:          lanReg,
+		connGracePeriod:      DefaultConnGracePeriod,
+		disconnectGrace:      DefaultDisconnectGrace,
+		reconnectLogBurst:    DefaultReconnectLogBurst,
+		reconnectLogInterval: backoffMax,
+		clock:                clock.Real,
+		peers:                make(map[peer.ID]*ManagedPeer),
+		relayCleanup:         make(map[peer.ID]struct{}),
+		reconnectNow:         make(chan struct{}, 1),
 	}
 }
 
@@ -405,6 +480,45 @@ func (pm *PeerManager) SetConnGracePeriod(d time.Duration) {
 	pm.connGracePeriod = d
 }
 
+// SetDisconnectGrace overrides how long a watched peer may be NotConnected
+// before it is marked disconnected and becomes eligible for reconnection.
+// Zero disables the grace period. Must be called before Start.
+func (pm *PeerManager) SetDisconnectGrace(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	pm.disconnectGrace = d
+}
+
+// SetReconnectLogSampling sets how many identical consecutive reconnect
+// failures per peer are logged before switching to one log line per
+// interval. burst <= 0 logs every failure; interval <= 0 keeps the default
+// of one backoffMax window. Must be called before Start.
+func (pm *PeerManager) SetReconnectLogSampling(burst int, interval time.Duration) {
+	pm.reconnectLogBurst = burst
+	if interval > 0 {
+		pm.reconnectLogInterval = interval
+	}
+}
+
+// SetKeepalive enables periodic keepalive pings to connected watched peers
+// over their direct connections. Zero (or negative) disables keepalives.
+// Must be called before Start.
+func (pm *PeerManager) SetKeepalive(interval time.Duration) {
+	if interval < 0 {
+		interval = 0
+	}
+	pm.keepaliveInterval = interval
+}
+
+// IsWatched reports whether pid is on the watchlist.
+func (pm *PeerManager) IsWatched(pid peer.ID) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	_, ok := pm.peers[pid]
+	return ok
+}
+
 // LANRegistry returns the mDNS-verified LAN registry for use by mDNS
 // discovery and the gater's LAN dial filter.
 func (pm *PeerManager) GetLANRegistry() *LANRegistry {
@@ -420,11 +534,20 @@ func (pm *PeerManager) Start(ctx context.Context) {
 	pm.connLog = &connLogger{pm: pm}
 	pm.host.Network().Notify(pm.connLog)
 
+	// Always answer keepalives, even when this node doesn't send its own:
+	// the peer that opted in needs a reply to refresh its NAT mapping.
+	pm.host.SetStreamHandler(protocol.ID(KeepaliveProtocol), handleKeepalive)
+
 	pm.wg.Add(3)
 	go pm.eventLoop()
 	go pm.reconnectLoop()
 	go pm.probeLoop()
 
+	if pm.keepaliveInterval > 0 {
+		pm.wg.Add(1)
+		go pm.keepaliveLoop()
+	}
+
 	slog.Info("peermanager: started", "watched", len(pm.peers))
 }
 
@@ -433,8 +556,18 @@ func (pm *PeerManager) Close() {
 	if pm.connLog != nil {
 		pm.host.Network().StopNotify(pm.connLog)
 	}
+	pm.host.RemoveStreamHandler(protocol.ID(KeepaliveProtocol))
 	pm.cancel()
 	pm.wg.Wait()
+
+	pm.mu.Lock()
+	for _, mp := range pm.peers {
+		if mp.graceTimer != nil {
+			mp.graceTimer.Stop()
+			mp.graceTimer = nil
+		}
+	}
+	pm.mu.Unlock()
 }
 
 // SetWatchlist updates which peers PeerManager should maintain connections to.
@@ -452,8 +585,11 @@ func (pm *PeerManager) SetWatchlist(peerIDs []peer.ID) {
 	// Remove peers no longer in the watchlist.
 	// Collect removed peers for deauth callback (R7-C1).
 	var removed []peer.ID
-	for pid := range pm.peers {
+	for pid, mp := range pm.peers {
 		if _, ok := newSet[pid]; !ok {
+			if mp.graceTimer != nil {
+				mp.graceTimer.Stop()
+			}
 			removed = append(removed, pid)
 			delete(pm.peers, pid)
 		}
@@ -483,7 +619,7 @@ func (pm *PeerManager) SetWatchlist(peerIDs []peer.ID) {
 				Connected: connected,
 			}
 			if connected {
-				mp.LastSeen = time.Now()
+				mp.LastSeen = pm.clock.Now()
 			}
 			pm.peers[pid] = mp
 		}
@@ -822,7 +958,7 @@ func (pm *PeerManager) GetManagedPeers() []ManagedPeerInfo {
 		if mp.LastDialError != "" {
 			info.LastDialError = mp.LastDialError
 		}
-		if !mp.BackoffUntil.IsZero() && mp.BackoffUntil.After(time.Now()) {
+		if !mp.BackoffUntil.IsZero() && mp.BackoffUntil.After(pm.clock.Now()) {
 			info.BackoffUntil = mp.BackoffUntil.Format(time.RFC3339)
 		}
 		result = append(result, info)
@@ -838,7 +974,7 @@ func (pm *PeerManager) snapshotExisting() {
 	for pid, mp := range pm.peers {
 		if pm.host.Network().Connectedness(pid) == network.Connected {
 			mp.Connected = true
-			mp.LastSeen = time.Now()
+			mp.LastSeen = pm.clock.Now()
 		}
 	}
 }
@@ -869,19 +1005,24 @@ func (pm *PeerManager) eventLoop() {
 			if watched {
 				switch e.Connectedness {
 				case network.Connected:
+					if mp.graceTimer != nil {
+						// Reconnected within the grace window: the blip never
+						// surfaces as a disconnect.
+						mp.graceTimer.Stop()
+						mp.graceTimer = nil
+						slog.Debug("peermanager: peer recovered within disconnect grace", "peer", e.Peer)
+					}
 					mp.Connected = true
-					mp.LastSeen = time.Now()
+					mp.LastSeen = pm.clock.Now()
 					mp.ConsecFailures = 0
 					mp.BackoffUntil = time.Time{}
 					mp.LastDialError = ""
 				case network.NotConnected:
-					if !mp.ProbeUntil.IsZero() && time.Now().Before(mp.ProbeUntil) {
-						slog.Info("peermanager: probe-upgraded peer disconnected, clearing cooldown",
-							"peer", e.Peer,
-							"probeUntil", mp.ProbeUntil.Format("15:04:05"))
+					if pm.disconnectGrace <= 0 {
+						pm.markDisconnectedLocked(mp)
+					} else if mp.graceTimer == nil {
+						pm.startDisconnectGraceLocked(mp)
 					}
-					mp.Connected = false
-					mp.ProbeUntil = time.Time{} // peer gone - cooldown invalid, reconnect immediately
 				}
 			}
 			pm.mu.Unlock()
@@ -889,6 +1030,40 @@ func (pm *PeerManager) eventLoop() {
 	}
 }
 
+// startDisconnectGraceLocked defers marking mp disconnected until the grace
+// period elapses. The peer keeps its Connected state meanwhile, so the
+// reconnect loop does not dial it. Caller must hold pm.mu.
+func (pm *PeerManager) startDisconnectGraceLocked(mp *ManagedPeer) {
+	var t *time.Timer
+	t = time.AfterFunc(pm.disconnectGrace, func() {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		// Stale timer: cancelled by a reconnect, or the peer was removed
+		// from (and possibly re-added to) the watchlist.
+		if mp.graceTimer != t || pm.peers[mp.ID] != mp {
+			return
+		}
+		mp.graceTimer = nil
+		if pm.host.Network().Connectedness(mp.ID) == network.Connected {
+			return
+		}
+		pm.markDisconnectedLocked(mp)
+	})
+	mp.graceTimer = t
+}
+
+// markDisconnectedLocked records that mp is no longer connected, making it
+// eligible for the next reconnect cycle. Caller must hold pm.mu.
+func (pm *PeerManager) markDisconnectedLocked(mp *ManagedPeer) {
+	if !mp.ProbeUntil.IsZero() && pm.clock.Now().Before(mp.ProbeUntil) {
+		slog.Info("peermanager: probe-upgraded peer disconnected, clearing cooldown",
+			"peer", mp.ID,
+			"probeUntil", mp.ProbeUntil.Format("15:04:05"))
+	}
+	mp.Connected = false
+	mp.ProbeUntil = time.Time{} // peer gone - cooldown invalid, reconnect immediately
+}
+
 // reconnectLoop periodically dials disconnected watched peers with
 // exponential backoff. See package-level constants for tuning parameters.
 // Also responds to immediate triggers via reconnectNow channel (used
@@ -896,7 +1071,7 @@ func (pm *PeerManager) eventLoop() {
 func (pm *PeerManager) reconnectLoop() {
 	defer pm.wg.Done()
 
-	ticker := time.NewTicker(reconnectInterval)
+	ticker := pm.clock.NewTicker(reconnectInterval)
 	defer ticker.Stop()
 
 	sem := make(chan struct{}, maxConcurrentDials)
@@ -905,7 +1080,7 @@ func (pm *PeerManager) reconnectLoop() {
 		select {
 		case <-pm.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			pm.runReconnectCycle(sem)
 		case <-pm.reconnectNow:
 			pm.runReconnectCycle(sem)
@@ -922,14 +1097,14 @@ func (pm *PeerManager) reconnectLoop() {
 func (pm *PeerManager) probeLoop() {
 	defer pm.wg.Done()
 
-	ticker := time.NewTicker(probeInterval)
+	ticker := pm.clock.NewTicker(probeInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-pm.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
@@ -947,7 +1122,7 @@ func (pm *PeerManager) probeLoop() {
 // disconnected and past their backoff window.
 func (pm *PeerManager) runReconnectCycle(sem chan struct{}) {
 	pm.mu.RLock()
-	now := time.Now()
+	now := pm.clock.Now()
 	var targets []peer.ID
 	for pid, mp := range pm.peers {
 		if pid == pm.host.ID() {
@@ -999,7 +1174,7 @@ func (pm *PeerManager) attemptReconnect(target peer.ID) {
 		pm.mu.Unlock()
 		return
 	}
-	mp.LastDialAttempt = time.Now()
+	mp.LastDialAttempt = pm.clock.Now()
 	pm.mu.Unlock()
 
 	dialCtx, dialCancel := context.WithTimeout(pm.ctx, reconnectDialTimeout)
@@ -1035,14 +1210,25 @@ func (pm *PeerManager) attemptReconnect(target peer.ID) {
 		if backoff > backoffMax {
 			backoff = backoffMax
 		}
-		mp.BackoffUntil = time.Now().Add(backoff)
+		mp.BackoffUntil = pm.clock.Now().Add(backoff)
+
+		failures := mp.ConsecFailures
+		logIt, suppressed := mp.failureLog.sample(mp.LastDialError, pm.clock.Now(), pm.reconnectLogBurst, pm.reconnectLogInterval)
 
 		pm.incMetric("failure")
 		pm.mu.Unlock()
-		slog.Debug("peermanager: reconnect failed",
-			"peer", short,
-			"failures", mp.ConsecFailures,
-			"backoff", backoff.Round(time.Second))
+		if logIt {
+			attrs := []any{
+				"peer", short,
+				"failures", failures,
+				"backoff", backoff.Round(time.Second),
+				"error", err,
+			}
+			if suppressed > 0 {
+				attrs = append(attrs, "suppressed", suppressed)
+			}
+			slog.Debug("peermanager: reconnect failed", attrs...)
+		}
 		return
 	}
 
@@ -1063,15 +1249,20 @@ func (pm *PeerManager) attemptReconnect(target peer.ID) {
 	}
 
 	mp.Connected = true
-	mp.LastSeen = time.Now()
+	mp.LastSeen = pm.clock.Now()
 	mp.ConsecFailures = 0
 	mp.BackoffUntil = time.Time{}
 	mp.LastDialError = ""
+	suppressed := mp.failureLog.reset()
 
 	pm.incMetric("success")
 	pm.mu.Unlock()
 
-	slog.Info("peermanager: reconnected", "peer", short, "path", result.PathType)
+	if suppressed > 0 {
+		slog.Info("peermanager: reconnected", "peer", short, "path", result.PathType, "suppressed_failures", suppressed)
+	} else {
+		slog.Info("peermanager: reconnected", "peer", short, "path", result.PathType)
+	}
 
 	// Invoke callback OUTSIDE the lock to prevent potential deadlock
 	// if the callback (e.g., PeerHistory.RecordConnection) ever calls
@@ -1463,10 +1654,10 @@ func (pm *PeerManager) probeAndUpgrade(pid peer.ID) {
 			pm.mu.Lock()
 			if mp := pm.peers[pid]; mp != nil {
 				mp.Connected = true
-				mp.LastSeen = time.Now()
+				mp.LastSeen = pm.clock.Now()
 				mp.ConsecFailures = 0
 				mp.BackoffUntil = time.Time{}
-				mp.ProbeUntil = time.Now().Add(90 * time.Second)
+				mp.ProbeUntil = pm.clock.Now().Add(90 * time.Second)
 			}
 			pm.mu.Unlock()
 
@@ -1632,15 +1823,7 @@ func extractIPv6TCPAddr(addr ma.Multiaddr) (ip6, port string) {
 // component, indicating it is a relay circuit address rather than a
 // direct address.
 func isCircuitAddr(addr ma.Multiaddr) bool {
-	found := false
-	ma.ForEach(addr, func(c ma.Component) bool {
-		if c.Protocol().Code == ma.P_CIRCUIT {
-			found = true
-			return false // stop iteration
-		}
-		return true
-	})
-	return found
+	return ClassifyAddr(addr) == AddrCircuit
 }
 
 // ---------------------------------------------------------------------------
@@ -2,15 +2,20 @@ package sdk
 
 import (
 	"context"
+	"log/slog"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 
+	"github.com/shurlinet/shurli/internal/clock"
 	"github.com/shurlinet/shurli/internal/config"
 )
 
@@ -77,6 +82,7 @@ func TestPeerManager_ConnectDisconnect(t *testing.T) {
 
 	pm := NewPeerManager(netA.Host(), nil, nil, nil, nil)
 	pm.SetWatchlist([]peer.ID{netB.Host().ID()})
+	pm.SetDisconnectGrace(100 * time.Millisecond)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -108,6 +114,63 @@ func TestPeerManager_ConnectDisconnect(t *testing.T) {
 	}
 }
 
+func TestPeerManager_DisconnectGrace_ReconnectWithinWindow(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	pidB := netB.Host().ID()
+
+	pd := NewPathDialer(netA.Host(), nil, nil, nil)
+	pm := NewPeerManager(netA.Host(), pd, nil, nil, nil)
+	pm.SetWatchlist([]peer.ID{pidB})
+	pm.SetDisconnectGrace(2 * time.Second)
+
+	connectNetworks(t, netA, netB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pm.Start(ctx)
+	defer pm.Close()
+
+	// Blip: drop the connection, then restore it well inside the grace window.
+	if err := netA.Host().Network().ClosePeer(pidB); err != nil {
+		t.Fatalf("ClosePeer: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // NotConnected event propagation
+
+	pm.mu.RLock()
+	mp := pm.peers[pidB]
+	connected, pending := mp.Connected, mp.graceTimer != nil
+	pm.mu.RUnlock()
+	if !connected {
+		t.Fatal("peer marked disconnected before grace period elapsed")
+	}
+	if !pending {
+		t.Fatal("expected a pending disconnect grace timer")
+	}
+
+	connectNetworks(t, netA, netB)
+	time.Sleep(100 * time.Millisecond) // Connected event propagation
+
+	// Run a reconnect cycle: a peer inside (or recovered from) its grace
+	// window must not be dialed.
+	pm.runReconnectCycle(make(chan struct{}, maxConcurrentDials))
+	time.Sleep(2500 * time.Millisecond) // past the original grace deadline
+
+	pm.mu.RLock()
+	connected, pending = mp.Connected, mp.graceTimer != nil
+	lastDial := mp.LastDialAttempt
+	pm.mu.RUnlock()
+	if !connected {
+		t.Error("peer should remain connected after recovering within the grace window")
+	}
+	if pending {
+		t.Error("grace timer should be cancelled by the reconnect")
+	}
+	if !lastDial.IsZero() {
+		t.Error("no reconnect dial should be attempted for a brief blip")
+	}
+}
+
 func TestPeerManager_Backoff(t *testing.T) {
 	netA := newListeningNetwork(t)
 
@@ -237,6 +300,72 @@ func TestPeerManager_WithMetrics(t *testing.T) {
 	}
 }
 
+// TestPeerManager_BackoffFakeClock walks through the reconnect backoff
+// window on a fake clock: a peer in backoff is skipped until the clock
+// passes BackoffUntil, then dialed again with a doubled backoff.
+func TestPeerManager_BackoffFakeClock(t *testing.T) {
+	netA := newListeningNetwork(t)
+
+	dir := t.TempDir()
+	unreachable, err := New(&Config{
+		KeyFile: filepath.Join(dir, "test.key"),
+		Config: &config.Config{
+			Network: config.NetworkConfig{
+				ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create unreachable network: %v", err)
+	}
+	unreachablePID := unreachable.Host().ID()
+	unreachable.Close()
+
+	fake := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	metrics := NewMetrics("test", "go1.26")
+	pd := NewPathDialer(netA.Host(), nil, nil, nil)
+	pm := NewPeerManager(netA.Host(), pd, metrics, nil, nil)
+	pm.clock = fake
+	pm.SetWatchlist([]peer.ID{unreachablePID})
+
+	pm.ctx, pm.cancel = context.WithCancel(context.Background())
+	defer pm.cancel()
+
+	backoffUntil := func() (time.Time, int) {
+		pm.mu.RLock()
+		defer pm.mu.RUnlock()
+		mp := pm.peers[unreachablePID]
+		return mp.BackoffUntil, mp.ConsecFailures
+	}
+
+	pm.attemptReconnect(unreachablePID)
+	until, failures := backoffUntil()
+	if want := fake.Now().Add(2 * backoffBase); failures != 1 || !until.Equal(want) {
+		t.Fatalf("after first failure: backoff until %v (failures %d), want %v (1)", until, failures, want)
+	}
+
+	// Still inside the window: the cycle skips the peer.
+	sem := make(chan struct{}, maxConcurrentDials)
+	fake.Advance(2*backoffBase - time.Second)
+	pm.runReconnectCycle(sem)
+	pm.wg.Wait()
+	if got := testCounterValue(t, metrics.PeerManagerReconnectTotal, "backoff_skip"); got != 1 {
+		t.Errorf("backoff_skip = %v, want 1", got)
+	}
+	if _, failures := backoffUntil(); failures != 1 {
+		t.Fatalf("peer dialed during backoff: failures = %d", failures)
+	}
+
+	// Past the window: the cycle dials again and the backoff doubles.
+	fake.Advance(2 * time.Second)
+	pm.runReconnectCycle(sem)
+	pm.wg.Wait()
+	until, failures = backoffUntil()
+	if want := fake.Now().Add(4 * backoffBase); failures != 2 || !until.Equal(want) {
+		t.Errorf("after second failure: backoff until %v (failures %d), want %v (2)", until, failures, want)
+	}
+}
+
 // testCounterValue reads the current value of a CounterVec for the given label.
 func testCounterValue(t *testing.T, cv *prometheus.CounterVec, label string) float64 {
 	t.Helper()
@@ -490,6 +619,93 @@ func TestAllConnsRelayed(t *testing.T) {
 	}
 }
 
+func TestKeepaliveConns_DirectOnly(t *testing.T) {
+	direct := &stubConn{remoteMA: mustMA(t, "/ip4/203.0.113.5/udp/4001/quic-v1")}
+	limited := &stubConn{remoteMA: mustMA(t, "/ip4/203.0.113.9/tcp/4001"), limited: true}
+	circuit := &stubConn{remoteMA: mustMA(t,
+		"/ip4/203.0.113.9/tcp/4001/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN/p2p-circuit")}
+
+	got := keepaliveConns([]network.Conn{limited, direct, circuit})
+	if len(got) != 1 || got[0] != direct {
+		t.Fatalf("keepaliveConns = %v, want only the direct conn", got)
+	}
+	if got := keepaliveConns([]network.Conn{limited, circuit}); len(got) != 0 {
+		t.Errorf("relay-only peer got %d keepalive conns, want 0", len(got))
+	}
+}
+
+func TestPeerManager_Keepalive(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+
+	// B answers keepalives via its own PeerManager handler.
+	pmB := NewPeerManager(netB.Host(), nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pmB.Start(ctx)
+	defer pmB.Close()
+
+	connectNetworks(t, netA, netB)
+
+	pm := NewPeerManager(netA.Host(), nil, nil, nil, nil)
+	pm.SetWatchlist([]peer.ID{netB.Host().ID()})
+	pm.SetKeepalive(50 * time.Millisecond)
+
+	var mu sync.Mutex
+	var sent, failed int
+	pm.keepaliveSend = func(ctx context.Context, c network.Conn) error {
+		err := sendKeepalive(ctx, c)
+		mu.Lock()
+		defer mu.Unlock()
+		if c.Stat().Limited {
+			t.Errorf("keepalive sent on limited conn %s", c.RemoteMultiaddr())
+		}
+		if err != nil {
+			failed++
+		} else {
+			sent++
+		}
+		return err
+	}
+	pm.Start(ctx)
+	defer pm.Close()
+
+	time.Sleep(300 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sent == 0 {
+		t.Errorf("expected keepalives over the direct connection, got none (failed=%d)", failed)
+	}
+	if failed != 0 {
+		t.Errorf("%d keepalives failed", failed)
+	}
+}
+
+func TestPeerManager_KeepaliveDisabledByDefault(t *testing.T) {
+	netA := newListeningNetwork(t)
+	netB := newListeningNetwork(t)
+	connectNetworks(t, netA, netB)
+
+	pm := NewPeerManager(netA.Host(), nil, nil, nil, nil)
+	pm.SetWatchlist([]peer.ID{netB.Host().ID()})
+	var calls atomic.Int32
+	pm.keepaliveSend = func(context.Context, network.Conn) error {
+		calls.Add(1)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pm.Start(ctx)
+	defer pm.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("keepalive sent %d times with keepalive_interval unset", n)
+	}
+}
+
 func TestPeerHasIPv6(t *testing.T) {
 	v6, _ := ma.NewMultiaddr("/ip6/2001:db8::1/tcp/4001")
 	v4, _ := ma.NewMultiaddr("/ip4/203.0.113.1/tcp/4001")
@@ -504,3 +720,120 @@ func TestPeerHasIPv6(t *testing.T) {
 		t.Error("expected false for nil addrs")
 	}
 }
+
+// logCapture is a slog handler that records the messages and attributes of
+// each log record.
+type logCapture struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (c *logCapture) Enabled(context.Context, slog.Level) bool { return true }
+func (c *logCapture) Handle(_ context.Context, r slog.Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, r.Clone())
+	return nil
+}
+func (c *logCapture) WithAttrs([]slog.Attr) slog.Handler { return c }
+func (c *logCapture) WithGroup(string) slog.Handler      { return c }
+
+// matching returns the records with message msg.
+func (c *logCapture) matching(msg string) []slog.Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []slog.Record
+	for _, r := range c.records {
+		if r.Message == msg {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func recordAttr(r slog.Record, key string) (slog.Value, bool) {
+	var v slog.Value
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			v, found = a.Value, true
+			return false
+		}
+		return true
+	})
+	return v, found
+}
+
+// TestPeerManager_ReconnectFailureLogSampling verifies repeated identical
+// reconnect failures are logged for the first burst only, then once per
+// interval with a count of the failures suppressed in between.
+func TestPeerManager_ReconnectFailureLogSampling(t *testing.T) {
+	netA := newListeningNetwork(t)
+	dir := t.TempDir()
+	unreachable, err := New(&Config{
+		KeyFile: filepath.Join(dir, "test.key"),
+		Config: &config.Config{
+			Network: config.NetworkConfig{ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create unreachable network: %v", err)
+	}
+	unreachablePID := unreachable.Host().ID()
+	unreachable.Close()
+
+	capture := &logCapture{}
+	orig := slog.Default()
+	slog.SetDefault(slog.New(capture))
+	defer slog.SetDefault(orig)
+
+	pm := NewPeerManager(netA.Host(), NewPathDialer(netA.Host(), nil, nil, nil), nil, nil, nil)
+	pm.SetWatchlist([]peer.ID{unreachablePID})
+	pm.SetReconnectLogSampling(2, 300*time.Millisecond)
+	pm.ctx, pm.cancel = context.WithCancel(context.Background())
+	defer pm.cancel()
+
+	for range 6 {
+		pm.attemptReconnect(unreachablePID)
+	}
+	if got := len(capture.matching("peermanager: reconnect failed")); got != 2 {
+		t.Fatalf("logged %d of 6 identical failures, want the first 2", got)
+	}
+
+	time.Sleep(350 * time.Millisecond)
+	pm.attemptReconnect(unreachablePID)
+	logged := capture.matching("peermanager: reconnect failed")
+	if len(logged) != 3 {
+		t.Fatalf("logged %d failures after the interval, want 3", len(logged))
+	}
+	if v, ok := recordAttr(logged[2], "suppressed"); !ok || v.Int64() != 4 {
+		t.Errorf("suppressed = %v (present %v), want 4", v, ok)
+	}
+	if _, ok := recordAttr(logged[0], "error"); !ok {
+		t.Error("failure log should include the error")
+	}
+}
+
+func TestFailureLogSampler_ErrorChange(t *testing.T) {
+	var s failureLogSampler
+	now := time.Now()
+	for i := range 3 {
+		if log, _ := s.sample("timeout", now, 1, time.Hour); log != (i == 0) {
+			t.Fatalf("failure %d: log = %v", i, log)
+		}
+	}
+	// A different error is logged immediately and reports the suppressed count.
+	log, suppressed := s.sample("connection refused", now, 1, time.Hour)
+	if !log || suppressed != 2 {
+		t.Errorf("changed error: log=%v suppressed=%d, want true 2", log, suppressed)
+	}
+	if log, _ := s.sample("connection refused", now, 1, time.Hour); log {
+		t.Error("repeat of the new error should be suppressed")
+	}
+	if got := s.reset(); got != 1 {
+		t.Errorf("reset returned %d suppressed, want 1", got)
+	}
+	if log, _ := s.sample("connection refused", now, 1, time.Hour); !log {
+		t.Error("first failure after reset should be logged")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
 	relayv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/relay"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 // PeerRelayConfig controls peer relay behavior.
@@ -89,6 +91,9 @@ type PeerRelay struct {
 	enabled atomic.Bool
 	relay   *relayv2.Relay
 
+	// draining refuses new reservations while existing circuits finish.
+	draining atomic.Bool
+
 	onStateChange func(enabled bool) // optional callback
 }
 
@@ -138,6 +143,7 @@ func (pr *PeerRelay) Enable() error {
 	r, err := relayv2.New(pr.host,
 		relayv2.WithResources(resources),
 		relayv2.WithLimit(limit),
+		relayv2.WithACL(drainACL{pr}),
 	)
 	if err != nil {
 		return err
@@ -157,6 +163,21 @@ func (pr *PeerRelay) Enable() error {
 	return nil
 }
 
+// SetDraining makes the relay refuse new (and renewed) reservations while
+// leaving established circuits alone. Used by a draining daemon shutdown.
+func (pr *PeerRelay) SetDraining(draining bool) {
+	pr.draining.Store(draining)
+}
+
+// drainACL is the relay's ACL filter. It only ever refuses reservations
+// while the relay is draining; access control proper is the host's
+// connection gater (see PeerRelay).
+type drainACL struct{ pr *PeerRelay }
+
+func (a drainACL) AllowReserve(peer.ID, ma.Multiaddr) bool { return !a.pr.draining.Load() }
+
+func (a drainACL) AllowConnect(peer.ID, ma.Multiaddr, peer.ID) bool { return true }
+
 // Disable stops the circuit relay service.
 func (pr *PeerRelay) Disable() {
 	if !pr.enabled.Load() {
@@ -245,3 +245,19 @@ func TestPeerRelay_OnStateChange(t *testing.T) {
 		t.Error("second callback should be false (disabled)")
 	}
 }
+
+func TestPeerRelay_DrainACL(t *testing.T) {
+	pr := NewPeerRelay(nil, nil, PeerRelayConfig{})
+	acl := drainACL{pr}
+
+	if !acl.AllowReserve("", nil) {
+		t.Error("reservations should be allowed before draining")
+	}
+	pr.SetDraining(true)
+	if acl.AllowReserve("", nil) {
+		t.Error("reservations should be refused while draining")
+	}
+	if !acl.AllowConnect("", nil, "") {
+		t.Error("circuits should still be allowed while draining")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,6 +34,11 @@ type PingStats struct {
 	MinMs    float64 `json:"min_ms"`
 	AvgMs    float64 `json:"avg_ms"`
 	MaxMs    float64 `json:"max_ms"`
+	StddevMs float64 `json:"stddev_ms"` // population standard deviation of RTTs
+	JitterMs float64 `json:"jitter_ms"` // mean absolute difference between consecutive RTTs
+	P50Ms    float64 `json:"p50_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	P99Ms    float64 `json:"p99_ms"`
 }
 
 // PingPeer sends count pings to peerID using the given ping-pong protocol.
@@ -97,8 +105,7 @@ func doPing(ctx context.Context, h host.Host, peerID peer.ID, protocolID string,
 	defer s.Close()
 
 	// Determine connection path
-	addr := s.Conn().RemoteMultiaddr().String()
-	if strings.Contains(addr, "/p2p-circuit") {
+	if ClassifyAddr(s.Conn().RemoteMultiaddr()) == AddrCircuit {
 		result.Path = "RELAYED"
 	} else {
 		result.Path = "DIRECT"
@@ -143,6 +150,7 @@ func ComputePingStats(results []PingResult) PingStats {
 
 	var sum float64
 	first := true
+	rtts := make([]float64, 0, len(results))
 	for _, r := range results {
 		if r.Error != "" {
 			stats.Lost++
@@ -150,6 +158,7 @@ func ComputePingStats(results []PingResult) PingStats {
 		}
 		stats.Received++
 		sum += r.RttMs
+		rtts = append(rtts, r.RttMs)
 		if first {
 			stats.MinMs = r.RttMs
 			stats.MaxMs = r.RttMs
@@ -165,6 +174,12 @@ func ComputePingStats(results []PingResult) PingStats {
 
 	if stats.Received > 0 {
 		stats.AvgMs = sum / float64(stats.Received)
+		stats.StddevMs = stddev(rtts, stats.AvgMs)
+		stats.JitterMs = jitter(rtts)
+		slices.Sort(rtts)
+		stats.P50Ms = percentile(rtts, 50)
+		stats.P95Ms = percentile(rtts, 95)
+		stats.P99Ms = percentile(rtts, 99)
 	}
 	if stats.Sent > 0 {
 		stats.LossPct = float64(stats.Lost) / float64(stats.Sent) * 100
@@ -172,3 +187,65 @@ func ComputePingStats(results []PingResult) PingStats {
 
 	return stats
 }
+
+// WritePingReport writes the plain-text report of a finished ping session
+// to target: a header with the short peer ID, one line per result and the
+// statistics. The daemon's text API and `shurli ping` via the daemon both
+// use it. With bell, a terminal bell follows each reply.
+func WritePingReport(w io.Writer, target, peerID string, results []PingResult, stats PingStats, bell bool) {
+	if len(peerID) > 16 {
+		peerID = peerID[:16] + "..."
+	}
+	fmt.Fprintf(w, "PING %s (%s):\n", target, peerID)
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "seq=%d error=%s\n", r.Seq, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "seq=%d rtt=%.1fms path=[%s]\n", r.Seq, r.RttMs, r.Path)
+		if bell {
+			fmt.Fprint(w, "\a")
+		}
+	}
+	fmt.Fprintf(w, "--- %s ping statistics ---\n", target)
+	fmt.Fprintf(w, "%d sent, %d received, %.0f%% loss, rtt min/avg/max/stddev = %.1f/%.1f/%.1f/%.1f ms, jitter = %.1f ms, p50/p95/p99 = %.1f/%.1f/%.1f ms\n",
+		stats.Sent, stats.Received, stats.LossPct, stats.MinMs, stats.AvgMs, stats.MaxMs,
+		stats.StddevMs, stats.JitterMs, stats.P50Ms, stats.P95Ms, stats.P99Ms)
+}
+
+// stddev returns the population standard deviation of samples around mean.
+// A single sample has a deviation of 0.
+func stddev(samples []float64, mean float64) float64 {
+	var sq float64
+	for _, v := range samples {
+		d := v - mean
+		sq += d * d
+	}
+	return math.Sqrt(sq / float64(len(samples)))
+}
+
+// jitter returns the mean absolute difference between consecutive samples,
+// in the order they were received (RFC 3550 style, without smoothing).
+// Fewer than two samples have no jitter.
+func jitter(samples []float64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	var sum float64
+	for i := 1; i < len(samples); i++ {
+		sum += math.Abs(samples[i] - samples[i-1])
+	}
+	return sum / float64(len(samples)-1)
+}
+
+// percentile returns the p-th percentile of sorted (non-empty) using the
+// nearest-rank method, so the result is always an observed sample. With few
+// samples the high percentiles collapse onto the maximum (p99 of 2 samples
+// is the larger one) rather than being interpolated.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 )
@@ -101,9 +102,81 @@ func TestComputePingStats(t *testing.T) {
 		if stats.MinMs != 42.0 || stats.MaxMs != 42.0 || stats.AvgMs != 42.0 {
 			t.Errorf("single: min=%f max=%f avg=%f", stats.MinMs, stats.MaxMs, stats.AvgMs)
 		}
+		if stats.P50Ms != 42.0 || stats.P95Ms != 42.0 || stats.P99Ms != 42.0 {
+			t.Errorf("single: p50=%f p95=%f p99=%f", stats.P50Ms, stats.P95Ms, stats.P99Ms)
+		}
+	})
+
+	t.Run("percentiles", func(t *testing.T) {
+		// RTTs 1..100 ms in reverse order, plus losses that must not count.
+		var results []PingResult
+		for i := 100; i >= 1; i-- {
+			results = append(results, PingResult{Seq: 101 - i, RttMs: float64(i)})
+		}
+		results = append(results, PingResult{Seq: 101, Error: "timeout"})
+		stats := ComputePingStats(results)
+		if stats.P50Ms != 50 || stats.P95Ms != 95 || stats.P99Ms != 99 {
+			t.Errorf("p50/p95/p99 = %v/%v/%v, want 50/95/99", stats.P50Ms, stats.P95Ms, stats.P99Ms)
+		}
+	})
+
+	t.Run("percentiles two samples", func(t *testing.T) {
+		results := []PingResult{
+			{Seq: 1, RttMs: 30.0},
+			{Seq: 2, RttMs: 10.0},
+		}
+		stats := ComputePingStats(results)
+		if stats.P50Ms != 10.0 {
+			t.Errorf("P50Ms = %f, want 10.0", stats.P50Ms)
+		}
+		if stats.P95Ms != 30.0 || stats.P99Ms != 30.0 {
+			t.Errorf("p95/p99 = %f/%f, want max 30.0", stats.P95Ms, stats.P99Ms)
+		}
+	})
+
+	t.Run("percentiles all errors", func(t *testing.T) {
+		stats := ComputePingStats([]PingResult{{Seq: 1, Error: "timeout"}})
+		if stats.P50Ms != 0 || stats.P95Ms != 0 || stats.P99Ms != 0 {
+			t.Errorf("all errors should leave percentiles zero: %+v", stats)
+		}
 	})
 }
 
+func TestComputePingStats_StddevJitter(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    []PingResult
+		wantStddev float64
+		wantJitter float64
+	}{
+		{"empty", nil, 0, 0},
+		{"all errors", []PingResult{{Seq: 1, Error: "timeout"}, {Seq: 2, Error: "timeout"}}, 0, 0},
+		{"single success", []PingResult{{Seq: 1, RttMs: 42.0}}, 0, 0},
+		{"constant", []PingResult{{Seq: 1, RttMs: 20}, {Seq: 2, RttMs: 20}, {Seq: 3, RttMs: 20}}, 0, 0},
+		// Mean 5, squared deviations sum to 32 over 8 samples: stddev 2.
+		// Consecutive differences 2,0,0,1,0,2,2 average to 1.
+		{"spread", []PingResult{
+			{Seq: 1, RttMs: 2}, {Seq: 2, RttMs: 4}, {Seq: 3, RttMs: 4}, {Seq: 4, RttMs: 4},
+			{Seq: 5, RttMs: 5}, {Seq: 6, RttMs: 5}, {Seq: 7, RttMs: 7}, {Seq: 8, RttMs: 9},
+		}, 2, 1},
+		// Jitter follows arrival order and skips lost replies: 10,30,10 -> 20.
+		{"order and losses", []PingResult{
+			{Seq: 1, RttMs: 10}, {Seq: 2, Error: "timeout"}, {Seq: 3, RttMs: 30}, {Seq: 4, RttMs: 10},
+		}, math.Sqrt(800.0 / 9), 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := ComputePingStats(tt.results)
+			if math.Abs(stats.StddevMs-tt.wantStddev) > 1e-9 {
+				t.Errorf("StddevMs = %f, want %f", stats.StddevMs, tt.wantStddev)
+			}
+			if math.Abs(stats.JitterMs-tt.wantJitter) > 1e-9 {
+				t.Errorf("JitterMs = %f, want %f", stats.JitterMs, tt.wantJitter)
+			}
+		})
+	}
+}
+
 func TestPingPeer_ContextCancelled(t *testing.T) {
 	netA := newListeningNetwork(t)
 	netB := newListeningNetwork(t)
@@ -1,10 +1,12 @@
 package sdk
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -42,7 +44,7 @@ func BidirectionalProxy(a, b HalfCloseConn, logPrefix string) {
 		defer close(aDone)
 		_, err := io.Copy(b, a)
 		if err != nil && err != io.EOF {
-			slog.Warn("copy error", "prefix", logPrefix, "direction", "a→b", "error", err)
+			logCopyError(logPrefix, "a→b", classifyCopyError(err, a, b))
 		}
 		b.CloseWrite()
 	}()
@@ -52,7 +54,7 @@ func BidirectionalProxy(a, b HalfCloseConn, logPrefix string) {
 		defer close(bDone)
 		_, err := io.Copy(a, b)
 		if err != nil && err != io.EOF {
-			slog.Warn("copy error", "prefix", logPrefix, "direction", "b→a", "error", err)
+			logCopyError(logPrefix, "b→a", classifyCopyError(err, a, b))
 		}
 		a.CloseWrite()
 	}()
@@ -63,6 +65,90 @@ func BidirectionalProxy(a, b HalfCloseConn, logPrefix string) {
 	b.Close()
 }
 
+// logCopyError logs a proxy copy failure. A relay cutting the circuit gets
+// an actionable message instead of a bare stream reset.
+func logCopyError(logPrefix, direction string, err error) {
+	if errors.Is(err, ErrServiceLimitExceeded) {
+		slog.Warn(ErrServiceLimitExceeded.Error(), "prefix", logPrefix, "direction", direction,
+			"error", err,
+			"hint", "close other connections to this service or wait a minute; the service owner sets max_concurrent and rate_per_minute")
+		return
+	}
+	if errors.Is(err, ErrRelaySessionLimit) {
+		slog.Warn(ErrRelaySessionLimit.Error(), "prefix", logPrefix, "direction", direction,
+			"error", err,
+			"hint", "reconnect to open a fresh relay circuit; for large transfers ask the relay admin for a bigger data budget or get a direct path")
+		return
+	}
+	slog.Warn("copy error", "prefix", logPrefix, "direction", direction, "error", err)
+}
+
+// classifyCopyError wraps err in ErrServiceLimitExceeded when the remote
+// service reset the stream with a limit error code, or in
+// ErrRelaySessionLimit when either side runs over a relay circuit and the
+// error is the abrupt stream reset a relay produces when it ends a circuit
+// at a session limit. The relay does not say why it cut the circuit, so the
+// error names the limit as the likely cause only. Other errors, including
+// TCP resets from the local side, are returned unchanged.
+func classifyCopyError(err error, a, b HalfCloseConn) error {
+	if isServiceLimitReset(err) {
+		return fmt.Errorf("%w: %w", ErrServiceLimitExceeded, err)
+	}
+	if err == nil || !(isRelayedConn(a) || isRelayedConn(b)) || !isCircuitCutError(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrRelaySessionLimit, err)
+}
+
+// isServiceLimitReset reports whether err is a remote stream reset carrying
+// one of the codes a service sends when a peer exceeds its limits.
+func isServiceLimitReset(err error) bool {
+	var se *network.StreamError
+	if !errors.As(err, &se) || !se.Remote {
+		return false
+	}
+	return se.ErrorCode == network.StreamResourceLimitExceeded || se.ErrorCode == network.StreamRateLimited
+}
+
+// isCircuitCutError reports whether err looks like a relay tearing down the
+// circuit under an active stream: a libp2p stream or muxer reset. A plain
+// "connection reset" comes from a TCP socket, i.e. the local service.
+func isCircuitCutError(err error) bool {
+	if errors.Is(err, network.ErrReset) {
+		return true
+	}
+	lower := strings.ToLower(err.Error())
+	for _, s := range []string{"stream reset", "session shutdown", "limit exceeded"} {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// relayedConn is implemented by proxy endpoints that know whether they run
+// over a relay circuit.
+type relayedConn interface {
+	Relayed() bool
+}
+
+// isRelayedConn reports whether c (possibly wrapped for metrics or
+// bandwidth shaping) is a stream over a relay circuit.
+func isRelayedConn(c HalfCloseConn) bool {
+	for {
+		switch w := c.(type) {
+		case relayedConn:
+			return w.Relayed()
+		case *countingConn:
+			c = w.HalfCloseConn
+		case *throttledConn:
+			c = w.HalfCloseConn
+		default:
+			return false
+		}
+	}
+}
+
 // countingConn wraps a HalfCloseConn to count bytes transferred via Prometheus metrics.
 type countingConn struct {
 	HalfCloseConn
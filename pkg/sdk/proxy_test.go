@@ -3,11 +3,14 @@ package sdk
 import (
 	"errors"
 	"io"
+	"log/slog"
 	"net"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
 )
 
 // memConn implements HalfCloseConn for simple tests (not BidirectionalProxy).
@@ -320,3 +323,71 @@ func TestDialWithRetry_AllFail(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+// --- Relay session limit ---
+
+// cutConn is a proxy endpoint whose reads fail with err, standing in for a
+// stream the relay tore down mid-transfer.
+type cutConn struct {
+	err     error
+	relayed bool
+}
+
+func (c *cutConn) Read([]byte) (int, error)    { return 0, c.err }
+func (c *cutConn) Write(p []byte) (int, error) { return len(p), nil }
+func (c *cutConn) Close() error                { return nil }
+func (c *cutConn) CloseWrite() error           { return nil }
+func (c *cutConn) Relayed() bool               { return c.relayed }
+
+func TestClassifyCopyError(t *testing.T) {
+	relayed := &cutConn{relayed: true}
+	direct := &cutConn{}
+	local := &cutConn{err: io.EOF}
+
+	err := classifyCopyError(network.ErrReset, relayed, local)
+	if !errors.Is(err, ErrRelaySessionLimit) || !errors.Is(err, network.ErrReset) {
+		t.Errorf("reset on relayed stream: got %v", err)
+	}
+	// Wrappers for metrics and bandwidth shaping do not hide the relay.
+	wrapped := &countingConn{HalfCloseConn: &throttledConn{HalfCloseConn: relayed}}
+	if err := classifyCopyError(errors.New("yamux: session shutdown"), local, wrapped); !errors.Is(err, ErrRelaySessionLimit) {
+		t.Errorf("wrapped relayed stream: got %v", err)
+	}
+
+	if err := classifyCopyError(network.ErrReset, direct, local); errors.Is(err, ErrRelaySessionLimit) {
+		t.Errorf("reset on a direct stream must not blame the relay: %v", err)
+	}
+	if err := classifyCopyError(errors.New("broken pipe"), relayed, local); errors.Is(err, ErrRelaySessionLimit) {
+		t.Errorf("unrelated error must pass through: %v", err)
+	}
+	// A TCP reset comes from the local service, not the relay.
+	if err := classifyCopyError(errors.New("read tcp 127.0.0.1:22: connection reset by peer"), relayed, local); errors.Is(err, ErrRelaySessionLimit) {
+		t.Errorf("local TCP reset must not blame the relay: %v", err)
+	}
+}
+
+func TestBidirectionalProxyRelayLimitMessage(t *testing.T) {
+	capture := &logCapture{}
+	orig := slog.Default()
+	slog.SetDefault(slog.New(capture))
+	defer slog.SetDefault(orig)
+
+	local := &cutConn{err: io.EOF}
+	circuit := &cutConn{err: network.ErrReset, relayed: true}
+	BidirectionalProxy(local, circuit, "proxy")
+
+	recs := capture.matching(ErrRelaySessionLimit.Error())
+	if len(recs) != 1 {
+		t.Fatalf("expected one relay-limit log record, got %d", len(recs))
+	}
+	if hint, ok := recordAttr(recs[0], "hint"); !ok || !strings.Contains(hint.String(), "fresh relay circuit") {
+		t.Errorf("missing actionable hint: %v", hint)
+	}
+	if n := len(capture.matching("copy error")); n != 0 {
+		t.Errorf("relay cut should not also log a bare copy error (%d)", n)
+	}
+
+	if msg := HumanizeError(ErrRelaySessionLimit.Error() + ": stream reset"); !strings.Contains(msg, "likely the relay's session data or time limit") {
+		t.Errorf("HumanizeError = %q", msg)
+	}
+}
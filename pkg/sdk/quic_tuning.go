@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	"github.com/libp2p/go-libp2p/p2p/transport/quicreuse"
+	"github.com/quic-go/quic-go"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+// newTunedQUICConnManager wraps quicreuse.NewConnManager so the
+// network.quic settings are applied to the QUIC config used for every
+// connection this node dials. quicreuse only exposes the client config;
+// inbound connections keep libp2p's defaults, and since either side's
+// keepalives hold a NAT mapping open, the dialing side (the node behind the
+// satellite link) is the one that needs tuning.
+func newTunedQUICConnManager(tuning config.QUICConfig) func(quic.StatelessResetKey, quic.TokenGeneratorKey, ...quicreuse.Option) (*quicreuse.ConnManager, error) {
+	return func(srk quic.StatelessResetKey, tk quic.TokenGeneratorKey, opts ...quicreuse.Option) (*quicreuse.ConnManager, error) {
+		cm, err := quicreuse.NewConnManager(srk, tk, opts...)
+		if err != nil {
+			return nil, err
+		}
+		applyQUICTuning(cm.ClientConfig(), tuning)
+		return cm, nil
+	}
+}
+
+// applyQUICTuning overrides qc with the non-zero settings in tuning. Zero
+// values leave the libp2p defaults untouched.
+func applyQUICTuning(qc *quic.Config, tuning config.QUICConfig) {
+	if tuning.InitialPacketSize != 0 {
+		qc.InitialPacketSize = tuning.InitialPacketSize
+	}
+	if tuning.DisableMTUDiscovery {
+		qc.DisablePathMTUDiscovery = true
+	}
+	if tuning.KeepalivePeriod != 0 {
+		qc.KeepAlivePeriod = tuning.KeepalivePeriod
+	}
+}
@@ -0,0 +1,51 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+func TestTunedQUICConnManagerDefaults(t *testing.T) {
+	cm, err := newTunedQUICConnManager(config.QUICConfig{})(quic.StatelessResetKey{}, quic.TokenGeneratorKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cm.Close()
+
+	// Zero tuning must leave the libp2p defaults in place.
+	qc := cm.ClientConfig()
+	if qc.KeepAlivePeriod != 15*time.Second {
+		t.Errorf("KeepAlivePeriod = %s, want libp2p default 15s", qc.KeepAlivePeriod)
+	}
+	if qc.InitialPacketSize != 0 || qc.DisablePathMTUDiscovery {
+		t.Errorf("packet settings changed without tuning: size=%d disableMTU=%v", qc.InitialPacketSize, qc.DisablePathMTUDiscovery)
+	}
+}
+
+func TestTunedQUICConnManagerAppliesTuning(t *testing.T) {
+	tuning := config.QUICConfig{
+		InitialPacketSize:   1200,
+		DisableMTUDiscovery: true,
+		KeepalivePeriod:     5 * time.Second,
+	}
+	cm, err := newTunedQUICConnManager(tuning)(quic.StatelessResetKey{}, quic.TokenGeneratorKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cm.Close()
+
+	qc := cm.ClientConfig()
+	if qc.InitialPacketSize != 1200 {
+		t.Errorf("InitialPacketSize = %d, want 1200", qc.InitialPacketSize)
+	}
+	if !qc.DisablePathMTUDiscovery {
+		t.Error("DisablePathMTUDiscovery should be set")
+	}
+	if qc.KeepAlivePeriod != 5*time.Second {
+		t.Errorf("KeepAlivePeriod = %s, want 5s", qc.KeepAlivePeriod)
+	}
+}
@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"sync/atomic"
+	"time"
+
+	pbv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/pb"
+	relayv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/relay"
+)
+
+// RelayMetricsTracer tracks live reservation and circuit counts and total
+// relayed bytes for the circuit relay v2 service, which does not expose
+// them. The counts feed the periodic status log via Snapshot and, when
+// metrics are enabled, the shurli_relay_* metrics. Pass it to relayv2.New
+// with WithMetricsTracer; the service accepts only one tracer.
+type RelayMetricsTracer struct {
+	m *Metrics // nil when metrics are disabled
+
+	reservations atomic.Int64
+	circuits     atomic.Int64
+	bytes        atomic.Uint64
+}
+
+var _ relayv2.MetricsTracer = (*RelayMetricsTracer)(nil)
+
+// RelayServiceStats is a point-in-time copy of the RelayMetricsTracer counts.
+type RelayServiceStats struct {
+	Reservations int64
+	Circuits     int64
+	BytesRelayed uint64
+}
+
+// NewRelayMetricsTracer returns a tracer that counts relay service events
+// and records them on m. m may be nil to only keep the counts.
+func NewRelayMetricsTracer(m *Metrics) *RelayMetricsTracer {
+	return &RelayMetricsTracer{m: m}
+}
+
+// Snapshot returns the current counts.
+func (t *RelayMetricsTracer) Snapshot() RelayServiceStats {
+	return RelayServiceStats{
+		Reservations: t.reservations.Load(),
+		Circuits:     t.circuits.Load(),
+		BytesRelayed: t.bytes.Load(),
+	}
+}
+
+func (t *RelayMetricsTracer) RelayStatus(enabled bool) {
+	if !enabled {
+		// The service closed every reservation and circuit with it.
+		t.reservations.Store(0)
+		t.circuits.Store(0)
+		if t.m != nil {
+			t.m.RelayReservationsActive.Set(0)
+			t.m.RelayCircuitsActive.Set(0)
+		}
+	}
+}
+
+func (t *RelayMetricsTracer) ConnectionOpened() {
+	t.circuits.Add(1)
+	if t.m != nil {
+		t.m.RelayCircuitsActive.Inc()
+	}
+}
+
+func (t *RelayMetricsTracer) ConnectionClosed(time.Duration) {
+	t.circuits.Add(-1)
+	if t.m != nil {
+		t.m.RelayCircuitsActive.Dec()
+	}
+}
+
+func (t *RelayMetricsTracer) ConnectionRequestHandled(pbv2.Status) {}
+
+func (t *RelayMetricsTracer) ReservationAllowed(isRenewal bool) {
+	if isRenewal {
+		return
+	}
+	t.reservations.Add(1)
+	if t.m != nil {
+		t.m.RelayReservationsActive.Inc()
+	}
+}
+
+func (t *RelayMetricsTracer) ReservationClosed(cnt int) {
+	t.reservations.Add(-int64(cnt))
+	if t.m != nil {
+		t.m.RelayReservationsActive.Sub(float64(cnt))
+	}
+}
+
+func (t *RelayMetricsTracer) ReservationRequestHandled(pbv2.Status) {}
+
+func (t *RelayMetricsTracer) BytesTransferred(cnt int) {
+	t.bytes.Add(uint64(cnt))
+	if t.m != nil {
+		t.m.RelayDataRelayedBytesTotal.Add(float64(cnt))
+	}
+}
@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	pbv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/pb"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricValue reads the current value of a gauge or counter.
+func metricValue(t *testing.T, c prometheus.Metric) float64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := c.Write(m); err != nil {
+		t.Fatalf("read metric: %v", err)
+	}
+	if m.Gauge != nil {
+		return m.GetGauge().GetValue()
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestRelayMetricsTracer(t *testing.T) {
+	m := NewMetrics("test", "go1.26.0")
+	tr := NewRelayMetricsTracer(m)
+
+	tr.RelayStatus(true)
+	tr.ReservationAllowed(false)
+	tr.ReservationAllowed(false)
+	tr.ReservationAllowed(true) // renewal of an existing reservation
+	tr.ReservationAllowed(false)
+	tr.ReservationClosed(2)
+	if got := metricValue(t, m.RelayReservationsActive); got != 1 {
+		t.Errorf("reservations active = %v, want 1", got)
+	}
+
+	tr.ConnectionOpened()
+	tr.ConnectionOpened()
+	tr.ConnectionClosed(time.Second)
+	if got := metricValue(t, m.RelayCircuitsActive); got != 1 {
+		t.Errorf("circuits active = %v, want 1", got)
+	}
+
+	tr.BytesTransferred(1000)
+	tr.BytesTransferred(24)
+	if got := metricValue(t, m.RelayDataRelayedBytesTotal); got != 1024 {
+		t.Errorf("data relayed = %v, want 1024", got)
+	}
+	want := RelayServiceStats{Reservations: 1, Circuits: 1, BytesRelayed: 1024}
+	if got := tr.Snapshot(); got != want {
+		t.Errorf("Snapshot = %+v, want %+v", got, want)
+	}
+
+	// Stopping the service drops everything it held.
+	tr.RelayStatus(false)
+	if r, c := metricValue(t, m.RelayReservationsActive), metricValue(t, m.RelayCircuitsActive); r != 0 || c != 0 {
+		t.Errorf("after stop: reservations %v circuits %v, want 0 0", r, c)
+	}
+	if got := metricValue(t, m.RelayDataRelayedBytesTotal); got != 1024 {
+		t.Errorf("data relayed reset on stop: %v", got)
+	}
+}
+
+func TestRelayMetricsTracer_NoMetrics(t *testing.T) {
+	tr := NewRelayMetricsTracer(nil)
+	tr.ReservationAllowed(false)
+	tr.ConnectionOpened()
+	tr.ConnectionRequestHandled(pbv2.Status_OK)
+	tr.ReservationRequestHandled(pbv2.Status_OK)
+	tr.BytesTransferred(512)
+
+	want := RelayServiceStats{Reservations: 1, Circuits: 1, BytesRelayed: 512}
+	if got := tr.Snapshot(); got != want {
+		t.Errorf("Snapshot = %+v, want %+v", got, want)
+	}
+
+	tr.RelayStatus(false)
+	if got := tr.Snapshot(); got.Reservations != 0 || got.Circuits != 0 || got.BytesRelayed != 512 {
+		t.Errorf("after relay stop = %+v, want zero live counts and bytes kept", got)
+	}
+}
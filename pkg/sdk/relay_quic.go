@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// relayQUICFallbackDelay is how long a static relay's non-QUIC addresses
+// are held back when relay.prefer_quic is set. The swarm dials them at once
+// if every QUIC dial has already failed, so a relay with a filtered UDP
+// port costs at most this long, and usually far less.
+const relayQUICFallbackDelay = 5 * time.Second
+
+// isQUICAddr reports whether addr dials over QUIC (quic-v1, draft-29 quic,
+// or WebTransport on top of QUIC).
+func isQUICAddr(addr ma.Multiaddr) bool {
+	quic := false
+	ma.ForEach(addr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_QUIC_V1, ma.P_QUIC:
+			quic = true
+			return false
+		}
+		return true
+	})
+	return quic
+}
+
+// relayQUICDialRanker returns a dial ranker that dials the static relays'
+// QUIC addresses first and their other addresses only after
+// relayQUICFallbackDelay. libp2p's default ranker gives QUIC just a short
+// head start, so TCP often wins the race and then carries every circuit
+// through the relay. Dials to other peers are ranked as usual.
+//
+// A ranker only sees addresses, not the peer being dialed, so a dial is
+// recognized as a relay dial by sharing an address with a relay's
+// peerstore entry. The swarm records resolved DNS addresses there before
+// ranking, so /dns relay addresses match too. ps is read at dial time,
+// because the ranker is built before the host (and its peerstore) exist.
+func relayQUICDialRanker(ps func() peerstore.Peerstore, relays []peer.ID) network.DialRanker {
+	return func(addrs []ma.Multiaddr) []network.AddrDelay {
+		store := ps()
+		if store == nil || !isRelayDial(store, relays, addrs) {
+			return swarm.DefaultDialRanker(addrs)
+		}
+		var quic, other []ma.Multiaddr
+		for _, a := range addrs {
+			if isQUICAddr(a) {
+				quic = append(quic, a)
+			} else {
+				other = append(other, a)
+			}
+		}
+		if len(quic) == 0 {
+			return swarm.DefaultDialRanker(addrs)
+		}
+		ranked := swarm.DefaultDialRanker(quic)
+		for _, ad := range swarm.DefaultDialRanker(other) {
+			ad.Delay += relayQUICFallbackDelay
+			ranked = append(ranked, ad)
+		}
+		return ranked
+	}
+}
+
+// isRelayDial reports whether any of addrs belongs to one of relays.
+func isRelayDial(store peerstore.Peerstore, relays []peer.ID, addrs []ma.Multiaddr) bool {
+	for _, id := range relays {
+		for _, known := range store.Addrs(id) {
+			for _, a := range addrs {
+				if a.Equal(known) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
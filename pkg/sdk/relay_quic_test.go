@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestRelayQUICDialRanker(t *testing.T) {
+	ps, err := pstoremem.NewPeerstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ps.Close()
+
+	relayID := genTestPeerID(t)
+	relayTCP := ma.StringCast("/ip4/203.0.113.7/tcp/7777")
+	relayQUIC := ma.StringCast("/ip4/203.0.113.7/udp/7777/quic-v1")
+	ps.AddAddrs(relayID, []ma.Multiaddr{relayTCP, relayQUIC}, peerstore.PermanentAddrTTL)
+
+	rank := relayQUICDialRanker(func() peerstore.Peerstore { return ps }, []peer.ID{relayID})
+
+	// Relay dial: QUIC first, TCP held back even though it is listed first.
+	ranked := rank([]ma.Multiaddr{relayTCP, relayQUIC})
+	if len(ranked) != 2 {
+		t.Fatalf("got %d ranked addrs, want 2", len(ranked))
+	}
+	if !ranked[0].Addr.Equal(relayQUIC) || ranked[0].Delay != 0 {
+		t.Errorf("first dial = %s after %v, want %s at once", ranked[0].Addr, ranked[0].Delay, relayQUIC)
+	}
+	if !ranked[1].Addr.Equal(relayTCP) || ranked[1].Delay < relayQUICFallbackDelay {
+		t.Errorf("second dial = %s after %v, want %s after >= %v", ranked[1].Addr, ranked[1].Delay, relayTCP, relayQUICFallbackDelay)
+	}
+
+	// Any other peer keeps libp2p's default ranking.
+	other := []ma.Multiaddr{
+		ma.StringCast("/ip4/198.51.100.2/tcp/4001"),
+		ma.StringCast("/ip4/198.51.100.2/udp/4001/quic-v1"),
+	}
+	got, want := rank(other), swarm.DefaultDialRanker(other)
+	if len(got) != len(want) {
+		t.Fatalf("other peer: got %d ranked addrs, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Addr.Equal(want[i].Addr) || got[i].Delay != want[i].Delay {
+			t.Errorf("other peer [%d] = %s/%v, want %s/%v", i, got[i].Addr, got[i].Delay, want[i].Addr, want[i].Delay)
+		}
+	}
+
+	// A relay with no QUIC address is not delayed.
+	if ranked := rank([]ma.Multiaddr{relayTCP}); len(ranked) != 1 || ranked[0].Delay != 0 {
+		t.Errorf("TCP-only relay ranked %v, want an immediate dial", ranked)
+	}
+}
+
+func TestRelayQUICDialRankerConnectsOverQUIC(t *testing.T) {
+	relay, err := libp2p.New(libp2p.ListenAddrStrings(
+		"/ip4/127.0.0.1/tcp/0",
+		"/ip4/127.0.0.1/udp/0/quic-v1",
+	))
+	if err != nil {
+		t.Fatalf("create relay host: %v", err)
+	}
+	defer relay.Close()
+
+	ps, err := pstoremem.NewPeerstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := libp2p.New(
+		libp2p.NoListenAddrs,
+		libp2p.Peerstore(ps),
+		libp2p.DialRanker(relayQUICDialRanker(func() peerstore.Peerstore { return ps }, []peer.ID{relay.ID()})),
+	)
+	if err != nil {
+		t.Fatalf("create client host: %v", err)
+	}
+	defer client.Close()
+
+	// Config order lists TCP first; QUIC must still be the address dialed.
+	var tcpAddrs, quicAddrs []ma.Multiaddr
+	for _, a := range relay.Addrs() {
+		if isQUICAddr(a) {
+			quicAddrs = append(quicAddrs, a)
+		} else {
+			tcpAddrs = append(tcpAddrs, a)
+		}
+	}
+	if len(tcpAddrs) == 0 || len(quicAddrs) == 0 {
+		t.Fatalf("relay should listen on TCP and QUIC, has %v", relay.Addrs())
+	}
+	ai := peer.AddrInfo{ID: relay.ID(), Addrs: append(tcpAddrs, quicAddrs...)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayQUICFallbackDelay/2)
+	defer cancel()
+	start := time.Now()
+	if err := client.Connect(ctx, ai); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	conns := client.Network().ConnsToPeer(relay.ID())
+	if len(conns) != 1 {
+		t.Fatalf("got %d connections to relay, want 1", len(conns))
+	}
+	if addr := conns[0].RemoteMultiaddr(); !isQUICAddr(addr) {
+		t.Errorf("relay connection uses %s after %v, want QUIC", addr, time.Since(start))
+	}
+}
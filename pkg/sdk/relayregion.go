@@ -0,0 +1,71 @@
+package sdk
+
+import (
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// RelayRegionToken prefixes the region a relay advertises in its identify
+// agent version, e.g. "relay-server/0.3.0 (syd-1) region/au-sydney".
+const RelayRegionToken = "region/"
+
+// RelayAgentRegion returns the region advertised in a relay's agent
+// version, or "" if it has none. Only text after the relay name (in
+// parentheses) is considered, so a name cannot spoof a region.
+func RelayAgentRegion(agentVersion string) string {
+	if i := strings.LastIndex(agentVersion, ")"); i >= 0 {
+		agentVersion = agentVersion[i+1:]
+	}
+	for _, f := range strings.Fields(agentVersion) {
+		if r, ok := strings.CutPrefix(f, RelayRegionToken); ok {
+			return r
+		}
+	}
+	return ""
+}
+
+// RelayRegion returns the region relay p advertised via identify, or ""
+// if unknown (not yet identified, or no region configured).
+func RelayRegion(h host.Host, p peer.ID) string {
+	av, err := h.Peerstore().Get(p, "AgentVersion")
+	if err != nil {
+		return ""
+	}
+	s, _ := av.(string)
+	return RelayAgentRegion(s)
+}
+
+// preferRelayRegion reorders relay addresses so relays that advertised
+// region come first, keeping the existing order (health/budget score)
+// within each group. Addresses it cannot parse keep their relative place
+// among the non-matching relays.
+func preferRelayRegion(h host.Host, relayAddrs []string, region string) []string {
+	if region == "" || len(relayAddrs) < 2 {
+		return relayAddrs
+	}
+	near := make([]string, 0, len(relayAddrs))
+	far := make([]string, 0, len(relayAddrs))
+	for _, a := range relayAddrs {
+		if relayAddrInRegion(h, a, region) {
+			near = append(near, a)
+		} else {
+			far = append(far, a)
+		}
+	}
+	return append(near, far...)
+}
+
+func relayAddrInRegion(h host.Host, addr, region string) bool {
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return false
+	}
+	ai, err := peer.AddrInfoFromP2pAddr(maddr)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(RelayRegion(h, ai.ID), region)
+}
@@ -0,0 +1,60 @@
+package sdk
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestRelayAgentRegion(t *testing.T) {
+	for _, tc := range []struct {
+		agent, want string
+	}{
+		{"relay-server/0.3.0", ""},
+		{"relay-server/0.3.0 (syd-1)", ""},
+		{"relay-server/0.3.0 region/au-sydney", "au-sydney"},
+		{"relay-server/0.3.0 (syd-1) region/au-sydney", "au-sydney"},
+		{"relay-server/0.3.0 (region/eu-west) region/au-sydney", "au-sydney"},
+		{"relay-server/0.3.0 (region/eu-west)", ""},
+		{"shurli/0.3.0", ""},
+	} {
+		if got := RelayAgentRegion(tc.agent); got != tc.want {
+			t.Errorf("RelayAgentRegion(%q) = %q, want %q", tc.agent, got, tc.want)
+		}
+	}
+}
+
+func TestPreferRelayRegion(t *testing.T) {
+	h, err := libp2p.New(libp2p.NoListenAddrs, libp2p.DisableRelay())
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	defer h.Close()
+
+	us := "/ip4/203.0.113.50/tcp/4001/p2p/12D3KooWDpJ7As7BWAwRMfu1VU2WCqNjvq387JEYKDBj4kx6nXTN"
+	au := "/ip4/203.0.113.51/tcp/4001/p2p/12D3KooWHVXoYnSJaifkK3bQN5RhnNiVDSbhVAhTwrJVwL3fZcmo"
+	unknown := "/ip4/203.0.113.52/tcp/4001/p2p/12D3KooWLRPJAA5o6Z6ucCKpLkxWnN1ZV6Su4UfsdmQPGbqGhWmw"
+	for addr, agent := range map[string]string{
+		us: "relay-server/0.3.0 (nyc) region/us-east",
+		au: "relay-server/0.3.0 (syd) region/au-sydney",
+	} {
+		ai, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Peerstore().Put(ai.ID, "AgentVersion", agent)
+	}
+
+	addrs := []string{us, unknown, au}
+	if got := preferRelayRegion(h, addrs, "au-sydney"); !slices.Equal(got, []string{au, us, unknown}) {
+		t.Errorf("prefer au-sydney = %v", got)
+	}
+	if got := preferRelayRegion(h, addrs, "eu-west"); !slices.Equal(got, addrs) {
+		t.Errorf("no match should keep order, got %v", got)
+	}
+	if got := preferRelayRegion(h, addrs, ""); !slices.Equal(got, addrs) {
+		t.Errorf("no preference should keep order, got %v", got)
+	}
+}
@@ -0,0 +1,310 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Relay roles assigned by RelaySelector.
+const (
+	RelayRolePrimary = "primary"
+	RelayRoleBackup  = "backup"
+)
+
+// relaySwitchFactor is how much faster (as a fraction of the current
+// primary's RTT) another relay must be before it takes over as primary.
+// Without it, two relays with similar latency would swap on every probe.
+const relaySwitchFactor = 0.75
+
+// relayProbeTimeout bounds a single latency probe.
+const relayProbeTimeout = 10 * time.Second
+
+// RelayLatency is the latest latency measurement for one configured relay.
+type RelayLatency struct {
+	PeerID    peer.ID   `json:"peer_id"`
+	RTTMs     float64   `json:"rtt_ms"`    // EWMA of ping RTT; 0 until the first success
+	Reachable bool      `json:"reachable"` // last probe succeeded
+	LastProbe time.Time `json:"last_probe"`
+	Error     string    `json:"error,omitempty"`
+	Role      string    `json:"role,omitempty"` // RelayRolePrimary, RelayRoleBackup, or ""
+}
+
+// RelaySelector ranks the configured relays by application-level ping RTT
+// and picks a primary (lowest latency) and a backup to hold reservations
+// on. Probes use libp2p ping over the connection that is already open; a
+// relay that is not connected counts as unreachable rather than being
+// dialed, so the probe measures the path reservations actually use.
+//
+// When the primary stops answering, the backup is promoted on the next
+// probe. Until the first probe completes (or when no relay answers) there
+// is no selection and Preferred reports every relay, so reservations are
+// attempted everywhere as before.
+type RelaySelector struct {
+	host   host.Host
+	relays []peer.ID // configured order, used to break RTT ties
+	ping   func(ctx context.Context, p peer.ID) (time.Duration, error)
+
+	mu       sync.RWMutex
+	latency  map[peer.ID]*RelayLatency
+	primary  peer.ID
+	backup   peer.ID
+	onChange func(primary, backup peer.ID)
+}
+
+// NewRelaySelector creates a selector for the given relays.
+func NewRelaySelector(h host.Host, relays []peer.AddrInfo) *RelaySelector {
+	s := &RelaySelector{
+		host:    h,
+		latency: make(map[peer.ID]*RelayLatency, len(relays)),
+	}
+	for _, ai := range relays {
+		if _, dup := s.latency[ai.ID]; dup {
+			continue
+		}
+		s.relays = append(s.relays, ai.ID)
+		s.latency[ai.ID] = &RelayLatency{PeerID: ai.ID}
+	}
+	s.ping = s.pingRelay
+	return s
+}
+
+// OnChange registers a callback invoked (outside the lock) whenever the
+// primary or backup changes. Either may be empty.
+func (s *RelaySelector) OnChange(fn func(primary, backup peer.ID)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// pingRelay sends one libp2p ping over an existing connection.
+func (s *RelaySelector) pingRelay(ctx context.Context, p peer.ID) (time.Duration, error) {
+	if s.host.Network().Connectedness(p) != network.Connected {
+		return 0, errors.New("not connected")
+	}
+	ctx, cancel := context.WithCancel(network.WithNoDial(ctx, "relay latency probe"))
+	defer cancel()
+	select {
+	case res := <-ping.Ping(ctx, s.host, p):
+		return res.RTT, res.Error
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Probe pings every relay once, updates the latencies, and reselects the
+// primary and backup. It reports whether the selection changed.
+func (s *RelaySelector) Probe(ctx context.Context) bool {
+	type result struct {
+		pid peer.ID
+		rtt time.Duration
+		err error
+	}
+	results := make(chan result, len(s.relays))
+	var wg sync.WaitGroup
+	for _, pid := range s.relays {
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, relayProbeTimeout)
+			defer cancel()
+			rtt, err := s.ping(probeCtx, pid)
+			results <- result{pid, rtt, err}
+		}(pid)
+	}
+	wg.Wait()
+	close(results)
+
+	now := time.Now()
+	s.mu.Lock()
+	for r := range results {
+		l := s.latency[r.pid]
+		l.LastProbe = now
+		if r.err != nil {
+			l.Reachable = false
+			l.Error = r.err.Error()
+			continue
+		}
+		ms := float64(r.rtt.Microseconds()) / 1000
+		if l.RTTMs == 0 {
+			l.RTTMs = ms
+		} else {
+			l.RTTMs = l.RTTMs*(1-ewmaAlpha) + ms*ewmaAlpha
+		}
+		l.Reachable = true
+		l.Error = ""
+	}
+	primary, backup := s.selectLocked()
+	changed := primary != s.primary || backup != s.backup
+	s.primary, s.backup = primary, backup
+	fn := s.onChange
+	s.mu.Unlock()
+
+	if changed && fn != nil {
+		fn(primary, backup)
+	}
+	return changed
+}
+
+// selectLocked picks the primary and backup from the reachable relays.
+// The current primary is kept unless it is unreachable or another relay
+// is faster by more than relaySwitchFactor.
+func (s *RelaySelector) selectLocked() (primary, backup peer.ID) {
+	var candidates []*RelayLatency
+	for _, pid := range s.relays {
+		if l := s.latency[pid]; l.Reachable {
+			candidates = append(candidates, l)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", ""
+	}
+	// Stable sort keeps configured order among equal RTTs.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].RTTMs < candidates[j].RTTMs
+	})
+
+	primary = candidates[0].PeerID
+	if cur, ok := s.latency[s.primary]; ok && cur.Reachable && cur.PeerID != primary {
+		if candidates[0].RTTMs >= cur.RTTMs*relaySwitchFactor {
+			primary = cur.PeerID
+		}
+	}
+	for _, c := range candidates {
+		if c.PeerID != primary {
+			backup = c.PeerID
+			break
+		}
+	}
+	return primary, backup
+}
+
+// Start probes every interval until ctx is done.
+func (s *RelaySelector) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Probe(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ranked returns the selected relays in preference order: the primary, then
+// the backup. It is nil until a selection exists.
+func (s *RelaySelector) Ranked() []peer.ID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []peer.ID
+	for _, p := range []peer.ID{s.primary, s.backup} {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Primary returns the current primary relay, or "" if none is selected.
+func (s *RelaySelector) Primary() peer.ID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.primary
+}
+
+// Preferred reports whether the node should hold a reservation on relay
+// p: it is the primary or the backup, or nothing has been selected yet.
+func (s *RelaySelector) Preferred(p peer.ID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.primary == "" || p == s.primary || p == s.backup
+}
+
+// Latencies returns the latest measurement for every relay, in configured
+// order, with the primary and backup marked.
+func (s *RelaySelector) Latencies() []RelayLatency {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]RelayLatency, 0, len(s.relays))
+	for _, pid := range s.relays {
+		l := *s.latency[pid]
+		switch pid {
+		case s.primary:
+			l.Role = RelayRolePrimary
+		case s.backup:
+			l.Role = RelayRoleBackup
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// relayPeerSource feeds AutoRelay its candidates from the configured relays.
+// Once a RelaySelector is attached, only the selected relays are offered,
+// primary first, so AutoRelay reserves where the latency ranking says to.
+// Until then (or when no relay answers probes) every relay is offered in
+// configured order.
+type relayPeerSource struct {
+	static   []peer.AddrInfo
+	selector atomic.Pointer[RelaySelector]
+}
+
+// candidates implements autorelay.PeerSource.
+func (ps *relayPeerSource) candidates(_ context.Context, num int) <-chan peer.AddrInfo {
+	order := ps.static
+	if sel := ps.selector.Load(); sel != nil {
+		if ranked := sel.Ranked(); len(ranked) > 0 {
+			byID := make(map[peer.ID]peer.AddrInfo, len(ps.static))
+			for _, ai := range ps.static {
+				byID[ai.ID] = ai
+			}
+			order = nil
+			for _, p := range ranked {
+				if ai, ok := byID[p]; ok {
+					order = append(order, ai)
+				}
+			}
+		}
+	}
+	if num > len(order) {
+		num = len(order)
+	}
+	ch := make(chan peer.AddrInfo, num)
+	for _, ai := range order[:num] {
+		ch <- ai
+	}
+	close(ch)
+	return ch
+}
+
+// ReleaseRelay closes the connection to a relay that is no longer selected
+// so AutoRelay drops its reservation there and asks for a new candidate.
+// A relay still carrying relayed connections to this node is left alone.
+// Reports whether the connection was closed.
+func ReleaseRelay(h host.Host, relay peer.ID) bool {
+	for _, c := range h.Network().Conns() {
+		if c.Stat().Limited {
+			if id, err := c.RemoteMultiaddr().ValueForProtocol(ma.P_P2P); err == nil && id == relay.String() {
+				return false
+			}
+		}
+	}
+	if h.Network().Connectedness(relay) != network.Connected {
+		return false
+	}
+	return h.Network().ClosePeer(relay) == nil
+}
@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// fakeRelayPings returns a RelaySelector ping function answering from rtts;
+// a missing peer fails.
+func fakeRelayPings(rtts map[peer.ID]time.Duration) func(context.Context, peer.ID) (time.Duration, error) {
+	return func(_ context.Context, p peer.ID) (time.Duration, error) {
+		rtt, ok := rtts[p]
+		if !ok {
+			return 0, errors.New("no pong")
+		}
+		return rtt, nil
+	}
+}
+
+func TestRelaySelector_PrimaryBackupAndFailover(t *testing.T) {
+	testPeer3 := genTestPeerID(t)
+	relays := []peer.AddrInfo{{ID: testPeer1}, {ID: testPeer2}, {ID: testPeer3}}
+	s := NewRelaySelector(nil, relays)
+
+	// Nothing measured yet: every relay is preferred.
+	for _, ai := range relays {
+		if !s.Preferred(ai.ID) {
+			t.Fatalf("relay %s not preferred before first probe", ai.ID)
+		}
+	}
+
+	var changes [][2]peer.ID
+	s.OnChange(func(primary, backup peer.ID) { changes = append(changes, [2]peer.ID{primary, backup}) })
+
+	rtts := map[peer.ID]time.Duration{
+		testPeer1: 120 * time.Millisecond,
+		testPeer2: 20 * time.Millisecond,
+		testPeer3: 60 * time.Millisecond,
+	}
+	s.ping = fakeRelayPings(rtts)
+	if !s.Probe(context.Background()) {
+		t.Fatal("first probe did not report a selection change")
+	}
+	if s.Primary() != testPeer2 {
+		t.Fatalf("primary = %s, want lowest-latency relay", s.Primary())
+	}
+	if !s.Preferred(testPeer3) || s.Preferred(testPeer1) {
+		t.Error("want the 60ms relay as backup and the 120ms relay unpreferred")
+	}
+
+	// A slightly faster relay does not take over (hysteresis).
+	rtts[testPeer3] = 18 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		s.Probe(context.Background())
+	}
+	if s.Primary() != testPeer2 {
+		t.Errorf("primary switched to a relay only marginally faster")
+	}
+
+	// Primary goes down: the backup is promoted without waiting.
+	delete(rtts, testPeer2)
+	s.Probe(context.Background())
+	if s.Primary() != testPeer3 {
+		t.Fatalf("primary = %s after failure, want backup promoted", s.Primary())
+	}
+	if !s.Preferred(testPeer1) {
+		t.Error("remaining relay should become the new backup")
+	}
+	last := changes[len(changes)-1]
+	if last != [2]peer.ID{testPeer3, testPeer1} {
+		t.Errorf("last change = %v, want promoted backup and new backup", last)
+	}
+
+	roles := map[peer.ID]string{}
+	for _, l := range s.Latencies() {
+		roles[l.PeerID] = l.Role
+		if l.PeerID == testPeer2 && (l.Reachable || l.Error == "") {
+			t.Errorf("down relay reported reachable: %+v", l)
+		}
+	}
+	if roles[testPeer3] != RelayRolePrimary || roles[testPeer1] != RelayRoleBackup || roles[testPeer2] != "" {
+		t.Errorf("roles = %v", roles)
+	}
+
+	// No relay answers: selection clears so reservations are tried everywhere.
+	s.ping = fakeRelayPings(nil)
+	s.Probe(context.Background())
+	if s.Primary() != "" || !s.Preferred(testPeer2) {
+		t.Error("want no selection when every relay is down")
+	}
+}
+
+func TestRelayPeerSource_FollowsRanking(t *testing.T) {
+	testPeer3 := genTestPeerID(t)
+	relays := []peer.AddrInfo{{ID: testPeer1}, {ID: testPeer2}, {ID: testPeer3}}
+	ps := &relayPeerSource{static: relays}
+
+	drain := func(num int) []peer.ID {
+		var out []peer.ID
+		for ai := range ps.candidates(context.Background(), num) {
+			out = append(out, ai.ID)
+		}
+		return out
+	}
+
+	// No selector: every relay in configured order.
+	if got := drain(10); len(got) != 3 || got[0] != testPeer1 || got[2] != testPeer3 {
+		t.Fatalf("unranked candidates = %v", got)
+	}
+
+	s := NewRelaySelector(nil, relays)
+	ps.selector.Store(s)
+	// Selector attached but nothing measured: still every relay.
+	if got := drain(10); len(got) != 3 {
+		t.Fatalf("candidates before first probe = %v", got)
+	}
+
+	s.ping = fakeRelayPings(map[peer.ID]time.Duration{
+		testPeer1: 120 * time.Millisecond,
+		testPeer2: 60 * time.Millisecond,
+		testPeer3: 20 * time.Millisecond,
+	})
+	s.Probe(context.Background())
+	if got := drain(10); len(got) != 2 || got[0] != testPeer3 || got[1] != testPeer2 {
+		t.Errorf("ranked candidates = %v, want primary then backup", got)
+	}
+	if got := drain(1); len(got) != 1 || got[0] != testPeer3 {
+		t.Errorf("one candidate = %v, want the primary", got)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"log/slog"
 	"net"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,14 +14,14 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"golang.org/x/time/rate"
 
 	"github.com/shurlinet/shurli/internal/validate"
 )
 
 // connectionTag returns "[RELAYED]" or "[DIRECT]" based on the stream's connection path.
 func connectionTag(s network.Stream) string {
-	addr := s.Conn().RemoteMultiaddr().String()
-	if strings.Contains(addr, "/p2p-circuit") {
+	if ClassifyAddr(s.Conn().RemoteMultiaddr()) == AddrCircuit {
 		return "[RELAYED]"
 	}
 	return "[DIRECT]"
@@ -48,6 +47,42 @@ type Service struct {
 	Enabled      bool                // Whether this service is enabled
 	AllowedPeers map[peer.ID]struct{} // Per-service ACL (nil = all authorized peers allowed). Used by TCP proxy path.
 	Policy       *PluginPolicy        // Transport + peer restrictions (nil = no policy, backward compat for TCP proxies).
+
+	// MaxBandwidthMbps caps TCP proxy throughput per direction, shared by all
+	// connections to the service (0 = unlimited). Ignored for custom handlers.
+	MaxBandwidthMbps float64
+
+	// MaxConcurrent and RatePerMinute cap the open streams and new streams
+	// per minute that each remote peer may have on the service (0 = unlimited).
+	// A stream over budget is reset with a stream error code before the
+	// service sees it; closing a stream frees its slot.
+	MaxConcurrent int
+	RatePerMinute int
+
+	// RequireVerified and LocalNetwork are in force from registration, before
+	// the stream handler is installed, so no stream is ever handled without
+	// them. Once registered, change them only through SetRequireVerified and
+	// SetLocalNetwork, which hold the registry lock.
+	RequireVerified bool
+	LocalNetwork    string // "tcp" (default) or "udp"
+
+	ingressLimiter *rate.Limiter // remote peer → local service; nil = unlimited
+	egressLimiter  *rate.Limiter // local service → remote peer; nil = unlimited
+	peerLimits     *peerLimiter  // per-peer stream budget; nil = unlimited
+}
+
+// allowsPeer reports whether the service's access control admits p: the
+// plugin policy's peer lists, or the legacy AllowedPeers map for TCP
+// proxies without a policy. Transport restrictions are not considered.
+func (svc *Service) allowsPeer(p peer.ID) bool {
+	if svc.Policy != nil {
+		return svc.Policy.PeerAllowed(p)
+	}
+	if svc.AllowedPeers != nil {
+		_, ok := svc.AllowedPeers[p]
+		return ok
+	}
+	return true
 }
 
 // ServiceConn represents a connection to a remote service
@@ -77,6 +112,21 @@ type TokenVerifier func(tokenBase64 string, peerID peer.ID, service string, tran
 // token is available for the given peer and service.
 type TokenLookup func(peerID peer.ID, service string) string
 
+// VerifiedChecker reports whether a peer has been verified out-of-band (the
+// SAS flow of 'shurli verify'). Injected by the daemon; consulted only for
+// services marked with SetRequireVerified.
+type VerifiedChecker func(peerID peer.ID) bool
+
+// ServiceAccessHook is called when an inbound stream passes every access
+// check, just before the service handles it. Injected by the daemon; must
+// not block the stream.
+type ServiceAccessHook func(service string, peerID peer.ID)
+
+// ServiceRejectHook is called when an authorized peer's stream is refused
+// for exceeding the service's per-peer limits. reason is ServiceRejectConcurrency
+// or ServiceRejectRate. Injected by the daemon (audit); must not block.
+type ServiceRejectHook func(service string, peerID peer.ID, reason string)
+
 
 // ServiceRegistry manages service registration and connections.
 //
@@ -96,8 +146,15 @@ type ServiceRegistry struct {
 	tokenVerifier     TokenVerifier     // set once at startup; nil = no token verification (Phase B)
 	tokenLookup       TokenLookup       // set once at startup; nil = no token presentation (Phase B)
 	lanRegistry       *LANRegistry      // set once at startup; nil = LAN classification uses Direct fallback
+	verifiedChecker   VerifiedChecker   // set once at startup; nil = no peer counts as verified
+	accessHook        ServiceAccessHook // set once at startup; nil = no access notifications
+	rejectHook        ServiceRejectHook // set once at startup; nil = no limit notifications
 	sealed            int32             // atomic; 1 after Seal() - Set* panics if sealed
-	mu                sync.RWMutex      // protects services and middleware; NOT callbacks (set-once)
+	mu                sync.RWMutex      // protects services (including their RequireVerified, LocalNetwork and AllowedPeers), middleware; NOT callbacks (set-once)
+
+	inboundMu sync.Mutex                 // protects inbound
+	inbound   map[*inboundEntry]struct{} // active inbound streams (InboundStreams)
+	draining  atomic.Bool                // refuse new streams (SetDraining)
 }
 
 // NewServiceRegistry creates a new service registry.
@@ -124,6 +181,16 @@ func (r *ServiceRegistry) RegisterService(svc *Service) error {
 		return fmt.Errorf("service requires either local_address or handler")
 	}
 
+	if svc.MaxConcurrent < 0 || svc.RatePerMinute < 0 {
+		return fmt.Errorf("service limits must be >= 0")
+	}
+
+	switch svc.LocalNetwork {
+	case "", "tcp", "udp":
+	default:
+		return fmt.Errorf("unknown service network %q (want tcp or udp)", svc.LocalNetwork)
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -132,6 +199,11 @@ func (r *ServiceRegistry) RegisterService(svc *Service) error {
 		return fmt.Errorf("%w: %s", ErrServiceAlreadyRegistered, svc.Name)
 	}
 
+	// Build per-direction token buckets before the handler can see svc.
+	svc.ingressLimiter = newBandwidthLimiter(svc.MaxBandwidthMbps)
+	svc.egressLimiter = newBandwidthLimiter(svc.MaxBandwidthMbps)
+	svc.peerLimits = newPeerLimiter(svc.MaxConcurrent, svc.RatePerMinute)
+
 	// Register service
 	r.services[svc.Name] = svc
 
@@ -187,6 +259,12 @@ func (r *ServiceRegistry) handleServiceStreamInner(svc *Service, s network.Strea
 	short := remotePeer.String()[:16] + "..."
 	slog.Info("incoming connection", "path", tag, "service", svc.Name, "peer", short)
 
+	if r.draining.Load() {
+		slog.Info("refusing stream, daemon is draining", "service", svc.Name, "peer", short)
+		s.Reset()
+		return
+	}
+
 	// Phase B: read grant header on plugin services.
 	// The remote side (OpenPluginStream) always writes a header for plugin streams.
 	var presentedToken string
@@ -256,20 +334,54 @@ func (r *ServiceRegistry) handleServiceStreamInner(svc *Service, s network.Strea
 	}
 
 	// Per-service access control (legacy path for TCP proxies without Policy).
-	if svc.Policy == nil && svc.AllowedPeers != nil {
-		if _, ok := svc.AllowedPeers[remotePeer]; !ok {
-			slog.Warn("peer not in service ACL", "service", svc.Name, "peer", short)
-			s.Reset()
-			return
-		}
+	if svc.Policy == nil && !r.serviceAllowsPeer(svc, remotePeer) {
+		slog.Warn("peer not in service ACL", "service", svc.Name, "peer", short)
+		s.Reset()
+		return
+	}
+
+	// Verified-only services: authorization alone is not enough.
+	if r.RequiresVerified(svc.Name) && !r.peerVerified(remotePeer) {
+		slog.Warn("peer not verified, service requires verification",
+			"service", svc.Name, "peer", short,
+			"hint", "verify the peer with 'shurli verify <peer>'")
+		s.Reset()
+		return
 	}
 
+	// Per-peer stream budget. Checked after access control so only
+	// authorized peers consume slots; the slot is held until the stream ends.
+	release, reason := svc.peerLimits.acquire(remotePeer)
+	if reason != "" {
+		r.rejectOverLimit(svc, remotePeer, s, reason)
+		return
+	}
+	defer release()
+
+	if r.accessHook != nil {
+		r.accessHook(svc.Name, remotePeer)
+	}
+
+	s, done := r.trackInbound(svc.Name, remotePeer, s)
+	defer done()
+
 	// Custom handler path: delegate to the plugin's stream handler.
 	if svc.Handler != nil {
 		svc.Handler(svc.Name, s)
 		return
 	}
 
+	// UDP path: datagrams arrive framed on the stream.
+	if r.LocalNetwork(svc.Name) == "udp" {
+		if err := ProxyStreamToUDP(&serviceStream{stream: s}, svc.LocalAddress); err != nil {
+			slog.Error("failed to connect to local service", "service", svc.Name, "addr", svc.LocalAddress, "error", err)
+			s.Reset()
+			return
+		}
+		slog.Info("closed connection", "service", svc.Name, "peer", short)
+		return
+	}
+
 	// TCP proxy path: connect to local service and proxy bidirectionally.
 	localConn, err := net.DialTimeout("tcp", svc.LocalAddress, 10*time.Second)
 	if err != nil {
@@ -278,12 +390,33 @@ func (r *ServiceRegistry) handleServiceStreamInner(svc *Service, s network.Strea
 		return
 	}
 
-	// Bidirectional proxy with half-close propagation and optional metrics
-	InstrumentedBidirectionalProxy(&serviceStream{stream: s}, &tcpHalfCloser{localConn}, svc.Name, r.metrics)
+	// Bidirectional proxy with half-close propagation, optional bandwidth
+	// shaping, and optional metrics. Writes to the stream carry egress
+	// traffic; writes to the local conn carry ingress traffic.
+	InstrumentedBidirectionalProxy(
+		throttle(&serviceStream{stream: s}, svc.egressLimiter),
+		throttle(&tcpHalfCloser{localConn}, svc.ingressLimiter),
+		svc.Name, r.metrics)
 
 	slog.Info("closed connection", "service", svc.Name, "peer", short)
 }
 
+// rejectOverLimit refuses a stream that exceeds the peer's budget. The reset
+// carries a stream error code so the remote side can report the limit
+// instead of a bare reset.
+func (r *ServiceRegistry) rejectOverLimit(svc *Service, p peer.ID, s network.Stream, reason string) {
+	slog.Warn("service limit exceeded, stream refused",
+		"service", svc.Name, "peer", p.String()[:16]+"...", "reason", reason,
+		"max_concurrent", svc.MaxConcurrent, "rate_per_minute", svc.RatePerMinute)
+	if r.metrics != nil {
+		r.metrics.ServiceRejectedTotal.WithLabelValues(svc.Name, reason).Inc()
+	}
+	if r.rejectHook != nil {
+		r.rejectHook(svc.Name, p, reason)
+	}
+	s.ResetWithError(serviceRejectCode(reason))
+}
+
 // DialService connects to a remote peer's service.
 // If the protocol matches a locally registered service with a PluginPolicy,
 // the policy's transport restrictions are enforced.
@@ -421,6 +554,90 @@ func (r *ServiceRegistry) UnregisterService(name string) error {
 	return nil
 }
 
+// SetRequireVerified restricts a registered service to peers the verified
+// checker accepts (require=true), or lifts the restriction. Authorized but
+// unverified peers are refused, and the service-query listing tells them
+// why.
+func (r *ServiceRegistry) SetRequireVerified(name string, require bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, exists := r.services[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+	}
+	svc.RequireVerified = require
+	return nil
+}
+
+// SetLocalNetwork sets whether a registered service's LocalAddress is a
+// "tcp" (default) or "udp" socket. UDP services carry framed datagrams
+// (see ProxyStreamToUDP) and ignore the bandwidth cap.
+func (r *ServiceRegistry) SetLocalNetwork(name, network string) error {
+	switch network {
+	case "", "tcp", "udp":
+	default:
+		return fmt.Errorf("unknown service network %q (want tcp or udp)", network)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, exists := r.services[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+	}
+	svc.LocalNetwork = network
+	return nil
+}
+
+// LocalNetwork returns "udp" for a registered UDP service, otherwise "tcp".
+func (r *ServiceRegistry) LocalNetwork(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if svc, ok := r.services[name]; ok && svc.LocalNetwork == "udp" {
+		return "udp"
+	}
+	return "tcp"
+}
+
+// SetAllowedPeers replaces a registered service's ACL (nil = all authorized
+// peers) without re-registering it, so open streams survive. New streams
+// are checked against the new set.
+func (r *ServiceRegistry) SetAllowedPeers(name string, allowed map[peer.ID]struct{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, exists := r.services[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+	}
+	svc.AllowedPeers = allowed
+	return nil
+}
+
+// serviceAllowsPeer is svc.allowsPeer under mu, since SetAllowedPeers can
+// swap the ACL of a live service.
+func (r *ServiceRegistry) serviceAllowsPeer(svc *Service, p peer.ID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return svc.allowsPeer(p)
+}
+
+// RequiresVerified reports whether a service is restricted to verified peers.
+func (r *ServiceRegistry) RequiresVerified(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	svc, ok := r.services[name]
+	return ok && svc.RequireVerified
+}
+
+// peerVerified consults the verified checker. Without one, no peer is
+// verified, so verified-only services fail closed.
+func (r *ServiceRegistry) peerVerified(p peer.ID) bool {
+	return r.verifiedChecker != nil && r.verifiedChecker(p)
+}
+
 // GetService retrieves a registered service by name
 func (r *ServiceRegistry) GetService(name string) (*Service, bool) {
 	r.mu.RLock()
@@ -476,6 +693,40 @@ func (r *ServiceRegistry) SetTokenVerifier(v TokenVerifier) {
 	r.tokenVerifier = v
 }
 
+// SetVerifiedChecker sets the function that decides whether a peer counts
+// as verified for services marked with SetRequireVerified.
+// Must be called before Seal().
+func (r *ServiceRegistry) SetVerifiedChecker(c VerifiedChecker) {
+	if atomic.LoadInt32(&r.sealed) != 0 {
+		panic("ServiceRegistry: SetVerifiedChecker called after Seal()")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifiedChecker = c
+}
+
+// SetServiceAccessHook sets the function called for each inbound stream a
+// service accepts. Must be called before Seal().
+func (r *ServiceRegistry) SetServiceAccessHook(h ServiceAccessHook) {
+	if atomic.LoadInt32(&r.sealed) != 0 {
+		panic("ServiceRegistry: SetServiceAccessHook called after Seal()")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accessHook = h
+}
+
+// SetServiceRejectHook sets the function called for each inbound stream
+// refused by a service's per-peer limits. Must be called before Seal().
+func (r *ServiceRegistry) SetServiceRejectHook(h ServiceRejectHook) {
+	if atomic.LoadInt32(&r.sealed) != 0 {
+		panic("ServiceRegistry: SetServiceRejectHook called after Seal()")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejectHook = h
+}
+
 // SetTokenLookup sets the function used to retrieve grant tokens from the
 // GrantPouch for outbound plugin streams (Phase B).
 // Must be called before Seal().
@@ -543,3 +794,8 @@ func (s *serviceStream) Close() error {
 func (s *serviceStream) CloseWrite() error {
 	return s.stream.CloseWrite()
 }
+
+// Relayed reports whether the stream runs over a relay circuit.
+func (s *serviceStream) Relayed() bool {
+	return s.stream.Conn().Stat().Limited
+}
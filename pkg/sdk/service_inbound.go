@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// InboundStream is a snapshot of one active inbound service stream: a
+// remote peer using a service this node exposes.
+type InboundStream struct {
+	Service   string
+	PeerID    peer.ID
+	Relayed   bool
+	StartedAt time.Time
+	BytesIn   int64 // received from the remote peer
+	BytesOut  int64 // sent to the remote peer
+}
+
+// inboundEntry is the live record behind an InboundStream.
+type inboundEntry struct {
+	service   string
+	peer      peer.ID
+	relayed   bool
+	startedAt time.Time
+	bytesIn   atomic.Int64
+	bytesOut  atomic.Int64
+}
+
+// countedStream counts the bytes a service moves over an inbound stream.
+type countedStream struct {
+	network.Stream
+	entry *inboundEntry
+}
+
+func (s *countedStream) Read(p []byte) (int, error) {
+	n, err := s.Stream.Read(p)
+	s.entry.bytesIn.Add(int64(n))
+	return n, err
+}
+
+func (s *countedStream) Write(p []byte) (int, error) {
+	n, err := s.Stream.Write(p)
+	s.entry.bytesOut.Add(int64(n))
+	return n, err
+}
+
+// trackInbound records s as an active inbound stream to service until the
+// returned done func is called. The returned stream counts bytes and must
+// be used in place of s.
+func (r *ServiceRegistry) trackInbound(service string, p peer.ID, s network.Stream) (network.Stream, func()) {
+	e := &inboundEntry{
+		service:   service,
+		peer:      p,
+		relayed:   s.Conn().Stat().Limited,
+		startedAt: time.Now(),
+	}
+	r.inboundMu.Lock()
+	if r.inbound == nil {
+		r.inbound = make(map[*inboundEntry]struct{})
+	}
+	r.inbound[e] = struct{}{}
+	r.inboundMu.Unlock()
+
+	return &countedStream{Stream: s, entry: e}, func() {
+		r.inboundMu.Lock()
+		delete(r.inbound, e)
+		r.inboundMu.Unlock()
+	}
+}
+
+// SetDraining makes the registry refuse new service streams while the ones
+// already open run to completion. Used by a draining daemon shutdown.
+func (r *ServiceRegistry) SetDraining(draining bool) {
+	r.draining.Store(draining)
+}
+
+// ActiveInbound returns the number of inbound service streams open.
+func (r *ServiceRegistry) ActiveInbound() int {
+	r.inboundMu.Lock()
+	defer r.inboundMu.Unlock()
+	return len(r.inbound)
+}
+
+// InboundStreams returns the inbound service streams currently open,
+// oldest first. Plugin streams are listed until the plugin's handler
+// returns.
+func (r *ServiceRegistry) InboundStreams() []InboundStream {
+	r.inboundMu.Lock()
+	out := make([]InboundStream, 0, len(r.inbound))
+	for e := range r.inbound {
+		out = append(out, InboundStream{
+			Service:   e.service,
+			PeerID:    e.peer,
+			Relayed:   e.relayed,
+			StartedAt: e.startedAt,
+			BytesIn:   e.bytesIn.Load(),
+			BytesOut:  e.bytesOut.Load(),
+		})
+	}
+	r.inboundMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/time/rate"
+)
+
+// Reasons a service refuses a stream for exceeding a peer's budget. Used in
+// logs, the shurli_service_rejected_total metric and the reject hook.
+const (
+	ServiceRejectConcurrency = "max_concurrent"
+	ServiceRejectRate        = "rate_per_minute"
+)
+
+// ServiceLimits caps how an exposed service may be used. The stream limits
+// apply to each remote peer separately; MaxBandwidthMbps is shared by all
+// connections to the service. Zero disables a limit.
+type ServiceLimits struct {
+	MaxBandwidthMbps float64 // TCP proxy throughput per direction
+	MaxConcurrent    int     // open streams per peer
+	RatePerMinute    int     // new streams per peer per minute (bursts up to this many)
+}
+
+// peerLimiter enforces MaxConcurrent and RatePerMinute for one service.
+type peerLimiter struct {
+	maxConcurrent int
+	ratePerMinute int
+
+	mu    sync.Mutex
+	peers map[peer.ID]*peerBudget
+}
+
+// peerBudget is one remote peer's usage. An entry is dropped once the peer
+// has no open streams and a full rate bucket. Only authorized peers reach
+// the limiter, which bounds the map.
+type peerBudget struct {
+	active int
+	rate   *rate.Limiter // nil when RatePerMinute is 0
+}
+
+// newPeerLimiter returns nil when neither limit is set.
+func newPeerLimiter(maxConcurrent, ratePerMinute int) *peerLimiter {
+	if maxConcurrent <= 0 && ratePerMinute <= 0 {
+		return nil
+	}
+	return &peerLimiter{
+		maxConcurrent: maxConcurrent,
+		ratePerMinute: ratePerMinute,
+		peers:         make(map[peer.ID]*peerBudget),
+	}
+}
+
+// acquire reserves a stream slot for p. On success it returns a release
+// func that frees the slot (safe to call more than once) and an empty
+// reason; otherwise release is nil and reason says which limit was hit.
+// A nil limiter admits everything.
+func (l *peerLimiter) acquire(p peer.ID) (release func(), reason string) {
+	if l == nil {
+		return func() {}, ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.peers[p]
+	if !ok {
+		b = &peerBudget{}
+		if l.ratePerMinute > 0 {
+			b.rate = rate.NewLimiter(rate.Every(time.Minute/time.Duration(l.ratePerMinute)), l.ratePerMinute)
+		}
+		l.peers[p] = b
+	}
+
+	// Check concurrency first so a refused stream does not spend a token.
+	if l.maxConcurrent > 0 && b.active >= l.maxConcurrent {
+		return nil, ServiceRejectConcurrency
+	}
+	if b.rate != nil && !b.rate.Allow() {
+		return nil, ServiceRejectRate
+	}
+	b.active++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			b.active--
+			l.prune(p, b)
+		})
+	}, ""
+}
+
+// prune drops p's entry when it carries no state worth keeping.
+// Called with l.mu held.
+func (l *peerLimiter) prune(p peer.ID, b *peerBudget) {
+	if b.active > 0 {
+		return
+	}
+	if b.rate != nil && b.rate.Tokens() < float64(l.ratePerMinute) {
+		return
+	}
+	delete(l.peers, p)
+}
+
+// serviceRejectCode maps a reject reason to the stream error code sent to
+// the remote peer, so its side can tell a limit from a plain reset.
+func serviceRejectCode(reason string) network.StreamErrorCode {
+	if reason == ServiceRejectRate {
+		return network.StreamRateLimited
+	}
+	return network.StreamResourceLimitExceeded
+}
@@ -0,0 +1,174 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestPeerLimiterNil(t *testing.T) {
+	l := newPeerLimiter(0, 0)
+	if l != nil {
+		t.Fatal("no limits should give a nil limiter")
+	}
+	release, reason := l.acquire("a")
+	if reason != "" || release == nil {
+		t.Fatalf("nil limiter refused: %q", reason)
+	}
+	release()
+}
+
+func TestPeerLimiterConcurrencyPerPeer(t *testing.T) {
+	l := newPeerLimiter(2, 0)
+	a, b := peer.ID("peer-a"), peer.ID("peer-b")
+
+	r1, _ := l.acquire(a)
+	r2, _ := l.acquire(a)
+	if _, reason := l.acquire(a); reason != ServiceRejectConcurrency {
+		t.Fatalf("third stream for a: reason = %q, want %q", reason, ServiceRejectConcurrency)
+	}
+	// Another peer has its own budget.
+	rb, reason := l.acquire(b)
+	if reason != "" {
+		t.Fatalf("peer b refused: %q", reason)
+	}
+
+	// Releasing frees a slot; a double release must not free two.
+	r1()
+	r1()
+	r3, reason := l.acquire(a)
+	if reason != "" {
+		t.Fatalf("slot not freed: %q", reason)
+	}
+	if _, reason := l.acquire(a); reason != ServiceRejectConcurrency {
+		t.Fatalf("double release freed an extra slot: reason = %q", reason)
+	}
+
+	r2()
+	r3()
+	rb()
+	if len(l.peers) != 0 {
+		t.Errorf("%d idle entries left, want 0", len(l.peers))
+	}
+}
+
+func TestPeerLimiterRate(t *testing.T) {
+	l := newPeerLimiter(0, 3)
+	a, b := peer.ID("peer-a"), peer.ID("peer-b")
+
+	for i := range 3 {
+		release, reason := l.acquire(a)
+		if reason != "" {
+			t.Fatalf("stream %d refused within burst: %q", i, reason)
+		}
+		release()
+	}
+	if _, reason := l.acquire(a); reason != ServiceRejectRate {
+		t.Fatalf("reason = %q, want %q", reason, ServiceRejectRate)
+	}
+	if _, reason := l.acquire(b); reason != "" {
+		t.Fatalf("peer b refused: %q", reason)
+	}
+	// a's spent bucket is kept so the limit survives between streams.
+	if _, ok := l.peers[a]; !ok {
+		t.Error("rate-limited peer entry was dropped")
+	}
+}
+
+func TestServiceLimitRejectsStream(t *testing.T) {
+	serverHost := newRawTestHost(t)
+	clientHost := newRawTestHost(t)
+
+	const protoID = "/shurli/test-limit/1.0.0"
+	reg := NewServiceRegistry(serverHost, nil)
+	if err := reg.RegisterService(&Service{
+		Name:     "test-limit",
+		Protocol: protoID,
+		Handler: func(name string, s network.Stream) {
+			defer s.Close()
+			buf := make([]byte, 64)
+			for {
+				n, err := s.Read(buf)
+				if n > 0 {
+					_, _ = s.Write(buf[:n])
+				}
+				if err != nil {
+					return
+				}
+			}
+		},
+		MaxConcurrent: 1,
+	}); err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+	var rejected atomic.Int32
+	reg.SetServiceRejectHook(func(service string, p peer.ID, reason string) {
+		if service == "test-limit" && p == clientHost.ID() && reason == ServiceRejectConcurrency {
+			rejected.Add(1)
+		}
+	})
+	reg.Seal()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := clientHost.Connect(ctx, peer.AddrInfo{ID: serverHost.ID(), Addrs: serverHost.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	echo := func(s network.Stream) error {
+		if _, err := s.Write([]byte("hi")); err != nil {
+			return err
+		}
+		_ = s.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, err := io.ReadFull(s, make([]byte, 2))
+		return err
+	}
+
+	first, err := clientHost.NewStream(ctx, serverHost.ID(), protoID)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	if err := echo(first); err != nil {
+		t.Fatalf("first stream: %v", err)
+	}
+
+	second, err := clientHost.NewStream(ctx, serverHost.ID(), protoID)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	err = echo(second)
+	var se *network.StreamError
+	if !errors.As(err, &se) || !se.Remote || se.ErrorCode != network.StreamResourceLimitExceeded {
+		t.Fatalf("second stream: err = %v, want remote StreamResourceLimitExceeded", err)
+	}
+	if !errors.Is(classifyCopyError(err, nil, nil), ErrServiceLimitExceeded) {
+		t.Error("limit reset not classified as ErrServiceLimitExceeded")
+	}
+	if rejected.Load() != 1 {
+		t.Errorf("reject hook fired %d times, want 1", rejected.Load())
+	}
+
+	// Closing the first stream frees its slot.
+	first.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		third, err := clientHost.NewStream(ctx, serverHost.ID(), protoID)
+		if err != nil {
+			t.Fatalf("NewStream: %v", err)
+		}
+		err = echo(third)
+		third.Close()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slot not freed after close: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -3,11 +3,15 @@ package sdk
 import (
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
+
+	"github.com/shurlinet/shurli/internal/config"
 )
 
 // ServiceQueryProtocol is the protocol ID for querying a peer's services.
@@ -29,11 +33,21 @@ type RemoteServiceInfo struct {
 	Name     string `json:"name"`
 	Protocol string `json:"protocol"`
 	Enabled  bool   `json:"enabled"`
+
+	// RequiresVerification is set when the service is restricted to
+	// verified peers and the querying peer is not one, so it can report
+	// why a connection would be refused.
+	RequiresVerification bool `json:"requires_verification,omitempty"`
+
+	// Network is "udp" for services that forward to a local UDP socket;
+	// empty means TCP (or a custom handler).
+	Network string `json:"network,omitempty"`
 }
 
 // HandleServiceQuery returns a stream handler that responds with this node's
-// enabled services. Only service name and protocol are exposed. Local addresses
-// are never sent to remote peers.
+// enabled services that the querying peer is allowed to use (per-service ACL
+// and plugin peer policy). Only service name and protocol are exposed. Local
+// addresses are never sent to remote peers.
 func HandleServiceQuery(registry *ServiceRegistry) StreamHandler {
 	return func(serviceName string, s network.Stream) {
 		defer s.Close()
@@ -55,18 +69,25 @@ func HandleServiceQuery(registry *ServiceRegistry) StreamHandler {
 			return
 		}
 
-		// Collect enabled services.
+		// Collect enabled services the querying peer may connect to, so
+		// the listing never reveals services hidden from it by an ACL.
+		remotePeer := s.Conn().RemotePeer()
 		services := registry.ListServices()
 		var infos []RemoteServiceInfo
 		for _, svc := range services {
-			if !svc.Enabled {
+			if !svc.Enabled || !registry.serviceAllowsPeer(svc, remotePeer) {
 				continue
 			}
-			infos = append(infos, RemoteServiceInfo{
-				Name:     svc.Name,
-				Protocol: svc.Protocol,
-				Enabled:  true,
-			})
+			info := RemoteServiceInfo{
+				Name:                 svc.Name,
+				Protocol:             svc.Protocol,
+				Enabled:              true,
+				RequiresVerification: registry.RequiresVerified(svc.Name) && !registry.peerVerified(remotePeer),
+			}
+			if registry.LocalNetwork(svc.Name) == "udp" {
+				info.Network = "udp"
+			}
+			infos = append(infos, info)
 		}
 
 		data, err := json.Marshal(infos)
@@ -131,6 +152,31 @@ func QueryPeerServices(s network.Stream) ([]RemoteServiceInfo, error) {
 	return infos, nil
 }
 
+// FindRemoteService looks up ref (a service name, or "name:port" for one
+// port of a port-range service) among the services a peer advertised. When
+// it is missing, the error wraps ErrServiceNotFound and lists the services
+// the peer does offer. A service the peer restricts to verified peers that
+// it has not verified us for wraps ErrServiceRequiresVerification.
+func FindRemoteService(services []RemoteServiceInfo, ref string) (*RemoteServiceInfo, error) {
+	name := config.ResolveServiceRef(ref)
+	var available []string
+	for i, svc := range services {
+		if svc.Name == name {
+			if svc.RequiresVerification {
+				return nil, fmt.Errorf("%w: %q is only available to peers it has verified; compare fingerprints with 'shurli verify <peer>' on both sides", ErrServiceRequiresVerification, ref)
+			}
+			return &services[i], nil
+		}
+		if svc.Protocol != ServiceQueryProtocol {
+			available = append(available, svc.Name)
+		}
+	}
+	if len(available) == 0 {
+		return nil, fmt.Errorf("%w: %q (the peer offers no services to you)", ErrServiceNotFound, ref)
+	}
+	return nil, fmt.Errorf("%w: %q (available services: %s)", ErrServiceNotFound, ref, strings.Join(available, ", "))
+}
+
 func writeServiceQueryError(s network.Stream, msg string) {
 	data := []byte(msg)
 	var header [5]byte
@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestFindRemoteService(t *testing.T) {
+	services := []RemoteServiceInfo{
+		{Name: "service-query", Protocol: ServiceQueryProtocol, Enabled: true},
+		{Name: "ssh", Protocol: "/shurli/ssh/1.0.0", Enabled: true},
+		{Name: "app-8005", Protocol: "/shurli/app-8005/1.0.0", Enabled: true},
+	}
+
+	if svc, err := FindRemoteService(services, "ssh"); err != nil || svc.Protocol != "/shurli/ssh/1.0.0" {
+		t.Errorf("FindRemoteService(ssh) = %v, %v", svc, err)
+	}
+	if svc, err := FindRemoteService(services, "app:8005"); err != nil || svc.Name != "app-8005" {
+		t.Errorf("FindRemoteService(app:8005) = %v, %v", svc, err)
+	}
+
+	_, err := FindRemoteService(services, "sshd")
+	if !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected ErrServiceNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "available services: ssh, app-8005") {
+		t.Errorf("error should list available services, got %q", err)
+	}
+	if strings.Contains(err.Error(), "service-query") {
+		t.Errorf("error should not list the service-query protocol itself: %q", err)
+	}
+
+	locked := append([]RemoteServiceInfo(nil), services...)
+	locked[1].RequiresVerification = true
+	_, err = FindRemoteService(locked, "ssh")
+	if !errors.Is(err, ErrServiceRequiresVerification) || !strings.Contains(err.Error(), "shurli verify") {
+		t.Errorf("verified-only service: got %v", err)
+	}
+
+	_, err = FindRemoteService(services[:1], "ssh")
+	if !errors.Is(err, ErrServiceNotFound) || !strings.Contains(err.Error(), "offers no services") {
+		t.Errorf("empty listing: got %v", err)
+	}
+}
+
+// TestServiceQueryHidesACLDeniedServices verifies a peer's service listing
+// omits services whose ACL excludes the querying peer.
+func TestServiceQueryHidesACLDeniedServices(t *testing.T) {
+	serverHost := newRawTestHost(t)
+	clientHost := newRawTestHost(t)
+	reg := NewServiceRegistry(serverHost, nil)
+
+	someoneElse := peer.ID("someone-else")
+	for _, svc := range []*Service{
+		{Name: "ssh", Protocol: "/shurli/ssh/1.0.0", LocalAddress: "localhost:22", Enabled: true},
+		{Name: "private", Protocol: "/shurli/private/1.0.0", LocalAddress: "localhost:2222", Enabled: true,
+			AllowedPeers: map[peer.ID]struct{}{someoneElse: {}}},
+		{Name: "shared", Protocol: "/shurli/shared/1.0.0", LocalAddress: "localhost:8080", Enabled: true,
+			AllowedPeers: map[peer.ID]struct{}{clientHost.ID(): {}}},
+		{Name: "service-query", Protocol: ServiceQueryProtocol, Handler: HandleServiceQuery(reg), Enabled: true,
+			Policy: &PluginPolicy{AllowedTransports: TransportLAN | TransportDirect | TransportRelay}},
+	} {
+		if err := reg.RegisterService(svc); err != nil {
+			t.Fatalf("RegisterService(%s): %v", svc.Name, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := clientHost.Connect(ctx, peer.AddrInfo{ID: serverHost.ID(), Addrs: serverHost.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	s, err := clientHost.NewStream(ctx, serverHost.ID(), ServiceQueryProtocol)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	defer s.Close()
+	if err := WriteGrantHeader(s, ""); err != nil {
+		t.Fatalf("WriteGrantHeader: %v", err)
+	}
+
+	services, err := QueryPeerServices(s)
+	if err != nil {
+		t.Fatalf("QueryPeerServices: %v", err)
+	}
+	got := map[string]bool{}
+	for _, svc := range services {
+		got[svc.Name] = true
+	}
+	if !got["ssh"] || !got["shared"] {
+		t.Errorf("expected ssh and shared in listing, got %v", services)
+	}
+	if got["private"] {
+		t.Errorf("service denied by ACL must not be listed, got %v", services)
+	}
+
+	_, err = FindRemoteService(services, "private")
+	if !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("expected ACL-hidden service to be not found, got %v", err)
+	}
+}
+
+// TestServiceQueryFlagsVerifiedOnlyServices verifies a verified-only service
+// stays listed for an unverified peer but carries RequiresVerification, and
+// is listed plainly once the peer is verified.
+func TestServiceQueryFlagsVerifiedOnlyServices(t *testing.T) {
+	for _, verified := range []bool{false, true} {
+		serverHost := newRawTestHost(t)
+		clientHost := newRawTestHost(t)
+		reg := NewServiceRegistry(serverHost, nil)
+
+		for _, svc := range []*Service{
+			{Name: "ssh", Protocol: "/shurli/ssh/1.0.0", LocalAddress: "localhost:22", Enabled: true},
+			{Name: "service-query", Protocol: ServiceQueryProtocol, Handler: HandleServiceQuery(reg), Enabled: true,
+				Policy: &PluginPolicy{AllowedTransports: TransportLAN | TransportDirect | TransportRelay}},
+		} {
+			if err := reg.RegisterService(svc); err != nil {
+				t.Fatalf("RegisterService(%s): %v", svc.Name, err)
+			}
+		}
+		if err := reg.SetRequireVerified("ssh", true); err != nil {
+			t.Fatalf("SetRequireVerified: %v", err)
+		}
+		reg.SetVerifiedChecker(func(peer.ID) bool { return verified })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := clientHost.Connect(ctx, peer.AddrInfo{ID: serverHost.ID(), Addrs: serverHost.Addrs()}); err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		s, err := clientHost.NewStream(ctx, serverHost.ID(), ServiceQueryProtocol)
+		if err != nil {
+			t.Fatalf("NewStream: %v", err)
+		}
+		if err := WriteGrantHeader(s, ""); err != nil {
+			t.Fatalf("WriteGrantHeader: %v", err)
+		}
+		services, err := QueryPeerServices(s)
+		s.Close()
+		if err != nil {
+			t.Fatalf("QueryPeerServices: %v", err)
+		}
+
+		_, err = FindRemoteService(services, "ssh")
+		if verified && err != nil {
+			t.Errorf("verified peer: FindRemoteService(ssh) = %v", err)
+		}
+		if !verified && !errors.Is(err, ErrServiceRequiresVerification) {
+			t.Errorf("unverified peer: err = %v, want ErrServiceRequiresVerification", err)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package sdk
 import (
 	"context"
 	"errors"
+	"io"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -555,3 +557,217 @@ func TestPluginPolicyGrantDeniesWrongTransport(t *testing.T) {
 		t.Error("relay-only grant must not unlock LAN stream")
 	}
 }
+
+// TestRequireVerifiedService verifies a service registered with
+// RequireVerified runs for a peer the verified checker accepts and
+// refuses an authorized but unverified peer.
+func TestRequireVerifiedService(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		verified bool
+	}{
+		{"verified peer allowed", true},
+		{"unverified peer refused", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			serverHost := newRawTestHost(t)
+			clientHost := newRawTestHost(t)
+
+			const protoID = "/shurli/test-verified/1.0.0"
+			reg := NewServiceRegistry(serverHost, nil)
+			svc := &Service{
+				Name:     "test-verified",
+				Protocol: protoID,
+				Handler: func(name string, s network.Stream) {
+					defer s.Close()
+					_, _ = s.Write([]byte("ok"))
+				},
+				Policy:          &PluginPolicy{AllowedTransports: TransportLAN | TransportDirect | TransportRelay},
+				RequireVerified: true,
+			}
+			if err := reg.RegisterService(svc); err != nil {
+				t.Fatalf("RegisterService: %v", err)
+			}
+			reg.SetVerifiedChecker(func(pid peer.ID) bool {
+				return tc.verified && pid == clientHost.ID()
+			})
+			var accessed atomic.Int32
+			reg.SetServiceAccessHook(func(service string, pid peer.ID) {
+				if service == svc.Name && pid == clientHost.ID() {
+					accessed.Add(1)
+				}
+			})
+			reg.Seal()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := clientHost.Connect(ctx, peer.AddrInfo{ID: serverHost.ID(), Addrs: serverHost.Addrs()}); err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			s, err := clientHost.NewStream(ctx, serverHost.ID(), protoID)
+			if err != nil {
+				t.Fatalf("NewStream: %v", err)
+			}
+			defer s.Close()
+			if err := WriteGrantHeader(s, ""); err != nil {
+				t.Fatalf("WriteGrantHeader: %v", err)
+			}
+
+			buf := make([]byte, 2)
+			_ = s.SetReadDeadline(time.Now().Add(2 * time.Second))
+			n, _ := s.Read(buf)
+			got := n == 2 && string(buf[:n]) == "ok"
+			if got != tc.verified {
+				t.Errorf("handler ran = %v, want %v", got, tc.verified)
+			}
+			// The access hook fires only for streams the service accepts.
+			if n := accessed.Load(); (n == 1) != tc.verified {
+				t.Errorf("access hook fired %d times, want accepted=%v", n, tc.verified)
+			}
+		})
+	}
+}
+
+func TestSetRequireVerified(t *testing.T) {
+	reg := newTestHost(t)
+	if err := reg.SetRequireVerified("missing", true); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("unknown service: err = %v, want ErrServiceNotFound", err)
+	}
+
+	if err := reg.RegisterService(&Service{Name: "ssh", Protocol: "/shurli/ssh/1.0.0", LocalAddress: "localhost:22", Enabled: true}); err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+	if err := reg.SetRequireVerified("ssh", true); err != nil {
+		t.Fatalf("SetRequireVerified: %v", err)
+	}
+	if !reg.RequiresVerified("ssh") {
+		t.Error("ssh should require verification")
+	}
+
+	// Unregistering drops the flag so a later re-expose starts open.
+	if err := reg.UnregisterService("ssh"); err != nil {
+		t.Fatalf("UnregisterService: %v", err)
+	}
+	if reg.RequiresVerified("ssh") {
+		t.Error("flag should be cleared on unregister")
+	}
+}
+
+// TestRegisterServiceAppliesFlags verifies RequireVerified and LocalNetwork
+// are in place as soon as RegisterService returns.
+func TestRegisterServiceAppliesFlags(t *testing.T) {
+	reg := newTestHost(t)
+	if err := reg.RegisterService(&Service{Name: "dns", Protocol: "/shurli/dns/1.0.0", LocalAddress: "localhost:53", Enabled: true, RequireVerified: true, LocalNetwork: "udp"}); err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+	if !reg.RequiresVerified("dns") {
+		t.Error("dns should require verification")
+	}
+	if got := reg.LocalNetwork("dns"); got != "udp" {
+		t.Errorf("LocalNetwork = %q, want udp", got)
+	}
+
+	err := reg.RegisterService(&Service{Name: "bad", Protocol: "/shurli/bad/1.0.0", LocalAddress: "localhost:1", Enabled: true, LocalNetwork: "sctp"})
+	if err == nil {
+		t.Error("unknown network should be rejected")
+	}
+}
+
+func TestSetAllowedPeers(t *testing.T) {
+	reg := newTestHost(t)
+	if err := reg.SetAllowedPeers("missing", nil); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("unknown service: err = %v, want ErrServiceNotFound", err)
+	}
+
+	svc := &Service{Name: "ssh", Protocol: "/shurli/ssh/1.0.0", LocalAddress: "localhost:22", Enabled: true}
+	if err := reg.RegisterService(svc); err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+	a, b := peer.ID("peer-a"), peer.ID("peer-b")
+	if !reg.serviceAllowsPeer(svc, a) {
+		t.Error("nil ACL should allow every peer")
+	}
+
+	if err := reg.SetAllowedPeers("ssh", map[peer.ID]struct{}{a: {}}); err != nil {
+		t.Fatalf("SetAllowedPeers: %v", err)
+	}
+	if !reg.serviceAllowsPeer(svc, a) || reg.serviceAllowsPeer(svc, b) {
+		t.Error("ACL not applied to the registered service")
+	}
+
+	if err := reg.SetAllowedPeers("ssh", nil); err != nil {
+		t.Fatalf("SetAllowedPeers: %v", err)
+	}
+	if !reg.serviceAllowsPeer(svc, b) {
+		t.Error("clearing the ACL should allow every peer")
+	}
+}
+
+func TestInboundStreams(t *testing.T) {
+	serverHost := newRawTestHost(t)
+	clientHost := newRawTestHost(t)
+
+	const protoID = "/shurli/test-inbound/1.0.0"
+	reg := NewServiceRegistry(serverHost, nil)
+	if err := reg.RegisterService(&Service{
+		Name:     "test-inbound",
+		Protocol: protoID,
+		Handler: func(name string, s network.Stream) {
+			defer s.Close()
+			buf := make([]byte, 64)
+			for {
+				n, err := s.Read(buf)
+				if n > 0 {
+					_, _ = s.Write(buf[:n])
+				}
+				if err != nil {
+					return
+				}
+			}
+		},
+	}); err != nil {
+		t.Fatalf("RegisterService: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := clientHost.Connect(ctx, peer.AddrInfo{ID: serverHost.ID(), Addrs: serverHost.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	s, err := clientHost.NewStream(ctx, serverHost.ID(), protoID)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	if _, err := s.Write([]byte("hello")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	echo := make([]byte, 5)
+	if _, err := io.ReadFull(s, echo); err != nil {
+		t.Fatalf("read echo: %v", err)
+	}
+
+	active := reg.InboundStreams()
+	if len(active) != 1 {
+		t.Fatalf("got %d inbound streams, want 1", len(active))
+	}
+	got := active[0]
+	if got.Service != "test-inbound" || got.PeerID != clientHost.ID() || got.Relayed {
+		t.Errorf("inbound = %+v", got)
+	}
+	if got.BytesIn != 5 || got.BytesOut != 5 {
+		t.Errorf("bytes in/out = %d/%d, want 5/5", got.BytesIn, got.BytesOut)
+	}
+	if got.StartedAt.IsZero() {
+		t.Error("StartedAt not set")
+	}
+
+	// Closing the stream ends the handler and drops the entry.
+	s.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(reg.InboundStreams()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := len(reg.InboundStreams()); n != 0 {
+		t.Errorf("got %d inbound streams after close, want 0", n)
+	}
+}
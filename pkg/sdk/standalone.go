@@ -58,9 +58,10 @@ func NewStandaloneHost(cfg StandaloneConfig) (*StandaloneResult, error) {
 		Config:                &config.Config{Network: nodeCfg.Network},
 		UserAgent:             cfg.UserAgent,
 		Namespace:             nodeCfg.Discovery.Network,
-		EnableRelay:           true,
-		RelayAddrs:            nodeCfg.Relay.Addresses,
+		EnableRelay:           nodeCfg.Relay.IsEnabled(),
+		RelayAddrs:            nodeCfg.Relay.ActiveAddresses(),
 		ForcePrivate:          nodeCfg.Network.ForcePrivateReachability,
+		PreferQUICRelay:       nodeCfg.Relay.PreferQUIC,
 		EnableNATPortMap:      true,
 		EnableHolePunching:    true,
 		ResourceLimitsEnabled: true,
@@ -84,9 +85,16 @@ func NewStandaloneHost(cfg StandaloneConfig) (*StandaloneResult, error) {
 // and connects to the target. This consolidates the repeated boilerplate in
 // standalone CLI commands (ping, traceroute) into a single library call.
 //
+// A target written as a full multiaddr is dialed directly at that address,
+// skipping name resolution and the DHT.
+//
 // After this returns, the caller can immediately use the host to communicate
 // with the target peer. The caller is still responsible for closing the Network.
 func (r *StandaloneResult) ResolveAndConnect(ctx context.Context, target string) (peer.ID, error) {
+	if IsMultiaddrTarget(target) {
+		return r.Network.ConnectMultiaddrTarget(ctx, target)
+	}
+
 	targetPeerID, err := r.Network.ResolveName(target)
 	if err != nil {
 		return "", fmt.Errorf("cannot resolve %q: %w", target, err)
@@ -96,7 +104,7 @@ func (r *StandaloneResult) ResolveAndConnect(ctx context.Context, target string)
 	bootstrapCfg := BootstrapConfig{
 		Namespace:      cfg.Discovery.Network,
 		BootstrapPeers: cfg.Discovery.BootstrapPeers,
-		RelayAddrs:     cfg.Relay.Addresses,
+		RelayAddrs:     cfg.Relay.ActiveAddresses(),
 	}
 
 	if err := BootstrapAndConnect(ctx, r.Network.Host(), r.Network, targetPeerID, bootstrapCfg); err != nil {
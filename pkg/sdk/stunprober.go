@@ -114,6 +114,8 @@ func (sp *STUNProber) Probe(ctx context.Context) (*STUNResult, error) {
 				seen[r.ExternalAddr] = true
 				result.ExternalAddrs = append(result.ExternalAddrs, r.ExternalAddr)
 			}
+		} else {
+			slog.Warn("stun: server unreachable", "server", r.ServerAddr, "error", r.Error)
 		}
 	}
 
@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// IsMultiaddrTarget reports whether a ping or traceroute target is written
+// as a multiaddr (e.g. from "shurli whoami --addresses") rather than as a
+// name or peer ID.
+func IsMultiaddrTarget(target string) bool {
+	return strings.HasPrefix(target, "/")
+}
+
+// ParseMultiaddrTarget parses a multiaddr target ending in /p2p/<peer-id>.
+// Direct addresses and relay circuit addresses
+// (/.../p2p/<relay>/p2p-circuit/p2p/<peer>) are both accepted.
+func ParseMultiaddrTarget(target string) (*peer.AddrInfo, error) {
+	addr, err := ma.NewMultiaddr(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multiaddr %q: %w", target, err)
+	}
+	ai, err := peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return nil, fmt.Errorf("multiaddr %q must end in /p2p/<peer-id>: %w", target, err)
+	}
+	if len(ai.Addrs) == 0 {
+		return nil, fmt.Errorf("multiaddr %q has no address before /p2p/<peer-id>", target)
+	}
+	return ai, nil
+}
+
+// ConnectMultiaddrTarget dials a multiaddr target at the address it names,
+// bypassing name resolution and DHT lookup. The address is added to the
+// peerstore with a temporary TTL first. An existing connection to the peer
+// is reused rather than replaced.
+func (n *Network) ConnectMultiaddrTarget(ctx context.Context, target string) (peer.ID, error) {
+	ai, err := ParseMultiaddrTarget(target)
+	if err != nil {
+		return "", err
+	}
+	if ai.ID == n.host.ID() {
+		return "", fmt.Errorf("multiaddr %q is this node", target)
+	}
+	n.host.Peerstore().AddAddrs(ai.ID, ai.Addrs, peerstore.TempAddrTTL)
+	// Circuit addresses yield a limited connection; allow it for the dial.
+	dialCtx := network.WithAllowLimitedConn(ctx, "multiaddr-target")
+	if err := n.host.Connect(dialCtx, *ai); err != nil {
+		return "", fmt.Errorf("connect to %s: %w", target, err)
+	}
+	return ai.ID, nil
+}
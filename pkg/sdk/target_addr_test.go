@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseMultiaddrTarget(t *testing.T) {
+	const (
+		relay  = "12D3KooWRzaGMTqQbRHNMZkAYj8ALUXoK99qSjhiFLanDoVWK9An"
+		target = "12D3KooWPrmh163sTHW3mYQm7YsLsSR2wr71fPp4g6yjuGv3sGQt"
+	)
+	for _, tc := range []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"/ip6/2001:db8::1/udp/9100/quic-v1/p2p/" + target, false},
+		{"/ip4/203.0.113.50/tcp/7777/p2p/" + relay + "/p2p-circuit/p2p/" + target, false},
+		{"/ip4/203.0.113.50/tcp/7777", true},
+		{"/p2p/" + target, true},
+		{"/not/a/multiaddr", true},
+	} {
+		ai, err := ParseMultiaddrTarget(tc.addr)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseMultiaddrTarget(%q): err=%v, wantErr=%v", tc.addr, err, tc.wantErr)
+			continue
+		}
+		if err == nil && ai.ID.String() != target {
+			t.Errorf("ParseMultiaddrTarget(%q): ID = %s, want %s", tc.addr, ai.ID, target)
+		}
+	}
+
+	if !IsMultiaddrTarget("/ip4/1.2.3.4/tcp/1/p2p/"+target) || IsMultiaddrTarget("home-server") || IsMultiaddrTarget(target) {
+		t.Error("IsMultiaddrTarget misclassified a target")
+	}
+}
+
+// TestConnectMultiaddrTarget dials a peer by a full multiaddr that was never
+// added to the peerstore, as a DHT lookup or name would have done.
+func TestConnectMultiaddrTarget(t *testing.T) {
+	a := newListeningNetwork(t)
+	b := newListeningNetwork(t)
+	target := b.Host().Addrs()[0].String() + "/p2p/" + b.Host().ID().String()
+
+	if len(a.Host().Peerstore().Addrs(b.Host().ID())) != 0 {
+		t.Fatal("peerstore already knows the target")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	got, err := a.ConnectMultiaddrTarget(ctx, target)
+	if err != nil {
+		t.Fatalf("ConnectMultiaddrTarget: %v", err)
+	}
+	if got != b.Host().ID() {
+		t.Errorf("peer ID = %s, want %s", got, b.Host().ID())
+	}
+	if len(a.Host().Network().ConnsToPeer(b.Host().ID())) == 0 {
+		t.Error("not connected after ConnectMultiaddrTarget")
+	}
+
+	self := a.Host().Addrs()[0].String() + "/p2p/" + a.Host().ID().String()
+	if _, err := a.ConnectMultiaddrTarget(ctx, self); err == nil {
+		t.Error("expected error when the target is this node")
+	}
+}
@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// minThrottleBurst is the smallest token-bucket burst used for service
+// bandwidth limits. Below this, per-write overhead dominates at low rates.
+const minThrottleBurst = 4 << 10 // 4 KB
+
+// newBandwidthLimiter returns a token-bucket limiter for the given rate in
+// megabits per second, or nil when mbps <= 0 (unlimited). The burst is sized
+// to roughly 100ms of traffic so a fresh bucket can't front-load much more
+// than the configured rate.
+func newBandwidthLimiter(mbps float64) *rate.Limiter {
+	if mbps <= 0 {
+		return nil
+	}
+	bytesPerSec := mbps * 1_000_000 / 8
+	burst := int(bytesPerSec / 10)
+	if burst < minThrottleBurst {
+		burst = minThrottleBurst
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), burst)
+}
+
+// throttledConn wraps a HalfCloseConn so writes wait on a shared token
+// bucket. Every connection to a service shares the service's limiter, so the
+// cap applies to the service as a whole, not per connection. Closing the
+// conn cancels any write still waiting for tokens.
+type throttledConn struct {
+	HalfCloseConn
+	limiter *rate.Limiter
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func (t *throttledConn) Write(p []byte) (int, error) {
+	written := 0
+	burst := t.limiter.Burst()
+	for written < len(p) {
+		chunk := len(p) - written
+		if chunk > burst {
+			chunk = burst
+		}
+		if err := t.limiter.WaitN(t.ctx, chunk); err != nil {
+			return written, err
+		}
+		n, err := t.HalfCloseConn.Write(p[written : written+chunk])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (t *throttledConn) Close() error {
+	t.cancel()
+	return t.HalfCloseConn.Close()
+}
+
+// throttle wraps c with limiter, or returns c unchanged when limiter is nil.
+func throttle(c HalfCloseConn, limiter *rate.Limiter) HalfCloseConn {
+	if limiter == nil {
+		return c
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &throttledConn{HalfCloseConn: c, limiter: limiter, ctx: ctx, cancel: cancel}
+}
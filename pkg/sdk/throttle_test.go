@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// nopHalfCloser is an in-memory HalfCloseConn sink.
+type nopHalfCloser struct{ bytes.Buffer }
+
+func (n *nopHalfCloser) Close() error      { return nil }
+func (n *nopHalfCloser) CloseWrite() error { return nil }
+
+func TestNewBandwidthLimiter_Unlimited(t *testing.T) {
+	if l := newBandwidthLimiter(0); l != nil {
+		t.Error("0 Mbps should mean unlimited (nil limiter)")
+	}
+	c := &nopHalfCloser{}
+	if throttle(c, nil) != HalfCloseConn(c) {
+		t.Error("throttle with nil limiter should return the conn unchanged")
+	}
+}
+
+func TestThrottledConn_CapsThroughput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+	const mbps = 8.0 // 1 MB/s
+	limiter := newBandwidthLimiter(mbps)
+	sink := &nopHalfCloser{}
+	conn := throttle(sink, limiter)
+
+	// Write 500 KB in io.Copy-sized chunks. At 1 MB/s with a 100 KB burst,
+	// this must take at least ~400ms; an unthrottled write is instant.
+	const total = 500 << 10
+	buf := make([]byte, 32<<10)
+	start := time.Now()
+	for written := 0; written < total; {
+		n, err := conn.Write(buf)
+		if err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		written += n
+	}
+	elapsed := time.Since(start)
+
+	if sink.Len() < total {
+		t.Fatalf("sink got %d bytes, want >= %d", sink.Len(), total)
+	}
+	bytesPerSec := float64(sink.Len()) / elapsed.Seconds()
+	want := mbps * 1_000_000 / 8
+	// Allow the initial burst plus scheduling slack, but no large overshoot.
+	if bytesPerSec > want*1.3 {
+		t.Errorf("throughput %.0f B/s exceeds cap %.0f B/s by more than 30%%", bytesPerSec, want)
+	}
+	if elapsed < 350*time.Millisecond {
+		t.Errorf("elapsed %v, throttle did not slow writes", elapsed)
+	}
+}
+
+func TestThrottledConn_SplitsLargeWrites(t *testing.T) {
+	limiter := newBandwidthLimiter(100) // burst 1.25 MB
+	sink := &nopHalfCloser{}
+	conn := throttle(sink, limiter)
+
+	// A single write larger than the burst must not fail with
+	// "exceeds limiter's burst"; it is split into burst-sized chunks.
+	big := make([]byte, limiter.Burst()*2+17)
+	n, err := conn.Write(big)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n != len(big) {
+		t.Errorf("wrote %d, want %d", n, len(big))
+	}
+}
+
+func TestThrottledConn_CloseCancelsWait(t *testing.T) {
+	limiter := newBandwidthLimiter(0.01) // 1250 B/s, 4 KB burst
+	conn := throttle(&nopHalfCloser{}, limiter)
+	if _, err := conn.Write(make([]byte, limiter.Burst())); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// The bucket is empty, so the next write would wait seconds for tokens.
+	errc := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(make([]byte, limiter.Burst()))
+		errc <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	conn.Close()
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("Write after Close succeeded, want an error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write still waiting for tokens after Close")
+	}
+}
+
+func BenchmarkThrottledConn_Write(b *testing.B) {
+	conn := throttle(&nopHalfCloser{}, newBandwidthLimiter(10_000))
+	buf := make([]byte, 32<<10)
+	b.SetBytes(int64(len(buf)))
+	for b.Loop() {
+		conn.Write(buf)
+	}
+}
@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/transport"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"golang.org/x/net/proxy"
+)
+
+// torProxyCheckTimeout bounds the startup check that the configured Tor
+// SOCKS proxy is accepting connections.
+const torProxyCheckTimeout = 5 * time.Second
+
+// torTransport dials /onion3 addresses through a Tor SOCKS5 proxy. The
+// onion address is handed to Tor as a hostname, so it is resolved inside
+// the Tor network and never touches local DNS. It only dials: accepting
+// onion connections needs an onion service in torrc that forwards to one
+// of the node's TCP listen addresses.
+type torTransport struct {
+	upgrader transport.Upgrader
+	rcmgr    network.ResourceManager
+	socks    proxy.ContextDialer
+}
+
+// newTorTransportConstructor returns a libp2p transport constructor for
+// onion addresses that routes through the SOCKS5 proxy at socksAddr.
+func newTorTransportConstructor(socksAddr string) func(transport.Upgrader, network.ResourceManager) (*torTransport, error) {
+	return func(upgrader transport.Upgrader, rcmgr network.ResourceManager) (*torTransport, error) {
+		return newTorTransport(upgrader, rcmgr, socksAddr)
+	}
+}
+
+func newTorTransport(upgrader transport.Upgrader, rcmgr network.ResourceManager, socksAddr string) (*torTransport, error) {
+	d, err := proxy.SOCKS5("tcp", socksAddr, nil, &net.Dialer{})
+	if err != nil {
+		return nil, fmt.Errorf("tor: invalid SOCKS proxy %q: %w", socksAddr, err)
+	}
+	if rcmgr == nil {
+		rcmgr = &network.NullResourceManager{}
+	}
+	return &torTransport{
+		upgrader: upgrader,
+		rcmgr:    rcmgr,
+		socks:    d.(proxy.ContextDialer),
+	}, nil
+}
+
+// CheckTorProxy verifies that a Tor SOCKS proxy is accepting connections.
+func CheckTorProxy(socksAddr string) error {
+	conn, err := net.DialTimeout("tcp", socksAddr, torProxyCheckTimeout)
+	if err != nil {
+		return fmt.Errorf("tor SOCKS proxy %s is not reachable (is tor running?): %w", socksAddr, err)
+	}
+	conn.Close()
+	return nil
+}
+
+// onionDialTarget returns the host:port to request from the SOCKS proxy
+// for an /onion3 multiaddr, e.g. "vww6...yd.onion:1234".
+func onionDialTarget(addr ma.Multiaddr) (string, error) {
+	if len(addr) != 1 || addr[0].Code() != ma.P_ONION3 {
+		return "", fmt.Errorf("not an onion3 address: %s", addr)
+	}
+	host, port, ok := strings.Cut(addr[0].Value(), ":")
+	if !ok {
+		return "", fmt.Errorf("onion3 address without port: %s", addr)
+	}
+	return net.JoinHostPort(host+".onion", port), nil
+}
+
+// CanDial reports whether addr is a bare /onion3 address (the swarm strips
+// the trailing /p2p component before asking).
+func (t *torTransport) CanDial(addr ma.Multiaddr) bool {
+	_, err := onionDialTarget(addr)
+	return err == nil
+}
+
+// dialConn opens the raw stream through Tor, before any libp2p upgrade.
+func (t *torTransport) dialConn(ctx context.Context, raddr ma.Multiaddr) (manet.Conn, error) {
+	target, err := onionDialTarget(raddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := t.socks.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return nil, fmt.Errorf("tor: dial %s: %w", target, err)
+	}
+	laddr, err := manet.FromNetAddr(conn.LocalAddr())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &onionConn{Conn: conn, laddr: laddr, raddr: raddr}, nil
+}
+
+func (t *torTransport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (transport.CapableConn, error) {
+	connScope, err := t.rcmgr.OpenConnection(network.DirOutbound, true, raddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := connScope.SetPeer(p); err != nil {
+		connScope.Done()
+		return nil, err
+	}
+	conn, err := t.dialConn(ctx, raddr)
+	if err != nil {
+		connScope.Done()
+		return nil, err
+	}
+	c, err := t.upgrader.Upgrade(ctx, t, conn, network.DirOutbound, p, connScope)
+	if err != nil {
+		connScope.Done()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (t *torTransport) Listen(laddr ma.Multiaddr) (transport.Listener, error) {
+	return nil, fmt.Errorf("tor: cannot listen on %s; configure an onion service in torrc that forwards to a TCP listen address", laddr)
+}
+
+func (t *torTransport) Protocols() []int {
+	return []int{ma.P_ONION3}
+}
+
+// Proxy returns false: although Tor relays the bytes, the libp2p
+// connection is end to end with the peer, not a circuit through another
+// libp2p node.
+func (t *torTransport) Proxy() bool {
+	return false
+}
+
+func (t *torTransport) String() string {
+	return "Tor"
+}
+
+// onionConn reports the onion multiaddr as the remote end of a connection
+// that is physically to the local Tor proxy.
+type onionConn struct {
+	net.Conn
+	laddr, raddr ma.Multiaddr
+}
+
+func (c *onionConn) LocalMultiaddr() ma.Multiaddr  { return c.laddr }
+func (c *onionConn) RemoteMultiaddr() ma.Multiaddr { return c.raddr }
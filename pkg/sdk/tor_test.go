@@ -0,0 +1,188 @@
+package sdk
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+const testOnionHost = "vww6ybal4bd7szmgncyruucpgfkqahzddi37ktceo3ah7ngmcopnpyyd"
+
+// fakeSOCKS5 is a minimal SOCKS5 proxy standing in for tor. It records
+// each CONNECT target and forwards the stream to backend.
+type fakeSOCKS5 struct {
+	ln      net.Listener
+	backend string
+	targets chan string
+}
+
+func newFakeSOCKS5(t *testing.T, backend string) *fakeSOCKS5 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSOCKS5{ln: ln, backend: backend, targets: make(chan string, 16)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSOCKS5) serve(c net.Conn) {
+	defer c.Close()
+	// Greeting: version, method count, methods. Reply "no auth".
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(c, hdr); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(c, make([]byte, hdr[1])); err != nil {
+		return
+	}
+	c.Write([]byte{5, 0})
+
+	// CONNECT request with a domain name target.
+	req := make([]byte, 5)
+	if _, err := io.ReadFull(c, req); err != nil || req[1] != 1 || req[3] != 3 {
+		return
+	}
+	host := make([]byte, int(req[4])+2)
+	if _, err := io.ReadFull(c, host); err != nil {
+		return
+	}
+	port := binary.BigEndian.Uint16(host[len(host)-2:])
+	s.targets <- net.JoinHostPort(string(host[:len(host)-2]), strconv.Itoa(int(port)))
+
+	up, err := net.Dial("tcp", s.backend)
+	if err != nil {
+		c.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer up.Close()
+	c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	go io.Copy(up, c)
+	io.Copy(c, up)
+}
+
+func TestOnionDialTarget(t *testing.T) {
+	addr := ma.StringCast("/onion3/" + testOnionHost + ":1234")
+	got, err := onionDialTarget(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testOnionHost + ".onion:1234"; got != want {
+		t.Errorf("target = %q, want %q", got, want)
+	}
+
+	if _, err := onionDialTarget(ma.StringCast("/ip4/1.2.3.4/tcp/1234")); err == nil {
+		t.Error("expected error for a TCP address")
+	}
+}
+
+func TestTorTransportCanDial(t *testing.T) {
+	tr, err := newTorTransport(nil, nil, "127.0.0.1:9050")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tr.CanDial(ma.StringCast("/onion3/" + testOnionHost + ":1234")) {
+		t.Error("should dial onion3 addresses")
+	}
+	for _, s := range []string{"/ip4/1.2.3.4/tcp/1234", "/dns4/example.com/tcp/443/wss"} {
+		if tr.CanDial(ma.StringCast(s)) {
+			t.Errorf("should not dial %s", s)
+		}
+	}
+}
+
+func TestCheckTorProxyUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if err := CheckTorProxy(addr); err == nil {
+		t.Error("expected error for a closed proxy port")
+	}
+	_, err = New(&Config{
+		KeyFile: filepath.Join(t.TempDir(), "test.key"),
+		Config: &config.Config{Network: config.NetworkConfig{
+			ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"},
+			Tor:             config.TorConfig{SOCKSProxy: addr},
+		}},
+	})
+	if err == nil {
+		t.Error("New should fail when the Tor SOCKS proxy is unreachable")
+	}
+}
+
+// TestTorTransportRoutesThroughSOCKS connects to a peer by onion address
+// and checks the connection went through the configured SOCKS proxy with
+// the onion hostname as the target.
+func TestTorTransportRoutesThroughSOCKS(t *testing.T) {
+	target := newListeningNetwork(t)
+	var backend string
+	for _, a := range target.Host().Addrs() {
+		if _, err := a.ValueForProtocol(ma.P_TCP); err == nil {
+			if _, na, err := manet.DialArgs(a); err == nil {
+				backend = na
+				break
+			}
+		}
+	}
+	if backend == "" {
+		t.Fatal("target has no TCP listen address")
+	}
+	socks := newFakeSOCKS5(t, backend)
+
+	dialer, err := New(&Config{
+		KeyFile: filepath.Join(t.TempDir(), "test.key"),
+		Config: &config.Config{Network: config.NetworkConfig{
+			ListenAddresses: []string{"/ip4/127.0.0.1/tcp/0"},
+			Tor:             config.TorConfig{SOCKSProxy: socks.ln.Addr().String()},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create tor network: %v", err)
+	}
+	defer dialer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	onion := ma.StringCast("/onion3/" + testOnionHost + ":7777")
+	if err := dialer.Host().Connect(ctx, peer.AddrInfo{ID: target.Host().ID(), Addrs: []ma.Multiaddr{onion}}); err != nil {
+		t.Fatalf("connect via onion address: %v", err)
+	}
+
+	select {
+	case got := <-socks.targets:
+		if want := testOnionHost + ".onion:7777"; got != want {
+			t.Errorf("SOCKS target = %q, want %q", got, want)
+		}
+	default:
+		t.Fatal("connection did not go through the SOCKS proxy")
+	}
+	conns := dialer.Host().Network().ConnsToPeer(target.Host().ID())
+	if len(conns) == 0 || !conns[0].RemoteMultiaddr().Equal(onion) {
+		t.Errorf("expected a connection to %s, got %v", onion, conns)
+	}
+}
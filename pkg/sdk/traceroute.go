@@ -14,24 +14,105 @@ import (
 // RTTProbeProtocol is the protocol ID used for traceroute RTT measurement.
 const RTTProbeProtocol = "/shurli/rtt-probe/1.0.0"
 
+// Hop roles reported in TraceHop.Role.
+const (
+	HopRoleRelay  = "relay"
+	HopRoleTarget = "target"
+)
+
 // TraceHop represents a single hop in a P2P traceroute.
 type TraceHop struct {
-	Hop      int     `json:"hop"`
-	PeerID   string  `json:"peer_id"`
-	Name     string  `json:"name,omitempty"`     // friendly name if known
-	Address  string  `json:"address,omitempty"`   // multiaddr of this hop
-	RttMs    float64 `json:"rtt_ms"`
-	Error    string  `json:"error,omitempty"`
+	Hop       int     `json:"hop"`
+	PeerID    string  `json:"peer_id"`
+	Role      string  `json:"role"`                 // HopRoleRelay or HopRoleTarget
+	Name      string  `json:"name,omitempty"`       // friendly name if known
+	Address   string  `json:"address,omitempty"`    // multiaddr of this hop
+	RttMs     float64 `json:"rtt_ms"`               // end-to-end RTT from us to this hop
+	SegmentMs float64 `json:"segment_ms,omitempty"` // RTT added by this hop over the previous one
+	Error     string  `json:"error,omitempty"`
 }
 
-// TraceResult holds the full traceroute output.
+// Label returns the parenthesised annotation printed after the hop's peer
+// ID: the relay role and/or the hop's name. Empty when there is neither.
+func (h TraceHop) Label() string {
+	switch {
+	case h.Role == HopRoleRelay && h.Name != "":
+		return " (relay, " + h.Name + ")"
+	case h.Role == HopRoleRelay:
+		return " (relay)"
+	case h.Name != "":
+		return " (" + h.Name + ")"
+	}
+	return ""
+}
+
+// SegmentNote returns "(+Xms after hop N)" for a hop past the first whose
+// segment was measured, and "" otherwise.
+func (h TraceHop) SegmentNote() string {
+	if h.Hop <= 1 || h.SegmentMs <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("(+%.1fms after hop %d)", h.SegmentMs, h.Hop-1)
+}
+
+// TraceResult holds the full traceroute output. Hops is ordered from the
+// local node outwards; a relayed path has one relay hop per circuit today,
+// but nothing here assumes there is only one.
 type TraceResult struct {
 	Target   string     `json:"target"`
 	TargetID string     `json:"target_id"`
-	Path     string     `json:"path"`   // "DIRECT" or "RELAYED"
+	Path     string     `json:"path"` // "DIRECT" or "RELAYED"
 	Hops     []TraceHop `json:"hops"`
 }
 
+// PathSummary returns the path for display: "DIRECT", or "RELAYED via"
+// followed by the relay hops' names (or short peer IDs when unnamed).
+func (r *TraceResult) PathSummary() string {
+	var relays []string
+	for _, hop := range r.Hops {
+		if hop.Role != HopRoleRelay {
+			continue
+		}
+		switch {
+		case hop.Name != "":
+			relays = append(relays, hop.Name)
+		case len(hop.PeerID) > 16:
+			relays = append(relays, hop.PeerID[:16]+"...")
+		case hop.PeerID != "":
+			relays = append(relays, hop.PeerID)
+		default:
+			relays = append(relays, "unknown relay")
+		}
+	}
+	if len(relays) == 0 {
+		return r.Path
+	}
+	return r.Path + " via " + strings.Join(relays, ", ")
+}
+
+// fillSegments sets SegmentMs on each hop: the first hop's segment is its
+// own RTT, later hops get the latency they add over the hop before. Hops
+// whose RTT (or whose predecessor's RTT) was not measured are left empty,
+// as is a negative difference, which only means jitter won the race.
+func fillSegments(hops []TraceHop) {
+	for i := range hops {
+		if hops[i].Error != "" || hops[i].RttMs <= 0 {
+			continue
+		}
+		if i == 0 {
+			hops[i].SegmentMs = hops[i].RttMs
+			continue
+		}
+		prev := hops[i-1]
+		if prev.Error != "" || prev.RttMs <= 0 {
+			continue
+		}
+		if d := hops[i].RttMs - prev.RttMs; d > 0 {
+			hops[i].SegmentMs = d
+		}
+	}
+}
+
 // TracePeer traces the network path to a peer.
 //
 // libp2p doesn't support TTL-based tracing, so this determines the path
@@ -55,30 +136,21 @@ func TracePeer(ctx context.Context, h host.Host, targetPeerID peer.ID) (*TraceRe
 	var connAddr string
 
 	for _, conn := range conns {
-		addr := conn.RemoteMultiaddr().String()
-		if !conn.Stat().Limited && !strings.Contains(addr, "/p2p-circuit") {
+		addr := conn.RemoteMultiaddr()
+		if !conn.Stat().Limited && ClassifyAddr(addr) != AddrCircuit {
 			// Direct connection found
-			connAddr = addr
+			connAddr = addr.String()
 			isRelayed = false
 			break
 		}
 		// Relayed connection
 		isRelayed = true
-		connAddr = addr
+		connAddr = addr.String()
 
 		// Extract relay peer ID from the circuit address
 		// Format: /ip4/.../tcp/.../p2p/<relay-id>/p2p-circuit/p2p/<target-id>
-		parts := strings.Split(addr, "/p2p-circuit")
-		if len(parts) >= 1 {
-			relayAddr := parts[0]
-			// Find the /p2p/<id> component in the relay part
-			p2pIdx := strings.LastIndex(relayAddr, "/p2p/")
-			if p2pIdx >= 0 {
-				relayIDStr := relayAddr[p2pIdx+5:]
-				if pid, err := peer.Decode(relayIDStr); err == nil {
-					relayPeerID = pid
-				}
-			}
+		if pid := RelayPeerFromAddr(addr); pid != "" {
+			relayPeerID = pid
 		}
 	}
 
@@ -87,10 +159,20 @@ func TracePeer(ctx context.Context, h host.Host, targetPeerID peer.ID) (*TraceRe
 		hopNum := 1
 
 		// Hop 1: Relay server
-		if relayPeerID != "" {
+		if relayPeerID == "" {
+			// A limited connection whose address doesn't name the relay.
+			// Keep the hop so the output still shows traffic is relayed.
+			result.Hops = append(result.Hops, TraceHop{
+				Hop:   hopNum,
+				Role:  HopRoleRelay,
+				Error: "relay identity unknown",
+			})
+			hopNum++
+		} else {
 			relayHop := TraceHop{
 				Hop:    hopNum,
 				PeerID: relayPeerID.String(),
+				Role:   HopRoleRelay,
 			}
 
 			// Measure RTT to relay
@@ -104,9 +186,8 @@ func TracePeer(ctx context.Context, h host.Host, targetPeerID peer.ID) (*TraceRe
 			// Get relay address (non-circuit part)
 			relayConns := h.Network().ConnsToPeer(relayPeerID)
 			for _, rc := range relayConns {
-				addr := rc.RemoteMultiaddr().String()
-				if !strings.Contains(addr, "/p2p-circuit") {
-					relayHop.Address = addr
+				if addr := rc.RemoteMultiaddr(); ClassifyAddr(addr) != AddrCircuit {
+					relayHop.Address = addr.String()
 					break
 				}
 			}
@@ -126,6 +207,7 @@ func TracePeer(ctx context.Context, h host.Host, targetPeerID peer.ID) (*TraceRe
 		targetHop := TraceHop{
 			Hop:     hopNum,
 			PeerID:  targetPeerID.String(),
+			Role:    HopRoleTarget,
 			Address: connAddr,
 		}
 
@@ -144,6 +226,7 @@ func TracePeer(ctx context.Context, h host.Host, targetPeerID peer.ID) (*TraceRe
 		targetHop := TraceHop{
 			Hop:     1,
 			PeerID:  targetPeerID.String(),
+			Role:    HopRoleTarget,
 			Address: connAddr,
 		}
 
@@ -157,6 +240,7 @@ func TracePeer(ctx context.Context, h host.Host, targetPeerID peer.ID) (*TraceRe
 		result.Hops = append(result.Hops, targetHop)
 	}
 
+	fillSegments(result.Hops)
 	return result, nil
 }
 
@@ -0,0 +1,223 @@
+package sdk
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// maxHolePunchPeers caps the peers holePunchCounter keeps per-peer outcomes
+// for. The peer with the oldest attempt is dropped first.
+const maxHolePunchPeers = 512
+
+// minHolePunchesForVerdict is how many DCUtR attempts must have failed,
+// with none succeeding, before observed outcomes override the STUN-based
+// prediction. A single failure is usually the remote peer's NAT, not ours.
+const minHolePunchesForVerdict = 3
+
+// TraversalVerdict classifies how this node's connections actually travel.
+type TraversalVerdict string
+
+const (
+	TraversalDirect    TraversalVerdict = "direct"     // no NAT: peers dial us directly
+	TraversalHolePunch TraversalVerdict = "hole-punch" // hole punching expected or observed to work
+	TraversalUncertain TraversalVerdict = "uncertain"  // hole punching works only with some peers
+	TraversalRelay     TraversalVerdict = "relay"      // connections will stay on relay
+	TraversalUnknown   TraversalVerdict = "unknown"    // not enough information yet
+)
+
+// HolePunchStats counts the DCUtR hole punch outcomes seen by this node.
+type HolePunchStats struct {
+	Attempts  int `json:"attempts"`
+	Successes int `json:"successes"`
+}
+
+// HolePunchPeerStats is the DCUtR hole punch record for one remote peer.
+// A peer with attempts but no successes has never been upgraded from a
+// relayed to a direct connection.
+type HolePunchPeerStats struct {
+	PeerID      string    `json:"peer_id"`
+	Attempts    int       `json:"attempts"`
+	Successes   int       `json:"successes"`
+	LastAttempt time.Time `json:"last_attempt"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	LastError   string    `json:"last_error,omitempty"` // error of the most recent failure
+}
+
+// Failures is the number of attempts that did not succeed.
+func (s HolePunchPeerStats) Failures() int {
+	return s.Attempts - s.Successes
+}
+
+// TraversalAssessment combines the STUN NAT classification with real hole
+// punch outcomes into an explanation of why connections are direct or
+// relayed.
+type TraversalAssessment struct {
+	Verdict     TraversalVerdict `json:"verdict"`
+	NATType     NATType          `json:"nat_type,omitempty"`
+	BehindCGNAT bool             `json:"behind_cgnat,omitempty"`
+	HolePunches HolePunchStats   `json:"hole_punches"`
+	Summary     string           `json:"summary"`
+}
+
+// AssessTraversal classifies this node's NAT traversal capability. stun may
+// be nil (probe pending or failed). Observed hole punch outcomes take
+// precedence over the STUN prediction: a success proves direct paths are
+// possible, and repeated failures prove they are not, whatever STUN says.
+func AssessTraversal(stun *STUNResult, hp HolePunchStats) TraversalAssessment {
+	a := TraversalAssessment{HolePunches: hp}
+	natDesc := "NAT type unknown"
+	if stun != nil {
+		a.NATType = stun.NATType
+		a.BehindCGNAT = stun.BehindCGNAT
+		switch {
+		case stun.BehindCGNAT:
+			natDesc = "Carrier-grade NAT (CGNAT) detected"
+		case stun.NATType != NATUnknown && stun.NATType != "":
+			natDesc = natTypeLabel(stun.NATType) + " detected"
+		}
+	}
+
+	if hp.Successes > 0 {
+		a.Verdict = TraversalHolePunch
+		a.Summary = fmt.Sprintf("%s; hole punching succeeded in %d of %d attempts, direct connections are possible.",
+			natDesc, hp.Successes, hp.Attempts)
+		return a
+	}
+	if hp.Attempts >= minHolePunchesForVerdict {
+		a.Verdict = TraversalRelay
+		a.Summary = fmt.Sprintf("%s; hole punching failed in all %d attempts, relay will be used.", natDesc, hp.Attempts)
+		return a
+	}
+
+	switch {
+	case stun == nil:
+		a.Verdict = TraversalUnknown
+		a.Summary = "NAT type not yet known (STUN probe pending or failed); relay will be used if hole punching fails."
+	case stun.BehindCGNAT:
+		a.Verdict = TraversalRelay
+		a.Summary = natDesc + "; hole punching unlikely, relay will be used."
+	case stun.NATType == NATNone:
+		a.Verdict = TraversalDirect
+		a.Summary = "No NAT detected; peers can connect directly."
+	case stun.NATType == NATFullCone || stun.NATType == NATAddressRestricted:
+		a.Verdict = TraversalHolePunch
+		a.Summary = natDesc + "; hole punching should succeed."
+	case stun.NATType == NATPortRestricted:
+		a.Verdict = TraversalUncertain
+		a.Summary = natDesc + "; hole punching works only with peers on easier NATs, otherwise relay will be used."
+	case stun.NATType == NATSymmetric:
+		a.Verdict = TraversalRelay
+		a.Summary = natDesc + "; hole punching unlikely, relay will be used."
+	default:
+		a.Verdict = TraversalUnknown
+		a.Summary = "NAT type could not be determined; relay will be used if hole punching fails."
+	}
+	return a
+}
+
+// natTypeLabel renders a NAT type for sentences ("Symmetric NAT").
+func natTypeLabel(t NATType) string {
+	switch t {
+	case NATNone:
+		return "No NAT"
+	case NATFullCone:
+		return "Full-cone NAT"
+	case NATAddressRestricted:
+		return "Address-restricted NAT"
+	case NATPortRestricted:
+		return "Port-restricted NAT"
+	case NATSymmetric:
+		return "Symmetric NAT"
+	}
+	return string(t) + " NAT"
+}
+
+// holePunchCounter accumulates hole punch outcomes reported by the DCUtR
+// tracer, in total and per peer. Safe for concurrent use.
+type holePunchCounter struct {
+	mu    sync.Mutex
+	stats HolePunchStats
+	peers map[peer.ID]*HolePunchPeerStats
+}
+
+func (c *holePunchCounter) record(p peer.ID, success bool, errMsg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Attempts++
+	if success {
+		c.stats.Successes++
+	}
+
+	if c.peers == nil {
+		c.peers = make(map[peer.ID]*HolePunchPeerStats)
+	}
+	ps, ok := c.peers[p]
+	if !ok {
+		if len(c.peers) >= maxHolePunchPeers {
+			c.evictOldestLocked()
+		}
+		ps = &HolePunchPeerStats{PeerID: p.String()}
+		c.peers[p] = ps
+	}
+	now := time.Now()
+	ps.Attempts++
+	ps.LastAttempt = now
+	if success {
+		ps.Successes++
+		ps.LastSuccess = now
+	} else {
+		ps.LastError = errMsg
+	}
+}
+
+func (c *holePunchCounter) evictOldestLocked() {
+	var oldest peer.ID
+	var oldestAt time.Time
+	for id, ps := range c.peers {
+		if oldestAt.IsZero() || ps.LastAttempt.Before(oldestAt) {
+			oldest, oldestAt = id, ps.LastAttempt
+		}
+	}
+	delete(c.peers, oldest)
+}
+
+// peerSnapshot returns copies of the per-peer records, most recent
+// attempt first.
+func (c *holePunchCounter) peerSnapshot() []HolePunchPeerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]HolePunchPeerStats, 0, len(c.peers))
+	for _, ps := range c.peers {
+		out = append(out, *ps)
+	}
+	slices.SortFunc(out, func(a, b HolePunchPeerStats) int {
+		return cmp.Or(b.LastAttempt.Compare(a.LastAttempt), cmp.Compare(a.PeerID, b.PeerID))
+	})
+	return out
+}
+
+// peerOutcomes counts the peers that were upgraded to direct at least once
+// and those whose every attempt failed.
+func (c *holePunchCounter) peerOutcomes() (upgraded, failing int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ps := range c.peers {
+		if ps.Successes > 0 {
+			upgraded++
+		} else {
+			failing++
+		}
+	}
+	return upgraded, failing
+}
+
+func (c *holePunchCounter) snapshot() HolePunchStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
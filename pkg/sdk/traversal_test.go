@@ -0,0 +1,109 @@
+package sdk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestAssessTraversal_NATTypes(t *testing.T) {
+	tests := []struct {
+		stun        *STUNResult
+		want        TraversalVerdict
+		wantSummary string
+	}{
+		{nil, TraversalUnknown, "not yet known"},
+		{&STUNResult{NATType: NATNone}, TraversalDirect, "No NAT detected"},
+		{&STUNResult{NATType: NATFullCone}, TraversalHolePunch, "Full-cone NAT detected; hole punching should succeed"},
+		{&STUNResult{NATType: NATAddressRestricted}, TraversalHolePunch, "should succeed"},
+		{&STUNResult{NATType: NATPortRestricted}, TraversalUncertain, "easier NATs"},
+		{&STUNResult{NATType: NATSymmetric}, TraversalRelay, "Symmetric NAT detected; hole punching unlikely, relay will be used."},
+		{&STUNResult{NATType: NATUnknown}, TraversalUnknown, "could not be determined"},
+		// CGNAT caps the verdict whatever the inner NAT looks like.
+		{&STUNResult{NATType: NATFullCone, BehindCGNAT: true}, TraversalRelay, "CGNAT"},
+	}
+	for _, tt := range tests {
+		a := AssessTraversal(tt.stun, HolePunchStats{})
+		if a.Verdict != tt.want {
+			t.Errorf("AssessTraversal(%+v) verdict = %s, want %s", tt.stun, a.Verdict, tt.want)
+		}
+		if !strings.Contains(a.Summary, tt.wantSummary) {
+			t.Errorf("AssessTraversal(%+v) summary = %q, want it to contain %q", tt.stun, a.Summary, tt.wantSummary)
+		}
+	}
+}
+
+func TestAssessTraversal_ObservedOutcomesWin(t *testing.T) {
+	// A success proves direct paths work, even behind symmetric NAT.
+	a := AssessTraversal(&STUNResult{NATType: NATSymmetric}, HolePunchStats{Attempts: 4, Successes: 1})
+	if a.Verdict != TraversalHolePunch {
+		t.Errorf("verdict = %s, want %s", a.Verdict, TraversalHolePunch)
+	}
+	if !strings.Contains(a.Summary, "1 of 4") {
+		t.Errorf("summary = %q, want attempt counts", a.Summary)
+	}
+
+	// Repeated failures override an optimistic STUN classification.
+	a = AssessTraversal(&STUNResult{NATType: NATFullCone}, HolePunchStats{Attempts: minHolePunchesForVerdict})
+	if a.Verdict != TraversalRelay {
+		t.Errorf("verdict = %s, want %s", a.Verdict, TraversalRelay)
+	}
+
+	// A single failure is not enough evidence.
+	a = AssessTraversal(&STUNResult{NATType: NATFullCone}, HolePunchStats{Attempts: 1})
+	if a.Verdict != TraversalHolePunch {
+		t.Errorf("verdict after one failure = %s, want %s", a.Verdict, TraversalHolePunch)
+	}
+}
+
+func TestHolePunchCounter(t *testing.T) {
+	var c holePunchCounter
+	a, b := peer.ID("peer-a"), peer.ID("peer-b")
+	c.record(a, false, "all dials failed")
+	c.record(a, true, "")
+	c.record(b, false, "no good addresses")
+	if got := c.snapshot(); got != (HolePunchStats{Attempts: 3, Successes: 1}) {
+		t.Errorf("snapshot = %+v", got)
+	}
+
+	peers := c.peerSnapshot()
+	if len(peers) != 2 {
+		t.Fatalf("peerSnapshot = %+v, want 2 peers", peers)
+	}
+	byID := map[string]HolePunchPeerStats{}
+	for _, ps := range peers {
+		byID[ps.PeerID] = ps
+	}
+	pa := byID[a.String()]
+	if pa.Attempts != 2 || pa.Successes != 1 || pa.Failures() != 1 || pa.LastSuccess.IsZero() {
+		t.Errorf("peer a = %+v", pa)
+	}
+	if pa.LastError != "all dials failed" {
+		t.Errorf("peer a LastError = %q, want the earlier failure kept", pa.LastError)
+	}
+	pb := byID[b.String()]
+	if pb.Successes != 0 || pb.LastError != "no good addresses" || !pb.LastSuccess.IsZero() {
+		t.Errorf("peer b = %+v", pb)
+	}
+	if up, failing := c.peerOutcomes(); up != 1 || failing != 1 {
+		t.Errorf("peerOutcomes = %d, %d, want 1, 1", up, failing)
+	}
+}
+
+func TestHolePunchCounterEvictsOldest(t *testing.T) {
+	var c holePunchCounter
+	for i := range maxHolePunchPeers {
+		c.record(peer.ID(fmt.Sprintf("peer-%d", i)), false, "")
+	}
+	c.peers[peer.ID("peer-0")].LastAttempt = time.Now().Add(-time.Hour)
+	c.record(peer.ID("one-more"), false, "")
+	if n := len(c.peerSnapshot()); n != maxHolePunchPeers {
+		t.Fatalf("tracking %d peers, want %d", n, maxHolePunchPeers)
+	}
+	if _, ok := c.peers[peer.ID("peer-0")]; ok {
+		t.Error("oldest peer was not evicted")
+	}
+}
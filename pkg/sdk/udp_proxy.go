@@ -0,0 +1,368 @@
+package sdk
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// UDP services ride on a libp2p stream as length-prefixed datagrams: a
+// 2-byte big-endian length followed by the payload. One stream carries one
+// UDP flow (one client source address).
+
+// maxDatagramSize is the largest datagram the framing can carry.
+const maxDatagramSize = 65535
+
+// UDPSessionIdleTimeout closes a UDP flow after this long without a
+// datagram in either direction. UDP has no close, so idle flows would
+// otherwise hold their stream forever.
+const UDPSessionIdleTimeout = 2 * time.Minute
+
+// writeDatagram frames p onto w in a single write.
+func writeDatagram(w io.Writer, p []byte) error {
+	if len(p) > maxDatagramSize {
+		return fmt.Errorf("datagram of %d bytes exceeds %d", len(p), maxDatagramSize)
+	}
+	frame := make([]byte, 2+len(p))
+	binary.BigEndian.PutUint16(frame, uint16(len(p)))
+	copy(frame[2:], p)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readDatagram reads one framed datagram from r into buf, which must hold
+// maxDatagramSize bytes, and returns its length.
+func readDatagram(r io.Reader, buf []byte) (int, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, err
+	}
+	n := int(binary.BigEndian.Uint16(hdr[:]))
+	if _, err := io.ReadFull(r, buf[:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// activity records when a UDP flow last carried a datagram.
+type activity struct{ last atomic.Int64 }
+
+func (a *activity) touch()                 { a.last.Store(time.Now().UnixNano()) }
+func (a *activity) idleFor() time.Duration { return time.Since(time.Unix(0, a.last.Load())) }
+
+// ProxyStreamToUDP forwards datagrams between a stream and the local UDP
+// service at udpAddr until either side fails or the flow has been idle
+// for UDPSessionIdleTimeout, then closes the stream. If the UDP socket
+// cannot be opened the stream is left to the caller.
+func ProxyStreamToUDP(stream io.ReadWriteCloser, udpAddr string) error {
+	conn, err := net.Dial("udp", udpAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer stream.Close()
+
+	var act activity
+	act.touch()
+
+	// stream → UDP service
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		defer conn.Close() // unblock the UDP read below
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, err := readDatagram(stream, buf)
+			if err != nil {
+				return
+			}
+			act.touch()
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+
+	// UDP service → stream. The read deadline only wakes the loop to
+	// check for idleness; traffic in the other direction keeps it alive.
+	buf := make([]byte, maxDatagramSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(UDPSessionIdleTimeout))
+		n, err := conn.Read(buf)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() && act.idleFor() < UDPSessionIdleTimeout {
+				continue
+			}
+			break
+		}
+		act.touch()
+		if err := writeDatagram(stream, buf[:n]); err != nil {
+			break
+		}
+	}
+	stream.Close()
+	<-streamDone
+	return nil
+}
+
+// udpSessionQueue is how many datagrams a flow buffers while its stream
+// is being dialed or is slow to drain. Further datagrams are dropped, as a
+// congested UDP path would.
+const udpSessionQueue = 64
+
+// UDPListener binds a local UDP port and forwards each client's datagrams
+// to a P2P service. Every client source address gets its own service
+// stream, dialed on its first datagram and closed after
+// UDPSessionIdleTimeout without traffic.
+type UDPListener struct {
+	conn     *net.UDPConn
+	dialFunc func() (ServiceConn, error)
+
+	mu       sync.Mutex
+	sessions map[string]*udpSession
+	wg       sync.WaitGroup // counts session goroutines
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// udpSession is one client flow. Its stream is dialed in the background,
+// so the read loop never waits on one client.
+type udpSession struct {
+	queue chan []byte   // client → service datagrams not yet written
+	done  chan struct{} // closed when the flow ends
+	once  sync.Once
+	act   activity
+
+	mu   sync.Mutex
+	conn ServiceConn // nil until dialed
+}
+
+// attach installs the dialed stream, or closes it and reports false if the
+// flow already ended.
+func (s *udpSession) attach(conn ServiceConn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+		conn.Close()
+		return false
+	default:
+	}
+	s.conn = conn
+	return true
+}
+
+// close ends the flow and its stream, if one was dialed.
+func (s *udpSession) close() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.done)
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	})
+}
+
+// NewUDPListener creates a new UDP listener for a P2P service.
+func NewUDPListener(localAddr string, dialFunc func() (ServiceConn, error)) (*UDPListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", localAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPListener{
+		conn:     conn,
+		dialFunc: dialFunc,
+		sessions: make(map[string]*udpSession),
+		closed:   make(chan struct{}),
+	}, nil
+}
+
+// Serve reads datagrams and forwards them to the P2P service until the
+// listener is closed. A new client's datagrams are queued while its
+// stream is dialed; other clients are served meanwhile.
+func (l *UDPListener) Serve() error {
+	go l.expireIdle()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, src, err := l.conn.ReadFromUDP(buf)
+		if err != nil {
+			return err
+		}
+		sess, err := l.session(src)
+		if err != nil {
+			slog.Warn("UDP client refused", "client", src.String(), "error", err)
+			continue
+		}
+		sess.act.touch()
+		select {
+		case sess.queue <- append([]byte(nil), buf[:n]...):
+		default: // queue full: drop the datagram
+		}
+	}
+}
+
+// session returns the flow for src, starting a new one if needed.
+func (l *UDPListener) session(src *net.UDPAddr) (*udpSession, error) {
+	key := src.String()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if sess, ok := l.sessions[key]; ok {
+		return sess, nil
+	}
+	if len(l.sessions) >= DefaultMaxProxyConns {
+		return nil, fmt.Errorf("too many UDP clients (max %d)", DefaultMaxProxyConns)
+	}
+	select {
+	case <-l.closed:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	sess := &udpSession{
+		queue: make(chan []byte, udpSessionQueue),
+		done:  make(chan struct{}),
+	}
+	sess.act.touch()
+	l.sessions[key] = sess
+	l.wg.Add(1)
+	go l.runSession(key, src, sess)
+	return sess, nil
+}
+
+// runSession dials the flow's stream, then forwards queued datagrams to it
+// while a second goroutine copies replies back to the client.
+func (l *UDPListener) runSession(key string, src *net.UDPAddr, sess *udpSession) {
+	defer l.wg.Done()
+	defer l.drop(key, sess)
+
+	conn, err := l.dialFunc()
+	if err != nil {
+		slog.Error("failed to dial P2P service", "client", src.String(), "error", err)
+		return
+	}
+	if !sess.attach(conn) {
+		return
+	}
+
+	// service → client
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer l.drop(key, sess)
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, err := readDatagram(conn, buf)
+			if err != nil {
+				return
+			}
+			sess.act.touch()
+			if _, err := l.conn.WriteToUDP(buf[:n], src); err != nil {
+				return
+			}
+		}
+	}()
+
+	// client → service
+	for {
+		select {
+		case <-sess.done:
+			return
+		case p := <-sess.queue:
+			if err := writeDatagram(conn, p); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// drop ends a flow and forgets it, unless it was already replaced.
+func (l *UDPListener) drop(key string, sess *udpSession) {
+	l.mu.Lock()
+	if l.sessions[key] == sess {
+		delete(l.sessions, key)
+	}
+	l.mu.Unlock()
+	sess.close()
+}
+
+// expireIdle closes flows that have been idle for UDPSessionIdleTimeout.
+func (l *UDPListener) expireIdle() {
+	ticker := time.NewTicker(UDPSessionIdleTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.closed:
+			return
+		case <-ticker.C:
+		}
+		l.mu.Lock()
+		var idle []*udpSession
+		for key, sess := range l.sessions {
+			if sess.act.idleFor() >= UDPSessionIdleTimeout {
+				idle = append(idle, sess)
+				delete(l.sessions, key)
+			}
+		}
+		l.mu.Unlock()
+		for _, sess := range idle {
+			sess.close()
+		}
+	}
+}
+
+// Close stops the listener and closes every flow.
+func (l *UDPListener) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.closed)
+		err = l.conn.Close()
+		l.mu.Lock()
+		for key, sess := range l.sessions {
+			sess.close()
+			delete(l.sessions, key)
+		}
+		l.mu.Unlock()
+	})
+	return err
+}
+
+// GracefulClose closes the listener and waits up to timeout for the flows'
+// goroutines to finish. UDP has no in-flight state worth draining, so this
+// only bounds the wait.
+func (l *UDPListener) GracefulClose(timeout time.Duration) {
+	l.Close()
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// Addr returns the listener's network address.
+func (l *UDPListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// ActiveConns returns the number of active client flows.
+func (l *UDPListener) ActiveConns() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.sessions)
+}
@@ -0,0 +1,164 @@
+package sdk
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDatagramFraming(t *testing.T) {
+	var buf bytes.Buffer
+	for _, p := range [][]byte{[]byte("hello"), {}, bytes.Repeat([]byte{0xAB}, maxDatagramSize)} {
+		if err := writeDatagram(&buf, p); err != nil {
+			t.Fatalf("writeDatagram(%d bytes): %v", len(p), err)
+		}
+	}
+	if err := writeDatagram(&buf, make([]byte, maxDatagramSize+1)); err == nil {
+		t.Error("oversized datagram accepted")
+	}
+
+	out := make([]byte, maxDatagramSize)
+	for _, want := range []int{5, 0, maxDatagramSize} {
+		n, err := readDatagram(&buf, out)
+		if err != nil {
+			t.Fatalf("readDatagram: %v", err)
+		}
+		if n != want {
+			t.Errorf("datagram length = %d, want %d", n, want)
+		}
+	}
+}
+
+// startUDPEcho runs a UDP server that sends every datagram back.
+func startUDPEcho(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(buf[:n], addr)
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func TestUDPListener_RoundTrip(t *testing.T) {
+	echoAddr := startUDPEcho(t)
+
+	// Each dial hands one end of a pipe to the service side, standing in
+	// for a libp2p stream.
+	dials := make(chan struct{}, 4)
+	l, err := NewUDPListener("127.0.0.1:0", func() (ServiceConn, error) {
+		client, service := net.Pipe()
+		go ProxyStreamToUDP(service, echoAddr)
+		dials <- struct{}{}
+		return &tcpHalfCloser{Conn: client}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go l.Serve()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 64)
+	for _, msg := range []string{"one", "two"} {
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("read reply to %q: %v", msg, err)
+		}
+		if got := string(buf[:n]); got != msg {
+			t.Errorf("reply = %q, want %q", got, msg)
+		}
+	}
+
+	// Both datagrams came from one source, so they shared one stream.
+	if len(dials) != 1 {
+		t.Errorf("dials = %d, want 1", len(dials))
+	}
+	if n := l.ActiveConns(); n != 1 {
+		t.Errorf("ActiveConns = %d, want 1", n)
+	}
+	l.Close()
+	if n := l.ActiveConns(); n != 0 {
+		t.Errorf("ActiveConns after Close = %d, want 0", n)
+	}
+}
+
+// TestUDPListener_SlowDialDoesNotBlock verifies that a client whose stream
+// is still being dialed doesn't hold up other clients, and that its
+// datagrams are delivered once the stream is ready.
+func TestUDPListener_SlowDialDoesNotBlock(t *testing.T) {
+	echoAddr := startUDPEcho(t)
+
+	release := make(chan struct{})
+	first := make(chan struct{}, 1)
+	first <- struct{}{}
+	l, err := NewUDPListener("127.0.0.1:0", func() (ServiceConn, error) {
+		select {
+		case <-first: // the first client's dial stalls until released
+			<-release
+		default:
+		}
+		client, service := net.Pipe()
+		go ProxyStreamToUDP(service, echoAddr)
+		return &tcpHalfCloser{Conn: client}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go l.Serve()
+
+	dial := func() net.Conn {
+		c, err := net.Dial("udp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+	read := func(c net.Conn, want string) {
+		t.Helper()
+		buf := make([]byte, 64)
+		c.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := c.Read(buf)
+		if err != nil {
+			t.Fatalf("read reply %q: %v", want, err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("reply = %q, want %q", got, want)
+		}
+	}
+
+	slow := dial()
+	slow.Write([]byte("queued"))
+	for len(first) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fast := dial()
+	fast.Write([]byte("fast"))
+	read(fast, "fast")
+
+	close(release)
+	read(slow, "queued")
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+// EnforceUserAgentPolicy disconnects peers whose identify agent version the
+// policy refuses. It subscribes synchronously (no identify completing after
+// it returns is missed) and runs until ctx is cancelled. exempt, if non-nil,
+// names peers that are never disconnected (e.g. this node's static relays).
+//
+// This is soft admission control: the peer picks its own agent string, so a
+// refused client can reconnect claiming another one.
+func EnforceUserAgentPolicy(ctx context.Context, h host.Host, policy config.UserAgentPolicy, exempt func(peer.ID) bool) {
+	if !policy.Enabled() {
+		return
+	}
+	sub, err := h.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		slog.Warn("user-agent policy: event bus subscribe failed", "error", err)
+		return
+	}
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				e := evt.(event.EvtPeerIdentificationCompleted)
+				checkUserAgent(h, policy, exempt, e.Peer, e.AgentVersion)
+			}
+		}
+	}()
+}
+
+// checkUserAgent applies the policy to one identified peer.
+func checkUserAgent(h host.Host, policy config.UserAgentPolicy, exempt func(peer.ID) bool, p peer.ID, agent string) bool {
+	if policy.Allows(agent) {
+		return true
+	}
+	if exempt != nil && exempt(p) {
+		slog.Debug("user-agent policy: exempt peer kept", "peer", shortPeerID(p), "agent", agent)
+		return true
+	}
+	slog.Warn("user-agent policy: disconnecting peer",
+		"peer", shortPeerID(p), "agent", agent)
+	if err := h.Network().ClosePeer(p); err != nil {
+		slog.Debug("user-agent policy: close failed", "peer", shortPeerID(p), "error", err)
+	}
+	return false
+}
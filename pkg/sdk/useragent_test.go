@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/shurlinet/shurli/internal/config"
+)
+
+func newAgentTestHost(t *testing.T, agent string) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"), libp2p.UserAgent(agent))
+	if err != nil {
+		t.Fatalf("create host: %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestEnforceUserAgentPolicy(t *testing.T) {
+	policy := config.UserAgentPolicy{
+		Allow: []string{"shurli/*"},
+		Deny:  []string{"shurli/0.1.*"},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		agent     string
+		exempt    bool
+		wantAlive bool
+	}{
+		{"allowed agent", "shurli/0.9.1", false, true},
+		{"denied agent", "shurli/0.1.4", false, false},
+		{"unknown agent", "go-libp2p/0.48.0", false, false},
+		{"exempt peer", "go-libp2p/0.48.0", true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newRawTestHost(t)
+			client := newAgentTestHost(t, tc.agent)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			EnforceUserAgentPolicy(ctx, server, policy, func(p peer.ID) bool {
+				return tc.exempt && p == client.ID()
+			})
+
+			if err := client.Connect(ctx, peer.AddrInfo{ID: server.ID(), Addrs: server.Addrs()}); err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+
+			// Identify completes shortly after connect; give the policy
+			// time to act before judging the outcome.
+			deadline := time.Now().Add(2 * time.Second)
+			for time.Now().Before(deadline) {
+				if server.Network().Connectedness(client.ID()) != network.Connected {
+					break
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			if !tc.wantAlive {
+				if server.Network().Connectedness(client.ID()) == network.Connected {
+					t.Errorf("peer with agent %q should have been disconnected", tc.agent)
+				}
+				return
+			}
+			if server.Network().Connectedness(client.ID()) != network.Connected {
+				t.Errorf("peer with agent %q should stay connected", tc.agent)
+			}
+		})
+	}
+}
+
+func TestEnforceUserAgentPolicyDisabled(t *testing.T) {
+	server := newRawTestHost(t)
+	client := newAgentTestHost(t, "anything/1.0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	EnforceUserAgentPolicy(ctx, server, config.UserAgentPolicy{}, nil)
+
+	if err := client.Connect(ctx, peer.AddrInfo{ID: server.ID(), Addrs: server.Addrs()}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if server.Network().Connectedness(client.ID()) != network.Connected {
+		t.Error("empty policy must not disconnect anyone")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/shurlinet/shurli/internal/config"
+	"github.com/shurlinet/shurli/internal/daemon"
 )
 
 // daemonClient connects to a running daemon via its Unix socket.
@@ -40,8 +41,8 @@ func daemonDir() string {
 // the daemon's own logic for socket/cookie placement.
 func newDaemonClient() (*daemonClient, error) {
 	dir := daemonDir()
-	socketPath := filepath.Join(dir, "shurli.sock")
-	cookiePath := filepath.Join(dir, ".daemon-cookie")
+	socketPath := daemon.SocketPath(dir, config.Profile())
+	cookiePath := daemon.CookiePath(dir, config.Profile())
 
 	if _, err := os.Stat(socketPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("daemon not running (no socket at %s)", socketPath)
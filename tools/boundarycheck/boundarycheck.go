@@ -8,6 +8,7 @@
 //  3. cmd/shurli/ must not import plugins/ except in registration files
 //  4. No plugin-specific receiver methods in pkg/sdk/
 //  5. No plugin-specific protocol constants in pkg/sdk/
+//  6. No package imports a pre-rename (legacy) module path
 //
 // Engine types and protocol constants are loaded from tools/plugin-engine-types.txt
 // (not hardcoded). When a new plugin is extracted, add its types there.
@@ -38,6 +39,14 @@ var allowedInternalForPlugins = []string{
 	modulePath + "/internal/termcolor",
 }
 
+// legacyModulePaths are import paths the project was published under
+// before the rename to shurli. They resolve to a different module and
+// break clean builds.
+var legacyModulePaths = []string{
+	"github.com/satindergrewal/peer-up",
+	"github.com/satindergrewal/peerup",
+}
+
 // coreProtocolPrefixes lists protocol path prefixes that are CORE (not plugin-specific).
 // Any /shurli/ protocol NOT matching these prefixes is considered plugin-specific.
 var coreProtocolPrefixes = []string{
@@ -178,6 +187,8 @@ func run(pass *analysis.Pass) (any, error) {
 	loadConfig(pass)
 	pkgPath := pass.Pkg.Path()
 
+	checkLegacyImports(pass)
+
 	switch {
 	case strings.HasPrefix(pkgPath, modulePath+"/pkg/sdk"):
 		return nil, checkSDK(pass, pkgPath)
@@ -341,6 +352,32 @@ func checkCmd(pass *analysis.Pass, pkgPath string) error {
 	return nil
 }
 
+// checkLegacyImports enforces: no package imports a legacy module path.
+// Applies to every package, not just the plugin boundary.
+func checkLegacyImports(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		for _, imp := range file.Imports {
+			importPath := strings.Trim(imp.Path.Value, `"`)
+			if legacy := legacyModulePath(importPath); legacy != "" {
+				pass.Reportf(imp.Pos(),
+					"import of legacy module path %q; use %q instead",
+					importPath, modulePath+strings.TrimPrefix(importPath, legacy))
+			}
+		}
+	}
+}
+
+// legacyModulePath returns the legacy module path that importPath falls
+// under, or "" when it is not a legacy import.
+func legacyModulePath(importPath string) string {
+	for _, legacy := range legacyModulePaths {
+		if importPath == legacy || strings.HasPrefix(importPath, legacy+"/") {
+			return legacy
+		}
+	}
+	return ""
+}
+
 // isRegistrationFile returns true for cmd/shurli/ files that are allowed
 // to import from plugins/ for plugin registration and lifecycle.
 func isRegistrationFile(fileName string) bool {
@@ -56,6 +56,16 @@ func TestBoundaryCheckCmd(t *testing.T) {
 	analysistest.Run(t, testdata, Analyzer, "github.com/shurlinet/shurli/cmd/shurli/fakecmd")
 }
 
+func TestBoundaryCheckLegacyModulePath(t *testing.T) {
+	overrideState(
+		map[string]bool{},
+		config{engineTypes: map[string]bool{}, protocolConsts: map[string]bool{}},
+	)
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "github.com/shurlinet/shurli/internal/fakelegacy")
+}
+
 func TestIsRegistrationFile(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,3 @@
+package p2pnet
+
+var Old = true
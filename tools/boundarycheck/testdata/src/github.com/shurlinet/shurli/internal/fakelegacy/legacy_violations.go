@@ -0,0 +1,8 @@
+package fakelegacy
+
+import (
+	_ "github.com/satindergrewal/peer-up/pkg/p2pnet" // want `import of legacy module path .*; use "github.com/shurlinet/shurli/pkg/p2pnet" instead`
+	_ "github.com/shurlinet/shurli/internal/config"  // canonical path: allowed
+)
+
+var Z = 1